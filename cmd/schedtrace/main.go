@@ -0,0 +1,123 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// schedtrace replays Karpenter's scheduling offline against a snapshot of its cluster state, as served on the
+// /debug/state metrics endpoint with --enable-debug-state, so that provisioning decisions can be reproduced from the
+// dumps of a cluster. It prints the nodeclaims that would be launched, the existing nodes that pods would be nominated
+// to and the pods that would fail to schedule.
+//
+//	kubectl port-forward -n kube-system deploy/karpenter 8000 &
+//	curl localhost:8000/debug/state > state.json
+//	kubectl get pods -A --field-selector status.phase=Pending -o yaml > pods.yaml
+//	kubectl get nodepools -o yaml > nodepools.yaml
+//	go run ./cmd/schedtrace --state state.json --pods pods.yaml --nodepools nodepools.yaml
+//
+// Instance types are the kwok provider's, or the ones in --instance-types in the format of its instance types file.
+// Karpenter's flags, e.g. --feature-gates, are accepted as well and apply to the replay.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/samber/lo"
+	"knative.dev/pkg/logging"
+
+	kwok "sigs.k8s.io/karpenter/kwok/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// The kwok provider's instance types are labeled with its labels, which it registers as well known
+func init() {
+	v1beta1.RestrictedLabelDomains = v1beta1.RestrictedLabelDomains.Insert(kwok.Group)
+	v1beta1.WellKnownLabels = v1beta1.WellKnownLabels.Insert(
+		kwok.InstanceSizeLabelKey,
+		kwok.InstanceFamilyLabelKey,
+		kwok.InstanceCPULabelKey,
+		kwok.InstanceMemoryLabelKey,
+	)
+}
+
+func main() {
+	fs := &options.FlagSet{FlagSet: flag.NewFlagSet("schedtrace", flag.ContinueOnError)}
+	statePath := fs.String("state", "", "Path to the cluster state snapshot, as served on the /debug/state metrics endpoint")
+	podsPath := fs.String("pods", "", "Path to the YAML or JSON of the pending pods to schedule, e.g. from kubectl get pods -o yaml")
+	nodePoolsPath := fs.String("nodepools", "", "Path to the YAML or JSON of the cluster's NodePools, e.g. from kubectl get nodepools -o yaml")
+	daemonSetsPath := fs.String("daemonsets", "", "Optional path to the YAML or JSON of the cluster's DaemonSets, whose overhead is added to new nodeclaims")
+	instanceTypesPath := fs.String("instance-types", "", "Optional path to an instance types file in the kwok provider's format. Defaults to the kwok provider's instance types.")
+	opts := &options.Options{}
+	opts.AddFlags(fs)
+	if err := opts.Parse(fs, os.Args[1:]...); err != nil {
+		fatalf("%s", err)
+	}
+	if *statePath == "" || *podsPath == "" || *nodePoolsPath == "" {
+		fs.Usage()
+		fatalf("--state, --pods and --nodepools are required")
+	}
+	ctx := opts.ToContext(context.Background())
+	// Logs go to stderr, so that they don't interleave with the decisions
+	cfg := operatorlogging.DefaultZapConfig(ctx, "schedtrace")
+	cfg.Encoding = "console"
+	cfg.OutputPaths = []string{"stderr"}
+	ctx = logging.WithLogger(ctx, lo.Must(cfg.Build()).Sugar())
+
+	nodes, err := readNodes(*statePath)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	pods, err := readPods(*podsPath)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	nodePools, err := readObjects[v1beta1.NodePool](*nodePoolsPath)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	daemonSets, err := readDaemonSets(*daemonSetsPath)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	var instanceTypes []*cloudprovider.InstanceType
+	if *instanceTypesPath != "" {
+		if instanceTypes, err = kwok.ReadInstanceTypes(*instanceTypesPath); err != nil {
+			fatalf("%s", err)
+		}
+	} else {
+		instanceTypes = kwok.ConstructInstanceTypes()
+	}
+
+	results, err := replay(ctx, snapshot{
+		nodes:         nodes,
+		pods:          pods,
+		nodePools:     nodePools,
+		daemonSets:    daemonSets,
+		instanceTypes: instanceTypes,
+	})
+	if err != nil {
+		fatalf("replaying scheduling, %s", err)
+	}
+	printResults(os.Stdout, results)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
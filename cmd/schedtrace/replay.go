@@ -0,0 +1,252 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/simulation"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// replayNamespace is the namespace of the pods that stand in for the requests of the snapshot's nodes
+const replayNamespace = "schedtrace"
+
+type snapshot struct {
+	nodes         []state.DebugStateNode
+	pods          []*v1.Pod
+	nodePools     []*v1beta1.NodePool
+	daemonSets    []*appsv1.DaemonSet
+	instanceTypes []*cloudprovider.InstanceType
+}
+
+// replay schedules the snapshot's pods against its nodes and the capacity that its NodePools can launch. The snapshot
+// doesn't include the pods that run on its nodes, so each node's requests are represented by a single pod, which means
+// that the topology of the pods that run on them isn't known.
+func replay(ctx context.Context, s snapshot) (scheduling.Results, error) {
+	pods := s.pods
+	var objects []client.Object
+	for _, daemonSet := range s.daemonSets {
+		objects = append(objects, daemonSet)
+	}
+	for _, n := range s.nodes {
+		node := nodeFor(n)
+		objects = append(objects, node)
+		if len(n.Requests) > 0 {
+			pod := requestsPod(node, n.Requests)
+			objects = append(objects, pod)
+			pods = append(pods, pod)
+		}
+	}
+	for _, obj := range objects {
+		prepare(obj)
+	}
+	// Cluster state reads the daemonsets' pods and the nodes' pods through a client
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objects...).
+		WithIndex(&v1.Pod{}, "spec.nodeName", func(o client.Object) []string { return []string{o.(*v1.Pod).Spec.NodeName} }).
+		Build()
+	cluster := state.NewCluster(clock.RealClock{}, kubeClient, nil)
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *v1.Node:
+			err = cluster.UpdateNode(ctx, o)
+		case *v1.Pod:
+			err = cluster.UpdatePod(ctx, o)
+		case *appsv1.DaemonSet:
+			err = cluster.UpdateDaemonSet(ctx, o)
+		}
+		if err != nil {
+			return scheduling.Results{}, fmt.Errorf("tracking %s, %w", client.ObjectKeyFromObject(obj), err)
+		}
+	}
+	daemonSetPods := lo.Map(s.daemonSets, func(d *appsv1.DaemonSet, _ int) *v1.Pod { return provisioning.DaemonSetPod(cluster, d) })
+	// The kwok provider offers every instance type to every nodepool
+	instanceTypes := lo.SliceToMap(s.nodePools, func(nodePool *v1beta1.NodePool) (string, []*cloudprovider.InstanceType) {
+		return nodePool.Name, s.instanceTypes
+	})
+	// Nodes that are marked for deletion aren't scheduled to, so the pods that stand in for their requests are left out
+	stateNodes := cluster.Nodes().Active()
+	nodeNames := sets.New(lo.Map(stateNodes, func(n *state.StateNode, _ int) string { return n.Name() })...)
+	pods = lo.Filter(pods, func(p *v1.Pod, _ int) bool { return p.Spec.NodeName == "" || nodeNames.Has(p.Spec.NodeName) })
+	return simulation.Simulate(ctx, pods, stateNodes, s.nodePools, instanceTypes, daemonSetPods)
+}
+
+// prepare readies the object to be served by the fake client, which manages resource versions itself. Objects are
+// given a uid if they don't have one, since scheduling tells pods apart by their uid.
+func prepare(obj client.Object) {
+	obj.SetResourceVersion("")
+	if obj.GetUID() == "" {
+		obj.SetUID(types.UID(client.ObjectKeyFromObject(obj).String()))
+	}
+}
+
+// nodeFor returns the node that the snapshot's node describes
+func nodeFor(n state.DebugStateNode) *v1.Node {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   n.Name,
+			Labels: n.Labels,
+		},
+		Spec: v1.NodeSpec{
+			// NodeClaims that haven't launched yet don't have a provider id
+			ProviderID: lo.Ternary(n.ProviderID != "", n.ProviderID, n.Name),
+			Taints:     n.Taints,
+		},
+		Status: v1.NodeStatus{
+			Capacity: n.Capacity,
+			// Snapshots from before the allocatable was served only have the capacity
+			Allocatable: lo.Ternary(len(n.Allocatable) > 0, n.Allocatable, n.Capacity),
+			Conditions:  []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+	// Nodes that are marked for deletion are replayed as terminating, so that pods aren't scheduled to them
+	if n.MarkedForDeletion {
+		node.DeletionTimestamp = lo.ToPtr(metav1.Now())
+		node.Finalizers = []string{v1beta1.TerminationFinalizer}
+	}
+	return node
+}
+
+// requestsPod returns a pod that's bound to the node and requests the resources that the node's pods request
+func requestsPod(node *v1.Node, requests v1.ResourceList) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      node.Name + "-requests",
+			Namespace: replayNamespace,
+		},
+		Spec: v1.PodSpec{
+			NodeName:    node.Name,
+			Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{{
+				Name:      "requests",
+				Resources: v1.ResourceRequirements{Requests: requests},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func readNodes(path string) ([]state.DebugStateNode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading state snapshot, %w", err)
+	}
+	var nodes []state.DebugStateNode
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("parsing state snapshot, %w", err)
+	}
+	return nodes, nil
+}
+
+// readPods reads the pending pods, leaving out the pods that are already bound to nodes
+func readPods(path string) ([]*v1.Pod, error) {
+	pods, err := readObjects[v1.Pod](path)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Filter(pods, func(pod *v1.Pod, _ int) bool { return pod.Spec.NodeName == "" }), nil
+}
+
+func readDaemonSets(path string) ([]*appsv1.DaemonSet, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return readObjects[appsv1.DaemonSet](path)
+}
+
+// readObjects reads the objects in a YAML or JSON file, which is either a single object or a list of them as kubectl
+// outputs it
+func readObjects[T any](path string) ([]*T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q, %w", path, err)
+	}
+	list := struct {
+		Kind  string `json:"kind"`
+		Items []T    `json:"items"`
+	}{}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing %q, %w", path, err)
+	}
+	if list.Kind == "List" || list.Items != nil {
+		return lo.ToSlicePtr(list.Items), nil
+	}
+	obj := new(T)
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return nil, fmt.Errorf("parsing %q, %w", path, err)
+	}
+	return []*T{obj}, nil
+}
+
+// printResults prints the scheduling decisions in a stable order, so that replays can be compared
+func printResults(w io.Writer, results scheduling.Results) {
+	for i, nodeClaim := range results.NewNodeClaims {
+		fmt.Fprintf(w, "new nodeclaim %d from nodepool %s with %d pod(s)\n", i, nodeClaim.NodePoolName, len(nodeClaim.Pods))
+		fmt.Fprintf(w, "  instance types: %s\n", pretty.Slice(lo.Map(nodeClaim.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) string { return it.Name }), 5))
+		fmt.Fprintf(w, "  requirements: %s\n", nodeClaim.Requirements)
+		fmt.Fprintf(w, "  requests: %s\n", resources.String(resources.RequestsForPods(nodeClaim.Pods...)))
+		printPods(w, nodeClaim.Pods)
+	}
+	for _, node := range results.ExistingNodes {
+		if len(node.Pods) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "existing node %s with %d pod(s)\n", node.Name(), len(node.Pods))
+		printPods(w, node.Pods)
+	}
+	podErrors := lo.Keys(results.PodErrors)
+	sort.Slice(podErrors, func(i, j int) bool {
+		return client.ObjectKeyFromObject(podErrors[i]).String() < client.ObjectKeyFromObject(podErrors[j]).String()
+	})
+	for _, pod := range podErrors {
+		fmt.Fprintf(w, "failed to schedule pod %s, %s\n", client.ObjectKeyFromObject(pod), results.PodErrors[pod])
+	}
+}
+
+func printPods(w io.Writer, pods []*v1.Pod) {
+	names := lo.Map(pods, func(pod *v1.Pod, _ int) string { return client.ObjectKeyFromObject(pod).String() })
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  pod %s\n", name)
+	}
+}
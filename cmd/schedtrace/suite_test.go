@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	kwok "sigs.k8s.io/karpenter/kwok/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+var ctx context.Context
+
+func TestSchedtrace(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schedtrace")
+}
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
+})
+
+// writeFixture writes the fixture to a temporary directory and returns its path
+func writeFixture(name, data string) string {
+	GinkgoHelper()
+	path := filepath.Join(GinkgoT().TempDir(), name)
+	Expect(os.WriteFile(path, []byte(data), 0600)).To(Succeed())
+	return path
+}
+
+const nodePoolsFixture = `
+apiVersion: karpenter.sh/v1beta1
+kind: NodePool
+metadata: {name: default}
+spec:
+  template:
+    spec:
+      nodeClassRef: {name: default}
+      requirements: [{key: kubernetes.io/os, operator: In, values: [linux]}]
+`
+
+// nodeFixture is a 4 cpu node of the default nodepool whose pods request 1 cpu
+const nodeFixture = `[{
+	"name": "node-a",
+	"providerID": "kwok://node-a",
+	"labels": {"karpenter.sh/nodepool": "default", "karpenter.sh/registered": "true", "karpenter.sh/initialized": "true", "kubernetes.io/os": "linux"},
+	"capacity": {"cpu": "4", "memory": "16Gi", "pods": "110"},
+	"requests": {"cpu": "1"}%s
+}]`
+
+// podFixture is a pending pod in the default namespace that requests the cpu
+func podFixture(name, cpu string) string {
+	return `
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: ` + name + `, namespace: default}
+  spec:
+    containers: [{name: c, image: x, resources: {requests: {cpu: "` + cpu + `"}}}]
+  status: {phase: Pending}`
+}
+
+var _ = Describe("Schedtrace", func() {
+	DescribeTable("should replay the scheduling of the pending pods",
+		func(node, pods string, expected []string) {
+			nodes, err := readNodes(writeFixture("state.json", node))
+			Expect(err).ToNot(HaveOccurred())
+			pendingPods, err := readPods(writeFixture("pods.yaml", "apiVersion: v1\nkind: List\nitems:"+pods))
+			Expect(err).ToNot(HaveOccurred())
+			nodePools, err := readObjects[v1beta1.NodePool](writeFixture("nodepools.yaml", nodePoolsFixture))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodePools).To(HaveLen(1))
+
+			results, err := replay(ctx, snapshot{
+				nodes:         nodes,
+				pods:          pendingPods,
+				nodePools:     nodePools,
+				instanceTypes: kwok.ConstructInstanceTypes(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			out := &bytes.Buffer{}
+			printResults(out, results)
+			// The instance types, requirements and requests of new nodeclaims depend on the kwok provider's catalog
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if strings.HasPrefix(line, "new nodeclaim") || strings.HasPrefix(line, "existing node") || strings.HasPrefix(line, "  pod") {
+					lines = append(lines, line)
+				}
+			}
+			Expect(lines).To(Equal(expected))
+		},
+		Entry("on the existing node when it has room",
+			fmt.Sprintf(nodeFixture, ""), podFixture("a", "2"),
+			[]string{
+				"existing node node-a with 1 pod(s)",
+				"  pod default/a",
+			},
+		),
+		Entry("on a new nodeclaim when the existing node doesn't have room",
+			fmt.Sprintf(nodeFixture, ""), podFixture("a", "4"),
+			[]string{
+				"new nodeclaim 0 from nodepool default with 1 pod(s)",
+				"  pod default/a",
+			},
+		),
+		Entry("on the existing node and a new nodeclaim once the existing node is full",
+			fmt.Sprintf(nodeFixture, ""), podFixture("a", "2")+podFixture("b", "2"),
+			[]string{
+				"new nodeclaim 0 from nodepool default with 1 pod(s)",
+				"  pod default/b",
+				"existing node node-a with 1 pod(s)",
+				"  pod default/a",
+			},
+		),
+		Entry("on a new nodeclaim when the existing node is marked for deletion",
+			fmt.Sprintf(nodeFixture, `, "markedForDeletion": true`), podFixture("a", "2"),
+			[]string{
+				"new nodeclaim 0 from nodepool default with 1 pod(s)",
+				"  pod default/a",
+			},
+		),
+	)
+})
@@ -18,9 +18,7 @@ package kwok
 
 import (
 	"context"
-	_ "embed"
 	"fmt"
-	"math"
 	"math/rand"
 	"strings"
 
@@ -104,16 +102,36 @@ func (c CloudProvider) List(ctx context.Context) ([]*v1beta1.NodeClaim, error) {
 	return nodeClaims, nil
 }
 
-// Return the hard-coded instance types.
+// Return the instance types of the configured catalog.
 func (c CloudProvider) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
 	return c.instanceTypes, nil
 }
 
+// The instance type catalog never changes, so it can be cached indefinitely.
+func (c CloudProvider) NotifyOfferingChange(func(...string)) {}
+
 // Return nothing since there's no cloud provider drift.
 func (c CloudProvider) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
 	return "", nil
 }
 
+// Return the price of the catalog's offering.
+func (c CloudProvider) Price(_ context.Context, instanceType, capacityType, zone string) (float64, error) {
+	it, err := c.getInstanceType(instanceType)
+	if err != nil {
+		return 0, err
+	}
+	offering, ok := it.Offerings.Get(capacityType, zone)
+	if !ok {
+		return 0, fmt.Errorf("unable to find offering %s/%s for instance type %q", capacityType, zone, instanceType)
+	}
+	return offering.Price, nil
+}
+
+func (c CloudProvider) RepairPolicies() []cloudprovider.RepairPolicy {
+	return nil
+}
+
 func (c CloudProvider) Name() string {
 	return "kwok"
 }
@@ -133,12 +151,8 @@ func (c CloudProvider) toNode(nodeClaim *v1beta1.NodeClaim) (*v1.Node, error) {
 	//nolint
 	newName = fmt.Sprintf("%s-%d", newName, rand.Uint32())
 
-	capacityType := v1beta1.CapacityTypeOnDemand
 	requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.
 		Spec.Requirements...)
-	if requirements.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeSpot) {
-		capacityType = v1beta1.CapacityTypeSpot
-	}
 	req, found := lo.Find(nodeClaim.Spec.Requirements, func(req v1beta1.NodeSelectorRequirementWithMinValues) bool {
 		return req.Key == v1.LabelInstanceTypeStable
 	})
@@ -146,36 +160,36 @@ func (c CloudProvider) toNode(nodeClaim *v1beta1.NodeClaim) (*v1.Node, error) {
 		return nil, fmt.Errorf("instance type requirement not found")
 	}
 
-	minInstanceTypePrice := math.MaxFloat64
 	var instanceType *cloudprovider.InstanceType
+	var offering cloudprovider.Offering
 	// Loop through instance type values, as the node claim will only have the In operator.
 	for _, val := range req.Values {
-		var price float64
-		var ok bool
 		it, err := c.getInstanceType(val)
 		if err != nil {
 			return nil, fmt.Errorf("instance type %s not found", val)
 		}
-
-		// Since we're constructing the instance types we know that each instance type with OD offerings will have spot
-		// offerings, where spot will always be cheapest.
-		zone := randomChoice(KwokZones)
-		offering, ok := it.Offerings.Get(capacityType, zone)
-		if !ok {
-			return nil, fmt.Errorf("failed to find offering %s/%s/%s", capacityType, zone, val)
+		compatible := lo.Filter(it.Offerings.Available(), func(o cloudprovider.Offering, _ int) bool {
+			return requirements.Get(v1beta1.CapacityTypeLabelKey).Has(o.CapacityType) && requirements.Get(v1.LabelTopologyZone).Has(o.Zone) &&
+				o.Compatible(requirements)
+		})
+		if len(compatible) == 0 {
+			continue
 		}
-		// All offerings of the same capacity type are the same price.
-		price = offering.Price
-		if price < minInstanceTypePrice {
-			minInstanceTypePrice = price
+		// Pick randomly between the cheapest offerings, so that nodes spread across the zones that offer them
+		cheapest := lo.MinBy(compatible, func(a, b cloudprovider.Offering) bool { return a.Price < b.Price })
+		if instanceType == nil || cheapest.Price < offering.Price {
 			instanceType = it
+			offering = randomChoice(lo.Filter(compatible, func(o cloudprovider.Offering, _ int) bool { return o.Price == cheapest.Price }))
 		}
 	}
+	if instanceType == nil {
+		return nil, cloudprovider.NewInsufficientCapacityError(fmt.Errorf("no available offerings are compatible with the nodeclaim requirements"))
+	}
 
 	return &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        newName,
-			Labels:      addInstanceLabels(nodeClaim.Labels, instanceType, nodeClaim, capacityType, fmt.Sprintf("%f", minInstanceTypePrice)),
+			Labels:      addInstanceLabels(nodeClaim.Labels, instanceType, nodeClaim, offering, fmt.Sprintf("%f", offering.Price)),
 			Annotations: addKwokAnnotation(nodeClaim.Annotations),
 		},
 		Spec: v1.NodeSpec{
@@ -189,7 +203,7 @@ func (c CloudProvider) toNode(nodeClaim *v1beta1.NodeClaim) (*v1.Node, error) {
 	}, nil
 }
 
-func addInstanceLabels(labels map[string]string, instanceType *cloudprovider.InstanceType, nodeClaim *v1beta1.NodeClaim, capacityType, price string) map[string]string {
+func addInstanceLabels(labels map[string]string, instanceType *cloudprovider.InstanceType, nodeClaim *v1beta1.NodeClaim, offering cloudprovider.Offering, price string) map[string]string {
 	ret := make(map[string]string, len(labels))
 	// start with labels on the nodeclaim
 	for k, v := range labels {
@@ -215,10 +229,10 @@ func addInstanceLabels(labels map[string]string, instanceType *cloudprovider.Ins
 	// Kwok has some scalability limitations.
 	// Randomly add each new node to one of the pre-created kwokPartitions.
 	ret[kwokPartitionLabelKey] = randomPartition(10)
-	ret[v1beta1.CapacityTypeLabelKey] = capacityType
-	// no zone set by requirements, so just pick one
-	if _, ok := ret[v1.LabelTopologyZone]; !ok {
-		ret[v1.LabelTopologyZone] = randomChoice(KwokZones)
+	ret[v1beta1.CapacityTypeLabelKey] = offering.CapacityType
+	ret[v1.LabelTopologyZone] = offering.Zone
+	for key, domain := range offering.Domains {
+		ret[key] = domain
 	}
 	ret[v1.LabelHostname] = nodeClaim.Name
 
@@ -233,10 +247,10 @@ func randomPartition(n int) string {
 	return KwokPartitions[i]
 }
 
-func randomChoice(zones []string) string {
+func randomChoice[T any](choices []T) T {
 	//nolint
-	i := rand.Intn(len(zones))
-	return zones[i]
+	i := rand.Intn(len(choices))
+	return choices[i]
 }
 
 func addKwokAnnotation(annotations map[string]string) map[string]string {
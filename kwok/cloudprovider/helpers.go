@@ -17,7 +17,9 @@ limitations under the License.
 package kwok
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/samber/lo"
@@ -104,6 +106,120 @@ func ConstructInstanceTypes() []*cloudprovider.InstanceType {
 	return instanceTypes
 }
 
+// InstanceTypeDefinition is the serialized form of an instance type in an instance types file
+type InstanceTypeDefinition struct {
+	Name             string               `json:"name"`
+	Architecture     string               `json:"architecture"`
+	OperatingSystems []string             `json:"operatingSystems"`
+	Resources        v1.ResourceList      `json:"resources"`
+	Offerings        []OfferingDefinition `json:"offerings"`
+	// Labels override the instance size, family, cpu and memory labels that are otherwise derived from the resources
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// OfferingDefinition is the serialized form of an offering in an instance types file
+type OfferingDefinition struct {
+	CapacityType string `json:"capacityType"`
+	Zone         string `json:"zone"`
+	// Price defaults to the price derived from the instance type's resources, discounted for spot
+	Price *float64 `json:"price,omitempty"`
+	// Available defaults to true. Unavailable offerings can be used to simulate capacity shortages.
+	Available *bool `json:"available,omitempty"`
+	// Domains are the offering's domains for topology keys other than the zone and capacity type, e.g. a rack, keyed
+	// by the node label of the topology key
+	Domains map[string]string `json:"domains,omitempty"`
+	// ReservationCapacity is the number of instances that the capacity reservations of a capacity-reservation offering
+	// hold
+	ReservationCapacity int `json:"reservationCapacity,omitempty"`
+}
+
+// ReadInstanceTypes reads a JSON list of instance type definitions from a file, so that scale tests can use an
+// instance type catalog that matches the cloud they model
+func ReadInstanceTypes(path string) ([]*cloudprovider.InstanceType, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading instance types file, %w", err)
+	}
+	var definitions []InstanceTypeDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("parsing instance types file, %w", err)
+	}
+	instanceTypes := make([]*cloudprovider.InstanceType, 0, len(definitions))
+	names := sets.New[string]()
+	for _, definition := range definitions {
+		opts, err := definition.toInstanceTypeOptions()
+		if err != nil {
+			return nil, fmt.Errorf("instance type %q, %w", definition.Name, err)
+		}
+		if names.Has(opts.Name) {
+			return nil, fmt.Errorf("instance type %q is defined more than once", opts.Name)
+		}
+		names.Insert(opts.Name)
+		instanceTypes = append(instanceTypes, newInstanceType(opts))
+	}
+	if len(instanceTypes) == 0 {
+		return nil, fmt.Errorf("instance types file %q defines no instance types", path)
+	}
+	return instanceTypes, nil
+}
+
+func (d InstanceTypeDefinition) toInstanceTypeOptions() (InstanceTypeOptions, error) {
+	if d.Name == "" {
+		return InstanceTypeOptions{}, fmt.Errorf("name is required")
+	}
+	if len(d.Offerings) == 0 {
+		return InstanceTypeOptions{}, fmt.Errorf("at least one offering is required")
+	}
+	cpu, mem := d.Resources.Cpu(), d.Resources.Memory()
+	if cpu.IsZero() || mem.IsZero() {
+		return InstanceTypeOptions{}, fmt.Errorf("cpu and memory resources are required")
+	}
+	resources := d.Resources.DeepCopy()
+	if _, ok := resources[v1.ResourcePods]; !ok {
+		resources[v1.ResourcePods] = resource.MustParse(fmt.Sprintf("%d", lo.Clamp(cpu.Value()*16, 0, 1024)))
+	}
+	if _, ok := resources[v1.ResourceEphemeralStorage]; !ok {
+		resources[v1.ResourceEphemeralStorage] = resource.MustParse("20G")
+	}
+	cpus := int(cpu.Value())
+	labels := MakeInstanceTypeLabels(cpus, int(mem.Value()/(1024*1024*1024))/cpus)
+	// The memory label is in MiB, which the derived labels only approximate for instance types with unusual ratios
+	labels[InstanceMemoryLabelKey] = fmt.Sprintf("%d", mem.Value()/(1024*1024))
+	for k, v := range d.Labels {
+		labels[k] = v
+	}
+	price := PriceFromResources(resources)
+	offerings := cloudprovider.Offerings{}
+	for _, o := range d.Offerings {
+		if o.CapacityType != v1beta1.CapacityTypeSpot && o.CapacityType != v1beta1.CapacityTypeOnDemand && o.CapacityType != v1beta1.CapacityTypeReserved {
+			return InstanceTypeOptions{}, fmt.Errorf("offering capacity type must be one of %q, %q or %q, got %q",
+				v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand, v1beta1.CapacityTypeReserved, o.CapacityType)
+		}
+		if o.CapacityType == v1beta1.CapacityTypeReserved && o.ReservationCapacity <= 0 {
+			return InstanceTypeOptions{}, fmt.Errorf("reservation capacity must be positive for %q offerings", v1beta1.CapacityTypeReserved)
+		}
+		if o.Zone == "" {
+			return InstanceTypeOptions{}, fmt.Errorf("offering zone is required")
+		}
+		offerings = append(offerings, cloudprovider.Offering{
+			CapacityType:        o.CapacityType,
+			Zone:                o.Zone,
+			Price:               lo.FromPtrOr(o.Price, lo.Ternary(o.CapacityType == v1beta1.CapacityTypeSpot, price*.7, price)),
+			Available:           lo.FromPtrOr(o.Available, true),
+			Domains:             o.Domains,
+			ReservationCapacity: o.ReservationCapacity,
+		})
+	}
+	return InstanceTypeOptions{
+		Name:               d.Name,
+		Offerings:          offerings,
+		Architecture:       lo.Ternary(d.Architecture == "", v1beta1.ArchitectureAmd64, d.Architecture),
+		OperatingSystems:   lo.Ternary(len(d.OperatingSystems) == 0, sets.New(string(v1.Linux)), sets.New(d.OperatingSystems...)),
+		Resources:          resources,
+		InstanceTypeLabels: labels,
+	}, nil
+}
+
 func newInstanceType(options InstanceTypeOptions) *cloudprovider.InstanceType {
 	requirements := scheduling.NewRequirements(
 		scheduling.NewRequirement(v1.LabelInstanceTypeStable, v1.NodeSelectorOpIn, options.Name),
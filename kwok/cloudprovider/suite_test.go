@@ -0,0 +1,238 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kwok
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+func TestKwok(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kwok")
+}
+
+// writeInstanceTypes writes the instance types file to a temporary directory and returns its path
+func writeInstanceTypes(data string) string {
+	GinkgoHelper()
+	path := filepath.Join(GinkgoT().TempDir(), "instance-types.json")
+	Expect(os.WriteFile(path, []byte(data), 0600)).To(Succeed())
+	return path
+}
+
+func nodeClaimFor(requirements ...v1beta1.NodeSelectorRequirementWithMinValues) *v1beta1.NodeClaim {
+	return test.NodeClaim(v1beta1.NodeClaim{Spec: v1beta1.NodeClaimSpec{Requirements: requirements}})
+}
+
+func requirement(key string, values ...string) v1beta1.NodeSelectorRequirementWithMinValues {
+	return v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: key, Operator: v1.NodeSelectorOpIn, Values: values}}
+}
+
+var _ = Describe("Kwok", func() {
+	Context("ReadInstanceTypes", func() {
+		It("should read the instance types with their defaults", func() {
+			instanceTypes, err := ReadInstanceTypes(writeInstanceTypes(`[{
+				"name": "s-4x-amd64-linux",
+				"resources": {"cpu": "4", "memory": "16Gi"},
+				"offerings": [
+					{"capacityType": "spot", "zone": "test-zone-a"},
+					{"capacityType": "on-demand", "zone": "test-zone-a", "price": 0.2},
+					{"capacityType": "on-demand", "zone": "test-zone-b", "available": false}
+				]
+			}]`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instanceTypes).To(HaveLen(1))
+			it := instanceTypes[0]
+			Expect(it.Name).To(Equal("s-4x-amd64-linux"))
+			Expect(it.Requirements.Get(v1.LabelArchStable).Values()).To(ConsistOf(v1beta1.ArchitectureAmd64))
+			Expect(it.Requirements.Get(v1.LabelOSStable).Values()).To(ConsistOf(string(v1.Linux)))
+			Expect(it.Requirements.Get(v1.LabelTopologyZone).Values()).To(ConsistOf("test-zone-a"))
+			Expect(it.Capacity.Pods().Value()).To(BeNumerically("==", 64))
+			Expect(it.Capacity).To(HaveKey(v1.ResourceEphemeralStorage))
+
+			price := PriceFromResources(it.Capacity)
+			spot, ok := it.Offerings.Get(v1beta1.CapacityTypeSpot, "test-zone-a")
+			Expect(ok).To(BeTrue())
+			Expect(spot.Price).To(BeNumerically("~", price*.7))
+			onDemand, ok := it.Offerings.Get(v1beta1.CapacityTypeOnDemand, "test-zone-a")
+			Expect(ok).To(BeTrue())
+			Expect(onDemand.Price).To(BeNumerically("==", 0.2))
+			unavailable, ok := it.Offerings.Get(v1beta1.CapacityTypeOnDemand, "test-zone-b")
+			Expect(ok).To(BeTrue())
+			Expect(unavailable.Available).To(BeFalse())
+		})
+		It("should fail when an instance type is defined more than once", func() {
+			_, err := ReadInstanceTypes(writeInstanceTypes(`[
+				{"name": "a", "resources": {"cpu": "1", "memory": "2Gi"}, "offerings": [{"capacityType": "spot", "zone": "test-zone-a"}]},
+				{"name": "a", "resources": {"cpu": "2", "memory": "4Gi"}, "offerings": [{"capacityType": "spot", "zone": "test-zone-a"}]}
+			]`))
+			Expect(err).To(MatchError(ContainSubstring("defined more than once")))
+		})
+		It("should fail when the file defines no instance types", func() {
+			_, err := ReadInstanceTypes(writeInstanceTypes(`[]`))
+			Expect(err).To(MatchError(ContainSubstring("defines no instance types")))
+		})
+		It("should fail when the file can't be parsed", func() {
+			_, err := ReadInstanceTypes(writeInstanceTypes(`{`))
+			Expect(err).To(MatchError(ContainSubstring("parsing instance types file")))
+		})
+		It("should fail when the file doesn't exist", func() {
+			_, err := ReadInstanceTypes(filepath.Join(GinkgoT().TempDir(), "missing.json"))
+			Expect(err).To(MatchError(ContainSubstring("reading instance types file")))
+		})
+	})
+	Context("toInstanceTypeOptions", func() {
+		var definition InstanceTypeDefinition
+
+		BeforeEach(func() {
+			definition = InstanceTypeDefinition{
+				Name:      "c-2x-arm64-linux",
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				Offerings: []OfferingDefinition{{CapacityType: v1beta1.CapacityTypeSpot, Zone: "test-zone-a"}},
+			}
+		})
+		It("should derive the instance type labels from the resources", func() {
+			opts, err := definition.toInstanceTypeOptions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts.InstanceTypeLabels).To(HaveKeyWithValue(InstanceSizeLabelKey, "2x"))
+			Expect(opts.InstanceTypeLabels).To(HaveKeyWithValue(InstanceFamilyLabelKey, "c"))
+			Expect(opts.InstanceTypeLabels).To(HaveKeyWithValue(InstanceCPULabelKey, "2"))
+			Expect(opts.InstanceTypeLabels).To(HaveKeyWithValue(InstanceMemoryLabelKey, "4096"))
+		})
+		It("should let the labels override the derived labels", func() {
+			definition.Labels = map[string]string{InstanceFamilyLabelKey: "custom"}
+			opts, err := definition.toInstanceTypeOptions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts.InstanceTypeLabels).To(HaveKeyWithValue(InstanceFamilyLabelKey, "custom"))
+		})
+		It("should keep the domains and reservation capacity of the offerings", func() {
+			definition.Offerings = []OfferingDefinition{{
+				CapacityType:        v1beta1.CapacityTypeReserved,
+				Zone:                "test-zone-a",
+				Domains:             map[string]string{"example.com/rack": "rack-1"},
+				ReservationCapacity: 10,
+			}}
+			opts, err := definition.toInstanceTypeOptions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts.Offerings).To(HaveLen(1))
+			Expect(opts.Offerings[0].Domains).To(HaveKeyWithValue("example.com/rack", "rack-1"))
+			Expect(opts.Offerings[0].ReservationCapacity).To(Equal(10))
+		})
+		DescribeTable("should reject invalid definitions",
+			func(mutate func(*InstanceTypeDefinition), message string) {
+				mutate(&definition)
+				_, err := definition.toInstanceTypeOptions()
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("without a name", func(d *InstanceTypeDefinition) { d.Name = "" }, "name is required"),
+			Entry("without offerings", func(d *InstanceTypeDefinition) { d.Offerings = nil }, "at least one offering is required"),
+			Entry("without memory", func(d *InstanceTypeDefinition) { delete(d.Resources, v1.ResourceMemory) }, "cpu and memory resources are required"),
+			Entry("with an unknown capacity type", func(d *InstanceTypeDefinition) { d.Offerings[0].CapacityType = "unknown" }, "offering capacity type must be one of"),
+			Entry("without a zone", func(d *InstanceTypeDefinition) { d.Offerings[0].Zone = "" }, "offering zone is required"),
+			Entry("with a reserved offering without capacity", func(d *InstanceTypeDefinition) { d.Offerings[0].CapacityType = v1beta1.CapacityTypeReserved }, "reservation capacity must be positive"),
+		)
+	})
+	Context("toNode", func() {
+		var cloudProvider CloudProvider
+
+		BeforeEach(func() {
+			instanceTypes, err := ReadInstanceTypes(writeInstanceTypes(`[
+				{"name": "small", "resources": {"cpu": "2", "memory": "4Gi"}, "offerings": [
+					{"capacityType": "on-demand", "zone": "test-zone-a", "price": 0.3},
+					{"capacityType": "spot", "zone": "test-zone-a", "price": 0.1, "domains": {"example.com/rack": "rack-1"}},
+					{"capacityType": "spot", "zone": "test-zone-b", "price": 0.1},
+					{"capacityType": "spot", "zone": "test-zone-c", "price": 0.05, "available": false}
+				]},
+				{"name": "large", "resources": {"cpu": "8", "memory": "16Gi"}, "offerings": [
+					{"capacityType": "on-demand", "zone": "test-zone-a", "price": 0.2},
+					{"capacityType": "spot", "zone": "test-zone-b", "price": 0.15}
+				]}
+			]`))
+			Expect(err).ToNot(HaveOccurred())
+			cloudProvider = CloudProvider{instanceTypes: instanceTypes}
+		})
+		It("should launch the cheapest available offering of the instance types", func() {
+			node, err := cloudProvider.toNode(nodeClaimFor(
+				requirement(v1.LabelInstanceTypeStable, "small", "large"),
+				requirement(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand),
+				requirement(v1.LabelTopologyZone, "test-zone-a", "test-zone-b", "test-zone-c"),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "small"))
+			Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot))
+			Expect(node.Labels[v1.LabelTopologyZone]).To(BeElementOf("test-zone-a", "test-zone-b"))
+			Expect(node.Status.Capacity.Cpu().Value()).To(BeNumerically("==", 2))
+			Expect(node.Spec.ProviderID).To(Equal(kwokProviderPrefix + node.Name))
+		})
+		It("should only launch offerings that are compatible with the nodeclaim's requirements", func() {
+			node, err := cloudProvider.toNode(nodeClaimFor(
+				requirement(v1.LabelInstanceTypeStable, "small", "large"),
+				requirement(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeOnDemand),
+				requirement(v1.LabelTopologyZone, "test-zone-a"),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large"))
+			Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeOnDemand))
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-a"))
+		})
+		It("should label the node with the domains of the offering", func() {
+			node, err := cloudProvider.toNode(nodeClaimFor(
+				requirement(v1.LabelInstanceTypeStable, "small"),
+				requirement(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot),
+				requirement(v1.LabelTopologyZone, "test-zone-a"),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Labels).To(HaveKeyWithValue("example.com/rack", "rack-1"))
+		})
+		It("should fail with insufficient capacity when no available offering is compatible", func() {
+			_, err := cloudProvider.toNode(nodeClaimFor(
+				requirement(v1.LabelInstanceTypeStable, "small", "large"),
+				requirement(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot),
+				requirement(v1.LabelTopologyZone, "test-zone-c"),
+			))
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeTrue())
+		})
+		It("should fail without an instance type requirement", func() {
+			_, err := cloudProvider.toNode(nodeClaimFor(requirement(v1.LabelTopologyZone, "test-zone-a")))
+			Expect(err).To(MatchError(ContainSubstring("instance type requirement not found")))
+		})
+		It("should fail for an instance type that isn't in the catalog", func() {
+			_, err := cloudProvider.toNode(nodeClaimFor(requirement(v1.LabelInstanceTypeStable, "missing")))
+			Expect(err).To(MatchError(ContainSubstring("instance type missing not found")))
+		})
+	})
+	Context("ConstructInstanceTypes", func() {
+		It("should discount spot offerings", func() {
+			it := ConstructInstanceTypes()[0]
+			spot, ok := it.Offerings.Get(v1beta1.CapacityTypeSpot, KwokZones[0])
+			Expect(ok).To(BeTrue())
+			onDemand, ok := it.Offerings.Get(v1beta1.CapacityTypeOnDemand, KwokZones[0])
+			Expect(ok).To(BeTrue())
+			Expect(spot.Price).To(BeNumerically("<", onDemand.Price))
+		})
+	})
+})
@@ -17,11 +17,17 @@ limitations under the License.
 package main
 
 import (
+	"github.com/samber/lo"
+
 	kwok "sigs.k8s.io/karpenter/kwok/cloudprovider"
+	"sigs.k8s.io/karpenter/kwok/options"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/overlay"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/ratelimit"
 	"sigs.k8s.io/karpenter/pkg/controllers"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/operator"
+	"sigs.k8s.io/karpenter/pkg/operator/faultinjection"
 )
 
 func init() {
@@ -37,13 +43,21 @@ func init() {
 func main() {
 	ctx, op := operator.NewOperator()
 
-	cloudProvider := kwok.NewCloudProvider(ctx, op.GetClient(), kwok.ConstructInstanceTypes())
+	instanceTypes := kwok.ConstructInstanceTypes()
+	if path := options.FromContext(ctx).InstanceTypesFilePath; path != "" {
+		instanceTypes = lo.Must(kwok.ReadInstanceTypes(path))
+	}
+	cloudProvider := faultinjection.DecorateCloudProvider(ctx, kwok.NewCloudProvider(ctx, op.GetClient(), instanceTypes), op.GetClient())
+	cloudProvider = overlay.Decorate(ratelimit.Decorate(ctx, cloudProvider, op.Clock), op.GetClient())
+	cluster := state.NewCluster(op.Clock, op.GetClient(), cloudProvider)
 	op.
-		WithControllers(ctx, controllers.NewControllers(
+		WithClusterState(cluster).
+		WithControllers(ctx, append(controllers.NewControllers(
 			op.Clock,
 			op.GetClient(),
-			state.NewCluster(op.Clock, op.GetClient(), cloudProvider),
+			op.GetAPIReader(),
+			cluster,
 			op.EventRecorder,
 			cloudProvider,
-		)...).Start(ctx)
+		), overlay.NewController(cloudProvider))...).Start(ctx)
 }
@@ -23,6 +23,7 @@ import (
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/utils/functional"
 )
@@ -31,6 +32,7 @@ var (
 	// Builder includes all types within the apis package
 	Builder = runtime.NewSchemeBuilder(
 		v1beta1.SchemeBuilder.AddToScheme,
+		v1alpha1.SchemeBuilder.AddToScheme,
 	)
 	// AddToScheme may be used to add all resources defined in the project to a Scheme
 	AddToScheme = Builder.AddToScheme
@@ -42,8 +44,17 @@ var (
 	NodePoolCRD []byte
 	//go:embed crds/karpenter.sh_nodeclaims.yaml
 	NodeClaimCRD []byte
+	//go:embed crds/karpenter.sh_nodeoverlays.yaml
+	NodeOverlayCRD []byte
+	//go:embed crds/karpenter.sh_disruptionactions.yaml
+	DisruptionActionCRD []byte
+	//go:embed crds/karpenter.sh_nodedrains.yaml
+	NodeDrainCRD []byte
 	CRDs         = []*v1.CustomResourceDefinition{
 		lo.Must(functional.Unmarshal[v1.CustomResourceDefinition](NodePoolCRD)),
 		lo.Must(functional.Unmarshal[v1.CustomResourceDefinition](NodeClaimCRD)),
+		lo.Must(functional.Unmarshal[v1.CustomResourceDefinition](NodeOverlayCRD)),
+		lo.Must(functional.Unmarshal[v1.CustomResourceDefinition](DisruptionActionCRD)),
+		lo.Must(functional.Unmarshal[v1.CustomResourceDefinition](NodeDrainCRD)),
 	}
 )
@@ -0,0 +1,137 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionActionSpec describes a disruption command that Karpenter decided on. DisruptionActions are a durable record
+// of the commands for audits and rollbacks, since events and logs only describe them for a short while.
+type DisruptionActionSpec struct {
+	// Method is the disruption method that decided on the command, e.g. drift or consolidation
+	Method string `json:"method"`
+	// ConsolidationType is the kind of consolidation that decided on the command, if it's a consolidation
+	// +optional
+	ConsolidationType string `json:"consolidationType,omitempty"`
+	// Reason is the reason that the candidates are disrupted for, e.g. Underutilized or Drifted
+	Reason string `json:"reason"`
+	// Action is whether the candidates are deleted, or replaced with the replacements
+	// +kubebuilder:validation:Enum:={delete,replace}
+	Action string `json:"action"`
+	// DryRun is true for commands that were only recorded rather than executed, because the nodepools of their
+	// candidates have disruption dry-run enabled
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Candidates are the nodes that the command disrupts
+	Candidates []DisruptionCandidate `json:"candidates"`
+	// Replacements are the NodeClaims that the command launches before the candidates are disrupted
+	// +optional
+	Replacements []DisruptionReplacement `json:"replacements,omitempty"`
+	// EstimatedMonthlySavings is the expected monthly cost reduction from executing the command, as a decimal in the
+	// cloudprovider's currency, if it's known
+	// +optional
+	EstimatedMonthlySavings string `json:"estimatedMonthlySavings,omitempty"`
+}
+
+// DisruptionCandidate describes a node that's disrupted by a command
+type DisruptionCandidate struct {
+	// NodeClaim is the name of the candidate's NodeClaim
+	NodeClaim string `json:"nodeClaim"`
+	// Node is the name of the candidate's node, if it has registered
+	// +optional
+	Node string `json:"node,omitempty"`
+	// NodePool is the name of the nodepool that the candidate belongs to
+	NodePool string `json:"nodePool"`
+	// InstanceType is the instance type of the candidate
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+	// CapacityType is the capacity type of the candidate
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	// Zone is the zone of the candidate
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// Pods is the number of pods on the candidate that are rescheduled when it's disrupted
+	Pods int `json:"pods"`
+}
+
+// DisruptionReplacement describes a NodeClaim that's launched by a command
+type DisruptionReplacement struct {
+	// NodeClaim is the name of the replacement NodeClaim, once it's been created
+	// +optional
+	NodeClaim string `json:"nodeClaim,omitempty"`
+	// NodePool is the name of the nodepool that the replacement is launched from
+	NodePool string `json:"nodePool"`
+	// InstanceTypes are the cheapest of the instance types that the replacement can be launched as
+	InstanceTypes []string `json:"instanceTypes"`
+}
+
+type DisruptionResult string
+
+const (
+	// DisruptionResultPending is the result of commands that are waiting for their replacements to initialize or their
+	// candidates to be deleted
+	DisruptionResultPending DisruptionResult = "Pending"
+	// DisruptionResultSucceeded is the result of commands whose candidates were deleted
+	DisruptionResultSucceeded DisruptionResult = "Succeeded"
+	// DisruptionResultFailed is the result of commands that were abandoned, e.g. because their replacements failed to
+	// launch
+	DisruptionResultFailed DisruptionResult = "Failed"
+	// DisruptionResultDryRun is the result of commands that were only recorded
+	DisruptionResultDryRun DisruptionResult = "DryRun"
+)
+
+// DisruptionActionStatus is the outcome of a disruption command
+type DisruptionActionStatus struct {
+	// Result is the outcome of the command
+	// +kubebuilder:validation:Enum:={Pending,Succeeded,Failed,DryRun}
+	// +optional
+	Result DisruptionResult `json:"result,omitempty"`
+	// Message explains why the command failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// CompletionTime is when the command succeeded, failed or was recorded as a dry run
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DisruptionAction is the Schema for the DisruptionActions API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=disruptionactions,scope=Cluster,categories=karpenter
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Method",type="string",JSONPath=".spec.method",description=""
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=".spec.action",description=""
+// +kubebuilder:printcolumn:name="Result",type="string",JSONPath=".status.result",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Savings",type="string",JSONPath=".spec.estimatedMonthlySavings",priority=1,description=""
+type DisruptionAction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec   DisruptionActionSpec   `json:"spec"`
+	Status DisruptionActionStatus `json:"status,omitempty"`
+}
+
+// DisruptionActionList contains a list of DisruptionAction
+// +kubebuilder:object:root=true
+type DisruptionActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DisruptionAction `json:"items"`
+}
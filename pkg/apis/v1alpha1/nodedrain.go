@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// NodeDrainerKarpenter is the drainer of the NodeDrains that Karpenter drains
+const NodeDrainerKarpenter = v1beta1.Group
+
+// NodeDrainSpec declares that a node should be drained. It mirrors the declarative node drain API that's proposed
+// upstream, so that drain-aware controllers can coordinate with Karpenter's termination flow until it's available.
+type NodeDrainSpec struct {
+	// NodeName is the name of the node to drain
+	// +kubebuilder:validation:XValidation:message="nodeName is immutable",rule="self == oldSelf"
+	// +required
+	NodeName string `json:"nodeName"`
+	// Drainer is the controller that drains the node. Karpenter drains the nodes of NodeDrains without a drainer or with
+	// the karpenter.sh drainer, and waits for other drainers to report that the node is drained.
+	// +optional
+	Drainer string `json:"drainer,omitempty"`
+}
+
+type NodeDrainPhase string
+
+const (
+	// NodeDrainPhasePending is the phase of NodeDrains whose node hasn't started draining
+	NodeDrainPhasePending NodeDrainPhase = "Pending"
+	// NodeDrainPhaseDraining is the phase of NodeDrains whose node has pods that are waiting to be evicted
+	NodeDrainPhaseDraining NodeDrainPhase = "Draining"
+	// NodeDrainPhaseDrained is the phase of NodeDrains whose node is drained
+	NodeDrainPhaseDrained NodeDrainPhase = "Drained"
+)
+
+// NodeDrainStatus is the progress of a node's drain
+type NodeDrainStatus struct {
+	// Phase is how far the node's drain has progressed
+	// +kubebuilder:validation:Enum:={Pending,Draining,Drained}
+	// +optional
+	Phase NodeDrainPhase `json:"phase,omitempty"`
+	// Message explains what the drain is waiting on
+	// +optional
+	Message string `json:"message,omitempty"`
+	// BlockingPods are the pods that the drain is waiting on because they can't be evicted
+	// +optional
+	BlockingPods []NodeDrainBlockingPod `json:"blockingPods,omitempty"`
+}
+
+// NodeDrainBlockingPod is a pod that blocks a node from draining, along with why
+type NodeDrainBlockingPod struct {
+	// Namespace is the namespace of the pod
+	// +required
+	Namespace string `json:"namespace"`
+	// Name is the name of the pod
+	// +required
+	Name string `json:"name"`
+	// Reason explains why the pod can't be evicted
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// NodeDrain is the Schema for the NodeDrains API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodedrains,scope=Cluster,categories=karpenter
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.nodeName",description=""
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Drainer",type="string",JSONPath=".spec.drainer",priority=1,description=""
+type NodeDrain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec   NodeDrainSpec   `json:"spec"`
+	Status NodeDrainStatus `json:"status,omitempty"`
+}
+
+// NodeDrainList contains a list of NodeDrain
+// +kubebuilder:object:root=true
+type NodeDrainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeDrain `json:"items"`
+}
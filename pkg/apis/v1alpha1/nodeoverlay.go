@@ -0,0 +1,87 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeOverlaySpec is the top level nodeoverlay specification. NodeOverlays adjust the instance types returned by the
+// CloudProvider before they're used for scheduling and consolidation, so that operators can tune them without
+// changing the CloudProvider's instance type definitions.
+type NodeOverlaySpec struct {
+	// Requirements select the instance types and offerings that the overlay applies to. An overlay without
+	// requirements applies to every instance type.
+	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	Requirements []v1.NodeSelectorRequirement `json:"requirements,omitempty"`
+	// PriceAdjustment changes the price of matching offerings, either by an absolute amount (e.g. "-0.05") or by a
+	// percentage of the offering's price (e.g. "+10%"). Adjusted prices are never less than zero.
+	// +kubebuilder:validation:Pattern=`^[+-]?(\d+(\.\d+)?|\.\d+)%?$`
+	// +optional
+	PriceAdjustment *string `json:"priceAdjustment,omitempty"`
+	// Reserved is the amount of resources set aside on matching instance types in addition to the overhead
+	// computed by the CloudProvider, e.g. memory for monitoring agents that don't run as daemonsets.
+	// +optional
+	Reserved v1.ResourceList `json:"reserved,omitempty"`
+}
+
+// NodeOverlay is the Schema for the NodeOverlays API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodeoverlays,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Price",type="string",JSONPath=".spec.priceAdjustment",description=""
+type NodeOverlay struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec NodeOverlaySpec `json:"spec"`
+}
+
+// NodeOverlayList contains a list of NodeOverlay
+// +kubebuilder:object:root=true
+type NodeOverlayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeOverlay `json:"items"`
+}
+
+// AdjustPrice returns the price after applying the overlay's price adjustment
+func (in *NodeOverlaySpec) AdjustPrice(price float64) (float64, error) {
+	if in.PriceAdjustment == nil {
+		return price, nil
+	}
+	adjustment := *in.PriceAdjustment
+	percentage := strings.HasSuffix(adjustment, "%")
+	delta, err := strconv.ParseFloat(strings.TrimSuffix(adjustment, "%"), 64)
+	if err != nil {
+		return price, fmt.Errorf("parsing price adjustment %q, %w", adjustment, err)
+	}
+	if percentage {
+		delta = price * delta / 100
+	}
+	if price+delta < 0 {
+		return 0, nil
+	}
+	return price + delta, nil
+}
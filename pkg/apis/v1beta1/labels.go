@@ -30,6 +30,9 @@ const (
 	ArchitectureArm64    = "arm64"
 	CapacityTypeSpot     = "spot"
 	CapacityTypeOnDemand = "on-demand"
+	// CapacityTypeReserved is the capacity type of offerings that launch into capacity that's been reserved with the
+	// cloudprovider ahead of time. Karpenter prefers it over on-demand and spot capacity while reservations have room.
+	CapacityTypeReserved = "capacity-reservation"
 )
 
 // Karpenter specific domains and labels
@@ -43,14 +46,62 @@ const (
 // Karpenter specific annotations
 const (
 	DoNotDisruptAnnotationKey          = Group + "/do-not-disrupt"
+	DoNotConsolidateAnnotationKey      = Group + "/do-not-consolidate"
 	ProviderCompatabilityAnnotationKey = CompatabilityGroup + "/provider"
 	ManagedByAnnotationKey             = Group + "/managed-by"
 	NodePoolHashAnnotationKey          = Group + "/nodepool-hash"
+	PodGroupAnnotationKey              = Group + "/pod-group"
+	PodGroupMinMemberAnnotationKey     = Group + "/pod-group-min-member"
+	HydratedProviderIDAnnotationKey    = Group + "/hydrated-provider-id"
+	WarmPoolAnnotationKey              = Group + "/warm-pool"
+	TraceParentAnnotationKey           = Group + "/traceparent"
+	DisruptionCostAnnotationKey        = Group + "/disruption-cost"
+	DisruptAnnotationKey               = Group + "/disrupt"
+	// DisruptionDryRunAnnotationKey is set to "true" on a NodePool to have the disruption controller record the commands
+	// that it would execute for the NodePool's nodes through events and metrics, without executing them.
+	DisruptionDryRunAnnotationKey = Group + "/disruption-dry-run"
+	// DeviceResourceAnnotationKey is set on a Dynamic Resource Allocation ResourceClass to name the resource that instance
+	// types advertise for the class's devices, e.g. nvidia.com/gpu. It defaults to the class's driver name.
+	DeviceResourceAnnotationKey = Group + "/device-resource"
+	// ExpireAfterAnnotationKey is set on pods to bound the lifetime of the nodes that they're scheduled to. A node
+	// expires once it's older than the shortest lifetime requested by its pods, or its nodepool's expireAfter.
+	ExpireAfterAnnotationKey = Group + "/expire-after"
+	// NominatedNodeAnnotationKey is set on pending pods to the provider id of the inflight or existing node that the
+	// scheduler expects them to bind to, until the time in NominatedUntilAnnotationKey when the nomination expires
+	NominatedNodeAnnotationKey  = Group + "/nominated-node"
+	NominatedUntilAnnotationKey = Group + "/nominated-until"
+	// ReservedResourcesAnnotationKey is set on nodes to resources of their allocatable that Karpenter shouldn't
+	// schedule to, e.g. "cpu=1,memory=2Gi", so that Karpenter coexists with schedulers that bind pods it can't predict
+	ReservedResourcesAnnotationKey = Group + "/reserved-resources"
 )
 
+// Cluster Autoscaler annotations that Karpenter honors, so that workloads migrating from the Cluster Autoscaler don't need
+// to be annotated again
+const (
+	// ClusterAutoscalerSafeToEvictAnnotationKey blocks the disruption of a pod's node when it's set to "false" on the pod
+	ClusterAutoscalerSafeToEvictAnnotationKey = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	// ClusterAutoscalerScaleDownDisabledAnnotationKey blocks the disruption of a node when it's set to "true" on the node
+	ClusterAutoscalerScaleDownDisabledAnnotationKey = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+)
+
+// DisruptAnnotationValueNow requests that a node is gracefully replaced through the disruption controller
+const DisruptAnnotationValueNow = "now"
+
 // Karpenter specific finalizers
 const (
 	TerminationFinalizer = Group + "/termination"
+	// PreTerminationFinalizer registers a pre-termination hook on a NodeClaim. Agents that need to complete before the
+	// instance is deleted, like backup agents or log shippers, add a finalizer that starts with this prefix, e.g.
+	// karpenter.sh/pre-termination-backup, and remove it once they're done. Once the node is drained, the NodeClaim's
+	// PreTerminationHooksCompleted condition is set to False, and the instance isn't deleted until every hook's
+	// finalizer is removed or the pre-termination hook timeout passes.
+	PreTerminationFinalizer = Group + "/pre-termination"
+)
+
+// Karpenter specific pod conditions
+const (
+	// PodFailedSchedulingCondition is set on pods that Karpenter couldn't find capacity for, explaining why
+	PodFailedSchedulingCondition v1.PodConditionType = Group + "/failed-scheduling"
 )
 
 var (
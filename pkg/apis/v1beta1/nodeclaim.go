@@ -35,6 +35,7 @@ type NodeClaimSpec struct {
 	StartupTaints []v1.Taint `json:"startupTaints,omitempty"`
 	// Requirements are layered with GetLabels and applied to every node.
 	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
+	// +kubebuilder:validation:XValidation:message="requirements with operator 'NotMatches' must have a value defined",rule="self.all(x, x.operator == 'NotMatches' ? x.values.size() != 0 : true)"
 	// +kubebuilder:validation:XValidation:message="requirements operator 'Gt' or 'Lt' must have a single positive integer value",rule="self.all(x, (x.operator == 'Gt' || x.operator == 'Lt') ? (x.values.size() == 1 && int(x.values[0]) >= 0) : true)"
 	// +kubebuilder:validation:XValidation:message="requirements with 'minValues' must have at least that many values specified in the 'values' field",rule="self.all(x, (x.operator == 'In' && has(x.minValues)) ? x.values.size() >= x.minValues : true)"
 	// +kubebuilder:validation:MaxItems:=30
@@ -53,6 +54,61 @@ type NodeClaimSpec struct {
 	// NodeClassRef is a reference to an object that defines provider specific configuration
 	// +required
 	NodeClassRef *NodeClassReference `json:"nodeClassRef"`
+	// TerminationGracePeriod is the maximum duration the controller will wait for the node to drain before
+	// terminating the instance, measured from when the node or NodeClaim is first deleted. Pods that block draining,
+	// such as pods that violate a PodDisruptionBudget or have stuck finalizers, won't be waited on past this period.
+	// If omitted, the controller waits indefinitely for the node to drain.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	TerminationGracePeriod *metav1.Duration `json:"terminationGracePeriod,omitempty"`
+	// RegistrationTimeout is how long the controller waits for the NodeClaim's node to register after the NodeClaim is
+	// launched. NodeClaims whose nodes haven't registered within the timeout are deleted and relaunched. Images that
+	// take a long time to boot (e.g. Windows or GPU images) may need a longer timeout. Defaults to 15m.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	RegistrationTimeout *metav1.Duration `json:"registrationTimeout,omitempty" hash:"ignore"`
+	// DevicePluginResources are the extended resources, e.g. nvidia.com/gpu, that device plugins register on the
+	// NodeClaim's node after it becomes ready. The node isn't initialized until every one of them that its instance
+	// type has is registered, so the scheduler keeps using the NodeClaim's capacity for them rather than launching more
+	// capacity for the pods that request them while the device plugins start.
+	// +kubebuilder:validation:MaxItems:=20
+	// +optional
+	DevicePluginResources []v1.ResourceName `json:"devicePluginResources,omitempty" hash:"ignore"`
+	// ReadinessGates are additional conditions that the NodeClaim's node must meet before it's initialized, e.g. that
+	// a CNI daemonset's pod on the node is ready or that a custom node condition is True.
+	// +kubebuilder:validation:MaxItems:=10
+	// +optional
+	ReadinessGates []ReadinessGate `json:"readinessGates,omitempty" hash:"ignore"`
+}
+
+// ReadinessGate is a condition that a NodeClaim's node must meet before it's initialized
+// +kubebuilder:validation:XValidation:message="exactly one of conditionType or daemonSet must be set",rule="has(self.conditionType) != has(self.daemonSet)"
+type ReadinessGate struct {
+	// ConditionType is the type of a node condition that must be True
+	// +optional
+	ConditionType v1.NodeConditionType `json:"conditionType,omitempty"`
+	// DaemonSet is a daemonset whose pod on the node must be Ready
+	// +optional
+	DaemonSet *DaemonSetReference `json:"daemonSet,omitempty"`
+	// Timeout is how long the controller waits for the gate after the NodeClaim's node registers. NodeClaims whose
+	// nodes haven't met the gate within the timeout are deleted and relaunched. If omitted, the controller waits
+	// indefinitely.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// DaemonSetReference refers to a daemonset by its namespace and name
+type DaemonSetReference struct {
+	// Namespace of the daemonset
+	// +required
+	Namespace string `json:"namespace"`
+	// Name of the daemonset
+	// +required
+	Name string `json:"name"`
 }
 
 // A node selector requirement with min values is a selector that contains values, a key, an operator that relates the key and values
@@ -67,12 +123,23 @@ type NodeSelectorRequirementWithMinValues struct {
 	MinValues *int `json:"minValues,omitempty"`
 }
 
+// NodeSelectorOpNotMatches excludes the values that match any of the requirement's values, which are regular expressions
+// that must match the whole value, e.g. ".*metal.*". It saves listing every excluded value of large sets, such as the
+// instance types of a family, with the NotIn operator.
+const NodeSelectorOpNotMatches v1.NodeSelectorOperator = "NotMatches"
+
 // ResourceRequirements models the required resources for the NodeClaim to launch
 // Ths will eventually be transformed into v1.ResourceRequirements when we support resources.limits
 type ResourceRequirements struct {
 	// Requests describes the minimum required resources for the NodeClaim to launch
 	// +optional
 	Requests v1.ResourceList `json:"requests,omitempty"`
+	// Reserved describes resources that are held back from the instance type's allocatable when scheduling pods to
+	// the NodeClaim. This can be used to leave headroom on nodes for things that aren't modeled as pod requests
+	// (e.g. container logs and images on ephemeral storage).
+	// +kubebuilder:validation:XValidation:message="reserved value cannot be a negative resource quantity",rule="self.all(x, !self[x].startsWith('-'))"
+	// +optional
+	Reserved v1.ResourceList `json:"reserved,omitempty"`
 }
 
 // KubeletConfiguration defines args to be used when configuring kubelet on provisioned nodes.
@@ -41,6 +41,10 @@ type NodeClaimStatus struct {
 	// Conditions contains signals for health and readiness
 	// +optional
 	Conditions apis.Conditions `json:"conditions,omitempty"`
+	// DriftedReasons are the specific reasons that the NodeClaim has drifted from its NodePool or NodeClass.
+	// This is only set while the NodeClaim is drifted.
+	// +optional
+	DriftedReasons []string `json:"driftedReasons,omitempty"`
 }
 
 func (in *NodeClaim) StatusConditions() apis.ConditionManager {
@@ -64,6 +68,16 @@ var (
 	Empty       apis.ConditionType = "Empty"
 	Drifted     apis.ConditionType = "Drifted"
 	Expired     apis.ConditionType = "Expired"
+	Stopped     apis.ConditionType = "Stopped"
+	// Drained is false while the NodeClaim's node is being drained, with the pods that are still waiting to be evicted,
+	// and is set once the node is drained
+	Drained apis.ConditionType = "Drained"
+	// TerminationBlocked is set while the NodeClaim's node has been blocked from draining for a while by pods whose PDBs
+	// don't allow a disruption or that have the do-not-disrupt annotation, and lists the pods that are blocking it
+	TerminationBlocked apis.ConditionType = "TerminationBlocked"
+	// PreTerminationHooksCompleted is set once the NodeClaim's node is drained, and is false while the NodeClaim's
+	// pre-termination hooks haven't completed
+	PreTerminationHooksCompleted apis.ConditionType = "PreTerminationHooksCompleted"
 )
 
 func (in *NodeClaim) GetConditions() apis.Conditions {
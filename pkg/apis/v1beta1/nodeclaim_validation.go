@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -41,6 +42,7 @@ var (
 		string(v1.NodeSelectorOpLt),
 		string(v1.NodeSelectorOpExists),
 		string(v1.NodeSelectorOpDoesNotExist),
+		string(NodeSelectorOpNotMatches),
 	)
 
 	SupportedReservedResources = sets.NewString(
@@ -156,11 +158,17 @@ func ValidateRequirement(requirement NodeSelectorRequirementWithMinValues) error
 		errs = multierr.Append(errs, fmt.Errorf("key %s is not a qualified name, %s", requirement.Key, err))
 	}
 	for _, value := range requirement.Values {
+		if requirement.Operator == NodeSelectorOpNotMatches {
+			if _, err := CompileNotMatchesPattern(value); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("invalid pattern %s for key %s, %s", value, requirement.Key, err))
+			}
+			continue
+		}
 		for _, err := range validation.IsValidLabelValue(value) {
 			errs = multierr.Append(errs, fmt.Errorf("invalid value %s for key %s, %s", value, requirement.Key, err))
 		}
 	}
-	if requirement.Operator == v1.NodeSelectorOpIn && len(requirement.Values) == 0 {
+	if (requirement.Operator == v1.NodeSelectorOpIn || requirement.Operator == NodeSelectorOpNotMatches) && len(requirement.Values) == 0 {
 		errs = multierr.Append(errs, fmt.Errorf("key %s with operator %s must have a value defined", requirement.Key, requirement.Operator))
 	}
 
@@ -181,6 +189,12 @@ func ValidateRequirement(requirement NodeSelectorRequirementWithMinValues) error
 	return errs
 }
 
+// CompileNotMatchesPattern compiles a value of a requirement with the NotMatches operator. Patterns use the RE2 syntax
+// and must match the whole label value.
+func CompileNotMatchesPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+}
+
 func (in *KubeletConfiguration) validate() (errs *apis.FieldError) {
 	if in == nil {
 		return
@@ -96,6 +96,50 @@ var _ = Describe("Validation", func() {
 			Expect(env.Client.Create(ctx, nodeClaim)).To(Succeed())
 		})
 	})
+	Context("TerminationGracePeriod", func() {
+		It("should succeed on a valid terminationGracePeriod", func() {
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Hour}
+			Expect(env.Client.Create(ctx, nodeClaim)).To(Succeed())
+		})
+		It("should fail on a negative terminationGracePeriod", func() {
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: -time.Second}
+			Expect(env.Client.Create(ctx, nodeClaim)).ToNot(Succeed())
+		})
+	})
+	Context("RegistrationTimeout", func() {
+		It("should succeed on a valid registrationTimeout", func() {
+			nodeClaim.Spec.RegistrationTimeout = &metav1.Duration{Duration: time.Hour}
+			Expect(env.Client.Create(ctx, nodeClaim)).To(Succeed())
+		})
+		It("should fail on a negative registrationTimeout", func() {
+			nodeClaim.Spec.RegistrationTimeout = &metav1.Duration{Duration: -time.Second}
+			Expect(env.Client.Create(ctx, nodeClaim)).ToNot(Succeed())
+		})
+	})
+	Context("ReadinessGates", func() {
+		It("should succeed on valid readiness gates", func() {
+			nodeClaim.Spec.ReadinessGates = []ReadinessGate{
+				{ConditionType: "example.com/NetworkReady", Timeout: &metav1.Duration{Duration: 10 * time.Minute}},
+				{DaemonSet: &DaemonSetReference{Namespace: "kube-system", Name: "cni"}},
+			}
+			Expect(env.Client.Create(ctx, nodeClaim)).To(Succeed())
+		})
+		It("should fail when a readiness gate has neither a conditionType nor a daemonSet", func() {
+			nodeClaim.Spec.ReadinessGates = []ReadinessGate{{Timeout: &metav1.Duration{Duration: time.Minute}}}
+			Expect(env.Client.Create(ctx, nodeClaim)).ToNot(Succeed())
+		})
+		It("should fail when a readiness gate has both a conditionType and a daemonSet", func() {
+			nodeClaim.Spec.ReadinessGates = []ReadinessGate{{
+				ConditionType: "example.com/NetworkReady",
+				DaemonSet:     &DaemonSetReference{Namespace: "kube-system", Name: "cni"},
+			}}
+			Expect(env.Client.Create(ctx, nodeClaim)).ToNot(Succeed())
+		})
+		It("should fail on a negative timeout", func() {
+			nodeClaim.Spec.ReadinessGates = []ReadinessGate{{ConditionType: "example.com/NetworkReady", Timeout: &metav1.Duration{Duration: -time.Second}}}
+			Expect(env.Client.Create(ctx, nodeClaim)).ToNot(Succeed())
+		})
+	})
 	Context("Requirements", func() {
 		It("should allow supported ops", func() {
 			nodeClaim.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
@@ -154,6 +154,21 @@ var _ = Describe("Validation", func() {
 			nodeClaim.Spec.Requirements = []NodeSelectorRequirementWithMinValues{}
 			Expect(nodeClaim.Validate(ctx)).To(Succeed())
 		})
+		It("should allow valid NotMatches patterns", func() {
+			nodeClaim.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{".*metal.*", `m5\.(2|4)xlarge`}}},
+			}
+			Expect(nodeClaim.Validate(ctx)).To(Succeed())
+		})
+		It("should fail with invalid NotMatches patterns", func() {
+			for _, requirement := range []NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{}}},
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{"metal("}}},
+			} {
+				nodeClaim.Spec.Requirements = []NodeSelectorRequirementWithMinValues{requirement}
+				Expect(nodeClaim.Validate(ctx)).ToNot(Succeed())
+			}
+		})
 		It("should fail with invalid GT or LT values", func() {
 			for _, requirement := range []NodeSelectorRequirementWithMinValues{
 				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{}}},
@@ -22,6 +22,7 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/mitchellh/hashstructure/v2"
 	"github.com/robfig/cron/v3"
@@ -52,6 +53,53 @@ type NodePoolSpec struct {
 	// Limits define a set of bounds for provisioning capacity.
 	// +optional
 	Limits Limits `json:"limits,omitempty"`
+	// MaxConcurrentLaunches bounds the number of NodeClaims from this nodepool that are launched with the cloud provider
+	// at the same time, so that large scheduling rounds don't exhaust the cloud provider's API rate limits. Launches are
+	// unbounded if it isn't set.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	MaxConcurrentLaunches *int32 `json:"maxConcurrentLaunches,omitempty"`
+	// MinNodes is the minimum number of nodes that the nodepool maintains, even when there are no pending pods that
+	// need them. Karpenter launches nodes from the template to make up any shortfall, and consolidation won't
+	// remove nodes from the nodepool once it's at this count.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	MinNodes *int32 `json:"minNodes,omitempty"`
+	// OverflowNodePool is the name of the nodepool that pods overflow to once this nodepool's limits are exhausted. It's
+	// tried before the nodepools that are next by weight, so that overflow goes to a designated burst nodepool. Pods
+	// that don't fit on the overflow nodepool fall back to the remaining nodepools by weight.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	OverflowNodePool string `json:"overflowNodePool,omitempty"`
+	// Schedule is a list of scaling windows that raise the nodepool's minimum number of nodes while they're active.
+	// This launches capacity ahead of known spikes in demand, and once a window ends, the nodes that it launched are
+	// consolidated as normal.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Schedule []ScalingWindow `json:"schedule,omitempty"`
+	// Headroom is spare capacity that the nodepool keeps schedulable for pods that haven't been created yet. Karpenter
+	// launches nodes so that the headroom would fit on the nodepool's nodes alongside the pods that are running on them,
+	// and consolidation won't remove capacity that the headroom needs.
+	// +optional
+	Headroom *Headroom `json:"headroom,omitempty"`
+	// WarmPool configures a pool of NodeClaims that are launched, initialized and then stopped ahead of time, so that
+	// they can be started for pending pods more quickly than new capacity can be launched. The warm pool is only
+	// maintained for cloud providers that are able to stop and start instances.
+	// +optional
+	WarmPool *WarmPool `json:"warmPool,omitempty"`
+	// CapacityTypeDistribution is the target ratio between the nodepool's spot and on-demand nodes. Karpenter launches
+	// each new node with the capacity type that's furthest below its target share, and only falls back to the other
+	// capacity type when pods aren't compatible with it or it isn't available.
+	// +kubebuilder:validation:XValidation:message="spot and onDemand cannot both be zero",rule="self.spot + self.onDemand > 0"
+	// +optional
+	CapacityTypeDistribution *CapacityTypeDistribution `json:"capacityTypeDistribution,omitempty"`
+	// TopologyKeys are node labels, in addition to the zone, hostname and capacity type, that the scheduler treats as
+	// topology domains for pod topology spread and pod (anti-)affinity, e.g. a rack or a placement group. The domains of
+	// each key are the ones that the cloud provider offers the nodepool's instance types in.
+	// +kubebuilder:validation:XValidation:message="topologyKeys cannot use a restricted label domain, the zone, hostname and capacity type are always topology domains",rule=`self.all(k, k.find("^([^/]+)").endsWith("node.kubernetes.io") || k.find("^([^/]+)").endsWith("node-restriction.kubernetes.io") || k.find("^([^/]+)").endsWith("kops.k8s.io") || !(k.find("^([^/]+)").endsWith("kubernetes.io") || k.find("^([^/]+)").endsWith("k8s.io") || k.find("^([^/]+)").endsWith("karpenter.sh")))`
+	// +kubebuilder:validation:MaxItems:=10
+	// +optional
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
 	// Weight is the priority given to the nodepool during scheduling. A higher
 	// numerical weight indicates that this nodepool will be ordered
 	// ahead of other nodepools with lower weights. A nodepool with no weight
@@ -60,6 +108,65 @@ type NodePoolSpec struct {
 	// +kubebuilder:validation:Maximum:=100
 	// +optional
 	Weight *int32 `json:"weight,omitempty"`
+	// PreemptionPolicy describes whether this nodepool launches capacity for pending pods that kube-scheduler
+	// could schedule by preempting lower priority pods on existing nodes. "Provision" always launches capacity
+	// for these pods, while "Preempt" leaves them to be scheduled through preemption.
+	// This policy defaults to "Provision" if not specified.
+	// +kubebuilder:validation:Enum:={Provision,Preempt}
+	// +optional
+	PreemptionPolicy PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	// ScaleUpDeadline is how long pods can stay pending while the nodepool can't launch capacity for them, because its
+	// limits are exhausted or none of its instance types have capacity, before the nodepool is marked with the
+	// ScaleUpStalled status condition. Stalled scale-ups are only reported for nodepools that set a deadline.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+)$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	ScaleUpDeadline *metav1.Duration `json:"scaleUpDeadline,omitempty"`
+}
+
+// Headroom is a number of equally sized units of spare capacity.
+type Headroom struct {
+	// Count is the number of units of spare capacity. Each unit must fit on a single node.
+	// +kubebuilder:validation:Minimum:=1
+	// +required
+	Count int32 `json:"count"`
+	// Resources are the resources that each unit of spare capacity requests.
+	// +kubebuilder:validation:XValidation:message="resources value cannot be a negative resource quantity",rule="self.all(x, !self[x].startsWith('-'))"
+	// +required
+	Resources v1.ResourceList `json:"resources"`
+}
+
+type WarmPool struct {
+	// Size is the number of stopped NodeClaims that the nodepool keeps in its warm pool. NodeClaims that are
+	// claimed from the warm pool are replaced.
+	// +kubebuilder:validation:Minimum:=0
+	// +required
+	Size int32 `json:"size"`
+}
+
+// CapacityTypeDistribution is a ratio between capacity types, e.g. spot=70 and onDemand=30 targets seven spot nodes for
+// every three on-demand nodes.
+type CapacityTypeDistribution struct {
+	// Spot is the share of the nodepool's nodes that are launched as spot capacity
+	// +kubebuilder:validation:Minimum:=0
+	// +required
+	Spot int32 `json:"spot"`
+	// OnDemand is the share of the nodepool's nodes that are launched as on-demand capacity, including the ones that
+	// are launched into capacity reservations
+	// +kubebuilder:validation:Minimum:=0
+	// +required
+	OnDemand int32 `json:"onDemand"`
+}
+
+// Weight returns the share of the capacity type, or zero if the distribution doesn't define it
+func (in *CapacityTypeDistribution) Weight(capacityType string) int32 {
+	switch capacityType {
+	case CapacityTypeSpot:
+		return in.Spot
+	case CapacityTypeOnDemand:
+		return in.OnDemand
+	}
+	return 0
 }
 
 type Disruption struct {
@@ -87,6 +194,28 @@ type Disruption struct {
 	// +kubebuilder:validation:Schemaless
 	// +optional
 	ExpireAfter NillableDuration `json:"expireAfter"`
+	// DriftCheckInterval is how often Karpenter re-evaluates the NodeClaims owned
+	// by this NodePool for drift against the NodePool and NodeClass. Longer intervals
+	// reduce API calls to the cloud provider at the cost of detecting drift later.
+	// If omitted, this defaults to 5 minutes.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+)$`
+	// +kubebuilder:validation:Type="string"
+	// +kubebuilder:validation:XValidation:message="driftCheckInterval must be greater than 0",rule="duration(self) > duration('0s')"
+	// +optional
+	DriftCheckInterval *metav1.Duration `json:"driftCheckInterval,omitempty" hash:"ignore"`
+	// DriftChecks enables or disables each of the checks that mark the NodeClaims owned by this
+	// NodePool as drifted, e.g. to roll out new node images without replacing nodes whose
+	// requirements no longer match. Checks that aren't set are enabled.
+	// +optional
+	DriftChecks *DriftChecks `json:"driftChecks,omitempty" hash:"ignore"`
+	// SpotToSpotPriceImprovementPercent lets single-node consolidation replace a spot node
+	// with a spot node that's at least this percentage cheaper, even when there aren't
+	// 15 cheaper instance types to launch the replacement from. Higher values trade
+	// savings for less churn. Only used when the SpotToSpotConsolidation feature gate is enabled.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=99
+	// +optional
+	SpotToSpotPriceImprovementPercent *int32 `json:"spotToSpotPriceImprovementPercent,omitempty" hash:"ignore"`
 	// Budgets is a list of Budgets.
 	// If there are multiple active budgets, Karpenter uses
 	// the most restrictive value. If left undefined,
@@ -98,6 +227,26 @@ type Disruption struct {
 	Budgets []Budget `json:"budgets,omitempty" hash:"ignore"`
 }
 
+// DriftChecks enables or disables each of the checks for drift. Drift that none of
+// these checks cover, e.g. of the NodePool's labels or kubelet configuration, is always detected.
+type DriftChecks struct {
+	// NodeImage enables drift of the node image that the cloud provider reports,
+	// e.g. when a newer image is released.
+	// +optional
+	NodeImage *bool `json:"nodeImage,omitempty"`
+	// Requirements enables drift of NodeClaims whose labels no longer match the
+	// NodePool's requirements.
+	// +optional
+	Requirements *bool `json:"requirements,omitempty"`
+	// Taints enables drift of the NodePool's taints and startup taints.
+	// +optional
+	Taints *bool `json:"taints,omitempty"`
+	// NodeClassHash enables drift of the NodeClass that the cloud provider reports
+	// when the NodeClass's hash changes.
+	// +optional
+	NodeClassHash *bool `json:"nodeClassHash,omitempty"`
+}
+
 // Budget defines when Karpenter will restrict the
 // number of Node Claims that can be terminating simultaneously.
 type Budget struct {
@@ -128,6 +277,53 @@ type Budget struct {
 	// +kubebuilder:validation:Type="string"
 	// +optional
 	Duration *metav1.Duration `json:"duration,omitempty" hash:"ignore"`
+	// Reasons is a list of disruption reasons that this budget applies to.
+	// If omitted, the budget applies to all disruption reasons.
+	// +kubebuilder:validation:MaxItems=6
+	// +optional
+	Reasons []DisruptionReason `json:"reasons,omitempty" hash:"ignore"`
+}
+
+// ScalingWindow defines a recurring window of time during which a NodePool maintains a minimum number of nodes.
+type ScalingWindow struct {
+	// Schedule specifies when the window begins being active, following
+	// the upstream cronjob syntax. Timezones are not supported.
+	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
+	// +required
+	Schedule string `json:"schedule"`
+	// Duration determines how long the window is active since each Schedule hit.
+	// Only minutes and hours are accepted, as cron does not work in seconds.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(m|h)+(0s)?)$`
+	// +kubebuilder:validation:Type="string"
+	// +required
+	Duration metav1.Duration `json:"duration"`
+	// MinNodes is the minimum number of nodes that the nodepool maintains while the window is active.
+	// +kubebuilder:validation:Minimum:=0
+	// +required
+	MinNodes int32 `json:"minNodes"`
+}
+
+// DisruptionReason defines valid reasons for disruption budgets.
+// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted,Expired,Resized,Requested}
+type DisruptionReason string
+
+const (
+	DisruptionReasonUnderutilized DisruptionReason = "Underutilized"
+	DisruptionReasonEmpty         DisruptionReason = "Empty"
+	DisruptionReasonDrifted       DisruptionReason = "Drifted"
+	DisruptionReasonExpired       DisruptionReason = "Expired"
+	DisruptionReasonResized       DisruptionReason = "Resized"
+	DisruptionReasonRequested     DisruptionReason = "Requested"
+)
+
+// SupportedDisruptionReasons are the reasons that a budget can be scoped to
+var SupportedDisruptionReasons = []DisruptionReason{
+	DisruptionReasonUnderutilized,
+	DisruptionReasonEmpty,
+	DisruptionReasonDrifted,
+	DisruptionReasonExpired,
+	DisruptionReasonResized,
+	DisruptionReasonRequested,
 }
 
 type ConsolidationPolicy string
@@ -137,6 +333,20 @@ const (
 	ConsolidationPolicyWhenUnderutilized ConsolidationPolicy = "WhenUnderutilized"
 )
 
+type PreemptionPolicy string
+
+const (
+	PreemptionPolicyProvision PreemptionPolicy = "Provision"
+	PreemptionPolicyPreempt   PreemptionPolicy = "Preempt"
+)
+
+type SyncPolicy string
+
+const (
+	SyncPolicyRegistration SyncPolicy = "Registration"
+	SyncPolicyContinuous   SyncPolicy = "Continuous"
+)
+
 type Limits v1.ResourceList
 
 func (l Limits) ExceededBy(resources v1.ResourceList) error {
@@ -173,13 +383,26 @@ type ObjectMeta struct {
 	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// SyncPolicy describes how the labels of the template and the taints of its spec are applied to nodes. "Registration"
+	// only applies them when the node registers, while "Continuous" keeps them in sync with the node, restoring any that
+	// are removed from it. This policy defaults to "Registration" if not specified.
+	// +kubebuilder:validation:Enum:={Registration,Continuous}
+	// +optional
+	SyncPolicy SyncPolicy `json:"syncPolicy,omitempty" hash:"ignore"`
 }
 
 // NodePool is the Schema for the NodePools API
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=nodepools,scope=Cluster,categories=karpenter
 // +kubebuilder:printcolumn:name="NodeClass",type="string",JSONPath=".spec.template.spec.nodeClassRef.name",description=""
+// +kubebuilder:printcolumn:name="Nodes",type="integer",JSONPath=".status.nodeCount",description=""
+// +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".status.resources.cpu",description=""
+// +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".status.resources.memory",description=""
 // +kubebuilder:printcolumn:name="Weight",type="string",JSONPath=".spec.weight",priority=1,description=""
+// +kubebuilder:printcolumn:name="NodeClaims",type="integer",JSONPath=".status.nodeClaimCount",priority=1,description=""
+// +kubebuilder:printcolumn:name="CPU Limit",type="string",JSONPath=".spec.limits.cpu",priority=1,description=""
+// +kubebuilder:printcolumn:name="Memory Limit",type="string",JSONPath=".spec.limits.memory",priority=1,description=""
 // +kubebuilder:subresource:status
 type NodePool struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -223,11 +446,11 @@ func (nl *NodePoolList) OrderByWeight() {
 	})
 }
 
-// MustGetAllowedDisruptions calls GetAllowedDisruptions and returns 0 if the error is not nil. This reduces the
-// amount of state that the disruption controller must reconcile, while allowing the GetAllowedDisruptions()
+// MustGetAllowedDisruptions calls GetAllowedDisruptionsByReason and returns 0 if the error is not nil. This reduces the
+// amount of state that the disruption controller must reconcile, while allowing the GetAllowedDisruptionsByReason()
 // to bubble up any errors in validation.
-func (in *NodePool) MustGetAllowedDisruptions(ctx context.Context, c clock.Clock, numNodes int) int {
-	val, err := in.GetAllowedDisruptions(ctx, c, numNodes)
+func (in *NodePool) MustGetAllowedDisruptions(ctx context.Context, c clock.Clock, numNodes int, reason DisruptionReason, deleting map[DisruptionReason]int) int {
+	val, err := in.GetAllowedDisruptionsByReason(ctx, c, numNodes, reason, deleting)
 	if err != nil {
 		return 0
 	}
@@ -249,6 +472,38 @@ func (in *NodePool) GetAllowedDisruptions(ctx context.Context, c clock.Clock, nu
 	return minVal, multiErr
 }
 
+// GetAllowedDisruptionsByReason returns the number of nodes of a given node pool that can be disrupted for the given
+// reason, across the disruption budgets that apply to it. Budgets that don't specify any reasons apply to every reason.
+// Each budget is reduced by the deleting nodes, which are keyed by the reason that they're being disrupted for, except
+// for the nodes that are being disrupted for reasons that the budget doesn't apply to. Nodes that are deleting for any
+// other cause are keyed by the empty reason and count against every budget.
+// This will return an error if there is a configuration error with any of those budgets' node or schedule values.
+func (in *NodePool) GetAllowedDisruptionsByReason(_ context.Context, c clock.Clock, numNodes int, reason DisruptionReason, deleting map[DisruptionReason]int) (int, error) {
+	minVal := math.MaxInt32
+	var multiErr error
+	for i := range in.Spec.Disruption.Budgets {
+		budget := &in.Spec.Disruption.Budgets[i]
+		if !budget.AppliesTo(reason) {
+			continue
+		}
+		val, err := budget.GetAllowedDisruptions(c, numNodes)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+		counted := 0
+		for r, n := range deleting {
+			if r == "" || budget.AppliesTo(r) {
+				counted += n
+			}
+		}
+		minVal = min(minVal, val-counted)
+	}
+	// Floor the value since the number of deleting nodes can exceed the number of allowed disruptions.
+	// Allowing this value to be negative breaks assumptions in the code used to calculate how
+	// many nodes can be disrupted.
+	return lo.Clamp(minVal, 0, math.MaxInt32), multiErr
+}
+
 // GetAllowedDisruptions returns an intstr.IntOrString that can be used a comparison
 // for calculating if a disruption action is allowed. It returns an error if the
 // schedule is invalid. This returns MAXINT if the value is unbounded.
@@ -276,6 +531,12 @@ func (in *Budget) GetAllowedDisruptions(c clock.Clock, numNodes int) (int, error
 	return res, nil
 }
 
+// AppliesTo returns true if the budget restricts disruptions for the given reason. A budget with
+// no reasons applies to all of them.
+func (in *Budget) AppliesTo(reason DisruptionReason) bool {
+	return len(in.Reasons) == 0 || lo.Contains(in.Reasons, reason)
+}
+
 // IsActive takes a clock as input and returns if a budget is active.
 // It walks back in time the time.Duration associated with the schedule,
 // and checks if the next time the schedule will hit is before the current time.
@@ -286,15 +547,45 @@ func (in *Budget) IsActive(c clock.Clock) (bool, error) {
 	if in.Schedule == nil && in.Duration == nil {
 		return true, nil
 	}
-	schedule, err := cron.ParseStandard(fmt.Sprintf("TZ=UTC %s", lo.FromPtr(in.Schedule)))
+	return isScheduleActive(c, lo.FromPtr(in.Schedule), lo.FromPtr(in.Duration).Duration)
+}
+
+// IsActive returns whether the scaling window is active at the clock's current time.
+func (in *ScalingWindow) IsActive(c clock.Clock) (bool, error) {
+	return isScheduleActive(c, in.Schedule, in.Duration.Duration)
+}
+
+// GetMinNodes returns the minimum number of nodes that the nodepool maintains at the clock's current time. This is
+// the largest of spec.minNodes and the minNodes of each scaling window that's active.
+// This will return an error if any scaling window's schedule is invalid.
+func (in *NodePool) GetMinNodes(c clock.Clock) (int, error) {
+	minNodes := int(lo.FromPtr(in.Spec.MinNodes))
+	var multiErr error
+	for i := range in.Spec.Schedule {
+		active, err := in.Spec.Schedule[i].IsActive(c)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+			continue
+		}
+		if active {
+			minNodes = max(minNodes, int(in.Spec.Schedule[i].MinNodes))
+		}
+	}
+	return minNodes, multiErr
+}
+
+// isScheduleActive walks back in time the duration associated with the schedule, and checks if the next time the
+// schedule will hit is before the current time.
+func isScheduleActive(c clock.Clock, schedule string, duration time.Duration) (bool, error) {
+	sched, err := cron.ParseStandard(fmt.Sprintf("TZ=UTC %s", schedule))
 	if err != nil {
 		// Should only occur if there's a discrepancy
 		// with the validation regex and the cron package.
 		return false, fmt.Errorf("invariant violated, invalid cron %s", schedule)
 	}
 	// Walk back in time for the duration associated with the schedule
-	checkPoint := c.Now().UTC().Add(-lo.FromPtr(in.Duration).Duration)
-	nextHit := schedule.Next(checkPoint)
+	checkPoint := c.Now().UTC().Add(-duration)
+	nextHit := sched.Next(checkPoint)
 	return !nextHit.After(c.Now().UTC()), nil
 }
 
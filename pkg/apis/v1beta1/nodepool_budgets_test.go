@@ -72,14 +72,14 @@ var _ = Describe("Budgets", func() {
 	})
 	Context("MustGetAllowedDisruptions", func() {
 		It("should return the min allowedDisruptions", func() {
-			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100)
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, nil)
 			Expect(min).To(BeNumerically("==", 10))
 		})
 		It("should return the min allowedDisruptions, ignoring inactive crons", func() {
 			// Make the first and third budgets inactive
 			budgets[0].Schedule = lo.ToPtr("@yearly")
 			budgets[2].Schedule = lo.ToPtr("@yearly")
-			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100)
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, nil)
 			Expect(min).To(BeNumerically("==", 100))
 		})
 		It("should return MaxInt32 if all crons are inactive", func() {
@@ -87,12 +87,42 @@ var _ = Describe("Budgets", func() {
 			budgets[1].Schedule = lo.ToPtr("@yearly")
 			budgets[2].Schedule = lo.ToPtr("@yearly")
 			budgets[3].Schedule = lo.ToPtr("@yearly")
-			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100)
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, nil)
 			Expect(min).To(BeNumerically("==", math.MaxInt32))
 		})
 		It("should return zero values if a schedule is invalid", func() {
 			budgets[0].Schedule = lo.ToPtr("@wrongly")
-			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100)
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, nil)
+			Expect(min).To(BeNumerically("==", 0))
+		})
+		It("should ignore budgets that don't apply to the reason", func() {
+			budgets[0].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			budgets[2].Reasons = []DisruptionReason{DisruptionReasonUnderutilized, DisruptionReasonEmpty}
+			budgets[3].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonExpired, nil)
+			Expect(min).To(BeNumerically("==", 100))
+			min = nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonUnderutilized, nil)
+			Expect(min).To(BeNumerically("==", 10))
+		})
+		It("should consider budgets that specify the reason", func() {
+			budgets[0].Reasons = []DisruptionReason{DisruptionReasonDrifted}
+			budgets[2].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			budgets[3].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, nil)
+			Expect(min).To(BeNumerically("==", 10))
+		})
+		It("should subtract the deleting nodes from the budgets that apply to their reason", func() {
+			budgets[0].Reasons = []DisruptionReason{DisruptionReasonDrifted}
+			budgets[2].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			budgets[3].Reasons = []DisruptionReason{DisruptionReasonUnderutilized}
+			// Only the nodes that are being disrupted for drift count against the drift budget
+			min := nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, map[DisruptionReason]int{DisruptionReasonUnderutilized: 5, DisruptionReasonDrifted: 2})
+			Expect(min).To(BeNumerically("==", 8))
+			// Nodes that are deleting for any other cause count against every budget
+			min = nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, map[DisruptionReason]int{"": 3, DisruptionReasonUnderutilized: 5})
+			Expect(min).To(BeNumerically("==", 7))
+			// The allowed disruptions can't be negative
+			min = nodePool.MustGetAllowedDisruptions(ctx, fakeClock, 100, DisruptionReasonDrifted, map[DisruptionReason]int{"": 20})
 			Expect(min).To(BeNumerically("==", 0))
 		})
 	})
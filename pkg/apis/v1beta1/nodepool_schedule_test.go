@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Pallinder/go-randomdata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+var _ = Describe("Schedule", func() {
+	var nodePool *NodePool
+	var fakeClock *clock.FakeClock
+
+	BeforeEach(func() {
+		// Set the time to a Monday at 8:30am
+		fakeClock = clock.NewFakeClock(time.Date(2024, time.January, 8, 8, 30, 0, 0, time.UTC))
+		nodePool = &NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: strings.ToLower(randomdata.SillyName())},
+			Spec: NodePoolSpec{
+				MinNodes: lo.ToPtr[int32](2),
+			},
+		}
+	})
+	Context("GetMinNodes", func() {
+		It("should return the nodepool's minNodes without a schedule", func() {
+			minNodes, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(minNodes).To(Equal(2))
+		})
+		It("should return the minNodes of an active scaling window", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{
+				{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 10},
+			}
+			minNodes, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(minNodes).To(Equal(10))
+		})
+		It("should ignore scaling windows that aren't active", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{
+				{Schedule: "0 8 * * SAT", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 10},
+				{Schedule: "0 9 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 20},
+			}
+			minNodes, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(minNodes).To(Equal(2))
+		})
+		It("should return the largest minNodes across the active scaling windows", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{
+				{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 10},
+				{Schedule: "0 * * * *", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 5},
+			}
+			minNodes, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(minNodes).To(Equal(10))
+		})
+		It("should not go below the nodepool's minNodes during a scaling window", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{
+				{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 1},
+			}
+			minNodes, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(minNodes).To(Equal(2))
+		})
+		It("should return an error for an invalid schedule", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{
+				{Schedule: "invalid", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 10},
+			}
+			_, err := nodePool.GetMinNodes(fakeClock)
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
 )
 
 // NodePoolStatus defines the observed state of NodePool
@@ -25,4 +26,38 @@ type NodePoolStatus struct {
 	// Resources is the list of resources that have been provisioned.
 	// +optional
 	Resources v1.ResourceList `json:"resources,omitempty"`
+	// NodeClaimCount is the number of launched NodeClaims that the NodePool owns, not counting the ones that are being
+	// deleted
+	// +optional
+	NodeClaimCount int32 `json:"nodeClaimCount,omitempty"`
+	// NodeCount is the number of nodes that the NodePool owns, not counting the ones that are being deleted
+	// +optional
+	NodeCount int32 `json:"nodeCount,omitempty"`
+	// Conditions contains signals for health and readiness
+	// +optional
+	Conditions apis.Conditions `json:"conditions,omitempty"`
+}
+
+var (
+	// Unhealthy is set on a NodePool whose NodeClaims have repeatedly failed to launch. Launches for the NodePool back
+	// off and the NodePool is deprioritized in scheduling until one of its launches succeeds.
+	Unhealthy apis.ConditionType = "Unhealthy"
+	// NodeClaimTemplateInvalid is set on a NodePool whose NodeClaim template the cloud provider would fail to launch,
+	// e.g. because none of its instance types can be resolved or its image doesn't exist
+	NodeClaimTemplateInvalid apis.ConditionType = "NodeClaimTemplateInvalid"
+	// ScaleUpStalled is set on a NodePool that hasn't been able to launch capacity for pending pods for longer than its
+	// scale-up deadline, because its limits are exhausted or none of its instance types have capacity
+	ScaleUpStalled apis.ConditionType = "ScaleUpStalled"
+)
+
+func (in *NodePool) StatusConditions() apis.ConditionManager {
+	return apis.NewLivingConditionSet().Manage(in)
+}
+
+func (in *NodePool) GetConditions() apis.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *NodePool) SetConditions(conditions apis.Conditions) {
+	in.Status.Conditions = conditions
 }
@@ -55,13 +55,54 @@ func (in *NodePoolSpec) validate() (errs *apis.FieldError) {
 	return errs.Also(
 		in.Template.validate().ViaField("template"),
 		in.Disruption.validate().ViaField("deprovisioning"),
+		in.validateSchedule().ViaField("schedule"),
+		in.validateTopologyKeys().ViaField("topologyKeys"),
+		in.validateScaleUpDeadline().ViaField("scaleUpDeadline"),
 	)
 }
 
+func (in *NodePoolSpec) validateScaleUpDeadline() (errs *apis.FieldError) {
+	if in.ScaleUpDeadline != nil && in.ScaleUpDeadline.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(in.ScaleUpDeadline.Duration.String(), "", "must be a positive duration"))
+	}
+	return errs
+}
+
+func (in *NodePoolSpec) validateTopologyKeys() (errs *apis.FieldError) {
+	for i, key := range in.TopologyKeys {
+		for _, err := range validation.IsQualifiedName(key) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(fmt.Sprintf("%s, %s", key, err), "", i))
+		}
+		// The zone, hostname and capacity type are always topology domains, and the other restricted labels can't be
+		// set by Karpenter
+		if IsRestrictedNodeLabel(key) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(fmt.Sprintf("%s is restricted", key), "", i))
+		}
+	}
+	return errs
+}
+
+func (in *NodePoolSpec) validateSchedule() (errs *apis.FieldError) {
+	for i := range in.Schedule {
+		if _, err := cron.ParseStandard(in.Schedule[i].Schedule); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(in.Schedule[i].Schedule, "schedule", fmt.Sprintf("invalid schedule %s", err)).ViaIndex(i))
+		}
+		if in.Schedule[i].Duration.Duration <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(in.Schedule[i].Duration.Duration.String(), "duration", "must be a positive duration").ViaIndex(i))
+		}
+	}
+	return errs
+}
+
 func (in *NodeClaimTemplate) validate() (errs *apis.FieldError) {
 	if len(in.Spec.Resources.Requests) > 0 {
 		errs = errs.Also(apis.ErrDisallowedFields("resources.requests"))
 	}
+	for k, v := range in.Spec.Resources.Reserved {
+		if v.Value() < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v.String(), fmt.Sprintf(`resources.reserved["%s"]`, k), "Value cannot be a negative resource quantity"))
+		}
+	}
 	return errs.Also(
 		in.validateLabels().ViaField("metadata"),
 		in.validateRequirementsNodePoolKeyDoesNotExist().ViaField("spec.requirements"),
@@ -104,6 +145,12 @@ func (in *Disruption) validate() (errs *apis.FieldError) {
 	if in.ConsolidateAfter == nil && in.ConsolidationPolicy == ConsolidationPolicyWhenEmpty {
 		return errs.Also(apis.ErrGeneric("consolidateAfter must be specified with consolidationPolicy=WhenEmpty"))
 	}
+	if in.DriftCheckInterval != nil && in.DriftCheckInterval.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(in.DriftCheckInterval.Duration.String(), "driftCheckInterval", "must be a positive duration"))
+	}
+	if in.SpotToSpotPriceImprovementPercent != nil && (*in.SpotToSpotPriceImprovementPercent < 1 || *in.SpotToSpotPriceImprovementPercent > 99) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*in.SpotToSpotPriceImprovementPercent, 1, 99, "spotToSpotPriceImprovementPercent"))
+	}
 	for i := range in.Budgets {
 		budget := in.Budgets[i]
 		if err := budget.validate(); err != nil {
@@ -122,5 +169,10 @@ func (in *Budget) validate() (errs *apis.FieldError) {
 			return apis.ErrInvalidValue(in.Schedule, "schedule", fmt.Sprintf("invalid schedule %s", err))
 		}
 	}
+	for i, reason := range in.Reasons {
+		if !lo.Contains(SupportedDisruptionReasons, reason) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(reason, "reasons", i))
+		}
+	}
 	return errs
 }
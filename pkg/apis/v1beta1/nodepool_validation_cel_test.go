@@ -105,6 +105,26 @@ var _ = Describe("CEL/Validation", func() {
 			nodePool.Spec.Disruption.ConsolidationPolicy = ConsolidationPolicyWhenUnderutilized
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
+		It("should fail on negative driftCheckInterval", func() {
+			nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{Duration: lo.Must(time.ParseDuration("-1s"))}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail on a zero driftCheckInterval", func() {
+			nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{Duration: 0}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should succeed on a valid driftCheckInterval", func() {
+			nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{Duration: lo.Must(time.ParseDuration("30s"))}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should succeed on a valid spotToSpotPriceImprovementPercent", func() {
+			nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](15)
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on an out of range spotToSpotPriceImprovementPercent", func() {
+			nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](100)
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 		It("should fail when creating a budget with an invalid cron", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes:    "10",
@@ -183,6 +203,20 @@ var _ = Describe("CEL/Validation", func() {
 			}}
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
+		It("should succeed when creating a budget with reasons", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:   "10",
+				Reasons: []DisruptionReason{DisruptionReasonUnderutilized, DisruptionReasonExpired},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail when creating a budget with an invalid reason", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:   "10",
+				Reasons: []DisruptionReason{"Unknown"},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 		It("should succeed when creating a budget with special cased crons", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes:    "10",
@@ -223,6 +257,104 @@ var _ = Describe("CEL/Validation", func() {
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 		})
 	})
+	Context("PreemptionPolicy", func() {
+		It("should succeed on a valid preemptionPolicy", func() {
+			nodePool.Spec.PreemptionPolicy = PreemptionPolicyPreempt
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on an invalid preemptionPolicy", func() {
+			nodePool.Spec.PreemptionPolicy = "Never"
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("SyncPolicy", func() {
+		It("should succeed on a valid syncPolicy", func() {
+			nodePool.Spec.Template.SyncPolicy = SyncPolicyContinuous
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on an invalid syncPolicy", func() {
+			nodePool.Spec.Template.SyncPolicy = "Always"
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("MaxConcurrentLaunches", func() {
+		It("should succeed on a positive maxConcurrentLaunches", func() {
+			nodePool.Spec.MaxConcurrentLaunches = lo.ToPtr[int32](10)
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a zero maxConcurrentLaunches", func() {
+			nodePool.Spec.MaxConcurrentLaunches = lo.ToPtr[int32](0)
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("MinNodes", func() {
+		It("should succeed on a valid minNodes", func() {
+			nodePool.Spec.MinNodes = lo.ToPtr[int32](3)
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a negative minNodes", func() {
+			nodePool.Spec.MinNodes = lo.ToPtr[int32](-1)
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("Schedule", func() {
+		It("should succeed on a valid scaling window", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 10}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a scaling window with an invalid schedule", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "*", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 10}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail on a scaling window with a duration in seconds", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 30 * time.Second}, MinNodes: 10}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail on a scaling window with a negative minNodes", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: -1}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("Headroom", func() {
+		It("should succeed on valid headroom", func() {
+			nodePool.Spec.Headroom = &Headroom{Count: 2, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), v1.ResourceMemory: resource.MustParse("32Gi")}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on headroom without any units", func() {
+			nodePool.Spec.Headroom = &Headroom{Count: 0, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail on headroom with a negative resource quantity", func() {
+			nodePool.Spec.Headroom = &Headroom{Count: 2, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("-8")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("WarmPool", func() {
+		It("should succeed on a valid warm pool size", func() {
+			nodePool.Spec.WarmPool = &WarmPool{Size: 3}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a negative warm pool size", func() {
+			nodePool.Spec.WarmPool = &WarmPool{Size: -1}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("TopologyKeys", func() {
+		It("should succeed on custom topology keys", func() {
+			nodePool.Spec.TopologyKeys = []string{"example.com/rack", "node.kubernetes.io/placement-group"}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		DescribeTable("should fail on restricted topology keys",
+			func(key string) {
+				nodePool.Spec.TopologyKeys = []string{key}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			},
+			Entry("zone", v1.LabelTopologyZone),
+			Entry("hostname", v1.LabelHostname),
+			Entry("capacity type", CapacityTypeLabelKey),
+			Entry("restricted domain", "topology.kubernetes.io/rack"),
+		)
+	})
 	Context("KubeletConfiguration", func() {
 		It("should succeed on kubeReserved with invalid keys", func() {
 			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{
@@ -675,6 +807,20 @@ var _ = Describe("CEL/Validation", func() {
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 			Expect(nodePool.RuntimeValidate()).To(Succeed())
 		})
+		It("should allow NotMatches patterns", func() {
+			nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{".*metal.*"}}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should fail for NotMatches without patterns", func() {
+			nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
 		It("should fail with invalid GT or LT values", func() {
 			for _, requirement := range []NodeSelectorRequirementWithMinValues{
 				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{}}},
@@ -829,5 +975,13 @@ var _ = Describe("CEL/Validation", func() {
 			nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 		})
+		It("should allow reserved resources to be set", func() {
+			nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Reserved: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("10Gi")}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on reserved resources with a negative quantity", func() {
+			nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Reserved: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("-10Gi")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 	})
 })
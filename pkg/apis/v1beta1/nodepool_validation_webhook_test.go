@@ -79,6 +79,32 @@ var _ = Describe("Webhook/Validation", func() {
 			nodePool.Spec.Disruption.ConsolidationPolicy = ConsolidationPolicyWhenUnderutilized
 			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 		})
+		It("should succeed on a valid driftCheckInterval", func() {
+			nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{Duration: lo.Must(time.ParseDuration("30s"))}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail on a zero driftCheckInterval", func() {
+			nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should succeed on a valid scaleUpDeadline", func() {
+			nodePool.Spec.ScaleUpDeadline = &metav1.Duration{Duration: lo.Must(time.ParseDuration("10m"))}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail on a zero scaleUpDeadline", func() {
+			nodePool.Spec.ScaleUpDeadline = &metav1.Duration{}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should succeed on a valid spotToSpotPriceImprovementPercent", func() {
+			nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](15)
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail on an out of range spotToSpotPriceImprovementPercent", func() {
+			nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](100)
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](0)
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
 		It("should fail to validate a budget with an invalid cron", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes:    "10",
@@ -123,6 +149,20 @@ var _ = Describe("Webhook/Validation", func() {
 			}}
 			Expect(nodePool.Validate(ctx)).To(Succeed())
 		})
+		It("should succeed to validate a budget with reasons", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:   "10",
+				Reasons: []DisruptionReason{DisruptionReasonUnderutilized, DisruptionReasonExpired},
+			}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail to validate a budget with an invalid reason", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:   "10",
+				Reasons: []DisruptionReason{"Unknown"},
+			}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
 		It("should succeed to validate a budget with special cased crons", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes:    "10",
@@ -163,6 +203,40 @@ var _ = Describe("Webhook/Validation", func() {
 			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 		})
 	})
+	Context("Schedule", func() {
+		It("should allow a valid scaling window", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinNodes: 10}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail on a scaling window with an invalid schedule", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "* * * *", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 10}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should fail on a scaling window without a duration", func() {
+			nodePool.Spec.Schedule = []ScalingWindow{{Schedule: "0 8 * * MON-FRI", MinNodes: 10}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+	})
+	Context("TopologyKeys", func() {
+		It("should allow custom topology keys", func() {
+			nodePool.Spec.TopologyKeys = []string{"example.com/rack", "node.kubernetes.io/placement-group"}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		DescribeTable("should fail on restricted topology keys",
+			func(key string) {
+				nodePool.Spec.TopologyKeys = []string{key}
+				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			},
+			Entry("zone", v1.LabelTopologyZone),
+			Entry("hostname", v1.LabelHostname),
+			Entry("capacity type", CapacityTypeLabelKey),
+			Entry("restricted domain", "topology.kubernetes.io/rack"),
+		)
+		It("should fail on an invalid topology key", func() {
+			nodePool.Spec.TopologyKeys = []string{"example.com/rack/"}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+	})
 	Context("Limits", func() {
 		It("should allow undefined limits", func() {
 			nodePool.Spec.Limits = nil
@@ -359,6 +433,21 @@ var _ = Describe("Webhook/Validation", func() {
 				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{}
 				Expect(nodePool.Validate(ctx)).To(Succeed())
 			})
+			It("should allow valid NotMatches patterns", func() {
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{".*metal.*", `m5\.(2|4)xlarge`}}},
+				}
+				Expect(nodePool.Validate(ctx)).To(Succeed())
+			})
+			It("should fail with invalid NotMatches patterns", func() {
+				for _, requirement := range []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: NodeSelectorOpNotMatches, Values: []string{"metal("}}},
+				} {
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{requirement}
+					Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+				}
+			})
 			It("should fail with invalid GT or LT values", func() {
 				for _, requirement := range []NodeSelectorRequirementWithMinValues{
 					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{}}},
@@ -381,6 +470,20 @@ var _ = Describe("Webhook/Validation", func() {
 				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 			})
 		})
+		Context("Resources", func() {
+			It("should fail when requests are set", func() {
+				nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}
+				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			})
+			It("should succeed when reserved resources are set", func() {
+				nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Reserved: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("10Gi")}}
+				Expect(nodePool.Validate(ctx)).To(Succeed())
+			})
+			It("should fail on reserved resources with a negative quantity", func() {
+				nodePool.Spec.Template.Spec.Resources = ResourceRequirements{Reserved: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("-10Gi")}}
+				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			})
+		})
 		Context("KubeletConfiguration", func() {
 			It("should fail on kubeReserved with invalid keys", func() {
 				nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{
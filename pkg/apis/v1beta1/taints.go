@@ -22,6 +22,7 @@ import v1 "k8s.io/api/core/v1"
 const (
 	DisruptionTaintKey             = Group + "/disruption"
 	DisruptingNoScheduleTaintValue = "disrupting"
+	WarmPoolTaintKey               = Group + "/warm-pool"
 )
 
 var (
@@ -32,6 +33,19 @@ var (
 		Effect: v1.TaintEffectNoSchedule,
 		Value:  DisruptingNoScheduleTaintValue,
 	}
+	// DisruptionPreferNoScheduleTaint is added to the nodes that Karpenter is about to disrupt for a grace window
+	// before they're tainted with DisruptionNoScheduleTaint, so that new pods prefer other nodes in the meantime.
+	DisruptionPreferNoScheduleTaint = v1.Taint{
+		Key:    DisruptionTaintKey,
+		Effect: v1.TaintEffectPreferNoSchedule,
+		Value:  DisruptingNoScheduleTaintValue,
+	}
+	// WarmPoolNoScheduleTaint is added to NodeClaims that are launched into a nodepool's warm pool to ensure no pods
+	// are scheduled to them before they're stopped. It's removed when the NodeClaim is claimed from the warm pool.
+	WarmPoolNoScheduleTaint = v1.Taint{
+		Key:    WarmPoolTaintKey,
+		Effect: v1.TaintEffectNoSchedule,
+	}
 )
 
 func IsDisruptingTaint(taint v1.Taint) bool {
@@ -41,6 +41,11 @@ func (in *Budget) DeepCopyInto(out *Budget) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]DisruptionReason, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Budget.
@@ -53,6 +58,36 @@ func (in *Budget) DeepCopy() *Budget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityTypeDistribution) DeepCopyInto(out *CapacityTypeDistribution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTypeDistribution.
+func (in *CapacityTypeDistribution) DeepCopy() *CapacityTypeDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityTypeDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetReference) DeepCopyInto(out *DaemonSetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetReference.
+func (in *DaemonSetReference) DeepCopy() *DaemonSetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disruption) DeepCopyInto(out *Disruption) {
 	*out = *in
@@ -62,6 +97,21 @@ func (in *Disruption) DeepCopyInto(out *Disruption) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.ExpireAfter.DeepCopyInto(&out.ExpireAfter)
+	if in.DriftCheckInterval != nil {
+		in, out := &in.DriftCheckInterval, &out.DriftCheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DriftChecks != nil {
+		in, out := &in.DriftChecks, &out.DriftChecks
+		*out = new(DriftChecks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpotToSpotPriceImprovementPercent != nil {
+		in, out := &in.SpotToSpotPriceImprovementPercent, &out.SpotToSpotPriceImprovementPercent
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Budgets != nil {
 		in, out := &in.Budgets, &out.Budgets
 		*out = make([]Budget, len(*in))
@@ -81,6 +131,63 @@ func (in *Disruption) DeepCopy() *Disruption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftChecks) DeepCopyInto(out *DriftChecks) {
+	*out = *in
+	if in.NodeImage != nil {
+		in, out := &in.NodeImage, &out.NodeImage
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeClassHash != nil {
+		in, out := &in.NodeClassHash, &out.NodeClassHash
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftChecks.
+func (in *DriftChecks) DeepCopy() *DriftChecks {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftChecks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Headroom) DeepCopyInto(out *Headroom) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Headroom.
+func (in *Headroom) DeepCopy() *Headroom {
+	if in == nil {
+		return nil
+	}
+	out := new(Headroom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeletConfiguration) DeepCopyInto(out *KubeletConfiguration) {
 	*out = *in
@@ -301,6 +408,28 @@ func (in *NodeClaimSpec) DeepCopyInto(out *NodeClaimSpec) {
 		*out = new(NodeClassReference)
 		**out = **in
 	}
+	if in.TerminationGracePeriod != nil {
+		in, out := &in.TerminationGracePeriod, &out.TerminationGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RegistrationTimeout != nil {
+		in, out := &in.RegistrationTimeout, &out.RegistrationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DevicePluginResources != nil {
+		in, out := &in.DevicePluginResources, &out.DevicePluginResources
+		*out = make([]v1.ResourceName, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimSpec.
@@ -337,6 +466,11 @@ func (in *NodeClaimStatus) DeepCopyInto(out *NodeClaimStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DriftedReasons != nil {
+		in, out := &in.DriftedReasons, &out.DriftedReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimStatus.
@@ -452,11 +586,51 @@ func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.MaxConcurrentLaunches != nil {
+		in, out := &in.MaxConcurrentLaunches, &out.MaxConcurrentLaunches
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinNodes != nil {
+		in, out := &in.MinNodes, &out.MinNodes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]ScalingWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Headroom != nil {
+		in, out := &in.Headroom, &out.Headroom
+		*out = new(Headroom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WarmPool != nil {
+		in, out := &in.WarmPool, &out.WarmPool
+		*out = new(WarmPool)
+		**out = **in
+	}
+	if in.CapacityTypeDistribution != nil {
+		in, out := &in.CapacityTypeDistribution, &out.CapacityTypeDistribution
+		*out = new(CapacityTypeDistribution)
+		**out = **in
+	}
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ScaleUpDeadline != nil {
+		in, out := &in.ScaleUpDeadline, &out.ScaleUpDeadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
@@ -479,6 +653,13 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apis.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
@@ -541,6 +722,31 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessGate) DeepCopyInto(out *ReadinessGate) {
+	*out = *in
+	if in.DaemonSet != nil {
+		in, out := &in.DaemonSet, &out.DaemonSet
+		*out = new(DaemonSetReference)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessGate.
+func (in *ReadinessGate) DeepCopy() *ReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
 	*out = *in
@@ -551,6 +757,13 @@ func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Reserved != nil {
+		in, out := &in.Reserved, &out.Reserved
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
@@ -562,3 +775,34 @@ func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingWindow) DeepCopyInto(out *ScalingWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingWindow.
+func (in *ScalingWindow) DeepCopy() *ScalingWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPool) DeepCopyInto(out *WarmPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPool.
+func (in *WarmPool) DeepCopy() *WarmPool {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPool)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -38,6 +38,11 @@ import (
 )
 
 var _ cloudprovider.CloudProvider = (*CloudProvider)(nil)
+var _ cloudprovider.InterruptionProvider = (*CloudProvider)(nil)
+var _ cloudprovider.WarmPoolProvider = (*CloudProvider)(nil)
+var _ cloudprovider.BatchCreator = (*CloudProvider)(nil)
+var _ cloudprovider.DryRunCreator = (*CloudProvider)(nil)
+var _ cloudprovider.MaxPodsProvider = (*CloudProvider)(nil)
 
 type CloudProvider struct {
 	InstanceTypes            []*cloudprovider.InstanceType
@@ -50,17 +55,38 @@ type CloudProvider struct {
 	AllowedCreateCalls int
 	NextCreateErr      error
 	DeleteCalls        []*v1beta1.NodeClaim
+	// CreateBatchCalls contains the arguments for every create batch call that was made since it was cleared. Each
+	// NodeClaim in a batch is also recorded in CreateCalls.
+	CreateBatchCalls [][]*v1beta1.NodeClaim
+	// CreateDryRunCalls contains the arguments for every create dry-run call that was made since it was cleared
+	CreateDryRunCalls []*v1beta1.NodeClaim
+	// CreateDryRunErr is returned by every create dry-run call
+	CreateDryRunErr error
 
 	CreatedNodeClaims map[string]*v1beta1.NodeClaim
 	Drifted           cloudprovider.DriftReason
+	Repair            []cloudprovider.RepairPolicy
+
+	// Interruptions are returned by GetInterruptions until they are acknowledged
+	Interruptions             []*cloudprovider.Interruption
+	AcknowledgedInterruptions []*cloudprovider.Interruption
+
+	// MaxPodsForInstanceType overrides the number of pods that nodes of the instance types can run, which otherwise is
+	// their pods capacity
+	MaxPodsForInstanceType map[string]int64
+
+	// StoppedNodeClaims contains the provider ids of the NodeClaims that are stopped
+	StoppedNodeClaims map[string]bool
 }
 
 func NewCloudProvider() *CloudProvider {
 	return &CloudProvider{
 		AllowedCreateCalls:       math.MaxInt,
 		CreatedNodeClaims:        map[string]*v1beta1.NodeClaim{},
+		StoppedNodeClaims:        map[string]bool{},
 		InstanceTypesForNodePool: map[string][]*cloudprovider.InstanceType{},
 		ErrorsForNodePool:        map[string]error{},
+		MaxPodsForInstanceType:   map[string]int64{},
 	}
 }
 
@@ -69,6 +95,9 @@ func (c *CloudProvider) Reset() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.CreateCalls = nil
+	c.CreateBatchCalls = nil
+	c.CreateDryRunCalls = nil
+	c.CreateDryRunErr = nil
 	c.CreatedNodeClaims = map[string]*v1beta1.NodeClaim{}
 	c.InstanceTypes = nil
 	c.InstanceTypesForNodePool = map[string][]*cloudprovider.InstanceType{}
@@ -77,6 +106,11 @@ func (c *CloudProvider) Reset() {
 	c.NextCreateErr = nil
 	c.DeleteCalls = []*v1beta1.NodeClaim{}
 	c.Drifted = "drifted"
+	c.Repair = nil
+	c.Interruptions = nil
+	c.AcknowledgedInterruptions = nil
+	c.StoppedNodeClaims = map[string]bool{}
+	c.MaxPodsForInstanceType = map[string]int64{}
 }
 
 func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
@@ -119,9 +153,10 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim
 		if reqs.Compatible(scheduling.NewRequirements(
 			scheduling.NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, o.Zone),
 			scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, o.CapacityType),
-		), scheduling.AllowUndefinedWellKnownLabels) == nil {
+		), scheduling.AllowUndefinedWellKnownLabels) == nil && o.Compatible(reqs) {
 			labels[v1.LabelTopologyZone] = o.Zone
 			labels[v1beta1.CapacityTypeLabelKey] = o.CapacityType
+			labels = lo.Assign(labels, o.Domains)
 			break
 		}
 	}
@@ -142,6 +177,28 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim
 	return created, nil
 }
 
+// CreateBatch creates each of the NodeClaims in order, so that AllowedCreateCalls and NextCreateErr apply to a batch
+// the same way that they apply to individual create calls
+func (c *CloudProvider) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	c.mu.Lock()
+	c.CreateBatchCalls = append(c.CreateBatchCalls, nodeClaims)
+	c.mu.Unlock()
+
+	created, errs := make([]*v1beta1.NodeClaim, len(nodeClaims)), make([]error, len(nodeClaims))
+	for i := range nodeClaims {
+		created[i], errs[i] = c.Create(ctx, nodeClaims[i])
+	}
+	return created, errs
+}
+
+func (c *CloudProvider) CreateDryRun(_ context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.CreateDryRunCalls = append(c.CreateDryRunCalls, nodeClaim)
+	return c.CreateDryRunErr
+}
+
 func (c *CloudProvider) Get(_ context.Context, id string) (*v1beta1.NodeClaim, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -233,7 +290,110 @@ func (c *CloudProvider) IsDrifted(context.Context, *v1beta1.NodeClaim) (cloudpro
 	return c.Drifted, nil
 }
 
+func (c *CloudProvider) Price(ctx context.Context, instanceType, capacityType, zone string) (float64, error) {
+	instanceTypes, err := c.GetInstanceTypes(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	instanceTypes = lo.Flatten(append([][]*cloudprovider.InstanceType{instanceTypes}, lo.Values(c.InstanceTypesForNodePool)...))
+	it, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == instanceType })
+	if !ok {
+		return 0, fmt.Errorf("no instance type exists with name '%s'", instanceType)
+	}
+	offering, ok := it.Offerings.Get(capacityType, zone)
+	if !ok {
+		return 0, fmt.Errorf("no offering exists for %s/%s/%s", instanceType, capacityType, zone)
+	}
+	return offering.Price, nil
+}
+
+func (c *CloudProvider) MaxPods(_ context.Context, instanceType *cloudprovider.InstanceType, _ *v1beta1.KubeletConfiguration) int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if pods, ok := c.MaxPodsForInstanceType[instanceType.Name]; ok {
+		return pods
+	}
+	return instanceType.Capacity.Pods().Value()
+}
+
+func (c *CloudProvider) RepairPolicies() []cloudprovider.RepairPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.Repair
+}
+
+func (c *CloudProvider) GetInterruptions(context.Context) ([]*cloudprovider.Interruption, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]*cloudprovider.Interruption{}, c.Interruptions...), nil
+}
+
+func (c *CloudProvider) AcknowledgeInterruption(_ context.Context, interruption *cloudprovider.Interruption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interruptions = lo.Reject(c.Interruptions, func(i *cloudprovider.Interruption, _ int) bool { return i.ID == interruption.ID })
+	c.AcknowledgedInterruptions = append(c.AcknowledgedInterruptions, interruption)
+	return nil
+}
+
+func (c *CloudProvider) Stop(_ context.Context, nc *v1beta1.NodeClaim) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.CreatedNodeClaims[nc.Status.ProviderID]; !ok {
+		return cloudprovider.NewNodeClaimNotFoundError(fmt.Errorf("no nodeclaim exists with provider id '%s'", nc.Status.ProviderID))
+	}
+	c.StoppedNodeClaims[nc.Status.ProviderID] = true
+	return nil
+}
+
+func (c *CloudProvider) Start(_ context.Context, nc *v1beta1.NodeClaim) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.CreatedNodeClaims[nc.Status.ProviderID]; !ok {
+		return cloudprovider.NewNodeClaimNotFoundError(fmt.Errorf("no nodeclaim exists with provider id '%s'", nc.Status.ProviderID))
+	}
+	delete(c.StoppedNodeClaims, nc.Status.ProviderID)
+	return nil
+}
+
 // Name returns the CloudProvider implementation name.
 func (c *CloudProvider) Name() string {
 	return "fake"
 }
+
+var _ cloudprovider.OfferingChangeNotifier = (*NotifyingCloudProvider)(nil)
+
+// NotifyingCloudProvider is a CloudProvider that also implements OfferingChangeNotifier, which CloudProvider doesn't so
+// that Karpenter doesn't cache the instance types that tests change
+type NotifyingCloudProvider struct {
+	*CloudProvider
+
+	callbacksMu sync.RWMutex
+	callbacks   []func(...string)
+}
+
+func NewNotifyingCloudProvider() *NotifyingCloudProvider {
+	return &NotifyingCloudProvider{CloudProvider: NewCloudProvider()}
+}
+
+func (c *NotifyingCloudProvider) NotifyOfferingChange(callback func(...string)) {
+	c.callbacksMu.Lock()
+	defer c.callbacksMu.Unlock()
+	c.callbacks = append(c.callbacks, callback)
+}
+
+// ChangeOfferings calls the callbacks registered with NotifyOfferingChange for the instance types
+func (c *NotifyingCloudProvider) ChangeOfferings(instanceTypes ...string) {
+	c.callbacksMu.RLock()
+	callbacks := c.callbacks
+	c.callbacksMu.RUnlock()
+	for _, callback := range callbacks {
+		callback(instanceTypes...)
+	}
+}
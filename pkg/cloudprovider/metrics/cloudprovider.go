@@ -43,7 +43,10 @@ const (
 )
 
 // decorator implements CloudProvider
-var _ cloudprovider.CloudProvider = (*decorator)(nil)
+var _ cloudprovider.Decorator = (*decorator)(nil)
+var _ cloudprovider.InterruptionProvider = (*decorator)(nil)
+var _ cloudprovider.WarmPoolProvider = (*decorator)(nil)
+var _ cloudprovider.BatchCreator = (*decorator)(nil)
 
 var methodDurationHistogramVec = prometheus.NewHistogramVec(
 	prometheus.HistogramOpts{
@@ -91,10 +94,18 @@ type decorator struct {
 //
 // Do not decorate a `CloudProvider` multiple times or published metrics will contain
 // duplicated method call counts and latencies.
+//
+// The returned instance supports the optional interfaces that `cloudProvider` supports, which are looked up with
+// `cloudprovider.As`.
 func Decorate(cloudProvider cloudprovider.CloudProvider) cloudprovider.CloudProvider {
 	return &decorator{cloudProvider}
 }
 
+// Unwrap returns the decorated CloudProvider
+func (d *decorator) Unwrap() cloudprovider.CloudProvider {
+	return d.CloudProvider
+}
+
 func (d *decorator) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
 	method := "Create"
 	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
@@ -105,6 +116,29 @@ func (d *decorator) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*
 	return nodeClaim, err
 }
 
+func (d *decorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	method := "CreateBatch"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	created, errs := cloudprovider.CreateBatch(ctx, d.CloudProvider, nodeClaims)
+	for _, err := range errs {
+		if err != nil {
+			errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+		}
+	}
+	return created, errs
+}
+
+func (d *decorator) CreateDryRun(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	method := "CreateDryRun"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	dryRunCreator, _ := cloudprovider.As[cloudprovider.DryRunCreator](d.CloudProvider)
+	err := dryRunCreator.CreateDryRun(ctx, nodeClaim)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return err
+}
+
 func (d *decorator) Delete(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
 	method := "Delete"
 	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
@@ -155,6 +189,60 @@ func (d *decorator) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim)
 	return isDrifted, err
 }
 
+func (d *decorator) Price(ctx context.Context, instanceType, capacityType, zone string) (float64, error) {
+	method := "Price"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	price, err := d.CloudProvider.Price(ctx, instanceType, capacityType, zone)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return price, err
+}
+
+func (d *decorator) GetInterruptions(ctx context.Context) ([]*cloudprovider.Interruption, error) {
+	method := "GetInterruptions"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	interruptionProvider, _ := cloudprovider.As[cloudprovider.InterruptionProvider](d.CloudProvider)
+	interruptions, err := interruptionProvider.GetInterruptions(ctx)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return interruptions, err
+}
+
+func (d *decorator) AcknowledgeInterruption(ctx context.Context, interruption *cloudprovider.Interruption) error {
+	method := "AcknowledgeInterruption"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	interruptionProvider, _ := cloudprovider.As[cloudprovider.InterruptionProvider](d.CloudProvider)
+	err := interruptionProvider.AcknowledgeInterruption(ctx, interruption)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return err
+}
+
+func (d *decorator) Stop(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	method := "Stop"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	warmPoolProvider, _ := cloudprovider.As[cloudprovider.WarmPoolProvider](d.CloudProvider)
+	err := warmPoolProvider.Stop(ctx, nodeClaim)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return err
+}
+
+func (d *decorator) Start(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	method := "Start"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
+	warmPoolProvider, _ := cloudprovider.As[cloudprovider.WarmPoolProvider](d.CloudProvider)
+	err := warmPoolProvider.Start(ctx, nodeClaim)
+	if err != nil {
+		errorsTotalCounter.With(getLabelsMapForError(ctx, d, method, err)).Inc()
+	}
+	return err
+}
+
 // getLabelsMapForDuration is a convenience func that constructs a map[string]string
 // for a prometheus Label map used to compose a duration metric spec
 func getLabelsMapForDuration(ctx context.Context, d *decorator, method string) map[string]string {
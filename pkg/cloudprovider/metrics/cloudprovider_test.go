@@ -17,13 +17,17 @@ limitations under the License.
 package metrics_test
 
 import (
+	"context"
 	"errors"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/metrics"
+	"sigs.k8s.io/karpenter/pkg/test"
 )
 
 var _ = Describe("Cloudprovider", func() {
@@ -50,4 +54,95 @@ var _ = Describe("Cloudprovider", func() {
 			})
 		})
 	})
+	Describe("Decorate", func() {
+		It("should implement InterruptionProvider when the cloudprovider does", func() {
+			cp := fake.NewCloudProvider()
+			cp.Interruptions = []*cloudprovider.Interruption{{ID: "1", ProviderID: "fake:///1", Kind: cloudprovider.SpotInterruptionKind}}
+			interruptionProvider, ok := cloudprovider.As[cloudprovider.InterruptionProvider](metrics.Decorate(cp))
+			Expect(ok).To(BeTrue())
+
+			interruptions, err := interruptionProvider.GetInterruptions(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(interruptions).To(HaveLen(1))
+			Expect(interruptionProvider.AcknowledgeInterruption(context.Background(), interruptions[0])).To(Succeed())
+			Expect(cp.Interruptions).To(BeEmpty())
+		})
+		It("should not implement InterruptionProvider when the cloudprovider doesn't", func() {
+			cp := struct{ cloudprovider.CloudProvider }{fake.NewCloudProvider()}
+			_, ok := cloudprovider.As[cloudprovider.InterruptionProvider](metrics.Decorate(cp))
+			Expect(ok).To(BeFalse())
+		})
+		It("should implement WarmPoolProvider when the cloudprovider does", func() {
+			cp := fake.NewCloudProvider()
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()}})
+			cp.CreatedNodeClaims[nodeClaim.Status.ProviderID] = nodeClaim
+			warmPoolProvider, ok := cloudprovider.As[cloudprovider.WarmPoolProvider](metrics.Decorate(cp))
+			Expect(ok).To(BeTrue())
+
+			Expect(warmPoolProvider.Stop(context.Background(), nodeClaim)).To(Succeed())
+			Expect(cp.StoppedNodeClaims).To(HaveKey(nodeClaim.Status.ProviderID))
+			Expect(warmPoolProvider.Start(context.Background(), nodeClaim)).To(Succeed())
+			Expect(cp.StoppedNodeClaims).To(BeEmpty())
+		})
+		It("should not implement WarmPoolProvider when the cloudprovider doesn't", func() {
+			cp := struct {
+				cloudprovider.CloudProvider
+				cloudprovider.InterruptionProvider
+			}{fake.NewCloudProvider(), fake.NewCloudProvider()}
+			decorated := metrics.Decorate(cp)
+			_, ok := cloudprovider.As[cloudprovider.WarmPoolProvider](decorated)
+			Expect(ok).To(BeFalse())
+			_, ok = cloudprovider.As[cloudprovider.InterruptionProvider](decorated)
+			Expect(ok).To(BeTrue())
+		})
+		It("should create batches with the cloudprovider's CreateBatch when the cloudprovider implements BatchCreator", func() {
+			cp := fake.NewCloudProvider()
+			batchCreator, ok := cloudprovider.As[cloudprovider.BatchCreator](metrics.Decorate(cp))
+			Expect(ok).To(BeTrue())
+
+			created, errs := batchCreator.CreateBatch(context.Background(), []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+			Expect(created).To(HaveLen(2))
+			Expect(errs).To(ConsistOf(BeNil(), BeNil()))
+			Expect(cp.CreateBatchCalls).To(HaveLen(1))
+		})
+		It("should not implement BatchCreator when the cloudprovider doesn't", func() {
+			cp := struct{ cloudprovider.CloudProvider }{fake.NewCloudProvider()}
+			_, ok := cloudprovider.As[cloudprovider.BatchCreator](metrics.Decorate(cp))
+			Expect(ok).To(BeFalse())
+		})
+		It("should dry-run launches with the cloudprovider's CreateDryRun when the cloudprovider implements DryRunCreator", func() {
+			cp := fake.NewCloudProvider()
+			cp.CreateDryRunErr = errors.New("image not found")
+			dryRunCreator, ok := cloudprovider.As[cloudprovider.DryRunCreator](metrics.Decorate(cp))
+			Expect(ok).To(BeTrue())
+
+			Expect(dryRunCreator.CreateDryRun(context.Background(), test.NodeClaim())).To(MatchError("image not found"))
+			Expect(cp.CreateDryRunCalls).To(HaveLen(1))
+		})
+		It("should not implement DryRunCreator when the cloudprovider doesn't", func() {
+			cp := struct{ cloudprovider.CloudProvider }{fake.NewCloudProvider()}
+			_, ok := cloudprovider.As[cloudprovider.DryRunCreator](metrics.Decorate(cp))
+			Expect(ok).To(BeFalse())
+		})
+		It("should return the cloudprovider's max pods when the cloudprovider implements MaxPodsProvider", func() {
+			cp := fake.NewCloudProvider()
+			instanceType := fake.NewInstanceType(fake.InstanceTypeOptions{Name: "cni-limited-instance-type"})
+			cp.MaxPodsForInstanceType[instanceType.Name] = 2
+			Expect(cloudprovider.MaxPods(context.Background(), metrics.Decorate(cp), instanceType, nil)).To(BeNumerically("==", 2))
+		})
+		It("should return the instance type's pods capacity when the cloudprovider doesn't implement MaxPodsProvider", func() {
+			cp := struct{ cloudprovider.CloudProvider }{fake.NewCloudProvider()}
+			instanceType := fake.NewInstanceType(fake.InstanceTypeOptions{Name: "cni-limited-instance-type"})
+			_, ok := cloudprovider.As[cloudprovider.MaxPodsProvider](metrics.Decorate(cp))
+			Expect(ok).To(BeFalse())
+			Expect(cloudprovider.MaxPods(context.Background(), metrics.Decorate(cp), instanceType, nil)).To(Equal(instanceType.Capacity.Pods().Value()))
+		})
+		It("should look up optional interfaces through every decorator", func() {
+			cp := struct{ cloudprovider.CloudProvider }{fake.NewCloudProvider()}
+			_, ok := cloudprovider.As[cloudprovider.BatchCreator](metrics.Decorate(metrics.Decorate(cp)))
+			Expect(ok).To(BeFalse())
+			_, ok = cloudprovider.As[cloudprovider.BatchCreator](metrics.Decorate(metrics.Decorate(fake.NewCloudProvider())))
+			Expect(ok).To(BeTrue())
+		})
+	})
 })
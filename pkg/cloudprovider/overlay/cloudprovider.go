@@ -0,0 +1,186 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overlay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// decorator implements CloudProvider
+var _ cloudprovider.Decorator = (*decorator)(nil)
+var _ cloudprovider.OfferingChangeNotifier = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+	kubeClient client.Client
+
+	// requirements caches the requirements of the instance types we've seen by name so that price adjustments can be
+	// matched against them when a price is looked up by name
+	requirements sync.Map
+
+	mu        sync.RWMutex
+	callbacks []func(...string) // callbacks are registered with NotifyOfferingChange
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument,
+// `cloudProvider`, and apply the NodeOverlays in the cluster to the instance types and prices that it returns.
+//
+// The returned instance supports the optional interfaces that `cloudProvider` supports, which are looked up with
+// `cloudprovider.As`.
+func Decorate(cloudProvider cloudprovider.CloudProvider, kubeClient client.Client) cloudprovider.CloudProvider {
+	return &decorator{CloudProvider: cloudProvider, kubeClient: kubeClient}
+}
+
+// Unwrap returns the decorated CloudProvider
+func (d *decorator) Unwrap() cloudprovider.CloudProvider {
+	return d.CloudProvider
+}
+
+func (d *decorator) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	instanceTypes, err := d.CloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range instanceTypes {
+		d.requirements.Store(it.Name, it.Requirements)
+	}
+	overlays, err := d.overlays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(overlays) == 0 {
+		return instanceTypes, nil
+	}
+	return lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) *cloudprovider.InstanceType {
+		return apply(ctx, it, overlays)
+	}), nil
+}
+
+// NotifyOfferingChange registers the callback with the decorated CloudProvider, and also calls it when a NodeOverlay
+// changes, since the overlays can change any of the instance types. It's only looked up through cloudprovider.As if
+// the decorated CloudProvider implements OfferingChangeNotifier, as the instance types can't be cached otherwise.
+func (d *decorator) NotifyOfferingChange(callback func(...string)) {
+	if offeringChangeNotifier, ok := cloudprovider.As[cloudprovider.OfferingChangeNotifier](d.CloudProvider); ok {
+		offeringChangeNotifier.NotifyOfferingChange(callback)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.callbacks = append(d.callbacks, callback)
+}
+
+// overlaysChanged calls the callbacks registered with NotifyOfferingChange for all the instance types
+func (d *decorator) overlaysChanged() {
+	d.mu.RLock()
+	callbacks := d.callbacks
+	d.mu.RUnlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+func (d *decorator) Price(ctx context.Context, instanceType, capacityType, zone string) (float64, error) {
+	price, err := d.CloudProvider.Price(ctx, instanceType, capacityType, zone)
+	if err != nil {
+		return 0, err
+	}
+	overlays, err := d.overlays(ctx)
+	if err != nil {
+		return 0, err
+	}
+	requirements := scheduling.NewLabelRequirements(map[string]string{v1.LabelInstanceTypeStable: instanceType})
+	if cached, ok := d.requirements.Load(instanceType); ok {
+		requirements = scheduling.NewRequirements(cached.(scheduling.Requirements).Values()...)
+	}
+	return adjustPrice(ctx, price, offeringRequirements(requirements, capacityType, zone), overlays), nil
+}
+
+// overlays returns the NodeOverlays in the cluster ordered by name so that they're applied deterministically
+func (d *decorator) overlays(ctx context.Context) ([]v1alpha1.NodeOverlay, error) {
+	overlayList := &v1alpha1.NodeOverlayList{}
+	if err := d.kubeClient.List(ctx, overlayList); err != nil {
+		return nil, fmt.Errorf("listing nodeoverlays, %w", err)
+	}
+	sort.Slice(overlayList.Items, func(i, j int) bool { return overlayList.Items[i].Name < overlayList.Items[j].Name })
+	return overlayList.Items, nil
+}
+
+// apply returns a copy of the instance type with the matching overlays applied. The CloudProvider may cache its
+// instance types, so they're never modified in place.
+func apply(ctx context.Context, it *cloudprovider.InstanceType, overlays []v1alpha1.NodeOverlay) *cloudprovider.InstanceType {
+	matching := lo.Filter(overlays, func(o v1alpha1.NodeOverlay, _ int) bool {
+		return it.Requirements.Intersects(scheduling.NewNodeSelectorRequirements(o.Spec.Requirements...)) == nil
+	})
+	if len(matching) == 0 {
+		return it
+	}
+	overhead := cloudprovider.InstanceTypeOverhead{
+		KubeReserved:      it.Overhead.KubeReserved,
+		SystemReserved:    resources.Merge(append([]v1.ResourceList{it.Overhead.SystemReserved}, lo.Map(matching, func(o v1alpha1.NodeOverlay, _ int) v1.ResourceList { return o.Spec.Reserved })...)...),
+		EvictionThreshold: it.Overhead.EvictionThreshold,
+	}
+	return &cloudprovider.InstanceType{
+		Name:         it.Name,
+		Requirements: it.Requirements,
+		Offerings: lo.Map(it.Offerings, func(o cloudprovider.Offering, _ int) cloudprovider.Offering {
+			o.Price = adjustPrice(ctx, o.Price, offeringRequirements(it.Requirements, o.CapacityType, o.Zone), matching)
+			return o
+		}),
+		Capacity:     it.Capacity,
+		Overhead:     &overhead,
+		VolumeLimits: it.VolumeLimits,
+	}
+}
+
+// adjustPrice applies the price adjustments of the overlays that match the offering's requirements
+func adjustPrice(ctx context.Context, price float64, requirements scheduling.Requirements, overlays []v1alpha1.NodeOverlay) float64 {
+	for _, o := range overlays {
+		if requirements.Intersects(scheduling.NewNodeSelectorRequirements(o.Spec.Requirements...)) != nil {
+			continue
+		}
+		adjusted, err := o.Spec.AdjustPrice(price)
+		if err != nil {
+			// A bad overlay shouldn't prevent us from scheduling, so we ignore it
+			logging.FromContext(ctx).With("nodeoverlay", o.Name).Errorf("applying price adjustment, %s", err)
+			continue
+		}
+		price = adjusted
+	}
+	return price
+}
+
+func offeringRequirements(requirements scheduling.Requirements, capacityType, zone string) scheduling.Requirements {
+	offering := scheduling.NewRequirements(requirements.Values()...)
+	offering.Add(
+		scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, capacityType),
+		scheduling.NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, zone),
+	)
+	return offering
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overlay
+
+import (
+	"context"
+
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+)
+
+// notifier is implemented by the CloudProviders that Decorate returns
+type notifier interface {
+	overlaysChanged()
+}
+
+// Controller notifies the callbacks registered with NotifyOfferingChange of a decorated CloudProvider when a
+// NodeOverlay is created, updated or deleted, so that the instance types that Karpenter caches are retrieved again
+// with the overlays applied
+type Controller struct {
+	notifier notifier
+}
+
+// NewController constructs a controller for the CloudProvider returned by Decorate. It does nothing for any other
+// CloudProvider.
+func NewController(cloudProvider cloudprovider.CloudProvider) operatorcontroller.Controller {
+	n, _ := cloudProvider.(notifier)
+	return &Controller{notifier: n}
+}
+
+func (c *Controller) Name() string {
+	return "nodeoverlay"
+}
+
+func (c *Controller) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	if c.notifier != nil {
+		c.notifier.overlaysChanged()
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1alpha1.NodeOverlay{}))
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overlay_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/overlay"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeCloudProvider *fake.CloudProvider
+var cloudProvider cloudprovider.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Overlay")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	fakeCloudProvider = fake.NewCloudProvider()
+	cloudProvider = overlay.Decorate(fakeCloudProvider, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Overlay", func() {
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		fakeCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:         "small-instance-type",
+				Architecture: v1beta1.ArchitectureAmd64,
+				Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: true},
+					{CapacityType: v1beta1.CapacityTypeSpot, Zone: "test-zone-1", Price: 0.5, Available: true},
+				},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:         "arm-instance-type",
+				Architecture: v1beta1.ArchitectureArm64,
+				Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: true},
+				},
+			}),
+		}
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		fakeCloudProvider.Reset()
+	})
+
+	It("should return instance types unchanged without overlays", func() {
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(Equal(fakeCloudProvider.InstanceTypes))
+	})
+	It("should reserve resources on matching instance types", func() {
+		ExpectApplied(ctx, env.Client, &v1alpha1.NodeOverlay{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.NodeOverlaySpec{
+				Requirements: []v1.NodeSelectorRequirement{{Key: v1.LabelArchStable, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.ArchitectureAmd64}}},
+				Reserved:     v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		})
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		amd64, _ := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == "small-instance-type" })
+		arm64, _ := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == "arm-instance-type" })
+
+		original := fakeCloudProvider.InstanceTypes[0].Allocatable()
+		allocatable := amd64.Allocatable()
+		reserved := resource.MustParse("1Gi")
+		Expect(allocatable.Memory().Value()).To(Equal(original.Memory().Value() - reserved.Value()))
+		Expect(arm64.Allocatable()).To(Equal(fakeCloudProvider.InstanceTypes[1].Allocatable()))
+		// The cloudprovider's instance types shouldn't be modified
+		Expect(fakeCloudProvider.InstanceTypes[0].Allocatable()).To(Equal(original))
+	})
+	It("should adjust the price of matching offerings", func() {
+		ExpectApplied(ctx, env.Client, &v1alpha1.NodeOverlay{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.NodeOverlaySpec{
+				Requirements:    []v1.NodeSelectorRequirement{{Key: v1beta1.CapacityTypeLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.CapacityTypeSpot}}},
+				PriceAdjustment: lo.ToPtr("-10%"),
+			},
+		})
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		amd64, _ := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == "small-instance-type" })
+		onDemand, ok := amd64.Offerings.Get(v1beta1.CapacityTypeOnDemand, "test-zone-1")
+		Expect(ok).To(BeTrue())
+		Expect(onDemand.Price).To(BeNumerically("~", 1))
+		spot, ok := amd64.Offerings.Get(v1beta1.CapacityTypeSpot, "test-zone-1")
+		Expect(ok).To(BeTrue())
+		Expect(spot.Price).To(BeNumerically("~", 0.45))
+
+		price, err := cloudProvider.Price(ctx, "small-instance-type", v1beta1.CapacityTypeSpot, "test-zone-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(price).To(BeNumerically("~", 0.45))
+	})
+	It("should apply absolute price adjustments without going below zero", func() {
+		ExpectApplied(ctx, env.Client, &v1alpha1.NodeOverlay{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.NodeOverlaySpec{
+				Requirements:    []v1.NodeSelectorRequirement{{Key: v1.LabelArchStable, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.ArchitectureArm64}}},
+				PriceAdjustment: lo.ToPtr("-2"),
+			},
+		})
+		price, err := cloudProvider.Price(ctx, "arm-instance-type", v1beta1.CapacityTypeOnDemand, "test-zone-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(price).To(BeNumerically("==", 0))
+	})
+	Context("Offering Changes", func() {
+		var notifyingCloudProvider *fake.NotifyingCloudProvider
+		var decorated cloudprovider.CloudProvider
+		var changes [][]string
+
+		BeforeEach(func() {
+			notifyingCloudProvider = fake.NewNotifyingCloudProvider()
+			decorated = overlay.Decorate(notifyingCloudProvider, env.Client)
+			changes = nil
+		})
+		It("should notify the offering changes of the decorated cloudprovider", func() {
+			notifier, ok := cloudprovider.As[cloudprovider.OfferingChangeNotifier](decorated)
+			Expect(ok).To(BeTrue())
+			notifier.NotifyOfferingChange(func(instanceTypes ...string) { changes = append(changes, instanceTypes) })
+			notifyingCloudProvider.ChangeOfferings("small-instance-type")
+			Expect(changes).To(Equal([][]string{{"small-instance-type"}}))
+		})
+		It("should notify that every instance type changed when a nodeoverlay changes", func() {
+			notifier, ok := cloudprovider.As[cloudprovider.OfferingChangeNotifier](decorated)
+			Expect(ok).To(BeTrue())
+			notifier.NotifyOfferingChange(func(instanceTypes ...string) { changes = append(changes, instanceTypes) })
+			nodeOverlay := &v1alpha1.NodeOverlay{ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()}}
+			ExpectApplied(ctx, env.Client, nodeOverlay)
+			ExpectReconcileSucceeded(ctx, overlay.NewController(decorated), client.ObjectKeyFromObject(nodeOverlay))
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0]).To(BeEmpty())
+		})
+		It("should not notify offering changes if the decorated cloudprovider doesn't", func() {
+			_, ok := cloudprovider.As[cloudprovider.OfferingChangeNotifier](overlay.Decorate(fake.NewCloudProvider(), env.Client))
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
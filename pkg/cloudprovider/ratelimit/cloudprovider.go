@@ -0,0 +1,148 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+const (
+	methodCreate           = "Create"
+	methodDelete           = "Delete"
+	methodGet              = "Get"
+	methodList             = "List"
+	methodGetInstanceTypes = "GetInstanceTypes"
+	methodIsDrifted        = "IsDrifted"
+	methodPrice            = "Price"
+)
+
+// decorator implements CloudProvider
+var _ cloudprovider.Decorator = (*decorator)(nil)
+var _ cloudprovider.BatchCreator = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+	limiters map[string]*limiter
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument,
+// `cloudProvider`, within the budgets of its methods in the cloudprovider rate limits option. The budgets are shared by
+// every controller that uses the returned instance. A method's budget is lowered while the cloudprovider throttles
+// it, and recovers once it stops. Calls that would have to wait too long for their method's budget fail with a
+// ThrottledError without being made.
+//
+// The returned instance supports the optional interfaces that `cloudProvider` supports, which are looked up with
+// `cloudprovider.As`.
+func Decorate(ctx context.Context, cloudProvider cloudprovider.CloudProvider, clk clock.Clock) cloudprovider.CloudProvider {
+	// The rate limits are validated when the options are parsed
+	rateLimits, _ := options.ParseRateLimits(options.FromContext(ctx).CloudProviderRateLimits)
+	d := &decorator{CloudProvider: cloudProvider, limiters: map[string]*limiter{}}
+	for _, method := range options.RateLimitedMethods {
+		d.limiters[method] = newLimiter(method, rateLimits, clk)
+	}
+	return d
+}
+
+// Unwrap returns the decorated CloudProvider
+func (d *decorator) Unwrap() cloudprovider.CloudProvider {
+	return d.CloudProvider
+}
+
+func (d *decorator) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	if err := d.limiters[methodCreate].wait(ctx, 1); err != nil {
+		return nil, err
+	}
+	created, err := d.CloudProvider.Create(ctx, nodeClaim)
+	d.limiters[methodCreate].observe(err)
+	return created, err
+}
+
+// CreateBatch counts each NodeClaim in the batch against the budget of Create
+func (d *decorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	if err := d.limiters[methodCreate].wait(ctx, len(nodeClaims)); err != nil {
+		errs := make([]error, len(nodeClaims))
+		for i := range errs {
+			errs[i] = err
+		}
+		return make([]*v1beta1.NodeClaim, len(nodeClaims)), errs
+	}
+	created, errs := cloudprovider.CreateBatch(ctx, d.CloudProvider, nodeClaims)
+	for _, err := range errs {
+		d.limiters[methodCreate].observe(err)
+	}
+	return created, errs
+}
+
+func (d *decorator) Delete(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	if err := d.limiters[methodDelete].wait(ctx, 1); err != nil {
+		return err
+	}
+	err := d.CloudProvider.Delete(ctx, nodeClaim)
+	d.limiters[methodDelete].observe(err)
+	return err
+}
+
+func (d *decorator) Get(ctx context.Context, providerID string) (*v1beta1.NodeClaim, error) {
+	if err := d.limiters[methodGet].wait(ctx, 1); err != nil {
+		return nil, err
+	}
+	nodeClaim, err := d.CloudProvider.Get(ctx, providerID)
+	d.limiters[methodGet].observe(err)
+	return nodeClaim, err
+}
+
+func (d *decorator) List(ctx context.Context) ([]*v1beta1.NodeClaim, error) {
+	if err := d.limiters[methodList].wait(ctx, 1); err != nil {
+		return nil, err
+	}
+	nodeClaims, err := d.CloudProvider.List(ctx)
+	d.limiters[methodList].observe(err)
+	return nodeClaims, err
+}
+
+func (d *decorator) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	if err := d.limiters[methodGetInstanceTypes].wait(ctx, 1); err != nil {
+		return nil, err
+	}
+	instanceTypes, err := d.CloudProvider.GetInstanceTypes(ctx, nodePool)
+	d.limiters[methodGetInstanceTypes].observe(err)
+	return instanceTypes, err
+}
+
+func (d *decorator) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+	if err := d.limiters[methodIsDrifted].wait(ctx, 1); err != nil {
+		return "", err
+	}
+	driftReason, err := d.CloudProvider.IsDrifted(ctx, nodeClaim)
+	d.limiters[methodIsDrifted].observe(err)
+	return driftReason, err
+}
+
+func (d *decorator) Price(ctx context.Context, instanceType, capacityType, zone string) (float64, error) {
+	if err := d.limiters[methodPrice].wait(ctx, 1); err != nil {
+		return 0, err
+	}
+	price, err := d.CloudProvider.Price(ctx, instanceType, capacityType, zone)
+	d.limiters[methodPrice].observe(err)
+	return price, err
+}
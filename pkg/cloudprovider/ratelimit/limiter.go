@@ -0,0 +1,170 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/utils/clock"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+const (
+	// MaxWait is the longest that a call waits for its method's budget. Calls that would wait longer fail with a
+	// ThrottledError, so that controllers requeue them rather than holding on to their workers.
+	MaxWait = 5 * time.Second
+	// DefaultThrottleBackoff is how long a method's calls are held back when the cloudprovider throttles it without
+	// saying for how long
+	DefaultThrottleBackoff = time.Second
+	// minRateFraction is the lowest fraction of its budget that a method's rate is lowered to while it's throttled
+	minRateFraction = 0.1
+	// recoveryFraction is the fraction of its budget that a method's rate recovers by with each call that isn't
+	// throttled
+	recoveryFraction = 0.05
+
+	metricLabelMethod = "method"
+)
+
+var (
+	throttledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "throttled_total",
+			Help:      "Number of cloud provider calls that the cloud provider throttled. Labeled by method.",
+		},
+		[]string{metricLabelMethod},
+	)
+	rateLimitedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "rate_limited_total",
+			Help:      "Number of cloud provider calls that weren't made because their method's client-side budget was exhausted. Labeled by method.",
+		},
+		[]string{metricLabelMethod},
+	)
+	rateLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "rate_limit",
+			Help:      "The current client-side budget of cloud provider calls in calls per second, which is lowered while the cloud provider throttles them. Labeled by method.",
+		},
+		[]string{metricLabelMethod},
+	)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(throttledTotal, rateLimitedTotal, rateLimit)
+}
+
+// limiter holds back the calls of a method to stay within its budget. Its rate is halved each time the cloudprovider
+// throttles the method, down to a fraction of the budget, and recovers gradually with the calls that aren't throttled.
+type limiter struct {
+	method string
+	// budget is nil for methods without a budget, whose calls are only held back while the cloudprovider asks for it
+	budget *options.RateLimit
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	limiter *rate.Limiter
+	// heldUntil is when the cloudprovider allows calls of the method again after it throttled them
+	heldUntil time.Time
+}
+
+func newLimiter(method string, rateLimits map[string]options.RateLimit, clk clock.Clock) *limiter {
+	l := &limiter{method: method, clock: clk, limiter: rate.NewLimiter(rate.Inf, 0)}
+	if budget, ok := rateLimits[method]; ok {
+		l.budget = &budget
+		l.limiter = rate.NewLimiter(rate.Limit(budget.QPS), budget.Burst)
+		rateLimit.With(prometheus.Labels{metricLabelMethod: method}).Set(budget.QPS)
+	}
+	return l
+}
+
+// wait waits until n calls of the method are within its budget. It returns a ThrottledError without waiting if that
+// would take longer than MaxWait.
+func (l *limiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	start := now
+	if l.heldUntil.After(now) {
+		start = l.heldUntil
+	}
+	// Calls beyond the burst can never be within the budget at once, so they're let through with a full burst
+	if n > l.limiter.Burst() && l.limiter.Limit() != rate.Inf {
+		n = l.limiter.Burst()
+	}
+	reservation := l.limiter.ReserveN(start, n)
+	delay := reservation.DelayFrom(now)
+	if delay > MaxWait {
+		reservation.CancelAt(now)
+		l.mu.Unlock()
+		rateLimitedTotal.With(prometheus.Labels{metricLabelMethod: l.method}).Inc()
+		return cloudprovider.NewThrottledError(fmt.Errorf("%s exceeded its client-side budget", l.method), delay)
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-l.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		reservation.CancelAt(l.clock.Now())
+		return ctx.Err()
+	}
+}
+
+// observe adapts the rate of the method to the result of a call
+func (l *limiter) observe(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if cloudprovider.IsThrottledError(err) {
+		throttledTotal.With(prometheus.Labels{metricLabelMethod: l.method}).Inc()
+		retryAfter, ok := cloudprovider.RetryAfter(err)
+		if !ok {
+			retryAfter = DefaultThrottleBackoff
+		}
+		if until := now.Add(retryAfter); until.After(l.heldUntil) {
+			l.heldUntil = until
+		}
+		if l.budget != nil {
+			l.setLimit(now, max(l.limiter.Limit()/2, rate.Limit(l.budget.QPS*minRateFraction)))
+		}
+		return
+	}
+	if l.budget != nil && l.limiter.Limit() < rate.Limit(l.budget.QPS) {
+		l.setLimit(now, min(l.limiter.Limit()+rate.Limit(l.budget.QPS*recoveryFraction), rate.Limit(l.budget.QPS)))
+	}
+}
+
+func (l *limiter) setLimit(now time.Time, limit rate.Limit) {
+	l.limiter.SetLimitAt(now, limit)
+	rateLimit.With(prometheus.Labels{metricLabelMethod: l.method}).Set(float64(limit))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	clock "k8s.io/utils/clock/testing"
+	. "knative.dev/pkg/logging/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/ratelimit"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var ctx context.Context
+var fakeClock *clock.FakeClock
+var fakeCloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RateLimit")
+}
+
+var _ = BeforeEach(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	fakeCloudProvider = fake.NewCloudProvider()
+})
+
+// decorate returns the fake cloudprovider decorated with the rate limits
+func decorate(rateLimits string) cloudprovider.CloudProvider {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{CloudProviderRateLimits: lo.ToPtr(rateLimits)}))
+	return ratelimit.Decorate(ctx, fakeCloudProvider, fakeClock)
+}
+
+var _ = Describe("RateLimit", func() {
+	It("should keep the optional interfaces of the cloudprovider", func() {
+		cloudProvider := decorate("")
+		_, ok := cloudprovider.As[cloudprovider.InterruptionProvider](cloudProvider)
+		Expect(ok).To(BeTrue())
+		_, ok = cloudprovider.As[cloudprovider.WarmPoolProvider](cloudProvider)
+		Expect(ok).To(BeTrue())
+		_, ok = cloudprovider.As[cloudprovider.BatchCreator](cloudProvider)
+		Expect(ok).To(BeTrue())
+	})
+	It("should not limit methods without a budget", func() {
+		cloudProvider := decorate("Delete=1:1")
+		for i := 0; i < 100; i++ {
+			_, err := cloudProvider.Create(ctx, test.NodeClaim())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(100))
+	})
+	It("should fail calls that would wait too long for their method's budget", func() {
+		cloudProvider := decorate("Create=0.01:2")
+		for i := 0; i < 2; i++ {
+			_, err := cloudProvider.Create(ctx, test.NodeClaim())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err := cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+		retryAfter, ok := cloudprovider.RetryAfter(err)
+		Expect(ok).To(BeTrue())
+		Expect(retryAfter).To(BeNumerically(">", ratelimit.MaxWait))
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(2))
+
+		// The budget refills over time
+		fakeClock.Step(100 * time.Second)
+		_, err = cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(3))
+	})
+	It("should count each nodeclaim of a batch against the budget of Create", func() {
+		cloudProvider := decorate("Create=0.01:3")
+		batchCreator, ok := cloudprovider.As[cloudprovider.BatchCreator](cloudProvider)
+		Expect(ok).To(BeTrue())
+		_, errs := batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+		Expect(errs).To(HaveEach(BeNil()))
+		_, errs = batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+		Expect(errs).To(HaveEach(Satisfy(cloudprovider.IsThrottledError)))
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(2))
+	})
+	It("should hold back a method's calls for as long as the cloudprovider asks once it's throttled", func() {
+		cloudProvider := decorate("")
+		fakeCloudProvider.NextCreateErr = cloudprovider.NewThrottledError(fmt.Errorf("request limit exceeded"), time.Minute)
+		_, err := cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+
+		_, err = cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+		Expect(fakeCloudProvider.CreateCalls).To(BeEmpty())
+		// Other methods aren't held back
+		_, err = cloudProvider.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Step(time.Minute)
+		_, err = cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should lower a method's budget while it's throttled", func() {
+		// A burst of one call every 4s is within the maximum wait, until throttling halves the rate
+		cloudProvider := decorate("Create=0.25:1")
+		fakeCloudProvider.NextCreateErr = cloudprovider.NewThrottledError(fmt.Errorf("request limit exceeded"), time.Second)
+		_, err := cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+
+		fakeClock.Step(time.Second)
+		_, err = cloudProvider.Create(ctx, test.NodeClaim())
+		Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+		retryAfter, _ := cloudprovider.RetryAfter(err)
+		Expect(retryAfter).To(BeNumerically(">", ratelimit.MaxWait))
+		Expect(fakeCloudProvider.CreateCalls).To(BeEmpty())
+	})
+})
@@ -23,9 +23,11 @@ import (
 	"math"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -34,6 +36,171 @@ import (
 
 type DriftReason string
 
+const (
+	// NodeImageDrifted is the drift reason that cloudproviders return when a NodeClaim's node image is no longer the
+	// one that its NodeClass resolves to, so that NodePools can disable the check
+	NodeImageDrifted DriftReason = "NodeImageDrifted"
+	// NodeClassHashDrifted is the drift reason that cloudproviders return when the hash of a NodeClaim's NodeClass has
+	// changed since it was launched, so that NodePools can disable the check
+	NodeClassHashDrifted DriftReason = "NodeClassHashDrifted"
+)
+
+// RepairPolicy is a node condition that, when present on a node for longer than the TolerationDuration,
+// causes Karpenter to consider the node unhealthy and replace it
+type RepairPolicy struct {
+	// ConditionType is the type of the node condition that indicates an unhealthy node
+	ConditionType v1.NodeConditionType
+	// ConditionStatus is the status of the node condition when the node is unhealthy
+	ConditionStatus v1.ConditionStatus
+	// TolerationDuration is how long the node can have the unhealthy condition before it is replaced
+	TolerationDuration time.Duration
+}
+
+// InterruptionKind describes why an instance is being interrupted, e.g. SpotInterruption
+type InterruptionKind string
+
+const (
+	// SpotInterruptionKind is an interruption of a spot instance that is being reclaimed by the cloudprovider
+	SpotInterruptionKind InterruptionKind = "SpotInterruption"
+	// ScheduledChangeKind is an interruption of an instance due to scheduled maintenance
+	ScheduledChangeKind InterruptionKind = "ScheduledChange"
+)
+
+// Interruption is a notice from the cloudprovider that an instance is about to be involuntarily terminated
+type Interruption struct {
+	// ID uniquely identifies the interruption so that it can be acknowledged
+	ID string
+	// ProviderID is the provider id of the instance that is being interrupted
+	ProviderID string
+	// Kind describes why the instance is being interrupted
+	Kind InterruptionKind
+}
+
+// InterruptionProvider is optionally implemented by cloud providers that are able to notify Karpenter of instance
+// interruptions. Karpenter will cordon and drain interrupted nodes and launch replacement capacity for their pods
+// ahead of the instance being terminated.
+type InterruptionProvider interface {
+	// GetInterruptions returns the interruptions that haven't been acknowledged yet
+	GetInterruptions(context.Context) ([]*Interruption, error)
+	// AcknowledgeInterruption marks an interruption as handled so it isn't returned by GetInterruptions again
+	AcknowledgeInterruption(context.Context, *Interruption) error
+}
+
+// WarmPoolProvider is optionally implemented by cloud providers that are able to stop and start instances. Karpenter
+// keeps the stopped instances of a nodepool's warm pool around, and starts them when pods go pending rather than
+// launching new capacity. Stopped instances must still be returned by Get and List.
+type WarmPoolProvider interface {
+	// Stop stops the NodeClaim's instance without terminating it
+	Stop(context.Context, *v1beta1.NodeClaim) error
+	// Start starts the NodeClaim's stopped instance so that it registers with the cluster again
+	Start(context.Context, *v1beta1.NodeClaim) error
+}
+
+// BatchCreator is optionally implemented by cloud providers that are able to launch many NodeClaims with a single
+// request, e.g. through a fleet or bulk API. Karpenter groups the launches of a nodepool's NodeClaims that arrive
+// together, and launches them with CreateBatch rather than calling Create for each of them.
+type BatchCreator interface {
+	// CreateBatch launches the NodeClaims and returns, in the same order as the NodeClaims, the hydrated NodeClaim or
+	// the error that each of them failed to launch with
+	CreateBatch(context.Context, []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error)
+}
+
+// DryRunCreator is optionally implemented by cloud providers that are able to validate a launch without launching
+// anything. Karpenter dry-runs the launch of each nodepool's NodeClaim template when the nodepool changes, and surfaces
+// failures (e.g. an image that doesn't exist) on the nodepool before any pods go pending on it.
+type DryRunCreator interface {
+	// CreateDryRun returns the error that Create would fail to launch the NodeClaim with, if any, without launching it
+	CreateDryRun(context.Context, *v1beta1.NodeClaim) error
+}
+
+// MaxPodsProvider is optionally implemented by cloud providers whose nodes can run fewer pods than their instance types'
+// pods capacity, e.g. because the CNI limits the number of pod IPs of each instance. Karpenter uses the number of pods
+// that it returns as the pods capacity of the instance type when simulating nodes, so that it doesn't pack more pods
+// onto a node than it can run.
+type MaxPodsProvider interface {
+	// MaxPods returns the number of pods that a node of the instance type can run with the kubelet configuration
+	MaxPods(context.Context, *InstanceType, *v1beta1.KubeletConfiguration) int64
+}
+
+// OfferingChangeNotifier is optionally implemented by cloud providers that are able to tell Karpenter when the instance
+// types or offerings that GetInstanceTypes returns change, e.g. when availability or prices change, or a NodeClass is
+// updated. Karpenter caches the instance types of each nodepool until they change, and doesn't cache the instance types
+// of cloud providers that don't implement it.
+type OfferingChangeNotifier interface {
+	// NotifyOfferingChange registers a callback that the cloud provider calls with the names of the instance types that
+	// changed. Calling it without any names means that any instance type may have changed.
+	NotifyOfferingChange(func(instanceTypes ...string))
+}
+
+// Decorator is implemented by CloudProviders that wrap another CloudProvider, e.g. to publish metrics for its calls.
+// A decorator can implement optional interfaces to decorate their methods as well, but it only supports the ones that
+// the CloudProvider that it wraps supports, so optional interfaces must be looked up with As rather than with a type
+// assertion.
+type Decorator interface {
+	CloudProvider
+	// Unwrap returns the CloudProvider that the decorator wraps
+	Unwrap() CloudProvider
+}
+
+// As returns the cloud provider as the optional interface T, e.g. BatchCreator, if it supports it. A decorator
+// supports T if the CloudProvider that it wraps does. Its own implementation of T is returned if it has one, so that
+// the calls are decorated, and the wrapped CloudProvider's otherwise.
+func As[T any](cloudProvider CloudProvider) (T, bool) {
+	decorator, ok := cloudProvider.(Decorator)
+	if !ok {
+		t, ok := cloudProvider.(T)
+		return t, ok
+	}
+	wrapped, ok := As[T](decorator.Unwrap())
+	if !ok {
+		return wrapped, false
+	}
+	if t, ok := cloudProvider.(T); ok {
+		return t, true
+	}
+	return wrapped, true
+}
+
+// MaxPods returns the number of pods that a node of the instance type can run with the kubelet configuration. An
+// explicit maxPods in the kubelet configuration takes precedence, followed by the cloud provider's MaxPods if it
+// implements MaxPodsProvider, and the instance type's pods capacity otherwise. The result is capped by the kubelet's
+// podsPerCore.
+func MaxPods(ctx context.Context, cloudProvider CloudProvider, instanceType *InstanceType, kubelet *v1beta1.KubeletConfiguration) int64 {
+	pods := instanceType.Capacity.Pods().Value()
+	if maxPodsProvider, ok := As[MaxPodsProvider](cloudProvider); ok {
+		pods = maxPodsProvider.MaxPods(ctx, instanceType, kubelet)
+	}
+	if kubelet == nil {
+		return pods
+	}
+	if kubelet.MaxPods != nil {
+		pods = int64(lo.FromPtr(kubelet.MaxPods))
+	}
+	if podsPerCore := int64(lo.FromPtr(kubelet.PodsPerCore)); podsPerCore > 0 {
+		pods = lo.Min([]int64{pods, podsPerCore * instanceType.Capacity.Cpu().Value()})
+	}
+	return pods
+}
+
+// CreateBatch launches the NodeClaims with the cloud provider's CreateBatch if it implements BatchCreator, and
+// otherwise calls Create for each of them in parallel
+func CreateBatch(ctx context.Context, cloudProvider CloudProvider, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	if batchCreator, ok := As[BatchCreator](cloudProvider); ok {
+		return batchCreator.CreateBatch(ctx, nodeClaims)
+	}
+	created, errs := make([]*v1beta1.NodeClaim, len(nodeClaims)), make([]error, len(nodeClaims))
+	var wg sync.WaitGroup
+	for i := range nodeClaims {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			created[i], errs[i] = cloudProvider.Create(ctx, nodeClaims[i])
+		}(i)
+	}
+	wg.Wait()
+	return created, errs
+}
+
 // CloudProvider interface is implemented by cloud providers to support provisioning.
 type CloudProvider interface {
 	// Create launches a NodeClaim with the given resource requests and requirements and returns a hydrated
@@ -51,8 +218,15 @@ type CloudProvider interface {
 	// even those with no offerings available.
 	GetInstanceTypes(context.Context, *v1beta1.NodePool) ([]*InstanceType, error)
 	// IsDrifted returns whether a NodeClaim has drifted from the provisioning requirements
-	// it is tied to.
+	// it is tied to. Drift of the node image or the NodeClass's hash should be returned as
+	// NodeImageDrifted or NodeClassHashDrifted, which NodePools can disable.
 	IsDrifted(context.Context, *v1beta1.NodeClaim) (DriftReason, error)
+	// Price returns the hourly price of an instance type for the capacity type in the zone. It's used to estimate the
+	// cost impact of disruption decisions.
+	Price(ctx context.Context, instanceType, capacityType, zone string) (float64, error)
+	// RepairPolicies returns the cloudprovider-specific node conditions that indicate an unhealthy node, in addition
+	// to the NotReady and DiskPressure conditions that Karpenter always watches for.
+	RepairPolicies() []RepairPolicy
 	// Name returns the CloudProvider implementation name.
 	Name() string
 }
@@ -72,6 +246,10 @@ type InstanceType struct {
 	// Overhead is the amount of resource overhead expected to be used by kubelet and any other system daemons outside
 	// of Kubernetes.
 	Overhead *InstanceTypeOverhead
+	// VolumeLimits are the maximum number of volumes of each CSI driver that can be attached to an instance of this
+	// type. They're used until the node's CSINode reports its own limits. Volumes of drivers without a limit aren't
+	// limited.
+	VolumeLimits map[string]int
 
 	once        sync.Once
 	allocatable v1.ResourceList
@@ -90,6 +268,22 @@ func (i *InstanceType) Allocatable() v1.ResourceList {
 	return i.allocatable.DeepCopy()
 }
 
+// WithPodsCapacity returns a copy of the instance type with the pods capacity, or the instance type itself if its pods
+// capacity already matches
+func (i *InstanceType) WithPodsCapacity(pods int64) *InstanceType {
+	if i.Capacity.Pods().Value() == pods {
+		return i
+	}
+	return &InstanceType{
+		Name:         i.Name,
+		Requirements: i.Requirements,
+		Offerings:    i.Offerings,
+		Capacity:     lo.Assign(i.Capacity, v1.ResourceList{v1.ResourcePods: *resource.NewQuantity(pods, resource.DecimalSI)}),
+		Overhead:     i.Overhead,
+		VolumeLimits: i.VolumeLimits,
+	}
+}
+
 func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceTypes {
 	// Order instance types so that we get the cheapest instance types of the available offerings
 	sort.Slice(its, func(i, j int) bool {
@@ -142,6 +336,14 @@ type Offering struct {
 	// Available is added so that Offerings can return all offerings that have ever existed for an instance type,
 	// so we can get historical pricing data for calculating savings in consolidation
 	Available bool
+	// Domains are the offering's domains for topology keys other than the zone and capacity type, e.g. the rack or
+	// placement group that it launches into, keyed by the node label of the topology key. They're only used as
+	// topology domains for the nodepools that declare the key in their topology keys.
+	Domains map[string]string
+	// ReservationCapacity is the number of instances that the capacity reservations of the instance type in the zone
+	// hold, including the ones that are already running in them. It's only used for offerings with the
+	// capacity-reservation capacity type.
+	ReservationCapacity int
 }
 
 type Offerings []Offering
@@ -164,11 +366,37 @@ func (ofs Offerings) Available() Offerings {
 // Compatible returns the offerings based on the passed requirements
 func (ofs Offerings) Compatible(reqs scheduling.Requirements) Offerings {
 	return lo.Filter(ofs, func(offering Offering, _ int) bool {
-		return (!reqs.Has(v1.LabelTopologyZone) || reqs.Get(v1.LabelTopologyZone).Has(offering.Zone)) &&
-			(!reqs.Has(v1beta1.CapacityTypeLabelKey) || reqs.Get(v1beta1.CapacityTypeLabelKey).Has(offering.CapacityType))
+		return offering.Compatible(reqs)
 	})
 }
 
+// Compatible returns whether the offering's zone, capacity type and domains are allowed by the requirements. An offering
+// that doesn't have a zone, capacity type or domain that the requirements constrain isn't compatible, since we can't
+// tell which one its nodes would be launched into.
+func (o Offering) Compatible(reqs scheduling.Requirements) bool {
+	if !allows(reqs, v1.LabelTopologyZone, o.Zone) || !allows(reqs, v1beta1.CapacityTypeLabelKey, o.CapacityType) {
+		return false
+	}
+	for key, domain := range o.Domains {
+		if !allows(reqs, key, domain) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows returns whether the requirements allow the value for the key. A missing value is only allowed if the key isn't
+// constrained, or is required not to exist.
+func allows(reqs scheduling.Requirements, key string, value string) bool {
+	if !reqs.Has(key) {
+		return true
+	}
+	if value == "" {
+		return reqs.Get(key).Operator() == v1.NodeSelectorOpDoesNotExist
+	}
+	return reqs.Get(key).Has(value)
+}
+
 // Cheapest returns the cheapest offering from the returned offerings
 func (ofs Offerings) Cheapest() Offering {
 	return lo.MinBy(ofs, func(a, b Offering) bool {
@@ -265,3 +493,95 @@ func IgnoreNodeClassNotReadyError(err error) error {
 	}
 	return err
 }
+
+// RetryableCapacityError is an error type returned by CloudProviders when a launch fails due to a lack of capacity that
+// is expected to become available again after RetryAfter. Unlike with an InsufficientCapacityError, the NodeClaim is
+// kept and its launch is retried once the delay has passed.
+type RetryableCapacityError struct {
+	error
+	RetryAfter time.Duration
+}
+
+func NewRetryableCapacityError(err error, retryAfter time.Duration) *RetryableCapacityError {
+	return &RetryableCapacityError{
+		error:      err,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *RetryableCapacityError) Error() string {
+	return fmt.Sprintf("capacity temporarily unavailable, retry after %s, %s", e.RetryAfter, e.error)
+}
+
+func IsRetryableCapacityError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rcErr *RetryableCapacityError
+	return errors.As(err, &rcErr)
+}
+
+// ThrottledError is an error type returned by CloudProviders when the cloudprovider's API throttled a request.
+// RetryAfter is how long the API asked for requests to be held back, e.g. from a Retry-After header, or zero if it
+// didn't say.
+type ThrottledError struct {
+	error
+	RetryAfter time.Duration
+}
+
+func NewThrottledError(err error, retryAfter time.Duration) *ThrottledError {
+	return &ThrottledError{
+		error:      err,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("throttled, %s", e.error)
+}
+
+func IsThrottledError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var tErr *ThrottledError
+	return errors.As(err, &tErr)
+}
+
+// TerminalError is an error type returned by CloudProviders when a request can't succeed no matter how often it's
+// retried, e.g. because the NodeClaim's configuration is invalid
+type TerminalError struct {
+	error
+}
+
+func NewTerminalError(err error) *TerminalError {
+	return &TerminalError{
+		error: err,
+	}
+}
+
+func (e *TerminalError) Error() string {
+	return fmt.Sprintf("terminal, %s", e.error)
+}
+
+func IsTerminalError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var tErr *TerminalError
+	return errors.As(err, &tErr)
+}
+
+// RetryAfter returns the delay that a RetryableCapacityError or ThrottledError asks for before the request is retried.
+// It returns false for other errors and for errors without a delay, which are retried with the usual backoff.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rcErr *RetryableCapacityError
+	if errors.As(err, &rcErr) && rcErr.RetryAfter > 0 {
+		return rcErr.RetryAfter, true
+	}
+	var tErr *ThrottledError
+	if errors.As(err, &tErr) && tErr.RetryAfter > 0 {
+		return tErr.RetryAfter, true
+	}
+	return 0, false
+}
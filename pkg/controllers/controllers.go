@@ -22,23 +22,38 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	disruptiongarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/disruption/garbagecollection"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
+	"sigs.k8s.io/karpenter/pkg/controllers/interruption"
 	"sigs.k8s.io/karpenter/pkg/controllers/leasegarbagecollection"
 	metricsnode "sigs.k8s.io/karpenter/pkg/controllers/metrics/node"
 	metricsnodepool "sigs.k8s.io/karpenter/pkg/controllers/metrics/nodepool"
 	metricspod "sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/drain"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/templatesync"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	nodeclaimconsistency "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/consistency"
 	nodeclaimdisruption "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/disruption"
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
+	nodeclaimhydration "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/hydration"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
 	nodeclaimtermination "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/termination"
+	nodepooladmissionpolicy "sigs.k8s.io/karpenter/pkg/controllers/nodepool/admissionpolicy"
 	nodepoolcounter "sigs.k8s.io/karpenter/pkg/controllers/nodepool/counter"
 	nodepoolhash "sigs.k8s.io/karpenter/pkg/controllers/nodepool/hash"
+	nodepoolhealth "sigs.k8s.io/karpenter/pkg/controllers/nodepool/health"
+	nodepoolminnodes "sigs.k8s.io/karpenter/pkg/controllers/nodepool/minnodes"
+	nodepoolvalidation "sigs.k8s.io/karpenter/pkg/controllers/nodepool/validation"
+	nodepoolwarmpool "sigs.k8s.io/karpenter/pkg/controllers/nodepool/warmpool"
+	"sigs.k8s.io/karpenter/pkg/controllers/operatorconfig"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	stateconsistency "sigs.k8s.io/karpenter/pkg/controllers/state/consistency"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	statemetrics "sigs.k8s.io/karpenter/pkg/controllers/state/metrics"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/nominations"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
 )
@@ -46,36 +61,56 @@ import (
 func NewControllers(
 	clock clock.Clock,
 	kubeClient client.Client,
+	apiReader client.Reader,
 	cluster *state.Cluster,
 	recorder events.Recorder,
 	cloudProvider cloudprovider.CloudProvider,
 ) []controller.Controller {
 
-	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster)
+	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock)
 	evictionQueue := terminator.NewQueue(kubeClient, recorder)
+	nodeTerminator := terminator.NewTerminator(clock, kubeClient, evictionQueue)
 	disruptionQueue := orchestration.NewQueue(kubeClient, recorder, cluster, clock, p)
 
 	return []controller.Controller{
 		p, evictionQueue, disruptionQueue,
 		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue),
+		disruptiongarbagecollection.NewController(clock, kubeClient),
+		interruption.NewController(kubeClient, cloudProvider, cluster, p, recorder),
 		provisioning.NewPodController(kubeClient, p, recorder),
 		provisioning.NewNodeController(kubeClient, p, recorder),
+		provisioning.NewNodePoolController(kubeClient, p),
+		provisioning.NewNodeClaimController(kubeClient, p),
 		nodepoolhash.NewController(kubeClient),
+		nodepooladmissionpolicy.NewController(kubeClient),
 		informer.NewDaemonSetController(kubeClient, cluster),
 		informer.NewNodeController(kubeClient, cluster),
 		informer.NewPodController(kubeClient, cluster),
 		informer.NewNodePoolController(kubeClient, cluster),
 		informer.NewNodeClaimController(kubeClient, cluster),
-		termination.NewController(kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue), recorder),
+		nominations.NewController(clock, kubeClient, cluster),
+		stateconsistency.NewController(apiReader, cluster, recorder),
+		statemetrics.NewController(cluster),
+		statemetrics.NewPodController(clock, kubeClient),
+		termination.NewController(clock, kubeClient, cloudProvider, nodeTerminator, recorder),
+		drain.NewController(kubeClient, nodeTerminator),
+		health.NewController(clock, kubeClient, cloudProvider, recorder),
+		templatesync.NewController(kubeClient),
 		metricspod.NewController(kubeClient),
 		metricsnodepool.NewController(kubeClient),
 		metricsnode.NewController(cluster),
 		nodepoolcounter.NewController(kubeClient, cluster),
+		nodepoolhealth.NewController(kubeClient, cluster),
+		nodepoolminnodes.NewController(clock, kubeClient, cluster, p),
+		nodepoolwarmpool.NewController(kubeClient, cluster, cloudProvider, p),
+		nodepoolvalidation.NewController(kubeClient, cloudProvider),
 		nodeclaimconsistency.NewController(clock, kubeClient, recorder, cloudProvider),
-		nodeclaimlifecycle.NewController(clock, kubeClient, cloudProvider, recorder),
+		nodeclaimlifecycle.NewController(clock, kubeClient, cluster, cloudProvider, recorder),
 		nodeclaimgarbagecollection.NewController(clock, kubeClient, cloudProvider),
-		nodeclaimtermination.NewController(kubeClient, cloudProvider),
-		nodeclaimdisruption.NewController(clock, kubeClient, cluster, cloudProvider),
+		nodeclaimhydration.NewController(clock, kubeClient, cloudProvider),
+		nodeclaimtermination.NewController(clock, kubeClient, cloudProvider),
+		nodeclaimdisruption.NewController(clock, kubeClient, cluster, cloudProvider, recorder),
 		leasegarbagecollection.NewController(kubeClient),
+		operatorconfig.NewController(kubeClient),
 	}
 }
@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// maxRecordedInstanceTypes is the number of the cheapest instance types of each replacement that are recorded
+const maxRecordedInstanceTypes = 5
+
+// recordAction creates the DisruptionAction that records the command with the result. Recording is best effort, since
+// failing to record a command shouldn't hold back its execution. Commands aren't recorded if their retention is zero.
+func (c *Controller) recordAction(ctx context.Context, name string, m Method, cmd Command, nodeClaimNames []string, result v1alpha1.DisruptionResult) {
+	if options.FromContext(ctx).DisruptionActionRetention == 0 {
+		return
+	}
+	action := newDisruptionAction(name, m, cmd, nodeClaimNames)
+	action.Spec.DryRun = result == v1alpha1.DisruptionResultDryRun
+	if err := c.kubeClient.Create(ctx, action); err != nil {
+		// Dry runs are recorded under the same name every time that the same command is computed
+		if !errors.IsAlreadyExists(err) {
+			logging.FromContext(ctx).Errorf("creating disruption action, %s", err)
+		}
+		return
+	}
+	action.Status.Result = result
+	if result != v1alpha1.DisruptionResultPending {
+		action.Status.CompletionTime = lo.ToPtr(metav1.NewTime(c.clock.Now()))
+	}
+	if err := c.kubeClient.Status().Update(ctx, action); err != nil {
+		logging.FromContext(ctx).Errorf("updating disruption action status, %s", err)
+	}
+}
+
+func newDisruptionAction(name string, m Method, cmd Command, nodeClaimNames []string) *v1alpha1.DisruptionAction {
+	action := &v1alpha1.DisruptionAction{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.DisruptionActionSpec{
+			Method:            m.Type(),
+			ConsolidationType: m.ConsolidationType(),
+			Reason:            string(m.Reason()),
+			Action:            string(cmd.Action()),
+			Candidates: lo.Map(cmd.candidates, func(cd *Candidate, _ int) v1alpha1.DisruptionCandidate {
+				return v1alpha1.DisruptionCandidate{
+					NodeClaim:    cd.NodeClaim.Name,
+					Node:         cd.Node.Name,
+					NodePool:     cd.nodePool.Name,
+					InstanceType: cd.instanceType.Name,
+					CapacityType: cd.capacityType,
+					Zone:         cd.zone,
+					Pods:         len(cd.reschedulablePods),
+				}
+			}),
+		},
+	}
+	for i, replacement := range cmd.replacements {
+		// The instance types are copied before they're ordered, since the replacement's own order is used to launch it
+		instanceTypes := cloudprovider.InstanceTypes(slices.Clone(replacement.InstanceTypeOptions)).OrderByPrice(replacement.Requirements)
+		recorded := v1alpha1.DisruptionReplacement{
+			NodePool: replacement.NodePoolName,
+			InstanceTypes: lo.Map(lo.Slice(instanceTypes, 0, maxRecordedInstanceTypes), func(it *cloudprovider.InstanceType, _ int) string {
+				return it.Name
+			}),
+		}
+		// Dry runs don't create their replacements
+		if i < len(nodeClaimNames) {
+			recorded.NodeClaim = nodeClaimNames[i]
+		}
+		action.Spec.Replacements = append(action.Spec.Replacements, recorded)
+	}
+	if cmd.estimatedMonthlySavings > 0 {
+		action.Spec.EstimatedMonthlySavings = strconv.FormatFloat(cmd.estimatedMonthlySavings, 'f', 2, 64)
+	}
+	return action
+}
+
+// dryRunActionName returns the name of the DisruptionAction that records a dry run of the command. It's derived from
+// the command, so that a command that's computed again and again while it's a dry run is only recorded once.
+func dryRunActionName(m Method, cmd Command) string {
+	nodeClaimNames := lo.Map(cmd.candidates, func(cd *Candidate, _ int) string { return cd.NodeClaim.Name })
+	sort.Strings(nodeClaimNames)
+	hash := fnv.New64a()
+	fmt.Fprint(hash, m.Type(), m.ConsolidationType(), cmd.Action(), nodeClaimNames)
+	return fmt.Sprintf("dry-run-%x", hash.Sum64())
+}
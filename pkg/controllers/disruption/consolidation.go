@@ -25,10 +25,13 @@ import (
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"sigs.k8s.io/karpenter/pkg/apis/v1alpha5"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
@@ -39,11 +42,15 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
 // consolidationTTL is the TTL between creating a consolidation command and validating that it still works.
 const consolidationTTL = 15 * time.Second
 
+// hoursPerMonth is the average number of hours in a month, used to turn hourly prices into monthly estimates
+const hoursPerMonth = 730
+
 // MinInstanceTypesForSpotToSpotConsolidation is the minimum number of instanceTypes in a NodeClaim needed to trigger spot-to-spot single-node consolidation
 const MinInstanceTypesForSpotToSpotConsolidation = 15
 
@@ -59,6 +66,8 @@ type consolidation struct {
 	cloudProvider          cloudprovider.CloudProvider
 	recorder               events.Recorder
 	lastConsolidationState time.Time
+	simulations            *simulationCache
+	workloadZones          *workloadZoneCache
 }
 
 func MakeConsolidation(clock clock.Clock, cluster *state.Cluster, kubeClient client.Client, provisioner *provisioning.Provisioner,
@@ -71,6 +80,8 @@ func MakeConsolidation(clock clock.Clock, cluster *state.Cluster, kubeClient cli
 		provisioner:   provisioner,
 		cloudProvider: cloudProvider,
 		recorder:      recorder,
+		simulations:   newSimulationCache(),
+		workloadZones: &workloadZoneCache{},
 	}
 }
 
@@ -86,9 +97,9 @@ func (c *consolidation) markConsolidated() {
 
 // ShouldDisrupt is a predicate used to filter candidates
 func (c *consolidation) ShouldDisrupt(_ context.Context, cn *Candidate) bool {
-	// TODO: Remove the check for do-not-consolidate at v1
-	if cn.Annotations()[v1alpha5.DoNotConsolidateNodeAnnotationKey] == "true" {
-		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("%s annotation exists", v1alpha5.DoNotConsolidateNodeAnnotationKey))...)
+	// The do-not-consolidate annotation only blocks consolidation, so drift and expiration can still replace the node
+	if cn.Annotations()[v1beta1.DoNotConsolidateAnnotationKey] == "true" {
+		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("%s annotation exists", v1beta1.DoNotConsolidateAnnotationKey))...)
 		return false
 	}
 	// If we don't have the "WhenUnderutilized" policy set, we should not do any of the consolidation methods, but
@@ -112,10 +123,24 @@ func (c *consolidation) sortCandidates(candidates []*Candidate) []*Candidate {
 	return candidates
 }
 
-// computeConsolidation computes a consolidation action to take
+// computeConsolidation computes a consolidation action to take. Candidates that couldn't be consolidated aren't
+// simulated again until the cluster state changes.
+func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...*Candidate) (Command, pscheduling.Results, error) {
+	revision := c.cluster.Revision()
+	if c.simulations.isNoOp(revision, candidates) {
+		return Command{}, pscheduling.Results{}, nil
+	}
+	cmd, results, err := c.simulateConsolidation(ctx, candidates...)
+	if err == nil && cmd.Action() == NoOpAction {
+		c.simulations.markNoOp(revision, candidates)
+	}
+	return cmd, results, err
+}
+
+// simulateConsolidation runs the scheduling simulation for the candidates and computes a consolidation action from it
 //
 // nolint:gocyclo
-func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...*Candidate) (Command, pscheduling.Results, error) {
+func (c *consolidation) simulateConsolidation(ctx context.Context, candidates ...*Candidate) (Command, pscheduling.Results, error) {
 	var err error
 	// Run scheduling simulation to compute consolidation option
 	results, err := SimulateScheduling(ctx, c.kubeClient, c.cluster, c.provisioner, candidates...)
@@ -140,6 +165,7 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	if len(results.NewNodeClaims) == 0 {
 		return Command{
 			candidates: candidates,
+			reason:     v1beta1.DisruptionReasonUnderutilized,
 		}, results, nil
 	}
 
@@ -151,6 +177,10 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 		return Command{}, pscheduling.Results{}, nil
 	}
 
+	if err = c.preserveZonalSpread(ctx, candidates, results.NewNodeClaims[0]); err != nil {
+		return Command{}, pscheduling.Results{}, fmt.Errorf("preserving zonal spread, %w", err)
+	}
+
 	// get the current node price based on the offering
 	// fallback if we can't find the specific zonal pricing data
 	candidatePrice, err := getCandidatePrices(candidates)
@@ -204,15 +234,88 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 
 	return Command{
 		candidates:   candidates,
+		reason:       v1beta1.DisruptionReasonUnderutilized,
 		replacements: results.NewNodeClaims,
 	}, results, nil
 }
 
+// preserveZonalSpread constrains the replacement to the candidates' zones when any of the pods that it moves belongs to
+// a workload with pods in more than one zone. Otherwise, the cheapest zone wins every replacement, and the workload's
+// pods accumulate in it over successive consolidations. The zone of the replacement is left as is when the scheduling
+// simulation already constrained it to other zones, e.g. for the zonal topology spread constraints of the pods.
+func (c *consolidation) preserveZonalSpread(ctx context.Context, candidates []*Candidate, nodeClaim *pscheduling.NodeClaim) error {
+	zones := sets.New(lo.FilterMap(candidates, func(cn *Candidate, _ int) (string, bool) { return cn.zone, cn.zone != "" })...)
+	if len(zones) == 0 {
+		return nil
+	}
+	requirement := scheduling.NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, sets.List(zones)...)
+	if nodeClaim.Requirements.Get(v1.LabelTopologyZone).Intersection(requirement).Len() == 0 {
+		return nil
+	}
+	spread, err := c.spreadAcrossZones(ctx, lo.FlatMap(candidates, func(cn *Candidate, _ int) []*v1.Pod { return cn.reschedulablePods }))
+	if err != nil {
+		return err
+	}
+	if spread {
+		nodeClaim.Requirements.Add(requirement)
+	}
+	return nil
+}
+
+// spreadAcrossZones returns whether any of the pods is controlled by a workload that has active pods scheduled in more
+// than one zone
+func (c *consolidation) spreadAcrossZones(ctx context.Context, pods []*v1.Pod) (bool, error) {
+	if !lo.SomeBy(pods, func(p *v1.Pod) bool { return metav1.GetControllerOf(p) != nil }) {
+		return false, nil
+	}
+	zones, err := c.workloadZones.get(c.cluster.Revision(), func() (map[types.UID]sets.Set[string], error) {
+		return c.listWorkloadZones(ctx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return lo.SomeBy(pods, func(p *v1.Pod) bool {
+		owner := metav1.GetControllerOf(p)
+		return owner != nil && zones[owner.UID].Len() > 1
+	}), nil
+}
+
+// listWorkloadZones returns the zones that the active pods of each workload are scheduled in, keyed by the uid of the
+// workload that controls them
+func (c *consolidation) listWorkloadZones(ctx context.Context) (map[types.UID]sets.Set[string], error) {
+	nodeZones := map[string]string{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		if zone, ok := n.Labels()[v1.LabelTopologyZone]; ok {
+			nodeZones[n.Name()] = zone
+		}
+		return true
+	})
+	podList := &v1.PodList{}
+	if err := c.kubeClient.List(ctx, podList); err != nil {
+		return nil, fmt.Errorf("listing pods, %w", err)
+	}
+	workloadZones := map[types.UID]sets.Set[string]{}
+	for i := range podList.Items {
+		p := &podList.Items[i]
+		owner := metav1.GetControllerOf(p)
+		zone, ok := nodeZones[p.Spec.NodeName]
+		if owner == nil || !ok || !podutil.IsActive(p) {
+			continue
+		}
+		if workloadZones[owner.UID] == nil {
+			workloadZones[owner.UID] = sets.New[string]()
+		}
+		workloadZones[owner.UID].Insert(zone)
+	}
+	return workloadZones, nil
+}
+
 // Compute command to execute spot-to-spot consolidation if:
 //  1. The SpotToSpotConsolidation feature flag is set to true.
 //  2. For single-node consolidation:
 //     a. There are at least 15 cheapest instance type replacement options to consolidate.
 //     b. The current candidate is NOT part of the first 15 cheapest instance types inorder to avoid repeated consolidation.
+//     Or, if the candidate's NodePool sets a minimum price improvement, the replacement is at least that much cheaper.
 func (c *consolidation) computeSpotToSpotConsolidation(ctx context.Context, candidates []*Candidate, results pscheduling.Results,
 	candidatePrice float64) (Command, pscheduling.Results, error) {
 
@@ -253,12 +356,33 @@ func (c *consolidation) computeSpotToSpotConsolidation(ctx context.Context, cand
 	if len(candidates) > 1 {
 		return Command{
 			candidates:   candidates,
+			reason:       v1beta1.DisruptionReasonUnderutilized,
 			replacements: results.NewNodeClaims,
 		}, results, nil
 	}
 
 	// For single-node consolidation:
 
+	// NodePools with a minimum price improvement are replaced by any spot replacement that's cheap enough. Each
+	// replacement has to be cheaper than the last by the same percentage, so consolidation can't churn indefinitely.
+	if improvement := candidates[0].nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent; improvement != nil {
+		results.NewNodeClaims[0].NodeClaimTemplate.InstanceTypeOptions, incompatibleMinReqKey, _ =
+			filterByPriceWithMinValues(results.NewNodeClaims[0].InstanceTypeOptions, results.NewNodeClaims[0].Requirements, candidatePrice*(1-float64(*improvement)/100))
+		if len(results.NewNodeClaims[0].NodeClaimTemplate.InstanceTypeOptions) == 0 {
+			if len(incompatibleMinReqKey) > 0 {
+				c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("minValues requirement is not met for %s", incompatibleMinReqKey))...)
+			} else {
+				c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("Can't replace spot node with a spot node that's at least %d%% cheaper", *improvement))...)
+			}
+			return Command{}, pscheduling.Results{}, nil
+		}
+		return Command{
+			candidates:   candidates,
+			reason:       v1beta1.DisruptionReasonUnderutilized,
+			replacements: results.NewNodeClaims,
+		}, results, nil
+	}
+
 	// We check whether we have 15 cheaper instances than the current candidate instance. If this is the case, we know the following things:
 	//   1) The current candidate is not in the set of the 15 cheapest instance types and
 	//   2) There were at least 15 options cheaper than the current candidate.
@@ -286,10 +410,59 @@ func (c *consolidation) computeSpotToSpotConsolidation(ctx context.Context, cand
 
 	return Command{
 		candidates:   candidates,
+		reason:       v1beta1.DisruptionReasonUnderutilized,
 		replacements: results.NewNodeClaims,
 	}, results, nil
 }
 
+// withEstimatedSavings returns the command with its estimated monthly savings populated. The estimate is only
+// informational, so failing to price the command doesn't prevent it from being executed.
+func (c *consolidation) withEstimatedSavings(ctx context.Context, cmd Command) Command {
+	savings, err := c.estimateMonthlySavings(ctx, cmd)
+	if err != nil {
+		logging.FromContext(ctx).Debugf("estimating consolidation savings, %s", err)
+		return cmd
+	}
+	cmd.estimatedMonthlySavings = savings
+	return cmd
+}
+
+// estimateMonthlySavings uses the CloudProvider's pricing to estimate how much cheaper the cluster is per month after
+// executing the command. Replacements are priced at their cheapest compatible offering since that's what we expect
+// them to launch with.
+func (c *consolidation) estimateMonthlySavings(ctx context.Context, cmd Command) (float64, error) {
+	var hourly float64
+	for _, cn := range cmd.candidates {
+		price, err := c.cloudProvider.Price(ctx, cn.instanceType.Name, cn.capacityType, cn.zone)
+		if err != nil {
+			return 0, fmt.Errorf("getting price of candidate %q, %w", cn.Name(), err)
+		}
+		hourly += price
+	}
+	for _, replacement := range cmd.replacements {
+		var instanceType *cloudprovider.InstanceType
+		var offering cloudprovider.Offering
+		for _, it := range replacement.InstanceTypeOptions {
+			offerings := it.Offerings.Available().Compatible(replacement.Requirements)
+			if len(offerings) == 0 {
+				continue
+			}
+			if cheapest := offerings.Cheapest(); instanceType == nil || cheapest.Price < offering.Price {
+				instanceType, offering = it, cheapest
+			}
+		}
+		if instanceType == nil {
+			return 0, fmt.Errorf("replacement has no compatible offerings")
+		}
+		price, err := c.cloudProvider.Price(ctx, instanceType.Name, offering.CapacityType, offering.Zone)
+		if err != nil {
+			return 0, fmt.Errorf("getting price of replacement, %w", err)
+		}
+		hourly -= price
+	}
+	return hourly * hoursPerMonth, nil
+}
+
 // getCandidatePrices returns the sum of the prices of the given candidates
 func getCandidatePrices(candidates []*Candidate) (float64, error) {
 	var price float64
@@ -537,7 +537,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			emptyConsolidation := disruption.NewEmptyNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, emptyConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, emptyConsolidation.ShouldDisrupt, queue)
@@ -601,7 +601,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			emptyConsolidation := disruption.NewEmptyNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, emptyConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, emptyConsolidation.ShouldDisrupt, queue)
@@ -628,7 +628,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			multiConsolidation := disruption.NewMultiNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, multiConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, multiConsolidation.ShouldDisrupt, queue)
@@ -692,7 +692,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			multiConsolidation := disruption.NewMultiNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, multiConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, multiConsolidation.ShouldDisrupt, queue)
@@ -719,7 +719,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			singleConsolidation := disruption.NewSingleNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, singleConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, singleConsolidation.ShouldDisrupt, queue)
@@ -783,7 +783,7 @@ var _ = Describe("Consolidation", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 
 			singleConsolidation := disruption.NewSingleNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
-			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+			budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, singleConsolidation.Reason())
 			Expect(err).To(Succeed())
 
 			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, singleConsolidation.ShouldDisrupt, queue)
@@ -1249,6 +1249,86 @@ var _ = Describe("Consolidation", func() {
 			})
 			Expect(ok).To(BeTrue())
 		})
+		DescribeTable("spot with spot with a minimum price improvement",
+			func(priceRatio float64, replaced bool) {
+				// Fewer than 15 instance types are cheaper than the candidate, which the price improvement makes up for
+				cloudProvider.InstanceTypes = lo.Slice(fake.InstanceTypesAssorted(), 0, 5)
+				cloudProvider.InstanceTypes[0].Offerings[0].CapacityType = v1beta1.CapacityTypeSpot
+				spotInstances = lo.Filter(cloudProvider.InstanceTypes, func(i *cloudprovider.InstanceType, _ int) bool {
+					return lo.ContainsBy(i.Offerings, func(o cloudprovider.Offering) bool { return o.CapacityType == v1beta1.CapacityTypeSpot })
+				})
+				mostExpSpotInstance := spotInstances[len(spotInstances)-1]
+				mostExpSpotOffering := mostExpSpotInstance.Offerings[0]
+				cloudProvider.InstanceTypes[0].Offerings[0].Price = mostExpSpotOffering.Price * priceRatio
+				nodePool.Spec.Disruption.SpotToSpotPriceImprovementPercent = lo.ToPtr[int32](15)
+				spotNodeClaim.Labels = lo.Assign(spotNodeClaim.Labels, map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpSpotInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpSpotOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpSpotOffering.Zone,
+				})
+				spotNode.Labels = lo.Assign(spotNode.Labels, map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpSpotInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpSpotOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpSpotOffering.Zone,
+				})
+
+				rs := test.ReplicaSet()
+				ExpectApplied(ctx, env.Client, rs)
+				Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+				pod := test.Pod(test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels,
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "apps/v1",
+								Kind:               "ReplicaSet",
+								Name:               rs.Name,
+								UID:                rs.UID,
+								Controller:         ptr.Bool(true),
+								BlockOwnerDeletion: ptr.Bool(true),
+							},
+						}}})
+				ExpectApplied(ctx, env.Client, rs, pod, spotNode, spotNodeClaim, nodePool)
+
+				// bind pods to node
+				ExpectManualBinding(ctx, env.Client, pod, spotNode)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{spotNode}, []*v1beta1.NodeClaim{spotNodeClaim})
+
+				fakeClock.Step(10 * time.Minute)
+
+				// consolidation won't delete the old nodeclaim until the new nodeclaim is ready
+				var wg sync.WaitGroup
+				ExpectTriggerVerifyAction(&wg)
+				if replaced {
+					ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+				}
+				ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+				wg.Wait()
+				ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+				if !replaced {
+					Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+					ExpectExists(ctx, env.Client, spotNodeClaim)
+					_, ok := lo.Find(recorder.Events(), func(e events.Event) bool {
+						return strings.Contains(e.Message, "Can't replace spot node with a spot node that's at least 15% cheaper")
+					})
+					Expect(ok).To(BeTrue())
+					return
+				}
+				ExpectNodeClaimsCascadeDeletion(ctx, env.Client, spotNodeClaim)
+				nodeClaims := ExpectNodeClaims(ctx, env.Client)
+				Expect(nodeClaims).To(HaveLen(1))
+				Expect(nodeClaims[0].Name).ToNot(Equal(spotNodeClaim.Name))
+				Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Values()).To(ConsistOf(cloudProvider.InstanceTypes[0].Name))
+				ExpectNotFound(ctx, env.Client, spotNodeClaim, spotNode)
+			},
+			Entry("can replace when the replacement is cheap enough", 0.5, true),
+			Entry("cannot replace when the replacement isn't cheap enough", 0.9, false),
+		)
 		It("cannot replace spot with spot if the spotToSpotConsolidation is disabled", func() {
 			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{SpotToSpotConsolidation: lo.ToPtr(false)}}))
 			// create our RS so we can link a pod to it
@@ -2144,7 +2224,7 @@ var _ = Describe("Consolidation", func() {
 				annotatedNodeClaim, annotatedNode := test.NodeClaimAndNode(v1beta1.NodeClaim{
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
-							v1alpha5.DoNotConsolidateNodeAnnotationKey: "true",
+							v1beta1.DoNotConsolidateAnnotationKey: "true",
 						},
 						Labels: map[string]string{
 							v1beta1.NodePoolLabelKey:     nodePool.Name,
@@ -2750,6 +2830,46 @@ var _ = Describe("Consolidation", func() {
 			// and delete the old one
 			ExpectNotFound(ctx, env.Client, nodeClaims[1], nodes[1])
 		})
+		It("should emit the estimated savings of deleting nodes", func() {
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[2], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{nodes[0], nodes[1]}, []*v1beta1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Deleting a node without a replacement saves the full price of the node
+			savings := leastExpensiveOffering.Price * 730
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Consolidating NodeClaim is estimated to save $%.2f/month", savings))).To(BeTrue())
+			metric, found := FindMetricWithLabelValues("karpenter_disruption_consolidation_estimated_monthly_savings_total", map[string]string{
+				"method":             "consolidation",
+				"consolidation_type": "single",
+			})
+			Expect(found).To(BeTrue())
+			Expect(metric.GetCounter().GetValue()).To(BeNumerically(">=", savings))
+		})
 		It("can delete nodes if another nodePool has no node template", func() {
 			// create our RS so we can link a pod to it
 			rs := test.ReplicaSet()
@@ -2932,8 +3052,8 @@ var _ = Describe("Consolidation", func() {
 							BlockOwnerDeletion: ptr.Bool(true),
 						},
 					}}})
-			nodeClaims[1].Annotations = lo.Assign(nodeClaims[1].Annotations, map[string]string{v1alpha5.DoNotConsolidateNodeAnnotationKey: "true"})
-			nodes[1].Annotations = lo.Assign(nodeClaims[1].Annotations, map[string]string{v1alpha5.DoNotConsolidateNodeAnnotationKey: "true"})
+			nodeClaims[1].Annotations = lo.Assign(nodeClaims[1].Annotations, map[string]string{v1beta1.DoNotConsolidateAnnotationKey: "true"})
+			nodes[1].Annotations = lo.Assign(nodeClaims[1].Annotations, map[string]string{v1beta1.DoNotConsolidateAnnotationKey: "true"})
 
 			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodePool)
 			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1])
@@ -3109,6 +3229,59 @@ var _ = Describe("Consolidation", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0])
 		})
+		It("can delete nodes, considers karpenter.sh/do-not-disrupt on namespaces", func() {
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+			pods := test.Pods(2, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+			// Block this pod from being disrupted with karpenter.sh/do-not-disrupt on its namespace
+			namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        test.RandomName(),
+				Annotations: map[string]string{v1beta1.DoNotDisruptAnnotationKey: "true"},
+			}}
+			protected := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Labels: labels}})
+
+			ExpectApplied(ctx, env.Client, rs, namespace, pods[0], pods[1], protected, nodePool)
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1])
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+			ExpectManualBinding(ctx, env.Client, protected, nodes[1])
+
+			// inform cluster state about nodes and nodeClaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{nodes[0], nodes[1]}, []*v1beta1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaims[0])
+
+			// we should delete the node that doesn't have pods in the annotated namespace
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0])
+		})
 		It("can delete nodes, evicts pods without an ownerRef", func() {
 			// create our RS so we can link a pod to it
 			rs := test.ReplicaSet()
@@ -4164,7 +4337,7 @@ var _ = Describe("Consolidation", func() {
 			}()
 
 			// advance the clock so that the timeout expires
-			fakeClock.Step(disruption.MultiNodeConsolidationTimeoutDuration)
+			fakeClock.Step(options.FromContext(ctx).MultiNodeConsolidationTimeout)
 
 			// wait for the controller to block on the validation timeout
 			Eventually(fakeClock.HasWaiters, time.Second*10).Should(BeTrue())
@@ -4255,7 +4428,7 @@ var _ = Describe("Consolidation", func() {
 			}()
 
 			// advance the clock so that the timeout expires for multi-nodeClaim
-			fakeClock.Step(disruption.MultiNodeConsolidationTimeoutDuration)
+			fakeClock.Step(options.FromContext(ctx).MultiNodeConsolidationTimeout)
 			// advance the clock so that the timeout expires for single-nodeClaim
 			fakeClock.Step(disruption.SingleNodeConsolidationTimeoutDuration)
 
@@ -4312,7 +4485,11 @@ var _ = Describe("Consolidation", func() {
 			})
 		})
 		DescribeTable("can merge 3 nodes into 1",
-			func(spotToSpot bool) {
+			func(spotToSpot bool, parallelism int) {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+					FeatureGates:                      test.FeatureGates{SpotToSpotConsolidation: lo.ToPtr(true)},
+					MultiNodeConsolidationParallelism: lo.ToPtr(parallelism),
+				}))
 				nodeClaims = lo.Ternary(spotToSpot, spotNodeClaims, nodeClaims)
 				nodes = lo.Ternary(spotToSpot, spotNodes, nodes)
 				// create our RS so we can link a pod to it
@@ -4361,8 +4538,10 @@ var _ = Describe("Consolidation", func() {
 				Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 				ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodeClaims[2], nodes[2])
 			},
-			Entry("if the candidate is on-demand node", false),
-			Entry("if the candidate is spot node", true),
+			Entry("if the candidate is on-demand node", false, 4),
+			Entry("if the candidate is spot node", true, 4),
+			Entry("if the candidate is on-demand node and batches are evaluated serially", false, 1),
+			Entry("if the candidate is spot node and batches are evaluated serially", true, 1),
 		)
 		It("can merge 3 nodes into 1 if the candidates have both spot and on-demand", func() {
 			// By default all the 3 nodeClaims are OD.
@@ -4826,6 +5005,55 @@ var _ = Describe("Consolidation", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0])
 		})
+		It("should consider the disruption cost annotation of pods when calculating disruption cost", func() {
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+
+			ownerReferences := []metav1.OwnerReference{
+				{
+					APIVersion:         "apps/v1",
+					Kind:               "ReplicaSet",
+					Name:               rs.Name,
+					UID:                rs.UID,
+					Controller:         ptr.Bool(true),
+					BlockOwnerDeletion: ptr.Bool(true),
+				},
+			}
+			pods := test.Pods(2, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels, OwnerReferences: ownerReferences}})
+			expensivePod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels, OwnerReferences: ownerReferences,
+					Annotations: map[string]string{v1beta1.DisruptionCostAnnotationKey: "10"}}})
+
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], expensivePod, nodePool)
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1])
+
+			// two cheap pods on node 1, one expensive pod on node 2
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+			ExpectManualBinding(ctx, env.Client, expensivePod, nodes[1])
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{nodes[0], nodes[1]}, []*v1beta1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaims[0])
+
+			// the second node has fewer pods, so it would normally be picked for consolidation, except its pod is
+			// expensive to disrupt
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0])
+		})
 	})
 	Context("Topology Consideration", func() {
 		var nodeClaims []*v1beta1.NodeClaim
@@ -4951,6 +5179,63 @@ var _ = Describe("Consolidation", func() {
 			// we should maintain our skew, the new node must be in the same zone as the old node it replaced
 			ExpectSkew(ctx, env.Client, "default", &tsc).To(ConsistOf(1, 1, 1))
 		})
+		It("can replace node in the same zone when its workload is spread across zones", func() {
+			labels = map[string]string{
+				"app": "test-zonal-spread",
+			}
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+
+			// The pods don't have topology spread constraints, so the scheduling simulation doesn't constrain the zone
+			// of the replacement
+			pods := test.Pods(3, test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{Requests: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("1")}},
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodeClaims[2], nodes[2], nodePool)
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, pods[2], nodes[2])
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{nodes[0], nodes[1], nodes[2]}, []*v1beta1.NodeClaim{nodeClaims[0], nodeClaims[1], nodeClaims[2]})
+
+			fakeClock.Step(10 * time.Minute)
+
+			// consolidation won't delete the old node until the new node is ready
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaims[1])
+			ExpectNotFound(ctx, env.Client, nodeClaims[1], nodes[1])
+
+			// The replacement is constrained to the zone of the node it replaced
+			newNodeClaim, ok := lo.Find(ExpectNodeClaims(ctx, env.Client), func(m *v1beta1.NodeClaim) bool {
+				return !oldNodeClaimNames.Has(m.Name)
+			})
+			Expect(ok).To(BeTrue())
+			Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(newNodeClaim.Spec.Requirements...).Get(v1.LabelTopologyZone).Values()).To(ConsistOf("test-zone-2"))
+		})
 		It("won't delete node if it would violate pod anti-affinity", func() {
 			// create our RS so we can link a pod to it
 			rs := test.ReplicaSet()
@@ -24,7 +24,10 @@ import (
 	"time"
 
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
@@ -34,8 +37,10 @@ import (
 
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
@@ -43,6 +48,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 )
 
 type Controller struct {
@@ -56,6 +62,11 @@ type Controller struct {
 	methods       []Method
 	mu            sync.Mutex
 	lastRun       map[string]time.Time
+	// preferNoScheduleTaintedAt is when each node was tainted with the PreferNoSchedule disruption taint, by provider ID
+	preferNoScheduleTaintedAt map[string]time.Time
+	// dryRunDisrupted is the dry-run command that each node of a dry-run nodepool was last counted as disrupted by, by
+	// provider ID
+	dryRunDisrupted map[string]string
 }
 
 // pollingPeriod that we inspect cluster to look for opportunities to disrupt
@@ -76,19 +87,26 @@ func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provi
 		recorder:      recorder,
 		cloudProvider: cp,
 		lastRun:       map[string]time.Time{},
+
+		preferNoScheduleTaintedAt: map[string]time.Time{},
+		dryRunDisrupted:           map[string]string{},
 		methods: []Method{
+			// Replace any NodeClaims that an operator asked to disrupt through the karpenter.sh/disrupt annotation
+			NewRequested(kubeClient, cluster, provisioner, recorder),
 			// Expire any NodeClaims that must be deleted, allowing their pods to potentially land on currently
 			NewExpiration(clk, kubeClient, cluster, provisioner, recorder),
 			// Terminate any NodeClaims that have drifted from provisioning specifications, allowing the pods to reschedule.
 			NewDrift(kubeClient, cluster, provisioner, recorder),
 			// Delete any remaining empty NodeClaims as there is zero cost in terms of disruption.  Emptiness and
 			// emptyNodeConsolidation are mutually exclusive, only one of these will operate
-			NewEmptiness(clk, recorder),
+			NewEmptiness(clk, kubeClient, cluster, provisioner, recorder),
 			NewEmptyNodeConsolidation(c),
 			// Attempt to identify multiple NodeClaims that we can consolidate simultaneously to reduce pod churn
 			NewMultiNodeConsolidation(c),
 			// And finally fall back our single NodeClaim consolidation to further reduce cluster cost.
 			NewSingleNodeConsolidation(c),
+			// Replace nodes that have been persistently under or over-utilized with right-sized ones
+			NewResize(c),
 		},
 	}
 }
@@ -118,6 +136,10 @@ func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconc
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 
+	// Resume the commands of the previous leader before we untaint the nodes that aren't being disrupted
+	if err := c.queue.Restore(ctx); err != nil {
+		return reconcile.Result{}, fmt.Errorf("restoring disruption commands, %w", err)
+	}
 	// Karpenter taints nodes with a karpenter.sh/disruption taint as part of the disruption process
 	// while it progresses in memory. If Karpenter restarts during a disruption action, some nodes can be left tainted.
 	// Idempotently remove this taint from candidates that are not in the orchestration queue before continuing.
@@ -126,12 +148,20 @@ func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconc
 	})...); err != nil {
 		return reconcile.Result{}, fmt.Errorf("removing taint from nodes, %w", err)
 	}
+	if err := c.cleanupPreferNoScheduleTaints(ctx); err != nil {
+		return reconcile.Result{}, fmt.Errorf("removing prefer no schedule taint from nodes, %w", err)
+	}
 
 	// Attempt different disruption methods. We'll only let one method perform an action
 	for _, m := range c.methods {
 		c.recordRun(fmt.Sprintf("%T", m))
 		success, err := c.disrupt(ctx, m)
 		if err != nil {
+			// The cloudprovider asked us to hold back, e.g. while pricing or launching replacements is throttled
+			if retryAfter, ok := cloudprovider.RetryAfter(err); ok {
+				logging.FromContext(ctx).With("retry-after", retryAfter).Debugf("retrying disruption via %q, %s", m.Type(), err)
+				return reconcile.Result{RequeueAfter: retryAfter}, nil
+			}
 			return reconcile.Result{}, fmt.Errorf("disrupting via %q, %w", m.Type(), err)
 		}
 		if success {
@@ -143,12 +173,18 @@ func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconc
 	return reconcile.Result{RequeueAfter: pollingPeriod}, nil
 }
 
-func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, error) {
+func (c *Controller) disrupt(ctx context.Context, disruption Method) (_ bool, err error) {
 	defer metrics.Measure(EvaluationDurationHistogram.With(map[string]string{
 		methodLabel:            disruption.Type(),
 		consolidationTypeLabel: disruption.ConsolidationType(),
 	}))()
-	candidates, err := GetCandidates(ctx, c.cluster, c.kubeClient, c.recorder, c.clock, c.cloudProvider, disruption.ShouldDisrupt, c.queue)
+	ctx, span := tracing.Start(ctx, "disruption.disrupt",
+		attribute.String(methodLabel, disruption.Type()),
+		attribute.String(consolidationTypeLabel, disruption.ConsolidationType()),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	candidates, err := c.getCandidates(ctx, disruption)
 	if err != nil {
 		return false, fmt.Errorf("determining candidates, %w", err)
 	}
@@ -156,19 +192,43 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 	if len(candidates) == 0 {
 		return false, nil
 	}
-	disruptionBudgetMapping, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.recorder)
+	disruptionBudgetMapping, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.recorder, c.queue, disruption.Reason())
 	if err != nil {
 		return false, fmt.Errorf("building disruption budgets, %w", err)
 	}
+	for _, candidate := range candidates {
+		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+			c.recorder.Publish(disruptionevents.BlockedByBudget(candidate.Node, candidate.NodeClaim, disruption.Reason())...)
+		}
+	}
 
 	// Determine the disruption action
-	cmd, schedulingResults, err := disruption.ComputeCommand(ctx, disruptionBudgetMapping, candidates...)
+	cmd, schedulingResults, err := c.computeCommand(ctx, disruption, disruptionBudgetMapping, candidates)
 	if err != nil {
 		return false, fmt.Errorf("computing disruption decision, %w", err)
 	}
 	if cmd.Action() == NoOpAction {
 		return false, nil
 	}
+	// Commands that disrupt the nodes of dry-run nodepools are only recorded. The other candidates are simulated again,
+	// so that dry-run nodepools don't hold back the disruption of the rest of the cluster.
+	if lo.ContainsBy(cmd.candidates, isDryRun) {
+		c.recordDryRun(ctx, disruption, cmd)
+		if candidates = lo.Reject(candidates, func(c *Candidate, _ int) bool { return isDryRun(c) }); len(candidates) == 0 {
+			return false, nil
+		}
+		if cmd, schedulingResults, err = c.computeCommand(ctx, disruption, disruptionBudgetMapping, candidates); err != nil {
+			return false, fmt.Errorf("computing disruption decision, %w", err)
+		}
+		if cmd.Action() == NoOpAction {
+			return false, nil
+		}
+	}
+	// The candidates are only disrupted once they've had the PreferNoSchedule taint for the configured window. Until
+	// then, the other methods can still disrupt other candidates.
+	if ready, err := c.awaitPreferNoSchedule(ctx, cmd); err != nil || !ready {
+		return false, err
+	}
 
 	// Attempt to disrupt
 	if err := c.executeCommand(ctx, disruption, cmd, schedulingResults); err != nil {
@@ -178,12 +238,41 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 	return true, nil
 }
 
+func (c *Controller) getCandidates(ctx context.Context, disruption Method) (candidates []*Candidate, err error) {
+	ctx, span := tracing.Start(ctx, "disruption.candidates")
+	defer func() {
+		span.SetAttributes(attribute.Int("candidates", len(candidates)))
+		tracing.End(span, err)
+	}()
+	return GetCandidates(ctx, c.cluster, c.kubeClient, c.recorder, c.clock, c.cloudProvider, disruption.ShouldDisrupt, c.queue)
+}
+
+// computeCommand simulates the disruption of the candidates to find the command to execute
+func (c *Controller) computeCommand(ctx context.Context, disruption Method, budgets map[string]int,
+	candidates []*Candidate) (cmd Command, results scheduling.Results, err error) {
+	ctx, span := tracing.Start(ctx, "disruption.simulate")
+	defer func() {
+		span.SetAttributes(
+			attribute.String(actionLabel, string(cmd.Action())),
+			attribute.StringSlice("nodes", lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.Name() })),
+		)
+		tracing.End(span, err)
+	}()
+	return disruption.ComputeCommand(ctx, budgets, candidates...)
+}
+
 // executeCommand will do the following, untainting if the step fails.
 // 1. Taint candidate nodes
 // 2. Spin up replacement nodes
 // 3. Add Command to orchestration.Queue to wait to delete the candiates.
-func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command, schedulingResults scheduling.Results) error {
+func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command, schedulingResults scheduling.Results) (err error) {
 	commandID := uuid.NewUUID()
+	ctx, span := tracing.Start(ctx, "disruption.execute",
+		attribute.String("command-id", string(commandID)),
+		attribute.StringSlice("nodes", lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.Name() })),
+		attribute.Int("replacements", len(cmd.replacements)),
+	)
+	defer func() { tracing.End(span, err) }()
 	logging.FromContext(ctx).With("command-id", commandID).Infof("disrupting via %s %s", m.Type(), cmd)
 
 	stateNodes := lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode {
@@ -195,7 +284,6 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 	}
 
 	var nodeClaimNames []string
-	var err error
 	if len(cmd.replacements) > 0 {
 		if nodeClaimNames, err = c.createReplacementNodeClaims(ctx, m, cmd); err != nil {
 			// If we failed to launch the replacement, don't disrupt.  If this is some permanent failure,
@@ -219,10 +307,16 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 	// We have the new NodeClaims created at the API server so mark the old NodeClaims for deletion
 	c.cluster.MarkForDeletion(providerIDs...)
 
+	// The command is recorded before it's added to the queue, so that the queue can record its result however quickly
+	// it completes
+	c.recordAction(ctx, string(commandID), m, cmd, nodeClaimNames, v1alpha1.DisruptionResultPending)
 	if err := c.queue.Add(orchestration.NewCommand(nodeClaimNames,
-		lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode }), commandID, m.Type(), m.ConsolidationType())); err != nil {
+		lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode }), commandID, cmd.reason, m.Type(), m.ConsolidationType()).
+		WithSpanContext(trace.SpanContextFromContext(ctx))); err != nil {
 		c.cluster.UnmarkForDeletion(providerIDs...)
-		return fmt.Errorf("adding command to queue (command-id: %s), %w", commandID, multierr.Append(err, state.RequireNoScheduleTaint(ctx, c.kubeClient, false, stateNodes...)))
+		err = fmt.Errorf("adding command to queue (command-id: %s), %w", commandID, multierr.Append(err, state.RequireNoScheduleTaint(ctx, c.kubeClient, false, stateNodes...)))
+		c.queue.CompleteAction(ctx, commandID, err)
+		return err
 	}
 
 	// An action is only performed and pods/nodes are only disrupted after a successful add to the queue
@@ -232,11 +326,13 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 		consolidationTypeLabel: m.ConsolidationType(),
 	}).Inc()
 	for _, cd := range cmd.candidates {
+		c.recorder.Publish(disruptionevents.Disrupted(cd.Node, cd.NodeClaim, m.Reason(), len(cmd.replacements))...)
 		NodesDisruptedCounter.With(map[string]string{
 			metrics.NodePoolLabel:  cd.nodePool.Name,
 			actionLabel:            string(cmd.Action()),
 			methodLabel:            m.Type(),
 			consolidationTypeLabel: m.ConsolidationType(),
+			dryRunLabel:            "false",
 		}).Inc()
 		PodsDisruptedCounter.With(map[string]string{
 			metrics.NodePoolLabel:  cd.nodePool.Name,
@@ -245,13 +341,62 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 			consolidationTypeLabel: m.ConsolidationType(),
 		}).Add(float64(len(cd.reschedulablePods)))
 	}
+	if cmd.estimatedMonthlySavings > 0 {
+		ConsolidationEstimatedSavingsCounter.With(map[string]string{
+			methodLabel:            m.Type(),
+			consolidationTypeLabel: m.ConsolidationType(),
+		}).Add(cmd.estimatedMonthlySavings)
+		for _, cd := range cmd.candidates {
+			c.recorder.Publish(disruptionevents.EstimatedSavings(cd.Node, cd.NodeClaim, cmd.estimatedMonthlySavings)...)
+		}
+	}
 	return nil
 }
 
+// isDryRun returns true if the candidate's nodepool has the disruption dry-run annotation
+func isDryRun(c *Candidate) bool {
+	return c.nodePool.Annotations[v1beta1.DisruptionDryRunAnnotationKey] == "true"
+}
+
+// recordDryRun emits the events and metrics of the command for the candidates of dry-run nodepools, as if it had been
+// executed. The same command is computed again on every pass while it's a dry run, so each candidate is only counted
+// once for it.
+func (c *Controller) recordDryRun(ctx context.Context, m Method, cmd Command) {
+	logging.FromContext(ctx).Debugf("dry run, would disrupt via %s %s", m.Type(), cmd)
+	name := dryRunActionName(m, cmd)
+	c.recordAction(ctx, name, m, cmd, nil, v1alpha1.DisruptionResultDryRun)
+	c.forgetRemovedDryRuns()
+	for _, cd := range lo.Filter(cmd.candidates, func(cd *Candidate, _ int) bool { return isDryRun(cd) }) {
+		if c.dryRunDisrupted[cd.ProviderID()] == name {
+			continue
+		}
+		c.dryRunDisrupted[cd.ProviderID()] = name
+		c.recorder.Publish(disruptionevents.DryRunDisrupted(cd.Node, cd.NodeClaim, m.Reason(), len(cmd.replacements))...)
+		NodesDisruptedCounter.With(map[string]string{
+			metrics.NodePoolLabel:  cd.nodePool.Name,
+			actionLabel:            string(cmd.Action()),
+			methodLabel:            m.Type(),
+			consolidationTypeLabel: m.ConsolidationType(),
+			dryRunLabel:            "true",
+		}).Inc()
+	}
+}
+
+// forgetRemovedDryRuns stops tracking the dry runs of nodes that are no longer in the cluster
+func (c *Controller) forgetRemovedDryRuns() {
+	providerIDs := sets.New(lo.Map(c.cluster.Nodes(), func(n *state.StateNode, _ int) string { return n.ProviderID() })...)
+	for providerID := range c.dryRunDisrupted {
+		if !providerIDs.Has(providerID) {
+			delete(c.dryRunDisrupted, providerID)
+		}
+	}
+}
+
 // createReplacementNodeClaims creates replacement NodeClaims
 func (c *Controller) createReplacementNodeClaims(ctx context.Context, m Method, cmd Command) ([]string, error) {
 	reason := fmt.Sprintf("%s/%s", m.Type(), cmd.Action())
-	nodeClaimNames, err := c.provisioner.CreateNodeClaims(ctx, cmd.replacements, provisioning.WithReason(reason))
+	nodeClaimNames, err := c.provisioner.CreateNodeClaims(ctx, cmd.replacements, provisioning.WithReason(reason),
+		provisioning.Replaces(lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.ProviderID() })...))
 	if err != nil {
 		return nil, err
 	}
@@ -62,9 +62,17 @@ func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[
 		return candidates[i].NodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).LastTransitionTime.Inner.Time.Before(
 			candidates[j].NodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).LastTransitionTime.Inner.Time)
 	})
+	return computeReplacementCommand(ctx, d, d.kubeClient, d.cluster, d.provisioner, d.recorder, disruptionBudgetMapping, candidates)
+}
+
+// computeReplacementCommand disrupts the empty candidates that the budgets allow, or otherwise the first candidate
+// whose pods can be rescheduled, along with the NodeClaims that have to be launched for them. It's shared by the
+// methods that disrupt candidates regardless of cost, which pass them in the order that they should be disrupted.
+func computeReplacementCommand(ctx context.Context, m Method, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	recorder events.Recorder, disruptionBudgetMapping map[string]int, candidates []*Candidate) (Command, scheduling.Results, error) {
 	EligibleNodesGauge.With(map[string]string{
-		methodLabel:            d.Type(),
-		consolidationTypeLabel: d.ConsolidationType(),
+		methodLabel:            m.Type(),
+		consolidationTypeLabel: m.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
 	// Do a quick check through the candidates to see if they're empty.
@@ -82,22 +90,23 @@ func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[
 			disruptionBudgetMapping[candidate.nodePool.Name]--
 		}
 	}
-	// Disrupt all empty drifted candidates, as they require no scheduling simulations.
+	// Disrupt all empty candidates, as they require no scheduling simulations.
 	if len(empty) > 0 {
 		return Command{
 			candidates: empty,
+			reason:     m.Reason(),
 		}, scheduling.Results{}, nil
 	}
 
 	for _, candidate := range candidates {
 		// If the disruption budget doesn't allow this candidate to be disrupted,
 		// continue to the next candidate. We don't need to decrement any budget
-		// counter since drift commands can only have one candidate.
+		// counter since these commands can only have one candidate.
 		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
 			continue
 		}
 		// Check if we need to create any NodeClaims.
-		results, err := SimulateScheduling(ctx, d.kubeClient, d.cluster, d.provisioner, candidate)
+		results, err := SimulateScheduling(ctx, kubeClient, cluster, provisioner, candidate)
 		if err != nil {
 			// if a candidate is now deleting, just retry
 			if errors.Is(err, errCandidateDeleting) {
@@ -107,12 +116,12 @@ func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[
 		}
 		// Emit an event that we couldn't reschedule the pods on the node.
 		if !results.AllNonPendingPodsScheduled() {
-			d.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
+			recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
 			continue
 		}
-
 		return Command{
 			candidates:   []*Candidate{candidate},
+			reason:       m.Reason(),
 			replacements: results.NewNodeClaims,
 		}, results, nil
 	}
@@ -126,3 +135,7 @@ func (d *Drift) Type() string {
 func (d *Drift) ConsolidationType() string {
 	return ""
 }
+
+func (d *Drift) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonDrifted
+}
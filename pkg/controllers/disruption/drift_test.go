@@ -17,6 +17,8 @@ limitations under the License.
 package disruption_test
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,6 +38,8 @@ import (
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -177,6 +181,12 @@ var _ = Describe("Drift", func() {
 			// Execute command, thus deleting no nodes
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(numNodes))
+
+			// Both the node and the nodeclaim of each drifted candidate are reported as blocked by the budget
+			blocked := lo.Filter(recorder.Events(), func(e events.Event, _ int) bool {
+				return e.Reason == disruptionevents.BlockedByBudgetReason && strings.Contains(e.Message, "for reason Drifted")
+			})
+			Expect(blocked).To(HaveLen(2 * numNodes))
 		})
 		It("should only allow 3 empty nodes to be disrupted", func() {
 			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1beta1.NodeClaim{
@@ -619,6 +629,35 @@ var _ = Describe("Drift", func() {
 			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+
+			// The decision is recorded on both the node and the nodeclaim
+			Expect(recorder.Calls(disruptionevents.DriftedReason)).To(Equal(2))
+			Expect(recorder.DetectedEvent("Disrupting Node for reason Drifted, deleting")).To(BeTrue())
+			Expect(recorder.Calls(disruptionevents.ReplacedReason)).To(Equal(0))
+		})
+		It("can delete drifted nodes with the karpenter.sh/do-not-consolidate annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1beta1.DoNotConsolidateAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			// The annotation only blocks consolidation, so the drifted nodeClaim is still deleted
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
 		It("should disrupt all empty drifted nodes in parallel", func() {
 			nodeClaims, nodes := test.NodeClaimsAndNodes(100, v1beta1.NodeClaim{
@@ -716,6 +755,12 @@ var _ = Describe("Drift", func() {
 			Expect(nodes).To(HaveLen(1))
 			Expect(nodeclaims[0].Name).ToNot(Equal(nodeClaim.Name))
 			Expect(nodes[0].Name).ToNot(Equal(node.Name))
+
+			// The decision and the replacement are recorded on both the node and the nodeclaim
+			Expect(recorder.Calls(disruptionevents.DriftedReason)).To(Equal(2))
+			Expect(recorder.DetectedEvent("Disrupting NodeClaim for reason Drifted, replacing with 1 nodeclaim(s)")).To(BeTrue())
+			Expect(recorder.Calls(disruptionevents.ReplacedReason)).To(Equal(2))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Replaced NodeClaim with NodeClaim(s) %s", nodeclaims[0].Name))).To(BeTrue())
 		})
 		It("should untaint nodes when drift replacement fails", func() {
 			cloudProvider.AllowedCreateCalls = 0 // fail the replacement and expect it to untaint
@@ -22,9 +22,12 @@ import (
 
 	"github.com/samber/lo"
 	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
@@ -34,14 +37,20 @@ import (
 // Emptiness is a subreconciler that deletes empty candidates.
 // Emptiness will respect TTLSecondsAfterEmpty
 type Emptiness struct {
-	clock    clock.Clock
-	recorder events.Recorder
+	clock       clock.Clock
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
 }
 
-func NewEmptiness(clk clock.Clock, recorder events.Recorder) *Emptiness {
+func NewEmptiness(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Emptiness {
 	return &Emptiness{
-		clock:    clk,
-		recorder: recorder,
+		clock:       clk,
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
 	}
 }
 
@@ -57,12 +66,16 @@ func (e *Emptiness) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 		e.recorder.Publish(disruptionevents.Unconsolidatable(c.Node, c.NodeClaim, fmt.Sprintf("NodePool %q has consolidation disabled", c.nodePool.Name))...)
 		return false
 	}
+	if c.Annotations()[v1beta1.DoNotConsolidateAnnotationKey] == "true" {
+		e.recorder.Publish(disruptionevents.Unconsolidatable(c.Node, c.NodeClaim, fmt.Sprintf("%s annotation exists", v1beta1.DoNotConsolidateAnnotationKey))...)
+		return false
+	}
 	return c.NodeClaim.StatusConditions().GetCondition(v1beta1.Empty).IsTrue() &&
 		!e.clock.Now().Before(c.NodeClaim.StatusConditions().GetCondition(v1beta1.Empty).LastTransitionTime.Inner.Add(*c.nodePool.Spec.Disruption.ConsolidateAfter.Duration))
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (e *Emptiness) ComputeCommand(_ context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+func (e *Emptiness) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
 	// First check how many nodes are empty so that we can emit a metric on how many nodes are eligible
 	emptyCandidates := lo.Filter(candidates, func(cn *Candidate, _ int) bool {
 		return cn.NodeClaim.DeletionTimestamp.IsZero() && len(cn.reschedulablePods) == 0
@@ -85,8 +98,13 @@ func (e *Emptiness) ComputeCommand(_ context.Context, disruptionBudgetMapping ma
 			disruptionBudgetMapping[candidate.nodePool.Name]--
 		}
 	}
+	empty, err := filterHeadroomCandidates(ctx, e.kubeClient, e.cluster, e.provisioner, empty)
+	if err != nil {
+		return Command{}, scheduling.Results{}, fmt.Errorf("filtering candidates needed for headroom, %w", err)
+	}
 	return Command{
 		candidates: empty,
+		reason:     e.Reason(),
 	}, scheduling.Results{}, nil
 }
 
@@ -97,3 +115,7 @@ func (e *Emptiness) Type() string {
 func (e *Emptiness) ConsolidationType() string {
 	return ""
 }
+
+func (e *Emptiness) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonEmpty
+}
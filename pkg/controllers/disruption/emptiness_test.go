@@ -30,8 +30,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1alpha5"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -105,6 +107,159 @@ var _ = Describe("Emptiness", func() {
 			Expect(recorder.Calls("Unconsolidatable")).To(Equal(2))
 		})
 	})
+	Context("Dry Run", func() {
+		It("should record the disruption of empty nodes without disrupting them when the NodePool is a dry run", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1beta1.DisruptionDryRunAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// The same command is computed again on the next pass, but the node is only counted once for it
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// The node and nodeclaim both get a dry run event
+			Expect(recorder.Calls("DisruptionDryRun")).To(Equal(2))
+			ExpectMetricCounterValue("karpenter_disruption_nodes_disrupted_total", 1, map[string]string{
+				"nodepool": nodePool.Name,
+				"action":   "delete",
+				"dry_run":  "true",
+			})
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			ExpectTaintedNodeCount(ctx, env.Client, 0)
+			ExpectExists(ctx, env.Client, nodeClaim)
+			ExpectExists(ctx, env.Client, node)
+		})
+		It("should disrupt the empty nodes of other NodePools when a NodePool is a dry run", func() {
+			dryRunNodePool := test.NodePool(v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1beta1.DisruptionDryRunAnnotationKey: "true"},
+				},
+				Spec: nodePool.Spec,
+			})
+			dryRunNodeClaim, dryRunNode := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     dryRunNodePool.Name,
+						v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+						v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+						v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID: test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceCPU:  resource.MustParse("32"),
+						v1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			dryRunNodeClaim.StatusConditions().MarkTrue(v1beta1.Empty)
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, dryRunNodeClaim, dryRunNode, dryRunNodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, dryRunNode}, []*v1beta1.NodeClaim{nodeClaim, dryRunNodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			Expect(recorder.Calls("DisruptionDryRun")).To(Equal(2))
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+			ExpectExists(ctx, env.Client, dryRunNodeClaim)
+			ExpectExists(ctx, env.Client, dryRunNode)
+		})
+	})
+	Context("Disruption Actions", func() {
+		It("should record the command along with its result", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			actions := &v1alpha1.DisruptionActionList{}
+			Expect(env.Client.List(ctx, actions)).To(Succeed())
+			Expect(actions.Items).To(HaveLen(1))
+			action := &actions.Items[0]
+			Expect(action.Spec.Method).To(Equal("emptiness"))
+			Expect(action.Spec.Action).To(Equal("delete"))
+			Expect(action.Spec.DryRun).To(BeFalse())
+			Expect(action.Spec.Candidates).To(ConsistOf(v1alpha1.DisruptionCandidate{
+				NodeClaim:    nodeClaim.Name,
+				Node:         node.Name,
+				NodePool:     nodePool.Name,
+				InstanceType: mostExpensiveInstance.Name,
+				CapacityType: mostExpensiveOffering.CapacityType,
+				Zone:         mostExpensiveOffering.Zone,
+			}))
+			Expect(action.Status.Result).To(Equal(v1alpha1.DisruptionResultPending))
+			Expect(action.Status.CompletionTime).To(BeNil())
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			action = ExpectExists(ctx, env.Client, action)
+			Expect(action.Status.Result).To(Equal(v1alpha1.DisruptionResultSucceeded))
+			Expect(action.Status.CompletionTime).ToNot(BeNil())
+		})
+		It("should record a dry run once however often it's computed", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1beta1.DisruptionDryRunAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+			fakeClock.Step(10 * time.Minute)
+
+			for i := 0; i < 2; i++ {
+				var wg sync.WaitGroup
+				ExpectTriggerVerifyAction(&wg)
+				ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+				wg.Wait()
+			}
+
+			actions := &v1alpha1.DisruptionActionList{}
+			Expect(env.Client.List(ctx, actions)).To(Succeed())
+			Expect(actions.Items).To(HaveLen(1))
+			Expect(actions.Items[0].Spec.DryRun).To(BeTrue())
+			Expect(actions.Items[0].Status.Result).To(Equal(v1alpha1.DisruptionResultDryRun))
+			Expect(actions.Items[0].Status.CompletionTime).ToNot(BeNil())
+		})
+		It("should not record the command when the retention is zero", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionActionRetention: lo.ToPtr(time.Duration(0))}))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			actions := &v1alpha1.DisruptionActionList{}
+			Expect(env.Client.List(ctx, actions)).To(Succeed())
+			Expect(actions.Items).To(BeEmpty())
+		})
+	})
 	Context("Budgets", func() {
 		var numNodes = 10
 		var nodeClaims []*v1beta1.NodeClaim
@@ -247,6 +402,52 @@ var _ = Describe("Emptiness", func() {
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(7))
 		})
+		It("should not allow empty nodes to be disrupted below the nodePool's minNodes", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+						v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+						v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					Allocatable: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceCPU:  resource.MustParse("32"),
+						v1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.MinNodes = lo.ToPtr[int32](8)
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().MarkTrue(v1beta1.Empty)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+
+			// Step the clock 10 minutes so that the emptiness expires
+			fakeClock.Step(10 * time.Minute)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			metric, found := FindMetricWithLabelValues("karpenter_disruption_budgets_allowed_disruptions", map[string]string{
+				"nodepool": nodePool.Name,
+			})
+			Expect(found).To(BeTrue())
+			Expect(metric.GetGauge().GetValue()).To(BeNumerically("==", 2))
+
+			// Execute command, thus deleting 2 nodes
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(8))
+		})
 		It("should allow 2 nodes from each nodePool to be deleted", func() {
 			// Create 10 nodepools
 			nps := test.NodePools(10, v1beta1.NodePool{
@@ -411,6 +612,60 @@ var _ = Describe("Emptiness", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
+		It("should not delete empty nodes that the nodePool's headroom needs", func() {
+			nodePool.Spec.Headroom = &v1beta1.Headroom{Count: 1, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete empty nodes beyond what the nodePool's headroom needs", func() {
+			nodePool.Spec.Headroom = &v1beta1.Headroom{Count: 1, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+						v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+						v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID: test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceCPU:  resource.MustParse("32"),
+						v1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodeClaim2.StatusConditions().MarkTrue(v1beta1.Empty)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, nodeClaim2, node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+			wg := sync.WaitGroup{}
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+			wg.Wait()
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+
+			// we should only delete one of the empty nodes
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+		})
 		It("should ignore nodes without the empty status condition", func() {
 			_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Empty)
 			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
@@ -439,6 +694,20 @@ var _ = Describe("Emptiness", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaim)
 		})
+		It("should ignore nodes with the karpenter.sh/do-not-consolidate annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1beta1.DoNotConsolidateAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
 		It("should ignore nodes that have pods with the karpenter.sh/do-not-evict annotation", func() {
 			pod := test.Pod(test.PodOptions{
 				ObjectMeta: metav1.ObjectMeta{
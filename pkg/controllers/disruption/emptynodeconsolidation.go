@@ -23,6 +23,7 @@ import (
 
 	"knative.dev/pkg/logging"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 )
@@ -65,6 +66,10 @@ func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 		empty = append(empty, candidate)
 		disruptionBudgetMapping[candidate.nodePool.Name]--
 	}
+	empty, err := filterHeadroomCandidates(ctx, c.kubeClient, c.cluster, c.provisioner, empty)
+	if err != nil {
+		return Command{}, scheduling.Results{}, fmt.Errorf("filtering candidates needed for headroom, %w", err)
+	}
 	// none empty, so do nothing
 	if len(empty) == 0 {
 		// if there are no candidates, but a nodepool had a fully blocking budget,
@@ -78,6 +83,7 @@ func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 
 	cmd := Command{
 		candidates: empty,
+		reason:     c.Reason(),
 	}
 
 	// Empty Node Consolidation doesn't use Validation as we get to take advantage of cluster.IsNodeNominated.  This
@@ -97,7 +103,7 @@ func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 	// We do this so that we can re-validate that the candidates that were computed before we made the decision are the same
 	candidatesToDelete := mapCandidates(cmd.candidates, validationCandidates)
 
-	postValidationMapping, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.recorder)
+	postValidationMapping, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.recorder, c.queue, c.Reason())
 	if err != nil {
 		return Command{}, scheduling.Results{}, fmt.Errorf("building disruption budgets, %w", err)
 	}
@@ -113,7 +119,7 @@ func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 		}
 		postValidationMapping[n.nodePool.Name]--
 	}
-	return cmd, scheduling.Results{}, nil
+	return c.withEstimatedSavings(ctx, cmd), scheduling.Results{}, nil
 }
 
 func (c *EmptyNodeConsolidation) Type() string {
@@ -123,3 +129,7 @@ func (c *EmptyNodeConsolidation) Type() string {
 func (c *EmptyNodeConsolidation) ConsolidationType() string {
 	return "empty"
 }
+
+func (c *EmptyNodeConsolidation) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonEmpty
+}
@@ -18,6 +18,7 @@ package events
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/text/cases"
@@ -28,6 +29,38 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 )
 
+// Reasons of the events that record disruption decisions. A decision event is emitted on both the Node and the
+// NodeClaim of each disrupted candidate, so that auditing tools can reconstruct why nodes were removed.
+const (
+	ConsolidatedReason    = "Consolidated"
+	DriftedReason         = "Drifted"
+	ExpiredReason         = "Expired"
+	ResizedReason         = "Resized"
+	RequestedReason       = "Requested"
+	ReplacedReason        = "Replaced"
+	BlockedByPDBReason    = "DisruptionBlockedByPDB"
+	BlockedByBudgetReason = "DisruptionBlockedByBudget"
+	DryRunReason          = "DisruptionDryRun"
+)
+
+// DecisionReason returns the reason of the decision event for a disruption reason. Empty and underutilized candidates
+// are both consolidated.
+func DecisionReason(reason v1beta1.DisruptionReason) string {
+	switch reason {
+	case v1beta1.DisruptionReasonEmpty, v1beta1.DisruptionReasonUnderutilized:
+		return ConsolidatedReason
+	case v1beta1.DisruptionReasonDrifted:
+		return DriftedReason
+	case v1beta1.DisruptionReasonExpired:
+		return ExpiredReason
+	case v1beta1.DisruptionReasonResized:
+		return ResizedReason
+	case v1beta1.DisruptionReasonRequested:
+		return RequestedReason
+	}
+	return string(reason)
+}
+
 func Launching(nodeClaim *v1beta1.NodeClaim, reason string) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
@@ -77,6 +110,98 @@ func Terminating(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []e
 	}
 }
 
+// Disrupted is an event that records the decision to disrupt a NodeClaim/Node combination, and whether it's deleted or
+// replaced
+func Disrupted(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason v1beta1.DisruptionReason, replacements int) []events.Event {
+	action := "deleting"
+	if replacements > 0 {
+		action = fmt.Sprintf("replacing with %d nodeclaim(s)", replacements)
+	}
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         DecisionReason(reason),
+			Message:        fmt.Sprintf("Disrupting Node for reason %s, %s", reason, action),
+			DedupeValues:   []string{string(node.UID), string(reason)},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         DecisionReason(reason),
+			Message:        fmt.Sprintf("Disrupting NodeClaim for reason %s, %s", reason, action),
+			DedupeValues:   []string{string(nodeClaim.UID), string(reason)},
+		},
+	}
+}
+
+// DryRunDisrupted is an event that records the decision that would have been made to disrupt a NodeClaim/Node
+// combination of a NodePool with the disruption dry-run annotation
+func DryRunDisrupted(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason v1beta1.DisruptionReason, replacements int) []events.Event {
+	action := "deleting"
+	if replacements > 0 {
+		action = fmt.Sprintf("replacing with %d nodeclaim(s)", replacements)
+	}
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         DryRunReason,
+			Message:        fmt.Sprintf("Would disrupt Node for reason %s, %s (dry run)", reason, action),
+			DedupeValues:   []string{string(node.UID), string(reason)},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         DryRunReason,
+			Message:        fmt.Sprintf("Would disrupt NodeClaim for reason %s, %s (dry run)", reason, action),
+			DedupeValues:   []string{string(nodeClaim.UID), string(reason)},
+		},
+	}
+}
+
+// Replaced is an event that informs the user that a NodeClaim/Node combination was replaced, once its replacements
+// have initialized and it's terminated
+func Replaced(node *v1.Node, nodeClaim *v1beta1.NodeClaim, replacements []string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         ReplacedReason,
+			Message:        fmt.Sprintf("Replaced Node with NodeClaim(s) %s", strings.Join(replacements, ", ")),
+			DedupeValues:   []string{string(node.UID)},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         ReplacedReason,
+			Message:        fmt.Sprintf("Replaced NodeClaim with NodeClaim(s) %s", strings.Join(replacements, ", ")),
+			DedupeValues:   []string{string(nodeClaim.UID)},
+		},
+	}
+}
+
+// EstimatedSavings is an event that informs the user of the expected monthly cost reduction from consolidating a
+// NodeClaim/Node combination, along with any other nodes that are consolidated in the same command
+func EstimatedSavings(node *v1.Node, nodeClaim *v1beta1.NodeClaim, monthlySavings float64) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionEstimatedSavings",
+			Message:        fmt.Sprintf("Consolidating Node is estimated to save $%.2f/month", monthlySavings),
+			DedupeValues:   []string{string(node.UID)},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionEstimatedSavings",
+			Message:        fmt.Sprintf("Consolidating NodeClaim is estimated to save $%.2f/month", monthlySavings),
+			DedupeValues:   []string{string(nodeClaim.UID)},
+		},
+	}
+}
+
 // Unconsolidatable is an event that informs the user that a NodeClaim/Node combination cannot be consolidated
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Unconsolidatable(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []events.Event {
@@ -121,6 +246,52 @@ func Blocked(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []event
 	}
 }
 
+// BlockedByPDB is an event that informs the user that a NodeClaim/Node combination can't be disrupted because a PDB
+// prevents the eviction of its pods
+func BlockedByPDB(node *v1.Node, nodeClaim *v1beta1.NodeClaim, pdbKey string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         BlockedByPDBReason,
+			Message:        fmt.Sprintf("Cannot disrupt Node: PDB %q prevents pod evictions", pdbKey),
+			DedupeValues:   []string{string(node.UID), pdbKey},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         BlockedByPDBReason,
+			Message:        fmt.Sprintf("Cannot disrupt NodeClaim: PDB %q prevents pod evictions", pdbKey),
+			DedupeValues:   []string{string(nodeClaim.UID), pdbKey},
+		},
+	}
+}
+
+// BlockedByBudget is an event that informs the user that a NodeClaim/Node combination would be disrupted, but its
+// nodepool's disruption budgets don't allow any disruptions for the reason
+func BlockedByBudget(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason v1beta1.DisruptionReason) []events.Event {
+	nodePoolName := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         BlockedByBudgetReason,
+			Message:        fmt.Sprintf("Cannot disrupt Node for reason %s: budgets of nodepool %q don't allow any disruptions", reason, nodePoolName),
+			DedupeValues:   []string{string(node.UID), string(reason)},
+			// Set a small timeout as a NodePool's disruption budget can change every minute.
+			DedupeTimeout: 1 * time.Minute,
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         BlockedByBudgetReason,
+			Message:        fmt.Sprintf("Cannot disrupt NodeClaim for reason %s: budgets of nodepool %q don't allow any disruptions", reason, nodePoolName),
+			DedupeValues:   []string{string(nodeClaim.UID), string(reason)},
+			DedupeTimeout:  1 * time.Minute,
+		},
+	}
+}
+
 func NodePoolBlocked(nodePool *v1beta1.NodePool) events.Event {
 	return events.Event{
 		InvolvedObject: nodePool,
@@ -55,10 +55,10 @@ func NewExpiration(clk clock.Clock, kubeClient client.Client, cluster *state.Clu
 	}
 }
 
-// ShouldDisrupt is a predicate used to filter candidates
+// ShouldDisrupt is a predicate used to filter candidates. NodeClaims can expire through the lifetimes requested by
+// their pods even if their nodepool doesn't expire them.
 func (e *Expiration) ShouldDisrupt(_ context.Context, c *Candidate) bool {
-	return c.nodePool.Spec.Disruption.ExpireAfter.Duration != nil &&
-		c.NodeClaim.StatusConditions().GetCondition(v1beta1.Expired).IsTrue()
+	return c.NodeClaim.StatusConditions().GetCondition(v1beta1.Expired).IsTrue()
 }
 
 // ComputeCommand generates a disruption command given candidates
@@ -92,6 +92,7 @@ func (e *Expiration) ComputeCommand(ctx context.Context, disruptionBudgetMapping
 	if len(empty) > 0 {
 		return Command{
 			candidates: empty,
+			reason:     e.Reason(),
 		}, scheduling.Results{}, nil
 	}
 
@@ -119,6 +120,7 @@ func (e *Expiration) ComputeCommand(ctx context.Context, disruptionBudgetMapping
 		logging.FromContext(ctx).With("ttl", candidates[0].nodePool.Spec.Disruption.ExpireAfter.String()).Infof("triggering termination for expired node after TTL")
 		return Command{
 			candidates:   []*Candidate{candidate},
+			reason:       e.Reason(),
 			replacements: results.NewNodeClaims,
 		}, results, nil
 	}
@@ -132,3 +134,7 @@ func (e *Expiration) Type() string {
 func (e *Expiration) ConsolidationType() string {
 	return ""
 }
+
+func (e *Expiration) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonExpired
+}
@@ -0,0 +1,77 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollection
+
+import (
+	"context"
+
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+var _ operatorcontroller.TypedController[*v1alpha1.DisruptionAction] = (*Controller)(nil)
+
+// Controller deletes DisruptionActions once they've been kept for the retention. The retention starts when the command
+// completes, or when it was recorded for commands that never completed, e.g. because the queue lost track of them.
+type Controller struct {
+	clock      clock.Clock
+	kubeClient client.Client
+}
+
+// NewController constructs a controller instance
+func NewController(clk clock.Clock, kubeClient client.Client) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1alpha1.DisruptionAction](kubeClient, &Controller{
+		clock:      clk,
+		kubeClient: kubeClient,
+	})
+}
+
+func (c *Controller) Name() string {
+	return "disruption.garbagecollection"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, action *v1alpha1.DisruptionAction) (reconcile.Result, error) {
+	if !action.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	recorded := action.CreationTimestamp.Time
+	if action.Status.CompletionTime != nil {
+		recorded = action.Status.CompletionTime.Time
+	}
+	if remaining := recorded.Add(options.FromContext(ctx).DisruptionActionRetention).Sub(c.clock.Now()); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	if err := c.kubeClient.Delete(ctx, action); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	logging.FromContext(ctx).Debugf("deleted disruption action after its retention")
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1alpha1.DisruptionAction{}))
+}
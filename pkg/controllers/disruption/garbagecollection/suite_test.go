@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollection_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption/garbagecollection"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var garbageCollectionController controller.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DisruptionActionGarbageCollection")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionActionRetention: lo.ToPtr(24 * time.Hour)}))
+	fakeClock = clock.NewFakeClock(time.Now())
+	garbageCollectionController = garbagecollection.NewController(fakeClock, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	fakeClock.SetTime(time.Now())
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("GarbageCollection", func() {
+	var action *v1alpha1.DisruptionAction
+	BeforeEach(func() {
+		action = &v1alpha1.DisruptionAction{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.DisruptionActionSpec{
+				Method: "emptiness",
+				Reason: "Empty",
+				Action: "delete",
+				Candidates: []v1alpha1.DisruptionCandidate{{
+					NodeClaim: test.RandomName(),
+					NodePool:  test.RandomName(),
+				}},
+			},
+		}
+	})
+	It("should delete disruption actions once they've been kept for the retention after completing", func() {
+		action.Status = v1alpha1.DisruptionActionStatus{
+			Result:         v1alpha1.DisruptionResultSucceeded,
+			CompletionTime: lo.ToPtr(metav1.NewTime(fakeClock.Now())),
+		}
+		ExpectApplied(ctx, env.Client, action)
+		fakeClock.Step(25 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKeyFromObject(action))
+		ExpectNotFound(ctx, env.Client, action)
+	})
+	It("should keep disruption actions that haven't been kept for the retention", func() {
+		action.Status = v1alpha1.DisruptionActionStatus{
+			Result:         v1alpha1.DisruptionResultFailed,
+			CompletionTime: lo.ToPtr(metav1.NewTime(fakeClock.Now())),
+		}
+		ExpectApplied(ctx, env.Client, action)
+		fakeClock.Step(23 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKeyFromObject(action))
+		ExpectExists(ctx, env.Client, action)
+	})
+	It("should delete disruption actions that never completed once they've been kept for the retention", func() {
+		action.Status = v1alpha1.DisruptionActionStatus{Result: v1alpha1.DisruptionResultPending}
+		ExpectApplied(ctx, env.Client, action)
+		fakeClock.Step(25 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKeyFromObject(action))
+		ExpectNotFound(ctx, env.Client, action)
+	})
+})
@@ -26,6 +26,7 @@ import (
 
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -79,6 +80,12 @@ func SimulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 		pods = append(pods, n.reschedulablePods...)
 	}
 	pods = append(pods, deletingNodePods...)
+	// Headroom is simulated as well, so that nodes aren't disrupted if the headroom would no longer fit without them
+	headroomPods, err := provisioner.HeadroomPods(ctx)
+	if err != nil {
+		return pscheduling.Results{}, fmt.Errorf("determining headroom pods, %w", err)
+	}
+	pods = append(pods, headroomPods...)
 	scheduler, err := provisioner.NewScheduler(logging.WithLogger(ctx, operatorlogging.NopLogger), pods, stateNodes)
 	if err != nil {
 		return pscheduling.Results{}, fmt.Errorf("creating scheduler, %w", err)
@@ -141,7 +148,24 @@ func GetPodEvictionCost(ctx context.Context, p *v1.Pod) float64 {
 	}
 
 	// overall we clamp the pod cost to the range [-10.0, 10.0] with the default being 1.0
-	return clamp(-10.0, cost, 10.0)
+	cost = clamp(-10.0, cost, 10.0)
+
+	// the disruption cost is how many additional pods evicting the pod is worth, e.g. for pods that take a long time to
+	// warm up. It isn't clamped so that nodes running these pods are ranked behind nodes that only run cheap pods.
+	if disruptionCostStr, ok := p.Annotations[v1beta1.DisruptionCostAnnotationKey]; ok {
+		disruptionCost, err := strconv.ParseFloat(disruptionCostStr, 64)
+		switch {
+		case err != nil:
+			logging.FromContext(ctx).Errorf("parsing %s=%s from pod %s, %s",
+				v1beta1.DisruptionCostAnnotationKey, disruptionCostStr, client.ObjectKeyFromObject(p), err)
+		case disruptionCost < 0 || math.IsInf(disruptionCost, 0) || math.IsNaN(disruptionCost):
+			logging.FromContext(ctx).Errorf("ignoring %s=%s from pod %s, must be a non-negative number",
+				v1beta1.DisruptionCostAnnotationKey, disruptionCostStr, client.ObjectKeyFromObject(p))
+		default:
+			cost += disruptionCost
+		}
+	}
+	return cost
 }
 
 // filterByPriceWithMinValues returns the instanceTypes that are lower priced than the current candidate and iterates over the cumulative minimum requirement of the InstanceTypeOptions to see if it meets the minValues of requirements.
@@ -196,14 +220,44 @@ func GetCandidates(ctx context.Context, cluster *state.Cluster, kubeClient clien
 	return lo.Filter(candidates, func(c *Candidate, _ int) bool { return shouldDeprovision(ctx, c) }), nil
 }
 
-// BuildDisruptionBudgets will return a map for nodePoolName -> numAllowedDisruptions and an error
-func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, recorder events.Recorder) (map[string]int, error) {
+// filterHeadroomCandidates returns the candidates that can be removed while still leaving their nodepools' headroom
+// schedulable. Candidates from nodepools without headroom are always kept. This is meant for empty candidates, which
+// are deleted without simulating scheduling, since they have no pods to reschedule.
+func filterHeadroomCandidates(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	candidates []*Candidate) ([]*Candidate, error) {
+	if !lo.ContainsBy(candidates, func(c *Candidate) bool { return c.nodePool.Spec.Headroom != nil }) {
+		return candidates, nil
+	}
+	var kept []*Candidate
+	for _, candidate := range candidates {
+		if candidate.nodePool.Spec.Headroom == nil {
+			kept = append(kept, candidate)
+			continue
+		}
+		results, err := SimulateScheduling(ctx, kubeClient, cluster, provisioner, append(kept, candidate)...)
+		if err != nil {
+			return nil, err
+		}
+		if !lo.ContainsBy(results.NewNodeClaims, func(n *pscheduling.NodeClaim) bool {
+			return lo.ContainsBy(n.Pods, podutil.IsOwnedByNodePool)
+		}) {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept, nil
+}
+
+// BuildDisruptionBudgets will return a map for nodePoolName -> numAllowedDisruptions for the given disruption reason and an error
+func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, recorder events.Recorder,
+	queue *orchestration.Queue, reason v1beta1.DisruptionReason) (map[string]int, error) {
 	nodePoolList := &v1beta1.NodePoolList{}
 	if err := kubeClient.List(ctx, nodePoolList); err != nil {
 		return nil, fmt.Errorf("listing node pools, %w", err)
 	}
 	numNodes := map[string]int{}
-	deleting := map[string]int{}
+	// The deleting nodes that are disrupted by a command in the queue are keyed by its reason, since they only count
+	// against the budgets of their reason
+	deleting := map[string]map[v1beta1.DisruptionReason]int{}
 	disruptionBudgetMapping := map[string]int{}
 	// We need to get all the nodes in the cluster
 	// Get each current active number of nodes per nodePool
@@ -227,19 +281,25 @@ func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clo
 		// 1. Has a NotReady conditiion
 		// 2. Is marked as deleting
 		if cond := nodeutils.GetCondition(node.Node, v1.NodeReady); cond.Status != v1.ConditionTrue || node.MarkedForDeletion() {
-			deleting[nodePool]++
+			if deleting[nodePool] == nil {
+				deleting[nodePool] = map[v1beta1.DisruptionReason]int{}
+			}
+			r, _ := queue.DisruptionReason(node.ProviderID())
+			deleting[nodePool][lo.Ternary(node.MarkedForDeletion(), r, "")]++
 		}
 		numNodes[nodePool]++
 	}
 
 	for i := range nodePoolList.Items {
 		nodePool := nodePoolList.Items[i]
-		disruptions := nodePool.MustGetAllowedDisruptions(ctx, clk, numNodes[nodePool.Name])
-		// Subtract the allowed number of disruptions from the number of already deleting nodes.
-		// Floor the value since the number of deleting nodes can exceed the number of allowed disruptions.
-		// Allowing this value to be negative breaks assumptions in the code used to calculate how
-		// many nodes can be disrupted.
-		allowedDisruptions := lo.Clamp(disruptions-deleting[nodePool.Name], 0, math.MaxInt32)
+		allowedDisruptions := nodePool.MustGetAllowedDisruptions(ctx, clk, numNodes[nodePool.Name], reason, deleting[nodePool.Name])
+		// Consolidation can't take the nodepool below its minimum number of nodes. Other disruption methods still
+		// apply, since the minnodes controller launches a node to replace any that are removed.
+		// Scaling windows with invalid schedules are rejected by validation, so the error is ignored here and only the
+		// windows that could be evaluated are considered.
+		if minNodes, _ := nodePool.GetMinNodes(clk); minNodes > 0 && (reason == v1beta1.DisruptionReasonEmpty || reason == v1beta1.DisruptionReasonUnderutilized) {
+			allowedDisruptions = lo.Clamp(numNodes[nodePool.Name]-lo.Sum(lo.Values(deleting[nodePool.Name]))-minNodes, 0, allowedDisruptions)
+		}
 		disruptionBudgetMapping[nodePool.Name] = allowedDisruptions
 		// If the nodepool is fully blocked, emit an event
 		if allowedDisruptions == 0 {
@@ -247,6 +307,7 @@ func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clo
 		}
 		BudgetsAllowedDisruptionsGauge.With(map[string]string{
 			metrics.NodePoolLabel: nodePool.Name,
+			metrics.ReasonLabel:   string(reason),
 		}).Set(float64(allowedDisruptions))
 	}
 	return disruptionBudgetMapping, nil
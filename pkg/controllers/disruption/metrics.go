@@ -32,6 +32,7 @@ func init() {
 		EligibleNodesGauge,
 		ConsolidationTimeoutTotalCounter,
 		BudgetsAllowedDisruptionsGauge,
+		ConsolidationEstimatedSavingsCounter,
 	)
 }
 
@@ -40,6 +41,7 @@ const (
 	actionLabel            = "action"
 	methodLabel            = "method"
 	consolidationTypeLabel = "consolidation_type"
+	dryRunLabel            = "dry_run"
 )
 
 var (
@@ -67,9 +69,9 @@ var (
 			Namespace: metrics.Namespace,
 			Subsystem: disruptionSubsystem,
 			Name:      "nodes_disrupted_total",
-			Help:      "Total number of nodes disrupted. Labeled by NodePool, disruption action, method, and consolidation type.",
+			Help:      "Total number of nodes disrupted. Nodes of NodePools with the karpenter.sh/disruption-dry-run annotation are counted once for each command that would have disrupted them. Labeled by NodePool, disruption action, method, consolidation type, and whether it was a dry run.",
 		},
-		[]string{metrics.NodePoolLabel, actionLabel, methodLabel, consolidationTypeLabel},
+		[]string{metrics.NodePoolLabel, actionLabel, methodLabel, consolidationTypeLabel, dryRunLabel},
 	)
 	PodsDisruptedCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -98,13 +100,22 @@ var (
 		},
 		[]string{consolidationTypeLabel},
 	)
+	ConsolidationEstimatedSavingsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "consolidation_estimated_monthly_savings_total",
+			Help:      "Estimated monthly cost savings of the consolidation actions performed, in the currency of the cloudprovider's pricing. Labeled by disruption method and consolidation type.",
+		},
+		[]string{methodLabel, consolidationTypeLabel},
+	)
 	BudgetsAllowedDisruptionsGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: metrics.Namespace,
 			Subsystem: disruptionSubsystem,
 			Name:      "budgets_allowed_disruptions",
-			Help:      "The number of nodes for a given NodePool that can be disrupted at a point in time. Labeled by NodePool. Note that allowed disruptions can change very rapidly, as new nodes may be created and others may be deleted at any point.",
+			Help:      "The number of nodes for a given NodePool that can be disrupted at a point in time. Labeled by NodePool and disruption reason. Note that allowed disruptions can change very rapidly, as new nodes may be created and others may be deleted at any point.",
 		},
-		[]string{metrics.NodePoolLabel},
+		[]string{metrics.NodePoolLabel, metrics.ReasonLabel},
 	)
 )
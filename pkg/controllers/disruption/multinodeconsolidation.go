@@ -20,19 +20,21 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"time"
 
 	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/logging"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
-const MultiNodeConsolidationTimeoutDuration = 1 * time.Minute
-
 type MultiNodeConsolidation struct {
 	consolidation
 }
@@ -101,7 +103,7 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 		logging.FromContext(ctx).Debugf("abandoning multi-node consolidation attempt due to pod churn, command is no longer valid, %s", cmd)
 		return Command{}, scheduling.Results{}, nil
 	}
-	return cmd, results, nil
+	return m.withEstimatedSavings(ctx, cmd), results, nil
 }
 
 // firstNConsolidationOption looks at the first N NodeClaims to determine if they can all be consolidated at once.  The
@@ -118,9 +120,10 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 
 	lastSavedCommand := Command{}
 	lastSavedResults := scheduling.Results{}
+	parallelism := options.FromContext(ctx).MultiNodeConsolidationParallelism
 	// Set a timeout
-	timeout := m.clock.Now().Add(MultiNodeConsolidationTimeoutDuration)
-	// binary search to find the maximum number of NodeClaims we can terminate
+	timeout := m.clock.Now().Add(options.FromContext(ctx).MultiNodeConsolidationTimeout)
+	// search to find the maximum number of NodeClaims we can terminate, evaluating several batch sizes at once
 	for min <= max {
 		if m.clock.Now().After(timeout) {
 			ConsolidationTimeoutTotalCounter.WithLabelValues(m.ConsolidationType()).Inc()
@@ -131,33 +134,73 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 			}
 			return lastSavedCommand, lastSavedResults, nil
 		}
-		mid := (min + max) / 2
-		candidatesToConsolidate := candidates[0 : mid+1]
-
-		cmd, results, err := m.computeConsolidation(ctx, candidatesToConsolidate...)
-		if err != nil {
+		sizes := batchSizes(min, max, parallelism)
+		cmds := make([]Command, len(sizes))
+		results := make([]scheduling.Results, len(sizes))
+		errs := make([]error, len(sizes))
+		workqueue.ParallelizeUntil(ctx, parallelism, len(sizes), func(i int) {
+			cmds[i], results[i], errs[i] = m.consolidationOption(ctx, candidates[0:sizes[i]+1])
+		})
+		if err := multierr.Combine(errs...); err != nil {
 			return Command{}, scheduling.Results{}, err
 		}
-
-		// ensure that the action is sensical for replacements, see explanation on filterOutSameType for why this is
-		// required
-		replacementHasValidInstanceTypes := false
-		if cmd.Action() == ReplaceAction {
-			cmd.replacements[0].InstanceTypeOptions = filterOutSameType(cmd.replacements[0], candidatesToConsolidate)
-			replacementHasValidInstanceTypes = len(cmd.replacements[0].InstanceTypeOptions) > 0
+		// Batches are consolidatable up to some size, so the largest batch that can be consolidated bounds the search
+		// from below and every larger batch that couldn't bounds it from above
+		for i := len(sizes) - 1; i >= 0; i-- {
+			if cmds[i].Action() == NoOpAction {
+				max = sizes[i] - 1
+				continue
+			}
+			// We can consolidate NodeClaims [0,sizes[i]]
+			lastSavedCommand = cmds[i]
+			lastSavedResults = results[i]
+			min = sizes[i] + 1
+			break
 		}
+	}
+	return lastSavedCommand, lastSavedResults, nil
+}
+
+// batchSizes returns up to n batch sizes spread evenly across [min, max]. With a single batch this is a binary search,
+// with more the largest batch is always included so that we exit after one round if every candidate can be
+// consolidated.
+func batchSizes(min, max, n int) []int {
+	if n <= 1 {
+		return []int{(min + max) / 2}
+	}
+	n = lo.Clamp(n, 1, max-min+1)
+	sizes := make([]int, 0, n)
+	for i := 1; i <= n; i++ {
+		sizes = append(sizes, min+i*(max-min)/n)
+	}
+	return lo.Uniq(sizes)
+}
+
+// consolidationOption computes the consolidation of the candidates, returning a no-op command if they can't all be
+// consolidated at once
+func (m *MultiNodeConsolidation) consolidationOption(ctx context.Context, candidates []*Candidate) (Command, scheduling.Results, error) {
+	// Scheduling relaxes the preferences of the pods it's given, so each batch works on its own copy of the
+	// candidates' pods to be evaluated in parallel with other batches
+	candidates = lo.Map(candidates, func(c *Candidate, _ int) *Candidate {
+		cp := *c
+		cp.reschedulablePods = lo.Map(c.reschedulablePods, func(p *v1.Pod, _ int) *v1.Pod { return p.DeepCopy() })
+		return &cp
+	})
+	cmd, results, err := m.computeConsolidation(ctx, candidates...)
+	if err != nil {
+		return Command{}, scheduling.Results{}, err
+	}
 
-		// replacementHasValidInstanceTypes will be false if the replacement action has valid instance types remaining after filtering.
-		if replacementHasValidInstanceTypes || cmd.Action() == DeleteAction {
-			// We can consolidate NodeClaims [0,mid]
-			lastSavedCommand = cmd
-			lastSavedResults = results
-			min = mid + 1
-		} else {
-			max = mid - 1
+	// ensure that the action is sensical for replacements, see explanation on filterOutSameType for why this is
+	// required
+	if cmd.Action() == ReplaceAction {
+		cmd.replacements[0].InstanceTypeOptions = filterOutSameType(cmd.replacements[0], candidates)
+		// the replacement isn't valid if it doesn't have any instance types remaining after filtering
+		if len(cmd.replacements[0].InstanceTypeOptions) == 0 {
+			return Command{}, scheduling.Results{}, nil
 		}
 	}
-	return lastSavedCommand, lastSavedResults, nil
+	return cmd, results, nil
 }
 
 // filterOutSameType filters out instance types that are more expensive than the cheapest instance type that is being
@@ -222,3 +265,7 @@ func (m *MultiNodeConsolidation) Type() string {
 func (m *MultiNodeConsolidation) ConsolidationType() string {
 	return "multi"
 }
+
+func (m *MultiNodeConsolidation) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonUnderutilized
+}
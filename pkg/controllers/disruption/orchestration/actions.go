@@ -0,0 +1,51 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orchestration
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+)
+
+// CompleteAction records the result of the command on the DisruptionAction that was created for it, if any. The
+// command failed if failure isn't nil. Like creating the DisruptionAction, recording the result is best effort.
+func (q *Queue) CompleteAction(ctx context.Context, id types.UID, failure error) {
+	action := &v1alpha1.DisruptionAction{}
+	if err := q.kubeClient.Get(ctx, client.ObjectKey{Name: string(id)}, action); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logging.FromContext(ctx).Errorf("getting disruption action, %s", err)
+		}
+		return
+	}
+	stored := action.DeepCopy()
+	action.Status.Result = lo.Ternary(failure == nil, v1alpha1.DisruptionResultSucceeded, v1alpha1.DisruptionResultFailed)
+	if failure != nil {
+		action.Status.Message = failure.Error()
+	}
+	action.Status.CompletionTime = lo.ToPtr(metav1.NewTime(q.clock.Now()))
+	if err := q.kubeClient.Status().Patch(ctx, action, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+		logging.FromContext(ctx).Errorf("patching disruption action status, %s", err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+const commandsKey = "commands"
+
+// ConfigMapName returns the name of the ConfigMap in Karpenter's namespace that the commands in the queue are persisted
+// to. It's scoped to the shard of nodepools that this instance of Karpenter manages, since each shard disrupts its own
+// nodes.
+func ConfigMapName(ctx context.Context) string {
+	return nodepoolutil.ShardScopedName(ctx, "karpenter-disruption-queue")
+}
+
+// persistedCommand is the part of a command that's needed to resume it after a leader failover
+type persistedCommand struct {
+	ID                types.UID                `json:"id"`
+	Method            string                   `json:"method"`
+	ConsolidationType string                   `json:"consolidationType,omitempty"`
+	Reason            v1beta1.DisruptionReason `json:"reason,omitempty"`
+	Candidates        []string                 `json:"candidates"` // provider IDs of the candidates
+	Replacements      []persistedReplacement   `json:"replacements,omitempty"`
+	TimeAdded         time.Time                `json:"timeAdded"`
+}
+
+type persistedReplacement struct {
+	Name        string `json:"name"`
+	Initialized bool   `json:"initialized,omitempty"`
+}
+
+// Restore adds the commands that the previous leader persisted back to the queue, so that we finish disrupting the
+// nodes that it tainted instead of leaving them cordoned with nobody waiting on their replacements. It only restores
+// the commands once, and must be called once cluster state is synced so that the candidates can be found.
+func (q *Queue) Restore(ctx context.Context) error {
+	q.restoreMu.Lock()
+	defer q.restoreMu.Unlock()
+	if q.restored {
+		return nil
+	}
+	cm := &v1.ConfigMap{}
+	if err := q.kubeClient.Get(ctx, client.ObjectKey{Namespace: system.Namespace(), Name: ConfigMapName(ctx)}, cm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("getting disruption queue configmap, %w", err)
+	}
+	var persisted []persistedCommand
+	if data, ok := cm.Data[commandsKey]; ok {
+		if err := json.Unmarshal([]byte(data), &persisted); err != nil {
+			// Nodes of the commands that can't be read are untainted by the disruption controller, so they can be
+			// disrupted again
+			logging.FromContext(ctx).Errorf("unmarshaling persisted disruption commands, %s", err)
+		}
+		q.persisted = data
+	}
+	stateNodes := lo.SliceToMap(q.cluster.Nodes(), func(n *state.StateNode) (string, *state.StateNode) {
+		return n.ProviderID(), n
+	})
+	for _, p := range persisted {
+		cmd := &Command{
+			Replacements: lo.Map(p.Replacements, func(r persistedReplacement, _ int) Replacement {
+				return Replacement{name: r.Name, Initialized: r.Initialized}
+			}),
+			// Candidates that are gone no longer need to be disrupted
+			candidates: lo.FilterMap(p.Candidates, func(providerID string, _ int) (*state.StateNode, bool) {
+				n, ok := stateNodes[providerID]
+				return n, ok && n.NodeClaim != nil
+			}),
+			timeAdded:         p.TimeAdded,
+			id:                p.ID,
+			method:            p.Method,
+			consolidationType: p.ConsolidationType,
+			disruptionReason:  p.Reason,
+		}
+		if len(cmd.candidates) == 0 {
+			continue
+		}
+		providerIDs := lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string { return s.ProviderID() })
+		if q.HasAny(providerIDs...) {
+			continue
+		}
+		q.cluster.MarkForDeletion(providerIDs...)
+		q.add(cmd)
+		logging.FromContext(ctx).With("command-id", string(cmd.id), "nodes", len(cmd.candidates)).Infof("restored disruption command")
+	}
+	q.restored = true
+	return nil
+}
+
+// persist writes the commands in the queue to the ConfigMap if they've changed since they were last written
+func (q *Queue) persist(ctx context.Context) error {
+	q.restoreMu.Lock()
+	defer q.restoreMu.Unlock()
+	// Persisting before the previous leader's commands are restored would drop them
+	if !q.restored {
+		return nil
+	}
+	q.mu.RLock()
+	persisted := lo.Map(lo.Uniq(lo.Values(q.providerIDToCommand)), func(cmd *Command, _ int) persistedCommand {
+		return persistedCommand{
+			ID:                cmd.id,
+			Method:            cmd.method,
+			ConsolidationType: cmd.consolidationType,
+			Reason:            cmd.disruptionReason,
+			Candidates:        lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string { return s.ProviderID() }),
+			Replacements: lo.Map(cmd.Replacements, func(r Replacement, _ int) persistedReplacement {
+				return persistedReplacement{Name: r.name, Initialized: r.Initialized}
+			}),
+			TimeAdded: cmd.timeAdded,
+		}
+	})
+	q.mu.RUnlock()
+	// Commands are restored in the order that they were added
+	sort.Slice(persisted, func(i, j int) bool {
+		if !persisted[i].TimeAdded.Equal(persisted[j].TimeAdded) {
+			return persisted[i].TimeAdded.Before(persisted[j].TimeAdded)
+		}
+		return persisted[i].ID < persisted[j].ID
+	})
+	// There's nothing to persist until the first command is added
+	if len(persisted) == 0 && q.persisted == "" {
+		return nil
+	}
+	data, err := json.Marshal(persisted)
+	if err != nil {
+		return fmt.Errorf("marshaling disruption commands, %w", err)
+	}
+	if string(data) == q.persisted {
+		return nil
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: ConfigMapName(ctx)},
+		Data:       map[string]string{commandsKey: string(data)},
+	}
+	if err = q.kubeClient.Update(ctx, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("updating disruption queue configmap, %w", err)
+		}
+		if err = q.kubeClient.Create(ctx, cm); err != nil {
+			return fmt.Errorf("creating disruption queue configmap, %w", err)
+		}
+	}
+	q.persisted = string(data)
+	return nil
+}
@@ -26,6 +26,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +42,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
@@ -51,16 +54,21 @@ const (
 	queueBaseDelay   = 1 * time.Second
 	queueMaxDelay    = 10 * time.Second
 	maxRetryDuration = 10 * time.Minute
+	// maxConcurrentTerminations bounds the number of a command's candidates that are terminated at the same time, so
+	// that the candidates of large multi-node commands drain concurrently without flooding the API server
+	maxConcurrentTerminations = 10
 )
 
 type Command struct {
 	Replacements      []Replacement
 	candidates        []*state.StateNode
-	timeAdded         time.Time // timeAdded is used to track timeouts
-	id                types.UID // used for log tracking
-	method            string    // used for metrics
-	consolidationType string    // used for metrics
+	timeAdded         time.Time                // timeAdded is used to track timeouts
+	id                types.UID                // used for log tracking
+	method            string                   // used for metrics
+	consolidationType string                   // used for metrics
+	disruptionReason  v1beta1.DisruptionReason // used to count the candidates against the budgets of the reason
 	lastError         error
+	spanContext       trace.SpanContext // used to continue the trace of the disruption that created the command
 }
 
 // Replacement wraps a NodeClaim name with an initialized field to save on readiness checks and identify
@@ -100,6 +108,10 @@ type Queue struct {
 	mu                  sync.RWMutex
 	providerIDToCommand map[string]*Command // providerID -> command, maps a candidate to its command
 
+	restoreMu sync.Mutex
+	restored  bool   // whether the commands that were persisted by the previous leader were restored
+	persisted string // the commands that were last persisted
+
 	kubeClient  client.Client
 	recorder    events.Recorder
 	cluster     *state.Cluster
@@ -140,7 +152,7 @@ func NewTestingQueue(kubeClient client.Client, recorder events.Recorder, cluster
 }
 
 // NewCommand creates a command key and adds in initial data for the orchestration queue.
-func NewCommand(replacements []string, candidates []*state.StateNode, id types.UID, method, consolidationType string) *Command {
+func NewCommand(replacements []string, candidates []*state.StateNode, id types.UID, reason v1beta1.DisruptionReason, method, consolidationType string) *Command {
 	return &Command{
 		Replacements: lo.Map(replacements, func(name string, _ int) Replacement {
 			return Replacement{name: name}
@@ -148,10 +160,17 @@ func NewCommand(replacements []string, candidates []*state.StateNode, id types.U
 		candidates:        candidates,
 		method:            method,
 		consolidationType: consolidationType,
+		disruptionReason:  reason,
 		id:                id,
 	}
 }
 
+// WithSpanContext records the span that the command was created in, so that executing the command continues its trace
+func (c *Command) WithSpanContext(spanContext trace.SpanContext) *Command {
+	c.spanContext = spanContext
+	return c
+}
+
 func (q *Queue) Name() string {
 	return "disruption.queue"
 }
@@ -166,6 +185,18 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 	// commands that haven't completed their requeue backoff.
 	disruptionQueueDepthGauge.Set(float64(len(lo.Uniq(lo.Values(q.providerIDToCommand)))))
 
+	// The commands of the previous leader can only be restored once we know about their candidates
+	if q.cluster.Synced(ctx) {
+		if err := q.Restore(ctx); err != nil {
+			return reconcile.Result{}, fmt.Errorf("restoring disruption commands, %w", err)
+		}
+	}
+	// Persisting is best effort, since failing to persist should only ever cost us the commands that are in flight
+	// during a leader failover
+	if err := q.persist(ctx); err != nil {
+		logging.FromContext(ctx).Errorf("persisting disruption commands, %s", err)
+	}
+
 	// Check if the queue is empty. client-go recommends not using this function to gate the subsequent
 	// get call, but since we're popping items off the queue synchronously retrying, there should be
 	// no synchonization issues.
@@ -180,7 +211,12 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 	}
 	cmd := item.(*Command)
 	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("command-id", string(cmd.id)))
-	if err := q.waitOrTerminate(ctx, cmd); err != nil {
+	ctx, span := tracing.Start(trace.ContextWithSpanContext(ctx, cmd.spanContext), "disruption.queue",
+		attribute.String("command-id", string(cmd.id)),
+	)
+	err := q.waitOrTerminate(ctx, cmd)
+	tracing.End(span, err)
+	if err != nil {
 		// If recoverable, re-queue and try again.
 		if !IsUnrecoverableError(err) {
 			// store the error that is causing us to fail so we can bubble it up later if this times out.
@@ -206,6 +242,9 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 		logging.FromContext(ctx).With("nodes", strings.Join(lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string {
 			return s.Name()
 		}), ",")).Errorf("failed to disrupt nodes, %s", multiErr)
+		q.CompleteAction(ctx, cmd.id, multiErr)
+	} else {
+		q.CompleteAction(ctx, cmd.id, nil)
 	}
 	// If command is complete, remove command from queue.
 	q.Remove(cmd)
@@ -261,25 +300,48 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) error {
 
 	// All replacements have been provisioned.
 	// All we need to do now is get a successful delete call for each node claim,
-	// then the termination controller will handle the eventual deletion of the nodes.
-	var multiErr error
-	for i := range cmd.candidates {
-		candidate := cmd.candidates[i]
-		q.recorder.Publish(disruptionevents.Terminating(candidate.Node, candidate.NodeClaim, cmd.Reason())...)
-		if err := q.kubeClient.Delete(ctx, candidate.NodeClaim); err != nil {
-			multiErr = multierr.Append(multiErr, client.IgnoreNotFound(err))
-		} else {
-			metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
-				metrics.ReasonLabel:       cmd.method,
-				metrics.NodePoolLabel:     cmd.candidates[i].NodeClaim.Labels[v1beta1.NodePoolLabelKey],
-				metrics.CapacityTypeLabel: cmd.candidates[i].NodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
-			}).Inc()
-		}
-	}
+	// then the termination controller will handle the eventual deletion of the nodes. The candidates are terminated
+	// concurrently, so that the termination controller drains them at the same time rather than one after another.
+	terminateErrs := make([]error, len(cmd.candidates))
+	workqueue.ParallelizeUntil(ctx, maxConcurrentTerminations, len(cmd.candidates), func(i int) {
+		terminateErrs[i] = q.terminate(ctx, cmd, cmd.candidates[i])
+	})
 	// If there were any deletion failures, we should requeue.
 	// In the case where we requeue, but the timeout for the command is reached, we'll mark this as a failure.
-	if multiErr != nil {
-		return fmt.Errorf("terminating nodeclaims, %w", multiErr)
+	if err := multierr.Combine(terminateErrs...); err != nil {
+		return fmt.Errorf("terminating nodeclaims, %w", err)
+	}
+	return nil
+}
+
+// terminate deletes the candidate's nodeclaim, which the termination controller drains and terminates
+func (q *Queue) terminate(ctx context.Context, cmd *Command, candidate *state.StateNode) error {
+	q.recorder.Publish(disruptionevents.Terminating(candidate.Node, candidate.NodeClaim, cmd.Reason())...)
+	// Record the trace on the nodeclaim so that the termination controller's cloudprovider delete continues it
+	if err := q.injectTraceContext(ctx, candidate.NodeClaim); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := q.kubeClient.Delete(ctx, candidate.NodeClaim); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if len(cmd.Replacements) > 0 {
+		q.recorder.Publish(disruptionevents.Replaced(candidate.Node, candidate.NodeClaim, lo.Map(cmd.Replacements, func(r Replacement, _ int) string { return r.name }))...)
+	}
+	metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
+		metrics.ReasonLabel:       cmd.method,
+		metrics.NodePoolLabel:     candidate.NodeClaim.Labels[v1beta1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: candidate.NodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
+	}).Inc()
+	return nil
+}
+
+func (q *Queue) injectTraceContext(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	stored := nodeClaim.DeepCopy()
+	if !tracing.Inject(ctx, nodeClaim) {
+		return nil
+	}
+	if err := q.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return fmt.Errorf("patching nodeclaim trace context, %w", err)
 	}
 	return nil
 }
@@ -296,13 +358,17 @@ func (q *Queue) Add(cmd *Command) error {
 	}
 
 	cmd.timeAdded = q.clock.Now()
+	q.add(cmd)
+	return nil
+}
+
+func (q *Queue) add(cmd *Command) {
 	q.mu.Lock()
 	for _, candidate := range cmd.candidates {
 		q.providerIDToCommand[candidate.ProviderID()] = cmd
 	}
 	q.mu.Unlock()
 	q.RateLimitingInterface.Add(cmd)
-	return nil
 }
 
 // HasAny checks to see if the candidate is part of an currently executing command.
@@ -318,6 +384,18 @@ func (q *Queue) HasAny(ids ...string) bool {
 	return ok
 }
 
+// DisruptionReason returns the reason that the candidate with the given provider ID is being disrupted for, and
+// whether it's part of a command in the queue
+func (q *Queue) DisruptionReason(providerID string) (v1beta1.DisruptionReason, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	cmd, ok := q.providerIDToCommand[providerID]
+	if !ok {
+		return "", false
+	}
+	return cmd.disruptionReason, true
+}
+
 // Remove fully clears the queue of all references of a hash/command
 func (q *Queue) Remove(cmd *Command) {
 	// mark this item as done processing. This is necessary so that the RLI is able to add the item back in.
@@ -338,6 +416,10 @@ func (q *Queue) Reset() {
 	defer q.mu.Unlock()
 	q.RateLimitingInterface = &controllertest.Queue{Interface: workqueue.New()}
 	q.providerIDToCommand = map[string]*Command{}
+	q.restoreMu.Lock()
+	defer q.restoreMu.Unlock()
+	q.restored = false
+	q.persisted = ""
 }
 
 func (q *Queue) IsEmpty() bool {
@@ -18,6 +18,7 @@ package orchestration_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
@@ -81,7 +83,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
 	queue = orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
 })
 
@@ -102,6 +104,7 @@ var _ = BeforeEach(func() {
 
 var _ = AfterEach(func() {
 	ExpectCleanedUp(ctx, env.Client)
+	Expect(client.IgnoreNotFound(env.Client.Delete(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(ctx)}}))).To(Succeed())
 })
 
 var _ = Describe("Queue", func() {
@@ -168,7 +171,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 
 			stateNode := ExpectStateNodeExists(cluster, node1)
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(BeNil())
 
 			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
 			Expect(node1.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
@@ -186,7 +189,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(BeNil())
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 		})
 		It("should untaint nodes when a command times out", func() {
@@ -194,7 +197,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(BeNil())
 
 			// Step the clock to trigger the timeout.
 			fakeClock.Step(11 * time.Minute)
@@ -208,7 +211,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type")
+			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type")
 			Expect(queue.Add(cmd)).To(BeNil())
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 
@@ -245,7 +248,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type")
+			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type")
 			Expect(queue.Add(cmd)).To(BeNil())
 
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
@@ -274,7 +277,7 @@ var _ = Describe("Queue", func() {
 			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
-			cmd := orchestration.NewCommand([]string{}, []*state.StateNode{stateNode}, "", "test-method", "fake-type")
+			cmd := orchestration.NewCommand([]string{}, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type")
 			Expect(queue.Add(cmd)).To(BeNil())
 
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
@@ -301,9 +304,9 @@ var _ = Describe("Queue", func() {
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 			stateNode2 := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim2)
 
-			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type")
+			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type")
 			Expect(queue.Add(cmd)).To(BeNil())
-			cmd2 := orchestration.NewCommand(replacements2, []*state.StateNode{stateNode2}, "", "test-method", "fake-type")
+			cmd2 := orchestration.NewCommand(replacements2, []*state.StateNode{stateNode2}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type")
 			Expect(queue.Add(cmd2)).To(BeNil())
 
 			// Reconcile the first command and expect nothing to be initialized
@@ -344,4 +347,93 @@ var _ = Describe("Queue", func() {
 		})
 
 	})
+	Context("Persistence", func() {
+		It("should persist the commands in the queue", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Restore(ctx)).To(Succeed())
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-id", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(BeNil())
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			persisted := ExpectPersistedCommands()
+			Expect(persisted).To(HaveLen(1))
+			Expect(persisted[0]["id"]).To(Equal("test-id"))
+			Expect(persisted[0]["reason"]).To(Equal(string(v1beta1.DisruptionReasonDrifted)))
+			Expect(persisted[0]["candidates"]).To(ConsistOf(nodeClaim1.Status.ProviderID))
+		})
+		It("should restore persisted commands and finish them", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1, replacementNode}, []*v1beta1.NodeClaim{nodeClaim1, replacementNodeClaim})
+			ExpectPersistCommands(`[{"id":"test-id","method":"test-method","reason":"Drifted","candidates":["` + nodeClaim1.Status.ProviderID + `"],"replacements":[{"name":"` + ncName + `"}],"timeAdded":"` + fakeClock.Now().Format(time.RFC3339) + `"}]`)
+
+			Expect(queue.Restore(ctx)).To(Succeed())
+			Expect(queue.HasAny(nodeClaim1.Status.ProviderID)).To(BeTrue())
+			reason, ok := queue.DisruptionReason(nodeClaim1.Status.ProviderID)
+			Expect(ok).To(BeTrue())
+			Expect(reason).To(Equal(v1beta1.DisruptionReasonDrifted))
+			Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1).MarkedForDeletion()).To(BeTrue())
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
+		It("should not restore commands whose candidates are gone", func() {
+			ExpectPersistCommands(`[{"id":"test-id","method":"test-method","candidates":["` + nodeClaim1.Status.ProviderID + `"],"timeAdded":"` + fakeClock.Now().Format(time.RFC3339) + `"}]`)
+			Expect(queue.Restore(ctx)).To(Succeed())
+			Expect(queue.IsEmpty()).To(BeTrue())
+		})
+		It("should ignore persisted commands that can't be read", func() {
+			ExpectPersistCommands("{")
+			Expect(queue.Restore(ctx)).To(Succeed())
+			Expect(queue.IsEmpty()).To(BeTrue())
+		})
+		It("should persist and restore the commands of each shard separately", func() {
+			shardACtx := options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr("team=a")}))
+			shardBCtx := options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr("team=b")}))
+			Expect(orchestration.ConfigMapName(shardACtx)).ToNot(Equal(orchestration.ConfigMapName(shardBCtx)))
+			DeferCleanup(func() {
+				for _, shardCtx := range []context.Context{shardACtx, shardBCtx} {
+					Expect(client.IgnoreNotFound(env.Client.Delete(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(shardCtx)}}))).To(Succeed())
+				}
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+			queueB := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+
+			Expect(queue.Restore(shardACtx)).To(Succeed())
+			Expect(queueB.Restore(shardBCtx)).To(Succeed())
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-id", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(BeNil())
+			ExpectReconcileSucceeded(shardACtx, queue, types.NamespacedName{})
+			ExpectReconcileSucceeded(shardBCtx, queueB, types.NamespacedName{})
+
+			cm := ExpectExists(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(shardACtx)}})
+			Expect(cm.Data["commands"]).To(ContainSubstring("test-id"))
+			cm = ExpectExists(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(shardBCtx)}})
+			Expect(cm.Data["commands"]).ToNot(ContainSubstring("test-id"))
+
+			// A new leader of shard B doesn't pick up shard A's commands
+			restartedB := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restartedB.Restore(shardBCtx)).To(Succeed())
+			Expect(restartedB.IsEmpty()).To(BeTrue())
+		})
+	})
 })
+
+func ExpectPersistCommands(data string) {
+	GinkgoHelper()
+	ExpectApplied(ctx, env.Client, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(ctx)},
+		Data:       map[string]string{"commands": data},
+	})
+}
+
+func ExpectPersistedCommands() []map[string]interface{} {
+	GinkgoHelper()
+	cm := ExpectExists(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: orchestration.ConfigMapName(ctx)}})
+	var commands []map[string]interface{}
+	Expect(json.Unmarshal([]byte(cm.Data["commands"]), &commands)).To(Succeed())
+	return commands
+}
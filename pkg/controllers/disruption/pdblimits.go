@@ -72,20 +72,12 @@ func (s *PDBLimits) CanEvictPods(pods []*v1.Pod) (client.ObjectKey, bool) {
 		for _, pdb := range s.pdbs {
 			if pdb.key.Namespace == pod.ObjectMeta.Namespace {
 				if pdb.selector.Matches(labels.Set(pod.Labels)) {
-
 					// if the PDB policy is set to allow evicting unhealthy pods, then it won't stop us from
-					// evicting unhealthy pods
-					ignorePod := false
-					if pdb.canAlwaysEvictUnhealthyPods {
-						for _, c := range pod.Status.Conditions {
-							if c.Type == v1.PodReady && c.Status == v1.ConditionFalse {
-								ignorePod = true
-								continue
-							}
-						}
+					// evicting unhealthy pods, such as pods that are crashlooping
+					if pdb.canAlwaysEvictUnhealthyPods && !podutil.IsHealthy(pod) {
+						continue
 					}
-
-					if !ignorePod && pdb.disruptionsAllowed == 0 {
+					if pdb.disruptionsAllowed == 0 {
 						return pdb.key, false
 					}
 				}
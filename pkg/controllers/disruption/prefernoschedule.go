@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"knative.dev/pkg/logging"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// awaitPreferNoSchedule taints the command's candidates with the karpenter.sh/disruption:PreferNoSchedule taint and
+// returns whether they've all been tainted for the prefer no schedule window, so that the kube-scheduler has stopped
+// placing new pods on them that would just be evicted again. Candidates are tainted the first time that they're part
+// of a command, and the command is executed once it's computed again after the window has passed.
+func (c *Controller) awaitPreferNoSchedule(ctx context.Context, cmd Command) (bool, error) {
+	window := options.FromContext(ctx).DisruptionPreferNoScheduleWindow
+	if window == 0 {
+		return true, nil
+	}
+	var untainted []*state.StateNode
+	ready := true
+	for _, cd := range cmd.candidates {
+		taintedAt, ok := c.preferNoScheduleTaintedAt[cd.ProviderID()]
+		if !ok {
+			c.preferNoScheduleTaintedAt[cd.ProviderID()] = c.clock.Now()
+			untainted = append(untainted, cd.StateNode)
+			ready = false
+			continue
+		}
+		if c.clock.Since(taintedAt) < window {
+			ready = false
+		}
+	}
+	if len(untainted) > 0 {
+		logging.FromContext(ctx).With("nodes", lo.Map(untainted, func(n *state.StateNode, _ int) string { return n.Name() }), "window", window).
+			Debugf("tainting nodes with %s:%s before disrupting them", v1beta1.DisruptionTaintKey, v1beta1.DisruptionPreferNoScheduleTaint.Effect)
+		if err := state.RequirePreferNoScheduleTaint(ctx, c.kubeClient, true, untainted...); err != nil {
+			for _, n := range untainted {
+				delete(c.preferNoScheduleTaintedAt, n.ProviderID())
+			}
+			return false, fmt.Errorf("tainting nodes, %w", err)
+		}
+	}
+	return ready, nil
+}
+
+// cleanupPreferNoScheduleTaints removes the karpenter.sh/disruption:PreferNoSchedule taint from nodes that weren't
+// disrupted within twice the prefer no schedule window after they were tainted, e.g. because the consolidation that
+// they were tainted for is no longer worthwhile. Short windows are extended to a minute past the window, so that the
+// command has a few disruption loops to be computed again. Nodes that were tainted before Karpenter restarted are
+// tracked from when they're first seen.
+func (c *Controller) cleanupPreferNoScheduleTaints(ctx context.Context) error {
+	window := options.FromContext(ctx).DisruptionPreferNoScheduleWindow
+	nodes := c.cluster.Nodes()
+	providerIDs := lo.SliceToMap(nodes, func(n *state.StateNode) (string, struct{}) { return n.ProviderID(), struct{}{} })
+	for providerID := range c.preferNoScheduleTaintedAt {
+		if _, ok := providerIDs[providerID]; !ok {
+			delete(c.preferNoScheduleTaintedAt, providerID)
+		}
+	}
+	var stale []*state.StateNode
+	for _, n := range nodes {
+		if n.Node != nil && lo.Contains(n.Node.Spec.Taints, v1beta1.DisruptionPreferNoScheduleTaint) {
+			if _, ok := c.preferNoScheduleTaintedAt[n.ProviderID()]; !ok {
+				c.preferNoScheduleTaintedAt[n.ProviderID()] = c.clock.Now()
+			}
+		}
+		taintedAt, ok := c.preferNoScheduleTaintedAt[n.ProviderID()]
+		if !ok || n.MarkedForDeletion() || c.queue.HasAny(n.ProviderID()) {
+			continue
+		}
+		if window == 0 || c.clock.Since(taintedAt) > window+max(window, time.Minute) {
+			stale = append(stale, n)
+			delete(c.preferNoScheduleTaintedAt, n.ProviderID())
+		}
+	}
+	return state.RequirePreferNoScheduleTaint(ctx, c.kubeClient, false, stale...)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"sort"
+
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+// Requested is a subreconciler that replaces the candidates that an operator asked to disrupt through the
+// karpenter.sh/disrupt annotation. Unlike deleting the node, this respects the nodepool's disruption budgets and
+// launches any replacements before the candidate is drained.
+type Requested struct {
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
+}
+
+func NewRequested(kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Requested {
+	return &Requested{
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
+	}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (r *Requested) ShouldDisrupt(_ context.Context, c *Candidate) bool {
+	// The annotation is checked on both objects, since the state node only surfaces the Node's annotations once it's
+	// registered
+	return c.NodeClaim.Annotations[v1beta1.DisruptAnnotationKey] == v1beta1.DisruptAnnotationValueNow ||
+		(c.Node != nil && c.Node.Annotations[v1beta1.DisruptAnnotationKey] == v1beta1.DisruptAnnotationValueNow)
+}
+
+// ComputeCommand generates a disruption command given candidates
+func (r *Requested) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+	sort.Slice(candidates, func(i int, j int) bool {
+		return candidates[i].NodeClaim.CreationTimestamp.Before(&candidates[j].NodeClaim.CreationTimestamp)
+	})
+	cmd, results, err := computeReplacementCommand(ctx, r, r.kubeClient, r.cluster, r.provisioner, r.recorder, disruptionBudgetMapping, candidates)
+	for _, candidate := range cmd.candidates {
+		logging.FromContext(ctx).With("nodeclaim", candidate.NodeClaim.Name).Infof("triggering disruption of node requested through the %q annotation", v1beta1.DisruptAnnotationKey)
+	}
+	return cmd, results, err
+}
+
+func (r *Requested) Type() string {
+	return metrics.RequestedReason
+}
+
+func (r *Requested) ConsolidationType() string {
+	return ""
+}
+
+func (r *Requested) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonRequested
+}
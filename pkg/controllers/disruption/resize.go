@@ -0,0 +1,185 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+const (
+	// ResizeUnderutilizedThreshold is the utilization below which a node is considered under-utilized
+	ResizeUnderutilizedThreshold = 0.3
+	// ResizeOverutilizedThreshold is the utilization above which a node is considered over-utilized
+	ResizeOverutilizedThreshold = 0.9
+	// ResizeAfter is how long a node has to stay under or over-utilized before it's resized
+	ResizeAfter = 30 * time.Minute
+)
+
+// Resize is a subreconciler that replaces nodes that have been persistently under or over-utilized with a right-sized
+// instance type. The replacement is launched and initialized before the candidate is drained.
+type Resize struct {
+	consolidation
+
+	// outOfBandSince tracks when each node (by provider id) was first seen outside the utilization band
+	outOfBandSince map[string]time.Time
+}
+
+func NewResize(consolidation consolidation) *Resize {
+	return &Resize{consolidation: consolidation, outOfBandSince: map[string]time.Time{}}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (r *Resize) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
+	if !options.FromContext(ctx).FeatureGates.NodeResize {
+		return false
+	}
+	// Resizing replaces nodes like consolidation does, so it honors the same annotation and consolidation policy
+	if !r.consolidation.ShouldDisrupt(ctx, c) {
+		delete(r.outOfBandSince, c.ProviderID())
+		return false
+	}
+	if inUtilizationBand(utilization(c.PodRequests(), c.Allocatable())) {
+		delete(r.outOfBandSince, c.ProviderID())
+		return false
+	}
+	since, ok := r.outOfBandSince[c.ProviderID()]
+	if !ok {
+		r.outOfBandSince[c.ProviderID()] = r.clock.Now()
+		return false
+	}
+	return r.clock.Since(since) >= ResizeAfter
+}
+
+// ComputeCommand generates a disruption command given candidates
+func (r *Resize) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, pscheduling.Results, error) {
+	r.forgetRemovedNodes()
+	// Resize the nodes that have been out of the utilization band the longest first
+	sort.Slice(candidates, func(i int, j int) bool {
+		return r.outOfBandSince[candidates[i].ProviderID()].Before(r.outOfBandSince[candidates[j].ProviderID()])
+	})
+	EligibleNodesGauge.With(map[string]string{
+		methodLabel:            r.Type(),
+		consolidationTypeLabel: r.ConsolidationType(),
+	}).Set(float64(len(candidates)))
+
+	for _, candidate := range candidates {
+		// If the disruption budget doesn't allow this candidate to be disrupted,
+		// continue to the next candidate. We don't need to decrement any budget
+		// counter since resize commands can only have one candidate.
+		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+			continue
+		}
+		results, err := SimulateScheduling(ctx, r.kubeClient, r.cluster, r.provisioner, candidate)
+		if err != nil {
+			// if a candidate is now deleting, just retry
+			if errors.Is(err, errCandidateDeleting) {
+				continue
+			}
+			return Command{}, pscheduling.Results{}, err
+		}
+		if !results.AllNonPendingPodsScheduled() {
+			r.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
+			continue
+		}
+		// Resizing only makes sense when the candidate's pods move to a single new node. If they fit on existing
+		// capacity then consolidation will remove the node instead.
+		if len(results.NewNodeClaims) != 1 {
+			continue
+		}
+		replacement := results.NewNodeClaims[0]
+		replacement.InstanceTypeOptions = rightSizedInstanceTypes(replacement, candidate)
+		if len(replacement.InstanceTypeOptions) == 0 {
+			r.recorder.Publish(disruptionevents.Unconsolidatable(candidate.Node, candidate.NodeClaim, "Can't resize, no right-sized instance types are available")...)
+			continue
+		}
+		// Keep the candidate's capacity type so that resizing a node doesn't change how it's purchased
+		if ct := replacement.Requirements.Get(v1beta1.CapacityTypeLabelKey); ct.Has(candidate.capacityType) {
+			replacement.Requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, candidate.capacityType))
+		}
+		return Command{
+			candidates:   []*Candidate{candidate},
+			reason:       r.Reason(),
+			replacements: results.NewNodeClaims,
+		}, results, nil
+	}
+	return Command{}, pscheduling.Results{}, nil
+}
+
+// forgetRemovedNodes stops tracking the utilization of nodes that are no longer in the cluster
+func (r *Resize) forgetRemovedNodes() {
+	providerIDs := sets.New(lo.Map(r.cluster.Nodes(), func(n *state.StateNode, _ int) string { return n.ProviderID() })...)
+	for providerID := range r.outOfBandSince {
+		if !providerIDs.Has(providerID) {
+			delete(r.outOfBandSince, providerID)
+		}
+	}
+}
+
+func (r *Resize) Type() string {
+	return metrics.ResizeReason
+}
+
+func (r *Resize) ConsolidationType() string {
+	return ""
+}
+
+func (r *Resize) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonResized
+}
+
+// rightSizedInstanceTypes returns the replacement's instance types that would be within the utilization band with the
+// replacement's requests, excluding the candidate's own instance type
+func rightSizedInstanceTypes(replacement *pscheduling.NodeClaim, candidate *Candidate) []*cloudprovider.InstanceType {
+	return lo.Filter(replacement.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) bool {
+		return it.Name != candidate.instanceType.Name && inUtilizationBand(utilization(replacement.Spec.Resources.Requests, it.Allocatable()))
+	})
+}
+
+// utilization returns the highest fraction of allocatable cpu or memory that is requested
+func utilization(requests, allocatable v1.ResourceList) float64 {
+	var u float64
+	for _, resourceName := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		a, ok := allocatable[resourceName]
+		if !ok || a.IsZero() {
+			continue
+		}
+		r := requests[resourceName]
+		u = math.Max(u, r.AsApproximateFloat64()/a.AsApproximateFloat64())
+	}
+	return u
+}
+
+func inUtilizationBand(u float64) bool {
+	return u >= ResizeUnderutilizedThreshold && u <= ResizeOverutilizedThreshold
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// simulationCache records the sets of candidates that consolidation simulations couldn't consolidate at a revision of
+// the cluster state. Until the revision changes, neither the candidates' pods nor the nodes that they could reschedule
+// to have changed, so simulating them again would reach the same verdict.
+type simulationCache struct {
+	mu       sync.Mutex
+	revision uint64
+	noOps    sets.Set[string]
+}
+
+func newSimulationCache() *simulationCache {
+	return &simulationCache{noOps: sets.New[string]()}
+}
+
+// isNoOp returns true if the candidates couldn't be consolidated at the revision
+func (s *simulationCache) isNoOp(revision uint64, candidates []*Candidate) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revision == revision && s.noOps.Has(simulationKey(candidates))
+}
+
+// markNoOp records that the candidates couldn't be consolidated at the revision. The verdicts of older revisions are
+// discarded once a newer revision is recorded.
+func (s *simulationCache) markNoOp(revision uint64, candidates []*Candidate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if revision < s.revision {
+		return
+	}
+	if revision > s.revision {
+		s.revision = revision
+		s.noOps = sets.New[string]()
+	}
+	s.noOps.Insert(simulationKey(candidates))
+}
+
+// workloadZoneCache records the zones that the active pods of each workload are scheduled in at a revision of the
+// cluster state, so that the cluster's pods are listed once per revision rather than once per simulation
+type workloadZoneCache struct {
+	mu       sync.Mutex
+	revision uint64
+	zones    map[types.UID]sets.Set[string]
+}
+
+// get returns the zones of each workload at the revision, computing them if they aren't recorded for it yet
+func (w *workloadZoneCache) get(revision uint64, compute func() (map[types.UID]sets.Set[string], error)) (map[types.UID]sets.Set[string], error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.zones != nil && w.revision == revision {
+		return w.zones, nil
+	}
+	zones, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	w.revision, w.zones = revision, zones
+	return zones, nil
+}
+
+func simulationKey(candidates []*Candidate) string {
+	providerIDs := lo.Map(candidates, func(c *Candidate, _ int) string { return c.ProviderID() })
+	sort.Strings(providerIDs)
+	return strings.Join(providerIDs, ",")
+}
@@ -23,6 +23,7 @@ import (
 
 	"knative.dev/pkg/logging"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 )
@@ -86,7 +87,7 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruption
 			logging.FromContext(ctx).Debugf("abandoning single-node consolidation attempt due to pod churn, command is no longer valid, %s", cmd)
 			return Command{}, scheduling.Results{}, nil
 		}
-		return cmd, results, nil
+		return s.withEstimatedSavings(ctx, cmd), results, nil
 	}
 	if !constrainedByBudgets {
 		// if there are no candidates because of a budget, don't mark
@@ -104,3 +105,7 @@ func (s *SingleNodeConsolidation) Type() string {
 func (s *SingleNodeConsolidation) ConsolidationType() string {
 	return "single"
 }
+
+func (s *SingleNodeConsolidation) Reason() v1beta1.DisruptionReason {
+	return v1beta1.DisruptionReasonUnderutilized
+}
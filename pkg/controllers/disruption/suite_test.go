@@ -19,6 +19,7 @@ package disruption_test
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"testing"
@@ -89,7 +90,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
 	queue = orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
 	disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue)
 })
@@ -481,6 +482,59 @@ var _ = Describe("Disruption Taints", func() {
 		node = ExpectNodeExists(ctx, env.Client, node.Name)
 		Expect(node.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
 	})
+	Context("PreferNoSchedule Window", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionPreferNoScheduleWindow: lo.ToPtr(time.Minute)}))
+			// Disable consolidation so that only the requested disruption acts on the empty node
+			nodePool.Spec.Disruption.ConsolidationPolicy = v1beta1.ConsolidationPolicyWhenEmpty
+			nodePool.Spec.Disruption.ConsolidateAfter = &v1beta1.NillableDuration{Duration: nil}
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{Nodes: "100%"}}
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1beta1.DisruptAnnotationKey: v1beta1.DisruptAnnotationValueNow})
+		})
+		It("should taint nodes with PreferNoSchedule for the window before disrupting them", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionPreferNoScheduleTaint))
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+			Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+
+			// The node keeps the PreferNoSchedule taint until the window has passed
+			fakeClock.Step(30 * time.Second)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionPreferNoScheduleTaint))
+			Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+
+			fakeClock.Step(30 * time.Second)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionPreferNoScheduleTaint))
+			Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeTrue())
+		})
+		It("should remove the PreferNoSchedule taint from nodes that aren't disrupted after the window", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionPreferNoScheduleTaint))
+
+			// The disruption is no longer requested
+			delete(node.Annotations, v1beta1.DisruptAnnotationKey)
+			ExpectApplied(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			fakeClock.Step(2*time.Minute + time.Second)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionPreferNoScheduleTaint))
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		})
+	})
 })
 
 var _ = Describe("BuildDisruptionBudgetMapping", func() {
@@ -523,7 +577,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		unmanaged := test.Node()
 		ExpectApplied(ctx, env.Client, unmanaged)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{unmanaged}, []*v1beta1.NodeClaim{})
-		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
 		Expect(err).To(Succeed())
 		// This should not bring in the unmanaged node.
 		Expect(budgets[nodePool.Name]).To(Equal(10))
@@ -552,7 +606,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
 		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaim))
 
-		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
 		Expect(err).To(Succeed())
 		// This should not bring in the uninitialized node.
 		Expect(budgets[nodePool.Name]).To(Equal(10))
@@ -572,7 +626,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(i))
 		}
 
-		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
 		Expect(err).To(Succeed())
 		Expect(budgets[nodePool.Name]).To(Equal(0))
 	})
@@ -594,7 +648,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(i))
 		}
 
-		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
 		Expect(err).To(Succeed())
 		Expect(budgets[nodePool.Name]).To(Equal(8))
 	})
@@ -613,10 +667,76 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(i))
 		}
 
-		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder)
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
 		Expect(err).To(Succeed())
 		Expect(budgets[nodePool.Name]).To(Equal(8))
 	})
+	It("should only consider budgets that apply to the disruption reason", func() {
+		nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{
+			{Nodes: "100%", Reasons: []v1beta1.DisruptionReason{v1beta1.DisruptionReasonExpired}},
+			{Nodes: "1", Reasons: []v1beta1.DisruptionReason{v1beta1.DisruptionReasonUnderutilized}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonExpired)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(10))
+
+		budgets, err = disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(1))
+
+		// Budgets that don't apply to a reason don't restrict it
+		budgets, err = disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(math.MaxInt32))
+	})
+	It("should only count nodes that are being disrupted against the budgets that apply to their reason", func() {
+		nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{
+			{Nodes: "100%"},
+			{Nodes: "2", Reasons: []v1beta1.DisruptionReason{v1beta1.DisruptionReasonUnderutilized}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		// Expire two of the nodes
+		stateNodes := lo.Filter(cluster.Nodes(), func(n *state.StateNode, _ int) bool {
+			return n.ProviderID() == nodeClaims[0].Status.ProviderID || n.ProviderID() == nodeClaims[1].Status.ProviderID
+		})
+		Expect(stateNodes).To(HaveLen(2))
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID, nodeClaims[1].Status.ProviderID)
+		Expect(queue.Add(orchestration.NewCommand([]string{}, stateNodes, "", v1beta1.DisruptionReasonExpired, "expiration", ""))).To(Succeed())
+
+		// The expiring nodes don't count against the consolidation budget
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonUnderutilized)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(2))
+
+		budgets, err = disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, v1beta1.DisruptionReasonExpired)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(8))
+	})
+	It("should validate a command against the budgets of its disruption reason", func() {
+		nodePool.Spec.Disruption.ConsolidationPolicy = v1beta1.ConsolidationPolicyWhenUnderutilized
+		nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{Nodes: "0", Reasons: []v1beta1.DisruptionReason{v1beta1.DisruptionReasonUnderutilized}}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		nodeClaims[0].StatusConditions().MarkTrue(v1beta1.Drifted)
+		ExpectApplied(ctx, env.Client, nodeClaims[0])
+		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaims[0]))
+
+		drift := disruption.NewDrift(env.Client, cluster, prov, recorder)
+		candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, drift.ShouldDisrupt, queue)
+		Expect(err).To(Succeed())
+		Expect(candidates).To(HaveLen(1))
+		budgets, err := disruption.BuildDisruptionBudgets(ctx, cluster, fakeClock, env.Client, recorder, queue, drift.Reason())
+		Expect(err).To(Succeed())
+		cmd, _, err := drift.ComputeCommand(ctx, budgets, candidates...)
+		Expect(err).To(Succeed())
+		Expect(cmd.Action()).To(Equal(disruption.DeleteAction))
+
+		// The budget blocks consolidating underutilized nodes, but doesn't apply to drift
+		v := disruption.NewValidation(0, fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue)
+		Expect(v.IsValid(ctx, cmd)).To(BeTrue())
+	})
 })
 
 var _ = Describe("Pod Eviction Cost", func() {
@@ -672,6 +792,33 @@ var _ = Describe("Pod Eviction Cost", func() {
 		})
 		Expect(cost).To(BeNumerically("<", standardPodCost))
 	})
+	It("should add the disruption cost annotation to the disruptionCost", func() {
+		cost := disruption.GetPodEvictionCost(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1beta1.DisruptionCostAnnotationKey: "50",
+			}},
+		})
+		Expect(cost).To(BeNumerically("==", standardPodCost+50))
+	})
+	It("should not clamp the disruption cost annotation", func() {
+		cost := disruption.GetPodEvictionCost(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1.PodDeletionCost:                  "2147483647",
+				v1beta1.DisruptionCostAnnotationKey: "100",
+			}},
+		})
+		Expect(cost).To(BeNumerically(">", 100))
+	})
+	It("should ignore invalid disruption cost annotations", func() {
+		for _, value := range []string{"expensive", "-10", "NaN", "+Inf"} {
+			cost := disruption.GetPodEvictionCost(ctx, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					v1beta1.DisruptionCostAnnotationKey: value,
+				}},
+			})
+			Expect(cost).To(BeNumerically("==", standardPodCost))
+		}
+	})
 })
 
 var _ = Describe("Candidate Filtering", func() {
@@ -721,6 +868,60 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod))))
 		Expect(recorder.DetectedEvent(fmt.Sprintf(`Cannot disrupt Node: Pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod)))).To(BeTrue())
 	})
+	It("should not consider candidates that have pods that aren't safe to evict scheduled", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey: "false",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has "cluster-autoscaler.kubernetes.io/safe-to-evict: false" annotation`, client.ObjectKeyFromObject(pod))))
+		Expect(recorder.DetectedEvent(fmt.Sprintf(`Cannot disrupt Node: Pod %q has "cluster-autoscaler.kubernetes.io/safe-to-evict: false" annotation`, client.ObjectKeyFromObject(pod)))).To(BeTrue())
+	})
+	It("should consider candidates that have pods that are safe to evict scheduled", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey: "true",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).ToNot(HaveOccurred())
+	})
 	It("should not consider candidates that have do-not-disrupt mirror pods scheduled", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -798,6 +999,96 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod))))
 		Expect(recorder.DetectedEvent(fmt.Sprintf(`Cannot disrupt Node: Pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod)))).To(BeTrue())
 	})
+	It("should not consider candidates that have pods in protected namespaces scheduled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ProtectedPodNamespaces: lo.ToPtr("kube-system,default")}))
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q is protected by the protected pod namespaces and selector`, client.ObjectKeyFromObject(pod))))
+		Expect(recorder.DetectedEvent(fmt.Sprintf(`Cannot disrupt Node: Pod %q is protected by the protected pod namespaces and selector`, client.ObjectKeyFromObject(pod)))).To(BeTrue())
+	})
+	It("should only consider pods in protected namespaces protected if they match the protected pod selector", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			ProtectedPodNamespaces: lo.ToPtr("default"),
+			ProtectedPodSelector:   lo.ToPtr("app=critical"),
+		}))
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Labels: map[string]string{"app": "web"}}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).ToNot(HaveOccurred())
+
+		critical := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Labels: map[string]string{"app": "critical"}}})
+		ExpectApplied(ctx, env.Client, critical)
+		ExpectManualBinding(ctx, env.Client, critical, node)
+		_, err = disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q is protected by the protected pod namespaces and selector`, client.ObjectKeyFromObject(critical))))
+	})
+	It("should consider candidates that only have protected daemonset pods scheduled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ProtectedPodNamespaces: lo.ToPtr("default")}))
+		daemonSet := test.DaemonSet()
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, daemonSet)
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "apps/v1",
+						Kind:       "DaemonSet",
+						Name:       daemonSet.Name,
+						UID:        daemonSet.UID,
+						Controller: lo.ToPtr(true),
+					},
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).ToNot(HaveOccurred())
+	})
 	It("should consider candidates that have do-not-disrupt terminating pods", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -889,6 +1180,29 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(err.Error()).To(Equal(`disruption is blocked through the "karpenter.sh/do-not-disrupt" annotation`))
 		Expect(recorder.DetectedEvent(`Cannot disrupt Node: Disruption is blocked with the "karpenter.sh/do-not-disrupt" annotation`)).To(BeTrue())
 	})
+	It("should not consider candidates that have scale-down-disabled on nodes", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1beta1.ClusterAutoscalerScaleDownDisabledAnnotationKey: "true",
+				},
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`disruption is blocked through the "cluster-autoscaler.kubernetes.io/scale-down-disabled" annotation`))
+		Expect(recorder.DetectedEvent(`Cannot disrupt Node: Disruption is blocked with the "cluster-autoscaler.kubernetes.io/scale-down-disabled" annotation`)).To(BeTrue())
+	})
 	It("should not consider candidates that have fully blocking PDBs", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1360,7 +1674,7 @@ var _ = Describe("Candidate Filtering", func() {
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
 
 		Expect(cluster.Nodes()).To(HaveLen(1))
-		Expect(queue.Add(orchestration.NewCommand([]string{}, []*state.StateNode{cluster.Nodes()[0]}, "", "test-method", "fake-type"))).To(Succeed())
+		Expect(queue.Add(orchestration.NewCommand([]string{}, []*state.StateNode{cluster.Nodes()[0]}, "", v1beta1.DisruptionReasonDrifted, "test-method", "fake-type"))).To(Succeed())
 
 		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue)
 		Expect(err).To(HaveOccurred())
@@ -20,9 +20,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,6 +39,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
 type Method interface {
@@ -43,6 +47,7 @@ type Method interface {
 	ComputeCommand(context.Context, map[string]int, ...*Candidate) (Command, scheduling.Results, error)
 	Type() string
 	ConsolidationType() string
+	Reason() v1beta1.DisruptionReason
 }
 
 type CandidateFilter func(context.Context, *Candidate) bool
@@ -82,6 +87,10 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("Disruption is blocked with the %q annotation", v1beta1.DoNotDisruptAnnotationKey))...)
 		return nil, fmt.Errorf("disruption is blocked through the %q annotation", v1beta1.DoNotDisruptAnnotationKey)
 	}
+	if node.Annotations()[v1beta1.ClusterAutoscalerScaleDownDisabledAnnotationKey] == "true" {
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("Disruption is blocked with the %q annotation", v1beta1.ClusterAutoscalerScaleDownDisabledAnnotationKey))...)
+		return nil, fmt.Errorf("disruption is blocked through the %q annotation", v1beta1.ClusterAutoscalerScaleDownDisabledAnnotationKey)
+	}
 	// check whether the node has all the labels we need
 	for _, label := range []string{
 		v1beta1.CapacityTypeLabelKey,
@@ -126,9 +135,25 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 			recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf(`Pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(po)))...)
 			return nil, fmt.Errorf(`pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(po))
 		}
+		if !pod.IsSafeToEvict(po) {
+			recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf(`Pod %q has "cluster-autoscaler.kubernetes.io/safe-to-evict: false" annotation`, client.ObjectKeyFromObject(po)))...)
+			return nil, fmt.Errorf(`pod %q has "cluster-autoscaler.kubernetes.io/safe-to-evict: false" annotation`, client.ObjectKeyFromObject(po))
+		}
+	}
+	namespace, err := doNotDisruptNamespace(ctx, kubeClient, pods)
+	if err != nil {
+		return nil, fmt.Errorf("getting pod namespaces, %w", err)
+	}
+	if namespace != "" {
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf(`Namespace %q has "karpenter.sh/do-not-disrupt" annotation`, namespace))...)
+		return nil, fmt.Errorf(`namespace %q has "karpenter.sh/do-not-disrupt" annotation`, namespace)
+	}
+	if po := protectedPod(ctx, pods); po != nil {
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("Pod %q is protected by the protected pod namespaces and selector", client.ObjectKeyFromObject(po)))...)
+		return nil, fmt.Errorf("pod %q is protected by the protected pod namespaces and selector", client.ObjectKeyFromObject(po))
 	}
 	if pdbKey, ok := pdbs.CanEvictPods(pods); !ok {
-		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("PDB %q prevents pod evictions", pdbKey))...)
+		recorder.Publish(disruptionevents.BlockedByPDB(node.Node, node.NodeClaim, pdbKey.String())...)
 		return nil, fmt.Errorf("pdb %q prevents pod evictions", pdbKey)
 	}
 	return &Candidate{
@@ -143,6 +168,48 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 	}, nil
 }
 
+// doNotDisruptNamespace returns the name of the first namespace with the "karpenter.sh/do-not-disrupt" annotation that
+// contains an active pod, so that entire namespaces can be protected from disruption without annotating every pod
+func doNotDisruptNamespace(ctx context.Context, kubeClient client.Client, pods []*v1.Pod) (string, error) {
+	names := lo.Uniq(lo.FilterMap(pods, func(p *v1.Pod, _ int) (string, bool) { return p.Namespace, pod.IsActive(p) }))
+	for _, name := range names {
+		namespace := &v1.Namespace{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: name}, namespace); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		if namespace.Annotations[v1beta1.DoNotDisruptAnnotationKey] == "true" {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// protectedPod returns the first active pod that the protected pod namespaces and selector of the operator options
+// protect from voluntary disruption, or nil if none of the pods are protected
+func protectedPod(ctx context.Context, pods []*v1.Pod) *v1.Pod {
+	opts := options.FromContext(ctx)
+	if opts.ProtectedPodNamespaces == "" && opts.ProtectedPodSelector == "" {
+		return nil
+	}
+	namespaces := sets.New(lo.Compact(lo.Map(strings.Split(opts.ProtectedPodNamespaces, ","), func(n string, _ int) string { return strings.TrimSpace(n) }))...)
+	// The selector is validated when the options are parsed
+	selector, err := labels.Parse(opts.ProtectedPodSelector)
+	if err != nil {
+		return nil
+	}
+	for _, p := range pods {
+		// DaemonSet and mirror pods go away with their node rather than being rescheduled, and protecting them would
+		// block every node when a namespace like kube-system is protected
+		if !pod.IsActive(p) || pod.IsOwnedByDaemonSet(p) || pod.IsOwnedByNode(p) {
+			continue
+		}
+		if (namespaces.Len() == 0 || namespaces.Has(p.Namespace)) && selector.Matches(labels.Set(p.Labels)) {
+			return p
+		}
+	}
+	return nil
+}
+
 // lifetimeRemaining calculates the fraction of node lifetime remaining in the range [0.0, 1.0].  If the TTLSecondsUntilExpired
 // is non-zero, we use it to scale down the disruption costs of candidates that are going to expire.  Just after creation, the
 // disruption cost is highest, and it approaches zero as the node ages towards its expiration time.
@@ -160,6 +227,10 @@ func lifetimeRemaining(clock clock.Clock, nodePool *v1beta1.NodePool, node *v1.N
 type Command struct {
 	candidates   []*Candidate
 	replacements []*scheduling.NodeClaim
+	// reason is the reason that the candidates are disrupted for, which determines the budgets that they count against
+	reason v1beta1.DisruptionReason
+	// estimatedMonthlySavings is the expected monthly cost reduction from executing the command, if it's known
+	estimatedMonthlySavings float64
 }
 
 type Action string
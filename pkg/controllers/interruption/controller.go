@@ -0,0 +1,138 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/multierr"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+)
+
+// pollingPeriod is how often we ask the cloudprovider for new interruptions
+const pollingPeriod = 5 * time.Second
+
+// Controller consumes interruptions from cloud providers that implement cloudprovider.InterruptionProvider.
+// Interrupted nodes are cordoned and marked for deletion so that the provisioner launches replacement capacity
+// for their pods, and then their NodeClaims are deleted so that the termination controller drains them.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+	provisioner   *provisioning.Provisioner
+	recorder      events.Recorder
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster,
+	provisioner *provisioning.Provisioner, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		provisioner:   provisioner,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "interruption"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	interruptionProvider, ok := cloudprovider.As[cloudprovider.InterruptionProvider](c.cloudProvider)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	interruptions, err := interruptionProvider.GetInterruptions(ctx)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting interruptions, %w", err)
+	}
+	if len(interruptions) == 0 {
+		return reconcile.Result{RequeueAfter: pollingPeriod}, nil
+	}
+	nodes := map[string]*state.StateNode{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		nodes[n.ProviderID()] = n.DeepCopy()
+		return true
+	})
+	errs := make([]error, len(interruptions))
+	workqueue.ParallelizeUntil(ctx, 10, len(interruptions), func(i int) {
+		if err := c.handleInterruption(ctx, nodes[interruptions[i].ProviderID], interruptions[i]); err != nil {
+			errs[i] = err
+			return
+		}
+		errs[i] = interruptionProvider.AcknowledgeInterruption(ctx, interruptions[i])
+	})
+	// Pick up the pods from the interrupted nodes as soon as possible
+	c.provisioner.Trigger()
+	if err = multierr.Combine(errs...); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: operatorcontroller.Immediately}, nil
+}
+
+func (c *Controller) handleInterruption(ctx context.Context, node *state.StateNode, interruption *cloudprovider.Interruption) error {
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("provider-id", interruption.ProviderID, "kind", interruption.Kind))
+	ReceivedInterruptionsCounter.With(prometheus.Labels{
+		metrics.TypeLabel: string(interruption.Kind),
+	}).Inc()
+	// If we aren't tracking the instance or it's already being deleted, there's nothing to do
+	if node == nil || node.NodeClaim == nil || node.MarkedForDeletion() {
+		return nil
+	}
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("nodeclaim", node.NodeClaim.Name))
+	// Cordon the node so nothing new schedules to it. Marking the node for deletion causes the provisioner to
+	// consider its pods for scheduling, so replacement capacity is launched while the node is draining.
+	if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, true, node); err != nil {
+		return fmt.Errorf("tainting node, %w", err)
+	}
+	c.cluster.MarkForDeletion(node.ProviderID())
+	c.recorder.Publish(InterruptedEvent(node, interruption)...)
+	if err := c.kubeClient.Delete(ctx, node.NodeClaim); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			c.cluster.UnmarkForDeletion(node.ProviderID())
+			return fmt.Errorf("deleting nodeclaim, %w", err)
+		}
+		return nil
+	}
+	logging.FromContext(ctx).Infof("deleting interrupted nodeclaim")
+	metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
+		metrics.ReasonLabel:       "interruption",
+		metrics.NodePoolLabel:     node.NodeClaim.Labels[v1beta1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: node.NodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
+	}).Inc()
+	return nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
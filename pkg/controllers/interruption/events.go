@@ -0,0 +1,49 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func InterruptedEvent(node *state.StateNode, interruption *cloudprovider.Interruption) []events.Event {
+	evts := []events.Event{
+		{
+			InvolvedObject: node.NodeClaim,
+			Type:           v1.EventTypeWarning,
+			Reason:         "Interrupted",
+			Message:        fmt.Sprintf("Received %s interruption, replacing nodeclaim", interruption.Kind),
+			DedupeValues:   []string{string(node.NodeClaim.UID)},
+		},
+	}
+	if node.Node != nil {
+		evts = append(evts, events.Event{
+			InvolvedObject: node.Node,
+			Type:           v1.EventTypeWarning,
+			Reason:         "Interrupted",
+			Message:        fmt.Sprintf("Received %s interruption, replacing node", interruption.Kind),
+			DedupeValues:   []string{string(node.Node.UID)},
+		})
+	}
+	return evts
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/interruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cluster *state.Cluster
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+var prov *provisioning.Provisioner
+var nodeStateController controller.Controller
+var nodeClaimStateController controller.Controller
+var interruptionController *interruption.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interruption")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodeStateController = informer.NewNodeController(env.Client, cluster)
+	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
+	recorder = test.NewEventRecorder()
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
+	interruptionController = interruption.NewController(env.Client, cloudProvider, cluster, prov, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Interruption", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	var node *v1.Node
+
+	BeforeEach(func() {
+		cloudProvider.Reset()
+		cloudProvider.InstanceTypes = fake.InstanceTypesAssorted()
+		recorder.Reset()
+		cluster.Reset()
+		fakeClock.SetTime(time.Now())
+
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Finalizers: []string{v1beta1.TerminationFinalizer},
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   "default-instance-type",
+					v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot,
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{
+				Allocatable: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU:  resource.MustParse("4"),
+					v1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should cordon and delete an interrupted nodeclaim", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+		cloudProvider.Interruptions = []*cloudprovider.Interruption{
+			{ID: "1", ProviderID: nodeClaim.Status.ProviderID, Kind: cloudprovider.SpotInterruptionKind},
+		}
+
+		ExpectReconcileSucceeded(ctx, interruptionController, client.ObjectKey{})
+
+		node = ExpectNodeExists(ctx, env.Client, node.Name)
+		Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(recorder.Calls("Interrupted")).To(Equal(2))
+
+		Expect(cloudProvider.Interruptions).To(BeEmpty())
+		Expect(cloudProvider.AcknowledgedInterruptions).To(HaveLen(1))
+	})
+	It("should provision replacement capacity for the pods on an interrupted node", func() {
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", APIVersion: "appsv1", Name: "rs", UID: "1234567890"}},
+			},
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		// The pod is already running, so there's nothing to schedule before the interruption
+		results, err := prov.Schedule(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.NewNodeClaims).To(BeEmpty())
+
+		cloudProvider.Interruptions = []*cloudprovider.Interruption{
+			{ID: "1", ProviderID: nodeClaim.Status.ProviderID, Kind: cloudprovider.SpotInterruptionKind},
+		}
+		ExpectReconcileSucceeded(ctx, interruptionController, client.ObjectKey{})
+
+		results, err = prov.Schedule(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.NewNodeClaims).To(HaveLen(1))
+		Expect(results.NewNodeClaims[0].Pods).To(ConsistOf(pod))
+	})
+	It("should acknowledge interruptions for instances that aren't managed by Karpenter", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+		cloudProvider.Interruptions = []*cloudprovider.Interruption{
+			{ID: "1", ProviderID: "fake:///unknown", Kind: cloudprovider.SpotInterruptionKind},
+		}
+
+		ExpectReconcileSucceeded(ctx, interruptionController, client.ObjectKey{})
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+		Expect(cloudProvider.Interruptions).To(BeEmpty())
+		Expect(cloudProvider.AcknowledgedInterruptions).To(HaveLen(1))
+	})
+	It("should poll for interruptions when there aren't any", func() {
+		result := ExpectReconcileSucceeded(ctx, interruptionController, client.ObjectKey{})
+		Expect(result.RequeueAfter).To(BeNumerically(">", time.Second))
+	})
+})
@@ -69,10 +69,21 @@ var (
 			nodePoolNameLabel,
 		},
 	)
+	scaleUpStalledGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: nodePoolSubsystem,
+			Name:      "scale_up_stalled",
+			Help:      "Whether the nodepool hasn't been able to launch capacity for pending pods for longer than its scale-up deadline. Only reported for nodepools with a scale-up deadline. Labeled by nodepool name.",
+		},
+		[]string{
+			nodePoolNameLabel,
+		},
+	)
 )
 
 func init() {
-	crmetrics.Registry.MustRegister(limitGaugeVec, usageGaugeVec)
+	crmetrics.Registry.MustRegister(limitGaugeVec, usageGaugeVec, scaleUpStalledGaugeVec)
 }
 
 type Controller struct {
@@ -120,6 +131,13 @@ func buildMetrics(nodePool *v1beta1.NodePool) (res []*metrics.StoreMetric) {
 			})
 		}
 	}
+	if nodePool.Spec.ScaleUpDeadline != nil {
+		res = append(res, &metrics.StoreMetric{
+			GaugeVec: scaleUpStalledGaugeVec,
+			Labels:   prometheus.Labels{nodePoolNameLabel: nodePool.Name},
+			Value:    lo.Ternary(nodePool.StatusConditions().GetCondition(v1beta1.ScaleUpStalled).IsTrue(), 1.0, 0.0),
+		})
+	}
 	return res
 }
 
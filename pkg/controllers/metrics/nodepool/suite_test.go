@@ -20,11 +20,13 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "knative.dev/pkg/logging/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -110,6 +112,21 @@ var _ = Describe("Metrics", func() {
 			Expect(m.GetGauge().GetValue()).To(BeNumerically("~", v.AsApproximateFloat64()))
 		}
 	})
+	It("should report whether the nodepool's scale-up has stalled past its deadline", func() {
+		nodePool.Spec.ScaleUpDeadline = &metav1.Duration{Duration: 10 * time.Minute}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		m, found := FindMetricWithLabelValues("karpenter_nodepool_scale_up_stalled", map[string]string{"nodepool": nodePool.GetName()})
+		Expect(found).To(BeTrue())
+		Expect(m.GetGauge().GetValue()).To(BeZero())
+
+		nodePool.StatusConditions().MarkTrue(v1beta1.ScaleUpStalled)
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		m, found = FindMetricWithLabelValues("karpenter_nodepool_scale_up_stalled", map[string]string{"nodepool": nodePool.GetName()})
+		Expect(found).To(BeTrue())
+		Expect(m.GetGauge().GetValue()).To(BeNumerically("==", 1))
+	})
 	It("should delete the nodepool state metrics on nodepool delete", func() {
 		expectedMetrics := []string{"karpenter_nodepool_limit", "karpenter_nodepool_usage"}
 		nodePool.Spec.Limits = v1beta1.Limits{
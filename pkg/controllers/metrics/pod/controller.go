@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
@@ -73,6 +74,16 @@ var (
 			Objectives: metrics.SummaryObjectives(),
 		},
 	)
+	podSchedulingLatencyHistogramVec = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "karpenter",
+			Subsystem: "pods",
+			Name:      "scheduling_latency_seconds",
+			Help:      "The time from a pod being marked unschedulable until it's bound to a node that Karpenter launched. Labeled by the nodepool and instance type of the node.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{podNodePool, podHostInstanceType},
+	)
 )
 
 // Controller for the resource
@@ -81,11 +92,14 @@ type Controller struct {
 	metricStore *metrics.Store
 
 	pendingPods sets.Set[string]
+	// unschedulablePods is when each pod that's waiting for capacity was first marked unschedulable
+	unschedulablePods map[string]time.Time
 }
 
 func init() {
 	crmetrics.Registry.MustRegister(podGaugeVec)
 	crmetrics.Registry.MustRegister(podStartupTimeSummary)
+	crmetrics.Registry.MustRegister(podSchedulingLatencyHistogramVec)
 }
 
 func labelNames() []string {
@@ -109,6 +123,8 @@ func NewController(kubeClient client.Client) controller.Controller {
 		kubeClient:  kubeClient,
 		metricStore: metrics.NewStore(),
 		pendingPods: sets.New[string](),
+
+		unschedulablePods: map[string]time.Time{},
 	}
 }
 
@@ -123,6 +139,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	if err := c.kubeClient.Get(ctx, req.NamespacedName, pod); err != nil {
 		if errors.IsNotFound(err) {
 			c.pendingPods.Delete(req.NamespacedName.String())
+			delete(c.unschedulablePods, req.NamespacedName.String())
 			c.metricStore.Delete(req.NamespacedName.String())
 		}
 		return reconcile.Result{}, client.IgnoreNotFound(err)
@@ -139,6 +156,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		},
 	})
 	c.recordPodStartupMetric(pod)
+	c.recordPodSchedulingLatencyMetric(pod, labels)
 	return reconcile.Result{}, nil
 }
 
@@ -157,6 +175,38 @@ func (c *Controller) recordPodStartupMetric(pod *v1.Pod) {
 	}
 }
 
+// recordPodSchedulingLatencyMetric records how long the pod waited for capacity, from when the kube-scheduler first
+// marked it unschedulable until it was bound to a node from one of Karpenter's nodepools. The unschedulable condition
+// is replaced when the pod is bound, so we remember when it was set.
+func (c *Controller) recordPodSchedulingLatencyMetric(pod *v1.Pod, labels prometheus.Labels) {
+	key := client.ObjectKeyFromObject(pod).String()
+	cond, ok := lo.Find(pod.Status.Conditions, func(c v1.PodCondition) bool {
+		return c.Type == v1.PodScheduled
+	})
+	if !ok {
+		return
+	}
+	if cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+		if _, ok := c.unschedulablePods[key]; !ok {
+			c.unschedulablePods[key] = cond.LastTransitionTime.Time
+		}
+		return
+	}
+	unschedulableAt, ok := c.unschedulablePods[key]
+	if !ok || cond.Status != v1.ConditionTrue {
+		return
+	}
+	delete(c.unschedulablePods, key)
+	// Pods that bound to nodes that Karpenter doesn't manage didn't wait on Karpenter's capacity
+	if labels[podNodePool] == "" {
+		return
+	}
+	podSchedulingLatencyHistogramVec.With(prometheus.Labels{
+		podNodePool:         labels[podNodePool],
+		podHostInstanceType: labels[podHostInstanceType],
+	}).Observe(cond.LastTransitionTime.Sub(unschedulableAt).Seconds())
+}
+
 // makeLabels creates the makeLabels using the current state of the pod
 func (c *Controller) makeLabels(ctx context.Context, pod *v1.Pod) (prometheus.Labels, error) {
 	metricLabels := prometheus.Labels{}
@@ -19,13 +19,16 @@ package pod_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "knative.dev/pkg/logging/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
 	"sigs.k8s.io/karpenter/pkg/operator/scheme"
@@ -100,4 +103,53 @@ var _ = Describe("Pod Metrics", func() {
 		})
 		Expect(found).To(BeFalse())
 	})
+	It("should record the scheduling latency of pods that bind to nodes that Karpenter launched", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   "default",
+					v1.LabelInstanceTypeStable: "latency-instance-type",
+				},
+			},
+		})
+		unschedulableAt := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+		p := test.Pod(test.PodOptions{
+			Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: v1.PodReasonUnschedulable, Status: v1.ConditionFalse, LastTransitionTime: unschedulableAt}},
+		})
+		ExpectApplied(ctx, env.Client, node, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		p.Spec.NodeName = node.Name
+		p.Status.Conditions = []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(unschedulableAt.Add(30 * time.Second))}}
+		ExpectApplied(ctx, env.Client, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		m, found := FindMetricWithLabelValues("karpenter_pods_scheduling_latency_seconds", map[string]string{
+			"nodepool":      "default",
+			"instance_type": "latency-instance-type",
+		})
+		Expect(found).To(BeTrue())
+		Expect(m.GetHistogram().GetSampleCount()).To(BeNumerically("==", 1))
+		Expect(m.GetHistogram().GetSampleSum()).To(BeNumerically("~", 30))
+	})
+	It("should not record the scheduling latency of pods that bind to nodes that Karpenter didn't launch", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.LabelInstanceTypeStable: "unmanaged-instance-type"},
+			},
+		})
+		p := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, node, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		p.Spec.NodeName = node.Name
+		p.Status.Conditions = []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: metav1.Now()}}
+		ExpectApplied(ctx, env.Client, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		_, found := FindMetricWithLabelValues("karpenter_pods_scheduling_latency_seconds", map[string]string{
+			"instance_type": "unmanaged-instance-type",
+		})
+		Expect(found).To(BeFalse())
+	})
 })
@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/logging"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+var _ operatorcontroller.TypedController[*v1alpha1.NodeDrain] = (*Controller)(nil)
+
+// Controller drains the nodes of the NodeDrains that Karpenter is the drainer of, and reports the progress of the
+// drains on the NodeDrains. NodeDrains of other drainers are left to them, and the termination of their nodes waits
+// for them to report that the nodes are drained.
+type Controller struct {
+	kubeClient client.Client
+	terminator *terminator.Terminator
+}
+
+// NewController constructs a controller instance
+func NewController(kubeClient client.Client, terminator *terminator.Terminator) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1alpha1.NodeDrain](kubeClient, &Controller{
+		kubeClient: kubeClient,
+		terminator: terminator,
+	})
+}
+
+func (c *Controller) Name() string {
+	return "node.drain"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodeDrain *v1alpha1.NodeDrain) (reconcile.Result, error) {
+	if !options.FromContext(ctx).FeatureGates.NodeDrain {
+		return reconcile.Result{}, nil
+	}
+	if nodeDrain.Spec.Drainer != "" && nodeDrain.Spec.Drainer != v1alpha1.NodeDrainerKarpenter {
+		return reconcile.Result{}, nil
+	}
+	if nodeDrain.Status.Phase == v1alpha1.NodeDrainPhaseDrained || !nodeDrain.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("node", nodeDrain.Spec.NodeName))
+	node := &v1.Node{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: nodeDrain.Spec.NodeName}, node); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("getting node, %w", err)
+		}
+		// A node that doesn't exist has nothing left to drain
+		return reconcile.Result{}, c.updateStatus(ctx, nodeDrain, nil)
+	}
+	// Nodes in another instance's shard are drained by that instance
+	if !nodepoolutil.InShard(ctx, node) {
+		return reconcile.Result{}, nil
+	}
+	if err := c.terminator.Taint(ctx, node); err != nil {
+		return reconcile.Result{}, fmt.Errorf("tainting node, %w", err)
+	}
+	drainErr := c.terminator.Drain(ctx, node)
+	if drainErr != nil && !terminator.IsNodeDrainError(drainErr) {
+		return reconcile.Result{}, fmt.Errorf("draining node, %w", drainErr)
+	}
+	if err := c.updateStatus(ctx, nodeDrain, drainErr); err != nil {
+		return reconcile.Result{}, err
+	}
+	// Drains that are still in progress are retried with the backoff of the evictions that they wait on
+	if drainErr != nil {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	logging.FromContext(ctx).Infof("drained node")
+	return reconcile.Result{}, nil
+}
+
+// updateStatus reports the progress of the node's drain on the NodeDrain, along with the pods that are blocking it so
+// that the termination controller can list them on the node's NodeClaims
+func (c *Controller) updateStatus(ctx context.Context, nodeDrain *v1alpha1.NodeDrain, drainErr error) error {
+	stored := nodeDrain.DeepCopy()
+	if drainErr != nil {
+		nodeDrain.Status = v1alpha1.NodeDrainStatus{
+			Phase:   v1alpha1.NodeDrainPhaseDraining,
+			Message: drainErr.Error(),
+			BlockingPods: lo.Map(terminator.BlockingPods(drainErr), func(b terminator.BlockingPod, _ int) v1alpha1.NodeDrainBlockingPod {
+				return v1alpha1.NodeDrainBlockingPod{Namespace: b.Namespace, Name: b.Name, Reason: b.Reason}
+			}),
+		}
+	} else {
+		nodeDrain.Status = v1alpha1.NodeDrainStatus{Phase: v1alpha1.NodeDrainPhaseDrained}
+	}
+	if equality.Semantic.DeepEqual(stored.Status, nodeDrain.Status) {
+		return nil
+	}
+	if err := c.kubeClient.Status().Patch(ctx, nodeDrain, client.MergeFrom(stored)); err != nil {
+		return client.IgnoreNotFound(fmt.Errorf("patching nodedrain status, %w", err))
+	}
+	return nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1alpha1.NodeDrain{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(terminator.EvictionQueueBaseDelay, terminator.EvictionQueueMaxDelay),
+		}))
+}
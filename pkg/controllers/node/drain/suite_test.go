@@ -0,0 +1,157 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clock "k8s.io/utils/clock/testing"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/drain"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var drainController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var queue *terminator.Queue
+var defaultOwnerRefs = []metav1.OwnerReference{{Kind: "ReplicaSet", APIVersion: "appsv1", Name: "rs", UID: "1234567890"}}
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NodeDrain")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	queue = terminator.NewQueue(env.Client, test.NewEventRecorder())
+	drainController = drain.NewController(env.Client, terminator.NewTerminator(fakeClock, env.Client, queue))
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("NodeDrain", func() {
+	var node *v1.Node
+	var nodeDrain *v1alpha1.NodeDrain
+
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeDrain: lo.ToPtr(true)}}))
+		node = test.Node()
+		nodeDrain = &v1alpha1.NodeDrain{
+			ObjectMeta: metav1.ObjectMeta{Name: node.Name},
+			Spec:       v1alpha1.NodeDrainSpec{NodeName: node.Name},
+		}
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		queue.Reset()
+	})
+
+	It("should drain the node and report the progress of the drain", func() {
+		pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+		ExpectApplied(ctx, env.Client, node, pod, nodeDrain)
+
+		result := ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		Expect(result.Requeue).To(BeTrue())
+		Expect(queue.Has(pod)).To(BeTrue())
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		nodeDrain = ExpectExists(ctx, env.Client, nodeDrain)
+		Expect(nodeDrain.Status.Phase).To(Equal(v1alpha1.NodeDrainPhaseDraining))
+		Expect(nodeDrain.Status.Message).To(ContainSubstring("1 pods are waiting to be evicted"))
+
+		ExpectDeleted(ctx, env.Client, pod)
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		nodeDrain = ExpectExists(ctx, env.Client, nodeDrain)
+		Expect(nodeDrain.Status.Phase).To(Equal(v1alpha1.NodeDrainPhaseDrained))
+		Expect(nodeDrain.Status.Message).To(BeEmpty())
+	})
+	It("should report the pods that are blocking the drain", func() {
+		minAvailable := intstr.FromInt32(1)
+		labels := map[string]string{test.RandomName(): test.RandomName()}
+		pdb := test.PodDisruptionBudget(test.PDBOptions{Labels: labels, MinAvailable: &minAvailable})
+		pod := test.Pod(test.PodOptions{
+			NodeName:   node.Name,
+			ObjectMeta: metav1.ObjectMeta{Labels: labels, OwnerReferences: defaultOwnerRefs},
+			Phase:      v1.PodRunning,
+		})
+		ExpectApplied(ctx, env.Client, node, pod, pdb, nodeDrain)
+
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		nodeDrain = ExpectExists(ctx, env.Client, nodeDrain)
+		Expect(nodeDrain.Status.BlockingPods).To(ConsistOf(v1alpha1.NodeDrainBlockingPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Reason:    fmt.Sprintf("PodDisruptionBudget %s", client.ObjectKeyFromObject(pdb)),
+		}))
+
+		ExpectDeleted(ctx, env.Client, pod)
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		Expect(ExpectExists(ctx, env.Client, nodeDrain).Status.BlockingPods).To(BeEmpty())
+	})
+	It("should mark nodedrains of nodes that don't exist as drained", func() {
+		ExpectApplied(ctx, env.Client, nodeDrain)
+
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		nodeDrain = ExpectExists(ctx, env.Client, nodeDrain)
+		Expect(nodeDrain.Status.Phase).To(Equal(v1alpha1.NodeDrainPhaseDrained))
+	})
+	It("should leave nodedrains of other drainers to them", func() {
+		nodeDrain.Spec.Drainer = "example.com/drainer"
+		pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+		ExpectApplied(ctx, env.Client, node, pod, nodeDrain)
+
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		Expect(queue.Has(pod)).To(BeFalse())
+		Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		Expect(ExpectExists(ctx, env.Client, nodeDrain).Status.Phase).To(BeEmpty())
+	})
+	It("should not drain nodes when the NodeDrain feature gate is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeDrain: lo.ToPtr(false)}}))
+		pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+		ExpectApplied(ctx, env.Client, node, pod, nodeDrain)
+
+		ExpectReconcileSucceeded(ctx, drainController, client.ObjectKeyFromObject(nodeDrain))
+		Expect(queue.Has(pod)).To(BeFalse())
+		Expect(ExpectExists(ctx, env.Client, nodeDrain).Status.Phase).To(BeEmpty())
+	})
+})
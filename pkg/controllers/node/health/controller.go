@@ -0,0 +1,200 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+var _ operatorcontroller.TypedController[*v1.Node] = (*Controller)(nil)
+
+// allowedUnhealthyPercent is the share of a NodePool's nodes that can be unhealthy for its nodes to be repaired. When
+// more of them are unhealthy, the cause is likely outside the nodes, and replacing them would only add churn.
+var allowedUnhealthyPercent = intstr.FromString("20%")
+
+// Controller repairs Karpenter-managed nodes that have been unhealthy for longer than their toleration duration
+// by tainting the node and deleting its NodeClaim, so that the pods are rescheduled onto replacement capacity
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	recorder      events.Recorder
+}
+
+// NewController constructs a controller instance
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1.Node](kubeClient, &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		recorder:      recorder,
+	})
+}
+
+func (c *Controller) Name() string {
+	return "node.health"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *v1.Node) (reconcile.Result, error) {
+	if !options.FromContext(ctx).FeatureGates.NodeRepair {
+		return reconcile.Result{}, nil
+	}
+	// Only repair nodes that are owned by a NodePool in this instance's shard and aren't already being deleted
+	if _, ok := node.Labels[v1beta1.NodePoolLabelKey]; !ok || !nodepoolutil.InShard(ctx, node) || !node.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	condition, policy, found := c.unhealthyCondition(ctx, node)
+	if !found {
+		return reconcile.Result{}, nil
+	}
+	// If the node hasn't been unhealthy for longer than the toleration duration, check back once it has
+	if remaining := policy.TolerationDuration - c.clock.Since(condition.LastTransitionTime.Time); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	healthy, err := c.isNodePoolHealthy(ctx, node.Labels[v1beta1.NodePoolLabelKey])
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("checking nodepool health, %w", err)
+	}
+	if !healthy {
+		logging.FromContext(ctx).With("nodepool", node.Labels[v1beta1.NodePoolLabelKey]).
+			Infof("skipping node repair, more than %s of the nodepool's nodes are unhealthy", allowedUnhealthyPercent.String())
+		c.recorder.Publish(NodeRepairBlockedEvent(node, allowedUnhealthyPercent.String()))
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+	nodeClaims, err := c.nodeClaimsForNode(ctx, node)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaims) == 0 {
+		return reconcile.Result{}, nil
+	}
+	if err = c.taint(ctx, node); err != nil {
+		return reconcile.Result{}, fmt.Errorf("tainting node, %w", err)
+	}
+	c.recorder.Publish(NodeRepairEvent(node, condition))
+	for _, nodeClaim := range nodeClaims {
+		if !nodeClaim.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err = c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		logging.FromContext(ctx).
+			With("nodeclaim", nodeClaim.Name, "condition", condition.Type, "status", condition.Status).
+			Infof("repairing unhealthy node")
+		metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
+			metrics.ReasonLabel:       "unhealthy",
+			metrics.NodePoolLabel:     nodeClaim.Labels[v1beta1.NodePoolLabelKey],
+			metrics.CapacityTypeLabel: nodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
+		}).Inc()
+	}
+	return reconcile.Result{}, nil
+}
+
+// repairPolicies returns the node conditions that Karpenter always repairs along with any cloudprovider-specific ones
+func (c *Controller) repairPolicies(ctx context.Context) []cloudprovider.RepairPolicy {
+	toleration := options.FromContext(ctx).NodeRepairTolerationDuration
+	return append([]cloudprovider.RepairPolicy{
+		{ConditionType: v1.NodeReady, ConditionStatus: v1.ConditionFalse, TolerationDuration: toleration},
+		{ConditionType: v1.NodeReady, ConditionStatus: v1.ConditionUnknown, TolerationDuration: toleration},
+		{ConditionType: v1.NodeDiskPressure, ConditionStatus: v1.ConditionTrue, TolerationDuration: toleration},
+	}, c.cloudProvider.RepairPolicies()...)
+}
+
+// unhealthyCondition returns the node condition that matches a repair policy and whose toleration expires first
+func (c *Controller) unhealthyCondition(ctx context.Context, node *v1.Node) (v1.NodeCondition, cloudprovider.RepairPolicy, bool) {
+	var condition v1.NodeCondition
+	var policy cloudprovider.RepairPolicy
+	var expiration time.Time
+	found := false
+	for _, p := range c.repairPolicies(ctx) {
+		cond, ok := lo.Find(node.Status.Conditions, func(cond v1.NodeCondition) bool {
+			return cond.Type == p.ConditionType && cond.Status == p.ConditionStatus
+		})
+		if !ok {
+			continue
+		}
+		if exp := cond.LastTransitionTime.Add(p.TolerationDuration); !found || exp.Before(expiration) {
+			condition, policy, expiration, found = cond, p, exp, true
+		}
+	}
+	return condition, policy, found
+}
+
+// isNodePoolHealthy returns whether few enough of the NodePool's nodes are unhealthy for its nodes to be repaired
+func (c *Controller) isNodePoolHealthy(ctx context.Context, nodePoolName string) (bool, error) {
+	nodeList := &v1.NodeList{}
+	if err := c.kubeClient.List(ctx, nodeList, client.MatchingLabels{v1beta1.NodePoolLabelKey: nodePoolName}); err != nil {
+		return false, err
+	}
+	unhealthy := lo.CountBy(nodeList.Items, func(n v1.Node) bool {
+		_, _, found := c.unhealthyCondition(ctx, &n)
+		return found
+	})
+	threshold := lo.Must(intstr.GetScaledValueFromIntOrPercent(lo.ToPtr(allowedUnhealthyPercent), len(nodeList.Items), true))
+	return unhealthy <= threshold, nil
+}
+
+func (c *Controller) nodeClaimsForNode(ctx context.Context, node *v1.Node) ([]*v1beta1.NodeClaim, error) {
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
+		return nil, err
+	}
+	return lo.ToSlicePtr(nodeClaimList.Items), nil
+}
+
+// taint adds the disruption taint to the node so that no new pods schedule to it while it is replaced
+func (c *Controller) taint(ctx context.Context, node *v1.Node) error {
+	stored := node.DeepCopy()
+	if !lo.ContainsBy(node.Spec.Taints, v1beta1.IsDisruptingTaint) {
+		node.Spec.Taints = append(node.Spec.Taints, v1beta1.DisruptionNoScheduleTaint)
+	}
+	if !equality.Semantic.DeepEqual(stored, node) {
+		if err := c.kubeClient.Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1.Node{}))
+}
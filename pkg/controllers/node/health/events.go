@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func NodeRepairEvent(node *v1.Node, condition v1.NodeCondition) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           v1.EventTypeWarning,
+		Reason:         "NodeRepair",
+		Message:        fmt.Sprintf("Replacing unhealthy node, condition %s has been %s since %s", condition.Type, condition.Status, condition.LastTransitionTime.Format(time.RFC3339)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeRepairBlockedEvent(node *v1.Node, allowedUnhealthy string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           v1.EventTypeWarning,
+		Reason:         "NodeRepairBlocked",
+		Message:        fmt.Sprintf("Not replacing unhealthy node, more than %s of the nodepool's nodes are unhealthy", allowedUnhealthy),
+		DedupeValues:   []string{node.Name},
+	}
+}
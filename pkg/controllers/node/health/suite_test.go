@@ -0,0 +1,224 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var healthController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...), test.WithFieldIndexers(test.NodeClaimFieldIndexer(ctx)))
+
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	healthController = health.NewController(fakeClock, env.Client, cloudProvider, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Health", func() {
+	var node *v1.Node
+	var nodeClaim *v1beta1.NodeClaim
+
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			NodeRepairTolerationDuration: lo.ToPtr(30 * time.Minute),
+			FeatureGates:                 test.FeatureGates{NodeRepair: lo.ToPtr(true)},
+		}))
+		nodePool := test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		node.Status.Conditions = []v1.NodeCondition{
+			{
+				Type:               v1.NodeReady,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		fakeClock.SetTime(time.Now())
+		cloudProvider.Reset()
+		recorder.Reset()
+	})
+
+	It("should not repair a node when the feature gate is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeRepair: lo.ToPtr(false)}}))
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should not repair a node that isn't owned by a nodepool", func() {
+		delete(node.Labels, v1beta1.NodePoolLabelKey)
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should not repair a healthy node", func() {
+		node.Status.Conditions[0].Status = v1.ConditionTrue
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		result := ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		Expect(result.RequeueAfter).To(BeZero())
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should requeue a node that is within its toleration duration", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(10 * time.Minute)
+
+		result := ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		Expect(result.RequeueAfter).To(BeNumerically("~", 20*time.Minute, time.Second))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should taint and replace a node that has been NotReady for longer than its toleration duration", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(31 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		node = ExpectNodeExists(ctx, env.Client, node.Name)
+		Expect(node.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+		Expect(recorder.Calls("NodeRepair")).To(Equal(1))
+	})
+	It("should replace a node that has had an Unknown Ready condition for longer than its toleration duration", func() {
+		node.Status.Conditions[0].Status = v1.ConditionUnknown
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(31 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should replace a node that has been under DiskPressure for longer than its toleration duration", func() {
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+			{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+		}
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(31 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should use the configured toleration duration", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			NodeRepairTolerationDuration: lo.ToPtr(5 * time.Minute),
+			FeatureGates:                 test.FeatureGates{NodeRepair: lo.ToPtr(true)},
+		}))
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(6 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should not repair a node when more than 20% of the nodepool's nodes are unhealthy", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		// Four more nodes, one of which is also unhealthy, make 40% of the nodepool unhealthy
+		for i := 0; i < 4; i++ {
+			other := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: node.Labels[v1beta1.NodePoolLabelKey]}}})
+			other.Status.Conditions = []v1.NodeCondition{{
+				Type:               v1.NodeReady,
+				Status:             lo.Ternary(i == 0, v1.ConditionFalse, v1.ConditionTrue),
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			}}
+			ExpectApplied(ctx, env.Client, other)
+		}
+		fakeClock.Step(31 * time.Minute)
+
+		result := ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(recorder.Calls("NodeRepairBlocked")).To(Equal(1))
+	})
+	It("should repair a node when at most 20% of the nodepool's nodes are unhealthy", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		for i := 0; i < 4; i++ {
+			other := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: node.Labels[v1beta1.NodePoolLabelKey]}}})
+			other.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}}}
+			ExpectApplied(ctx, env.Client, other)
+		}
+		fakeClock.Step(31 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should replace a node with a cloudprovider-specific unhealthy condition", func() {
+		cloudProvider.Repair = []cloudprovider.RepairPolicy{
+			{ConditionType: "KernelDeadlock", ConditionStatus: v1.ConditionTrue, TolerationDuration: time.Minute},
+		}
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+			{Type: "KernelDeadlock", Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+		}
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		fakeClock.Step(2 * time.Minute)
+
+		ExpectReconcileSucceeded(ctx, healthController, client.ObjectKeyFromObject(node))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+})
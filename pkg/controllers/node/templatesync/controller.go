@@ -0,0 +1,98 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templatesync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"knative.dev/pkg/logging"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+var _ operatorcontroller.TypedController[*v1.Node] = (*Controller)(nil)
+
+// Controller keeps the labels and taints of registered nodes in sync with their NodeClaims for NodePools with the
+// "Continuous" sync policy, so that labels and taints that are removed from the node are restored. NodeClaims get
+// their labels and taints from the NodePool's template when they're launched.
+type Controller struct {
+	kubeClient client.Client
+}
+
+// NewController constructs a controller instance
+func NewController(kubeClient client.Client) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1.Node](kubeClient, &Controller{
+		kubeClient: kubeClient,
+	})
+}
+
+func (c *Controller) Name() string {
+	return "node.templatesync"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *v1.Node) (reconcile.Result, error) {
+	// Nodes are only synced once they've registered, since registration applies the labels and taints in the first place
+	nodePoolName, ok := node.Labels[v1beta1.NodePoolLabelKey]
+	if !ok || node.Labels[v1beta1.NodeRegisteredLabelKey] != "true" || !nodepoolutil.InShard(ctx, node) || !node.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	nodePool := &v1beta1.NodePool{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if nodePool.Spec.Template.SyncPolicy != v1beta1.SyncPolicyContinuous {
+		return reconcile.Result{}, nil
+	}
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaimList.Items) != 1 {
+		return reconcile.Result{}, nil
+	}
+	nodeClaim := nodeClaimList.Items[0]
+
+	stored := node.DeepCopy()
+	node.Labels = lo.Assign(node.Labels, nodeClaim.Labels)
+	// Startup taints are expected to be removed once the node is initialized, so only the NodeClaim's taints are restored
+	node.Spec.Taints = scheduling.Taints(node.Spec.Taints).Merge(nodeClaim.Spec.Taints)
+	if equality.Semantic.DeepEqual(stored, node) {
+		return reconcile.Result{}, nil
+	}
+	if err := c.kubeClient.Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	logging.FromContext(ctx).With("nodepool", nodePool.Name).Infof("restored the labels and taints of the nodepool's template on the node")
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1.Node{}))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templatesync_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/templatesync"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var templateSyncController controller.Controller
+var env *test.Environment
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TemplateSync")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...), test.WithFieldIndexers(test.NodeClaimFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+	templateSyncController = templatesync.NewController(env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("TemplateSync", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	var node *v1.Node
+	taint := v1.Taint{Key: "example.com/dedicated", Value: "true", Effect: v1.TaintEffectNoSchedule}
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodePool.Spec.Template.SyncPolicy = v1beta1.SyncPolicyContinuous
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:       nodePool.Name,
+					v1beta1.NodeRegisteredLabelKey: "true",
+					"example.com/team":             "a",
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				Taints: []v1.Taint{taint},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should restore the labels and taints that are removed from the node", func() {
+		delete(node.Labels, "example.com/team")
+		node.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, templateSyncController, client.ObjectKeyFromObject(node))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("example.com/team", "a"))
+		Expect(node.Spec.Taints).To(ContainElement(taint))
+	})
+	It("should not restore the startup taints of the nodeclaim", func() {
+		startupTaint := v1.Taint{Key: "example.com/startup", Effect: v1.TaintEffectNoSchedule}
+		nodeClaim.Spec.StartupTaints = []v1.Taint{startupTaint}
+		node.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, templateSyncController, client.ObjectKeyFromObject(node))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(taint))
+		Expect(node.Spec.Taints).ToNot(ContainElement(startupTaint))
+	})
+	It("should not restore labels and taints when the nodepool has the registration sync policy", func() {
+		nodePool.Spec.Template.SyncPolicy = v1beta1.SyncPolicyRegistration
+		delete(node.Labels, "example.com/team")
+		node.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, templateSyncController, client.ObjectKeyFromObject(node))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).ToNot(HaveKey("example.com/team"))
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+	It("should not restore labels and taints when the nodepool doesn't set a sync policy", func() {
+		nodePool.Spec.Template.SyncPolicy = ""
+		node.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, templateSyncController, client.ObjectKeyFromObject(node))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+	It("should not sync nodes that haven't registered", func() {
+		delete(node.Labels, v1beta1.NodeRegisteredLabelKey)
+		node.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, templateSyncController, client.ObjectKeyFromObject(node))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+})
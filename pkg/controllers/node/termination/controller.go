@@ -19,13 +19,20 @@ package termination
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
 	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
@@ -41,26 +49,39 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeclaimutil "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
 
 var _ operatorcontroller.FinalizingTypedController[*v1.Node] = (*Controller)(nil)
 
+// maxBlockingPods is the number of blocking pods that are listed on a NodeClaim
+const maxBlockingPods = 5
+
 // Controller for the resource
 type Controller struct {
+	clock         clock.Clock
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
 	terminator    *terminator.Terminator
 	recorder      events.Recorder
+
+	// blockedSince is when each terminating node's drain started being blocked by pods that can't be evicted, keyed by
+	// the node's UID
+	blockedSince   map[types.UID]time.Time
+	blockedSinceMu sync.Mutex
 }
 
 // NewController constructs a controller instance
-func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder) operatorcontroller.Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder) operatorcontroller.Controller {
 	return operatorcontroller.Typed[*v1.Node](kubeClient, &Controller{
+		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 		terminator:    terminator,
 		recorder:      recorder,
+		blockedSince:  map[types.UID]time.Time{},
 	})
 }
 
@@ -74,7 +95,8 @@ func (c *Controller) Reconcile(_ context.Context, _ *v1.Node) (reconcile.Result,
 
 //nolint:gocyclo
 func (c *Controller) Finalize(ctx context.Context, node *v1.Node) (reconcile.Result, error) {
-	if !controllerutil.ContainsFinalizer(node, v1beta1.TerminationFinalizer) {
+	// Nodes in another instance's shard are terminated by that instance
+	if !controllerutil.ContainsFinalizer(node, v1beta1.TerminationFinalizer) || !nodepoolutil.InShard(ctx, node) {
 		return reconcile.Result{}, nil
 	}
 	if err := c.deleteAllNodeClaims(ctx, node); err != nil {
@@ -83,11 +105,15 @@ func (c *Controller) Finalize(ctx context.Context, node *v1.Node) (reconcile.Res
 	if err := c.terminator.Taint(ctx, node); err != nil {
 		return reconcile.Result{}, fmt.Errorf("tainting node, %w", err)
 	}
-	if err := c.terminator.Drain(ctx, node); err != nil {
-		if !terminator.IsNodeDrainError(err) {
-			return reconcile.Result{}, fmt.Errorf("draining node, %w", err)
-		}
-		c.recorder.Publish(terminatorevents.NodeFailedToDrain(node, err))
+	drainErr := c.drain(ctx, node)
+	if drainErr != nil && !terminator.IsNodeDrainError(drainErr) {
+		return reconcile.Result{}, fmt.Errorf("draining node, %w", drainErr)
+	}
+	if err := c.updateDrainConditions(ctx, node, drainErr); err != nil {
+		return reconcile.Result{}, err
+	}
+	if drainErr != nil {
+		c.recorder.Publish(terminatorevents.NodeFailedToDrain(node, drainErr))
 		// If the underlying nodeclaim no longer exists.
 		if _, err := c.cloudProvider.Get(ctx, node.Spec.ProviderID); err != nil {
 			if cloudprovider.IsNodeClaimNotFoundError(err) {
@@ -95,7 +121,16 @@ func (c *Controller) Finalize(ctx context.Context, node *v1.Node) (reconcile.Res
 			}
 			return reconcile.Result{}, fmt.Errorf("getting nodeclaim, %w", err)
 		}
-		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+		elapsed, err := c.terminationGracePeriodElapsed(ctx, node)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("checking termination grace period, %w", err)
+		}
+		if !elapsed {
+			return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+		}
+		// Pods that are still blocking the drain past the termination grace period won't be waited on any longer
+		logging.FromContext(ctx).Infof("termination grace period elapsed, terminating node without waiting for it to drain")
+		c.recorder.Publish(terminatorevents.NodeTerminationGracePeriodElapsed(node))
 	}
 	if err := c.cloudProvider.Delete(ctx, nodeclaimutil.NewFromNode(node)); cloudprovider.IgnoreNodeClaimNotFoundError(err) != nil {
 		return reconcile.Result{}, fmt.Errorf("terminating cloudprovider instance, %w", err)
@@ -106,6 +141,58 @@ func (c *Controller) Finalize(ctx context.Context, node *v1.Node) (reconcile.Res
 	return reconcile.Result{}, nil
 }
 
+// drain drains the node with the terminator, or waits for the node's NodeDrain to be drained when the NodeDrain feature
+// gate is enabled, so that the drainer of the NodeDrain can be another drain-aware controller. A NodeDrain that was
+// created before the feature gate was disabled is deleted, since nothing reports on it anymore once Karpenter drains
+// the node itself.
+func (c *Controller) drain(ctx context.Context, node *v1.Node) error {
+	nodeDrain := &v1alpha1.NodeDrain{}
+	if !options.FromContext(ctx).FeatureGates.NodeDrain {
+		if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: node.Name}, nodeDrain); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("getting nodedrain, %w", err)
+		} else if err == nil {
+			if err = c.kubeClient.Delete(ctx, nodeDrain); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("deleting nodedrain, %w", err)
+			}
+			logging.FromContext(ctx).Infof("deleted nodedrain")
+		}
+		return c.terminator.Drain(ctx, node)
+	}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: node.Name}, nodeDrain); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("getting nodedrain, %w", err)
+		}
+		// The NodeDrain is owned by the node, so that it's garbage collected once the node is deleted
+		nodeDrain = &v1alpha1.NodeDrain{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: node.Name,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "v1",
+					Kind:       "Node",
+					Name:       node.Name,
+					UID:        node.UID,
+				}},
+			},
+			Spec: v1alpha1.NodeDrainSpec{NodeName: node.Name},
+		}
+		if err := c.kubeClient.Create(ctx, nodeDrain); err != nil {
+			return fmt.Errorf("creating nodedrain, %w", err)
+		}
+		logging.FromContext(ctx).Infof("created nodedrain")
+	}
+	if nodeDrain.Status.Phase == v1alpha1.NodeDrainPhaseDrained {
+		return nil
+	}
+	drainer := lo.Ternary(nodeDrain.Spec.Drainer == "", v1alpha1.NodeDrainerKarpenter, nodeDrain.Spec.Drainer)
+	blocking := lo.Map(nodeDrain.Status.BlockingPods, func(b v1alpha1.NodeDrainBlockingPod, _ int) terminator.BlockingPod {
+		return terminator.BlockingPod{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}, Reason: b.Reason}
+	})
+	if nodeDrain.Status.Message != "" {
+		return terminator.NewNodeDrainError(fmt.Errorf("waiting on nodedrain to be drained by %s, %s", drainer, nodeDrain.Status.Message), blocking...)
+	}
+	return terminator.NewNodeDrainError(fmt.Errorf("waiting on nodedrain to be drained by %s", drainer), blocking...)
+}
+
 func (c *Controller) deleteAllNodeClaims(ctx context.Context, node *v1.Node) error {
 	nodeClaimList := &v1beta1.NodeClaimList{}
 	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
@@ -119,7 +206,92 @@ func (c *Controller) deleteAllNodeClaims(ctx context.Context, node *v1.Node) err
 	return nil
 }
 
+// updateDrainConditions reports the progress of the node's drain on its NodeClaims, so that the progress of each node
+// of a disruption command can be followed while they're drained concurrently. Once the drain has been blocked for
+// longer than the blocked termination threshold, the pods that are blocking it are listed on the NodeClaims as well.
+func (c *Controller) updateDrainConditions(ctx context.Context, node *v1.Node, drainErr error) error {
+	blocking := terminator.BlockingPods(drainErr)
+	blockedFor := c.trackBlocked(node, len(blocking) > 0)
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
+		return fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	for i := range nodeClaimList.Items {
+		nodeClaim := &nodeClaimList.Items[i]
+		stored := nodeClaim.DeepCopy()
+		if drainErr != nil {
+			nodeClaim.StatusConditions().MarkFalse(v1beta1.Drained, "Draining", "%s", drainErr)
+		} else {
+			nodeClaim.StatusConditions().MarkTrue(v1beta1.Drained)
+		}
+		if len(blocking) > 0 && blockedFor >= options.FromContext(ctx).BlockedTerminationThreshold {
+			nodeClaim.StatusConditions().MarkTrueWithReason(v1beta1.TerminationBlocked, "BlockingPods", "%s", blockingPodsMessage(blocking))
+		} else if err := nodeClaim.StatusConditions().ClearCondition(v1beta1.TerminationBlocked); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(stored.Status.Conditions, nodeClaim.Status.Conditions) {
+			continue
+		}
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("patching drained condition, %w", err)
+		}
+	}
+	return nil
+}
+
+// trackBlocked returns how long the node's drain has been blocked by pods that can't be evicted, and forgets when it
+// started being blocked once it isn't. Blocking is tracked in memory, so it's measured again after a restart.
+func (c *Controller) trackBlocked(node *v1.Node, blocked bool) time.Duration {
+	c.blockedSinceMu.Lock()
+	defer c.blockedSinceMu.Unlock()
+	if !blocked {
+		delete(c.blockedSince, node.UID)
+		return 0
+	}
+	since, ok := c.blockedSince[node.UID]
+	if !ok {
+		since = c.clock.Now()
+		c.blockedSince[node.UID] = since
+	}
+	return c.clock.Since(since)
+}
+
+// blockingPodsMessage lists the first of the pods that are blocking a drain, along with why
+func blockingPodsMessage(blocking []terminator.BlockingPod) string {
+	pods := lo.Map(lo.Slice(blocking, 0, maxBlockingPods), func(b terminator.BlockingPod, _ int) string {
+		return fmt.Sprintf("%s (%s)", b.NamespacedName, b.Reason)
+	})
+	if len(blocking) > maxBlockingPods {
+		return fmt.Sprintf("%s and %d other(s)", strings.Join(pods, ", "), len(blocking)-maxBlockingPods)
+	}
+	return strings.Join(pods, ", ")
+}
+
+// terminationGracePeriodElapsed returns true if the node has been terminating for longer than its NodeClaim's
+// terminationGracePeriod, measured from when the node or the NodeClaim was first deleted
+func (c *Controller) terminationGracePeriodElapsed(ctx context.Context, node *v1.Node) (bool, error) {
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
+		return false, err
+	}
+	for i := range nodeClaimList.Items {
+		nodeClaim := &nodeClaimList.Items[i]
+		if nodeClaim.Spec.TerminationGracePeriod == nil {
+			continue
+		}
+		deletedAt := node.DeletionTimestamp.Time
+		if !nodeClaim.DeletionTimestamp.IsZero() && nodeClaim.DeletionTimestamp.Time.Before(deletedAt) {
+			deletedAt = nodeClaim.DeletionTimestamp.Time
+		}
+		if c.clock.Since(deletedAt) >= nodeClaim.Spec.TerminationGracePeriod.Duration {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *Controller) removeFinalizer(ctx context.Context, n *v1.Node) error {
+	c.trackBlocked(n, false)
 	stored := n.DeepCopy()
 	controllerutil.RemoveFinalizer(n, v1beta1.TerminationFinalizer)
 	if !equality.Semantic.DeepEqual(stored, n) {
@@ -145,7 +317,7 @@ func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontr
 		WithOptions(
 			controller.Options{
 				RateLimiter: workqueue.NewMaxOfRateLimiter(
-					workqueue.NewItemExponentialFailureRateLimiter(100*time.Millisecond, 10*time.Second),
+					workqueue.NewItemExponentialFailureRateLimiter(terminator.EvictionQueueBaseDelay, terminator.EvictionQueueMaxDelay),
 					// 10 qps, 100 bucket size
 					&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
 				),
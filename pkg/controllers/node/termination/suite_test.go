@@ -18,6 +18,7 @@ package termination_test
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -30,16 +31,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/operator/scheme"
 	"sigs.k8s.io/karpenter/pkg/test"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/ptr"
@@ -71,7 +75,7 @@ var _ = BeforeSuite(func() {
 	cloudProvider = fake.NewCloudProvider()
 	recorder = test.NewEventRecorder()
 	queue = terminator.NewQueue(env.Client, recorder)
-	terminationController = termination.NewController(env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue), recorder)
+	terminationController = termination.NewController(fakeClock, env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue), recorder)
 })
 
 var _ = AfterSuite(func() {
@@ -83,6 +87,7 @@ var _ = Describe("Termination", func() {
 	var nodeClaim *v1beta1.NodeClaim
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
 		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{v1beta1.TerminationFinalizer}}})
 		node.Labels[v1beta1.NodePoolLabelKey] = test.NodePool().Name
 		cloudProvider.CreatedNodeClaims[node.Spec.ProviderID] = nodeClaim
@@ -336,6 +341,141 @@ var _ = Describe("Termination", func() {
 			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should terminate nodes blocked by a PDB once the termination grace period elapses", func() {
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: v1.PodRunning,
+			})
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Minute}
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podNoEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+
+			// Expect node to exist and be draining while within the termination grace period
+			ExpectNodeWithNodeClaimDraining(env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNodeExists(ctx, env.Client, node.Name)
+
+			// Once the termination grace period elapses, the node is terminated without waiting on the pod
+			fakeClock.Step(2 * time.Minute)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, node)
+			Expect(recorder.Calls("TerminationGracePeriodElapsed")).To(Equal(1))
+		})
+		It("should not terminate nodes that are blocked from draining without a termination grace period", func() {
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: v1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podNoEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+
+			fakeClock.Step(time.Hour)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNodeWithNodeClaimDraining(env.Client, node.Name)
+		})
+		It("should list the pods that block the drain on the nodeclaim once it's been blocked for a while", func() {
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: v1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podNoEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)).To(BeNil())
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			condition := ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.IsTrue()).To(BeTrue())
+			Expect(condition.Reason).To(Equal("BlockingPods"))
+			Expect(condition.Message).To(ContainSubstring(client.ObjectKeyFromObject(podNoEvict).String()))
+			Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("PodDisruptionBudget %s", client.ObjectKeyFromObject(pdb))))
+		})
+		It("should measure the blocked termination threshold from when the drain started being blocked", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{BlockedTerminationThreshold: lo.ToPtr(30 * time.Minute)}))
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: v1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podNoEvict)
+
+			// The node has been terminating for a while before the pdb starts blocking its drain
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			fakeClock.Step(time.Hour)
+			ExpectApplied(ctx, env.Client, pdb)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)).To(BeNil())
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)).To(BeNil())
+
+			fakeClock.Step(30 * time.Minute)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			condition := ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.IsTrue()).To(BeTrue())
+		})
 		It("should evict pods in order", func() {
 			daemonEvict := test.DaemonSet()
 			daemonNodeCritical := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{PriorityClassName: "system-node-critical"}})
@@ -457,6 +597,129 @@ var _ = Describe("Termination", func() {
 			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should evict lower priority pods first", func() {
+			highPriority := test.PriorityClass(1000)
+			podLow := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podHigh := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: highPriority.Name, Priority: lo.ToPtr(highPriority.Value), ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+
+			ExpectApplied(ctx, env.Client, highPriority, node, podLow, podHigh)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+
+			// Expect only the low priority pod to be evicting, and delete it
+			EventuallyExpectTerminating(ctx, env.Client, podLow)
+			Expect(queue.Has(podHigh)).To(BeFalse())
+			ExpectDeleted(ctx, env.Client, podLow)
+
+			// Expect the high priority pod to be evicted once the low priority pod is gone
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			EventuallyExpectTerminating(ctx, env.Client, podHigh)
+			ExpectDeleted(ctx, env.Client, podHigh)
+
+			// Reconcile to delete node
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should evict the pods with the lowest pod deletion cost first", func() {
+			podExpensive := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: defaultOwnerRefs,
+				Annotations:     map[string]string{v1.PodDeletionCost: "100"},
+			}})
+			podCheap := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: defaultOwnerRefs,
+				Annotations:     map[string]string{v1.PodDeletionCost: "-100"},
+			}})
+			ExpectApplied(ctx, env.Client, node, podExpensive, podCheap)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			// Both pods are in the same wave, but the cheaper pod is evicted first
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			EventuallyExpectTerminating(ctx, env.Client, podCheap)
+			Expect(queue.Has(podExpensive)).To(BeTrue())
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			EventuallyExpectTerminating(ctx, env.Client, podExpensive)
+			ExpectDeleted(ctx, env.Client, podCheap, podExpensive)
+
+			// Reconcile to delete node
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should delay evicting pods whose PDBs don't allow a disruption", func() {
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: v1.PodRunning,
+			})
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, podNoEvict, podEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			// The pod blocked by its PDB is queued with a backoff while the other pod is evicted
+			Expect(queue.Has(podNoEvict)).To(BeTrue())
+			Expect(queue.NumRequeues(terminator.NewQueueKey(podNoEvict))).To(BeNumerically(">=", 1))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			ExpectNodeWithNodeClaimDraining(env.Client, node.Name)
+		})
+		It("should evict unhealthy pods whose PDBs always allow evicting unhealthy pods", func() {
+			minAvailable := intstr.FromInt32(1)
+			alwaysAllow := policyv1.AlwaysAllow
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any healthy pod evict
+				MinAvailable: &minAvailable,
+			})
+			pdb.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
+			// The pod is crashlooping, so it never becomes ready
+			pod := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse, Reason: "ContainersNotReady"}},
+			})
+			ExpectApplied(ctx, env.Client, node, pod, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			// The pod is queued without a backoff and evicted
+			Expect(queue.Has(pod)).To(BeTrue())
+			Expect(queue.NumRequeues(terminator.NewQueueKey(pod))).To(BeZero())
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			EventuallyExpectTerminating(ctx, env.Client, pod)
+		})
 		It("should not evict static pods", func() {
 			ExpectApplied(ctx, env.Client, node)
 			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
@@ -535,6 +798,95 @@ var _ = Describe("Termination", func() {
 			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should report the progress of the drain on the node's nodeclaim", func() {
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			condition := nodeClaim.StatusConditions().GetCondition(v1beta1.Drained)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.IsFalse()).To(BeTrue())
+			Expect(condition.Reason).To(Equal("Draining"))
+			Expect(condition.Message).To(ContainSubstring("1 pods are waiting to be evicted"))
+
+			ExpectDeleted(ctx, env.Client, pod)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, node)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drained).IsTrue()).To(BeTrue())
+		})
+		It("should wait for the node's nodedrain to be drained when the NodeDrain feature gate is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeDrain: lo.ToPtr(true)}}))
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			// The node is drained through the nodedrain, rather than by the termination controller
+			nodeDrain := ExpectExists(ctx, env.Client, &v1alpha1.NodeDrain{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+			Expect(nodeDrain.Spec.NodeName).To(Equal(node.Name))
+			Expect(nodeDrain.OwnerReferences).To(HaveLen(1))
+			Expect(nodeDrain.OwnerReferences[0].UID).To(Equal(node.UID))
+			Expect(queue.Has(pod)).To(BeFalse())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNodeExists(ctx, env.Client, node.Name)
+
+			nodeDrain.Status.Phase = v1alpha1.NodeDrainPhaseDrained
+			Expect(env.Client.Status().Update(ctx, nodeDrain)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should list the pods that block the node's nodedrain on the nodeclaim once it's been blocked for a while", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeDrain: lo.ToPtr(true)}}))
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+
+			// The drainer of the nodedrain reports the pods that are blocking it
+			nodeDrain := ExpectExists(ctx, env.Client, &v1alpha1.NodeDrain{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+			nodeDrain.Status = v1alpha1.NodeDrainStatus{
+				Phase:        v1alpha1.NodeDrainPhaseDraining,
+				BlockingPods: []v1alpha1.NodeDrainBlockingPod{{Namespace: pod.Namespace, Name: pod.Name, Reason: "DoNotDisrupt"}},
+			}
+			Expect(env.Client.Status().Update(ctx, nodeDrain)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)).To(BeNil())
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			condition := ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().GetCondition(v1beta1.TerminationBlocked)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.IsTrue()).To(BeTrue())
+			Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("%s (DoNotDisrupt)", client.ObjectKeyFromObject(pod))))
+		})
+		It("should delete the node's nodedrain and drain the node itself when the NodeDrain feature gate is disabled mid-drain", func() {
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeDrain: lo.ToPtr(true)}})), terminationController, client.ObjectKeyFromObject(node))
+			nodeDrain := ExpectExists(ctx, env.Client, &v1alpha1.NodeDrain{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+			Expect(queue.Has(pod)).To(BeFalse())
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectNotFound(ctx, env.Client, nodeDrain)
+			Expect(queue.Has(pod)).To(BeTrue())
+		})
 		It("should delete nodes with no underlying instance even if not fully drained", func() {
 			pods := test.Pods(2, test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
 			ExpectApplied(ctx, env.Client, node, pods[0], pods[1])
@@ -34,6 +34,16 @@ func EvictPod(pod *v1.Pod) events.Event {
 	}
 }
 
+func DeletePod(pod *v1.Pod) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           v1.EventTypeNormal,
+		Reason:         "Deleted",
+		Message:        "Deleted pod without the eviction API",
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
 func NodeFailedToDrain(node *v1.Node, err error) events.Event {
 	return events.Event{
 		InvolvedObject: node,
@@ -43,3 +53,13 @@ func NodeFailedToDrain(node *v1.Node, err error) events.Event {
 		DedupeValues:   []string{node.Name},
 	}
 }
+
+func NodeTerminationGracePeriodElapsed(node *v1.Node) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           v1.EventTypeWarning,
+		Reason:         "TerminationGracePeriodElapsed",
+		Message:        "Termination grace period elapsed, terminating node without waiting for it to drain",
+		DedupeValues:   []string{node.Name},
+	}
+}
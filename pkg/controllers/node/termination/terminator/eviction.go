@@ -28,6 +28,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
@@ -38,21 +39,41 @@ import (
 
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 
 	"sigs.k8s.io/karpenter/pkg/events"
 )
 
 const (
-	evictionQueueBaseDelay = 100 * time.Millisecond
-	evictionQueueMaxDelay  = 10 * time.Second
+	// EvictionQueueBaseDelay and EvictionQueueMaxDelay bound the backoff of pods that fail to be evicted, which drains
+	// are retried with as well
+	EvictionQueueBaseDelay = 100 * time.Millisecond
+	EvictionQueueMaxDelay  = 10 * time.Second
 )
 
 type NodeDrainError struct {
 	error
+	// BlockingPods are the pods that are waiting to be evicted because a PDB or the do-not-disrupt annotation blocks them
+	BlockingPods []BlockingPod
 }
 
-func NewNodeDrainError(err error) *NodeDrainError {
-	return &NodeDrainError{error: err}
+func NewNodeDrainError(err error, blockingPods ...BlockingPod) *NodeDrainError {
+	return &NodeDrainError{error: err, BlockingPods: blockingPods}
+}
+
+// BlockingPod is a pod that blocks a node from draining, along with why
+type BlockingPod struct {
+	types.NamespacedName
+	Reason string
+}
+
+// BlockingPods returns the pods that block the node of a NodeDrainError from draining
+func BlockingPods(err error) []BlockingPod {
+	var nodeDrainErr *NodeDrainError
+	if !errors.As(err, &nodeDrainErr) {
+		return nil
+	}
+	return nodeDrainErr.BlockingPods
 }
 
 func IsNodeDrainError(err error) bool {
@@ -87,7 +108,7 @@ type Queue struct {
 
 func NewQueue(kubeClient client.Client, recorder events.Recorder) *Queue {
 	queue := &Queue{
-		RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(evictionQueueBaseDelay, evictionQueueMaxDelay)),
+		RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(EvictionQueueBaseDelay, EvictionQueueMaxDelay)),
 		set:                   sets.New[QueueKey](),
 		kubeClient:            kubeClient,
 		recorder:              recorder,
@@ -117,6 +138,21 @@ func (q *Queue) Add(pods ...*v1.Pod) {
 	}
 }
 
+// AddRateLimited adds pods to the Queue once their rate limiter allows it. This is used for pods that are expected to
+// fail eviction so that they're retried with a backoff.
+func (q *Queue) AddRateLimited(pods ...*v1.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, pod := range pods {
+		qk := NewQueueKey(pod)
+		if !q.set.Has(qk) {
+			q.set.Insert(qk)
+			q.RateLimitingInterface.AddRateLimited(qk)
+		}
+	}
+}
+
 func (q *Queue) Has(pod *v1.Pod) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -155,6 +191,10 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 // Evict returns true if successful eviction call, and false if not an eviction-related error
 func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("pod", key.NamespacedName))
+	if q.bypassesEviction(ctx, key) {
+		logging.FromContext(ctx).Infof("deleting pod without the eviction API, its namespace matches the eviction bypass namespace selector")
+		return q.delete(ctx, key)
+	}
 	if err := q.kubeClient.SubResource("eviction").Create(ctx,
 		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}},
 		&policyv1.Eviction{
@@ -180,6 +220,10 @@ func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 			}}, fmt.Errorf("evicting pod %s/%s violates a PDB", key.Namespace, key.Name)))
 			return false
 		}
+		if apierrors.IsMethodNotSupported(err) { // 405 - The apiserver doesn't support evicting the pod
+			logging.FromContext(ctx).Infof("deleting pod without the eviction API, eviction isn't supported, %s", err)
+			return q.delete(ctx, key)
+		}
 		logging.FromContext(ctx).Errorf("evicting pod, %s", err)
 		return false
 	}
@@ -187,10 +231,48 @@ func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 	return true
 }
 
+// bypassesEviction returns true if the pod's namespace matches the eviction bypass namespace selector, so that the
+// pod should be deleted rather than evicted. This lets nodes drain when a workload's PDBs never allow an eviction.
+func (q *Queue) bypassesEviction(ctx context.Context, key QueueKey) bool {
+	if options.FromContext(ctx).EvictionBypassNamespaceSelector == "" {
+		return false
+	}
+	// The selector is validated when the options are parsed
+	selector, err := labels.Parse(options.FromContext(ctx).EvictionBypassNamespaceSelector)
+	if err != nil {
+		return false
+	}
+	namespace := &v1.Namespace{}
+	if err = q.kubeClient.Get(ctx, client.ObjectKey{Name: key.Namespace}, namespace); err != nil {
+		logging.FromContext(ctx).Errorf("getting namespace, %s", err)
+		return false
+	}
+	return selector.Matches(labels.Set(namespace.Labels))
+}
+
+// delete returns true if the pod was deleted, or is already gone, and false otherwise
+func (q *Queue) delete(ctx context.Context, key QueueKey) bool {
+	if err := q.kubeClient.Delete(ctx,
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}},
+		&client.DeleteOptions{
+			Preconditions: &metav1.Preconditions{
+				UID: lo.ToPtr(key.UID),
+			},
+		}); err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			return true
+		}
+		logging.FromContext(ctx).Errorf("deleting pod, %s", err)
+		return false
+	}
+	q.recorder.Publish(terminatorevents.DeletePod(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}))
+	return true
+}
+
 func (q *Queue) Reset() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.RateLimitingInterface = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(evictionQueueBaseDelay, evictionQueueMaxDelay))
+	q.RateLimitingInterface = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(EvictionQueueBaseDelay, EvictionQueueMaxDelay))
 	q.set = sets.New[QueueKey]()
 }
@@ -127,6 +127,45 @@ var _ = Describe("Eviction/Queue", func() {
 			ExpectApplied(ctx, env.Client, pdb, pdb2, pod)
 			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod))).To(BeFalse())
 		})
+		Context("Eviction Bypass", func() {
+			var namespace *v1.Namespace
+			BeforeEach(func() {
+				namespace = &v1.Namespace{ObjectMeta: test.ObjectMeta(metav1.ObjectMeta{Labels: map[string]string{"eviction": "bypass"}})}
+				pdb = test.PodDisruptionBudget(test.PDBOptions{
+					ObjectMeta:     metav1.ObjectMeta{Namespace: namespace.Name},
+					Labels:         testLabels,
+					MaxUnavailable: &intstr.IntOrString{IntVal: 0},
+				})
+				pod = test.Pod(test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespace.Name,
+						Labels:    testLabels,
+					},
+				})
+			})
+			It("should delete pods in namespaces that match the eviction bypass namespace selector", func() {
+				bypassCtx := options.ToContext(ctx, test.Options(test.OptionsFields{EvictionBypassNamespaceSelector: lo.ToPtr("eviction=bypass")}))
+				ExpectApplied(ctx, env.Client, namespace, pdb, pod)
+				Expect(queue.Evict(bypassCtx, terminator.NewQueueKey(pod))).To(BeTrue())
+				Expect(recorder.Calls("Deleted")).To(Equal(1))
+				Expect(recorder.Calls("FailedDraining")).To(Equal(0))
+				ExpectNotFound(ctx, env.Client, pod)
+			})
+			It("should evict pods in namespaces that don't match the eviction bypass namespace selector", func() {
+				bypassCtx := options.ToContext(ctx, test.Options(test.OptionsFields{EvictionBypassNamespaceSelector: lo.ToPtr("eviction=other")}))
+				ExpectApplied(ctx, env.Client, namespace, pdb, pod)
+				Expect(queue.Evict(bypassCtx, terminator.NewQueueKey(pod))).To(BeFalse())
+				Expect(recorder.Calls("Deleted")).To(Equal(0))
+				Expect(recorder.Calls("FailedDraining")).To(Equal(1))
+				ExpectExists(ctx, env.Client, pod)
+			})
+			It("should evict pods when the eviction bypass namespace selector isn't set", func() {
+				ExpectApplied(ctx, env.Client, namespace, pdb, pod)
+				Expect(queue.Evict(ctx, terminator.NewQueueKey(pod))).To(BeFalse())
+				Expect(recorder.Calls("Deleted")).To(Equal(0))
+				ExpectExists(ctx, env.Client, pod)
+			})
+		})
 		It("should ensure that calling Evict() is valid while making Add() calls", func() {
 			cancelCtx, cancel := context.WithCancel(ctx)
 			wg := sync.WaitGroup{}
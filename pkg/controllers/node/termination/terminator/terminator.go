@@ -19,6 +19,8 @@ package terminator
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
@@ -30,6 +32,7 @@ import (
 	nodeutil "sigs.k8s.io/karpenter/pkg/utils/node"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
@@ -86,18 +89,49 @@ func (t *Terminator) Drain(ctx context.Context, node *v1.Node) error {
 	}
 	// evictablePods are pods that aren't yet terminating are eligible to have the eviction API called against them
 	evictablePods := lo.Filter(pods, func(p *v1.Pod, _ int) bool { return podutil.IsEvictable(p) })
-	t.Evict(evictablePods)
+	pdbs, err := disruption.NewPDBLimits(ctx, t.clock, t.kubeClient)
+	if err != nil {
+		return fmt.Errorf("tracking pdbs, %w", err)
+	}
+	t.Evict(evictablePods, pdbs)
 
 	// podsWaitingEvictionCount are  the number of pods that either haven't had eviction called against them yet
 	// or are still actively terminated and haven't exceeded their termination grace period yet
 	podsWaitingEvictionCount := lo.CountBy(pods, func(p *v1.Pod) bool { return podutil.IsWaitingEviction(p, t.clock) })
 	if podsWaitingEvictionCount > 0 {
-		return NewNodeDrainError(fmt.Errorf("%d pods are waiting to be evicted", len(pods)))
+		return NewNodeDrainError(fmt.Errorf("%d pods are waiting to be evicted", podsWaitingEvictionCount), blockingPods(pods, pdbs, t.clock)...)
 	}
 	return nil
 }
 
-func (t *Terminator) Evict(pods []*v1.Pod) {
+// blockingPods returns the pods that are waiting to be evicted because their PDBs don't allow a disruption, or because
+// they have the do-not-disrupt annotation
+func blockingPods(pods []*v1.Pod, pdbs *disruption.PDBLimits, clk clock.Clock) []BlockingPod {
+	var blocking []BlockingPod
+	for _, pod := range pods {
+		if !podutil.IsWaitingEviction(pod, clk) {
+			continue
+		}
+		if podutil.HasDoNotDisrupt(pod) {
+			blocking = append(blocking, BlockingPod{NamespacedName: client.ObjectKeyFromObject(pod), Reason: "DoNotDisrupt"})
+		} else if pdb, ok := pdbs.CanEvictPods([]*v1.Pod{pod}); !ok {
+			blocking = append(blocking, BlockingPod{NamespacedName: client.ObjectKeyFromObject(pod), Reason: fmt.Sprintf("PodDisruptionBudget %s", pdb)})
+		}
+	}
+	return blocking
+}
+
+// Evict queues the next wave of pods for eviction. A wave is only started once every pod in the previous wave has
+// been evicted, so that pods are evicted in order:
+// a. non-critical non-daemonsets
+// b. non-critical daemonsets
+// c. critical non-daemonsets
+// d. critical daemonsets
+// Within the non-critical groups, pods are evicted by priority with the lowest priority pods evicted first.
+// Within a wave, unhealthy pods are queued first, since PDBs that always allow evicting unhealthy pods don't count them
+// against their budget, and then pods are queued in order of their pod deletion cost, so that the pods that an
+// application marked as cheapest are the first to be evicted when a PDB only allows some of them to be disrupted.
+func (t *Terminator) Evict(pods []*v1.Pod, pdbs *disruption.PDBLimits) {
 	// 1. Prioritize noncritical pods, non-daemon pods https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
 	var criticalNonDaemon, criticalDaemon, nonCriticalNonDaemon, nonCriticalDaemon []*v1.Pod
 	for _, pod := range pods {
@@ -115,18 +149,52 @@ func (t *Terminator) Evict(pods []*v1.Pod) {
 			}
 		}
 	}
-	// 2. Evict in order:
-	// a. non-critical non-daemonsets
-	// b. non-critical daemonsets
-	// c. critical non-daemonsets
-	// d. critical daemonsets
+	// 2. Evict the first group that still has pods
 	if len(nonCriticalNonDaemon) != 0 {
-		t.evictionQueue.Add(nonCriticalNonDaemon...)
+		t.evictWave(lowestPriority(nonCriticalNonDaemon), pdbs)
 	} else if len(nonCriticalDaemon) != 0 {
-		t.evictionQueue.Add(nonCriticalDaemon...)
+		t.evictWave(lowestPriority(nonCriticalDaemon), pdbs)
 	} else if len(criticalNonDaemon) != 0 {
-		t.evictionQueue.Add(criticalNonDaemon...)
+		t.evictWave(criticalNonDaemon, pdbs)
 	} else if len(criticalDaemon) != 0 {
-		t.evictionQueue.Add(criticalDaemon...)
+		t.evictWave(criticalDaemon, pdbs)
+	}
+}
+
+// lowestPriority returns the pods that have the lowest priority
+func lowestPriority(pods []*v1.Pod) []*v1.Pod {
+	lowest := lo.Min(lo.Map(pods, func(p *v1.Pod, _ int) int32 { return lo.FromPtr(p.Spec.Priority) }))
+	return lo.Filter(pods, func(p *v1.Pod, _ int) bool { return lo.FromPtr(p.Spec.Priority) == lowest })
+}
+
+// evictWave adds the pods to the eviction queue. Pods whose PDBs don't currently allow a disruption would only fail
+// to evict, so they're queued with a backoff rather than retried immediately.
+func (t *Terminator) evictWave(pods []*v1.Pod, pdbs *disruption.PDBLimits) {
+	pods = append([]*v1.Pod{}, pods...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		if healthyI, healthyJ := podutil.IsHealthy(pods[i]), podutil.IsHealthy(pods[j]); healthyI != healthyJ {
+			return healthyJ
+		}
+		return deletionCost(pods[i]) < deletionCost(pods[j])
+	})
+	var allowed, blocked []*v1.Pod
+	for _, pod := range pods {
+		if _, ok := pdbs.CanEvictPods([]*v1.Pod{pod}); ok {
+			allowed = append(allowed, pod)
+		} else {
+			blocked = append(blocked, pod)
+		}
+	}
+	t.evictionQueue.Add(allowed...)
+	t.evictionQueue.AddRateLimited(blocked...)
+}
+
+// deletionCost returns the pod's controller.kubernetes.io/pod-deletion-cost. Like the ReplicaSet controller, a missing
+// or invalid cost is treated as 0.
+func deletionCost(pod *v1.Pod) int32 {
+	cost, err := strconv.ParseInt(pod.Annotations[v1.PodDeletionCost], 10, 32)
+	if err != nil {
+		return 0
 	}
+	return int32(cost)
 }
@@ -19,9 +19,12 @@ package disruption
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -31,6 +34,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -39,11 +43,22 @@ import (
 const (
 	NodePoolDrifted     cloudprovider.DriftReason = "NodePoolDrifted"
 	RequirementsDrifted cloudprovider.DriftReason = "RequirementsDrifted"
+
+	// The static fields of the NodePool's template, recorded as drifted reasons when the NodePool hash has drifted
+	LabelsDrifted        cloudprovider.DriftReason = "LabelsDrifted"
+	AnnotationsDrifted   cloudprovider.DriftReason = "AnnotationsDrifted"
+	TaintsDrifted        cloudprovider.DriftReason = "TaintsDrifted"
+	StartupTaintsDrifted cloudprovider.DriftReason = "StartupTaintsDrifted"
+	KubeletDrifted       cloudprovider.DriftReason = "KubeletDrifted"
+	NodeClassRefDrifted  cloudprovider.DriftReason = "NodeClassRefDrifted"
+
+	TerminationGracePeriodDrifted cloudprovider.DriftReason = "TerminationGracePeriodDrifted"
 )
 
 // Drift is a nodeclaim sub-controller that adds or removes status conditions on drifted nodeclaims
 type Drift struct {
 	cloudProvider cloudprovider.CloudProvider
+	recorder      events.Recorder
 }
 
 func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
@@ -53,6 +68,7 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 	// 1. If drift is not enabled but the NodeClaim is drifted, remove the status condition
 	if !options.FromContext(ctx).FeatureGates.Drift {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Drifted)
+		nodeClaim.Status.DriftedReasons = nil
 		if hasDriftedCondition {
 			logging.FromContext(ctx).Debugf("removing drift status condition, drift has been disabled")
 		}
@@ -61,22 +77,29 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 	// 2. If NodeClaim is not launched, remove the drift status condition
 	if launchCond := nodeClaim.StatusConditions().GetCondition(v1beta1.Launched); launchCond == nil || launchCond.IsFalse() {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Drifted)
+		nodeClaim.Status.DriftedReasons = nil
 		if hasDriftedCondition {
 			logging.FromContext(ctx).Debugf("removing drift status condition, isn't launched")
 		}
 		return reconcile.Result{}, nil
 	}
-	driftedReason, err := d.isDrifted(ctx, nodePool, nodeClaim)
+	driftedReason, driftedReasons, err := d.isDrifted(ctx, nodePool, nodeClaim)
 	if err != nil {
+		// Check again once the cloudprovider is ready for more requests, rather than backing off on our own
+		if retryAfter, ok := cloudprovider.RetryAfter(err); ok {
+			logging.FromContext(ctx).With("retry-after", retryAfter).Debugf("retrying drift check, %s", err)
+			return reconcile.Result{RequeueAfter: retryAfter}, nil
+		}
 		return reconcile.Result{}, cloudprovider.IgnoreNodeClaimNotFoundError(fmt.Errorf("getting drift, %w", err))
 	}
 	// 3. Otherwise, if the NodeClaim isn't drifted, but has the status condition, remove it.
 	if driftedReason == "" {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Drifted)
+		nodeClaim.Status.DriftedReasons = nil
 		if hasDriftedCondition {
 			logging.FromContext(ctx).Debugf("removing drifted status condition, not drifted")
 		}
-		return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+		return reconcile.Result{RequeueAfter: driftCheckInterval(nodePool)}, nil
 	}
 	// 4. Finally, if the NodeClaim is drifted, but doesn't have status condition, add it.
 	nodeClaim.StatusConditions().SetCondition(apis.Condition{
@@ -85,8 +108,9 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 		Severity: apis.ConditionSeverityWarning,
 		Reason:   string(driftedReason),
 	})
+	reasons := lo.Map(driftedReasons, func(r cloudprovider.DriftReason, _ int) string { return string(r) })
 	if !hasDriftedCondition {
-		logging.FromContext(ctx).With("reason", string(driftedReason)).Debugf("marking drifted")
+		logging.FromContext(ctx).With("reason", string(driftedReason), "drifted-reasons", reasons).Debugf("marking drifted")
 		metrics.NodeClaimsDisruptedCounter.With(prometheus.Labels{
 			metrics.TypeLabel:     metrics.DriftReason,
 			metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
@@ -96,34 +120,123 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 			metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
 		}).Inc()
 	}
-	// Requeue after 5 minutes for the cache TTL
-	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+	// Surface the specific reasons whenever they change so that a NodeClaim that drifts further is reported again
+	if previous := sets.New(nodeClaim.Status.DriftedReasons...); !previous.Equal(sets.New(reasons...)) {
+		for _, reason := range sets.New(reasons...).Difference(previous).UnsortedList() {
+			metrics.NodeClaimsDriftedReasonsCounter.With(prometheus.Labels{
+				metrics.ReasonLabel:   reason,
+				metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
+			}).Inc()
+		}
+		d.recorder.Publish(DriftedEvent(nodeClaim, reasons))
+	}
+	nodeClaim.Status.DriftedReasons = reasons
+	// Requeue after the drift check interval to re-evaluate drift
+	return reconcile.Result{RequeueAfter: driftCheckInterval(nodePool)}, nil
+}
+
+// driftCheckInterval returns how often the NodeClaims of the NodePool should be checked for drift, defaulting to
+// 5 minutes for the cache TTL
+func driftCheckInterval(nodePool *v1beta1.NodePool) time.Duration {
+	if nodePool.Spec.Disruption.DriftCheckInterval != nil {
+		return nodePool.Spec.Disruption.DriftCheckInterval.Duration
+	}
+	return 5 * time.Minute
 }
 
-// isDrifted will check if a NodeClaim is drifted from the fields in the NodePool Spec and the CloudProvider
-func (d *Drift) isDrifted(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+// isDrifted will check if a NodeClaim is drifted from the fields in the NodePool Spec and the CloudProvider. It returns
+// the reason used for the drifted status condition along with each of the specific reasons that the NodeClaim drifted.
+// Reasons of the drift checks that the NodePool disabled are ignored.
+func (d *Drift) isDrifted(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, []cloudprovider.DriftReason, error) {
+	checks := lo.FromPtr(nodePool.Spec.Disruption.DriftChecks)
 	// First check for static drift or node requirements have drifted to save on API calls.
-	if reason := lo.FindOrElse([]cloudprovider.DriftReason{areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
-		return i != ""
-	}); reason != "" {
-		return reason, nil
+	staticReasons := lo.Filter(areStaticFieldsDrifted(nodePool, nodeClaim), func(r cloudprovider.DriftReason, _ int) bool {
+		return lo.FromPtrOr(checks.Taints, true) || (r != TaintsDrifted && r != StartupTaintsDrifted)
+	})
+	requirementsReason := lo.Ternary(lo.FromPtrOr(checks.Requirements, true), areRequirementsDrifted(nodePool, nodeClaim), "")
+	if len(staticReasons) != 0 {
+		return NodePoolDrifted, append(staticReasons, lo.Ternary(requirementsReason != "", []cloudprovider.DriftReason{requirementsReason}, nil)...), nil
+	}
+	if requirementsReason != "" {
+		return requirementsReason, []cloudprovider.DriftReason{requirementsReason}, nil
 	}
 	driftedReason, err := d.cloudProvider.IsDrifted(ctx, nodeClaim)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	return driftedReason, nil
+	if driftedReason == "" ||
+		(driftedReason == cloudprovider.NodeImageDrifted && !lo.FromPtrOr(checks.NodeImage, true)) ||
+		(driftedReason == cloudprovider.NodeClassHashDrifted && !lo.FromPtrOr(checks.NodeClassHash, true)) {
+		return "", nil, nil
+	}
+	return driftedReason, []cloudprovider.DriftReason{driftedReason}, nil
 }
 
 // Eligible fields for static drift are described in the docs
 // https://karpenter.sh/docs/concepts/deprovisioning/#drift
-func areStaticFieldsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) cloudprovider.DriftReason {
+func areStaticFieldsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) []cloudprovider.DriftReason {
 	nodePoolHash, foundHashNodePool := nodePool.Annotations[v1beta1.NodePoolHashAnnotationKey]
 	nodeClaimHash, foundHashNodeClaim := nodeClaim.Annotations[v1beta1.NodePoolHashAnnotationKey]
-	if !foundHashNodePool || !foundHashNodeClaim {
-		return ""
+	if !foundHashNodePool || !foundHashNodeClaim || nodePoolHash == nodeClaimHash {
+		return nil
+	}
+	// The hash tells us that something in the template changed, so we compare the NodeClaim against the template to
+	// find out what. If none of the fields we compare changed we can still only say that the NodePool drifted.
+	if reasons := driftedStaticFields(nodePool.Spec.Template, nodeClaim); len(reasons) != 0 {
+		return reasons
+	}
+	return []cloudprovider.DriftReason{NodePoolDrifted}
+}
+
+// driftedStaticFields returns a drift reason for each static field of the template that doesn't match the NodeClaim
+func driftedStaticFields(template v1beta1.NodeClaimTemplate, nodeClaim *v1beta1.NodeClaim) []cloudprovider.DriftReason {
+	var reasons []cloudprovider.DriftReason
+	if !containsAll(nodeClaim.Labels, template.Labels) {
+		reasons = append(reasons, LabelsDrifted)
+	}
+	if !containsAll(nodeClaim.Annotations, template.Annotations) {
+		reasons = append(reasons, AnnotationsDrifted)
+	}
+	if !equality.Semantic.DeepEqual(nodeClaim.Spec.Taints, template.Spec.Taints) {
+		reasons = append(reasons, TaintsDrifted)
+	}
+	if !equality.Semantic.DeepEqual(nodeClaim.Spec.StartupTaints, template.Spec.StartupTaints) {
+		reasons = append(reasons, StartupTaintsDrifted)
+	}
+	if kubeletReasons := driftedKubeletFields(nodeClaim.Spec.Kubelet, template.Spec.Kubelet); len(kubeletReasons) != 0 {
+		reasons = append(append(reasons, KubeletDrifted), kubeletReasons...)
+	}
+	if !equality.Semantic.DeepEqual(nodeClaim.Spec.NodeClassRef, template.Spec.NodeClassRef) {
+		reasons = append(reasons, NodeClassRefDrifted)
+	}
+	if !equality.Semantic.DeepEqual(nodeClaim.Spec.TerminationGracePeriod, template.Spec.TerminationGracePeriod) {
+		reasons = append(reasons, TerminationGracePeriodDrifted)
+	}
+	return reasons
+}
+
+// driftedKubeletFields returns a drift reason for each field of the kubelet configuration that doesn't match, e.g.
+// KubeletMaxPodsDrifted, so that a kubelet configuration rollout reports which of the fields it changed. A kubelet
+// configuration that isn't set is the same as one without any fields set.
+func driftedKubeletFields(nodeClaim, template *v1beta1.KubeletConfiguration) []cloudprovider.DriftReason {
+	current, desired := reflect.ValueOf(lo.FromPtr(nodeClaim)), reflect.ValueOf(lo.FromPtr(template))
+	var reasons []cloudprovider.DriftReason
+	for i := 0; i < current.NumField(); i++ {
+		if !equality.Semantic.DeepEqual(current.Field(i).Interface(), desired.Field(i).Interface()) {
+			reasons = append(reasons, cloudprovider.DriftReason(fmt.Sprintf("Kubelet%sDrifted", current.Type().Field(i).Name)))
+		}
+	}
+	return reasons
+}
+
+// containsAll returns true if every key and value in subset is also in superset
+func containsAll(superset, subset map[string]string) bool {
+	for k, v := range subset {
+		if value, ok := superset[k]; !ok || value != v {
+			return false
+		}
 	}
-	return lo.Ternary(nodePoolHash != nodeClaimHash, NodePoolDrifted, "")
+	return true
 }
 
 func areRequirementsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) cloudprovider.DriftReason {
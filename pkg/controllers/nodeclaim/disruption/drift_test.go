@@ -17,13 +17,17 @@ limitations under the License.
 package disruption_test
 
 import (
+	"time"
+
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/disruption"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/hash"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
@@ -214,6 +218,228 @@ var _ = Describe("Drift", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
 	})
+	It("should requeue drift evaluation after the nodePool's driftCheckInterval", func() {
+		cp.Drifted = "drifted"
+		nodePool.Spec.Disruption.DriftCheckInterval = &metav1.Duration{Duration: time.Second * 30}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		result := ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(result.RequeueAfter).To(Equal(time.Second * 30))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).IsTrue()).To(BeTrue())
+	})
+	Context("Drifted Reasons", func() {
+		It("should record the cloudprovider drift reason", func() {
+			cp.Drifted = "CloudProviderDrifted"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf("CloudProviderDrifted"))
+			Expect(recorder.Calls("Drifted")).To(Equal(1))
+		})
+		It("should record the static fields that drifted", func() {
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(10)}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(disruption.NodePoolDrifted)))
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(string(disruption.KubeletDrifted), "KubeletMaxPodsDrifted"))
+			metric, found := FindMetricWithLabelValues("karpenter_nodeclaims_drifted_reasons", map[string]string{
+				"reason":   string(disruption.KubeletDrifted),
+				"nodepool": nodePool.Name,
+			})
+			Expect(found).To(BeTrue())
+			Expect(metric.GetCounter().GetValue()).To(BeNumerically(">=", 1))
+		})
+		It("should record each of the kubelet fields that drifted", func() {
+			nodeClaim.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+				MaxPods:      ptr.Int32(10),
+				EvictionHard: map[string]string{"memory.available": "5%"},
+				ClusterDNS:   []string{"10.0.0.10"},
+			}
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+				MaxPods:        ptr.Int32(20),
+				EvictionHard:   map[string]string{"memory.available": "10%"},
+				SystemReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				ClusterDNS:     []string{"10.0.0.10"},
+			}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(disruption.NodePoolDrifted)))
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(
+				string(disruption.KubeletDrifted),
+				"KubeletMaxPodsDrifted",
+				"KubeletEvictionHardDrifted",
+				"KubeletSystemReservedDrifted",
+			))
+		})
+		It("should not consider an empty kubelet configuration drifted from an unset one", func() {
+			nodeClaim.Spec.Kubelet = nil
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{}
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "drifted", Effect: v1.TaintEffectNoSchedule}}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(string(disruption.TaintsDrifted)))
+		})
+		It("should record requirements drift alongside static drift", func() {
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1.LabelInstanceTypeStable,
+						Operator: v1.NodeSelectorOpDoesNotExist,
+					},
+				},
+			}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(string(disruption.NodePoolDrifted), string(disruption.RequirementsDrifted)))
+		})
+		It("should only publish an event when the drifted reasons change", func() {
+			cp.Drifted = "CloudProviderDrifted"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(recorder.Calls("Drifted")).To(Equal(1))
+
+			cp.Drifted = "OtherCloudProviderDrifted"
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf("OtherCloudProviderDrifted"))
+			Expect(recorder.Calls("Drifted")).To(Equal(2))
+		})
+		It("should clear the drifted reasons when the nodeClaim is no longer drifted", func() {
+			cp.Drifted = "CloudProviderDrifted"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Status.DriftedReasons).ToNot(BeEmpty())
+
+			cp.Drifted = ""
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
+			Expect(nodeClaim.Status.DriftedReasons).To(BeEmpty())
+		})
+	})
+	Context("Drift Checks", func() {
+		It("should ignore taints drift when the nodePool disables the taints check", func() {
+			nodeClaim.Spec.Kubelet = nil
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "drifted", Effect: v1.TaintEffectNoSchedule}}
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{Taints: lo.ToPtr(false)}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
+		})
+		It("should detect the static drift of other fields when the nodePool disables the taints check", func() {
+			nodeClaim.Spec.Kubelet = nil
+			nodePool.Spec.Template.Spec.NodeClassRef = nodeClaim.Spec.NodeClassRef
+			nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(10)}
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "drifted", Effect: v1.TaintEffectNoSchedule}}
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{Taints: lo.ToPtr(false)}
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey: "123456789",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(disruption.NodePoolDrifted)))
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(string(disruption.KubeletDrifted), "KubeletMaxPodsDrifted"))
+		})
+		It("should fall back to cloud provider drift when the nodePool disables the requirements check", func() {
+			cp.Drifted = cloudprovider.NodeImageDrifted
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1.LabelInstanceTypeStable,
+						Operator: v1.NodeSelectorOpDoesNotExist,
+					},
+				},
+			}
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{Requirements: lo.ToPtr(false)}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(cloudprovider.NodeImageDrifted)))
+			Expect(nodeClaim.Status.DriftedReasons).To(ConsistOf(string(cloudprovider.NodeImageDrifted)))
+		})
+		It("should ignore node image drift when the nodePool disables the node image check", func() {
+			cp.Drifted = cloudprovider.NodeImageDrifted
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{NodeImage: lo.ToPtr(false)}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
+
+			// Other cloud provider drift is still detected
+			cp.Drifted = cloudprovider.NodeClassHashDrifted
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(cloudprovider.NodeClassHashDrifted)))
+		})
+		It("should ignore nodeclass hash drift when the nodePool disables the nodeclass hash check", func() {
+			cp.Drifted = cloudprovider.NodeClassHashDrifted
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{NodeClassHash: lo.ToPtr(false)}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
+
+			// Node image drift is still detected
+			cp.Drifted = cloudprovider.NodeImageDrifted
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).Reason).To(Equal(string(cloudprovider.NodeImageDrifted)))
+		})
+		It("should remove the drifted status condition when the nodePool disables the check that drifted", func() {
+			cp.Drifted = cloudprovider.NodeImageDrifted
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).IsTrue()).To(BeTrue())
+
+			nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{NodeImage: lo.ToPtr(false)}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted)).To(BeNil())
+			Expect(nodeClaim.Status.DriftedReasons).To(BeEmpty())
+		})
+	})
 	Context("NodeRequirement Drift", func() {
 		DescribeTable("",
 			func(oldNodePoolReq []v1beta1.NodeSelectorRequirementWithMinValues, newNodePoolReq []v1beta1.NodeSelectorRequirementWithMinValues, labels map[string]string, drifted bool) {
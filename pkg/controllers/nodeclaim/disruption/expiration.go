@@ -18,6 +18,8 @@ package disruption
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
@@ -29,9 +31,13 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	nodeutil "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutil "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
-// Expiration is a nodeclaim sub-controller that adds or removes status conditions on expired nodeclaims based on TTLSecondsUntilExpired
+// Expiration is a nodeclaim sub-controller that adds or removes status conditions on expired nodeclaims based on the
+// expireAfter of their nodepool and the lifetimes requested by the pods on their node
 type Expiration struct {
 	kubeClient client.Client
 	clock      clock.Clock
@@ -40,16 +46,20 @@ type Expiration struct {
 func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
 	hasExpiredCondition := nodeClaim.StatusConditions().GetCondition(v1beta1.Expired) != nil
 
+	expireAfter, err := e.expireAfter(ctx, nodePool, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 	// From here there are three scenarios to handle:
-	// 1. If ExpireAfter is not configured, remove the expired status condition
-	if nodePool.Spec.Disruption.ExpireAfter.Duration == nil {
+	// 1. If ExpireAfter is not configured by the nodepool or the pods on the node, remove the expired status condition
+	if expireAfter == nil {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Expired)
 		if hasExpiredCondition {
 			logging.FromContext(ctx).Debugf("removing expiration status condition, expiration has been disabled")
 		}
 		return reconcile.Result{}, nil
 	}
-	expirationTime := nodeClaim.CreationTimestamp.Add(*nodePool.Spec.Disruption.ExpireAfter.Duration)
+	expirationTime := nodeClaim.CreationTimestamp.Add(*expireAfter)
 	// 2. If the NodeClaim isn't expired, remove the status condition.
 	if e.clock.Now().Before(expirationTime) {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Expired)
@@ -67,7 +77,7 @@ func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool,
 		Severity: apis.ConditionSeverityWarning,
 	})
 	if !hasExpiredCondition {
-		logging.FromContext(ctx).Debugf("marking expired")
+		logging.FromContext(ctx).With("ttl", expireAfter.String()).Debugf("marking expired")
 		metrics.NodeClaimsDisruptedCounter.With(prometheus.Labels{
 			metrics.TypeLabel:     metrics.ExpirationReason,
 			metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
@@ -75,3 +85,35 @@ func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool,
 	}
 	return reconcile.Result{}, nil
 }
+
+// expireAfter returns the lifetime of the NodeClaim, which is the shortest of its nodepool's expireAfter and the
+// lifetimes that the pods on its node request through the karpenter.sh/expire-after annotation
+func (e *Expiration) expireAfter(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (*time.Duration, error) {
+	expireAfter := nodePool.Spec.Disruption.ExpireAfter.Duration
+	n, err := nodeclaimutil.NodeForNodeClaim(ctx, e.kubeClient, nodeClaim)
+	if err != nil {
+		if nodeclaimutil.IsDuplicateNodeError(err) || nodeclaimutil.IsNodeNotFoundError(err) {
+			return expireAfter, nil
+		}
+		return nil, err
+	}
+	pods, err := nodeutil.GetPods(ctx, e.kubeClient, n)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving node pods, %w", err)
+	}
+	for _, pod := range pods {
+		value, ok := pod.Annotations[v1beta1.ExpireAfterAnnotationKey]
+		if !ok || podutil.IsTerminal(pod) {
+			continue
+		}
+		lifetime, err := time.ParseDuration(value)
+		if err != nil || lifetime <= 0 {
+			logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pod)).Debugf("ignoring invalid %s annotation %q", v1beta1.ExpireAfterAnnotationKey, value)
+			continue
+		}
+		if expireAfter == nil || lifetime < *expireAfter {
+			expireAfter = &lifetime
+		}
+	}
+	return expireAfter, nil
+}
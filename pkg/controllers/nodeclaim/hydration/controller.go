@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hydration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+)
+
+// launchGracePeriod is how long we wait after an instance is created before considering it orphaned. Instances that
+// were just launched may not have their provider id on their NodeClaim yet.
+const launchGracePeriod = time.Minute
+
+// Controller recreates NodeClaims for Karpenter-owned instances in the CloudProvider that no longer have a NodeClaim
+// (e.g. after an etcd restore). It runs once on startup so that these instances are tracked, disrupted, and
+// garbage collected like any other capacity rather than being leaked.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	hydrated      bool
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) operatorcontroller.Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "nodeclaim.hydration"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	// Hydration only needs to happen once per process, after that there's nothing left for us to do
+	if c.hydrated {
+		return reconcile.Result{RequeueAfter: time.Hour}, nil
+	}
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList); err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	providerIDs := sets.New(lo.Map(nodeClaimList.Items, func(n v1beta1.NodeClaim, _ int) string { return n.Status.ProviderID })...)
+	names := sets.New(lo.Map(nodeClaimList.Items, func(n v1beta1.NodeClaim, _ int) string { return n.Name })...)
+
+	cloudProviderNodeClaims, err := c.cloudProvider.List(ctx)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing cloudprovider nodeclaims, %w", err)
+	}
+	orphaned := lo.Filter(cloudProviderNodeClaims, func(n *v1beta1.NodeClaim, _ int) bool {
+		return n.DeletionTimestamp.IsZero() &&
+			n.Labels[v1beta1.NodePoolLabelKey] != "" &&
+			n.Status.ProviderID != "" &&
+			!providerIDs.Has(n.Status.ProviderID) &&
+			(n.Name == "" || !names.Has(n.Name)) &&
+			c.clock.Since(n.CreationTimestamp.Time) > launchGracePeriod
+	})
+
+	errs := make([]error, len(orphaned))
+	workqueue.ParallelizeUntil(ctx, 20, len(orphaned), func(i int) {
+		errs[i] = c.hydrate(ctx, orphaned[i])
+	})
+	if err = multierr.Combine(errs...); err != nil {
+		return reconcile.Result{}, err
+	}
+	c.hydrated = true
+	return reconcile.Result{RequeueAfter: time.Hour}, nil
+}
+
+// hydrate creates a NodeClaim for an orphaned instance from its NodePool's template. The lifecycle controller
+// recognizes the hydrated provider id and fills in the NodeClaim's status from the existing instance instead of
+// launching a new one.
+func (c *Controller) hydrate(ctx context.Context, retrieved *v1beta1.NodeClaim) error {
+	nodePool := &v1beta1.NodePool{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: retrieved.Labels[v1beta1.NodePoolLabelKey]}, nodePool); err != nil {
+		// Without a NodePool there's no template to hydrate from, so we leave the instance to the cloudprovider
+		if client.IgnoreNotFound(err) == nil {
+			logging.FromContext(ctx).With("provider-id", retrieved.Status.ProviderID, "nodepool", retrieved.Labels[v1beta1.NodePoolLabelKey]).
+				Debugf("skipping hydration of instance, nodepool not found")
+			return nil
+		}
+		return fmt.Errorf("getting nodepool, %w", err)
+	}
+	nodeClaim := &v1beta1.NodeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         retrieved.Name,
+			GenerateName: lo.Ternary(retrieved.Name == "", fmt.Sprintf("%s-", nodePool.Name), ""),
+			Labels: lo.Assign(nodePool.Spec.Template.Labels, retrieved.Labels, map[string]string{
+				v1beta1.NodePoolLabelKey: nodePool.Name,
+			}),
+			Annotations: lo.Assign(nodePool.Spec.Template.Annotations, map[string]string{
+				v1beta1.NodePoolHashAnnotationKey:       nodePool.Hash(),
+				v1beta1.HydratedProviderIDAnnotationKey: retrieved.Status.ProviderID,
+			}),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         v1beta1.SchemeGroupVersion.String(),
+					Kind:               "NodePool",
+					Name:               nodePool.Name,
+					UID:                nodePool.UID,
+					BlockOwnerDeletion: ptr.Bool(true),
+				},
+			},
+		},
+		Spec: *nodePool.Spec.Template.Spec.DeepCopy(),
+	}
+	if err := c.kubeClient.Create(ctx, nodeClaim); client.IgnoreAlreadyExists(err) != nil {
+		return fmt.Errorf("creating nodeclaim, %w", err)
+	}
+	logging.FromContext(ctx).With("nodeclaim", nodeClaim.Name, "provider-id", retrieved.Status.ProviderID, "nodepool", nodePool.Name).
+		Infof("hydrated nodeclaim for orphaned instance")
+	return nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
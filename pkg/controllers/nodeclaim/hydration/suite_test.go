@@ -0,0 +1,152 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hydration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/hydration"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var hydrationController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hydration")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Hydration", func() {
+	var nodePool *v1beta1.NodePool
+	var instance *v1beta1.NodeClaim
+
+	BeforeEach(func() {
+		// Hydration only happens once for each controller, so each test gets a fresh one
+		hydrationController = hydration.NewController(fakeClock, env.Client, cloudProvider)
+		nodePool = test.NodePool()
+		instance = &v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              test.RandomName(),
+				CreationTimestamp: metav1.Time{Time: fakeClock.Now().Add(-time.Hour)},
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: "default-instance-type",
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		}
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		fakeClock.SetTime(time.Now())
+		cloudProvider.Reset()
+	})
+
+	It("should hydrate a nodeclaim for an orphaned instance", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+
+		nodeClaim := ExpectExists(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1beta1.HydratedProviderIDAnnotationKey, instance.Status.ProviderID))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "default-instance-type"))
+		Expect(nodeClaim.Spec.NodeClassRef).To(Equal(nodePool.Spec.Template.Spec.NodeClassRef))
+		Expect(nodeClaim.OwnerReferences).To(HaveLen(1))
+		Expect(nodeClaim.OwnerReferences[0].Name).To(Equal(nodePool.Name))
+	})
+	It("should not hydrate instances that are tracked by a nodeclaim", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Status:     v1beta1.NodeClaimStatus{ProviderID: instance.Status.ProviderID},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectApplied(ctx, env.Client, nodeClaim) // update the status
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+	})
+	It("should not hydrate instances that were just launched", func() {
+		instance.CreationTimestamp = metav1.Time{Time: fakeClock.Now()}
+		ExpectApplied(ctx, env.Client, nodePool)
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+	})
+	It("should not hydrate instances that aren't owned by a nodepool", func() {
+		delete(instance.Labels, v1beta1.NodePoolLabelKey)
+		ExpectApplied(ctx, env.Client, nodePool)
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+	})
+	It("should not hydrate instances whose nodepool doesn't exist", func() {
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+	})
+	It("should only hydrate on startup", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+		result := ExpectReconcileSucceeded(ctx, hydrationController, client.ObjectKey{})
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		ExpectNotFound(ctx, env.Client, &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: instance.Name}})
+	})
+})
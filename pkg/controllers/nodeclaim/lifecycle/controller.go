@@ -40,6 +40,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
 	nodeclaimutil "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
@@ -62,16 +63,18 @@ type Controller struct {
 	launch         *Launch
 	registration   *Registration
 	initialization *Initialization
+	warmPool       *WarmPool
 	liveness       *Liveness
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) operatorcontroller.Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) operatorcontroller.Controller {
 	return operatorcontroller.Typed[*v1beta1.NodeClaim](kubeClient, &Controller{
 		kubeClient: kubeClient,
 
-		launch:         &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder},
+		launch:         &Launch{kubeClient: kubeClient, cluster: cluster, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder, inflight: newInflightLaunches(), batches: newLaunchBatches()},
 		registration:   &Registration{kubeClient: kubeClient},
-		initialization: &Initialization{kubeClient: kubeClient},
+		initialization: &Initialization{clock: clk, kubeClient: kubeClient},
+		warmPool:       &WarmPool{kubeClient: kubeClient, cloudProvider: cloudProvider},
 		liveness:       &Liveness{clock: clk, kubeClient: kubeClient},
 	})
 }
@@ -98,6 +101,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim
 		c.launch,
 		c.registration,
 		c.initialization,
+		c.warmPool,
 		c.liveness,
 	} {
 		res, err := reconciler.Reconcile(ctx, nodeClaim)
@@ -134,7 +138,10 @@ func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontr
 		For(&v1beta1.NodeClaim{}, builder.WithPredicates(
 			predicate.Funcs{
 				CreateFunc: func(e event.CreateEvent) bool { return true },
-				UpdateFunc: func(e event.UpdateEvent) bool { return false },
+				// NodeClaims that are claimed from a warm pool need to be started
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return e.ObjectOld.GetAnnotations()[v1beta1.WarmPoolAnnotationKey] != e.ObjectNew.GetAnnotations()[v1beta1.WarmPoolAnnotationKey]
+				},
 				DeleteFunc: func(e event.DeleteEvent) bool { return false },
 			},
 		)).
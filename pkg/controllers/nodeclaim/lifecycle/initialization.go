@@ -19,11 +19,14 @@ package lifecycle
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -36,7 +39,12 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
+// readinessGateRequeueInterval is how often the readiness gates of a NodeClaim are checked while one of them isn't met,
+// since the readiness of daemonset pods doesn't trigger the NodeClaim's reconciliation
+const readinessGateRequeueInterval = 10 * time.Second
+
 type Initialization struct {
+	clock      clock.Clock
 	kubeClient client.Client
 }
 
@@ -44,6 +52,8 @@ type Initialization struct {
 // a) its current status is set to Ready
 // b) all the startup taints have been removed from the node
 // c) all extended resources have been registered
+// d) all device plugin resources that the instance type has have been registered
+// e) all the readiness gates have been met
 // This method handles both nil nodepools and nodes without extended resources gracefully.
 func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
 	if nodeClaim.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue() {
@@ -76,6 +86,18 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeC
 		nodeClaim.StatusConditions().MarkFalse(v1beta1.Initialized, "ResourceNotRegistered", "Resource %q was requested but not registered", name)
 		return reconcile.Result{}, nil
 	}
+	if name, ok := DevicePluginResourcesRegistered(node, nodeClaim); !ok {
+		nodeClaim.StatusConditions().MarkFalse(v1beta1.Initialized, "ResourceNotRegistered", "Device plugin resource %q was not registered", name)
+		return reconcile.Result{}, nil
+	}
+	gate, err := i.unmetReadinessGate(ctx, node, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if gate != nil {
+		nodeClaim.StatusConditions().MarkFalse(v1beta1.Initialized, "ReadinessGateNotMet", "Readiness gate %s is not met", formatReadinessGate(gate))
+		return i.readinessGateTimeout(ctx, nodeClaim, gate)
+	}
 	stored := node.DeepCopy()
 	node.Labels = lo.Assign(node.Labels, map[string]string{v1beta1.NodeInitializedLabelKey: "true"})
 	if !equality.Semantic.DeepEqual(stored, node) {
@@ -147,3 +169,83 @@ func formatTaint(taint *v1.Taint) string {
 	}
 	return fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect)
 }
+
+// DevicePluginResourcesRegistered returns true if the device plugins have registered every device plugin resource that
+// the NodeClaim's instance type has. Resources that the instance type doesn't have aren't waited on, so that one set
+// of device plugin resources can be used for a nodepool of mixed instance types.
+func DevicePluginResourcesRegistered(node *v1.Node, nodeClaim *v1beta1.NodeClaim) (v1.ResourceName, bool) {
+	for _, resourceName := range nodeClaim.Spec.DevicePluginResources {
+		if resources.IsZero(nodeClaim.Status.Allocatable[resourceName]) {
+			continue
+		}
+		if resources.IsZero(node.Status.Allocatable[resourceName]) {
+			return resourceName, false
+		}
+	}
+	return "", true
+}
+
+// unmetReadinessGate returns the first readiness gate of the NodeClaim that its node doesn't meet, or nil if the node
+// meets all of them
+func (i *Initialization) unmetReadinessGate(ctx context.Context, node *v1.Node, nodeClaim *v1beta1.NodeClaim) (*v1beta1.ReadinessGate, error) {
+	var pods []*v1.Pod
+	for idx := range nodeClaim.Spec.ReadinessGates {
+		gate := &nodeClaim.Spec.ReadinessGates[idx]
+		if gate.ConditionType != "" && nodeutil.GetCondition(node, gate.ConditionType).Status != v1.ConditionTrue {
+			return gate, nil
+		}
+		if gate.DaemonSet == nil {
+			continue
+		}
+		// The node's pods are only listed once, and only if the NodeClaim has a daemonset gate
+		if pods == nil {
+			var err error
+			if pods, err = nodeutil.GetPods(ctx, i.kubeClient, node); err != nil {
+				return nil, fmt.Errorf("listing pods on node, %w", err)
+			}
+		}
+		if !lo.ContainsBy(pods, func(p *v1.Pod) bool { return isReadyDaemonSetPod(p, gate.DaemonSet) }) {
+			return gate, nil
+		}
+	}
+	return nil, nil
+}
+
+// readinessGateTimeout deletes the NodeClaim if its node hasn't met the readiness gate within the gate's timeout since
+// the node registered. Otherwise, it requeues the NodeClaim so that the gate is checked again.
+func (i *Initialization) readinessGateTimeout(ctx context.Context, nodeClaim *v1beta1.NodeClaim, gate *v1beta1.ReadinessGate) (reconcile.Result, error) {
+	registered := nodeClaim.StatusConditions().GetCondition(v1beta1.Registered)
+	if gate.Timeout == nil || registered == nil {
+		return reconcile.Result{RequeueAfter: readinessGateRequeueInterval}, nil
+	}
+	if remaining := gate.Timeout.Duration - i.clock.Since(registered.LastTransitionTime.Inner.Time); remaining > 0 {
+		return reconcile.Result{RequeueAfter: lo.Min([]time.Duration{remaining, readinessGateRequeueInterval})}, nil
+	}
+	if err := i.kubeClient.Delete(ctx, nodeClaim); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	logging.FromContext(ctx).With("readiness-gate", formatReadinessGate(gate), "timeout", gate.Timeout.Duration).Debugf("terminating due to readiness gate timeout")
+	metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
+		metrics.ReasonLabel:       "readiness_gate_timeout",
+		metrics.NodePoolLabel:     nodeClaim.Labels[v1beta1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: nodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
+	}).Inc()
+	return reconcile.Result{}, nil
+}
+
+func isReadyDaemonSetPod(pod *v1.Pod, daemonSet *v1beta1.DaemonSetReference) bool {
+	owner := metav1.GetControllerOf(pod)
+	if pod.Namespace != daemonSet.Namespace || owner == nil || owner.Kind != "DaemonSet" || owner.Name != daemonSet.Name {
+		return false
+	}
+	return lo.ContainsBy(pod.Status.Conditions, func(c v1.PodCondition) bool {
+		return c.Type == v1.PodReady && c.Status == v1.ConditionTrue
+	})
+}
+
+func formatReadinessGate(gate *v1beta1.ReadinessGate) string {
+	if gate.DaemonSet != nil {
+		return fmt.Sprintf("daemonset %s/%s", gate.DaemonSet.Namespace, gate.DaemonSet.Name)
+	}
+	return fmt.Sprintf("condition %s", gate.ConditionType)
+}
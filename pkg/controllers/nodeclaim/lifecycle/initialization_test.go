@@ -17,6 +17,9 @@ limitations under the License.
 package lifecycle_test
 
 import (
+	"time"
+
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -286,6 +289,216 @@ var _ = Describe("Initialization", func() {
 		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Registered).Status).To(Equal(v1.ConditionTrue))
 		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionTrue))
 	})
+	It("should not consider the Node to be initialized until the device plugin resources are registered", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				// None of the pods that the nodeClaim was launched for requested the device plugin resource
+				Resources: v1beta1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:  resource.MustParse("2"),
+						v1.ResourcePods: resource.MustParse("5"),
+					},
+				},
+				DevicePluginResources: []v1.ResourceName{fake.ResourceGPUVendorA},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		// Update the nodeClaim to add mock the instance type having an extended resource
+		nodeClaim.Status.Capacity[fake.ResourceGPUVendorA] = resource.MustParse("2")
+		nodeClaim.Status.Allocatable[fake.ResourceGPUVendorA] = resource.MustParse("2")
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		// Extended resource hasn't registered yet by the device plugin
+		node := test.Node(test.NodeOptions{
+			ProviderID: nodeClaim.Status.ProviderID,
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("10"),
+				v1.ResourcePods: resource.MustParse("110"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("8"),
+				v1.ResourcePods: resource.MustParse("110"),
+			},
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectMakeNodesReady(ctx, env.Client, node) // Remove the not-ready taint
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionFalse))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Reason).To(Equal("ResourceNotRegistered"))
+
+		// Node now registers the resource
+		node = ExpectExists(ctx, env.Client, node)
+		node.Status.Capacity[fake.ResourceGPUVendorA] = resource.MustParse("2")
+		node.Status.Allocatable[fake.ResourceGPUVendorA] = resource.MustParse("2")
+		ExpectApplied(ctx, env.Client, node)
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should not wait for device plugin resources that the instance type doesn't have", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				DevicePluginResources: []v1.ResourceName{fake.ResourceGPUVendorA},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		// Update the nodeClaim to mock the instance type not having the extended resource
+		delete(nodeClaim.Status.Capacity, fake.ResourceGPUVendorA)
+		delete(nodeClaim.Status.Allocatable, fake.ResourceGPUVendorA)
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{
+			ProviderID: nodeClaim.Status.ProviderID,
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("10"),
+				v1.ResourcePods: resource.MustParse("110"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("8"),
+				v1.ResourcePods: resource.MustParse("110"),
+			},
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectMakeNodesReady(ctx, env.Client, node) // Remove the not-ready taint
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should not consider the Node to be initialized until its readiness gate conditions are True", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				ReadinessGates: []v1beta1.ReadinessGate{{ConditionType: "example.com/NetworkReady"}},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectMakeNodesReady(ctx, env.Client, node) // Remove the not-ready taint
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Registered).Status).To(Equal(v1.ConditionTrue))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionFalse))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Reason).To(Equal("ReadinessGateNotMet"))
+
+		node = ExpectExists(ctx, env.Client, node)
+		node.Status.Conditions = append(node.Status.Conditions, v1.NodeCondition{Type: "example.com/NetworkReady", Status: v1.ConditionTrue})
+		ExpectApplied(ctx, env.Client, node)
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should not consider the Node to be initialized until its readiness gate daemonset pods are Ready", func() {
+		daemonSet := test.DaemonSet()
+		ExpectApplied(ctx, env.Client, daemonSet)
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				ReadinessGates: []v1beta1.ReadinessGate{{DaemonSet: &v1beta1.DaemonSetReference{Namespace: daemonSet.Namespace, Name: daemonSet.Name}}},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectMakeNodesReady(ctx, env.Client, node) // Remove the not-ready taint
+
+		pod := test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: daemonSet.Namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         "apps/v1",
+					Kind:               "DaemonSet",
+					Name:               daemonSet.Name,
+					UID:                daemonSet.UID,
+					Controller:         lo.ToPtr(true),
+					BlockOwnerDeletion: lo.ToPtr(true),
+				}},
+			},
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}},
+		})
+		ExpectApplied(ctx, env.Client, pod)
+
+		result := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionFalse))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Reason).To(Equal("ReadinessGateNotMet"))
+
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		ExpectApplied(ctx, env.Client, pod)
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Initialized).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should delete the nodeClaim when its readiness gate isn't met within the gate's timeout", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				ReadinessGates: []v1beta1.ReadinessGate{{ConditionType: "example.com/NetworkReady", Timeout: &metav1.Duration{Duration: 10 * time.Minute}}},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectMakeNodesReady(ctx, env.Client, node) // Remove the not-ready taint
+
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 5)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 6)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
 	It("should not consider the Node to be initialized when all startupTaints aren't removed", func() {
 		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
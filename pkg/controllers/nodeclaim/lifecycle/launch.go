@@ -19,10 +19,13 @@ package lifecycle
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/patrickmn/go-cache"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,16 +33,25 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 )
 
+// launchThrottledRequeueInterval is how long a NodeClaim waits to launch again when its NodePool is already at
+// its maxConcurrentLaunches limit
+const launchThrottledRequeueInterval = time.Second
+
 type Launch struct {
 	kubeClient    client.Client
+	cluster       *state.Cluster
 	cloudProvider cloudprovider.CloudProvider
 	cache         *cache.Cache // exists due to eventual consistency on the cache
 	recorder      events.Recorder
+	inflight      *inflightLaunches
+	batches       *launchBatches
 }
 
 func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
@@ -53,18 +65,61 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (r
 	// One of the following scenarios can happen with a NodeClaim that isn't marked as launched:
 	//  1. It was already launched by the CloudProvider but the client-go cache wasn't updated quickly enough or
 	//     patching failed on the status. In this case, we use the in-memory cached value for the created NodeClaim.
-	//  2. It is a standard NodeClaim launch where we should call CloudProvider Create() and fill in details of the launched
+	//  2. It was hydrated from an instance that already exists in the CloudProvider. In this case, we retrieve the
+	//     instance rather than launching a new one.
+	//  3. It is a standard NodeClaim launch where we should call CloudProvider Create() and fill in details of the launched
 	//     NodeClaim into the NodeClaim CR.
 	if ret, ok := l.cache.Get(string(nodeClaim.UID)); ok {
 		created = ret.(*v1beta1.NodeClaim)
+	} else if providerID, ok := nodeClaim.Annotations[v1beta1.HydratedProviderIDAnnotationKey]; ok {
+		created, err = l.getHydratedNodeClaim(ctx, nodeClaim, providerID)
 	} else {
+		nodePoolName := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+		maxLaunches, maxErr := l.maxConcurrentLaunches(ctx, nodePoolName)
+		if maxErr != nil {
+			return reconcile.Result{}, maxErr
+		}
+		// Launches for a nodepool back off after they fail, so that we don't hot-loop on a nodepool that can't launch
+		if backoff := l.cluster.LaunchBackoff(nodePoolName); backoff > 0 {
+			logging.FromContext(ctx).With("backoff", backoff).Debugf("waiting to launch nodeclaim, nodepool's recent launches failed")
+			return reconcile.Result{RequeueAfter: backoff}, nil
+		}
+		if !l.inflight.tryAcquire(nodePoolName, maxLaunches) {
+			logging.FromContext(ctx).Debugf("waiting to launch nodeclaim, nodepool is at its maxConcurrentLaunches limit")
+			return reconcile.Result{RequeueAfter: launchThrottledRequeueInterval}, nil
+		}
 		created, err = l.launchNodeClaim(ctx, nodeClaim)
+		l.inflight.release(nodePoolName)
+		retryAfter, retryable := cloudprovider.RetryAfter(err)
+		switch {
+		case cloudprovider.IsNodeClassNotReadyError(err):
+			// The nodeclass isn't ready to launch with yet, which doesn't reflect on the nodepool's health
+		case cloudprovider.IsThrottledError(err):
+			// Throttling doesn't reflect on the nodepool's health either, but the nodepool's other NodeClaims wait out
+			// the delay too rather than adding to the requests that are being throttled
+			l.cluster.DelayLaunches(nodePoolName, retryAfter)
+		case retryable:
+			l.cluster.RecordRetryableLaunchFailure(nodePoolName, retryAfter)
+		case err != nil || created == nil:
+			l.cluster.RecordLaunchFailure(nodePoolName)
+		default:
+			l.cluster.RecordLaunchSuccess(nodePoolName)
+		}
 	}
 	// Either the Node launch failed or the Node was deleted due to InsufficientCapacity/NotFound
 	if err != nil || created == nil {
 		if cloudprovider.IsNodeClassNotReadyError(err) {
 			return reconcile.Result{Requeue: true}, nil
 		}
+		// The cloudprovider knows better than our backoff when the launch can succeed
+		if retryAfter, ok := cloudprovider.RetryAfter(err); ok {
+			logging.FromContext(ctx).With("retry-after", retryAfter).Debugf("retrying launch, %s", err)
+			return reconcile.Result{RequeueAfter: retryAfter}, nil
+		}
+		// Retrying won't help, so the NodeClaim is left for liveness to clean up once it times out
+		if cloudprovider.IsTerminalError(err) {
+			return reconcile.Result{}, reconcile.TerminalError(err)
+		}
 		return reconcile.Result{}, err
 	}
 	l.cache.SetDefault(string(nodeClaim.UID), created)
@@ -77,13 +132,29 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (r
 	return reconcile.Result{}, nil
 }
 
+// createInstance launches the nodeclaim's instance, continuing the trace of the disruption that created the nodeclaim
+func (l *Launch) createInstance(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (_ *v1beta1.NodeClaim, err error) {
+	ctx, span := tracing.Start(tracing.Extract(ctx, nodeClaim), "cloudprovider.create",
+		attribute.String("nodeclaim", nodeClaim.Name),
+	)
+	defer func() { tracing.End(span, err) }()
+	// NodeClaims without a nodepool aren't launched together with any other NodeClaims
+	if batchCreator, ok := cloudprovider.As[cloudprovider.BatchCreator](l.cloudProvider); ok && nodeClaim.Labels[v1beta1.NodePoolLabelKey] != "" {
+		return l.batches.create(ctx, batchCreator, nodeClaim)
+	}
+	return l.cloudProvider.Create(ctx, nodeClaim)
+}
+
 func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
-	created, err := l.cloudProvider.Create(ctx, nodeClaim)
+	created, err := l.createInstance(ctx, nodeClaim)
 	if err != nil {
 		switch {
 		case cloudprovider.IsInsufficientCapacityError(err):
 			l.recorder.Publish(InsufficientCapacityErrorEvent(nodeClaim, err))
 			logging.FromContext(ctx).Error(err)
+			// The provisioner falls back to other nodepools while this one is out of capacity, and is triggered again
+			// by the deletion so that the pods don't wait to be retried
+			l.cluster.RecordInsufficientCapacity(nodeClaim.Labels[v1beta1.NodePoolLabelKey])
 			if err = l.kubeClient.Delete(ctx, nodeClaim); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
@@ -111,6 +182,32 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1beta1.NodeCla
 	return created, nil
 }
 
+// maxConcurrentLaunches returns the NodePool's limit on in-flight launches, or zero if launches are unbounded
+func (l *Launch) maxConcurrentLaunches(ctx context.Context, nodePoolName string) (int, error) {
+	if nodePoolName == "" {
+		return 0, nil
+	}
+	nodePool := &v1beta1.NodePool{}
+	if err := l.kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		return 0, client.IgnoreNotFound(fmt.Errorf("getting nodepool, %w", err))
+	}
+	return int(lo.FromPtr(nodePool.Spec.MaxConcurrentLaunches)), nil
+}
+
+func (l *Launch) getHydratedNodeClaim(ctx context.Context, nodeClaim *v1beta1.NodeClaim, providerID string) (*v1beta1.NodeClaim, error) {
+	retrieved, err := l.cloudProvider.Get(ctx, providerID)
+	if err != nil {
+		// The instance that we hydrated from is gone, so there's nothing for this NodeClaim to track
+		if cloudprovider.IsNodeClaimNotFoundError(err) {
+			logging.FromContext(ctx).With("provider-id", providerID).Infof("deleting hydrated nodeclaim, instance no longer exists")
+			return nil, client.IgnoreNotFound(l.kubeClient.Delete(ctx, nodeClaim))
+		}
+		return nil, fmt.Errorf("getting hydrated nodeclaim, %w", err)
+	}
+	logging.FromContext(ctx).With("provider-id", retrieved.Status.ProviderID).Infof("hydrated nodeclaim from existing instance")
+	return retrieved, nil
+}
+
 func PopulateNodeClaimDetails(nodeClaim, retrieved *v1beta1.NodeClaim) *v1beta1.NodeClaim {
 	// These are ordered in priority order so that user-defined nodeClaim labels and requirements trump retrieved labels
 	// or the static nodeClaim labels
@@ -127,6 +224,36 @@ func PopulateNodeClaimDetails(nodeClaim, retrieved *v1beta1.NodeClaim) *v1beta1.
 	return nodeClaim
 }
 
+// inflightLaunches tracks the number of CloudProvider launches that are in progress for each NodePool
+type inflightLaunches struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newInflightLaunches() *inflightLaunches {
+	return &inflightLaunches{counts: map[string]int{}}
+}
+
+// tryAcquire reserves a launch for the NodePool if fewer than max launches are in progress. A max of zero means that
+// launches for the NodePool are unbounded.
+func (i *inflightLaunches) tryAcquire(nodePoolName string, max int) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if max > 0 && i.counts[nodePoolName] >= max {
+		return false
+	}
+	i.counts[nodePoolName]++
+	return true
+}
+
+func (i *inflightLaunches) release(nodePoolName string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.counts[nodePoolName]--; i.counts[nodePoolName] <= 0 {
+		delete(i.counts, nodePoolName)
+	}
+}
+
 func truncateMessage(msg string) string {
 	if len(msg) < 300 {
 		return msg
@@ -17,14 +17,24 @@ limitations under the License.
 package lifecycle_test
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/metrics"
+	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/test"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -70,6 +80,131 @@ var _ = Describe("Launch", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
 	})
+	It("should launch an instance when the NodePool has a maxConcurrentLaunches limit", func() {
+		nodePool.Spec.MaxConcurrentLaunches = lo.ToPtr[int32](1)
+		nodeClaims := lo.Times(2, func(_ int) *v1beta1.NodeClaim {
+			return test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+			})
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1])
+		// Launches that have completed don't count against the limit
+		for _, nodeClaim := range nodeClaims {
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+		}
+		Expect(cloudProvider.CreateCalls).To(HaveLen(2))
+	})
+	It("should launch the nodeclaims of a nodepool that arrive together in a single batch", func() {
+		nodeClaims := lo.Times(3, func(_ int) *v1beta1.NodeClaim {
+			return test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+			})
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		var wg sync.WaitGroup
+		for _, nodeClaim := range nodeClaims {
+			wg.Add(1)
+			go func(nodeClaim *v1beta1.NodeClaim) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			}(nodeClaim)
+		}
+		wg.Wait()
+		for _, nodeClaim := range nodeClaims {
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+		}
+		Expect(cloudProvider.CreateBatchCalls).To(HaveLen(1))
+		Expect(cloudProvider.CreateBatchCalls[0]).To(HaveLen(3))
+	})
+	It("should launch nodeclaims directly when the decorated cloudprovider doesn't implement BatchCreator", func() {
+		decorated := metrics.Decorate(struct{ cloudprovider.CloudProvider }{cloudProvider})
+		controller := nodeclaimlifecycle.NewController(fakeClock, env.Client, state.NewCluster(fakeClock, env.Client, decorated), decorated, events.NewRecorder(&record.FakeRecorder{}))
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+		Expect(cloudProvider.CreateBatchCalls).To(BeEmpty())
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should launch the nodeclaims of different nodepools in separate batches", func() {
+		otherNodePool := test.NodePool()
+		nodeClaims := lo.Map([]*v1beta1.NodePool{nodePool, otherNodePool}, func(np *v1beta1.NodePool, _ int) *v1beta1.NodeClaim {
+			return test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey: np.Name,
+					},
+				},
+			})
+		})
+		ExpectApplied(ctx, env.Client, nodePool, otherNodePool, nodeClaims[0], nodeClaims[1])
+		var wg sync.WaitGroup
+		for _, nodeClaim := range nodeClaims {
+			wg.Add(1)
+			go func(nodeClaim *v1beta1.NodeClaim) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			}(nodeClaim)
+		}
+		wg.Wait()
+		Expect(cloudProvider.CreateBatchCalls).To(HaveLen(2))
+	})
+	It("should populate a hydrated NodeClaim from its existing instance without launching", func() {
+		providerID := test.RandomProviderID()
+		cloudProvider.CreatedNodeClaims[providerID] = &v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.LabelInstanceTypeStable: "default-instance-type"},
+			},
+			Status: v1beta1.NodeClaimStatus{ProviderID: providerID},
+		}
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				Annotations: map[string]string{v1beta1.HydratedProviderIDAnnotationKey: providerID},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		Expect(nodeClaim.Status.ProviderID).To(Equal(providerID))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "default-instance-type"))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should delete a hydrated NodeClaim if its instance no longer exists", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1beta1.HydratedProviderIDAnnotationKey: test.RandomProviderID()},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+	})
 	It("should delete the nodeclaim if InsufficientCapacity is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
 		nodeClaim := test.NodeClaim()
@@ -88,4 +223,137 @@ var _ = Describe("Launch", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionFalse))
 	})
+	Context("Backoff", func() {
+		It("should back off launches for a nodepool after a launch fails", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+			nodeClaims := lo.Times(2, func(_ int) *v1beta1.NodeClaim {
+				return test.NodeClaim(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+					},
+				})
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1])
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaims[0]))
+			Expect(cluster.ConsecutiveLaunchFailures(nodePool.Name)).To(Equal(1))
+
+			res := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaims[1]))
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(cloudProvider.CreateCalls).To(BeEmpty())
+
+			fakeClock.Step(res.RequeueAfter)
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaims[1]))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(ExpectStatusConditionExists(ExpectExists(ctx, env.Client, nodeClaims[1]), v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+		})
+		It("should mark a nodepool unhealthy after consecutive launch failures", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < state.UnhealthyLaunchFailureThreshold; i++ {
+				cloudProvider.NextCreateErr = fmt.Errorf("quota exceeded")
+				nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+					},
+				})
+				ExpectApplied(ctx, env.Client, nodeClaim)
+				fakeClock.Step(cluster.LaunchBackoff(nodePool.Name))
+				ExpectReconcileFailed(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			}
+			Expect(cluster.IsNodePoolUnhealthy(nodePool.Name)).To(BeTrue())
+		})
+		It("should reset a nodepool's launch failures once a launch succeeds", func() {
+			cluster.RecordLaunchFailure(nodePool.Name)
+			cluster.RecordLaunchFailure(nodePool.Name)
+			cluster.RecordLaunchFailure(nodePool.Name)
+			Expect(cluster.IsNodePoolUnhealthy(nodePool.Name)).To(BeTrue())
+
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			fakeClock.Step(cluster.LaunchBackoff(nodePool.Name))
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(cluster.ConsecutiveLaunchFailures(nodePool.Name)).To(Equal(0))
+			Expect(cluster.IsNodePoolUnhealthy(nodePool.Name)).To(BeFalse())
+		})
+		It("should record that a nodepool is out of capacity when InsufficientCapacity is returned from the cloudprovider", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(cluster.HasInsufficientCapacity(nodePool.Name)).To(BeTrue())
+		})
+		It("should not record that a nodepool is out of capacity for other launch failures", func() {
+			cloudProvider.NextCreateErr = fmt.Errorf("quota exceeded")
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileFailed(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(cluster.HasInsufficientCapacity(nodePool.Name)).To(BeFalse())
+		})
+		It("should not back off launches when the nodeclass isn't ready", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewNodeClassNotReadyError(fmt.Errorf("nodeClass isn't ready"))
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(cluster.LaunchBackoff(nodePool.Name)).To(BeZero())
+		})
+		It("should wait out the delay that the cloudprovider asks for when it throttles launches", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewThrottledError(fmt.Errorf("request limit exceeded"), 30*time.Second)
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			res := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(res.RequeueAfter).To(Equal(30 * time.Second))
+			Expect(cluster.LaunchBackoff(nodePool.Name)).To(Equal(30 * time.Second))
+			// Throttling doesn't count against the nodepool's health
+			Expect(cluster.ConsecutiveLaunchFailures(nodePool.Name)).To(Equal(0))
+		})
+		It("should keep the nodeclaim and retry after the delay when capacity is temporarily unavailable", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewRetryableCapacityError(fmt.Errorf("capacity is being reclaimed"), time.Minute)
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			res := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(cluster.LaunchBackoff(nodePool.Name)).To(Equal(time.Minute))
+			Expect(cluster.ConsecutiveLaunchFailures(nodePool.Name)).To(Equal(1))
+			Expect(ExpectStatusConditionExists(ExpectExists(ctx, env.Client, nodeClaim), v1beta1.Launched).Status).To(Equal(v1.ConditionFalse))
+
+			fakeClock.Step(res.RequeueAfter)
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(ExpectStatusConditionExists(ExpectExists(ctx, env.Client, nodeClaim), v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+		})
+		It("should not retry launches that fail with a terminal error", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewTerminalError(fmt.Errorf("invalid image"))
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			_, err := nodeClaimController.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nodeClaim)})
+			Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+			Expect(cluster.ConsecutiveLaunchFailures(nodePool.Name)).To(Equal(1))
+		})
+	})
 })
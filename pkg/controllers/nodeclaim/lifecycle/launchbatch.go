@@ -0,0 +1,131 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+)
+
+const (
+	// launchBatchIdleDuration is how long a batch waits for more of its nodepool's NodeClaims before it's launched.
+	// The provisioner creates the NodeClaims of a scale-up together, so their launches arrive within a few
+	// milliseconds of each other.
+	launchBatchIdleDuration = 25 * time.Millisecond
+	// launchBatchMaxDuration bounds how long the first NodeClaim of a batch waits while more NodeClaims keep arriving
+	launchBatchMaxDuration = 250 * time.Millisecond
+)
+
+type launchResult struct {
+	nodeClaim *v1beta1.NodeClaim
+	err       error
+}
+
+type launchRequest struct {
+	nodeClaim *v1beta1.NodeClaim
+	result    chan launchResult
+}
+
+type launchBatch struct {
+	requests []launchRequest
+	trigger  chan struct{}
+}
+
+// launchBatches groups the launches of each nodepool's NodeClaims that arrive together, so that cloud providers that
+// implement cloudprovider.BatchCreator launch them with a single request
+type launchBatches struct {
+	mu      sync.Mutex
+	pending map[string]*launchBatch
+}
+
+func newLaunchBatches() *launchBatches {
+	return &launchBatches{pending: map[string]*launchBatch{}}
+}
+
+// create adds the NodeClaim to its nodepool's pending batch and waits for the batch to launch. The first NodeClaim of
+// a batch launches it once no other NodeClaims have arrived within the idle duration, or once the batch has waited
+// for the max duration.
+func (b *launchBatches) create(ctx context.Context, batchCreator cloudprovider.BatchCreator, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	nodePoolName := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+	request := launchRequest{nodeClaim: nodeClaim, result: make(chan launchResult, 1)}
+
+	b.mu.Lock()
+	if batch, ok := b.pending[nodePoolName]; ok {
+		batch.requests = append(batch.requests, request)
+		// The trigger is idempotently armed. This statement never blocks
+		select {
+		case batch.trigger <- struct{}{}:
+		default:
+		}
+		b.mu.Unlock()
+	} else {
+		batch = &launchBatch{requests: []launchRequest{request}, trigger: make(chan struct{}, 1)}
+		b.pending[nodePoolName] = batch
+		b.mu.Unlock()
+		b.launch(ctx, batchCreator, nodePoolName, batch)
+	}
+
+	select {
+	case result := <-request.result:
+		return result.nodeClaim, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// launch waits for the batch to fill up, then launches it and hands each NodeClaim's result to its request
+func (b *launchBatches) launch(ctx context.Context, batchCreator cloudprovider.BatchCreator, nodePoolName string, batch *launchBatch) {
+	timeout := time.NewTimer(launchBatchMaxDuration)
+	defer timeout.Stop()
+	idle := time.NewTimer(launchBatchIdleDuration)
+	defer idle.Stop()
+wait:
+	for {
+		select {
+		case <-batch.trigger:
+			// correct way to reset an active timer per docs
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(launchBatchIdleDuration)
+		case <-timeout.C:
+			break wait
+		case <-idle.C:
+			break wait
+		}
+	}
+	// Once the batch is removed from the pending batches, NodeClaims that arrive start the next batch
+	b.mu.Lock()
+	delete(b.pending, nodePoolName)
+	b.mu.Unlock()
+
+	created, errs := batchCreator.CreateBatch(ctx, lo.Map(batch.requests, func(r launchRequest, _ int) *v1beta1.NodeClaim { return r.nodeClaim }))
+	for i, r := range batch.requests {
+		if i >= len(created) || i >= len(errs) {
+			r.result <- launchResult{err: fmt.Errorf("creating batch, cloudprovider returned %d nodeclaims and %d errors for %d nodeclaims", len(created), len(errs), len(batch.requests))}
+			continue
+		}
+		r.result <- launchResult{nodeClaim: created[i], err: errs[i]}
+	}
+}
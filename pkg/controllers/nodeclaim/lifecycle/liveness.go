@@ -35,9 +35,10 @@ type Liveness struct {
 	kubeClient client.Client
 }
 
-// registrationTTL is a heuristic time that we expect the node to register within
+// defaultRegistrationTTL is a heuristic time that we expect the node to register within
 // If we don't see the node within this time, then we should delete the NodeClaim and try again
-const registrationTTL = time.Minute * 15
+// NodeClaims can override this with spec.registrationTimeout
+const defaultRegistrationTTL = time.Minute * 15
 
 func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
 	registered := nodeClaim.StatusConditions().GetCondition(v1beta1.Registered)
@@ -47,20 +48,31 @@ func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim)
 	if registered == nil {
 		return reconcile.Result{Requeue: true}, nil
 	}
+	ttl := registrationTTL(nodeClaim)
 	// If the Registered statusCondition hasn't gone True during the TTL since we first updated it, we should terminate the NodeClaim
-	if l.clock.Since(registered.LastTransitionTime.Inner.Time) < registrationTTL {
-		return reconcile.Result{RequeueAfter: registrationTTL - l.clock.Since(registered.LastTransitionTime.Inner.Time)}, nil
+	if l.clock.Since(registered.LastTransitionTime.Inner.Time) < ttl {
+		return reconcile.Result{RequeueAfter: ttl - l.clock.Since(registered.LastTransitionTime.Inner.Time)}, nil
 	}
 	// Delete the NodeClaim if we believe the NodeClaim won't register since we haven't seen the node
 	if err := l.kubeClient.Delete(ctx, nodeClaim); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
-	logging.FromContext(ctx).With("ttl", registrationTTL).Debugf("terminating due to registration ttl")
+	logging.FromContext(ctx).With("ttl", ttl).Debugf("terminating due to registration ttl")
 	metrics.NodeClaimsTerminatedCounter.With(prometheus.Labels{
 		metrics.ReasonLabel:       "liveness",
 		metrics.NodePoolLabel:     nodeClaim.Labels[v1beta1.NodePoolLabelKey],
 		metrics.CapacityTypeLabel: nodeClaim.Labels[v1beta1.CapacityTypeLabelKey],
 	}).Inc()
+	metrics.NodeClaimsRegistrationTimeoutCounter.With(prometheus.Labels{
+		metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
+	}).Inc()
 
 	return reconcile.Result{}, nil
 }
+
+func registrationTTL(nodeClaim *v1beta1.NodeClaim) time.Duration {
+	if nodeClaim.Spec.RegistrationTimeout != nil {
+		return nodeClaim.Spec.RegistrationTimeout.Duration
+	}
+	return defaultRegistrationTTL
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	nodeclaimutil "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// WarmPool stops the instances of NodeClaims in a warm pool once they've initialized, and starts them again when
+// they're claimed from the warm pool. It only acts for cloud providers that implement cloudprovider.WarmPoolProvider.
+type WarmPool struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+func (w *WarmPool) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
+	warmPoolProvider, ok := cloudprovider.As[cloudprovider.WarmPoolProvider](w.cloudProvider)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	warm := nodeClaim.Annotations[v1beta1.WarmPoolAnnotationKey] == "true"
+	stopped := nodeClaim.StatusConditions().GetCondition(v1beta1.Stopped).IsTrue()
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("provider-id", nodeClaim.Status.ProviderID))
+	switch {
+	case warm && !stopped && nodeClaim.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue():
+		return reconcile.Result{}, w.stop(ctx, warmPoolProvider, nodeClaim)
+	case !warm && stopped:
+		return reconcile.Result{}, w.start(ctx, warmPoolProvider, nodeClaim)
+	}
+	return reconcile.Result{}, nil
+}
+
+// stop removes the NodeClaim's node from the cluster and stops its instance. The node is removed rather than left
+// NotReady so that it isn't considered unhealthy while it's held in the warm pool.
+func (w *WarmPool) stop(ctx context.Context, warmPoolProvider cloudprovider.WarmPoolProvider, nodeClaim *v1beta1.NodeClaim) error {
+	node, err := nodeclaimutil.NodeForNodeClaim(ctx, w.kubeClient, nodeClaim)
+	if err != nil && !nodeclaimutil.IsNodeNotFoundError(err) {
+		return fmt.Errorf("getting node for nodeclaim, %w", err)
+	}
+	if err == nil {
+		// The termination finalizer is removed first since terminating the node would terminate the instance
+		stored := node.DeepCopy()
+		controllerutil.RemoveFinalizer(node, v1beta1.TerminationFinalizer)
+		if !equality.Semantic.DeepEqual(stored, node) {
+			if err = w.kubeClient.Patch(ctx, node, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("removing node finalizer, %w", err)
+			}
+		}
+		if err = w.kubeClient.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting node, %w", err)
+		}
+	}
+	if err = warmPoolProvider.Stop(ctx, nodeClaim); err != nil {
+		return fmt.Errorf("stopping instance, %w", err)
+	}
+	logging.FromContext(ctx).Infof("stopped warm pool nodeclaim")
+	nodeClaim.StatusConditions().MarkTrue(v1beta1.Stopped)
+	return nil
+}
+
+// start starts the instance of a NodeClaim that was claimed from the warm pool. Its node registers and initializes
+// again just like a newly launched NodeClaim's would.
+func (w *WarmPool) start(ctx context.Context, warmPoolProvider cloudprovider.WarmPoolProvider, nodeClaim *v1beta1.NodeClaim) error {
+	if err := warmPoolProvider.Start(ctx, nodeClaim); err != nil {
+		return fmt.Errorf("starting instance, %w", err)
+	}
+	logging.FromContext(ctx).Infof("started nodeclaim claimed from warm pool")
+	_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Stopped)
+	nodeClaim.StatusConditions().MarkFalse(v1beta1.Registered, "Starting", "Node is starting from the warm pool")
+	nodeClaim.StatusConditions().MarkFalse(v1beta1.Initialized, "Starting", "Node is starting from the warm pool")
+	return nil
+}
@@ -19,12 +19,16 @@ package termination
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -39,21 +43,31 @@ import (
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeclaimutil "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 )
 
 var _ operatorcontroller.FinalizingTypedController[*v1beta1.NodeClaim] = (*Controller)(nil)
 
+const (
+	// preTerminationHookRequeueInterval is how often the nodeclaim is checked for the removal of its pre-termination hooks
+	preTerminationHookRequeueInterval = 5 * time.Second
+	preTerminationHookTimeoutReason   = "PreTerminationHookTimeout"
+)
+
 // Controller is a NodeClaim Termination controller that triggers deletion of the Node and the
 // CloudProvider NodeClaim through its graceful termination mechanism
 type Controller struct {
+	clock         clock.Clock
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
 }
 
 // NewController is a constructor for the NodeClaim Controller
-func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) operatorcontroller.Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) operatorcontroller.Controller {
 	return operatorcontroller.Typed[*v1beta1.NodeClaim](kubeClient, &Controller{
+		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 	})
@@ -84,7 +98,12 @@ func (c *Controller) Finalize(ctx context.Context, nodeClaim *v1beta1.NodeClaim)
 		return reconcile.Result{}, nil
 	}
 	if nodeClaim.Status.ProviderID != "" {
-		if err = c.cloudProvider.Delete(ctx, nodeClaim); cloudprovider.IgnoreNodeClaimNotFoundError(err) != nil {
+		// Pre-termination hooks are only waited on once the node is drained, and are removed from the nodeclaim by the
+		// agents that registered them. Finalizer updates don't change the generation, so we poll for their removal.
+		if requeueAfter, err := c.awaitPreTerminationHooks(ctx, nodeClaim); err != nil || requeueAfter > 0 {
+			return reconcile.Result{RequeueAfter: requeueAfter}, err
+		}
+		if err = c.deleteInstance(ctx, nodeClaim); cloudprovider.IgnoreNodeClaimNotFoundError(err) != nil {
 			return reconcile.Result{}, fmt.Errorf("terminating cloudprovider instance, %w", err)
 		}
 	}
@@ -98,10 +117,59 @@ func (c *Controller) Finalize(ctx context.Context, nodeClaim *v1beta1.NodeClaim)
 	return reconcile.Result{}, nil
 }
 
+// awaitPreTerminationHooks returns how long to wait before checking the nodeclaim's pre-termination hooks again, or
+// zero once they've completed or timed out. Hooks that time out are removed from the nodeclaim, so that they don't
+// block its deletion.
+func (c *Controller) awaitPreTerminationHooks(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (time.Duration, error) {
+	hooks := lo.Filter(nodeClaim.Finalizers, func(f string, _ int) bool { return strings.HasPrefix(f, v1beta1.PreTerminationFinalizer) })
+	condition := nodeClaim.StatusConditions().GetCondition(v1beta1.PreTerminationHooksCompleted)
+	if condition == nil && len(hooks) == 0 {
+		return 0, nil
+	}
+	if !condition.IsTrue() {
+		stored := nodeClaim.DeepCopy()
+		switch {
+		case len(hooks) == 0:
+			nodeClaim.StatusConditions().MarkTrue(v1beta1.PreTerminationHooksCompleted)
+		case condition == nil:
+			nodeClaim.StatusConditions().MarkFalse(v1beta1.PreTerminationHooksCompleted, "AwaitingPreTerminationHooks", "Waiting on pre-termination hooks %s", strings.Join(hooks, ", "))
+		case c.clock.Since(condition.LastTransitionTime.Inner.Time) < options.FromContext(ctx).PreTerminationHookTimeout:
+			return lo.Min([]time.Duration{preTerminationHookRequeueInterval, options.FromContext(ctx).PreTerminationHookTimeout - c.clock.Since(condition.LastTransitionTime.Inner.Time)}), nil
+		default:
+			logging.FromContext(ctx).With("hooks", strings.Join(hooks, ",")).Infof("pre-termination hooks timed out after %s", options.FromContext(ctx).PreTerminationHookTimeout)
+			nodeClaim.StatusConditions().MarkTrueWithReason(v1beta1.PreTerminationHooksCompleted, preTerminationHookTimeoutReason, "Pre-termination hooks %s timed out", strings.Join(hooks, ", "))
+		}
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return 0, client.IgnoreNotFound(fmt.Errorf("patching pre-termination hooks condition, %w", err))
+		}
+		condition = nodeClaim.StatusConditions().GetCondition(v1beta1.PreTerminationHooksCompleted)
+		if !condition.IsTrue() {
+			return preTerminationHookRequeueInterval, nil
+		}
+	}
+	// The finalizers of the hooks that timed out are removed along with the termination finalizer. Hooks that are
+	// registered after the hooks completed are left to the agents that registered them, since the node is already
+	// drained.
+	if condition.Reason == preTerminationHookTimeoutReason {
+		nodeClaim.Finalizers = lo.Without(nodeClaim.Finalizers, hooks...)
+	}
+	return 0, nil
+}
+
 func (*Controller) Name() string {
 	return "nodeclaim.termination"
 }
 
+// deleteInstance deletes the nodeclaim's instance, continuing the trace of the disruption that deleted the nodeclaim
+func (c *Controller) deleteInstance(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (err error) {
+	ctx, span := tracing.Start(tracing.Extract(ctx, nodeClaim), "cloudprovider.delete",
+		attribute.String("nodeclaim", nodeClaim.Name),
+		attribute.String("provider-id", nodeClaim.Status.ProviderID),
+	)
+	defer func() { tracing.End(span, cloudprovider.IgnoreNodeClaimNotFoundError(err)) }()
+	return c.cloudProvider.Delete(ctx, nodeClaim)
+}
+
 func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
 	return operatorcontroller.Adapt(controllerruntime.
 		NewControllerManagedBy(m).
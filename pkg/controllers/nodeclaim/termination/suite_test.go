@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
@@ -53,6 +54,7 @@ var ctx context.Context
 var env *test.Environment
 var fakeClock *clock.FakeClock
 var cloudProvider *fake.CloudProvider
+var cluster *state.Cluster
 var nodeClaimLifecycleController controller.Controller
 var nodeClaimTerminationController controller.Controller
 
@@ -71,8 +73,9 @@ var _ = BeforeSuite(func() {
 	}))
 	ctx = options.ToContext(ctx, test.Options())
 	cloudProvider = fake.NewCloudProvider()
-	nodeClaimLifecycleController = nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, events.NewRecorder(&record.FakeRecorder{}))
-	nodeClaimTerminationController = nodeclaimtermination.NewController(env.Client, cloudProvider)
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodeClaimLifecycleController = nodeclaimlifecycle.NewController(fakeClock, env.Client, cluster, cloudProvider, events.NewRecorder(&record.FakeRecorder{}))
+	nodeClaimTerminationController = nodeclaimtermination.NewController(fakeClock, env.Client, cloudProvider)
 })
 
 var _ = AfterSuite(func() {
@@ -83,6 +86,7 @@ var _ = AfterEach(func() {
 	fakeClock.SetTime(time.Now())
 	ExpectCleanedUp(ctx, env.Client)
 	cloudProvider.Reset()
+	cluster.Reset()
 })
 
 var _ = Describe("Termination", func() {
@@ -216,4 +220,61 @@ var _ = Describe("Termination", func() {
 			ExpectExists(ctx, env.Client, node)
 		}
 	})
+	Context("Pre-Termination Hooks", func() {
+		var hook string
+		BeforeEach(func() {
+			hook = v1beta1.PreTerminationFinalizer + "-backup"
+			nodeClaim.Finalizers = append(nodeClaim.Finalizers, hook)
+		})
+		It("should not delete the instance until the pre-termination hooks are removed", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimLifecycleController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+			Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, nodeClaimTerminationController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.PreTerminationHooksCompleted).IsFalse()).To(BeTrue())
+			Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
+			_, err := cloudProvider.Get(ctx, nodeClaim.Status.ProviderID)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Removing the hook lets the termination continue
+			nodeClaim.Finalizers = lo.Without(nodeClaim.Finalizers, hook)
+			Expect(env.Client.Update(ctx, nodeClaim)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, nodeClaimTerminationController, client.ObjectKeyFromObject(nodeClaim))
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			_, err = cloudProvider.Get(ctx, nodeClaim.Status.ProviderID)
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+		})
+		It("should delete the instance and remove the pre-termination hooks once they time out", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimLifecycleController, client.ObjectKeyFromObject(nodeClaim))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+			Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, nodeClaimTerminationController, client.ObjectKeyFromObject(nodeClaim))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
+
+			fakeClock.Step(time.Minute * 11)
+			ExpectReconcileSucceeded(ctx, nodeClaimTerminationController, client.ObjectKeyFromObject(nodeClaim))
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			_, err := cloudProvider.Get(ctx, nodeClaim.Status.ProviderID)
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+		})
+		It("should not wait for pre-termination hooks on NodeClaims that haven't launched", func() {
+			nodeClaim.Status.ProviderID = ""
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, nodeClaimTerminationController, client.ObjectKeyFromObject(nodeClaim))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Finalizers).To(ConsistOf(hook))
+		})
+	})
 })
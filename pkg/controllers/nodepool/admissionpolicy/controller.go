@@ -0,0 +1,109 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// resyncPeriod is how often the policy and its binding are checked, so that changes to them are reverted
+const resyncPeriod = 5 * time.Minute
+
+// Controller installs a ValidatingAdmissionPolicy that validates NodePools in the apiserver. This gives clusters that
+// don't run the webhook the validation that the CRD schema can't express.
+type Controller struct {
+	kubeClient client.Client
+}
+
+func NewController(kubeClient client.Client) operatorcontroller.Controller {
+	return &Controller{
+		kubeClient: kubeClient,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "nodepool.admissionpolicy"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	if !options.FromContext(ctx).EnableAdmissionPolicies {
+		return reconcile.Result{RequeueAfter: resyncPeriod}, nil
+	}
+	policy := Policy()
+	if err := c.ensure(ctx, "validating admission policy", policy, &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}, func(existing client.Object) bool {
+		e := existing.(*admissionregistrationv1beta1.ValidatingAdmissionPolicy)
+		if equality.Semantic.DeepEqual(e.Spec, policy.Spec) {
+			return false
+		}
+		e.Spec = policy.Spec
+		return true
+	}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("ensuring validating admission policy, %w", err)
+	}
+	binding := Binding()
+	if err := c.ensure(ctx, "validating admission policy binding", binding, &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}, func(existing client.Object) bool {
+		e := existing.(*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding)
+		if equality.Semantic.DeepEqual(e.Spec, binding.Spec) {
+			return false
+		}
+		e.Spec = binding.Spec
+		return true
+	}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("ensuring validating admission policy binding, %w", err)
+	}
+	return reconcile.Result{RequeueAfter: resyncPeriod}, nil
+}
+
+// ensure creates the desired object if it doesn't exist, or updates the existing object if update reports that it
+// changed it to match the desired object
+func (c *Controller) ensure(ctx context.Context, kind string, desired, existing client.Object, update func(client.Object) bool) error {
+	if err := c.kubeClient.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err = c.kubeClient.Create(ctx, desired); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).With("name", desired.GetName()).Infof("created %s", kind)
+		return nil
+	}
+	if !update(existing) {
+		return nil
+	}
+	if err := c.kubeClient.Update(ctx, existing); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).With("name", desired.GetName()).Infof("updated %s", kind)
+	return nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
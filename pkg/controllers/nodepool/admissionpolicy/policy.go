@@ -0,0 +1,141 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// Name is the name of both the ValidatingAdmissionPolicy and its binding
+const Name = "nodepools." + v1beta1.Group
+
+// Policy returns a ValidatingAdmissionPolicy that enforces the NodePool invariants that the CRD schema doesn't. It's
+// generated from the same values that the webhook validates against, so that the two don't drift apart.
+func Policy() *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: Name},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			FailurePolicy: lo.ToPtr(admissionregistrationv1beta1.Fail),
+			// The apiserver's defaults are set explicitly, so that the installed policy can be compared with this one
+			MatchConstraints: &admissionregistrationv1beta1.MatchResources{
+				NamespaceSelector: &metav1.LabelSelector{},
+				ObjectSelector:    &metav1.LabelSelector{},
+				MatchPolicy:       lo.ToPtr(admissionregistrationv1beta1.Equivalent),
+				ResourceRules: []admissionregistrationv1beta1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{v1beta1.Group},
+							APIVersions: []string{v1beta1.SchemeGroupVersion.Version},
+							Resources:   []string{"nodepools"},
+							Scope:       lo.ToPtr(admissionregistrationv1beta1.AllScopes),
+						},
+					},
+				}},
+			},
+			Variables: []admissionregistrationv1beta1.Variable{
+				{Name: "budgets", Expression: "has(object.spec.disruption.budgets) ? object.spec.disruption.budgets : []"},
+			},
+			Validations: Validations(),
+		},
+	}
+}
+
+// Binding returns the ValidatingAdmissionPolicyBinding that denies NodePools that violate the policy
+func Binding() *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: Name},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        Name,
+			ValidationActions: []admissionregistrationv1beta1.ValidationAction{admissionregistrationv1beta1.Deny},
+		},
+	}
+}
+
+// Validations returns the CEL validations of the NodePool invariants
+func Validations() []admissionregistrationv1beta1.Validation {
+	operators := v1beta1.SupportedNodeSelectorOps.List()
+	reasons := lo.Map(v1beta1.SupportedDisruptionReasons, func(r v1beta1.DisruptionReason, _ int) string { return string(r) })
+	sort.Strings(reasons)
+	return []admissionregistrationv1beta1.Validation{
+		// Immutable fields
+		immutable("spec.template.spec.nodeClassRef", "kind"),
+		immutable("spec.template.spec.nodeClassRef", "apiVersion"),
+		// Requirement operators
+		{
+			Expression: fmt.Sprintf("object.spec.template.spec.requirements.all(r, r.operator in %s)", celList(operators)),
+			Message:    fmt.Sprintf("requirements must use an operator in %s", strings.Join(operators, ", ")),
+		},
+		{
+			Expression: "object.spec.template.spec.requirements.all(r, r.operator == 'In' ? has(r.values) && r.values.size() != 0 : true)",
+			Message:    "requirements with operator 'In' must have a value defined",
+		},
+		{
+			Expression: "object.spec.template.spec.requirements.all(r, r.operator == 'NotMatches' ? has(r.values) && r.values.size() != 0 : true)",
+			Message:    "requirements with operator 'NotMatches' must have a value defined",
+		},
+		{
+			Expression: "object.spec.template.spec.requirements.all(r, r.operator in ['Gt', 'Lt'] ? has(r.values) && r.values.size() == 1 && r.values[0].matches('^[0-9]+$') : true)",
+			Message:    "requirements with operator 'Gt' or 'Lt' must have a single positive integer value",
+		},
+		{
+			Expression: `object.spec.template.spec.requirements.all(r, !has(r.values) || r.operator == 'NotMatches' || r.values.all(v, v.size() <= 63 && v.matches(r'^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$')))`,
+			Message:    "requirements must have values that are valid label values",
+		},
+		// Budget syntax
+		{
+			Expression: "variables.budgets.all(b, b.nodes.matches('^((100|[0-9]{1,2})%|[0-9]+)$'))",
+			Message:    "budget nodes must be a percentage or a non-negative integer",
+		},
+		{
+			Expression: "variables.budgets.all(b, has(b.schedule) == has(b.duration))",
+			Message:    "budget schedule and duration must be specified together",
+		},
+		{
+			Expression: `variables.budgets.all(b, has(b.schedule) ? b.schedule.matches(r'^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$') : true)`,
+			Message:    "budget schedule must follow the cronjob syntax",
+		},
+		{
+			Expression: fmt.Sprintf("variables.budgets.all(b, has(b.reasons) ? b.reasons.all(r, r in %s) : true)", celList(reasons)),
+			Message:    fmt.Sprintf("budget reasons must be in %s", strings.Join(reasons, ", ")),
+		},
+	}
+}
+
+// immutable returns a validation that the field can't change once the NodePool is created. The field is optional, so
+// it's compared with the empty string when it isn't set.
+func immutable(parent, field string) admissionregistrationv1beta1.Validation {
+	value := func(obj string) string {
+		return fmt.Sprintf("(has(%[1]s.%[2]s.%[3]s) ? %[1]s.%[2]s.%[3]s : '')", obj, parent, field)
+	}
+	return admissionregistrationv1beta1.Validation{
+		Expression: fmt.Sprintf("request.operation != 'UPDATE' || %s == %s", value("object"), value("oldObject")),
+		Message:    fmt.Sprintf("%s.%s is immutable", parent, field),
+	}
+}
+
+func celList(values []string) string {
+	return fmt.Sprintf("[%s]", strings.Join(lo.Map(values, func(v string, _ int) string { return fmt.Sprintf("'%s'", v) }), ", "))
+}
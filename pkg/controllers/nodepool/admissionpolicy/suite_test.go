@@ -0,0 +1,129 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/admissionpolicy"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var admissionPolicyController controller.Controller
+var env *test.Environment
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AdmissionPolicy")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	admissionPolicyController = admissionpolicy.NewController(env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EnableAdmissionPolicies: lo.ToPtr(true)}))
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	for _, obj := range []client.Object{admissionpolicy.Binding(), admissionpolicy.Policy()} {
+		Expect(client.IgnoreNotFound(env.Client.Delete(ctx, obj))).To(Succeed())
+	}
+})
+
+var _ = Describe("AdmissionPolicy", func() {
+	It("should install the policy and its binding", func() {
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+
+		policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+		Expect(env.Client.Get(ctx, client.ObjectKey{Name: admissionpolicy.Name}, policy)).To(Succeed())
+		Expect(policy.Spec.Validations).To(Equal(admissionpolicy.Validations()))
+		binding := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+		Expect(env.Client.Get(ctx, client.ObjectKey{Name: admissionpolicy.Name}, binding)).To(Succeed())
+		Expect(binding.Spec.PolicyName).To(Equal(admissionpolicy.Name))
+		Expect(binding.Spec.ValidationActions).To(ConsistOf(admissionregistrationv1beta1.Deny))
+	})
+	It("should not install the policy when admission policies are disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EnableAdmissionPolicies: lo.ToPtr(false)}))
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+
+		ExpectNotFound(ctx, env.Client, admissionpolicy.Policy(), admissionpolicy.Binding())
+	})
+	It("should revert changes to the installed policy", func() {
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+		policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+		Expect(env.Client.Get(ctx, client.ObjectKey{Name: admissionpolicy.Name}, policy)).To(Succeed())
+		policy.Spec.Validations = policy.Spec.Validations[:1]
+		Expect(env.Client.Update(ctx, policy)).To(Succeed())
+
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+		Expect(env.Client.Get(ctx, client.ObjectKey{Name: admissionpolicy.Name}, policy)).To(Succeed())
+		Expect(policy.Spec.Validations).To(Equal(admissionpolicy.Validations()))
+	})
+	It("should deny nodepools that violate the policy", func() {
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+
+		// The apiserver loads the policy asynchronously, so it may take a moment to start denying nodepools
+		nodePool := test.NodePool()
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "example.com/team", Operator: v1.NodeSelectorOpIn, Values: []string{"not a label value"}}},
+		}
+		Eventually(func() error {
+			// Each attempt creates a new nodepool, so that an attempt before the policy is loaded doesn't block the next
+			invalid := nodePool.DeepCopy()
+			invalid.Name, invalid.GenerateName = "", "invalid-"
+			return env.Client.Create(ctx, invalid)
+		}).Should(MatchError(ContainSubstring("requirements must have values that are valid label values")))
+	})
+	It("should deny changes to the nodeclass kind of a nodepool", func() {
+		ExpectReconcileSucceeded(ctx, admissionPolicyController, client.ObjectKey{})
+
+		nodePool := test.NodePool()
+		ExpectApplied(ctx, env.Client, nodePool)
+		Eventually(func() error {
+			// Each attempt changes the kind, so that an attempt before the policy is loaded doesn't block the next
+			stored := ExpectExists(ctx, env.Client, nodePool)
+			stored.Spec.Template.Spec.NodeClassRef.Kind = test.RandomName()
+			return env.Client.Update(ctx, stored)
+		}).Should(MatchError(ContainSubstring("spec.template.spec.nodeClassRef.kind is immutable")))
+	})
+})
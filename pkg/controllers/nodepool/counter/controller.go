@@ -36,8 +36,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-
-	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*Controller)(nil)
@@ -66,7 +64,14 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool)
 	}
 	stored := nodePool.DeepCopy()
 	// Determine resource usage and update nodepool.status.resources
-	nodePool.Status.Resources = c.resourceCountsFor(v1beta1.NodePoolLabelKey, nodePool.Name)
+	// Record all resources provisioned by the nodepools, we look at the cluster state nodes as their capacity
+	// is accurately reported even for nodes that haven't fully started yet. This allows us to update our nodepool
+	// status immediately upon node creation instead of waiting for the node to become ready.
+	usage := c.cluster.NodePoolUsage()[nodePool.Name]
+	nodePool.Status.Resources = functional.FilterMap(usage.Capacity, func(_ v1.ResourceName, v resource.Quantity) bool { return !v.IsZero() })
+	// Count the nodepool's NodeClaims and nodes so that dashboards and kubectl don't need to aggregate them
+	nodePool.Status.NodeClaimCount = int32(usage.NodeClaims)
+	nodePool.Status.NodeCount = int32(usage.KubeNodes)
 	if !equality.Semantic.DeepEqual(stored, nodePool) {
 		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFrom(stored)); err != nil {
 			return reconcile.Result{}, client.IgnoreNotFound(err)
@@ -75,25 +80,6 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool)
 	return reconcile.Result{}, nil
 }
 
-func (c *Controller) resourceCountsFor(ownerLabel string, ownerName string) v1.ResourceList {
-	var res v1.ResourceList
-	// Record all resources provisioned by the nodepools, we look at the cluster state nodes as their capacity
-	// is accurately reported even for nodes that haven't fully started yet. This allows us to update our nodepool
-	// status immediately upon node creation instead of waiting for the node to become ready.
-	c.cluster.ForEachNode(func(n *state.StateNode) bool {
-		// Don't count nodes that we are planning to delete. This is to ensure that we are consistent throughout
-		// our provisioning and deprovisioning loops
-		if n.MarkedForDeletion() {
-			return true
-		}
-		if n.Labels()[ownerLabel] == ownerName {
-			res = resources.MergeInto(res, n.Capacity())
-		}
-		return true
-	})
-	return functional.FilterMap(res, func(_ v1.ResourceName, v resource.Quantity) bool { return !v.IsZero() })
-}
-
 func (c *Controller) Name() string {
 	return "nodepool.counter"
 }
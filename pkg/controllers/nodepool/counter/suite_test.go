@@ -203,4 +203,24 @@ var _ = Describe("Counter", func() {
 		nodePool = ExpectExists(ctx, env.Client, nodePool)
 		Expect(nodePool.Status.Resources).To(BeNil())
 	})
+	It("should count the nodeClaims and nodes of the nodePool", func() {
+		// The second nodeClaim hasn't registered a node yet
+		ExpectApplied(ctx, env.Client, node, nodeClaim, nodeClaim2)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim2))
+
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.NodeClaimCount).To(BeNumerically("==", 2))
+		Expect(nodePool.Status.NodeCount).To(BeNumerically("==", 1))
+
+		ExpectDeleted(ctx, env.Client, node, nodeClaim, nodeClaim2)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim2))
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.NodeClaimCount).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.NodeCount).To(BeNumerically("==", 0))
+	})
 })
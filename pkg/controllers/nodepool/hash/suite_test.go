@@ -26,6 +26,7 @@ import (
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -111,6 +112,20 @@ var _ = Describe("Static Drift Hash", func() {
 		expectedHashTwo := nodePool.Hash()
 		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1beta1.NodePoolHashAnnotationKey, expectedHashTwo))
 	})
+	It("should update the static drift hash when the kubelet configuration is updated", func() {
+		hashes := map[string]struct{}{nodePool.Hash(): {}}
+		for _, kubelet := range []*v1beta1.KubeletConfiguration{
+			{MaxPods: ptr.Int32(20)},
+			{MaxPods: ptr.Int32(10), SystemReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}},
+			{MaxPods: ptr.Int32(10), EvictionHard: map[string]string{"memory.available": "5%"}},
+			{MaxPods: ptr.Int32(10), EvictionSoft: map[string]string{"memory.available": "10%"}, EvictionSoftGracePeriod: map[string]metav1.Duration{"memory.available": {Duration: time.Minute}}},
+		} {
+			updated := nodePool.DeepCopy()
+			updated.Spec.Template.Spec.Kubelet = kubelet
+			Expect(hashes).ToNot(HaveKey(updated.Hash()))
+			hashes[updated.Hash()] = struct{}{}
+		}
+	})
 	It("should not update the static drift hash when NodePool behavior field is updated", func() {
 		ExpectApplied(ctx, env.Client, nodePool)
 		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
@@ -123,6 +138,7 @@ var _ = Describe("Static Drift Hash", func() {
 		nodePool.Spec.Disruption.ConsolidationPolicy = v1beta1.ConsolidationPolicyWhenEmpty
 		nodePool.Spec.Disruption.ConsolidateAfter = &v1beta1.NillableDuration{Duration: lo.ToPtr(30 * time.Second)}
 		nodePool.Spec.Disruption.ExpireAfter.Duration = lo.ToPtr(30 * time.Second)
+		nodePool.Spec.Disruption.DriftChecks = &v1beta1.DriftChecks{NodeImage: lo.ToPtr(false)}
 		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
 			{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
 			{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{"1"}}},
@@ -0,0 +1,119 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// scaleUpStalledCheckInterval is how often NodePools with a scale-up deadline are checked for stalled scale-ups
+const scaleUpStalledCheckInterval = 30 * time.Second
+
+var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*Controller)(nil)
+
+// Controller sets the Unhealthy status condition on NodePools whose NodeClaims have repeatedly failed to launch, and the
+// ScaleUpStalled status condition on NodePools that haven't been able to launch capacity for pending pods for longer
+// than their scale-up deadline. Both are tracked in cluster state, so a NodePool is considered healthy again after a
+// restart until its launches fail or its scale-ups stall again.
+type Controller struct {
+	kubeClient client.Client
+	cluster    *state.Cluster
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cluster *state.Cluster) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodePool](kubeClient, &Controller{
+		kubeClient: kubeClient,
+		cluster:    cluster,
+	})
+}
+
+// Reconcile a control loop for the resource
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool) (reconcile.Result, error) {
+	stored := nodePool.DeepCopy()
+	if c.cluster.IsNodePoolUnhealthy(nodePool.Name) {
+		nodePool.StatusConditions().MarkTrueWithReason(v1beta1.Unhealthy, "LaunchFailed",
+			fmt.Sprintf("%d consecutive launches failed", c.cluster.ConsecutiveLaunchFailures(nodePool.Name)))
+	} else {
+		_ = nodePool.StatusConditions().ClearCondition(v1beta1.Unhealthy)
+	}
+	requeueAfter := c.reconcileScaleUpStalled(nodePool)
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFrom(stored)); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcileScaleUpStalled sets the ScaleUpStalled status condition once the NodePool's scale-up has been stalled for
+// longer than its deadline, and returns when it should be checked again. Scale-ups stall and recover without any
+// change to the NodePool or its NodeClaims, so NodePools with a deadline are checked periodically.
+func (c *Controller) reconcileScaleUpStalled(nodePool *v1beta1.NodePool) time.Duration {
+	if nodePool.Spec.ScaleUpDeadline == nil {
+		_ = nodePool.StatusConditions().ClearCondition(v1beta1.ScaleUpStalled)
+		return 0
+	}
+	deadline := nodePool.Spec.ScaleUpDeadline.Duration
+	stalledFor, stalled := c.cluster.ScaleUpStalledFor(nodePool.Name)
+	if stalled && stalledFor >= deadline {
+		nodePool.StatusConditions().MarkTrueWithReason(v1beta1.ScaleUpStalled, "PendingPodsNotLaunched",
+			fmt.Sprintf("capacity for pending pods couldn't be launched for longer than the scale-up deadline of %s", deadline))
+		return scaleUpStalledCheckInterval
+	}
+	_ = nodePool.StatusConditions().ClearCondition(v1beta1.ScaleUpStalled)
+	if stalled {
+		return min(deadline-stalledFor, scaleUpStalledCheckInterval)
+	}
+	return scaleUpStalledCheckInterval
+}
+
+func (c *Controller) Name() string {
+	return "nodepool.health"
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1beta1.NodePool{}).
+		Watches(
+			&v1beta1.NodeClaim{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+				if name, ok := o.GetLabels()[v1beta1.NodePoolLabelKey]; ok {
+					return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+				}
+				return nil
+			}),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clock "k8s.io/utils/clock/testing"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/health"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var nodePoolController controller.Controller
+var ctx context.Context
+var env *test.Environment
+var cluster *state.Cluster
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodePoolController = health.NewController(env.Client, cluster)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cluster.Reset()
+})
+
+var _ = Describe("Health", func() {
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		ExpectApplied(ctx, env.Client, nodePool)
+	})
+	It("should not mark a nodepool unhealthy without launch failures", func() {
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should not mark a nodepool unhealthy below the launch failure threshold", func() {
+		for i := 0; i < state.UnhealthyLaunchFailureThreshold-1; i++ {
+			cluster.RecordLaunchFailure(nodePool.Name)
+		}
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should mark a nodepool unhealthy once its launches have repeatedly failed", func() {
+		for i := 0; i < state.UnhealthyLaunchFailureThreshold; i++ {
+			cluster.RecordLaunchFailure(nodePool.Name)
+		}
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(ExpectStatusConditionExists(nodePool, v1beta1.Unhealthy).Status).To(Equal(v1.ConditionTrue))
+	})
+	It("should clear the unhealthy condition once a launch succeeds", func() {
+		for i := 0; i < state.UnhealthyLaunchFailureThreshold; i++ {
+			cluster.RecordLaunchFailure(nodePool.Name)
+		}
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(ExpectStatusConditionExists(nodePool, v1beta1.Unhealthy).Status).To(Equal(v1.ConditionTrue))
+
+		cluster.RecordLaunchSuccess(nodePool.Name)
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	Context("Scale-Up Deadline", func() {
+		var pod *v1.Pod
+		var stalled map[string]sets.Set[types.NamespacedName]
+		BeforeEach(func() {
+			nodePool.Spec.ScaleUpDeadline = &metav1.Duration{Duration: 10 * time.Minute}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod = test.UnschedulablePod()
+			stalled = map[string]sets.Set[types.NamespacedName]{nodePool.Name: sets.New(client.ObjectKeyFromObject(pod))}
+		})
+		It("should not mark a nodepool stalled before its scale-up deadline", func() {
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(5 * time.Minute)
+			cluster.RecordStalledScaleUps(stalled)
+			result := ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().GetCondition(v1beta1.ScaleUpStalled)).To(BeNil())
+		})
+		It("should mark a nodepool stalled once its scale-up has stalled past the deadline", func() {
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(10 * time.Minute)
+			cluster.RecordStalledScaleUps(stalled)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(ExpectStatusConditionExists(nodePool, v1beta1.ScaleUpStalled).Status).To(Equal(v1.ConditionTrue))
+		})
+		It("should clear the stalled condition once the nodepool can launch capacity again", func() {
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(10 * time.Minute)
+			cluster.RecordStalledScaleUps(stalled)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(ExpectStatusConditionExists(nodePool, v1beta1.ScaleUpStalled).Status).To(Equal(v1.ConditionTrue))
+
+			cluster.RecordStalledScaleUps(nil)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().GetCondition(v1beta1.ScaleUpStalled)).To(BeNil())
+		})
+		It("should clear the stalled condition once the pods that stalled are no longer pending", func() {
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(ExpectStatusConditionExists(nodePool, v1beta1.ScaleUpStalled).Status).To(Equal(v1.ConditionTrue))
+
+			cluster.DeletePod(client.ObjectKeyFromObject(pod))
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().GetCondition(v1beta1.ScaleUpStalled)).To(BeNil())
+		})
+		It("should keep the deadline of a stall while its pods are pending", func() {
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(time.Hour)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(ExpectStatusConditionExists(nodePool, v1beta1.ScaleUpStalled).Status).To(Equal(v1.ConditionTrue))
+		})
+		It("should not mark a nodepool stalled without a scale-up deadline", func() {
+			nodePool.Spec.ScaleUpDeadline = nil
+			ExpectApplied(ctx, env.Client, nodePool)
+			cluster.RecordStalledScaleUps(stalled)
+			fakeClock.Step(time.Hour)
+			cluster.RecordStalledScaleUps(stalled)
+			ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().GetCondition(v1beta1.ScaleUpStalled)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,124 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minnodes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*Controller)(nil)
+
+// Controller launches NodeClaims for NodePools that have fewer nodes than their minNodes, so that a floor of warm
+// capacity is available even when there are no pending pods. The minNodes of any active scaling window in the
+// NodePool's schedule also applies.
+type Controller struct {
+	clock       clock.Clock
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+}
+
+// NewController is a constructor
+func NewController(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodePool](kubeClient, &Controller{
+		clock:       clk,
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+	})
+}
+
+// Reconcile a control loop for the resource
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool) (reconcile.Result, error) {
+	if !nodePool.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	// Scaling windows start and end without the nodepool changing, so nodepools with a schedule are checked every
+	// minute, which is the granularity of a cron schedule
+	result := reconcile.Result{RequeueAfter: lo.Ternary(len(nodePool.Spec.Schedule) > 0, time.Minute, 0)}
+	minNodes, err := nodePool.GetMinNodes(c.clock)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting min nodes, %w", err)
+	}
+	if minNodes == 0 {
+		return result, nil
+	}
+	// We need to ensure that our internal cluster state mechanism is synced before we proceed
+	// Otherwise, we may not know about all of the nodes in the nodepool and launch more than we need
+	if !c.cluster.Synced(ctx) {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+	if err := nodePool.RuntimeValidate(); err != nil {
+		logging.FromContext(ctx).Errorf("nodepool failed validation, %s", err)
+		return result, nil
+	}
+	// In-flight NodeClaims are included so that we don't launch again while earlier launches are still registering.
+	// NodeClaims in the warm pool aren't, since they aren't available to pods.
+	nodes := lo.CountBy(c.cluster.Nodes(), func(n *state.StateNode) bool {
+		return n.Managed() && n.Labels()[v1beta1.NodePoolLabelKey] == nodePool.Name && !n.MarkedForDeletion() && !n.Warm()
+	})
+	for i := nodes; i < minNodes; i++ {
+		nodeClaim, err := c.provisioner.NodeClaimForNodePool(ctx, nodePool)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if _, err = c.provisioner.Create(ctx, nodeClaim, provisioning.WithReason(metrics.MinNodesReason)); err != nil {
+			return reconcile.Result{}, fmt.Errorf("creating nodeclaim, %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (c *Controller) Name() string {
+	return "nodepool.minnodes"
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1beta1.NodePool{}).
+		Watches(
+			&v1beta1.NodeClaim{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+				if name, ok := o.GetLabels()[v1beta1.NodePoolLabelKey]; ok {
+					return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+				}
+				return nil
+			}),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}))
+}
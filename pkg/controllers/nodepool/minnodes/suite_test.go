@@ -0,0 +1,159 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minnodes_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clock "k8s.io/utils/clock/testing"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/minnodes"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var minNodesController controller.Controller
+var nodeClaimController controller.Controller
+var ctx context.Context
+var env *test.Environment
+var cluster *state.Cluster
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MinNodes")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodeClaimController = informer.NewNodeClaimController(env.Client, cluster)
+	prov := provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
+	minNodesController = minnodes.NewController(fakeClock, env.Client, cluster, prov)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cluster.Reset()
+	cloudProvider.Reset()
+})
+
+var nodePool *v1beta1.NodePool
+
+var _ = Describe("MinNodes", func() {
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{MinNodes: lo.ToPtr[int32](2)}})
+	})
+	It("should launch nodeclaims up to minNodes", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(2))
+		for _, nodeClaim := range nodeClaims {
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+		}
+	})
+	It("should only launch the nodeclaims that the nodepool is missing", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+	})
+	It("should not launch again while launched nodeclaims are registering", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+	})
+	It("should replace nodes that are marked for deletion", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		cluster.MarkForDeletion(nodeClaim.Status.ProviderID)
+
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(3))
+	})
+	It("should not launch nodeclaims for a nodepool without minNodes", func() {
+		nodePool.Spec.MinNodes = nil
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+	It("should launch nodeclaims up to the minNodes of an active scaling window", func() {
+		nodePool.Spec.Schedule = []v1beta1.ScalingWindow{{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 3}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		result := ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(3))
+	})
+	It("should not apply the minNodes of a scaling window that isn't active", func() {
+		nodePool.Spec.MinNodes = nil
+		nodePool.Spec.Schedule = []v1beta1.ScalingWindow{{Schedule: fmt.Sprintf("0 0 * * %s", fakeClock.Now().UTC().Add(48 * time.Hour).Weekday().String()[:3]), Duration: metav1.Duration{Duration: time.Hour}, MinNodes: 3}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		result := ExpectReconcileSucceeded(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+	It("should not launch nodeclaims when the nodepool's limits are exceeded", func() {
+		nodePool.Spec.Limits = v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")})
+		nodePool.Status.Resources = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileFailed(ctx, minNodesController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/equality"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	provisioningscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*Controller)(nil)
+
+// Controller dry-runs the launch of each NodePool's NodeClaim template when the NodePool changes, and sets the
+// NodeClaimTemplateInvalid status condition on the NodePools that the cloud provider would fail to launch. It's a no-op
+// for cloud providers that don't implement cloudprovider.DryRunCreator.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodePool](kubeClient, &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	})
+}
+
+// Reconcile a control loop for the resource
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool) (reconcile.Result, error) {
+	dryRunCreator, ok := cloudprovider.As[cloudprovider.DryRunCreator](c.cloudProvider)
+	if !ok || !nodePool.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	stored := nodePool.DeepCopy()
+	instanceTypes, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting instance types, %w", err)
+	}
+	nodeClaimTemplate := provisioningscheduling.NewNodeClaimTemplate(nodePool)
+	nodeClaimTemplate.InstanceTypeOptions = lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+		return nodeClaimTemplate.Requirements.Compatible(it.Requirements, scheduling.AllowUndefinedWellKnownLabels) == nil &&
+			len(it.Offerings.Available().Compatible(nodeClaimTemplate.Requirements)) > 0
+	})
+	if len(nodeClaimTemplate.InstanceTypeOptions) == 0 {
+		nodePool.StatusConditions().MarkTrueWithReason(v1beta1.NodeClaimTemplateInvalid, "NoCompatibleInstanceTypes",
+			"no instance types with available offerings satisfy the requirements")
+	} else if err = dryRunCreator.CreateDryRun(ctx, nodeClaimTemplate.ToNodeClaim(nodePool)); err != nil {
+		// The NodeClass may not have resolved its fields yet, which doesn't mean that the template is invalid
+		if cloudprovider.IsNodeClassNotReadyError(err) {
+			return reconcile.Result{RequeueAfter: time.Minute}, nil
+		}
+		nodePool.StatusConditions().MarkTrueWithReason(v1beta1.NodeClaimTemplateInvalid, "DryRunFailed", err.Error())
+	} else {
+		_ = nodePool.StatusConditions().ClearCondition(v1beta1.NodeClaimTemplateInvalid)
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		if err = c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFrom(stored)); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Name() string {
+	return "nodepool.validation"
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		// Status updates, including our own, don't change the template
+		For(&v1beta1.NodePool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}))
+}
@@ -0,0 +1,142 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/metrics"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/validation"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var nodePoolController controller.Controller
+var ctx context.Context
+var env *test.Environment
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Validation")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	nodePoolController = validation.NewController(env.Client, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+})
+
+var _ = Describe("Validation", func() {
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		ExpectApplied(ctx, env.Client, nodePool)
+	})
+	It("should dry-run the launch of the nodepool's nodeclaim template", func() {
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(cloudProvider.CreateDryRunCalls).To(HaveLen(1))
+		nodeClaim := cloudProvider.CreateDryRunCalls[0]
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)
+		Expect(requirements.Get(v1.LabelInstanceTypeStable).Len()).To(BeNumerically(">", 0))
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.NodeClaimTemplateInvalid)).To(BeNil())
+	})
+	It("should mark a nodepool invalid when the dry-run fails", func() {
+		cloudProvider.CreateDryRunErr = fmt.Errorf("image not found")
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		condition := ExpectStatusConditionExists(nodePool, v1beta1.NodeClaimTemplateInvalid)
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("DryRunFailed"))
+		Expect(condition.Message).To(ContainSubstring("image not found"))
+	})
+	It("should mark a nodepool invalid when no instance types are compatible with its requirements", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"does-not-exist"}}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(cloudProvider.CreateDryRunCalls).To(BeEmpty())
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		condition := ExpectStatusConditionExists(nodePool, v1beta1.NodeClaimTemplateInvalid)
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("NoCompatibleInstanceTypes"))
+	})
+	It("should clear the condition once the dry-run succeeds", func() {
+		cloudProvider.CreateDryRunErr = fmt.Errorf("image not found")
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(ExpectStatusConditionExists(nodePool, v1beta1.NodeClaimTemplateInvalid).Status).To(Equal(v1.ConditionTrue))
+
+		cloudProvider.CreateDryRunErr = nil
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.NodeClaimTemplateInvalid)).To(BeNil())
+	})
+	It("should not mark a nodepool invalid when its nodeclass isn't ready", func() {
+		cloudProvider.CreateDryRunErr = cloudprovider.NewNodeClassNotReadyError(fmt.Errorf("nodeclass not ready"))
+		result := ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.NodeClaimTemplateInvalid)).To(BeNil())
+	})
+	It("should dry-run the launch through decorators of the cloudprovider", func() {
+		cloudProvider.CreateDryRunErr = fmt.Errorf("image not found")
+		decoratedController := validation.NewController(env.Client, metrics.Decorate(cloudProvider))
+		ExpectReconcileSucceeded(ctx, decoratedController, client.ObjectKeyFromObject(nodePool))
+		Expect(cloudProvider.CreateDryRunCalls).To(HaveLen(1))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(ExpectStatusConditionExists(nodePool, v1beta1.NodeClaimTemplateInvalid).Reason).To(Equal("DryRunFailed"))
+	})
+	It("should do nothing when the decorated cloudprovider doesn't implement DryRunCreator", func() {
+		decoratedController := validation.NewController(env.Client, metrics.Decorate(struct{ cloudprovider.CloudProvider }{cloudProvider}))
+		ExpectReconcileSucceeded(ctx, decoratedController, client.ObjectKeyFromObject(nodePool))
+		Expect(cloudProvider.CreateDryRunCalls).To(BeEmpty())
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().GetCondition(v1beta1.NodeClaimTemplateInvalid)).To(BeNil())
+	})
+})
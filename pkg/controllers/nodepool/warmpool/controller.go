@@ -0,0 +1,146 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*Controller)(nil)
+
+// Controller keeps the number of NodeClaims in each NodePool's warm pool at the warm pool's size. It launches warm
+// NodeClaims to replace the ones that are claimed, and removes warm NodeClaims that are surplus or no longer match
+// the NodePool. The lifecycle controller stops warm NodeClaims once they've initialized.
+type Controller struct {
+	kubeClient    client.Client
+	cluster       *state.Cluster
+	cloudProvider cloudprovider.CloudProvider
+	provisioner   *provisioning.Provisioner
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cluster *state.Cluster, cloudProvider cloudprovider.CloudProvider, provisioner *provisioning.Provisioner) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodePool](kubeClient, &Controller{
+		kubeClient:    kubeClient,
+		cluster:       cluster,
+		cloudProvider: cloudProvider,
+		provisioner:   provisioner,
+	})
+}
+
+// Reconcile a control loop for the resource
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool) (reconcile.Result, error) {
+	// Warm NodeClaims are only worth keeping around if their instances can be stopped
+	if _, ok := cloudprovider.As[cloudprovider.WarmPoolProvider](c.cloudProvider); !ok || !nodePool.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	// We need to ensure that our internal cluster state mechanism is synced before we proceed
+	// Otherwise, we may not know about all of the warm NodeClaims in the nodepool and launch more than we need
+	if !c.cluster.Synced(ctx) {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+	warm := lo.Filter(c.cluster.Nodes(), func(n *state.StateNode, _ int) bool {
+		return n.Warm() && n.Labels()[v1beta1.NodePoolLabelKey] == nodePool.Name && !n.MarkedForDeletion()
+	})
+	if nodePool.Spec.WarmPool == nil && len(warm) == 0 {
+		return reconcile.Result{}, nil
+	}
+	// Warm NodeClaims that no longer match the nodepool would be disrupted as soon as they're claimed, so they're
+	// replaced while they're still in the warm pool
+	current := lo.Filter(warm, func(n *state.StateNode, _ int) bool { return !stale(nodePool, n.NodeClaim) })
+	size := int(lo.FromPtr(nodePool.Spec.WarmPool).Size)
+	removed := lo.Filter(warm, func(n *state.StateNode, _ int) bool { return stale(nodePool, n.NodeClaim) })
+	if len(current) > size {
+		removed = append(removed, current[size:]...)
+		current = current[:size]
+	}
+	var errs error
+	for _, n := range removed {
+		if err := c.kubeClient.Delete(ctx, n.NodeClaim); client.IgnoreNotFound(err) != nil {
+			errs = multierr.Append(errs, fmt.Errorf("deleting warm nodeclaim, %w", err))
+			continue
+		}
+		logging.FromContext(ctx).With("nodeclaim", n.NodeClaim.Name).Infof("removed nodeclaim from warm pool")
+	}
+	if errs != nil {
+		return reconcile.Result{}, errs
+	}
+	if err := nodePool.RuntimeValidate(); err != nil {
+		logging.FromContext(ctx).Errorf("nodepool failed validation, %s", err)
+		return reconcile.Result{}, nil
+	}
+	for i := len(current); i < size; i++ {
+		nodeClaim, err := c.provisioner.NodeClaimForNodePool(ctx, nodePool)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1beta1.WarmPoolAnnotationKey: "true"})
+		nodeClaim.Spec.Taints = append(append([]v1.Taint{}, nodeClaim.Spec.Taints...), v1beta1.WarmPoolNoScheduleTaint)
+		if _, err = c.provisioner.Create(ctx, nodeClaim, provisioning.WithReason(metrics.WarmPoolReason)); err != nil {
+			return reconcile.Result{}, fmt.Errorf("creating warm nodeclaim, %w", err)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// stale returns whether a warm NodeClaim was launched from an older version of the nodepool or has drifted
+func stale(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) bool {
+	return nodeClaim.Annotations[v1beta1.NodePoolHashAnnotationKey] != nodePool.Hash() ||
+		nodeClaim.StatusConditions().GetCondition(v1beta1.Drifted).IsTrue()
+}
+
+func (c *Controller) Name() string {
+	return "nodepool.warmpool"
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1beta1.NodePool{}).
+		Watches(
+			&v1beta1.NodeClaim{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+				if name, ok := o.GetLabels()[v1beta1.NodePoolLabelKey]; ok {
+					return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+				}
+				return nil
+			}),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmpool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clock "k8s.io/utils/clock/testing"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/warmpool"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var warmPoolController controller.Controller
+var nodeClaimController controller.Controller
+var ctx context.Context
+var env *test.Environment
+var cluster *state.Cluster
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "WarmPool")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodeClaimController = informer.NewNodeClaimController(env.Client, cluster)
+	prov := provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
+	warmPoolController = warmpool.NewController(env.Client, cluster, cloudProvider, prov)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cluster.Reset()
+	cloudProvider.Reset()
+})
+
+var nodePool *v1beta1.NodePool
+
+var _ = Describe("WarmPool", func() {
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{WarmPool: &v1beta1.WarmPool{Size: 2}}})
+	})
+	warmNodeClaim := func(hash string) *v1beta1.NodeClaim {
+		return test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+				Annotations: map[string]string{
+					v1beta1.WarmPoolAnnotationKey:     "true",
+					v1beta1.NodePoolHashAnnotationKey: hash,
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+	}
+	It("should launch warm nodeclaims up to the warm pool's size", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(2))
+		for _, nodeClaim := range nodeClaims {
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+			Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1beta1.WarmPoolAnnotationKey, "true"))
+			Expect(nodeClaim.Spec.Taints).To(ContainElement(v1beta1.WarmPoolNoScheduleTaint))
+		}
+	})
+	It("should only launch the warm nodeclaims that the warm pool is missing", func() {
+		nodeClaim := warmNodeClaim(nodePool.Hash())
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+	})
+	It("should not count nodes that aren't in the warm pool", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(3))
+	})
+	It("should remove warm nodeclaims beyond the warm pool's size", func() {
+		nodePool.Spec.WarmPool.Size = 1
+		nodeClaims := []*v1beta1.NodeClaim{warmNodeClaim(nodePool.Hash()), warmNodeClaim(nodePool.Hash())}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1])
+		for _, nodeClaim := range nodeClaims {
+			ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		}
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+	})
+	It("should replace warm nodeclaims that were launched from an older version of the nodepool", func() {
+		nodeClaim := warmNodeClaim("stale-hash")
+		nodePool.Spec.WarmPool.Size = 1
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Annotations).To(HaveKeyWithValue(v1beta1.NodePoolHashAnnotationKey, nodePool.Hash()))
+	})
+	It("should remove all warm nodeclaims when the nodepool no longer has a warm pool", func() {
+		nodeClaim := warmNodeClaim(nodePool.Hash())
+		nodePool.Spec.WarmPool = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+	It("should not launch warm nodeclaims for a nodepool without a warm pool", func() {
+		nodePool.Spec.WarmPool = nil
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, warmPoolController, client.ObjectKeyFromObject(nodePool))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+})
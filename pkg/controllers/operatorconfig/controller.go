@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	"knative.dev/pkg/system"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// ConfigMapName is the name of the ConfigMap in Karpenter's namespace that overrides the reloadable options. Its keys
+// are the flag names of the options, e.g. "batch-idle-duration", "feature-gates" and "log-level".
+const ConfigMapName = "karpenter-operator-config"
+
+// Controller reloads the operator's options from the operator config ConfigMap whenever it changes, so that tuning
+// them doesn't require restarting the controller and dropping its in-flight state. Options that the ConfigMap doesn't
+// set, or all of them once it's deleted, go back to the values from the flags and environment variables.
+type Controller struct {
+	kubeClient client.Client
+}
+
+func NewController(kubeClient client.Client) operatorcontroller.Controller {
+	return &Controller{
+		kubeClient: kubeClient,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "operator.config"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	cm := &v1.ConfigMap{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Namespace: system.Namespace(), Name: ConfigMapName}, cm); client.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("getting operator config, %w", err)
+	}
+	previous := options.FromContext(ctx)
+	reloaded, err := options.ReloadContext(ctx, cm.Data)
+	if err != nil {
+		// Retrying won't fix the config, so the current options are kept until the ConfigMap is updated again
+		logging.FromContext(ctx).Errorf("reloading operator config, %s", err)
+		return reconcile.Result{}, nil
+	}
+	if reflect.DeepEqual(previous, reloaded) {
+		return reconcile.Result{}, nil
+	}
+	if reloaded.LogLevel != previous.LogLevel {
+		if err = operatorlogging.SetLevel(reloaded.LogLevel); err != nil {
+			return reconcile.Result{}, fmt.Errorf("setting log level, %w", err)
+		}
+	}
+	logging.FromContext(ctx).With("overrides", cm.Data).Infof("reloaded operator config")
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == system.Namespace() && o.GetName() == ConfigMapName
+		}))).
+		// Every replica reloads its options, so that a replica that becomes the leader already runs with them
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1, NeedLeaderElection: ptr.Bool(false)}),
+	)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/controllers/operatorconfig"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var env *test.Environment
+var operatorConfigController controller.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OperatorConfig")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	operatorConfigController = operatorconfig.NewController(env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+		BatchIdleDuration: lo.ToPtr(time.Second),
+		BatchMaxDuration:  lo.ToPtr(10 * time.Second),
+	}))
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	Expect(client.IgnoreNotFound(env.Client.Delete(ctx, configMap(nil)))).To(Succeed())
+})
+
+func configMap(data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: operatorconfig.ConfigMapName},
+		Data:       data,
+	}
+}
+
+func reconcileConfig() {
+	ExpectReconcileSucceeded(ctx, operatorConfigController, client.ObjectKeyFromObject(configMap(nil)))
+}
+
+var _ = Describe("OperatorConfig", func() {
+	It("should reload the batch durations", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{
+			"batch-idle-duration": "3s",
+			"batch-max-duration":  "30s",
+		}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).BatchIdleDuration).To(Equal(3 * time.Second))
+		Expect(options.FromContext(ctx).BatchMaxDuration).To(Equal(30 * time.Second))
+	})
+	It("should reload the options of contexts derived from the reloaded context", func() {
+		derived, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"batch-idle-duration": "3s"}))
+		reconcileConfig()
+		Expect(options.FromContext(derived).BatchIdleDuration).To(Equal(3 * time.Second))
+	})
+	It("should only override the feature gates that are set", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{Drift: lo.ToPtr(true)}}))
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"feature-gates": "SpotToSpotConsolidation=true"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).FeatureGates.Drift).To(BeTrue())
+		Expect(options.FromContext(ctx).FeatureGates.SpotToSpotConsolidation).To(BeTrue())
+	})
+	It("should reload the log level", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"log-level": "debug"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).LogLevel).To(Equal("debug"))
+	})
+	It("should restore the injected options once the config is deleted", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"batch-idle-duration": "3s"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).BatchIdleDuration).To(Equal(3 * time.Second))
+
+		ExpectDeleted(ctx, env.Client, configMap(nil))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).BatchIdleDuration).To(Equal(time.Second))
+	})
+	It("should keep the current options when the config is invalid", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"batch-idle-duration": "3s"}))
+		reconcileConfig()
+
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"batch-idle-duration": "3s", "batch-max-duration": "soon"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).BatchIdleDuration).To(Equal(3 * time.Second))
+		Expect(options.FromContext(ctx).BatchMaxDuration).To(Equal(10 * time.Second))
+	})
+	It("should keep the current options when the config sets an option that can't be reloaded", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"batch-idle-duration": "3s", "metrics-port": "9000"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).BatchIdleDuration).To(Equal(time.Second))
+	})
+	It("should keep the current options when the config sets an invalid log level", func() {
+		ExpectApplied(ctx, env.Client, configMap(map[string]string{"log-level": "verbose"}))
+		reconcileConfig()
+		Expect(options.FromContext(ctx).LogLevel).To(Equal(test.Options().LogLevel))
+	})
+})
@@ -47,14 +47,14 @@ func (b *Batcher) Trigger() {
 	}
 }
 
-// Wait starts a batching window and continues waiting as long as it continues receiving triggers within
-// the idleDuration, up to the maxDuration
+// Wait blocks until the first trigger arrives, then starts a batching window and continues waiting as long as it
+// continues receiving triggers within the idleDuration, up to the maxDuration. It returns false if the context is
+// cancelled before the first trigger arrives.
 func (b *Batcher) Wait(ctx context.Context) bool {
 	select {
 	case <-b.trigger:
 		// start the batching window after the first item is received
-	case <-time.After(1 * time.Second):
-		// If no pods, bail to the outer controller framework to refresh the context
+	case <-ctx.Done():
 		return false
 	}
 	timeout := time.NewTimer(options.FromContext(ctx).BatchMaxDuration)
@@ -22,10 +22,14 @@ import (
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
@@ -65,18 +69,38 @@ func (c *PodController) Reconcile(_ context.Context, p *v1.Pod) (reconcile.Resul
 	// Continue to requeue until the pod is no longer provisionable. Pods may
 	// not be scheduled as expected if new pods are created while nodes are
 	// coming online. Even if a provisioning loop is successful, the pod may
-	// require another provisioning loop to become schedulable.
+	// require another provisioning loop to become schedulable, e.g. once a
+	// nodepool's limits are freed or its offerings become available again,
+	// which doesn't change the pod.
 	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 }
 
 func (*PodController) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
 	return operatorcontroller.Adapt(controllerruntime.
 		NewControllerManagedBy(m).
-		For(&v1.Pod{}).
+		For(&v1.Pod{}, builder.WithPredicates(
+			predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool { return true },
+				// The kube-scheduler updates the PodScheduled condition each time it fails to schedule the pod, so
+				// pods that are still pending after a provisioning loop trigger another one when it retries them
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !equality.Semantic.DeepEqual(podScheduledCondition(e.ObjectOld.(*v1.Pod)), podScheduledCondition(e.ObjectNew.(*v1.Pod)))
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool { return false },
+			},
+		)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
 	)
 }
 
+func podScheduledCondition(p *v1.Pod) *v1.PodCondition {
+	condition, ok := lo.Find(p.Status.Conditions, func(c v1.PodCondition) bool { return c.Type == v1.PodScheduled })
+	if !ok {
+		return nil
+	}
+	return &condition
+}
+
 var _ operatorcontroller.TypedController[*v1.Node] = (*NodeController)(nil)
 
 // NodeController for the resource
@@ -108,17 +132,120 @@ func (c *NodeController) Reconcile(_ context.Context, n *v1.Node) (reconcile.Res
 		return reconcile.Result{}, nil
 	}
 	c.provisioner.Trigger()
-	// Continue to requeue until the node is no longer provisionable. Pods may
-	// not be scheduled as expected if new pods are created while nodes are
-	// coming online. Even if a provisioning loop is successful, the pod may
-	// require another provisioning loop to become schedulable.
-	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+	return reconcile.Result{}, nil
 }
 
 func (*NodeController) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
 	return operatorcontroller.Adapt(controllerruntime.
 		NewControllerManagedBy(m).
-		For(&v1.Node{}).
+		For(&v1.Node{}, builder.WithPredicates(
+			predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool { return true },
+				// Nodes only trigger the provisioner when they're tainted for disruption, and the pods that are
+				// evicted from them trigger it again once they're pending
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return lo.Contains(e.ObjectOld.(*v1.Node).Spec.Taints, v1beta1.DisruptionNoScheduleTaint) !=
+						lo.Contains(e.ObjectNew.(*v1.Node).Spec.Taints, v1beta1.DisruptionNoScheduleTaint)
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool { return false },
+			},
+		)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
+	)
+}
+
+var _ operatorcontroller.TypedController[*v1beta1.NodePool] = (*NodePoolController)(nil)
+
+// NodePoolController for the resource
+type NodePoolController struct {
+	kubeClient  client.Client
+	provisioner *Provisioner
+}
+
+// NewNodePoolController constructs a controller instance
+func NewNodePoolController(kubeClient client.Client, provisioner *Provisioner) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodePool](kubeClient, &NodePoolController{
+		kubeClient:  kubeClient,
+		provisioner: provisioner,
+	})
+}
+
+func (*NodePoolController) Name() string {
+	return "provisioner.trigger.nodepool"
+}
+
+// Reconcile the resource
+func (c *NodePoolController) Reconcile(_ context.Context, np *v1beta1.NodePool) (reconcile.Result, error) {
+	if np.Spec.Headroom == nil || !np.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	c.provisioner.Trigger()
+	// Continue to requeue while the nodepool has headroom. Pods that schedule to existing nodes use up headroom
+	// without anything else triggering the provisioner, so the provisioner needs to check periodically whether the
+	// headroom still fits.
+	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+func (*NodePoolController) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1beta1.NodePool{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
+	)
+}
+
+var _ operatorcontroller.TypedController[*v1beta1.NodeClaim] = (*NodeClaimController)(nil)
+
+// NodeClaimController for the resource
+type NodeClaimController struct {
+	kubeClient  client.Client
+	provisioner *Provisioner
+}
+
+// NewNodeClaimController constructs a controller instance
+func NewNodeClaimController(kubeClient client.Client, provisioner *Provisioner) operatorcontroller.Controller {
+	return operatorcontroller.Typed[*v1beta1.NodeClaim](kubeClient, &NodeClaimController{
+		kubeClient:  kubeClient,
+		provisioner: provisioner,
+	})
+}
+
+func (*NodeClaimController) Name() string {
+	return "provisioner.trigger.nodeclaim"
+}
+
+// Reconcile the resource
+func (c *NodeClaimController) Reconcile(_ context.Context, nc *v1beta1.NodeClaim) (reconcile.Result, error) {
+	// A NodeClaim that's deleted before it launches, e.g. because its nodepool is out of capacity, leaves its pods
+	// pending. We trigger right away so that the pods are scheduled against the next compatible nodepool, rather than
+	// waiting for the pods to trigger the provisioner again.
+	if !nc.DeletionTimestamp.IsZero() && !nc.StatusConditions().GetCondition(v1beta1.Launched).IsTrue() {
+		c.provisioner.Trigger()
+		return reconcile.Result{}, nil
+	}
+	// Pods that were nominated for a NodeClaim while it was in flight may not fit on it once it initializes, e.g.
+	// because its allocatable is smaller than expected. We trigger once it initializes, so that those pods get
+	// another provisioning loop.
+	if nc.DeletionTimestamp.IsZero() && nc.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue() {
+		c.provisioner.Trigger()
+	}
+	return reconcile.Result{}, nil
+}
+
+func (*NodeClaimController) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1beta1.NodeClaim{}, builder.WithPredicates(
+			predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool { return false },
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldNodeClaim, newNodeClaim := e.ObjectOld.(*v1beta1.NodeClaim), e.ObjectNew.(*v1beta1.NodeClaim)
+					return oldNodeClaim.DeletionTimestamp.IsZero() != newNodeClaim.DeletionTimestamp.IsZero() ||
+						oldNodeClaim.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue() != newNodeClaim.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue()
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool { return false },
+			},
+		)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
 	)
 }
@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// HeadroomPods returns a placeholder pod for each unit of headroom in the nodepools. They're scheduled alongside the
+// pending pods, so that nodes are launched for headroom that doesn't fit on the existing nodes, and so that
+// consolidation keeps the capacity that the headroom needs. The placeholder pods are never created in the cluster.
+func (p *Provisioner) HeadroomPods(ctx context.Context) ([]*v1.Pod, error) {
+	nodePoolList := &v1beta1.NodePoolList{}
+	if err := p.kubeClient.List(ctx, nodePoolList); err != nil {
+		return nil, fmt.Errorf("listing node pools, %w", err)
+	}
+	var pods []*v1.Pod
+	for i := range nodePoolList.Items {
+		nodePool := &nodePoolList.Items[i]
+		if nodePool.Spec.Headroom == nil || !nodePool.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for j := 0; j < int(nodePool.Spec.Headroom.Count); j++ {
+			pods = append(pods, headroomPod(nodePool, j))
+		}
+	}
+	return pods, nil
+}
+
+func headroomPod(nodePool *v1beta1.NodePool, i int) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-headroom-%d", nodePool.Name, i),
+			UID:  types.UID(fmt.Sprintf("%s-headroom-%d", nodePool.UID, i)),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       "NodePool",
+				Name:       nodePool.Name,
+				UID:        nodePool.UID,
+			}},
+		},
+		Spec: v1.PodSpec{
+			NodeSelector: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+			Tolerations:  []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			// Headroom is scheduled after every real pod, so that it never takes capacity that a pod needs
+			Priority: lo.ToPtr[int32](math.MinInt32),
+			Containers: []v1.Container{{
+				Name:      "headroom",
+				Resources: v1.ResourceRequirements{Requests: nodePool.Spec.Headroom.Resources},
+			}},
+		},
+		// Headroom is pending so that consolidation treats it the same as a pod that was pending before consolidation,
+		// and doesn't block if it can't schedule at all
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{{
+				Type:   v1.PodScheduled,
+				Status: v1.ConditionFalse,
+				Reason: v1.PodReasonUnschedulable,
+			}},
+		},
+	}
+}
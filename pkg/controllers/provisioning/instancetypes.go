@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"sync"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+)
+
+// instanceTypeCache caches the instance types of each nodepool along with the topology domains that they contribute,
+// so that scheduling only rebuilds them for the nodepools whose instance types have changed. It's only used for
+// cloudproviders that notify of offering changes, since nothing else invalidates it.
+type instanceTypeCache struct {
+	mu      sync.RWMutex
+	entries map[string]*instanceTypeCacheEntry
+	// version is incremented whenever entries are invalidated, so that instance types that were retrieved before an
+	// invalidation aren't cached after it
+	version uint64
+}
+
+type instanceTypeCacheEntry struct {
+	// uid and generation identify the version of the nodepool that the entry was built for
+	uid           types.UID
+	generation    int64
+	instanceTypes []*cloudprovider.InstanceType
+	domains       map[string]sets.Set[string]
+}
+
+func newInstanceTypeCache() *instanceTypeCache {
+	return &instanceTypeCache{entries: map[string]*instanceTypeCacheEntry{}}
+}
+
+// invalidate removes the entries of the nodepools that have any of the instance types, or every entry if no instance
+// types are given
+func (c *instanceTypeCache) invalidate(instanceTypes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	if len(instanceTypes) == 0 {
+		c.entries = map[string]*instanceTypeCacheEntry{}
+		return
+	}
+	changed := sets.New(instanceTypes...)
+	for name, entry := range c.entries {
+		if lo.ContainsBy(entry.instanceTypes, func(it *cloudprovider.InstanceType) bool { return changed.Has(it.Name) }) {
+			delete(c.entries, name)
+		}
+	}
+}
+
+// retain removes the entries of nodepools other than the given ones, so that deleted nodepools aren't cached forever
+func (c *instanceTypeCache) retain(nodePoolNames sets.Set[string]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.entries {
+		if !nodePoolNames.Has(name) {
+			delete(c.entries, name)
+		}
+	}
+}
+
+// getInstanceTypes returns the nodepool's instance types and the topology domains that they contribute, from the
+// cache if the cloudprovider notifies of offering changes
+func (p *Provisioner) getInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, map[string]sets.Set[string], error) {
+	if p.instanceTypeCache == nil {
+		instanceTypes, err := p.cloudProvider.GetInstanceTypes(ctx, nodePool)
+		if err != nil {
+			return nil, nil, err
+		}
+		instanceTypes = p.withPodsCapacity(ctx, nodePool, instanceTypes)
+		return instanceTypes, scheduler.TopologyDomains(nodePool, instanceTypes), nil
+	}
+	p.instanceTypeCache.mu.RLock()
+	entry, ok := p.instanceTypeCache.entries[nodePool.Name]
+	version := p.instanceTypeCache.version
+	p.instanceTypeCache.mu.RUnlock()
+	if ok && entry.uid == nodePool.UID && entry.generation == nodePool.Generation {
+		return entry.instanceTypes, entry.domains, nil
+	}
+	instanceTypes, err := p.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, nil, err
+	}
+	instanceTypes = p.withPodsCapacity(ctx, nodePool, instanceTypes)
+	entry = &instanceTypeCacheEntry{
+		uid:           nodePool.UID,
+		generation:    nodePool.Generation,
+		instanceTypes: instanceTypes,
+		domains:       scheduler.TopologyDomains(nodePool, instanceTypes),
+	}
+	p.instanceTypeCache.mu.Lock()
+	defer p.instanceTypeCache.mu.Unlock()
+	if p.instanceTypeCache.version == version {
+		p.instanceTypeCache.entries[nodePool.Name] = entry
+	}
+	return entry.instanceTypes, entry.domains, nil
+}
+
+// withPodsCapacity sets the pods capacity of the instance types to the number of pods that the nodepool's nodes can run,
+// which can be lower than the instance types report when the cloudprovider's CNI limits the pods of each node or the
+// nodepool's kubelet configuration overrides them
+func (p *Provisioner) withPodsCapacity(ctx context.Context, nodePool *v1beta1.NodePool, instanceTypes []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+	return lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) *cloudprovider.InstanceType {
+		return it.WithPodsCapacity(cloudprovider.MaxPods(ctx, p.cloudProvider, it, nodePool.Spec.Template.Spec.Kubelet))
+	})
+}
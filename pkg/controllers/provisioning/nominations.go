@@ -0,0 +1,89 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// MaxPodNominations bounds the number of pods whose nomination annotations are patched in a single scheduling run, so
+// that a large batch of pending pods doesn't flood the API server. Nominations are tracked per node, so the pods that
+// aren't annotated are still covered by the nominations of the other pods on their node.
+const MaxPodNominations = 100
+
+// MaxFailedSchedulingConditions bounds the number of pods whose failed scheduling condition is updated in a single
+// scheduling run, so that a large batch of unschedulable pods doesn't flood the API server
+const MaxFailedSchedulingConditions = 100
+
+// annotatePodNominations annotates the pods that were scheduled to existing or inflight nodes with the node that they
+// were nominated to and when the nomination expires, so that observers can see the intended placement and the
+// nominations can be rebuilt after a restart
+func (p *Provisioner) annotatePodNominations(ctx context.Context, results scheduler.Results) {
+	nominations := p.cluster.Nominations()
+	var pods []*v1.Pod
+	var providerIDs []string
+	for _, n := range results.ExistingNodes {
+		until, ok := nominations[n.ProviderID()]
+		if !ok {
+			continue
+		}
+		for _, pod := range n.Pods {
+			// Headroom pods don't exist in the cluster, and pods that are already annotated for the node only need to be
+			// patched once their nomination has been extended by more than half of the nomination window
+			if podutil.IsOwnedByNodePool(pod) || !nominationChanged(pod, n.ProviderID(), until, p.clock.Now()) {
+				continue
+			}
+			pods = append(pods, pod)
+			providerIDs = append(providerIDs, n.ProviderID())
+		}
+	}
+	if len(pods) > MaxPodNominations {
+		logging.FromContext(ctx).With("pods", len(pods)).Debugf("only annotating the nominations of %d pod(s)", MaxPodNominations)
+		pods, providerIDs = pods[:MaxPodNominations], providerIDs[:MaxPodNominations]
+	}
+	workqueue.ParallelizeUntil(ctx, 10, len(pods), func(i int) {
+		stored := pods[i].DeepCopy()
+		pods[i].Annotations = lo.Assign(pods[i].Annotations, map[string]string{
+			v1beta1.NominatedNodeAnnotationKey:  providerIDs[i],
+			v1beta1.NominatedUntilAnnotationKey: nominations[providerIDs[i]].UTC().Format(time.RFC3339),
+		})
+		if err := p.kubeClient.Patch(ctx, pods[i], client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pods[i])).Errorf("annotating pod nomination, %s", err)
+		}
+	})
+}
+
+// nominationChanged returns true if the pod isn't annotated with the nomination, or its annotated nomination expires
+// sooner than halfway between now and the nomination's expiry
+func nominationChanged(pod *v1.Pod, providerID string, until, now time.Time) bool {
+	annotatedProviderID, annotatedUntil, ok := podutil.Nomination(pod)
+	if !ok || annotatedProviderID != providerID {
+		return true
+	}
+	return annotatedUntil.Before(until.Add(-until.Sub(now) / 2))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,21 +29,27 @@ import (
 	"go.uber.org/multierr"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	nodeutil "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/utils/functional"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
@@ -56,6 +63,7 @@ import (
 type LaunchOptions struct {
 	RecordPodNomination bool
 	Reason              string
+	ReplacedProviderIDs []string
 }
 
 // RecordPodNomination causes nominate pod events to be recorded against the node.
@@ -64,6 +72,15 @@ func RecordPodNomination(o LaunchOptions) LaunchOptions {
 	return o
 }
 
+// Replaces marks the NodeClaim as a replacement for the nodes that are removed once it's ready. Replacements are
+// checked against the nodepool's limits as if the capacity of the nodes they replace had already been removed.
+func Replaces(providerIDs ...string) func(LaunchOptions) LaunchOptions {
+	return func(o LaunchOptions) LaunchOptions {
+		o.ReplacedProviderIDs = providerIDs
+		return o
+	}
+}
+
 func WithReason(reason string) func(LaunchOptions) LaunchOptions {
 	return func(o LaunchOptions) LaunchOptions {
 		o.Reason = reason
@@ -73,26 +90,39 @@ func WithReason(reason string) func(LaunchOptions) LaunchOptions {
 
 // Provisioner waits for enqueued pods, batches them, creates capacity and binds the pods to the capacity.
 type Provisioner struct {
+	clock          clock.Clock
 	cloudProvider  cloudprovider.CloudProvider
 	kubeClient     client.Client
 	batcher        *Batcher
 	volumeTopology *scheduler.VolumeTopology
+	resourceClaims *scheduler.ResourceClaims
 	cluster        *state.Cluster
 	recorder       events.Recorder
 	cm             *pretty.ChangeMonitor
+	// instanceTypeCache is nil if the cloudprovider doesn't notify of offering changes
+	instanceTypeCache *instanceTypeCache
+	// daemonOverheadCache is keyed on the daemonset pods, so daemonset changes are picked up without invalidating it
+	daemonOverheadCache *scheduler.DaemonOverheadCache
 }
 
 func NewProvisioner(kubeClient client.Client, recorder events.Recorder,
-	cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster,
+	cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster, clock clock.Clock,
 ) *Provisioner {
 	p := &Provisioner{
-		batcher:        NewBatcher(),
-		cloudProvider:  cloudProvider,
-		kubeClient:     kubeClient,
-		volumeTopology: scheduler.NewVolumeTopology(kubeClient),
-		cluster:        cluster,
-		recorder:       recorder,
-		cm:             pretty.NewChangeMonitor(),
+		clock:               clock,
+		batcher:             NewBatcher(),
+		cloudProvider:       cloudProvider,
+		kubeClient:          kubeClient,
+		volumeTopology:      scheduler.NewVolumeTopology(kubeClient),
+		resourceClaims:      scheduler.NewResourceClaims(kubeClient),
+		cluster:             cluster,
+		recorder:            recorder,
+		cm:                  pretty.NewChangeMonitor(),
+		daemonOverheadCache: scheduler.NewDaemonOverheadCache(),
+	}
+	if offeringChangeNotifier, ok := cloudprovider.As[cloudprovider.OfferingChangeNotifier](cloudProvider); ok {
+		p.instanceTypeCache = newInstanceTypeCache()
+		offeringChangeNotifier.NotifyOfferingChange(p.instanceTypeCache.invalidate)
 	}
 	return p
 }
@@ -119,19 +149,27 @@ func (p *Provisioner) Reconcile(ctx context.Context, _ reconcile.Request) (resul
 	// a scheduling decision based on a smaller subset of nodes in our cluster state than actually exist.
 	if !p.cluster.Synced(ctx) {
 		logging.FromContext(ctx).Debugf("waiting on cluster sync")
+		// The batch was consumed without provisioning, so it's re-armed to be picked up once the cluster is synced
+		p.Trigger()
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 
 	// Schedule pods to potential nodes, exit if nothing to do
 	results, err := p.Schedule(ctx)
 	if err != nil {
+		p.Trigger()
 		return reconcile.Result{}, err
 	}
 	if len(results.NewNodeClaims) == 0 {
 		return reconcile.Result{}, nil
 	}
-	_, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisioningReason), RecordPodNomination)
-	return reconcile.Result{}, err
+	if _, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisioningReason), RecordPodNomination); err != nil {
+		// Nothing else triggers the provisioner for pods whose NodeClaims failed to create, so the next loop is
+		// triggered here. The singleton's rate limiter backs off the retries.
+		p.Trigger()
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
 }
 
 // CreateNodeClaims launches nodes passed into the function in parallel. It returns a slice of the successfully created node
@@ -141,6 +179,11 @@ func (p *Provisioner) CreateNodeClaims(ctx context.Context, nodeClaims []*schedu
 	errs := make([]error, len(nodeClaims))
 	nodeClaimNames := make([]string, len(nodeClaims))
 	workqueue.ParallelizeUntil(ctx, len(nodeClaims), len(nodeClaims), func(i int) {
+		// Starting a NodeClaim from the warm pool is quicker than launching a new one
+		if name, ok, err := p.claimWarmNodeClaim(ctx, nodeClaims[i], opts...); err != nil || ok {
+			errs[i], nodeClaimNames[i] = err, name
+			return
+		}
 		// create a new context to avoid a data race on the ctx variable
 		if name, err := p.Create(ctx, nodeClaims[i], opts...); err != nil {
 			errs[i] = fmt.Errorf("creating node claim, %w", err)
@@ -213,12 +256,18 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 	// since they are stored within a slice and scheduling
 	// will always attempt to schedule on the first nodeTemplate
 	nodePoolList.OrderByWeight()
+	// NodePools that are out of capacity or whose launches keep failing are only used for pods that can't schedule
+	// against any other NodePool
+	sort.SliceStable(nodePoolList.Items, func(i, j int) bool {
+		return p.launchRank(nodePoolList.Items[i].Name) < p.launchRank(nodePoolList.Items[j].Name)
+	})
 
 	instanceTypes := map[string][]*cloudprovider.InstanceType{}
 	domains := map[string]sets.Set[string]{}
-	for _, nodePool := range nodePoolList.Items {
+	for i := range nodePoolList.Items {
+		nodePool := &nodePoolList.Items[i]
 		// Get instance type options
-		instanceTypeOptions, err := p.cloudProvider.GetInstanceTypes(ctx, lo.ToPtr(nodePool))
+		instanceTypeOptions, nodePoolDomains, err := p.getInstanceTypes(ctx, nodePool)
 		if err != nil {
 			// we just log an error and skip the provisioner to prevent a single mis-configured provisioner from stopping
 			// all scheduling
@@ -231,43 +280,23 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 		}
 		instanceTypes[nodePool.Name] = append(instanceTypes[nodePool.Name], instanceTypeOptions...)
 
-		// Construct Topology Domains
-		for _, instanceType := range instanceTypeOptions {
-			// We need to intersect the instance type requirements with the current nodePool requirements.  This
-			// ensures that something like zones from an instance type don't expand the universe of valid domains.
-			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
-			requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
-			requirements.Add(instanceType.Requirements.Values()...)
-
-			for key, requirement := range requirements {
-				// This code used to execute a Union between domains[key] and requirement.Values().
-				// The downside of this is that Union is immutable and takes a copy of the set it is executed upon.
-				// This resulted in a lot of memory pressure on the heap and poor performance
-				// https://github.com/aws/karpenter/issues/3565
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
-				}
-			}
-		}
-
-		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
-		requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
-		for key, requirement := range requirements {
-			if requirement.Operator() == v1.NodeSelectorOpIn {
-				// The following is a performance optimisation, for the explanation see the comment above
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
-				}
+		// Construct Topology Domains. The nodepool's domains may be cached, so they're copied rather than modified.
+		for key, values := range nodePoolDomains {
+			if domains[key] == nil {
+				domains[key] = sets.New[string]()
 			}
+			domains[key].Insert(values.UnsortedList()...)
 		}
 	}
+	nodePoolNames := lo.Map(nodePoolList.Items, func(np v1beta1.NodePool, _ int) string { return np.Name })
+	if p.instanceTypeCache != nil {
+		p.instanceTypeCache.retain(sets.New(nodePoolNames...))
+	}
+	p.daemonOverheadCache.Retain(nodePoolNames...)
 
 	// inject topology constraints
 	pods = p.injectVolumeTopologyRequirements(ctx, pods)
+	pods = p.injectResourceClaimRequirements(ctx, pods)
 
 	// Calculate cluster topology
 	topology, err := scheduler.NewTopology(ctx, p.kubeClient, p.cluster, domains, pods)
@@ -278,7 +307,19 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 	if err != nil {
 		return nil, fmt.Errorf("getting daemon pods, %w", err)
 	}
-	return scheduler.NewScheduler(ctx, p.kubeClient, lo.ToSlicePtr(nodePoolList.Items), p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.recorder), nil
+	return scheduler.NewScheduler(ctx, p.kubeClient, lo.ToSlicePtr(nodePoolList.Items), p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.daemonOverheadCache, p.recorder), nil
+}
+
+// launchRank orders NodePools by how likely their launches are to succeed, which takes precedence over their weight
+func (p *Provisioner) launchRank(nodePoolName string) int {
+	switch {
+	case p.cluster.IsNodePoolUnhealthy(nodePoolName):
+		return 2
+	case p.cluster.HasInsufficientCapacity(nodePoolName):
+		return 1
+	default:
+		return 0
+	}
 }
 
 func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
@@ -310,26 +351,267 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 		return scheduler.Results{}, err
 	}
 	pods := append(pendingPods, deletingNodePods...)
+	// Headroom is scheduled alongside the pods, so that nodes are launched for any headroom that doesn't fit
+	headroomPods, err := p.HeadroomPods(ctx)
+	if err != nil {
+		return scheduler.Results{}, err
+	}
 	// nothing to schedule, so just return success
-	if len(pods) == 0 {
+	if len(pods) == 0 && len(headroomPods) == 0 {
+		p.cluster.RecordStalledScaleUps(nil)
 		return scheduler.Results{}, nil
 	}
-	s, err := p.NewScheduler(ctx, pods, nodes.Active())
+	results, err := p.solve(ctx, append(pods, headroomPods...), nodes.Active())
 	if err != nil {
 		if errors.Is(err, ErrNodePoolsNotFound) {
 			logging.FromContext(ctx).Info(ErrNodePoolsNotFound)
+			p.cluster.RecordStalledScaleUps(nil)
 			return scheduler.Results{}, nil
 		}
+		return scheduler.Results{}, err
+	}
+	p.cluster.RecordStalledScaleUps(p.stalledNodePools(results))
+	if len(pods) > 0 {
+		logging.FromContext(ctx).With("pods", pretty.Slice(lo.Map(pods, func(p *v1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() }), 5)).
+			With("duration", time.Since(start)).
+			Infof("found provisionable pod(s)")
+	}
+	results.Record(ctx, p.recorder, p.cluster)
+	p.annotatePodNominations(ctx, results)
+	p.updateFailedSchedulingConditions(ctx, results)
+	return results, nil
+}
+
+// stalledNodePools returns the nodepools that can't launch capacity for pending pods, along with the pods. These are
+// the nodepools that pods failed to schedule to because their limits are exhausted or none of their instance types
+// have capacity, and the nodepools that pods were scheduled to even though their launches are failing for
+// insufficient capacity.
+func (p *Provisioner) stalledNodePools(results scheduler.Results) map[string]sets.Set[types.NamespacedName] {
+	stalled := map[string]sets.Set[types.NamespacedName]{}
+	stall := func(nodePoolName string, pod *v1.Pod) {
+		if stalled[nodePoolName] == nil {
+			stalled[nodePoolName] = sets.New[types.NamespacedName]()
+		}
+		stalled[nodePoolName].Insert(client.ObjectKeyFromObject(pod))
+	}
+	for pod, err := range results.PodErrors {
+		// Headroom is spare capacity rather than pending pods
+		if podutil.IsOwnedByNodePool(pod) {
+			continue
+		}
+		var schedulingErr *scheduler.SchedulingError
+		if !errors.As(err, &schedulingErr) {
+			continue
+		}
+		for _, failure := range schedulingErr.NodePools {
+			if failure.Reason == scheduler.FailureReasonLimits || failure.Reason == scheduler.FailureReasonCapacity {
+				stall(failure.NodePool, pod)
+			}
+		}
+	}
+	for _, nodeClaim := range results.NewNodeClaims {
+		if !p.cluster.HasInsufficientCapacity(nodeClaim.NodePoolName) {
+			continue
+		}
+		for _, pod := range nodeClaim.Pods {
+			if !podutil.IsOwnedByNodePool(pod) {
+				stall(nodeClaim.NodePoolName, pod)
+			}
+		}
+	}
+	return stalled
+}
+
+// updateFailedSchedulingConditions sets the failed scheduling condition on pods that couldn't schedule so that users
+// can see why without digging through events or logs. The condition is removed once the pod schedules.
+func (p *Provisioner) updateFailedSchedulingConditions(ctx context.Context, results scheduler.Results) {
+	var pods []*v1.Pod
+	var conditions []*v1.PodCondition
+	for pod, err := range results.PodErrors {
+		pods = append(pods, pod)
+		conditions = append(conditions, failedSchedulingCondition(err))
+	}
+	for _, n := range results.NewNodeClaims {
+		pods = append(pods, n.Pods...)
+		conditions = append(conditions, make([]*v1.PodCondition, len(n.Pods))...)
+	}
+	for _, n := range results.ExistingNodes {
+		pods = append(pods, n.Pods...)
+		conditions = append(conditions, make([]*v1.PodCondition, len(n.Pods))...)
+	}
+	// Headroom pods don't exist in the cluster, so there's nothing to update, and pods whose condition is unchanged
+	// don't need to be patched
+	skip := lo.Map(pods, func(pod *v1.Pod, i int) bool {
+		return podutil.IsOwnedByNodePool(pod) || !failedSchedulingConditionChanged(pod, conditions[i])
+	})
+	conditions = lo.Reject(conditions, func(_ *v1.PodCondition, i int) bool { return skip[i] })
+	pods = lo.Reject(pods, func(_ *v1.Pod, i int) bool { return skip[i] })
+	// The pods that aren't updated in this run are still changed in the next one, so they're updated then
+	if len(pods) > MaxFailedSchedulingConditions {
+		logging.FromContext(ctx).With("pods", len(pods)).Debugf("only updating the failed scheduling conditions of %d pod(s)", MaxFailedSchedulingConditions)
+		pods, conditions = pods[:MaxFailedSchedulingConditions], conditions[:MaxFailedSchedulingConditions]
+	}
+	workqueue.ParallelizeUntil(ctx, 10, len(pods), func(i int) {
+		if err := p.updateFailedSchedulingCondition(ctx, pods[i], conditions[i]); err != nil {
+			logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pods[i])).Errorf("updating failed scheduling condition, %s", err)
+		}
+	})
+}
+
+// updateFailedSchedulingCondition sets the passed condition on the pod, or removes it if the condition is nil
+func (p *Provisioner) updateFailedSchedulingCondition(ctx context.Context, pod *v1.Pod, condition *v1.PodCondition) error {
+	stored := pod.DeepCopy()
+	pod.Status.Conditions = lo.Reject(pod.Status.Conditions, func(c v1.PodCondition, _ int) bool { return c.Type == v1beta1.PodFailedSchedulingCondition })
+	if condition != nil {
+		pod.Status.Conditions = append(pod.Status.Conditions, *condition)
+	}
+	return client.IgnoreNotFound(p.kubeClient.Status().Patch(ctx, pod, client.StrategicMergeFrom(stored)))
+}
+
+// failedSchedulingConditionChanged returns true if setting the passed condition on the pod would change it, where a
+// nil condition removes it
+func failedSchedulingConditionChanged(pod *v1.Pod, condition *v1.PodCondition) bool {
+	existing, found := lo.Find(pod.Status.Conditions, func(c v1.PodCondition) bool { return c.Type == v1beta1.PodFailedSchedulingCondition })
+	if condition == nil {
+		return found
+	}
+	return !found || existing.Reason != condition.Reason || existing.Message != condition.Message
+}
+
+func failedSchedulingCondition(err error) *v1.PodCondition {
+	condition := &v1.PodCondition{
+		Type:               v1beta1.PodFailedSchedulingCondition,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "Unschedulable",
+		Message:            err.Error(),
+	}
+	var schedulingErr *scheduler.SchedulingError
+	if errors.As(err, &schedulingErr) {
+		condition.Reason = schedulingErr.Reason()
+		condition.Message = schedulingErr.Explanation()
+	}
+	return condition
+}
+
+// SimulateScheduling runs the same scheduling simulation as Schedule for the passed pods against the current cluster
+// state and returns the NodeClaims that would be launched for them. Nothing is created, nominated, or published as an
+// event, so this can be used to forecast the capacity that pending workloads would need.
+func (p *Provisioner) SimulateScheduling(ctx context.Context, pods []*v1.Pod) (scheduler.Results, error) {
+	if !p.cluster.Synced(ctx) {
+		return scheduler.Results{}, fmt.Errorf("cluster state is not synced")
+	}
+	// Scheduling relaxes preferences and injects volume topology requirements on the pods it's given, so we work
+	// on copies to leave the caller's pods untouched. Results refer to these copies.
+	pods = lo.Map(pods, func(po *v1.Pod, _ int) *v1.Pod { return po.DeepCopy() })
+	podErrors := map[*v1.Pod]error{}
+	pods = lo.Reject(pods, func(po *v1.Pod, _ int) bool {
+		if err := p.Validate(ctx, po); err != nil {
+			podErrors[po] = err
+			return true
+		}
+		return false
+	})
+	results := scheduler.Results{PodErrors: podErrors}
+	if len(pods) > 0 {
+		var err error
+		if results, err = p.solve(ctx, pods, p.cluster.Nodes().Active()); err != nil {
+			return scheduler.Results{}, err
+		}
+		if results.PodErrors == nil {
+			results.PodErrors = map[*v1.Pod]error{}
+		}
+		for po, err := range podErrors {
+			results.PodErrors[po] = err
+		}
+	}
+	return results, nil
+}
+
+// solve schedules the pods against the passed nodes and any capacity that the NodePools can launch
+func (p *Provisioner) solve(ctx context.Context, pods []*v1.Pod, nodes []*state.StateNode) (scheduler.Results, error) {
+	// The scheduler modifies the state nodes that it's given, so we keep a copy in case pod groups force us to
+	// schedule a second time
+	podGroups := scheduler.PodGroups(pods)
+	var podGroupNodes []*state.StateNode
+	if len(podGroups) > 0 {
+		podGroupNodes = lo.Map(nodes, func(n *state.StateNode, _ int) *state.StateNode { return n.DeepCopy() })
+	}
+	s, err := p.NewScheduler(ctx, pods, nodes)
+	if err != nil {
 		return scheduler.Results{}, fmt.Errorf("creating scheduler, %w", err)
 	}
 	results := s.Solve(ctx, pods).TruncateInstanceTypes(scheduler.MaxInstanceTypes)
-	logging.FromContext(ctx).With("pods", pretty.Slice(lo.Map(pods, func(p *v1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() }), 5)).
-		With("duration", time.Since(start)).
-		Infof("found provisionable pod(s)")
-	results.Record(ctx, p.recorder, p.cluster)
+	if len(podGroups) > 0 {
+		if results, err = p.schedulePodGroups(ctx, pods, podGroupNodes, podGroups, results); err != nil {
+			return scheduler.Results{}, err
+		}
+	}
 	return results, nil
 }
 
+// schedulePodGroups ensures that capacity is provisioned for pod groups all-or-nothing. If any pod group has fewer than
+// its min member count of pods scheduled (including members that are already running), all of the pods from those groups
+// are removed from the batch and the remaining pods are scheduled again.
+func (p *Provisioner) schedulePodGroups(ctx context.Context, pods []*v1.Pod, nodes []*state.StateNode,
+	podGroups map[types.NamespacedName]*scheduler.PodGroup, results scheduler.Results) (scheduler.Results, error) {
+	running, err := p.runningPodGroupMembers(ctx, podGroups)
+	if err != nil {
+		return scheduler.Results{}, err
+	}
+	incomplete := map[types.NamespacedName]error{}
+	for key, group := range podGroups {
+		if scheduled := group.Scheduled(results) + running[key]; scheduled < group.MinMember {
+			incomplete[key] = fmt.Errorf("pod group %q requires %d members to schedule together, only %d could schedule", key, group.MinMember, scheduled)
+		}
+	}
+	if len(incomplete) == 0 {
+		return results, nil
+	}
+	remaining := lo.Reject(pods, func(pod *v1.Pod, _ int) bool {
+		_, ok := incomplete[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Annotations[v1beta1.PodGroupAnnotationKey]}]
+		return ok
+	})
+	results = scheduler.Results{PodErrors: map[*v1.Pod]error{}}
+	if len(remaining) > 0 {
+		s, err := p.NewScheduler(ctx, remaining, nodes)
+		if err != nil {
+			return scheduler.Results{}, fmt.Errorf("creating scheduler, %w", err)
+		}
+		results = s.Solve(ctx, remaining).TruncateInstanceTypes(scheduler.MaxInstanceTypes)
+	}
+	for key, err := range incomplete {
+		for _, pod := range podGroups[key].Pods {
+			results.PodErrors[pod] = err
+		}
+	}
+	return results, nil
+}
+
+// runningPodGroupMembers returns the number of pods in each group that are already bound to a node and aren't part of
+// the pods that are being scheduled. The pods of each namespace are only listed once per scheduling pass.
+func (p *Provisioner) runningPodGroupMembers(ctx context.Context, podGroups map[types.NamespacedName]*scheduler.PodGroup) (map[types.NamespacedName]int, error) {
+	batch := sets.New[types.UID]()
+	for _, group := range podGroups {
+		batch.Insert(lo.Map(group.Pods, func(pod *v1.Pod, _ int) types.UID { return pod.UID })...)
+	}
+	running := map[types.NamespacedName]int{}
+	for _, namespace := range lo.Uniq(lo.MapToSlice(podGroups, func(key types.NamespacedName, _ *scheduler.PodGroup) string { return key.Namespace })) {
+		podList := &v1.PodList{}
+		if err := p.kubeClient.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("listing pods, %w", err)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			key := types.NamespacedName{Namespace: namespace, Name: pod.Annotations[v1beta1.PodGroupAnnotationKey]}
+			if _, ok := podGroups[key]; ok && pod.Spec.NodeName != "" && !podutil.IsTerminal(pod) && !batch.Has(pod.UID) {
+				running[key]++
+			}
+		}
+	}
+	return running, nil
+}
+
 func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts ...functional.Option[LaunchOptions]) (string, error) {
 	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("nodepool", n.NodePoolName))
 	options := functional.ResolveOptions(opts...)
@@ -340,9 +622,22 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 	if err := latest.Spec.Limits.ExceededBy(latest.Status.Resources); err != nil {
 		return "", err
 	}
+	// The resources of the NodeClaim are reserved from the nodepool's limits until it launches, so that concurrent
+	// launches can't overshoot them
+	reservation, err := p.cluster.ReserveLimits(latest, resources.MaxResources(lo.Map(n.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) v1.ResourceList {
+		return it.Capacity
+	})...), options.ReplacedProviderIDs...)
+	if err != nil {
+		return "", err
+	}
 	nodeClaim := n.ToNodeClaim(latest)
+	// The nodepool's shard labels are propagated so that the NodeClaim and its Node stay in the nodepool's shard
+	nodeClaim.Labels = lo.Assign(nodeClaim.Labels, nodepoolutil.ShardLabels(ctx, latest))
+	// Replacements that are created by disruption continue its trace when they're launched
+	tracing.Inject(ctx, nodeClaim)
 
 	if err := p.kubeClient.Create(ctx, nodeClaim); err != nil {
+		p.cluster.ReleaseLimitReservation(reservation)
 		return "", err
 	}
 	instanceTypeRequirement, _ := lo.Find(nodeClaim.Spec.Requirements, func(req v1beta1.NodeSelectorRequirementWithMinValues) bool {
@@ -360,14 +655,32 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 	// to then trigger cluster state updates. Triggering it manually ensures that Karpenter waits for the
 	// internal cache to sync before moving onto another disruption loop.
 	p.cluster.UpdateNodeClaim(nodeClaim)
+	p.cluster.BindLimitReservation(reservation, nodeClaim.Name)
 	if functional.ResolveOptions(opts...).RecordPodNomination {
-		for _, pod := range n.Pods {
+		for _, pod := range lo.Reject(n.Pods, func(pod *v1.Pod, _ int) bool { return podutil.IsOwnedByNodePool(pod) }) {
 			p.recorder.Publish(scheduler.NominatePodEvent(pod, nil, nodeClaim))
 		}
 	}
 	return nodeClaim.Name, nil
 }
 
+// NodeClaimForNodePool returns a NodeClaim that can launch as any of the nodepool's compatible instance types. It's
+// used to launch capacity for a nodepool that isn't for any pods in particular.
+func (p *Provisioner) NodeClaimForNodePool(ctx context.Context, nodePool *v1beta1.NodePool) (*scheduler.NodeClaim, error) {
+	instanceTypes, err := p.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("getting instance types, %w", err)
+	}
+	nodeClaimTemplate := scheduler.NewNodeClaimTemplate(nodePool)
+	nodeClaimTemplate.InstanceTypeOptions = lo.Filter(cloudprovider.InstanceTypes(instanceTypes).Compatible(nodeClaimTemplate.Requirements), func(it *cloudprovider.InstanceType, _ int) bool {
+		return it.Requirements.Intersects(nodeClaimTemplate.Requirements) == nil && resources.Fits(nodeClaimTemplate.Spec.Resources.Reserved, it.Allocatable())
+	})
+	if len(nodeClaimTemplate.InstanceTypeOptions) == 0 {
+		return nil, fmt.Errorf("no instance types are compatible with the nodepool")
+	}
+	return &scheduler.NodeClaim{NodeClaimTemplate: *nodeClaimTemplate}, nil
+}
+
 func instanceTypeList(names []string) string {
 	var itSb strings.Builder
 	for i, name := range names {
@@ -389,25 +702,49 @@ func (p *Provisioner) getDaemonSetPods(ctx context.Context) ([]*v1.Pod, error) {
 		return nil, fmt.Errorf("listing daemonsets, %w", err)
 	}
 
-	return lo.Map(daemonSetList.Items, func(d appsv1.DaemonSet, _ int) *v1.Pod {
-		pod := p.cluster.GetDaemonSetPod(&d)
-		if pod == nil {
-			pod = &v1.Pod{Spec: d.Spec.Template.Spec}
+	return lo.Map(daemonSetList.Items, func(d appsv1.DaemonSet, _ int) *v1.Pod { return DaemonSetPod(p.cluster, &d) }), nil
+}
+
+// DaemonSetPod returns a pod that stands in for the pods that the daemonset runs on each node, which is one of its pods
+// if cluster state has seen one, and a pod from its template otherwise
+func DaemonSetPod(cluster *state.Cluster, d *appsv1.DaemonSet) *v1.Pod {
+	pod := cluster.GetDaemonSetPod(d)
+	if pod == nil {
+		pod = &v1.Pod{Spec: *d.Spec.Template.Spec.DeepCopy()}
+		// The daemonset controller adds these tolerations to every pod that it creates, so daemonsets without pods
+		// yet would otherwise be left out of the overhead of nodes with the taints, e.g. in-flight nodes that aren't ready
+		// https://github.com/kubernetes/kubernetes/blob/c5cf0ac1889f55ab51749798bec684aed876709d/pkg/controller/daemon/util/daemonset_util.go#L50
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, daemonSetTolerations(pod)...)
+	}
+	// Replacing retrieved pod affinity with daemonset pod template required node affinity since this is overridden
+	// by the daemonset controller during pod creation
+	// https://github.com/kubernetes/kubernetes/blob/c5cf0ac1889f55ab51749798bec684aed876709d/pkg/controller/daemon/util/daemonset_util.go#L176
+	if d.Spec.Template.Spec.Affinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if pod.Spec.Affinity == nil {
+			pod.Spec.Affinity = &v1.Affinity{}
 		}
-		// Replacing retrieved pod affinity with daemonset pod template required node affinity since this is overridden
-		// by the daemonset controller during pod creation
-		// https://github.com/kubernetes/kubernetes/blob/c5cf0ac1889f55ab51749798bec684aed876709d/pkg/controller/daemon/util/daemonset_util.go#L176
-		if d.Spec.Template.Spec.Affinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
-			if pod.Spec.Affinity == nil {
-				pod.Spec.Affinity = &v1.Affinity{}
-			}
-			if pod.Spec.Affinity.NodeAffinity == nil {
-				pod.Spec.Affinity.NodeAffinity = &v1.NodeAffinity{}
-			}
-			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if pod.Spec.Affinity.NodeAffinity == nil {
+			pod.Spec.Affinity.NodeAffinity = &v1.NodeAffinity{}
 		}
-		return pod
-	}), nil
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+	return pod
+}
+
+// daemonSetTolerations returns the tolerations that the daemonset controller adds to the pods of daemonsets
+func daemonSetTolerations(pod *v1.Pod) []v1.Toleration {
+	tolerations := []v1.Toleration{
+		{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeDiskPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeMemoryPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodePIDPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+	if pod.Spec.HostNetwork {
+		tolerations = append(tolerations, v1.Toleration{Key: v1.TaintNodeNetworkUnavailable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule})
+	}
+	return tolerations
 }
 
 func (p *Provisioner) Validate(ctx context.Context, pod *v1.Pod) error {
@@ -443,6 +780,18 @@ func (p *Provisioner) injectVolumeTopologyRequirements(ctx context.Context, pods
 	return schedulablePods
 }
 
+func (p *Provisioner) injectResourceClaimRequirements(ctx context.Context, pods []*v1.Pod) []*v1.Pod {
+	var schedulablePods []*v1.Pod
+	for _, pod := range pods {
+		if err := p.resourceClaims.Inject(ctx, pod); err != nil {
+			logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pod)).Errorf("getting resource claim requirements, %s", err)
+		} else {
+			schedulablePods = append(schedulablePods, pod)
+		}
+	}
+	return schedulablePods
+}
+
 func validateNodeSelector(p *v1.Pod) (errs error) {
 	terms := lo.MapToSlice(p.Spec.NodeSelector, func(k string, v string) v1.NodeSelectorTerm {
 		return v1.NodeSelectorTerm{
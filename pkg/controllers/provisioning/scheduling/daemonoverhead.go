@@ -0,0 +1,175 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"sync"
+
+	"github.com/mitchellh/hashstructure/v2"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// DaemonOverheadCache caches the daemonset overhead of each nodepool, so that scheduling doesn't check every daemonset
+// pod against every nodepool's NodeClaimTemplate each time that it runs. Entries are only used for the version of the
+// nodepool and the set of daemonset pods that they were computed for, so they're recomputed once either changes.
+type DaemonOverheadCache struct {
+	mu      sync.RWMutex
+	entries map[string]*daemonOverheadCacheEntry
+}
+
+type daemonOverheadCacheEntry struct {
+	// uid and generation identify the version of the nodepool, and daemonSetHash the set of daemonset pods, that the
+	// entry was computed for
+	uid           types.UID
+	generation    int64
+	daemonSetHash uint64
+	overhead      v1.ResourceList
+	archOverhead  map[string]v1.ResourceList
+}
+
+func NewDaemonOverheadCache() *DaemonOverheadCache {
+	return &DaemonOverheadCache{entries: map[string]*daemonOverheadCacheEntry{}}
+}
+
+// Retain removes the entries of nodepools other than the given ones, so that deleted nodepools aren't cached forever
+func (c *DaemonOverheadCache) Retain(nodePoolNames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := lo.SliceToMap(nodePoolNames, func(name string) (string, struct{}) { return name, struct{}{} })
+	for name := range c.entries {
+		if _, ok := names[name]; !ok {
+			delete(c.entries, name)
+		}
+	}
+}
+
+// overheads returns the daemon overhead of each NodeClaimTemplate, and of each architecture offered by its instance
+// types, from the cache if it has them. A nil cache computes them every time.
+func (c *DaemonOverheadCache) overheads(nodePools []*v1beta1.NodePool, nodeClaimTemplates []*NodeClaimTemplate,
+	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*v1.Pod) (map[*NodeClaimTemplate]v1.ResourceList, map[*NodeClaimTemplate]map[string]v1.ResourceList) {
+	overhead := map[*NodeClaimTemplate]v1.ResourceList{}
+	archOverhead := map[*NodeClaimTemplate]map[string]v1.ResourceList{}
+	var hash uint64
+	if c != nil {
+		hash = daemonSetHash(daemonSetPods)
+	}
+	for i, nodeClaimTemplate := range nodeClaimTemplates {
+		archs := lo.Uniq(lo.FilterMap(instanceTypes[nodeClaimTemplate.NodePoolName], func(it *cloudprovider.InstanceType, _ int) (string, bool) {
+			return architecture(it)
+		}))
+		if c == nil {
+			overhead[nodeClaimTemplate] = daemonOverheadFor(nodeClaimTemplate.Requirements, nodeClaimTemplate, daemonSetPods)
+			archOverhead[nodeClaimTemplate] = archDaemonOverheadFor(nodeClaimTemplate, archs, daemonSetPods)
+			continue
+		}
+		entry := c.get(nodePools[i], nodeClaimTemplate, archs, daemonSetPods, hash)
+		overhead[nodeClaimTemplate], archOverhead[nodeClaimTemplate] = entry.overhead, entry.archOverhead
+	}
+	return overhead, archOverhead
+}
+
+// get returns the nodepool's entry, computing the overhead that isn't cached yet
+func (c *DaemonOverheadCache) get(nodePool *v1beta1.NodePool, nodeClaimTemplate *NodeClaimTemplate, archs []string, daemonSetPods []*v1.Pod, hash uint64) *daemonOverheadCacheEntry {
+	c.mu.RLock()
+	cached, ok := c.entries[nodePool.Name]
+	c.mu.RUnlock()
+	entry := cached
+	if !ok || entry.uid != nodePool.UID || entry.generation != nodePool.Generation || entry.daemonSetHash != hash {
+		entry = &daemonOverheadCacheEntry{
+			uid:           nodePool.UID,
+			generation:    nodePool.Generation,
+			daemonSetHash: hash,
+			overhead:      daemonOverheadFor(nodeClaimTemplate.Requirements, nodeClaimTemplate, daemonSetPods),
+			archOverhead:  map[string]v1.ResourceList{},
+		}
+	}
+	// The nodepool's architectures change with its instance types, so the overhead of the ones that weren't offered
+	// before is added to a copy of the entry
+	missing := lo.Reject(archs, func(arch string, _ int) bool { _, ok := entry.archOverhead[arch]; return ok })
+	if entry == cached && len(missing) == 0 {
+		return entry
+	}
+	if len(missing) > 0 {
+		entry = &daemonOverheadCacheEntry{
+			uid:           entry.uid,
+			generation:    entry.generation,
+			daemonSetHash: entry.daemonSetHash,
+			overhead:      entry.overhead,
+			archOverhead:  lo.Assign(entry.archOverhead, archDaemonOverheadFor(nodeClaimTemplate, missing, daemonSetPods)),
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[nodePool.Name] = entry
+	return entry
+}
+
+// archDaemonOverheadFor returns the daemon overhead of the NodeClaimTemplate for each of the architectures. Daemonsets
+// are often constrained to a single architecture, so an instance type only needs room for the daemons that will
+// actually run on its architecture.
+func archDaemonOverheadFor(nodeClaimTemplate *NodeClaimTemplate, archs []string, daemonSetPods []*v1.Pod) map[string]v1.ResourceList {
+	overhead := map[string]v1.ResourceList{}
+	for _, arch := range archs {
+		requirements := scheduling.NewRequirements(nodeClaimTemplate.Requirements.Values()...)
+		requirements.Add(scheduling.NewRequirement(v1.LabelArchStable, v1.NodeSelectorOpIn, arch))
+		overhead[arch] = daemonOverheadFor(requirements, nodeClaimTemplate, daemonSetPods)
+	}
+	return overhead
+}
+
+// daemonOverheadFor returns the requests of the daemonset pods that tolerate the NodeClaimTemplate's taints and are
+// compatible with the requirements. Startup taints are removed once the node initializes, so daemonset pods that don't
+// tolerate them still run on the node.
+func daemonOverheadFor(requirements scheduling.Requirements, nodeClaimTemplate *NodeClaimTemplate, daemonSetPods []*v1.Pod) v1.ResourceList {
+	var daemons []*v1.Pod
+	for _, p := range daemonSetPods {
+		if err := scheduling.HardTaints(nodeClaimTemplate.Spec.Taints).Tolerates(p); err != nil {
+			continue
+		}
+		if err := requirements.Compatible(scheduling.NewPodRequirements(p), scheduling.AllowUndefinedWellKnownLabels); err != nil {
+			continue
+		}
+		daemons = append(daemons, p)
+	}
+	return resources.RequestsForPods(daemons...)
+}
+
+// daemonSetHash hashes the parts of the daemonset pods that their overhead depends on
+func daemonSetHash(daemonSetPods []*v1.Pod) uint64 {
+	return lo.Must(hashstructure.Hash(lo.Map(daemonSetPods, func(p *v1.Pod, _ int) interface{} {
+		return struct {
+			// Quantities don't have exported fields to hash, so they're hashed as strings
+			Requests     map[v1.ResourceName]string
+			NodeSelector map[string]string
+			Affinity     *v1.Affinity
+			Tolerations  []v1.Toleration
+		}{
+			Requests:     lo.MapValues(resources.RequestsForPods(p), func(q resource.Quantity, _ v1.ResourceName) string { return q.String() }),
+			NodeSelector: p.Spec.NodeSelector,
+			Affinity:     p.Spec.Affinity,
+			Tolerations:  p.Spec.Tolerations,
+		}
+	}), hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true}))
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package scheduling
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -50,11 +51,16 @@ func NominatePodEvent(pod *v1.Pod, node *v1.Node, nodeClaim *v1beta1.NodeClaim)
 }
 
 func PodFailedToScheduleEvent(pod *v1.Pod, err error) events.Event {
+	message := err.Error()
+	var schedulingErr *SchedulingError
+	if errors.As(err, &schedulingErr) {
+		message = schedulingErr.Explanation()
+	}
 	return events.Event{
 		InvolvedObject: pod,
 		Type:           v1.EventTypeWarning,
 		Reason:         "FailedScheduling",
-		Message:        fmt.Sprintf("Failed to schedule pod, %s", err),
+		Message:        fmt.Sprintf("Failed to schedule pod, %s", message),
 		DedupeValues:   []string{string(pod.UID)},
 		DedupeTimeout:  5 * time.Minute,
 	}
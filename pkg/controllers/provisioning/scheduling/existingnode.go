@@ -17,11 +17,9 @@ limitations under the License.
 package scheduling
 
 import (
-	"context"
 	"fmt"
 
 	v1 "k8s.io/api/core/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -61,24 +59,12 @@ func NewExistingNode(n *state.StateNode, topology *Topology, daemonResources v1.
 	return node
 }
 
-func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v1.Pod) error {
-	// Check Taints
-	if err := scheduling.Taints(n.Taints()).Tolerates(pod); err != nil {
+func (n *ExistingNode) Add(pod *v1.Pod, volumes scheduling.Volumes) error {
+	// Check the node's taints, labels, host ports and volume limits. The host ports and volumes of the pods that have
+	// already been added to the node are tracked by its usage, but their requests and requirements are checked below.
+	if err := n.IsCompatible(pod, volumes); err != nil {
 		return err
 	}
-	// determine the volumes that will be mounted if the pod schedules
-	volumes, err := scheduling.GetVolumes(ctx, kubeClient, pod)
-	if err != nil {
-		return err
-	}
-	// determine the host ports that will be used if the pod schedules
-	hostPorts := scheduling.GetHostPorts(pod)
-	if err = n.VolumeUsage().ExceedsLimits(volumes); err != nil {
-		return fmt.Errorf("checking volume usage, %w", err)
-	}
-	if err = n.HostPortUsage().Conflicts(pod, hostPorts); err != nil {
-		return fmt.Errorf("checking host port usage, %w", err)
-	}
 
 	// check resource requests first since that's a pretty likely reason the pod won't schedule on an in-flight
 	// node, which at this point can't be increased in size
@@ -91,7 +77,7 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	nodeRequirements := scheduling.NewRequirements(n.requirements.Values()...)
 	podRequirements := scheduling.NewPodRequirements(pod)
 	// Check NodeClaim Affinity Requirements
-	if err = nodeRequirements.Compatible(podRequirements); err != nil {
+	if err := nodeRequirements.Compatible(podRequirements); err != nil {
 		return err
 	}
 	nodeRequirements.Add(podRequirements.Values()...)
@@ -117,8 +103,8 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	n.Pods = append(n.Pods, pod)
 	n.requests = requests
 	n.requirements = nodeRequirements
-	n.topology.Record(pod, nodeRequirements)
-	n.HostPortUsage().Add(pod, hostPorts)
+	n.topology.Record(pod, n.Taints(), nodeRequirements)
+	n.HostPortUsage().Add(pod, scheduling.GetHostPorts(pod))
 	n.VolumeUsage().Add(pod, volumes)
 	return nil
 }
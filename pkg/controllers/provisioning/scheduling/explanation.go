@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// FailureReason is the category of constraint that prevented a pod from scheduling to a NodePool
+type FailureReason string
+
+const (
+	FailureReasonTaints        FailureReason = "Taints"
+	FailureReasonHostPorts     FailureReason = "HostPorts"
+	FailureReasonVolumes       FailureReason = "Volumes"
+	FailureReasonRequirements  FailureReason = "Requirements"
+	FailureReasonTopology      FailureReason = "Topology"
+	FailureReasonInstanceTypes FailureReason = "InstanceTypes"
+	FailureReasonLimits        FailureReason = "Limits"
+	FailureReasonCapacity      FailureReason = "Capacity"
+	FailureReasonPreemption    FailureReason = "Preemption"
+	FailureReasonUnknown       FailureReason = "Unknown"
+)
+
+// NodePoolFailure explains why a pod couldn't schedule to a new NodeClaim from a single NodePool
+type NodePoolFailure struct {
+	NodePool string
+	Reason   FailureReason
+	Message  string
+}
+
+// SchedulingError is returned for pods that couldn't schedule to any existing node or NodePool. It keeps a
+// structured explanation for each NodePool that was considered alongside the combined error.
+type SchedulingError struct {
+	NodePools []NodePoolFailure
+	err       error
+}
+
+func (e *SchedulingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *SchedulingError) Unwrap() error {
+	return e.err
+}
+
+// Reason returns the failure reason shared by every NodePool, or a combined reason if the NodePools failed for
+// different reasons
+func (e *SchedulingError) Reason() string {
+	reasons := lo.Uniq(lo.Map(e.NodePools, func(f NodePoolFailure, _ int) FailureReason { return f.Reason }))
+	if len(reasons) == 1 {
+		return fmt.Sprintf("Incompatible%s", reasons[0])
+	}
+	return "IncompatibleNodePools"
+}
+
+// Explanation returns a human-readable summary of why each NodePool couldn't fit the pod
+func (e *SchedulingError) Explanation() string {
+	return strings.Join(lo.Map(e.NodePools, func(f NodePoolFailure, _ int) string {
+		return fmt.Sprintf("nodepool %q (%s): %s", f.NodePool, f.Reason, f.Message)
+	}), "; ")
+}
+
+// incompatibleError tags an error from adding a pod to a NodeClaim with the constraint that wasn't met
+type incompatibleError struct {
+	reason FailureReason
+	err    error
+}
+
+func (e incompatibleError) Error() string {
+	return e.err.Error()
+}
+
+func (e incompatibleError) Unwrap() error {
+	return e.err
+}
+
+func failureReason(err error) FailureReason {
+	var ie incompatibleError
+	if errors.As(err, &ie) {
+		return ie.reason
+	}
+	return FailureReasonUnknown
+}
@@ -26,7 +26,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 
-	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
@@ -40,12 +39,17 @@ type NodeClaim struct {
 	Pods            []*v1.Pod
 	topology        *Topology
 	hostPortUsage   *scheduling.HostPortUsage
+	volumes         scheduling.Volumes
 	daemonResources v1.ResourceList
+	// archDaemonResources is the daemon overhead for each architecture, which may be less than daemonResources when
+	// daemonsets are constrained to an architecture
+	archDaemonResources map[string]v1.ResourceList
 }
 
 var nodeID int64
 
-func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, instanceTypes []*cloudprovider.InstanceType) *NodeClaim {
+func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, archDaemonResources map[string]v1.ResourceList,
+	instanceTypes []*cloudprovider.InstanceType) *NodeClaim {
 	// Copy the template, and add hostname
 	hostname := fmt.Sprintf("hostname-placeholder-%04d", atomic.AddInt64(&nodeID, 1))
 	topology.Register(v1.LabelHostname, hostname)
@@ -57,30 +61,46 @@ func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daem
 	template.Spec.Resources.Requests = daemonResources
 
 	return &NodeClaim{
-		NodeClaimTemplate: template,
-		hostPortUsage:     scheduling.NewHostPortUsage(),
-		topology:          topology,
-		daemonResources:   daemonResources,
+		NodeClaimTemplate:   template,
+		hostPortUsage:       scheduling.NewHostPortUsage(),
+		volumes:             scheduling.Volumes{},
+		topology:            topology,
+		daemonResources:     daemonResources,
+		archDaemonResources: archDaemonResources,
 	}
 }
 
-func (n *NodeClaim) Add(pod *v1.Pod) error {
+// Add adds the pod to the NodeClaim if it's compatible. volumes are the pod's volumes, which the NodeClaim's instance
+// type must be able to attach alongside the volumes of the pods that were already added.
+func (n *NodeClaim) Add(pod *v1.Pod, volumes scheduling.Volumes) error {
 	// Check Taints
 	if err := scheduling.Taints(n.Spec.Taints).Tolerates(pod); err != nil {
-		return err
+		return incompatibleError{reason: FailureReasonTaints, err: err}
 	}
 
 	// exposed host ports on the node
-	hostPorts := scheduling.GetHostPorts(pod)
-	if err := n.hostPortUsage.Conflicts(pod, hostPorts); err != nil {
-		return fmt.Errorf("checking host port usage, %w", err)
+	if err := n.hostPortUsage.Conflicts(pod); err != nil {
+		return incompatibleError{reason: FailureReasonHostPorts, err: fmt.Errorf("checking host port usage, %w", err)}
+	}
+
+	// Check volume limits
+	instanceTypes := n.InstanceTypeOptions
+	if len(volumes) > 0 {
+		volumes = n.volumes.Union(volumes)
+		var err error
+		instanceTypes, err = filterByVolumeLimits(instanceTypes, volumes)
+		if err != nil {
+			return incompatibleError{reason: FailureReasonVolumes, err: fmt.Errorf("checking volume limits, %w", err)}
+		}
+	} else {
+		volumes = n.volumes
 	}
 	nodeClaimRequirements := scheduling.NewRequirements(n.Requirements.Values()...)
 	podRequirements := scheduling.NewPodRequirements(pod)
 
 	// Check NodeClaim Affinity Requirements
 	if err := nodeClaimRequirements.Compatible(podRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return fmt.Errorf("incompatible requirements, %w", err)
+		return incompatibleError{reason: FailureReasonRequirements, err: fmt.Errorf("incompatible requirements, %w", err)}
 	}
 	nodeClaimRequirements.Add(podRequirements.Values()...)
 
@@ -93,22 +113,25 @@ func (n *NodeClaim) Add(pod *v1.Pod) error {
 	// Check Topology Requirements
 	topologyRequirements, err := n.topology.AddRequirements(strictPodRequirements, nodeClaimRequirements, pod, scheduling.AllowUndefinedWellKnownLabels)
 	if err != nil {
-		return err
+		return incompatibleError{reason: FailureReasonTopology, err: err}
 	}
 	if err = nodeClaimRequirements.Compatible(topologyRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return err
+		return incompatibleError{reason: FailureReasonTopology, err: err}
 	}
 	nodeClaimRequirements.Add(topologyRequirements.Values()...)
 
 	// Check instance type combinations
 	requests := resources.Merge(n.Spec.Resources.Requests, resources.RequestsForPods(pod))
 
-	filtered := filterInstanceTypesByRequirements(n.InstanceTypeOptions, nodeClaimRequirements, requests)
+	filtered := filterInstanceTypesByRequirements(instanceTypes, nodeClaimRequirements, requests, n.requestsFor(requests))
 
 	if len(filtered.remaining) == 0 {
-		// log the total resources being requested (daemonset + the pod)
-		cumulativeResources := resources.Merge(n.daemonResources, resources.RequestsForPods(pod))
-		return fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)", resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason())
+		// log the total resources being requested (daemonset + the pod + the nodepool's reserved resources)
+		cumulativeResources := resources.Merge(n.daemonResources, resources.RequestsForPods(pod), n.Spec.Resources.Reserved)
+		// Instance types that meet the requirements and fit the pod, but have no available offering, would launch once
+		// the cloud provider has capacity for them
+		reason := lo.Ternary(filtered.requirementsAndFits, FailureReasonCapacity, FailureReasonInstanceTypes)
+		return incompatibleError{reason: reason, err: fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)", resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason())}
 	}
 
 	// Update node
@@ -116,11 +139,47 @@ func (n *NodeClaim) Add(pod *v1.Pod) error {
 	n.InstanceTypeOptions = filtered.remaining
 	n.Spec.Resources.Requests = requests
 	n.Requirements = nodeClaimRequirements
-	n.topology.Record(pod, nodeClaimRequirements, scheduling.AllowUndefinedWellKnownLabels)
-	n.hostPortUsage.Add(pod, hostPorts)
+	n.topology.Record(pod, n.Spec.Taints, nodeClaimRequirements, scheduling.AllowUndefinedWellKnownLabels)
+	n.hostPortUsage.Add(pod, scheduling.GetHostPorts(pod))
+	n.volumes = volumes
 	return nil
 }
 
+// filterByVolumeLimits returns the instance types that can attach all of the volumes, or an error describing the
+// limits that were exceeded if none of them can
+func filterByVolumeLimits(instanceTypes []*cloudprovider.InstanceType, volumes scheduling.Volumes) ([]*cloudprovider.InstanceType, error) {
+	var err error
+	remaining := lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+		if exceeded := volumes.ExceedsLimits(it.VolumeLimits); exceeded != nil {
+			err = exceeded
+			return false
+		}
+		return true
+	})
+	if len(remaining) == 0 && err != nil {
+		return nil, fmt.Errorf("no instance type can attach the volumes, %w", err)
+	}
+	return remaining, nil
+}
+
+// requestsFor returns a function that computes the requests an instance type has to fit. Only the daemons that will
+// run on the instance type's architecture are counted, and the NodePool's reserved resources are held back from the
+// instance type's allocatable.
+func (n *NodeClaim) requestsFor(requests v1.ResourceList) func(*cloudprovider.InstanceType) v1.ResourceList {
+	podRequests := resources.Subtract(requests, n.daemonResources)
+	return func(it *cloudprovider.InstanceType) v1.ResourceList {
+		arch, ok := architecture(it)
+		if !ok {
+			return resources.Merge(requests, n.Spec.Resources.Reserved)
+		}
+		daemonResources, ok := n.archDaemonResources[arch]
+		if !ok {
+			return resources.Merge(requests, n.Spec.Resources.Reserved)
+		}
+		return resources.Merge(podRequests, daemonResources, n.Spec.Resources.Reserved)
+	}
+}
+
 // FinalizeScheduling is called once all scheduling has completed and allows the node to perform any cleanup
 // necessary before its requirements are used for instance launching
 func (n *NodeClaim) FinalizeScheduling() {
@@ -230,7 +289,8 @@ func (r filterResults) FailureReason() string {
 }
 
 //nolint:gocyclo
-func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements, requests v1.ResourceList) filterResults {
+func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements, requests v1.ResourceList,
+	requestsFor func(*cloudprovider.InstanceType) v1.ResourceList) filterResults {
 	results := filterResults{
 		requests:        requests,
 		requirementsMet: false,
@@ -246,7 +306,7 @@ func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceTy
 		// the tradeoff to not short circuiting on the filtering is that we can report much better error messages
 		// about why scheduling failed
 		itCompat := compatible(it, requirements)
-		itFits := fits(it, requests)
+		itFits := fits(it, requestsFor(it))
 		itHasOffering := hasOffering(it, requirements)
 
 		// track if any single instance type met a single criteria
@@ -284,8 +344,7 @@ func fits(instanceType *cloudprovider.InstanceType, requests v1.ResourceList) bo
 
 func hasOffering(instanceType *cloudprovider.InstanceType, requirements scheduling.Requirements) bool {
 	for _, offering := range instanceType.Offerings.Available() {
-		if (!requirements.Has(v1.LabelTopologyZone) || requirements.Get(v1.LabelTopologyZone).Has(offering.Zone)) &&
-			(!requirements.Has(v1beta1.CapacityTypeLabelKey) || requirements.Get(v1beta1.CapacityTypeLabelKey).Has(offering.CapacityType)) {
+		if offering.Compatible(requirements) {
 			return true
 		}
 	}
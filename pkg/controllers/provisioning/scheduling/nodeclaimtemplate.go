@@ -53,6 +53,10 @@ func NewNodeClaimTemplate(nodePool *v1beta1.NodePool) *NodeClaimTemplate {
 	nct.Labels = lo.Assign(nct.Labels, map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name})
 	nct.Requirements.Add(scheduling.NewNodeSelectorRequirementsWithMinValues(nct.Spec.Requirements...).Values()...)
 	nct.Requirements.Add(scheduling.NewLabelRequirements(nct.Labels).Values()...)
+	// Nodes are labeled with the domains of the nodepool's topology keys, so pods can select them
+	for _, key := range nodePool.Spec.TopologyKeys {
+		nct.Requirements.Add(scheduling.NewRequirement(key, v1.NodeSelectorOpExists))
+	}
 	return nct
 }
 
@@ -0,0 +1,78 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// PodGroup is a set of pods in the same namespace that share the karpenter.sh/pod-group annotation. Capacity is only
+// provisioned for the pods in a group if at least MinMember pods of the group are able to schedule.
+type PodGroup struct {
+	types.NamespacedName
+	// MinMember is the minimum number of pods in the group that must be able to schedule together. This is read from
+	// the karpenter.sh/pod-group-min-member annotation and defaults to the number of pods in the group.
+	MinMember int
+	Pods      []*v1.Pod
+}
+
+// PodGroups returns the pod groups, keyed by namespaced group name, for all pods that have the karpenter.sh/pod-group
+// annotation. Pods without the annotation aren't part of any group.
+func PodGroups(pods []*v1.Pod) map[types.NamespacedName]*PodGroup {
+	groups := map[types.NamespacedName]*PodGroup{}
+	for _, p := range pods {
+		name, ok := p.Annotations[v1beta1.PodGroupAnnotationKey]
+		if !ok || name == "" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: p.Namespace, Name: name}
+		group, ok := groups[key]
+		if !ok {
+			group = &PodGroup{NamespacedName: key}
+			groups[key] = group
+		}
+		group.Pods = append(group.Pods, p)
+		// The first valid min-member value in the group wins
+		if group.MinMember == 0 {
+			if minMember, err := strconv.Atoi(p.Annotations[v1beta1.PodGroupMinMemberAnnotationKey]); err == nil && minMember > 0 {
+				group.MinMember = minMember
+			}
+		}
+	}
+	for _, group := range groups {
+		if group.MinMember == 0 {
+			group.MinMember = len(group.Pods)
+		}
+	}
+	return groups
+}
+
+// Scheduled returns the number of pods in the group that were able to schedule in the results
+func (g *PodGroup) Scheduled(results Results) int {
+	scheduled := 0
+	for _, p := range g.Pods {
+		if _, ok := results.PodErrors[p]; !ok {
+			scheduled++
+		}
+	}
+	return scheduled
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// canPreempt returns true if kube-scheduler could schedule the pending pod to an existing node by preempting
+// lower priority pods. This is a coarse simulation of kube-scheduler's preemption: it checks taints, node
+// requirements and whether the pod's requests fit once every lower priority pod on the node is evicted. It
+// doesn't consider PodDisruptionBudgets, topology spread or host ports.
+func (s *Scheduler) canPreempt(ctx context.Context, p *v1.Pod) bool {
+	// Pods being rescheduled for disruption are already bound, and pods that can't preempt will never be
+	// scheduled through preemption
+	if pod.IsScheduled(p) || lo.FromPtr(p.Spec.PreemptionPolicy) == v1.PreemptNever {
+		return false
+	}
+	priority := lo.FromPtr(p.Spec.Priority)
+	podRequirements := scheduling.NewPodRequirements(p)
+	requests := resources.RequestsForPods(p)
+	for _, node := range s.existingNodes {
+		// kube-scheduler can only preempt pods that are running on registered nodes
+		if !node.Initialized() || node.MarkedForDeletion() {
+			continue
+		}
+		if err := scheduling.HardTaints(node.Taints()).Tolerates(p); err != nil {
+			continue
+		}
+		if err := node.requirements.Compatible(podRequirements); err != nil {
+			continue
+		}
+		pods, err := s.podsOn(ctx, node)
+		if err != nil {
+			logging.FromContext(ctx).With("node", node.Name()).Errorf("listing pods for preemption, %s", err)
+			continue
+		}
+		victims := lo.Filter(pods, func(v *v1.Pod, _ int) bool {
+			return lo.FromPtr(v.Spec.Priority) < priority && !pod.IsTerminal(v) && !pod.IsTerminating(v)
+		})
+		if len(victims) == 0 {
+			continue
+		}
+		if resources.Fits(resources.Merge(node.requests, requests), resources.Merge(node.Available(), resources.RequestsForPods(victims...))) {
+			return true
+		}
+	}
+	return false
+}
+
+// podsOn returns the pods that are bound to the existing node. The pods are listed the first time that they're needed
+// in a Solve, since the pods that the scheduler adds to the node aren't bound to it during the Solve.
+func (s *Scheduler) podsOn(ctx context.Context, node *ExistingNode) ([]*v1.Pod, error) {
+	if pods, ok := s.existingNodePods[node.ProviderID()]; ok {
+		return pods, nil
+	}
+	pods, err := node.StateNode.Pods(ctx, s.kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	s.existingNodePods[node.ProviderID()] = pods
+	return pods, nil
+}
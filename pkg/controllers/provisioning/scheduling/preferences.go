@@ -122,8 +122,9 @@ func (p *Preferences) removePreferredPodAntiAffinityTerm(pod *v1.Pod) *string {
 	terms := pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
 	// Remove the all the terms
 	if len(terms) > 0 {
-		// Sort descending by weight to remove heaviest preferences to try lighter ones
-		sort.SliceStable(terms, func(i, j int) bool { return terms[i].Weight > terms[j].Weight })
+		// Anti-affinity terms are all enforced at once (terms are an AND semantic), so sort ascending by weight to remove
+		// the lightest preferences first and keep satisfying the heaviest ones for as long as possible
+		sort.SliceStable(terms, func(i, j int) bool { return terms[i].Weight < terms[j].Weight })
 		pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = terms[1:]
 		return ptr.String(fmt.Sprintf("removing: spec.affinity.podAntiAffinity.preferredDuringSchedulingIgnoredDuringExecution[0]=%s", pretty.Concise(terms[0])))
 	}
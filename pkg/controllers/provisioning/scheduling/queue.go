@@ -19,9 +19,11 @@ package scheduling
 import (
 	"sort"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
@@ -33,9 +35,11 @@ type Queue struct {
 	lastLen map[types.UID]int
 }
 
-// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes.
-func NewQueue(pods ...*v1.Pod) *Queue {
-	sort.Slice(pods, byCPUAndMemoryDescending(pods))
+// NewQueue constructs a new queue given the input pods, sorting them by priority so that higher priority pods get
+// capacity first, and then by their size in the binpacking strategy so that the largest pods are packed into nodes
+// first. The capacity is only used by the dominant resource strategy, which measures the pods' requests against it.
+func NewQueue(strategy string, capacity v1.ResourceList, pods ...*v1.Pod) *Queue {
+	sort.Slice(pods, byPriorityAndSizeDescending(pods, sizeCmp(strategy, capacity)))
 	return &Queue{
 		pods:    pods,
 		lastLen: map[types.UID]int{},
@@ -73,27 +77,18 @@ func (q *Queue) List() []*v1.Pod {
 	return q.pods
 }
 
-func byCPUAndMemoryDescending(pods []*v1.Pod) func(i int, j int) bool {
+func byPriorityAndSizeDescending(pods []*v1.Pod, cmp func(lhs, rhs v1.ResourceList) int) func(i int, j int) bool {
 	return func(i, j int) bool {
 		lhsPod := pods[i]
 		rhsPod := pods[j]
 
-		lhs := resources.RequestsForPods(lhsPod)
-		rhs := resources.RequestsForPods(rhsPod)
-
-		cpuCmp := resources.Cmp(lhs[v1.ResourceCPU], rhs[v1.ResourceCPU])
-		if cpuCmp < 0 {
-			// LHS has less CPU, so it should be sorted after
-			return false
-		} else if cpuCmp > 0 {
-			return true
+		if lhsPriority, rhsPriority := lo.FromPtr(lhsPod.Spec.Priority), lo.FromPtr(rhsPod.Spec.Priority); lhsPriority != rhsPriority {
+			return lhsPriority > rhsPriority
 		}
-		memCmp := resources.Cmp(lhs[v1.ResourceMemory], rhs[v1.ResourceMemory])
 
-		if memCmp < 0 {
-			return false
-		} else if memCmp > 0 {
-			return true
+		if sizeCmp := cmp(resources.RequestsForPods(lhsPod), resources.RequestsForPods(rhsPod)); sizeCmp != 0 {
+			// LHS is larger, so it should be sorted first
+			return sizeCmp > 0
 		}
 
 		// If all else is equal, give a consistent ordering. This reduces the number of NominatePod events as we
@@ -110,3 +105,48 @@ func byCPUAndMemoryDescending(pods []*v1.Pod) func(i int, j int) bool {
 		return lhsPod.UID < rhsPod.UID
 	}
 }
+
+// sizeCmp returns a function that compares the requests of pods by their size in the binpacking strategy
+func sizeCmp(strategy string, capacity v1.ResourceList) func(lhs, rhs v1.ResourceList) int {
+	switch strategy {
+	case options.BinPackingStrategyMemory:
+		return func(lhs, rhs v1.ResourceList) int {
+			return cmpRequests(lhs, rhs, v1.ResourceMemory, v1.ResourceCPU)
+		}
+	case options.BinPackingStrategyDominantResource:
+		return func(lhs, rhs v1.ResourceList) int {
+			if lhsShare, rhsShare := dominantShare(lhs, capacity), dominantShare(rhs, capacity); lhsShare != rhsShare {
+				return lo.Ternary(lhsShare > rhsShare, 1, -1)
+			}
+			return cmpRequests(lhs, rhs, v1.ResourceCPU, v1.ResourceMemory)
+		}
+	default:
+		return func(lhs, rhs v1.ResourceList) int {
+			return cmpRequests(lhs, rhs, v1.ResourceCPU, v1.ResourceMemory)
+		}
+	}
+}
+
+// cmpRequests compares the requests of the resources in order, until one of them differs
+func cmpRequests(lhs, rhs v1.ResourceList, resourceNames ...v1.ResourceName) int {
+	for _, resourceName := range resourceNames {
+		if cmp := resources.Cmp(lhs[resourceName], rhs[resourceName]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// dominantShare returns the largest share of the capacity's CPU or memory that the requests take up
+func dominantShare(requests, capacity v1.ResourceList) float64 {
+	share := 0.0
+	for _, resourceName := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		total, ok := capacity[resourceName]
+		if !ok || total.IsZero() {
+			continue
+		}
+		quantity := requests[resourceName]
+		share = max(share, quantity.AsApproximateFloat64()/total.AsApproximateFloat64())
+	}
+	return share
+}
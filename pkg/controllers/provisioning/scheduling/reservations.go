@@ -0,0 +1,69 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"github.com/samber/lo"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+)
+
+// reservedInstanceTypes returns copies of the instance types whose capacity reservations still have room. The reserved
+// offerings of the copies that don't have room are marked unavailable, so that NodeClaims that are restricted to
+// reserved capacity can't be launched into them.
+func (s *Scheduler) reservedInstanceTypes(instanceTypes []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+	var reserved []*cloudprovider.InstanceType
+	for _, instanceType := range instanceTypes {
+		if !hasReservedOfferings(instanceType) {
+			continue
+		}
+		offerings := cloudprovider.Offerings(lo.Map(instanceType.Offerings, func(o cloudprovider.Offering, _ int) cloudprovider.Offering {
+			if o.CapacityType == v1beta1.CapacityTypeReserved {
+				o.Available = o.Available && s.remainingReservations[state.Reservation{InstanceType: instanceType.Name, Zone: o.Zone}] > 0
+			}
+			return o
+		}))
+		if !lo.ContainsBy(offerings.Available(), func(o cloudprovider.Offering) bool { return o.CapacityType == v1beta1.CapacityTypeReserved }) {
+			continue
+		}
+		reserved = append(reserved, &cloudprovider.InstanceType{
+			Name:         instanceType.Name,
+			Requirements: instanceType.Requirements,
+			Offerings:    offerings,
+			Capacity:     instanceType.Capacity,
+			Overhead:     instanceType.Overhead,
+			VolumeLimits: instanceType.VolumeLimits,
+		})
+	}
+	return reserved
+}
+
+// reserve takes up a slot in the capacity reservation that the NodeClaim is expected to launch into, so that the
+// NodeClaims of the same scheduling round don't oversubscribe it
+func (s *Scheduler) reserve(nodeClaim *NodeClaim) {
+	if reservation, ok := state.ReservationFor(nodeClaim.InstanceTypeOptions, nodeClaim.Requirements); ok {
+		s.remainingReservations[reservation]--
+	}
+}
+
+func hasReservedOfferings(instanceType *cloudprovider.InstanceType) bool {
+	return lo.ContainsBy(instanceType.Offerings.Available(), func(o cloudprovider.Offering) bool {
+		return o.CapacityType == v1beta1.CapacityTypeReserved
+	})
+}
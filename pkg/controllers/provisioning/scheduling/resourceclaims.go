@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	resourcev1alpha2 "k8s.io/api/resource/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+func NewResourceClaims(kubeClient client.Client) *ResourceClaims {
+	return &ResourceClaims{kubeClient: kubeClient}
+}
+
+// ResourceClaims translates the Dynamic Resource Allocation claims of pods into requirements and requests that
+// instance types can be matched against. Each claim that still needs to be allocated requests one device of its
+// ResourceClass, which instance types advertise as capacity named after the class's driver, or the resource in the
+// class's karpenter.sh/device-resource annotation. The class's suitable nodes are added to the pod's requirements.
+type ResourceClaims struct {
+	kubeClient client.Client
+}
+
+func (r *ResourceClaims) Inject(ctx context.Context, pod *v1.Pod) error {
+	var claimTerms [][][]v1.NodeSelectorRequirement
+	devices := v1.ResourceList{}
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		terms, device, err := r.getRequirements(ctx, pod, podClaim)
+		if err != nil {
+			return err
+		}
+		if len(terms) > 0 {
+			claimTerms = append(claimTerms, terms)
+		}
+		if device != "" {
+			resources.MergeInto(devices, v1.ResourceList{device: resource.MustParse("1")})
+		}
+	}
+	claimTerms = lo.UniqBy(claimTerms, func(terms [][]v1.NodeSelectorRequirement) string { return fmt.Sprint(terms) })
+	if len(claimTerms) == 0 && len(devices) == 0 {
+		return nil
+	}
+	if len(claimTerms) > 0 {
+		requireTerms(pod, claimTerms)
+	}
+	// The devices are added to the pod's overhead rather than its containers, since claims are shared by the containers
+	// that reference them
+	if len(devices) > 0 {
+		if pod.Spec.Overhead == nil {
+			pod.Spec.Overhead = v1.ResourceList{}
+		}
+		resources.MergeInto(pod.Spec.Overhead, devices)
+	}
+
+	logging.FromContext(ctx).
+		With("pod", client.ObjectKeyFromObject(pod)).
+		Debugf("adding requirements derived from pod resource claims, %s, requests %s", claimTerms, resources.String(devices))
+	return nil
+}
+
+// getRequirements returns the ORed terms of requirements that a node must meet for the claim to be allocated on it,
+// along with the resource of the device that the claim requests, if the claim still needs to be allocated
+func (r *ResourceClaims) getRequirements(ctx context.Context, pod *v1.Pod, podClaim v1.PodResourceClaim) ([][]v1.NodeSelectorRequirement, v1.ResourceName, error) {
+	var spec resourcev1alpha2.ResourceClaimSpec
+	switch {
+	case podClaim.Source.ResourceClaimName != nil:
+		claim := &resourcev1alpha2.ResourceClaim{}
+		if err := r.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: *podClaim.Source.ResourceClaimName}, claim); err != nil {
+			return nil, "", fmt.Errorf("getting resource claim %q, %w", *podClaim.Source.ResourceClaimName, err)
+		}
+		// An allocated claim's devices already exist, so it only constrains the nodes that the pod can run on
+		if claim.Status.Allocation != nil {
+			return nodeSelectorTerms(claim.Status.Allocation.AvailableOnNodes), "", nil
+		}
+		spec = claim.Spec
+	case podClaim.Source.ResourceClaimTemplateName != nil:
+		template := &resourcev1alpha2.ResourceClaimTemplate{}
+		if err := r.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: *podClaim.Source.ResourceClaimTemplateName}, template); err != nil {
+			return nil, "", fmt.Errorf("getting resource claim template %q, %w", *podClaim.Source.ResourceClaimTemplateName, err)
+		}
+		spec = template.Spec.Spec
+	default:
+		return nil, "", nil
+	}
+	class := &resourcev1alpha2.ResourceClass{}
+	if err := r.kubeClient.Get(ctx, types.NamespacedName{Name: spec.ResourceClassName}, class); err != nil {
+		return nil, "", fmt.Errorf("getting resource class %q, %w", spec.ResourceClassName, err)
+	}
+	device := v1.ResourceName(class.DriverName)
+	if name, ok := class.Annotations[v1beta1.DeviceResourceAnnotationKey]; ok {
+		device = v1.ResourceName(name)
+	}
+	return nodeSelectorTerms(class.SuitableNodes), device, nil
+}
+
+// nodeSelectorTerms returns the ORed terms of requirements of a node selector. A term without any expressions doesn't
+// constrain the labels of the node, so the selector isn't constrained either.
+func nodeSelectorTerms(selector *v1.NodeSelector) [][]v1.NodeSelectorRequirement {
+	if selector == nil || lo.SomeBy(selector.NodeSelectorTerms, func(term v1.NodeSelectorTerm) bool { return len(term.MatchExpressions) == 0 }) {
+		return nil
+	}
+	return lo.Map(selector.NodeSelectorTerms, func(term v1.NodeSelectorTerm, _ int) []v1.NodeSelectorRequirement {
+		return term.MatchExpressions
+	})
+}
@@ -38,6 +38,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
@@ -45,7 +46,7 @@ import (
 
 func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1beta1.NodePool,
 	cluster *state.Cluster, stateNodes []*state.StateNode, topology *Topology,
-	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*v1.Pod,
+	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*v1.Pod, daemonOverheadCache *DaemonOverheadCache,
 	recorder events.Recorder) *Scheduler {
 
 	// if any of the nodePools add a taint with a prefer no schedule effect, we add a toleration for the taint
@@ -60,6 +61,7 @@ func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1
 	}
 
 	templates := lo.Map(nodePools, func(np *v1beta1.NodePool, _ int) *NodeClaimTemplate { return NewNodeClaimTemplate(np) })
+	daemonOverhead, archDaemonOverhead := daemonOverheadCache.overheads(nodePools, templates, instanceTypes, daemonSetPods)
 	s := &Scheduler{
 		id:                 uuid.NewUUID(),
 		kubeClient:         kubeClient,
@@ -67,28 +69,61 @@ func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1
 		topology:           topology,
 		cluster:            cluster,
 		instanceTypes:      instanceTypes,
-		daemonOverhead:     getDaemonOverhead(templates, daemonSetPods),
+		daemonOverhead:     daemonOverhead,
+		archDaemonOverhead: archDaemonOverhead,
 		recorder:           recorder,
 		preferences:        &Preferences{ToleratePreferNoSchedule: toleratePreferNoSchedule},
 		remainingResources: lo.SliceToMap(nodePools, func(np *v1beta1.NodePool) (string, v1.ResourceList) { return np.Name, v1.ResourceList(np.Spec.Limits) }),
+		limits:             lo.SliceToMap(nodePools, func(np *v1beta1.NodePool) (string, v1.ResourceList) { return np.Name, v1.ResourceList(np.Spec.Limits) }),
+		reservedLimits:     options.FromContext(ctx).ReservedLimitsPercentage,
+		binPackingStrategy: options.FromContext(ctx).BinPackingStrategy,
+		preemptionPolicies: lo.SliceToMap(nodePools, func(np *v1beta1.NodePool) (string, v1beta1.PreemptionPolicy) {
+			return np.Name, np.Spec.PreemptionPolicy
+		}),
+		overflowNodePools: lo.SliceToMap(lo.Filter(nodePools, func(np *v1beta1.NodePool, _ int) bool { return np.Spec.OverflowNodePool != "" }),
+			func(np *v1beta1.NodePool) (string, string) {
+				return np.Name, np.Spec.OverflowNodePool
+			}),
+		capacityTypeRatios: lo.SliceToMap(lo.Filter(nodePools, func(np *v1beta1.NodePool, _ int) bool { return np.Spec.CapacityTypeDistribution != nil }),
+			func(np *v1beta1.NodePool) (string, *v1beta1.CapacityTypeDistribution) {
+				return np.Name, np.Spec.CapacityTypeDistribution
+			}),
+		capacityTypeCounts:    map[string]map[string]int{},
+		remainingReservations: cluster.RemainingReservations(lo.Flatten(lo.Values(instanceTypes))),
+	}
+	if len(s.capacityTypeRatios) > 0 {
+		usage := cluster.NodePoolUsage()
+		for nodePoolName := range s.capacityTypeRatios {
+			s.capacityTypeCounts[nodePoolName] = lo.Assign(usage[nodePoolName].CapacityTypes)
+		}
 	}
 	s.calculateExistingNodeClaims(stateNodes, daemonSetPods)
 	return s
 }
 
 type Scheduler struct {
-	id                 types.UID // Unique UUID attached to this scheduling loop
-	newNodeClaims      []*NodeClaim
-	existingNodes      []*ExistingNode
-	nodeClaimTemplates []*NodeClaimTemplate
-	remainingResources map[string]v1.ResourceList               // (NodePool name) -> remaining resources for that NodePool
-	instanceTypes      map[string][]*cloudprovider.InstanceType // (NodePool name) -> instance types for NodePool
-	daemonOverhead     map[*NodeClaimTemplate]v1.ResourceList
-	preferences        *Preferences
-	topology           *Topology
-	cluster            *state.Cluster
-	recorder           events.Recorder
-	kubeClient         client.Client
+	id                    types.UID // Unique UUID attached to this scheduling loop
+	newNodeClaims         []*NodeClaim
+	existingNodes         []*ExistingNode
+	nodeClaimTemplates    []*NodeClaimTemplate
+	remainingResources    map[string]v1.ResourceList               // (NodePool name) -> remaining resources for that NodePool
+	limits                map[string]v1.ResourceList               // (NodePool name) -> resource limits for that NodePool
+	reservedLimits        int                                      // percentage of each NodePool's limits reserved for pods with a positive priority
+	binPackingStrategy    string                                   // order in which pods are packed onto new NodeClaims
+	preemptionPolicies    map[string]v1beta1.PreemptionPolicy      // (NodePool name) -> preemption policy for that NodePool
+	overflowNodePools     map[string]string                        // (NodePool name) -> NodePool that pods overflow to once its limits are exhausted
+	instanceTypes         map[string][]*cloudprovider.InstanceType // (NodePool name) -> instance types for NodePool
+	daemonOverhead        map[*NodeClaimTemplate]v1.ResourceList
+	archDaemonOverhead    map[*NodeClaimTemplate]map[string]v1.ResourceList // (NodeClaimTemplate) -> (architecture) -> daemon overhead
+	capacityTypeRatios    map[string]*v1beta1.CapacityTypeDistribution      // (NodePool name) -> target ratio between capacity types for that NodePool
+	capacityTypeCounts    map[string]map[string]int                         // (NodePool name) -> (capacity type) -> number of nodes and new NodeClaims
+	remainingReservations map[state.Reservation]int                         // (instance type and zone) -> number of NodeClaims that can still launch into its capacity reservations
+	existingNodePods      map[string][]*v1.Pod                              // (provider id) -> pods bound to the existing node, listed at most once per Solve for preemption
+	preferences           *Preferences
+	topology              *Topology
+	cluster               *state.Cluster
+	recorder              events.Recorder
+	kubeClient            client.Client
 }
 
 // Results contains the results of the scheduling operation
@@ -102,8 +137,12 @@ type Results struct {
 // It also nominates nodes in the cluster state based on the scheduling run to signal to other components
 // leveraging the cluster state that a previous scheduling run that was recorded is relying on these nodes
 func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *state.Cluster) {
-	// Report failures and nominations
+	// Report failures and nominations. Headroom pods don't exist in the cluster, so there's nothing to report them on,
+	// but the nodes that they're nominated to are still nominated so that their capacity isn't disrupted.
 	for p, err := range r.PodErrors {
+		if pod.IsOwnedByNodePool(p) {
+			continue
+		}
 		logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(p)).Errorf("Could not schedule pod, %s", err)
 		recorder.Publish(PodFailedToScheduleEvent(p, err))
 	}
@@ -111,7 +150,7 @@ func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *
 		if len(existing.Pods) > 0 {
 			cluster.NominateNodeForPod(ctx, existing.ProviderID())
 		}
-		for _, p := range existing.Pods {
+		for _, p := range lo.Reject(existing.Pods, func(p *v1.Pod, _ int) bool { return pod.IsOwnedByNodePool(p) }) {
 			recorder.Publish(NominatePodEvent(p, existing.Node, existing.NodeClaim))
 		}
 	}
@@ -212,8 +251,13 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*v1.Pod) Results {
 	// had 5xA pods and 5xB pods were they have a zonal topology spread, but A can only go in one zone and B in another.
 	// We need to schedule them alternating, A, B, A, B, .... and this solution also solves that as well.
 	errors := map[*v1.Pod]error{}
+	s.existingNodePods = map[string][]*v1.Pod{}
 	QueueDepth.DeletePartialMatch(prometheus.Labels{controllerLabel: injection.GetControllerName(ctx)}) // Reset the metric for the controller, so we don't keep old ids around
-	q := NewQueue(pods...)
+	var capacity v1.ResourceList
+	if s.binPackingStrategy == options.BinPackingStrategyDominantResource {
+		capacity = s.maxAllocatable()
+	}
+	q := NewQueue(s.binPackingStrategy, capacity, pods...)
 	for {
 		QueueDepth.With(
 			prometheus.Labels{controllerLabel: injection.GetControllerName(ctx), schedulingIDLabel: string(s.id)},
@@ -256,9 +300,15 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*v1.Pod) Results {
 }
 
 func (s *Scheduler) add(ctx context.Context, pod *v1.Pod) error {
+	// determine the volumes that the node that the pod schedules to would need to attach
+	volumes, err := scheduling.GetVolumes(ctx, s.kubeClient, pod)
+	if err != nil {
+		return err
+	}
+
 	// first try to schedule against an in-flight real node
 	for _, node := range s.existingNodes {
-		if err := node.Add(ctx, s.kubeClient, pod); err == nil {
+		if err := node.Add(pod, volumes); err == nil {
 			return nil
 		}
 	}
@@ -268,40 +318,143 @@ func (s *Scheduler) add(ctx context.Context, pod *v1.Pod) error {
 
 	// Pick existing node that we are about to create
 	for _, nodeClaim := range s.newNodeClaims {
-		if err := nodeClaim.Add(pod); err == nil {
+		if err := nodeClaim.Add(pod, volumes); err == nil {
 			return nil
 		}
 	}
 
+	// Only simulate preemption if there's a NodePool that leaves pods to kube-scheduler's preemption
+	preempting := lo.SomeBy(s.nodeClaimTemplates, func(nct *NodeClaimTemplate) bool {
+		return s.preemptionPolicies[nct.NodePoolName] == v1beta1.PreemptionPolicyPreempt
+	}) && s.canPreempt(ctx, pod)
+
 	// Create new node
 	var errs error
-	for _, nodeClaimTemplate := range s.nodeClaimTemplates {
+	var failures []NodePoolFailure
+	nodeClaimTemplates := s.nodeClaimTemplates
+	for i := 0; i < len(nodeClaimTemplates); i++ {
+		nodeClaimTemplate := nodeClaimTemplates[i]
+		if preempting && s.preemptionPolicies[nodeClaimTemplate.NodePoolName] == v1beta1.PreemptionPolicyPreempt {
+			errs = multierr.Append(errs, fmt.Errorf("pod can schedule by preempting lower priority pods, not provisioning from nodepool %q", nodeClaimTemplate.NodePoolName))
+			failures = append(failures, NodePoolFailure{NodePool: nodeClaimTemplate.NodePoolName, Reason: FailureReasonPreemption, Message: "pod can schedule to an existing node by preempting lower priority pods"})
+			continue
+		}
 		instanceTypes := s.instanceTypes[nodeClaimTemplate.NodePoolName]
 		// if limits have been applied to the nodepool, ensure we filter instance types to avoid violating those limits
 		if remaining, ok := s.remainingResources[nodeClaimTemplate.NodePoolName]; ok {
 			instanceTypes = filterByRemainingResources(s.instanceTypes[nodeClaimTemplate.NodePoolName], remaining)
 			if len(instanceTypes) == 0 {
 				errs = multierr.Append(errs, fmt.Errorf("all available instance types exceed limits for nodepool: %q", nodeClaimTemplate.NodePoolName))
+				failures = append(failures, NodePoolFailure{NodePool: nodeClaimTemplate.NodePoolName, Reason: FailureReasonLimits, Message: "all available instance types exceed limits"})
+				nodeClaimTemplates = s.overflow(nodeClaimTemplates, i)
 				continue
 			} else if len(s.instanceTypes[nodeClaimTemplate.NodePoolName]) != len(instanceTypes) {
 				logging.FromContext(ctx).With("nodepool", nodeClaimTemplate.NodePoolName).Debugf("%d out of %d instance types were excluded because they would breach limits",
 					len(s.instanceTypes[nodeClaimTemplate.NodePoolName])-len(instanceTypes), len(s.instanceTypes[nodeClaimTemplate.NodePoolName]))
 			}
 		}
-		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], instanceTypes)
-		if err := nodeClaim.Add(pod); err != nil {
+		if s.limitsReserved(nodeClaimTemplate.NodePoolName, pod) {
+			errs = multierr.Append(errs, fmt.Errorf("remaining limits for nodepool %q are reserved for pods with a positive priority", nodeClaimTemplate.NodePoolName))
+			failures = append(failures, NodePoolFailure{NodePool: nodeClaimTemplate.NodePoolName, Reason: FailureReasonLimits, Message: "remaining limits are reserved for pods with a positive priority"})
+			nodeClaimTemplates = s.overflow(nodeClaimTemplates, i)
+			continue
+		}
+		// Instance types that can't satisfy the pod's node selector are never going to be compatible, so we drop them
+		// before evaluating the full set of requirements
+		if selected := filterByNodeSelector(instanceTypes, pod); len(selected) > 0 {
+			instanceTypes = selected
+		}
+		nodeClaim, err := s.newNodeClaim(nodeClaimTemplate, instanceTypes, pod, volumes)
+		if err != nil {
 			errs = multierr.Append(errs, fmt.Errorf("incompatible with nodepool %q, daemonset overhead=%s, %w",
 				nodeClaimTemplate.NodePoolName,
 				resources.String(s.daemonOverhead[nodeClaimTemplate]),
 				err))
+			failures = append(failures, NodePoolFailure{NodePool: nodeClaimTemplate.NodePoolName, Reason: failureReason(err), Message: err.Error()})
 			continue
 		}
 		// we will launch this nodeClaim and need to track its maximum possible resource usage against our remaining resources
 		s.newNodeClaims = append(s.newNodeClaims, nodeClaim)
 		s.remainingResources[nodeClaimTemplate.NodePoolName] = subtractMax(s.remainingResources[nodeClaimTemplate.NodePoolName], nodeClaim.InstanceTypeOptions)
+		if counts, ok := s.capacityTypeCounts[nodeClaimTemplate.NodePoolName]; ok {
+			counts[nodeClaim.Requirements.Get(v1beta1.CapacityTypeLabelKey).Any()]++
+		}
+		return nil
+	}
+	if errs == nil {
 		return nil
 	}
-	return errs
+	return &SchedulingError{NodePools: failures, err: errs}
+}
+
+// overflow returns the templates with the template of the overflow nodepool of the i-th template's nodepool moved up to
+// be tried next, since the i-th template's nodepool has exhausted its limits. Overflow nodepools that have already been
+// tried are left where they are, which also prevents overflow cycles.
+func (s *Scheduler) overflow(nodeClaimTemplates []*NodeClaimTemplate, i int) []*NodeClaimTemplate {
+	nodePoolName, ok := s.overflowNodePools[nodeClaimTemplates[i].NodePoolName]
+	if !ok {
+		return nodeClaimTemplates
+	}
+	_, j, ok := lo.FindIndexOf(nodeClaimTemplates[i+1:], func(nct *NodeClaimTemplate) bool { return nct.NodePoolName == nodePoolName })
+	if !ok || j == 0 {
+		return nodeClaimTemplates
+	}
+	j += i + 1
+	reordered := make([]*NodeClaimTemplate, 0, len(nodeClaimTemplates))
+	reordered = append(reordered, nodeClaimTemplates[:i+1]...)
+	reordered = append(reordered, nodeClaimTemplates[j])
+	reordered = append(reordered, nodeClaimTemplates[i+1:j]...)
+	return append(reordered, nodeClaimTemplates[j+1:]...)
+}
+
+// newNodeClaim creates a NodeClaim from the template and adds the pod to it. NodeClaims are restricted to reserved
+// capacity while the capacity reservations of the instance types have room. Otherwise, NodeClaims from nodepools with a
+// capacity type distribution are restricted to a single capacity type, trying the capacity types in order of how far
+// they are below their target share.
+func (s *Scheduler) newNodeClaim(nodeClaimTemplate *NodeClaimTemplate, instanceTypes []*cloudprovider.InstanceType, pod *v1.Pod, volumes scheduling.Volumes) (*NodeClaim, error) {
+	var err error
+	if reserved := s.reservedInstanceTypes(instanceTypes); len(reserved) > 0 {
+		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], s.archDaemonOverhead[nodeClaimTemplate], reserved)
+		nodeClaim.Requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, v1beta1.CapacityTypeReserved))
+		if err = nodeClaim.Add(pod, volumes); err == nil {
+			s.reserve(nodeClaim)
+			return nodeClaim, nil
+		}
+	}
+	// Capacity reservations are only launched into by the NodeClaims above, which keep track of the room left in them
+	reservations := lo.SomeBy(instanceTypes, hasReservedOfferings)
+	for _, capacityType := range s.capacityTypePreferences(nodeClaimTemplate.NodePoolName) {
+		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], s.archDaemonOverhead[nodeClaimTemplate], instanceTypes)
+		if capacityType != "" {
+			nodeClaim.Requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, capacityType))
+		} else if reservations {
+			nodeClaim.Requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpNotIn, v1beta1.CapacityTypeReserved))
+		}
+		if err = nodeClaim.Add(pod, volumes); err == nil {
+			return nodeClaim, nil
+		}
+	}
+	return nil, err
+}
+
+// capacityTypePreferences returns the capacity types to restrict the nodepool's next NodeClaim to, ordered by how far
+// each would remain below its target share once the NodeClaim launched. Nodepools without a capacity type distribution
+// aren't restricted, which is represented by a single empty capacity type. Nodes and NodeClaims that are launched into
+// capacity reservations count towards the on-demand share, since reserved capacity isn't interrupted either.
+func (s *Scheduler) capacityTypePreferences(nodePoolName string) []string {
+	distribution, ok := s.capacityTypeRatios[nodePoolName]
+	if !ok {
+		return []string{""}
+	}
+	counts := lo.Assign(s.capacityTypeCounts[nodePoolName])
+	counts[v1beta1.CapacityTypeOnDemand] += counts[v1beta1.CapacityTypeReserved]
+	capacityTypes := []string{v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand}
+	sort.SliceStable(capacityTypes, func(i, j int) bool {
+		// Compares (count_i+1)/weight_i < (count_j+1)/weight_j without dividing by a weight of zero
+		return int64(counts[capacityTypes[i]]+1)*int64(distribution.Weight(capacityTypes[j])) <
+			int64(counts[capacityTypes[j]]+1)*int64(distribution.Weight(capacityTypes[i]))
+	})
+	return capacityTypes
 }
 
 func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, daemonSetPods []*v1.Pod) {
@@ -310,7 +463,7 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 		// Calculate any daemonsets that should schedule to the inflight node
 		var daemons []*v1.Pod
 		for _, p := range daemonSetPods {
-			if err := scheduling.Taints(node.Taints()).Tolerates(p); err != nil {
+			if err := scheduling.HardTaints(node.Taints()).Tolerates(p); err != nil {
 				continue
 			}
 			if err := scheduling.NewLabelRequirements(node.Labels()).Compatible(scheduling.NewPodRequirements(p)); err != nil {
@@ -318,6 +471,17 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 			}
 			daemons = append(daemons, p)
 		}
+		// In-flight nodes don't know their volume limits until their CSINode reports them, so they're limited by what
+		// their instance type can attach until then
+		if !node.VolumeUsage().HasLimits() {
+			if it, ok := lo.Find(s.instanceTypes[node.Labels()[v1beta1.NodePoolLabelKey]], func(it *cloudprovider.InstanceType) bool {
+				return it.Name == node.Labels()[v1.LabelInstanceTypeStable]
+			}); ok {
+				for driver, limit := range it.VolumeLimits {
+					node.VolumeUsage().AddLimit(driver, limit)
+				}
+			}
+		}
 		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, resources.RequestsForPods(daemons...)))
 
 		// We don't use the status field and instead recompute the remaining resources to ensure we have a consistent view
@@ -327,6 +491,11 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 			s.remainingResources[node.Labels()[v1beta1.NodePoolLabelKey]] = resources.Subtract(s.remainingResources[node.Labels()[v1beta1.NodePoolLabelKey]], node.Capacity())
 		}
 	}
+	// NodeClaims that haven't launched yet don't have any capacity, so the resources that are reserved for them are
+	// subtracted instead
+	for nodePoolName, remaining := range s.remainingResources {
+		s.remainingResources[nodePoolName] = resources.Subtract(remaining, s.cluster.ReservedLimits(nodePoolName))
+	}
 	// Order the existing nodes for scheduling with initialized nodes first
 	// This is done specifically for consolidation where we want to make sure we schedule to initialized nodes
 	// before we attempt to schedule un-initialized ones
@@ -341,23 +510,16 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 	})
 }
 
-func getDaemonOverhead(nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*v1.Pod) map[*NodeClaimTemplate]v1.ResourceList {
-	overhead := map[*NodeClaimTemplate]v1.ResourceList{}
-
-	for _, nodeClaimTemplate := range nodeClaimTemplates {
-		var daemons []*v1.Pod
-		for _, p := range daemonSetPods {
-			if err := scheduling.Taints(nodeClaimTemplate.Spec.Taints).Tolerates(p); err != nil {
-				continue
-			}
-			if err := nodeClaimTemplate.Requirements.Compatible(scheduling.NewPodRequirements(p), scheduling.AllowUndefinedWellKnownLabels); err != nil {
-				continue
-			}
-			daemons = append(daemons, p)
-		}
-		overhead[nodeClaimTemplate] = resources.RequestsForPods(daemons...)
+// architecture returns the single architecture of an instance type, if it has one
+func architecture(instanceType *cloudprovider.InstanceType) (string, bool) {
+	if !instanceType.Requirements.Has(v1.LabelArchStable) {
+		return "", false
+	}
+	arch := instanceType.Requirements.Get(v1.LabelArchStable)
+	if arch.Operator() != v1.NodeSelectorOpIn || arch.Len() != 1 {
+		return "", false
 	}
-	return overhead
+	return arch.Any(), true
 }
 
 // subtractMax returns the remaining resources after subtracting the max resource quantity per instance type. To avoid
@@ -383,6 +545,29 @@ func subtractMax(remaining v1.ResourceList, instanceTypes []*cloudprovider.Insta
 	return result
 }
 
+// limitsReserved returns true if the pod doesn't have a positive priority and the nodepool is close enough to its
+// limits that its remaining resources are reserved for pods that do
+func (s *Scheduler) limitsReserved(nodePoolName string, pod *v1.Pod) bool {
+	if s.reservedLimits == 0 || lo.FromPtr(pod.Spec.Priority) > 0 {
+		return false
+	}
+	remaining := s.remainingResources[nodePoolName]
+	for resourceName, limit := range s.limits[nodePoolName] {
+		if remainingQuantity, ok := remaining[resourceName]; ok && remainingQuantity.AsApproximateFloat64() < limit.AsApproximateFloat64()*float64(s.reservedLimits)/100 {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAllocatable returns the most of each resource that any of the instance types can allocate to pods, which the
+// dominant resource binpacking strategy measures the pods against
+func (s *Scheduler) maxAllocatable() v1.ResourceList {
+	return resources.MaxResources(lo.Map(lo.Flatten(lo.Values(s.instanceTypes)), func(it *cloudprovider.InstanceType, _ int) v1.ResourceList {
+		return it.Allocatable()
+	})...)
+}
+
 // filterByRemainingResources is used to filter out instance types that if launched would exceed the nodepool limits
 func filterByRemainingResources(instanceTypes []*cloudprovider.InstanceType, remaining v1.ResourceList) []*cloudprovider.InstanceType {
 	var filtered []*cloudprovider.InstanceType
@@ -401,3 +586,19 @@ func filterByRemainingResources(instanceTypes []*cloudprovider.InstanceType, rem
 	}
 	return filtered
 }
+
+// filterByNodeSelector is used to filter out instance types that define a label from the pod's node selector with a
+// different value. Labels that an instance type doesn't define are left to the NodeClaim's requirements.
+func filterByNodeSelector(instanceTypes []*cloudprovider.InstanceType, pod *v1.Pod) []*cloudprovider.InstanceType {
+	if len(pod.Spec.NodeSelector) == 0 {
+		return instanceTypes
+	}
+	return lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+		for key, value := range pod.Spec.NodeSelector {
+			if it.Requirements.Has(key) && !it.Requirements.Get(key).Has(value) {
+				return false
+			}
+		}
+		return true
+	})
+}
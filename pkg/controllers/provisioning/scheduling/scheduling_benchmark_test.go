@@ -176,7 +176,7 @@ func benchmarkScheduler(b *testing.B, instanceCount, podCount int) {
 
 	scheduler := scheduling.NewScheduler(ctx, client, []*v1beta1.NodePool{nodePool},
 		cluster, nil, topology,
-		map[string][]*cloudprovider.InstanceType{nodePool.Name: instanceTypes}, nil,
+		map[string][]*cloudprovider.InstanceType{nodePool.Name: instanceTypes}, nil, nil,
 		events.NewRecorder(&record.FakeRecorder{}))
 
 	b.ResetTimer()
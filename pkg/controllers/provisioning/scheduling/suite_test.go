@@ -19,6 +19,7 @@ package scheduling_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -30,6 +31,7 @@ import (
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -96,7 +98,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	podStateController = informer.NewPodController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
 })
 
 var _ = AfterSuite(func() {
@@ -201,6 +203,19 @@ var _ = Context("Scheduling", func() {
 				node := ExpectScheduled(ctx, env.Client, pod)
 				Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
 			})
+			It("should exclude instance types that match NodePool patterns", func() {
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1beta1.NodeSelectorOpNotMatches, Values: []string{"default-.*"}}}}
+				ExpectApplied(ctx, env.Client, nodePool)
+				excluded := test.UnschedulablePod(
+					test.PodOptions{NodeSelector: map[string]string{v1.LabelInstanceTypeStable: "default-instance-type"}},
+				)
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, excluded, pod)
+				ExpectNotScheduled(ctx, env.Client, excluded)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1.LabelInstanceTypeStable]).ToNot(HavePrefix("default-"))
+			})
 			It("should use node selectors", func() {
 				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
 					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}}
@@ -1216,6 +1231,20 @@ var _ = Context("Scheduling", func() {
 			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
+		It("should not schedule to an offering without a zone if the pod constrains the zone", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:      "zoneless-instance-type",
+					Offerings: []cloudprovider.Offering{{CapacityType: v1beta1.CapacityTypeOnDemand, Price: 1, Available: true}},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeRequirements: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn, Values: []string{"test-zone-1"}}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
 		It("should launch pods with different archs on different instances", func() {
 			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
 				{
@@ -1801,6 +1830,50 @@ var _ = Context("Scheduling", func() {
 			possibleInstanceType := sets.NewString(pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Values()...)
 			Expect(possibleInstanceType).To(Equal(sets.NewString("small", "medium", "large")))
 		})
+		Context("Strategies", func() {
+			var cpuHeavyPod, memoryHeavyPod *v1.Pod
+			BeforeEach(func() {
+				cpuHeavyPod = test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				}})
+				memoryHeavyPod = test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("8Gi")},
+				}})
+			})
+			It("should pack the pods with the largest CPU requests first", func() {
+				q := scheduling.NewQueue(options.BinPackingStrategyCPU, nil, memoryHeavyPod, cpuHeavyPod)
+				Expect(q.List()).To(Equal([]*v1.Pod{cpuHeavyPod, memoryHeavyPod}))
+			})
+			It("should pack the pods with the largest memory requests first", func() {
+				q := scheduling.NewQueue(options.BinPackingStrategyMemory, nil, cpuHeavyPod, memoryHeavyPod)
+				Expect(q.List()).To(Equal([]*v1.Pod{memoryHeavyPod, cpuHeavyPod}))
+			})
+			It("should pack the pods with the largest share of the capacity first", func() {
+				q := scheduling.NewQueue(options.BinPackingStrategyDominantResource, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("16"),
+					v1.ResourceMemory: resource.MustParse("16Gi"),
+				}, cpuHeavyPod, memoryHeavyPod)
+				Expect(q.List()).To(Equal([]*v1.Pod{memoryHeavyPod, cpuHeavyPod}))
+
+				q = scheduling.NewQueue(options.BinPackingStrategyDominantResource, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("8"),
+					v1.ResourceMemory: resource.MustParse("64Gi"),
+				}, memoryHeavyPod, cpuHeavyPod)
+				Expect(q.List()).To(Equal([]*v1.Pod{cpuHeavyPod, memoryHeavyPod}))
+			})
+			It("should pack higher priority pods first regardless of the strategy", func() {
+				cpuHeavyPod.Spec.Priority = lo.ToPtr[int32](100)
+				q := scheduling.NewQueue(options.BinPackingStrategyMemory, nil, memoryHeavyPod, cpuHeavyPod)
+				Expect(q.List()).To(Equal([]*v1.Pod{cpuHeavyPod, memoryHeavyPod}))
+			})
+			It("should schedule pods with the memory binpacking strategy", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{BinPackingStrategy: lo.ToPtr(options.BinPackingStrategyMemory)}))
+				ExpectApplied(ctx, env.Client, nodePool)
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, cpuHeavyPod, memoryHeavyPod)
+				ExpectScheduled(ctx, env.Client, cpuHeavyPod)
+				ExpectScheduled(ctx, env.Client, memoryHeavyPod)
+			})
+		})
 	})
 
 	Describe("In-Flight Nodes", func() {
@@ -2438,6 +2511,48 @@ var _ = Context("Scheduling", func() {
 			scheduledNode := ExpectScheduled(ctx, env.Client, pod)
 			Expect(node.Name).To(Equal(scheduledNode.Name))
 		})
+		It("should not schedule pods to the reserved resources of an existing node", func() {
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1beta1.ReservedResourcesAnnotationKey: "cpu=9500m"},
+				},
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("10"),
+					v1.ResourceMemory: resource.MustParse("10Gi"),
+					v1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			scheduledNode := ExpectScheduled(ctx, env.Client, pod)
+			Expect(scheduledNode.Name).ToNot(Equal(node.Name))
+		})
+		It("should schedule a pod to an existing node with the disruption PreferNoSchedule taint", func() {
+			node := test.Node(test.NodeOptions{
+				Taints: []v1.Taint{v1beta1.DisruptionPreferNoScheduleTaint},
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("10"),
+					v1.ResourceMemory: resource.MustParse("10Gi"),
+					v1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			scheduledNode := ExpectScheduled(ctx, env.Client, pod)
+			Expect(scheduledNode.Name).To(Equal(node.Name))
+		})
 		It("should schedule multiple pods to an existing node unowned by Karpenter", func() {
 			node := test.Node(test.NodeOptions{
 				Allocatable: v1.ResourceList{
@@ -2761,6 +2876,123 @@ var _ = Context("Scheduling", func() {
 			// we need to create a new node as the in-flight one can only contain 5 pods due to the CSINode volume limit
 			Expect(nodeList.Items).To(HaveLen(2))
 		})
+		It("should launch multiple nodes if required due to instance type volume limits", func() {
+			cloudProvider.InstanceTypes[0].VolumeLimits = map[string]int{csiProvider: 4}
+			ExpectApplied(ctx, env.Client, nodePool)
+			sc := test.StorageClass(test.StorageClassOptions{
+				ObjectMeta:  metav1.ObjectMeta{Name: "my-storage-class"},
+				Provisioner: ptr.String(csiProvider),
+				Zones:       []string{"test-zone-1"}})
+			ExpectApplied(ctx, env.Client, sc)
+
+			var pods []*v1.Pod
+			for i := 0; i < 3; i++ {
+				pvcA := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+					StorageClassName: ptr.String("my-storage-class"),
+					ObjectMeta:       metav1.ObjectMeta{Name: fmt.Sprintf("my-claim-a-%d", i)},
+				})
+				pvcB := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+					StorageClassName: ptr.String("my-storage-class"),
+					ObjectMeta:       metav1.ObjectMeta{Name: fmt.Sprintf("my-claim-b-%d", i)},
+				})
+				ExpectApplied(ctx, env.Client, pvcA, pvcB)
+				pods = append(pods, test.UnschedulablePod(test.PodOptions{
+					PersistentVolumeClaims: []string{pvcA.Name, pvcB.Name},
+				}))
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			var nodeList v1.NodeList
+			Expect(env.Client.List(ctx, &nodeList)).To(Succeed())
+			// the instance type can only attach the volumes of 2 pods
+			Expect(nodeList.Items).To(HaveLen(2))
+		})
+		It("should launch instance types that can attach the pod's volumes", func() {
+			cloudProvider.InstanceTypes[0].VolumeLimits = map[string]int{csiProvider: 1}
+			cloudProvider.InstanceTypes = append(cloudProvider.InstanceTypes, fake.NewInstanceType(
+				fake.InstanceTypeOptions{
+					Name: "large-instance-type",
+					Resources: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceCPU:  resource.MustParse("2048"),
+						v1.ResourcePods: resource.MustParse("1024"),
+					},
+				}))
+			cloudProvider.InstanceTypes[1].VolumeLimits = map[string]int{csiProvider: 4}
+			ExpectApplied(ctx, env.Client, nodePool)
+			sc := test.StorageClass(test.StorageClassOptions{
+				ObjectMeta:  metav1.ObjectMeta{Name: "my-storage-class"},
+				Provisioner: ptr.String(csiProvider),
+				Zones:       []string{"test-zone-1"}})
+			pvcA := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+				StorageClassName: ptr.String("my-storage-class"),
+				ObjectMeta:       metav1.ObjectMeta{Name: "my-claim-a"},
+			})
+			pvcB := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+				StorageClassName: ptr.String("my-storage-class"),
+				ObjectMeta:       metav1.ObjectMeta{Name: "my-claim-b"},
+			})
+			ExpectApplied(ctx, env.Client, sc, pvcA, pvcB)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{pvcA.Name, pvcB.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large-instance-type"))
+		})
+		It("should not launch nodes for pods whose volumes no instance type can attach", func() {
+			cloudProvider.InstanceTypes[0].VolumeLimits = map[string]int{csiProvider: 1}
+			ExpectApplied(ctx, env.Client, nodePool)
+			sc := test.StorageClass(test.StorageClassOptions{
+				ObjectMeta:  metav1.ObjectMeta{Name: "my-storage-class"},
+				Provisioner: ptr.String(csiProvider),
+				Zones:       []string{"test-zone-1"}})
+			pvcA := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+				StorageClassName: ptr.String("my-storage-class"),
+				ObjectMeta:       metav1.ObjectMeta{Name: "my-claim-a"},
+			})
+			pvcB := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+				StorageClassName: ptr.String("my-storage-class"),
+				ObjectMeta:       metav1.ObjectMeta{Name: "my-claim-b"},
+			})
+			ExpectApplied(ctx, env.Client, sc, pvcA, pvcB)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{pvcA.Name, pvcB.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should limit volumes on in-flight nodes by their instance type until the CSINode reports limits", func() {
+			cloudProvider.InstanceTypes[0].VolumeLimits = map[string]int{csiProvider: 2}
+			ExpectApplied(ctx, env.Client, nodePool)
+			initialPod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, initialPod)
+			ExpectScheduled(ctx, env.Client, initialPod)
+
+			sc := test.StorageClass(test.StorageClassOptions{
+				ObjectMeta:  metav1.ObjectMeta{Name: "my-storage-class"},
+				Provisioner: ptr.String(csiProvider),
+				Zones:       []string{"test-zone-1"}})
+			ExpectApplied(ctx, env.Client, sc)
+			var pods []*v1.Pod
+			for i := 0; i < 2; i++ {
+				pvcA := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+					StorageClassName: ptr.String("my-storage-class"),
+					ObjectMeta:       metav1.ObjectMeta{Name: fmt.Sprintf("my-claim-a-%d", i)},
+				})
+				pvcB := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+					StorageClassName: ptr.String("my-storage-class"),
+					ObjectMeta:       metav1.ObjectMeta{Name: fmt.Sprintf("my-claim-b-%d", i)},
+				})
+				ExpectApplied(ctx, env.Client, pvcA, pvcB)
+				pods = append(pods, test.UnschedulablePod(test.PodOptions{
+					PersistentVolumeClaims: []string{pvcA.Name, pvcB.Name},
+				}))
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			var nodeList v1.NodeList
+			Expect(env.Client.List(ctx, &nodeList)).To(Succeed())
+			// the in-flight node can only attach the volumes of one of the pods
+			Expect(nodeList.Items).To(HaveLen(2))
+		})
 		It("should launch a single node if all pods use the same PVC", func() {
 			ExpectApplied(ctx, env.Client, nodePool)
 			initialPod := test.UnschedulablePod()
@@ -3630,6 +3862,309 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Preemption", func() {
+		var node *v1.Node
+		var highPriority *schedulingv1.PriorityClass
+		BeforeEach(func() {
+			highPriority = test.PriorityClass(1000)
+			node = test.Node(test.NodeOptions{
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("1"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+					v1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node, highPriority)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+		})
+		// bindPod binds a pod to the node, with the priority of the PriorityClass if one is passed
+		bindPod := func(priorityClass *schedulingv1.PriorityClass) {
+			GinkgoHelper()
+			opts := test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("900m")}},
+			}
+			if priorityClass != nil {
+				opts.PriorityClassName = priorityClass.Name
+				opts.Priority = lo.ToPtr(priorityClass.Value)
+			}
+			pod := test.Pod(opts)
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectReconcileSucceeded(ctx, podStateController, client.ObjectKeyFromObject(pod))
+		}
+		pendingPod := func() *v1.Pod {
+			return test.UnschedulablePod(test.PodOptions{
+				PriorityClassName:    highPriority.Name,
+				Priority:             lo.ToPtr(highPriority.Value),
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}},
+			})
+		}
+		It("should not provision for a pod that can preempt lower priority pods", func() {
+			nodePool.Spec.PreemptionPolicy = v1beta1.PreemptionPolicyPreempt
+			ExpectApplied(ctx, env.Client, nodePool)
+			bindPod(nil)
+			pod := pendingPod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		})
+		It("should provision for a pod that can preempt lower priority pods when the nodepool provisions", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			bindPod(nil)
+			pod := pendingPod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
+		It("should provision for a pod that can't preempt pods with the same priority", func() {
+			nodePool.Spec.PreemptionPolicy = v1beta1.PreemptionPolicyPreempt
+			ExpectApplied(ctx, env.Client, nodePool)
+			bindPod(highPriority)
+			pod := pendingPod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
+		It("should provision for a pod with a preemption policy of Never", func() {
+			nodePool.Spec.PreemptionPolicy = v1beta1.PreemptionPolicyPreempt
+			ExpectApplied(ctx, env.Client, nodePool)
+			bindPod(nil)
+			pod := pendingPod()
+			pod.Spec.PreemptionPolicy = lo.ToPtr(v1.PreemptNever)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
+		It("should explain that the pod can schedule through preemption", func() {
+			nodePool.Spec.PreemptionPolicy = v1beta1.PreemptionPolicyPreempt
+			ExpectApplied(ctx, env.Client, nodePool)
+			bindPod(nil)
+			pod := pendingPod()
+			s, err := prov.NewScheduler(ctx, []*v1.Pod{pod}, cluster.Nodes())
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, []*v1.Pod{pod})
+			var schedulingErr *scheduling.SchedulingError
+			Expect(errors.As(results.PodErrors[pod], &schedulingErr)).To(BeTrue())
+			Expect(schedulingErr.Reason()).To(Equal("IncompatiblePreemption"))
+		})
+	})
+	Describe("Capacity Type Distribution", func() {
+		// capacityTypes solves the pods and returns the number of new NodeClaims with each capacity type
+		capacityTypes := func(pods ...*v1.Pod) map[string]int {
+			GinkgoHelper()
+			s, err := prov.NewScheduler(ctx, pods, cluster.Nodes())
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, pods)
+			Expect(results.PodErrors).To(BeEmpty())
+			return lo.CountValuesBy(results.NewNodeClaims, func(nc *scheduling.NodeClaim) string {
+				return nc.Requirements.Get(v1beta1.CapacityTypeLabelKey).Any()
+			})
+		}
+		// hostPortPods returns pods that each need their own node
+		hostPortPods := func(count int) []*v1.Pod {
+			return test.UnschedulablePods(test.PodOptions{HostPorts: []int32{80}}, count)
+		}
+		It("should launch nodes in the ratio of the distribution", func() {
+			nodePool.Spec.CapacityTypeDistribution = &v1beta1.CapacityTypeDistribution{Spot: 70, OnDemand: 30}
+			ExpectApplied(ctx, env.Client, nodePool)
+			Expect(capacityTypes(hostPortPods(10)...)).To(Equal(map[string]int{
+				v1beta1.CapacityTypeSpot:     7,
+				v1beta1.CapacityTypeOnDemand: 3,
+			}))
+		})
+		It("should count the nodepool's existing nodes towards the distribution", func() {
+			nodePool.Spec.CapacityTypeDistribution = &v1beta1.CapacityTypeDistribution{Spot: 50, OnDemand: 50}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < 2; i++ {
+				node := test.Node(test.NodeOptions{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeOnDemand,
+					}},
+					Taints:     []v1.Taint{{Key: "untolerated", Effect: v1.TaintEffectNoSchedule}},
+					ProviderID: test.RandomProviderID(),
+				})
+				ExpectApplied(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			}
+			Expect(capacityTypes(hostPortPods(2)...)).To(Equal(map[string]int{v1beta1.CapacityTypeSpot: 2}))
+		})
+		It("should fall back to the other capacity type for pods that require it", func() {
+			nodePool.Spec.CapacityTypeDistribution = &v1beta1.CapacityTypeDistribution{Spot: 1, OnDemand: 0}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeOnDemand}})
+			Expect(capacityTypes(pod)).To(Equal(map[string]int{v1beta1.CapacityTypeOnDemand: 1}))
+		})
+		It("should not restrict the capacity type of nodepools without a distribution", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			s, err := prov.NewScheduler(ctx, []*v1.Pod{pod}, cluster.Nodes())
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, []*v1.Pod{pod})
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			Expect(results.NewNodeClaims[0].Requirements.Get(v1beta1.CapacityTypeLabelKey).Len()).To(Equal(2))
+		})
+	})
+	Describe("Capacity Reservations", func() {
+		BeforeEach(func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+				Key:      v1beta1.CapacityTypeLabelKey,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{v1beta1.CapacityTypeReserved, v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand},
+			}}}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "reserved-instance-type",
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-1", Price: 1, Available: true, ReservationCapacity: 2},
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: true},
+					{CapacityType: v1beta1.CapacityTypeSpot, Zone: "test-zone-1", Price: 0.5, Available: true},
+				},
+			})}
+		})
+		// reserved solves the pods and returns the number of new NodeClaims that are restricted to reserved capacity,
+		// along with the number of NodeClaims that aren't allowed to launch into it
+		reserved := func(pods ...*v1.Pod) (int, int) {
+			GinkgoHelper()
+			s, err := prov.NewScheduler(ctx, pods, cluster.Nodes())
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, pods)
+			Expect(results.PodErrors).To(BeEmpty())
+			return lo.CountBy(results.NewNodeClaims, func(nc *scheduling.NodeClaim) bool {
+					return nc.Requirements.Get(v1beta1.CapacityTypeLabelKey).Len() == 1 &&
+						nc.Requirements.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeReserved)
+				}), lo.CountBy(results.NewNodeClaims, func(nc *scheduling.NodeClaim) bool {
+					return !nc.Requirements.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeReserved)
+				})
+		}
+		hostPortPods := func(count int) []*v1.Pod {
+			return test.UnschedulablePods(test.PodOptions{HostPorts: []int32{80}}, count)
+		}
+		It("should prefer reserved capacity while the reservations have room", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			inReservations, outsideReservations := reserved(hostPortPods(3)...)
+			Expect(inReservations).To(Equal(2))
+			Expect(outsideReservations).To(Equal(1))
+		})
+		It("should count the nodes that were launched into the reservations", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeReserved,
+					v1.LabelInstanceTypeStable:   "reserved-instance-type",
+					v1.LabelTopologyZone:         "test-zone-1",
+				}},
+				Taints:     []v1.Taint{{Key: "untolerated", Effect: v1.TaintEffectNoSchedule}},
+				ProviderID: test.RandomProviderID(),
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			inReservations, outsideReservations := reserved(hostPortPods(2)...)
+			Expect(inReservations).To(Equal(1))
+			Expect(outsideReservations).To(Equal(1))
+		})
+		It("should count the NodeClaims that haven't launched into the reservations yet", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Spec: v1beta1.NodeClaimSpec{Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1beta1.CapacityTypeLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.CapacityTypeReserved}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"reserved-instance-type"}}},
+				}},
+			})
+			nodeClaim.Status.ProviderID = ""
+			cluster.UpdateNodeClaim(nodeClaim)
+			inReservations, outsideReservations := reserved(hostPortPods(2)...)
+			Expect(inReservations).To(Equal(1))
+			Expect(outsideReservations).To(Equal(1))
+		})
+		It("should not launch pods that require another capacity type into the reservations", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot}})
+			inReservations, outsideReservations := reserved(pod)
+			Expect(inReservations).To(Equal(0))
+			Expect(outsideReservations).To(Equal(1))
+		})
+		It("should not restrict the capacity type of nodepools without reservations", func() {
+			cloudProvider.InstanceTypes = fake.InstanceTypes(1)
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			s, err := prov.NewScheduler(ctx, []*v1.Pod{pod}, cluster.Nodes())
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, []*v1.Pod{pod})
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			Expect(results.NewNodeClaims[0].Requirements.Get(v1beta1.CapacityTypeLabelKey).Len()).To(Equal(3))
+		})
+	})
+	Describe("Scheduling Explanations", func() {
+		solve := func(pod *v1.Pod) *scheduling.SchedulingError {
+			GinkgoHelper()
+			s, err := prov.NewScheduler(ctx, []*v1.Pod{pod}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			results := s.Solve(ctx, []*v1.Pod{pod})
+			Expect(results.PodErrors).To(HaveKey(pod))
+			var schedulingErr *scheduling.SchedulingError
+			Expect(errors.As(results.PodErrors[pod], &schedulingErr)).To(BeTrue())
+			return schedulingErr
+		}
+		It("should explain when a pod doesn't tolerate a nodepool's taints", func() {
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			schedulingErr := solve(test.UnschedulablePod())
+			Expect(schedulingErr.NodePools).To(HaveLen(1))
+			Expect(schedulingErr.NodePools[0].NodePool).To(Equal(nodePool.Name))
+			Expect(schedulingErr.NodePools[0].Reason).To(Equal(scheduling.FailureReasonTaints))
+			Expect(schedulingErr.Reason()).To(Equal("IncompatibleTaints"))
+		})
+		It("should explain when a pod's requirements aren't compatible with a nodepool", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			schedulingErr := solve(test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown-zone"}}))
+			Expect(schedulingErr.NodePools).To(HaveLen(1))
+			Expect(schedulingErr.NodePools[0].Reason).To(Equal(scheduling.FailureReasonRequirements))
+			Expect(schedulingErr.Explanation()).To(ContainSubstring(nodePool.Name))
+		})
+		It("should explain when no instance type can fit a pod", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			schedulingErr := solve(test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10000")}},
+			}))
+			Expect(schedulingErr.NodePools).To(HaveLen(1))
+			Expect(schedulingErr.NodePools[0].Reason).To(Equal(scheduling.FailureReasonInstanceTypes))
+		})
+		It("should explain when none of a nodepool's instance types have capacity", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "unavailable-instance-type",
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: false},
+						{CapacityType: v1beta1.CapacityTypeSpot, Zone: "test-zone-1", Price: 1, Available: false},
+					},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			schedulingErr := solve(test.UnschedulablePod())
+			Expect(schedulingErr.NodePools).To(HaveLen(1))
+			Expect(schedulingErr.NodePools[0].Reason).To(Equal(scheduling.FailureReasonCapacity))
+			Expect(schedulingErr.Reason()).To(Equal("IncompatibleCapacity"))
+		})
+		It("should explain when a nodepool's limits have been reached", func() {
+			nodePool.Spec.Limits = v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")})
+			ExpectApplied(ctx, env.Client, nodePool)
+			schedulingErr := solve(test.UnschedulablePod())
+			Expect(schedulingErr.NodePools).To(HaveLen(1))
+			Expect(schedulingErr.NodePools[0].Reason).To(Equal(scheduling.FailureReasonLimits))
+		})
+		It("should explain each nodepool when they fail for different reasons", func() {
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			other := test.NodePool()
+			other.Spec.Limits = v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")})
+			ExpectApplied(ctx, env.Client, nodePool, other)
+			schedulingErr := solve(test.UnschedulablePod())
+			Expect(lo.Map(schedulingErr.NodePools, func(f scheduling.NodePoolFailure, _ int) scheduling.FailureReason { return f.Reason })).To(ConsistOf(
+				scheduling.FailureReasonTaints,
+				scheduling.FailureReasonLimits,
+			))
+			Expect(schedulingErr.Reason()).To(Equal("IncompatibleNodePools"))
+		})
+	})
 	Describe("Metrics", func() {
 		It("should surface the queueDepth metric while executing the scheduling loop", func() {
 			nodePool := test.NodePool()
@@ -23,6 +23,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/utils/functional"
@@ -135,11 +137,11 @@ func (t *Topology) Update(ctx context.Context, p *v1.Pod) error {
 	return nil
 }
 
-// Record records the topology changes given that pod p schedule on a node with the given requirements
-func (t *Topology) Record(p *v1.Pod, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) {
+// Record records the topology changes given that pod p schedule on a node with the given taints and requirements
+func (t *Topology) Record(p *v1.Pod, taints []v1.Taint, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) {
 	// once we've committed to a domain, we record the usage in every topology that cares about it
 	for _, tc := range t.topologies {
-		if tc.Counts(p, requirements, compatabilityOptions...) {
+		if tc.Counts(p, taints, requirements, compatabilityOptions...) {
 			domains := requirements.Get(tc.Key)
 			if tc.Type == TopologyTypePodAntiAffinity {
 				// for anti-affinity topologies we need to block out all possible domains that the pod could land in
@@ -233,7 +235,7 @@ func (t *Topology) updateInverseAntiAffinity(ctx context.Context, pod *v1.Pod, d
 			return err
 		}
 
-		tg := NewTopologyGroup(TopologyTypePodAntiAffinity, term.TopologyKey, pod, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey])
+		tg := NewTopologyGroup(TopologyTypePodAntiAffinity, term.TopologyKey, TopologyNodeFilter{}, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey])
 
 		hash := tg.Hash()
 		if existing, ok := t.inverseTopologies[hash]; !ok {
@@ -311,11 +313,37 @@ func (t *Topology) countDomains(ctx context.Context, tg *TopologyGroup) error {
 func (t *Topology) newForTopologies(p *v1.Pod) []*TopologyGroup {
 	var topologyGroups []*TopologyGroup
 	for _, cs := range p.Spec.TopologySpreadConstraints {
-		topologyGroups = append(topologyGroups, NewTopologyGroup(TopologyTypeSpread, cs.TopologyKey, p, sets.New(p.Namespace), cs.LabelSelector, cs.MaxSkew, cs.MinDomains, t.domains[cs.TopologyKey]))
+		// minDomains only applies to constraints that can't be violated, matching the kube-scheduler
+		var minDomains *int32
+		if cs.WhenUnsatisfiable == v1.DoNotSchedule {
+			minDomains = cs.MinDomains
+		}
+		topologyGroups = append(topologyGroups, NewTopologyGroup(TopologyTypeSpread, cs.TopologyKey, MakeTopologyNodeFilter(p, cs), sets.New(p.Namespace), spreadLabelSelector(p, cs), cs.MaxSkew, minDomains, t.domains[cs.TopologyKey]))
 	}
 	return topologyGroups
 }
 
+// spreadLabelSelector returns the constraint's label selector, narrowed to the pods that share the pod's values for
+// the constraint's matchLabelKeys. This lets rollouts spread the pods of each revision (e.g. by pod-template-hash)
+// independently instead of counting the pods of old revisions. Like the kube-scheduler, keys that the pod doesn't
+// have are ignored.
+func spreadLabelSelector(p *v1.Pod, cs v1.TopologySpreadConstraint) *metav1.LabelSelector {
+	if cs.LabelSelector == nil || len(cs.MatchLabelKeys) == 0 {
+		return cs.LabelSelector
+	}
+	selector := cs.LabelSelector.DeepCopy()
+	for _, key := range cs.MatchLabelKeys {
+		if value, ok := p.Labels[key]; ok {
+			selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+				Key:      key,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{value},
+			})
+		}
+	}
+	return selector
+}
+
 // newForAffinities returns a list of topology groups that have been constructed based on the input pod and required/preferred affinity terms
 func (t *Topology) newForAffinities(ctx context.Context, p *v1.Pod) ([]*TopologyGroup, error) {
 	var topologyGroups []*TopologyGroup
@@ -348,7 +376,7 @@ func (t *Topology) newForAffinities(ctx context.Context, p *v1.Pod) ([]*Topology
 			if err != nil {
 				return nil, err
 			}
-			topologyGroups = append(topologyGroups, NewTopologyGroup(topologyType, term.TopologyKey, p, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey]))
+			topologyGroups = append(topologyGroups, NewTopologyGroup(topologyType, term.TopologyKey, TopologyNodeFilter{}, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey]))
 		}
 	}
 	return topologyGroups, nil
@@ -389,7 +417,8 @@ func (t *Topology) getMatchingTopologies(p *v1.Pod, requirements scheduling.Requ
 		}
 	}
 	for _, tc := range t.inverseTopologies {
-		if tc.Counts(p, requirements, compatabilityOptions...) {
+		// inverse anti-affinities count across all nodes, so the node's taints don't matter
+		if tc.Counts(p, nil, requirements, compatabilityOptions...) {
 			matchingTopologies = append(matchingTopologies, tc)
 		}
 	}
@@ -437,3 +466,57 @@ func mapOperator(operator metav1.LabelSelectorOperator) selection.Operator {
 func IgnoredForTopology(p *v1.Pod) bool {
 	return !pod.IsScheduled(p) || pod.IsTerminal(p) || pod.IsTerminating(p)
 }
+
+// TopologyDomains returns the topology domains that a nodepool's instance types contribute
+func TopologyDomains(nodePool *v1beta1.NodePool, instanceTypes []*cloudprovider.InstanceType) map[string]sets.Set[string] {
+	domains := map[string]sets.Set[string]{}
+	for _, instanceType := range instanceTypes {
+		// We need to intersect the instance type requirements with the current nodePool requirements.  This
+		// ensures that something like zones from an instance type don't expand the universe of valid domains.
+		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
+		requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
+		requirements.Add(instanceType.Requirements.Values()...)
+
+		for key, requirement := range requirements {
+			// This code used to execute a Union between domains[key] and requirement.Values().
+			// The downside of this is that Union is immutable and takes a copy of the set it is executed upon.
+			// This resulted in a lot of memory pressure on the heap and poor performance
+			// https://github.com/aws/karpenter/issues/3565
+			if domains[key] == nil {
+				domains[key] = sets.New(requirement.Values()...)
+			} else {
+				domains[key].Insert(requirement.Values()...)
+			}
+		}
+		// The domains of the nodepool's additional topology keys are the ones that the instance type is offered in
+		for _, offering := range instanceType.Offerings.Available() {
+			if !offering.Compatible(requirements) {
+				continue
+			}
+			for _, key := range nodePool.Spec.TopologyKeys {
+				domain, ok := offering.Domains[key]
+				if !ok {
+					continue
+				}
+				if domains[key] == nil {
+					domains[key] = sets.New[string]()
+				}
+				domains[key].Insert(domain)
+			}
+		}
+	}
+
+	requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
+	requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
+	for key, requirement := range requirements {
+		if requirement.Operator() == v1.NodeSelectorOpIn {
+			// The following is a performance optimisation, for the explanation see the comment above
+			if domains[key] == nil {
+				domains[key] = sets.New(requirement.Values()...)
+			} else {
+				domains[key].Insert(requirement.Values()...)
+			}
+		}
+	}
+	return domains
+}
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +31,9 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -526,8 +529,109 @@ var _ = Describe("Topology", func() {
 			)
 			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(4, 4, 3))
 		})
+		It("should only schedule up to maxSkew pods per domain when there are fewer domains than minDomains", func() {
+			if env.Version.Minor() < 24 {
+				Skip("MinDomains TopologySpreadConstraint is only available starting in K8s >= 1.24.x")
+			}
+			var minDomains int32 = 5
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2", "test-zone-3"}}}}
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       v1.LabelTopologyZone,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+				MinDomains:        &minDomains,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 5)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(1, 1, 1))
+		})
+		It("should track topologies that only differ by minDomains separately", func() {
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}})
+			domains := sets.New("test-zone-1", "test-zone-2", "test-zone-3")
+			selector := &metav1.LabelSelector{MatchLabels: labels}
+			withoutMinDomains := scheduling.NewTopologyGroup(scheduling.TopologyTypeSpread, v1.LabelTopologyZone, scheduling.MakeTopologyNodeFilter(pod, v1.TopologySpreadConstraint{}), sets.New(pod.Namespace), selector, 1, nil, domains)
+			withMinDomains := scheduling.NewTopologyGroup(scheduling.TopologyTypeSpread, v1.LabelTopologyZone, scheduling.MakeTopologyNodeFilter(pod, v1.TopologySpreadConstraint{}), sets.New(pod.Namespace), selector, 1, lo.ToPtr[int32](3), domains)
+			Expect(withMinDomains.Hash()).ToNot(Equal(withoutMinDomains.Hash()))
+		})
 	})
 
+	Context("MatchLabelKeys and Node Inclusion Policies", func() {
+		It("should only count the pods that share the pod's values for the matchLabelKeys", func() {
+			node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelTopologyZone: "test-zone-1"}}})
+			oldRevision := lo.Assign(labels, map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "old"})
+			newRevision := lo.Assign(labels, map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "new"})
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       v1.LabelTopologyZone,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+				MatchLabelKeys:    []string{appsv1.DefaultDeploymentUniqueLabelKey},
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			pods := []*v1.Pod{
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: oldRevision}, NodeName: node.Name}),
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: oldRevision}, NodeName: node.Name}),
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: oldRevision}, NodeName: node.Name}),
+			}
+			pods = append(pods, test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: newRevision}, TopologySpreadConstraints: topology}, 3)...)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+
+			// The pods of the old revision in test-zone-1 aren't counted, so the new revision spreads across all zones
+			ExpectSkew(ctx, env.Client, "default", &v1.TopologySpreadConstraint{
+				TopologyKey:   v1.LabelTopologyZone,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: newRevision},
+			}).To(ConsistOf(1, 1, 1))
+		})
+		It("should count the domains that the pod's node affinity excludes when the nodeAffinityPolicy is Ignore", func() {
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:        v1.LabelTopologyZone,
+				WhenUnsatisfiable:  v1.DoNotSchedule,
+				LabelSelector:      &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:            1,
+				NodeAffinityPolicy: lo.ToPtr(v1.NodeInclusionPolicyIgnore),
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{
+					ObjectMeta:                metav1.ObjectMeta{Labels: labels},
+					TopologySpreadConstraints: topology,
+					NodeSelector:              map[string]string{v1.LabelTopologyZone: "test-zone-1"},
+				}, 5)...,
+			)
+			// test-zone-2 and test-zone-3 are empty, so only one pod can schedule to test-zone-1 within the max skew
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(1))
+		})
+		It("should not count the pods on nodes with untolerated taints when the nodeTaintsPolicy is Honor", func() {
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelTopologyZone: "test-zone-1"}},
+				Taints:     []v1.Taint{{Key: "example.com/dedicated", Value: "true", Effect: v1.TaintEffectNoSchedule}},
+			})
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       v1.LabelTopologyZone,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+				NodeTaintsPolicy:  lo.ToPtr(v1.NodeInclusionPolicyHonor),
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			pods := []*v1.Pod{
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, NodeName: node.Name}),
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, NodeName: node.Name}),
+				test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, NodeName: node.Name}),
+			}
+			pods = append(pods, test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 3)...)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+
+			// The pods on the tainted node aren't counted, so the new pods spread evenly across all zones
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(4, 1, 1))
+		})
+	})
 	Context("Hostname", func() {
 		It("should balance pods across nodes", func() {
 			topology := []v1.TopologySpreadConstraint{{
@@ -924,6 +1028,82 @@ var _ = Describe("Topology", func() {
 		})
 	})
 
+	Context("NodePool Topology Keys", func() {
+		const rackLabelKey = "example.com/rack"
+		BeforeEach(func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "rack-instance-type",
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: true, Domains: map[string]string{rackLabelKey: "rack-1"}},
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1, Available: true, Domains: map[string]string{rackLabelKey: "rack-2"}},
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-2", Price: 1, Available: true, Domains: map[string]string{rackLabelKey: "rack-3"}},
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-2", Price: 1, Available: false, Domains: map[string]string{rackLabelKey: "rack-4"}},
+					},
+				}),
+			}
+		})
+		It("should spread pods across the domains of a nodepool's topology keys", func() {
+			nodePool.Spec.TopologyKeys = []string{rackLabelKey}
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       rackLabelKey,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 4)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(1, 1, 2))
+		})
+		It("should only use the domains of offerings that are compatible with the nodepool", func() {
+			nodePool.Spec.TopologyKeys = []string{rackLabelKey}
+			nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements, v1beta1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}},
+			})
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       rackLabelKey,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 4)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(2, 2))
+		})
+		It("should not use offering domains for topology keys that the nodepool doesn't declare", func() {
+			topology := []v1.TopologySpreadConstraint{{
+				TopologyKey:       rackLabelKey,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology})
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule pods that select a domain of a topology key", func() {
+			nodePool.Spec.TopologyKeys = []string{rackLabelKey}
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{rackLabelKey: "rack-3"}})
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(rackLabelKey, "rack-3"))
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
+		})
+		It("should not schedule pods that select a domain that isn't available", func() {
+			nodePool.Spec.TopologyKeys = []string{rackLabelKey}
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{rackLabelKey: "rack-4"}})
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+	})
+
 	Context("Combined Hostname and Zonal Topology", func() {
 		It("should spread pods while respecting both constraints (hostname and zonal)", func() {
 			topology := []v1.TopologySpreadConstraint{{
@@ -1875,6 +2055,45 @@ var _ = Describe("Topology", func() {
 			// the anti-affinity was a preference, so this can schedule
 			ExpectScheduled(ctx, env.Client, affPod)
 		})
+		It("should relax lower weighted preferred pod anti-affinity terms first", func() {
+			affLabels := map[string]string{"security": "s2"}
+			affPod := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: affLabels},
+				ResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+				NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-1"}})
+			// the zonal term can never be satisfied since both pods are constrained to the same zone, but the heavier
+			// hostname term can be satisfied by launching a separate node
+			antiPod := test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+				PodAntiPreferences: []v1.WeightedPodAffinityTerm{
+					{
+						Weight: 100,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: affLabels},
+							TopologyKey:   v1.LabelHostname,
+						},
+					},
+					{
+						Weight: 1,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: affLabels},
+							TopologyKey:   v1.LabelTopologyZone,
+						},
+					},
+				},
+				NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-1"}})
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, affPod, antiPod)
+			n1 := ExpectScheduled(ctx, env.Client, affPod)
+			n2 := ExpectScheduled(ctx, env.Client, antiPod)
+			Expect(n1.Labels[v1.LabelTopologyZone]).To(Equal(n2.Labels[v1.LabelTopologyZone]))
+			Expect(n1.Name).ToNot(Equal(n2.Name))
+		})
 		It("should not violate pod anti-affinity on zone (inverse)", func() {
 			affLabels := map[string]string{"security": "s2"}
 			anti := []v1.PodAffinityTerm{{
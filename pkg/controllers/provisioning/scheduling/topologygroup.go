@@ -68,22 +68,19 @@ type TopologyGroup struct {
 	emptyDomains sets.Set[string]       // domains for which we know that no pod exists
 }
 
-func NewTopologyGroup(topologyType TopologyType, topologyKey string, pod *v1.Pod, namespaces sets.Set[string], labelSelector *metav1.LabelSelector, maxSkew int32, minDomains *int32, domains sets.Set[string]) *TopologyGroup {
+// NewTopologyGroup constructs a topology group. The zero-value TopologyNodeFilter always passes which is what we need for
+// affinity/anti-affinity.
+func NewTopologyGroup(topologyType TopologyType, topologyKey string, nodeFilter TopologyNodeFilter, namespaces sets.Set[string], labelSelector *metav1.LabelSelector, maxSkew int32, minDomains *int32, domains sets.Set[string]) *TopologyGroup {
 	domainCounts := map[string]int32{}
 	for domain := range domains {
 		domainCounts[domain] = 0
 	}
-	// the nil *TopologyNodeFilter always passes which is what we need for affinity/anti-affinity
-	var nodeSelector TopologyNodeFilter
-	if topologyType == TopologyTypeSpread {
-		nodeSelector = MakeTopologyNodeFilter(pod)
-	}
 	return &TopologyGroup{
 		Type:         topologyType,
 		Key:          topologyKey,
 		namespaces:   namespaces,
 		selector:     labelSelector,
-		nodeFilter:   nodeSelector,
+		nodeFilter:   nodeFilter,
 		maxSkew:      maxSkew,
 		domains:      domainCounts,
 		emptyDomains: domains.Clone(),
@@ -113,9 +110,9 @@ func (t *TopologyGroup) Record(domains ...string) {
 }
 
 // Counts returns true if the pod would count for the topology, given that it schedule to a node with the provided
-// requirements
-func (t *TopologyGroup) Counts(pod *v1.Pod, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) bool {
-	return t.selects(pod) && t.nodeFilter.MatchesRequirements(requirements, compatabilityOptions...)
+// taints and requirements
+func (t *TopologyGroup) Counts(pod *v1.Pod, taints []v1.Taint, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) bool {
+	return t.selects(pod) && t.nodeFilter.MatchesRequirements(taints, requirements, compatabilityOptions...)
 }
 
 // Register ensures that the topology is aware of the given domain names.
@@ -150,6 +147,7 @@ func (t *TopologyGroup) Hash() uint64 {
 		Namespaces    sets.Set[string]
 		LabelSelector *metav1.LabelSelector
 		MaxSkew       int32
+		MinDomains    *int32
 		NodeFilter    TopologyNodeFilter
 	}{
 		TopologyKey:   t.Key,
@@ -157,6 +155,7 @@ func (t *TopologyGroup) Hash() uint64 {
 		Namespaces:    t.namespaces,
 		LabelSelector: t.selector,
 		MaxSkew:       t.maxSkew,
+		MinDomains:    t.minDomains,
 		NodeFilter:    t.nodeFilter,
 	}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true}))
 }
@@ -165,8 +164,13 @@ func (t *TopologyGroup) Hash() uint64 {
 // If there are multiple eligible domains, we return any random domain that satisfies the `maxSkew` configuration.
 // If there are no eligible domains, we return a `DoesNotExist` requirement, implying that we could not satisfy the topologySpread requirement.
 func (t *TopologyGroup) nextDomainTopologySpread(pod *v1.Pod, podDomains, nodeDomains *scheduling.Requirement) *scheduling.Requirement {
-	// min count is calculated across all domains
-	min := t.domainMinCount(podDomains)
+	// min count is calculated across all domains, which are limited to the pod's domains unless the constraint
+	// ignores the pod's node affinity
+	minDomains := podDomains
+	if t.nodeFilter.AffinityPolicy == v1.NodeInclusionPolicyIgnore {
+		minDomains = scheduling.NewRequirement(podDomains.Key, v1.NodeSelectorOpExists)
+	}
+	min := t.domainMinCount(minDomains)
 	selfSelecting := t.selects(pod)
 
 	minDomain := ""
@@ -210,6 +214,8 @@ func (t *TopologyGroup) domainMinCount(domains *scheduling.Requirement) int32 {
 			}
 		}
 	}
+	// If there are fewer eligible domains than minDomains, the global minimum is treated as zero. This forces us to
+	// spread onto new domains rather than continuing to pack into the ones we already have.
 	if t.minDomains != nil && numPodSupportedDomains < *t.minDomains {
 		min = 0
 	}
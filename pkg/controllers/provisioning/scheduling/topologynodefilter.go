@@ -17,6 +17,7 @@ limitations under the License.
 package scheduling
 
 import (
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -24,27 +25,47 @@ import (
 )
 
 // TopologyNodeFilter is used to determine if a given actual node or scheduling node matches the pod's node selectors
-// and required node affinity terms.  This is used with topology spread constraints to determine if the node should be
-// included for topology counting purposes. This is only used with topology spread constraints as affinities/anti-affinities
-// always count across all nodes. A nil or zero-value TopologyNodeFilter behaves well and the filter returns true for
-// all nodes.
-type TopologyNodeFilter []scheduling.Requirements
+// and required node affinity terms, and optionally tolerates the node's taints.  This is used with topology spread
+// constraints to determine if the node should be included for topology counting purposes. This is only used with
+// topology spread constraints as affinities/anti-affinities always count across all nodes. A zero-value
+// TopologyNodeFilter behaves well and the filter returns true for all nodes.
+type TopologyNodeFilter struct {
+	// Requirements are the node selector combined with each of the required node affinity terms. These are OR'd, so
+	// a node matches if it's compatible with any of them.
+	Requirements []scheduling.Requirements
+	// AffinityPolicy is the constraint's nodeAffinityPolicy. Nodes always match when it's Ignore.
+	AffinityPolicy v1.NodeInclusionPolicy
+	// TaintPolicy is the constraint's nodeTaintsPolicy. The node's taints are only checked when it's Honor.
+	TaintPolicy v1.NodeInclusionPolicy
+	Tolerations []v1.Toleration
+}
 
-func MakeTopologyNodeFilter(p *v1.Pod) TopologyNodeFilter {
+func MakeTopologyNodeFilter(p *v1.Pod, constraint v1.TopologySpreadConstraint) TopologyNodeFilter {
+	// The kube-scheduler honors node affinity and ignores taints by default
+	filter := TopologyNodeFilter{
+		AffinityPolicy: lo.FromPtrOr(constraint.NodeAffinityPolicy, v1.NodeInclusionPolicyHonor),
+		TaintPolicy:    lo.FromPtrOr(constraint.NodeTaintsPolicy, v1.NodeInclusionPolicyIgnore),
+	}
+	if filter.TaintPolicy == v1.NodeInclusionPolicyHonor {
+		filter.Tolerations = p.Spec.Tolerations
+	}
+	if filter.AffinityPolicy == v1.NodeInclusionPolicyIgnore {
+		return filter
+	}
 	nodeSelectorRequirements := scheduling.NewLabelRequirements(p.Spec.NodeSelector)
 	// if we only have a label selector, that's the only requirement that must match
 	if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil || p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
-		return TopologyNodeFilter{nodeSelectorRequirements}
+		filter.Requirements = []scheduling.Requirements{nodeSelectorRequirements}
+		return filter
 	}
 
 	// otherwise, we need to match the combination of label selector and any term of the required node affinities since
 	// those terms are OR'd together
-	var filter TopologyNodeFilter
 	for _, term := range p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
 		requirements := scheduling.NewRequirements()
 		requirements.Add(nodeSelectorRequirements.Values()...)
 		requirements.Add(scheduling.NewNodeSelectorRequirements(term.MatchExpressions...).Values()...)
-		filter = append(filter, requirements)
+		filter.Requirements = append(filter.Requirements, requirements)
 	}
 
 	return filter
@@ -52,22 +73,43 @@ func MakeTopologyNodeFilter(p *v1.Pod) TopologyNodeFilter {
 
 // Matches returns true if the TopologyNodeFilter doesn't prohibit node from the participating in the topology
 func (t TopologyNodeFilter) Matches(node *v1.Node) bool {
-	return t.MatchesRequirements(scheduling.NewLabelRequirements(node.Labels))
+	return t.MatchesRequirements(node.Spec.Taints, scheduling.NewLabelRequirements(node.Labels))
 }
 
-// MatchesRequirements returns true if the TopologyNodeFilter doesn't prohibit a node with the requirements from
-// participating in the topology. This method allows checking the requirements from a scheduling.NodeClaim to see if the
-// node we will soon create participates in this topology.
-func (t TopologyNodeFilter) MatchesRequirements(requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) bool {
+// MatchesRequirements returns true if the TopologyNodeFilter doesn't prohibit a node with the taints and requirements
+// from participating in the topology. This method allows checking the requirements from a scheduling.NodeClaim to see
+// if the node we will soon create participates in this topology.
+func (t TopologyNodeFilter) MatchesRequirements(taints []v1.Taint, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) bool {
+	return t.toleratesTaints(taints) && t.matchesRequirements(requirements, compatabilityOptions...)
+}
+
+func (t TopologyNodeFilter) matchesRequirements(requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatibilityOptions]) bool {
 	// no requirements, so it always matches
-	if len(t) == 0 {
+	if len(t.Requirements) == 0 {
 		return true
 	}
 	// these are an OR, so if any passes the filter passes
-	for _, req := range t {
+	for _, req := range t.Requirements {
 		if err := requirements.Compatible(req, compatabilityOptions...); err == nil {
 			return true
 		}
 	}
 	return false
 }
+
+// toleratesTaints returns true if the taints are ignored, or if the tolerations tolerate all the taints that prevent
+// scheduling. Like the kube-scheduler, PreferNoSchedule taints never exclude a node.
+func (t TopologyNodeFilter) toleratesTaints(taints []v1.Taint) bool {
+	if t.TaintPolicy != v1.NodeInclusionPolicyHonor {
+		return true
+	}
+	for i := range taints {
+		if taints[i].Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !lo.ContainsBy(t.Tolerations, func(toleration v1.Toleration) bool { return toleration.ToleratesTaint(&taints[i]) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -39,17 +39,32 @@ type VolumeTopology struct {
 }
 
 func (v *VolumeTopology) Inject(ctx context.Context, pod *v1.Pod) error {
-	var requirements []v1.NodeSelectorRequirement
+	// Each volume's requirements are a set of ORed terms. Volumes that share a storage class or topology have the same
+	// terms, so they're only added once.
+	var volumeTerms [][][]v1.NodeSelectorRequirement
 	for _, volume := range pod.Spec.Volumes {
-		req, err := v.getRequirements(ctx, pod, volume)
+		terms, err := v.getRequirements(ctx, pod, volume)
 		if err != nil {
 			return err
 		}
-		requirements = append(requirements, req...)
+		if len(terms) > 0 {
+			volumeTerms = append(volumeTerms, terms)
+		}
 	}
-	if len(requirements) == 0 {
+	volumeTerms = lo.UniqBy(volumeTerms, func(terms [][]v1.NodeSelectorRequirement) string { return fmt.Sprint(terms) })
+	if len(volumeTerms) == 0 {
 		return nil
 	}
+	requireTerms(pod, volumeTerms)
+
+	logging.FromContext(ctx).
+		With("pod", client.ObjectKeyFromObject(pod)).
+		Debugf("adding requirements derived from pod volumes, %s", volumeTerms)
+	return nil
+}
+
+// requireTerms adds sets of ORed terms of requirements to the pod's required node affinity
+func requireTerms(pod *v1.Pod, termSets [][][]v1.NodeSelectorRequirement) {
 	if pod.Spec.Affinity == nil {
 		pod.Spec.Affinity = &v1.Affinity{}
 	}
@@ -63,20 +78,24 @@ func (v *VolumeTopology) Inject(ctx context.Context, pod *v1.Pod) error {
 		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = []v1.NodeSelectorTerm{{}}
 	}
 
-	// We add our volume topology zonal requirement to every node selector term.  This causes it to be AND'd with every existing
-	// requirement so that relaxation won't remove our volume requirement.
-	for i := 0; i < len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms); i++ {
-		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i].MatchExpressions = append(
-			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i].MatchExpressions, requirements...)
+	// We AND the requirements with every node selector term so that relaxation won't remove them. Since both the node
+	// selector terms and each set of terms are ORed, every node selector term is expanded into one term for each of the
+	// set's terms.
+	for _, terms := range termSets {
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = lo.FlatMap(
+			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, func(term v1.NodeSelectorTerm, _ int) []v1.NodeSelectorTerm {
+				return lo.Map(terms, func(requirements []v1.NodeSelectorRequirement, _ int) v1.NodeSelectorTerm {
+					return v1.NodeSelectorTerm{
+						MatchExpressions: append(append([]v1.NodeSelectorRequirement{}, term.MatchExpressions...), requirements...),
+						MatchFields:      term.MatchFields,
+					}
+				})
+			})
 	}
-
-	logging.FromContext(ctx).
-		With("pod", client.ObjectKeyFromObject(pod)).
-		Debugf("adding requirements derived from pod volumes, %s", requirements)
-	return nil
 }
 
-func (v *VolumeTopology) getRequirements(ctx context.Context, pod *v1.Pod, volume v1.Volume) ([]v1.NodeSelectorRequirement, error) {
+// getRequirements returns the ORed terms of requirements that a node must meet for the volume to be attached to it
+func (v *VolumeTopology) getRequirements(ctx context.Context, pod *v1.Pod, volume v1.Volume) ([][]v1.NodeSelectorRequirement, error) {
 	pvc, err := volumeutil.GetPersistentVolumeClaim(ctx, v.kubeClient, pod, volume)
 	if err != nil {
 		return nil, fmt.Errorf("discovering persistent volume claim, %w", err)
@@ -105,22 +124,24 @@ func (v *VolumeTopology) getRequirements(ctx context.Context, pod *v1.Pod, volum
 	return nil, nil
 }
 
-func (v *VolumeTopology) getStorageClassRequirements(ctx context.Context, storageClassName string) ([]v1.NodeSelectorRequirement, error) {
+func (v *VolumeTopology) getStorageClassRequirements(ctx context.Context, storageClassName string) ([][]v1.NodeSelectorRequirement, error) {
 	storageClass := &storagev1.StorageClass{}
 	if err := v.kubeClient.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass); err != nil {
 		return nil, fmt.Errorf("getting storage class %q, %w", storageClassName, err)
 	}
-	var requirements []v1.NodeSelectorRequirement
-	if len(storageClass.AllowedTopologies) > 0 {
-		// Terms are ORed, only use the first term
-		for _, requirement := range storageClass.AllowedTopologies[0].MatchLabelExpressions {
-			requirements = append(requirements, v1.NodeSelectorRequirement{Key: requirement.Key, Operator: v1.NodeSelectorOpIn, Values: requirement.Values})
-		}
+	// Terms are ORed, and the label expressions within a term are ANDed. A term without any expressions allows every
+	// node, so the storage class doesn't constrain the volume at all.
+	if lo.SomeBy(storageClass.AllowedTopologies, func(term v1.TopologySelectorTerm) bool { return len(term.MatchLabelExpressions) == 0 }) {
+		return nil, nil
 	}
-	return requirements, nil
+	return lo.Map(storageClass.AllowedTopologies, func(term v1.TopologySelectorTerm, _ int) []v1.NodeSelectorRequirement {
+		return lo.Map(term.MatchLabelExpressions, func(requirement v1.TopologySelectorLabelRequirement, _ int) v1.NodeSelectorRequirement {
+			return v1.NodeSelectorRequirement{Key: requirement.Key, Operator: v1.NodeSelectorOpIn, Values: requirement.Values}
+		})
+	}), nil
 }
 
-func (v *VolumeTopology) getPersistentVolumeRequirements(ctx context.Context, pod *v1.Pod, volumeName string) ([]v1.NodeSelectorRequirement, error) {
+func (v *VolumeTopology) getPersistentVolumeRequirements(ctx context.Context, pod *v1.Pod, volumeName string) ([][]v1.NodeSelectorRequirement, error) {
 	pv := &v1.PersistentVolume{}
 	if err := v.kubeClient.Get(ctx, types.NamespacedName{Name: volumeName, Namespace: pod.Namespace}, pv); err != nil {
 		return nil, fmt.Errorf("getting persistent volume %q, %w", volumeName, err)
@@ -131,12 +152,14 @@ func (v *VolumeTopology) getPersistentVolumeRequirements(ctx context.Context, po
 	if pv.Spec.NodeAffinity.Required == nil {
 		return nil, nil
 	}
-	var requirements []v1.NodeSelectorRequirement
-	if len(pv.Spec.NodeAffinity.Required.NodeSelectorTerms) > 0 {
-		// Terms are ORed, only use the first term
-		requirements = append(requirements, pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions...)
+	// Terms are ORed, and the expressions within a term are ANDed. A term without any expressions doesn't constrain
+	// the labels of the node, so the volume isn't constrained either.
+	if lo.SomeBy(pv.Spec.NodeAffinity.Required.NodeSelectorTerms, func(term v1.NodeSelectorTerm) bool { return len(term.MatchExpressions) == 0 }) {
+		return nil, nil
 	}
-	return requirements, nil
+	return lo.Map(pv.Spec.NodeAffinity.Required.NodeSelectorTerms, func(term v1.NodeSelectorTerm, _ int) []v1.NodeSelectorRequirement {
+		return term.MatchExpressions
+	}), nil
 }
 
 // ValidatePersistentVolumeClaims returns an error if the pod doesn't appear to be valid with respect to
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// SimulationResponse is the result of a scheduling simulation served by the SimulationHandler
+type SimulationResponse struct {
+	NodeClaims    []SimulatedNodeClaim `json:"nodeClaims"`
+	ExistingNodes map[string][]string  `json:"existingNodes,omitempty"`
+	PodErrors     map[string]string    `json:"podErrors,omitempty"`
+}
+
+// SimulatedNodeClaim describes a NodeClaim that would be launched, along with the pods that would schedule to it
+type SimulatedNodeClaim struct {
+	NodePool            string          `json:"nodePool"`
+	InstanceTypeOptions []string        `json:"instanceTypeOptions"`
+	Requests            v1.ResourceList `json:"requests"`
+	Pods                []string        `json:"pods"`
+}
+
+// SimulationHandler serves scheduling simulations over HTTP. It accepts a POST with a PodList and responds with
+// the NodeClaims that the provisioner would launch for those pods, without launching them.
+type SimulationHandler struct {
+	provisioner *Provisioner
+}
+
+func NewSimulationHandler(p *Provisioner) *SimulationHandler {
+	return &SimulationHandler{provisioner: p}
+}
+
+func (h *SimulationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	podList := &v1.PodList{}
+	if err := json.NewDecoder(r.Body).Decode(podList); err != nil {
+		http.Error(w, fmt.Sprintf("decoding pod list, %s", err), http.StatusBadRequest)
+		return
+	}
+	results, err := h.provisioner.SimulateScheduling(r.Context(), lo.ToSlicePtr(podList.Items))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("simulating scheduling, %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(NewSimulationResponse(results)); err != nil {
+		http.Error(w, fmt.Sprintf("encoding response, %s", err), http.StatusInternalServerError)
+	}
+}
+
+// NewSimulationResponse converts scheduling results into their serializable form
+func NewSimulationResponse(results scheduler.Results) SimulationResponse {
+	podNames := func(pods []*v1.Pod) []string {
+		return lo.Map(pods, func(p *v1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() })
+	}
+	response := SimulationResponse{
+		NodeClaims: lo.Map(results.NewNodeClaims, func(n *scheduler.NodeClaim, _ int) SimulatedNodeClaim {
+			return SimulatedNodeClaim{
+				NodePool:            n.NodePoolName,
+				InstanceTypeOptions: lo.Map(n.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) string { return it.Name }),
+				Requests:            resources.RequestsForPods(n.Pods...),
+				Pods:                podNames(n.Pods),
+			}
+		}),
+		ExistingNodes: map[string][]string{},
+		PodErrors:     map[string]string{},
+	}
+	for _, n := range results.ExistingNodes {
+		if len(n.Pods) > 0 {
+			response.ExistingNodes[n.Name()] = podNames(n.Pods)
+		}
+	}
+	for p, err := range results.PodErrors {
+		response.PodErrors[client.ObjectKeyFromObject(p).String()] = err.Error()
+	}
+	return response
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package provisioning_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -26,6 +30,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	resourcev1alpha2 "k8s.io/api/resource/v1alpha2"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,9 +47,11 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/operator/scheme"
@@ -76,7 +84,7 @@ var _ = BeforeSuite(func() {
 	fakeClock = clock.NewFakeClock(time.Now())
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
 	nodeController = informer.NewNodeController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
 	daemonsetController = informer.NewDaemonSetController(env.Client, cluster)
 	instanceTypes, _ := cloudProvider.GetInstanceTypes(ctx, nil)
 	instanceTypeMap = map[string]*cloudprovider.InstanceType{}
@@ -98,6 +106,7 @@ var _ = AfterEach(func() {
 	ExpectCleanedUp(ctx, env.Client)
 	cloudProvider.Reset()
 	cluster.Reset()
+	fakeClock.SetTime(time.Now())
 })
 
 var _ = Describe("Provisioning", func() {
@@ -110,6 +119,22 @@ var _ = Describe("Provisioning", func() {
 		Expect(len(nodes.Items)).To(Equal(1))
 		ExpectScheduled(ctx, env.Client, pod)
 	})
+	It("should annotate pods with the inflight node that they're nominated to", func() {
+		ExpectApplied(ctx, env.Client, test.NodePool())
+		pod := test.UnschedulablePod()
+		ExpectProvisionedNoBinding(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		nodes := &v1.NodeList{}
+		Expect(env.Client.List(ctx, nodes)).To(Succeed())
+		Expect(nodes.Items).To(HaveLen(1))
+
+		// The pod isn't bound, so the next scheduling run nominates the inflight node for it
+		ExpectProvisionedNoBinding(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Annotations).To(HaveKeyWithValue(v1beta1.NominatedNodeAnnotationKey, nodes.Items[0].Spec.ProviderID))
+		until, err := time.Parse(time.RFC3339, pod.Annotations[v1beta1.NominatedUntilAnnotationKey])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(until).To(BeTemporally("~", cluster.Nominations()[nodes.Items[0].Spec.ProviderID], time.Second))
+	})
 	It("should ignore NodePools that are deleting", func() {
 		nodePool := test.NodePool()
 		ExpectApplied(ctx, env.Client, nodePool)
@@ -121,6 +146,21 @@ var _ = Describe("Provisioning", func() {
 		Expect(len(nodes.Items)).To(Equal(0))
 		ExpectNotScheduled(ctx, env.Client, pod)
 	})
+	It("should propagate the nodepool labels that the nodepool selector matches on to nodeclaims", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr("team=a")}))
+		nodePool := test.NodePool(v1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "a", "unrelated": "value"}},
+		})
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		ExpectScheduled(ctx, env.Client, pod)
+		nodeClaims := &v1beta1.NodeClaimList{}
+		Expect(env.Client.List(ctx, nodeClaims)).To(Succeed())
+		Expect(nodeClaims.Items).To(HaveLen(1))
+		Expect(nodeClaims.Items[0].Labels).To(HaveKeyWithValue("team", "a"))
+		Expect(nodeClaims.Items[0].Labels).ToNot(HaveKey("unrelated"))
+	})
 	It("should provision nodes for pods with supported node selectors", func() {
 		nodePool := test.NodePool()
 		schedulable := []*v1.Pod{
@@ -217,8 +257,7 @@ var _ = Describe("Provisioning", func() {
 		}
 	})
 	It("should provision multiple nodes when maxPods is set", func() {
-		// Kubelet is actually not observed here, the scheduler is relying on the
-		// pods resource value which is statically set in the fake cloudprovider
+		// The scheduler uses the kubelet's maxPods as the pods capacity of the instance types
 		ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
 			Spec: v1beta1.NodePoolSpec{
 				Template: v1beta1.NodeClaimTemplate{
@@ -248,6 +287,47 @@ var _ = Describe("Provisioning", func() {
 			ExpectScheduled(ctx, env.Client, pod)
 		}
 	})
+	It("should use the cloudprovider's max pods as the pods capacity of instance types", func() {
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:      "cni-limited-instance-type",
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("16"), v1.ResourceMemory: resource.MustParse("32Gi"), v1.ResourcePods: resource.MustParse("110")},
+			}),
+		}
+		cloudProvider.MaxPodsForInstanceType["cni-limited-instance-type"] = 2
+		ExpectApplied(ctx, env.Client, test.NodePool())
+		pods := []*v1.Pod{test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod()}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		nodes := &v1.NodeList{}
+		Expect(env.Client.List(ctx, nodes)).To(Succeed())
+		Expect(nodes.Items).To(HaveLen(2))
+		for _, pod := range pods {
+			ExpectScheduled(ctx, env.Client, pod)
+		}
+	})
+	It("should prefer the kubelet's max pods over the cloudprovider's max pods", func() {
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:      "cni-limited-instance-type",
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("16"), v1.ResourceMemory: resource.MustParse("32Gi"), v1.ResourcePods: resource.MustParse("110")},
+			}),
+		}
+		cloudProvider.MaxPodsForInstanceType["cni-limited-instance-type"] = 2
+		ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
+			Spec: v1beta1.NodePoolSpec{
+				Template: v1beta1.NodeClaimTemplate{
+					Spec: v1beta1.NodeClaimSpec{
+						Kubelet: &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(3)},
+					},
+				},
+			},
+		}))
+		pods := []*v1.Pod{test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod()}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		nodes := &v1.NodeList{}
+		Expect(env.Client.List(ctx, nodes)).To(Succeed())
+		Expect(nodes.Items).To(HaveLen(1))
+	})
 	It("should schedule all pods on one inflight node when node is in deleting state", func() {
 		nodePool := test.NodePool()
 		its, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
@@ -304,6 +384,126 @@ var _ = Describe("Provisioning", func() {
 			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
+		It("should overflow to the nodepool's overflow nodepool once its limits are exhausted", func() {
+			burst := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(10)}})
+			exhausted := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Weight:           ptr.Int32(100),
+					Limits:           v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+					OverflowNodePool: burst.Name,
+				},
+			})
+			next := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(50)}})
+			ExpectApplied(ctx, env.Client, exhausted, next, burst)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(burst.Name))
+		})
+		It("should fall back to the nodepools by weight if the overflow nodepool's limits are also exhausted", func() {
+			burst := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Weight: ptr.Int32(10),
+					Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+				},
+			})
+			exhausted := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Weight:           ptr.Int32(100),
+					Limits:           v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+					OverflowNodePool: burst.Name,
+				},
+			})
+			next := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(50)}})
+			ExpectApplied(ctx, env.Client, exhausted, next, burst)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(next.Name))
+		})
+		It("should record a stalled scale-up for a nodepool whose limits are exhausted", func() {
+			nodePool := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			_, stalled := cluster.ScaleUpStalledFor(nodePool.Name)
+			Expect(stalled).To(BeTrue())
+
+			// Once the pod is gone, the nodepool's scale-up is no longer stalled, even before another scheduling run
+			ExpectDeleted(ctx, env.Client, pod)
+			cluster.DeletePod(client.ObjectKeyFromObject(pod))
+			_, stalled = cluster.ScaleUpStalledFor(nodePool.Name)
+			Expect(stalled).To(BeFalse())
+		})
+		It("should not record a stalled scale-up for a nodepool whose limits were exhausted when pods overflow", func() {
+			burst := test.NodePool()
+			exhausted := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Limits:           v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+					OverflowNodePool: burst.Name,
+				},
+			})
+			ExpectApplied(ctx, env.Client, exhausted, burst)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			_, stalled := cluster.ScaleUpStalledFor(exhausted.Name)
+			Expect(stalled).To(BeFalse())
+		})
+		It("should only account for daemonset overhead on the daemonset's architecture", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:         "amd64-instance-type",
+					Architecture: v1beta1.ArchitectureAmd64,
+					Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:         "arm64-instance-type",
+					Architecture: v1beta1.ArchitectureArm64,
+					Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				}),
+			}
+			// The daemonset only runs on amd64, so it shouldn't take up room on arm64 instance types
+			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					NodeSelector:         map[string]string{v1.LabelArchStable: v1beta1.ArchitectureAmd64},
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+				}},
+			))
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector:         map[string]string{v1.LabelArchStable: v1beta1.ArchitectureArm64},
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[v1.LabelInstanceTypeStable]).To(Equal("arm64-instance-type"))
+		})
+		It("should account for daemonset overhead on the daemonset's architecture", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:         "amd64-instance-type",
+					Architecture: v1beta1.ArchitectureAmd64,
+					Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("4Gi")},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					NodeSelector:         map[string]string{v1.LabelArchStable: v1beta1.ArchitectureAmd64},
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+				}},
+			))
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector:         map[string]string{v1.LabelArchStable: v1beta1.ArchitectureAmd64},
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
 		It("should schedule if limits would be met", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
 				Spec: v1beta1.NodePoolSpec{
@@ -371,6 +571,56 @@ var _ = Describe("Provisioning", func() {
 			Expect(scheduledPodCount).To(Equal(1))
 			Expect(unscheduledPodCount).To(Equal(1))
 		})
+		Context("Reserved Limits", func() {
+			var priorityClass *schedulingv1.PriorityClass
+			BeforeEach(func() {
+				priorityClass = test.PriorityClass(1000)
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ReservedLimitsPercentage: lo.ToPtr(60)}))
+				cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+					fake.NewInstanceType(fake.InstanceTypeOptions{
+						Name:      "default-instance-type",
+						Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("6"), v1.ResourceMemory: resource.MustParse("6Gi")},
+					}),
+				}
+				ExpectApplied(ctx, env.Client, priorityClass, test.NodePool(v1beta1.NodePool{
+					Spec: v1beta1.NodePoolSpec{
+						Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("14")}),
+					},
+				}))
+			})
+			It("should not schedule pods without a positive priority once remaining limits are reserved", func() {
+				prioritized := test.UnschedulablePod(test.PodOptions{
+					PriorityClassName:    priorityClass.Name,
+					Priority:             lo.ToPtr(priorityClass.Value),
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("5")}},
+				})
+				pod := test.UnschedulablePod(test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("5")}},
+				})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod, prioritized)
+				// The prioritized pod is scheduled first, leaving 8 of 14 cpu which is less than the 60% that's reserved
+				ExpectScheduled(ctx, env.Client, prioritized)
+				ExpectNotScheduled(ctx, env.Client, pod)
+			})
+			It("should schedule pods with a positive priority using reserved limits", func() {
+				pods := []*v1.Pod{
+					test.UnschedulablePod(test.PodOptions{
+						PriorityClassName:    priorityClass.Name,
+						Priority:             lo.ToPtr(priorityClass.Value),
+						ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("5")}},
+					}),
+					test.UnschedulablePod(test.PodOptions{
+						PriorityClassName:    priorityClass.Name,
+						Priority:             lo.ToPtr(priorityClass.Value),
+						ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("5")}},
+					}),
+				}
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+				for _, pod := range pods {
+					ExpectScheduled(ctx, env.Client, pod)
+				}
+			})
+		})
 		It("should not schedule if limits would be exceeded", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
 				Spec: v1beta1.NodePoolSpec{
@@ -430,6 +680,35 @@ var _ = Describe("Provisioning", func() {
 			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
+		It("should not exceed limits when scheduling rounds race", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}),
+				},
+			}))
+			pod := test.UnschedulablePod(
+				test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						// requires a 2 CPU node, but leaves room for overhead
+						v1.ResourceCPU: resource.MustParse("1.75"),
+					},
+				}})
+			ExpectApplied(ctx, env.Client, pod)
+
+			// Both scheduling rounds see the same headroom, since neither has created its NodeClaim yet
+			first, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(first.NewNodeClaims).To(HaveLen(1))
+			second, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second.NewNodeClaims).To(HaveLen(1))
+
+			_, err = prov.Create(ctx, first.NewNodeClaims[0], provisioning.WithReason(metrics.ProvisioningReason))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = prov.Create(ctx, second.NewNodeClaims[0], provisioning.WithReason(metrics.ProvisioningReason))
+			Expect(err).To(HaveOccurred())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		})
 	})
 	Context("Daemonsets and Node Overhead", func() {
 		It("should account for overhead", func() {
@@ -477,6 +756,25 @@ var _ = Describe("Provisioning", func() {
 			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
 			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
 		})
+		It("should account for the overhead of daemonsets that changed since the last provisioning loop", func() {
+			nodePool := test.NodePool()
+			daemonSet := test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				}},
+			)
+			ExpectApplied(ctx, env.Client, nodePool, daemonSet)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			daemonSet.Spec.Template.Spec.Containers[0].Resources.Requests = v1.ResourceList{v1.ResourceCPU: resource.MustParse("10000"), v1.ResourceMemory: resource.MustParse("10000Gi")}
+			ExpectApplied(ctx, env.Client, daemonSet)
+			ExpectReconcileSucceeded(ctx, daemonsetController, client.ObjectKeyFromObject(daemonSet))
+			pod = test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
 		It("should not schedule if overhead is too large", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
 				test.DaemonSetOptions{PodOptions: test.PodOptions{
@@ -487,6 +785,48 @@ var _ = Describe("Provisioning", func() {
 			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
+		It("should account for the nodepool's reserved resources", func() {
+			nodePool := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Template: v1beta1.NodeClaimTemplate{
+						Spec: v1beta1.NodeClaimSpec{
+							Resources: v1beta1.ResourceRequirements{
+								Reserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+							},
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			allocatable := instanceTypeMap[node.Labels[v1.LabelInstanceTypeStable]].Capacity
+			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
+			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
+		})
+		It("should not schedule if the nodepool's reserved resources are too large", func() {
+			nodePool := test.NodePool(v1beta1.NodePool{
+				Spec: v1beta1.NodePoolSpec{
+					Template: v1beta1.NodeClaimTemplate{
+						Spec: v1beta1.NodeClaimSpec{
+							Resources: v1beta1.ResourceRequirements{
+								Reserved: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("10000Gi")},
+							},
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
 		It("should account for overhead using daemonset pod spec instead of daemonset spec", func() {
 			nodePool := test.NodePool()
 			// Create a daemonset with large resource requests
@@ -790,6 +1130,34 @@ var _ = Describe("Provisioning", func() {
 			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("2")))
 			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("2Gi")))
 		})
+		It("should account for daemonsets that don't tolerate PreferNoSchedule taints", func() {
+			ExpectApplied(ctx, env.Client,
+				test.NodePool(v1beta1.NodePool{
+					Spec: v1beta1.NodePoolSpec{
+						Template: v1beta1.NodeClaimTemplate{
+							Spec: v1beta1.NodeClaimSpec{
+								Taints: []v1.Taint{{Key: "foo", Value: "bar", Effect: v1.TaintEffectPreferNoSchedule}},
+							},
+						},
+					},
+				}),
+				test.DaemonSet(
+					test.DaemonSetOptions{PodOptions: test.PodOptions{
+						ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+					}},
+				))
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					Tolerations:          []v1.Toleration{{Operator: v1.TolerationOperator(v1.NodeSelectorOpExists)}},
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			allocatable := instanceTypeMap[node.Labels[v1.LabelInstanceTypeStable]].Capacity
+			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
+			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
+		})
 		It("should ignore daemonsets with an invalid selector", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
 				test.DaemonSetOptions{PodOptions: test.PodOptions{
@@ -907,6 +1275,186 @@ var _ = Describe("Provisioning", func() {
 			ExpectScheduled(ctx, env.Client, pod)
 		})
 	})
+	Context("Pod Groups", func() {
+		It("should provision for a pod group when all members can schedule", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pods := test.UnschedulablePods(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.PodGroupAnnotationKey: "training"}},
+			}, 3)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+		})
+		It("should not provision for any member of a pod group when one member can't schedule", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pods := test.UnschedulablePods(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.PodGroupAnnotationKey: "training"}},
+			}, 2)
+			pods = append(pods, test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:   metav1.ObjectMeta{Annotations: map[string]string{v1beta1.PodGroupAnnotationKey: "training"}},
+				NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown-zone"},
+			}))
+			other := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, append(pods, other)...)
+			for _, pod := range pods {
+				ExpectNotScheduled(ctx, env.Client, pod)
+			}
+			ExpectScheduled(ctx, env.Client, other)
+		})
+		It("should provision for a pod group when the min member count can schedule", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			annotations := map[string]string{
+				v1beta1.PodGroupAnnotationKey:          "training",
+				v1beta1.PodGroupMinMemberAnnotationKey: "2",
+			}
+			pods := test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}, 2)
+			unschedulable := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:   metav1.ObjectMeta{Annotations: annotations},
+				NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown-zone"},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, append(pods, unschedulable)...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+			ExpectNotScheduled(ctx, env.Client, unschedulable)
+		})
+	})
+	Context("Failed Scheduling Condition", func() {
+		It("should explain why a pod couldn't schedule on the pod's status", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+
+			pod = ExpectExists(ctx, env.Client, pod)
+			condition, ok := lo.Find(pod.Status.Conditions, func(c v1.PodCondition) bool { return c.Type == v1beta1.PodFailedSchedulingCondition })
+			Expect(ok).To(BeTrue())
+			Expect(condition.Status).To(Equal(v1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("IncompatibleTaints"))
+			Expect(condition.Message).To(ContainSubstring(nodePool.Name))
+		})
+		It("should remove the condition once the pod can schedule", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.Status.Conditions).To(ContainElement(HaveField("Type", v1beta1.PodFailedSchedulingCondition)))
+
+			nodePool.Spec.Template.Spec.Taints = nil
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.Status.Conditions).ToNot(ContainElement(HaveField("Type", v1beta1.PodFailedSchedulingCondition)))
+		})
+		It("should not update the condition of a pod when it hasn't changed", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.Status.Conditions).To(ContainElement(HaveField("Type", v1beta1.PodFailedSchedulingCondition)))
+
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectExists(ctx, env.Client, pod).ResourceVersion).To(Equal(pod.ResourceVersion))
+		})
+		It("should limit the number of conditions updated in a single scheduling run", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: "test-key", Value: "test-value", Effect: v1.TaintEffectNoSchedule}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, provisioning.MaxFailedSchedulingConditions+10)
+			hasCondition := func() int {
+				return lo.CountBy(pods, func(pod *v1.Pod) bool {
+					return lo.ContainsBy(ExpectExists(ctx, env.Client, pod).Status.Conditions, func(c v1.PodCondition) bool {
+						return c.Type == v1beta1.PodFailedSchedulingCondition
+					})
+				})
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(hasCondition()).To(Equal(provisioning.MaxFailedSchedulingConditions))
+
+			// The remaining pods are updated by the next scheduling run
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, lo.Map(pods, func(pod *v1.Pod, _ int) *v1.Pod { return ExpectExists(ctx, env.Client, pod) })...)
+			Expect(hasCondition()).To(Equal(len(pods)))
+		})
+	})
+	Context("Scheduling Simulation", func() {
+		It("should return the nodeclaims that would be created without creating them", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pods := test.UnschedulablePods(test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			}, 3)
+			results, err := prov.SimulateScheduling(ctx, pods)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).ToNot(BeEmpty())
+			Expect(lo.SumBy(results.NewNodeClaims, func(n *scheduling.NodeClaim) int { return len(n.Pods) })).To(Equal(3))
+			Expect(results.PodErrors).To(BeEmpty())
+
+			nodeClaims := &v1beta1.NodeClaimList{}
+			Expect(env.Client.List(ctx, nodeClaims)).To(Succeed())
+			Expect(nodeClaims.Items).To(BeEmpty())
+		})
+		It("should schedule against existing capacity", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+			results, err := prov.SimulateScheduling(ctx, []*v1.Pod{test.UnschedulablePod()})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(BeEmpty())
+			Expect(results.ExistingNodes).To(HaveLen(1))
+		})
+		It("should report pods that can't schedule", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			schedulable := test.UnschedulablePod()
+			unschedulable := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown-zone"}})
+			invalid := test.UnschedulablePod(test.PodOptions{
+				NodeRequirements: []v1.NodeSelectorRequirement{{Key: v1beta1.NodePoolLabelKey, Operator: v1.NodeSelectorOpDoesNotExist}},
+			})
+			results, err := prov.SimulateScheduling(ctx, []*v1.Pod{schedulable, unschedulable, invalid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			Expect(lo.Map(lo.Keys(results.PodErrors), func(p *v1.Pod, _ int) string { return p.Name })).To(ConsistOf(unschedulable.Name, invalid.Name))
+		})
+		It("should not modify the pods that are passed in", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodePreferences: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"unknown-zone"}}},
+			})
+			stored := pod.DeepCopy()
+			_, err := prov.SimulateScheduling(ctx, []*v1.Pod{pod})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pod).To(Equal(stored))
+		})
+		It("should serve simulations over http", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			body, err := json.Marshal(&v1.PodList{Items: []v1.Pod{*pod}})
+			Expect(err).ToNot(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			provisioning.NewSimulationHandler(prov).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)).WithContext(ctx))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			response := provisioning.SimulationResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.NodeClaims).To(HaveLen(1))
+			Expect(response.NodeClaims[0].Pods).To(ConsistOf(client.ObjectKeyFromObject(pod).String()))
+			Expect(response.NodeClaims[0].InstanceTypeOptions).ToNot(BeEmpty())
+		})
+		It("should reject non-POST requests to the simulation endpoint", func() {
+			recorder := httptest.NewRecorder()
+			provisioning.NewSimulationHandler(prov).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
 	Context("Annotations", func() {
 		It("should annotate nodes", func() {
 			nodePool := test.NodePool(v1beta1.NodePool{
@@ -1453,6 +2001,68 @@ var _ = Describe("Provisioning", func() {
 			node := ExpectScheduled(ctx, env.Client, pod)
 			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
 		})
+		It("should schedule to any of the storage class's allowed topologies", func() {
+			storageClass.AllowedTopologies = []v1.TopologySelectorTerm{
+				{MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{{Key: v1.LabelTopologyZone, Values: []string{"test-zone-1"}}}},
+				{MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{{Key: v1.LabelTopologyZone, Values: []string{"test-zone-3"}}}},
+			}
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{StorageClassName: &storageClass.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), storageClass, persistentVolumeClaim)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name},
+				NodeRequirements: []v1.NodeSelectorRequirement{{
+					Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2", "test-zone-3"},
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
+		})
+		It("should schedule to storage class allowed topologies with multiple keys", func() {
+			storageClass.AllowedTopologies = []v1.TopologySelectorTerm{{MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{
+				{Key: v1.LabelTopologyZone, Values: []string{"test-zone-2"}},
+				{Key: v1beta1.CapacityTypeLabelKey, Values: []string{v1beta1.CapacityTypeSpot}},
+			}}}
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{StorageClassName: &storageClass.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), storageClass, persistentVolumeClaim)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
+			Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot))
+		})
+		It("should schedule to zones that are allowed by every volume's storage class", func() {
+			otherStorageClass := test.StorageClass(test.StorageClassOptions{Zones: []string{"test-zone-1", "test-zone-2"}})
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{StorageClassName: &storageClass.Name})
+			otherPersistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{StorageClassName: &otherStorageClass.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), storageClass, otherStorageClass, persistentVolumeClaim, otherPersistentVolumeClaim)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name, otherPersistentVolumeClaim.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
+		})
+		It("should schedule to any of the persistent volume's node affinity terms", func() {
+			persistentVolume := test.PersistentVolume()
+			persistentVolume.Spec.NodeAffinity = &v1.VolumeNodeAffinity{Required: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{MatchExpressions: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}},
+				{MatchExpressions: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-3"}}}},
+			}}}
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{VolumeName: persistentVolume.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), persistentVolume, persistentVolumeClaim)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name},
+				NodeRequirements: []v1.NodeSelectorRequirement{{
+					Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-3"},
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
+		})
 		It("should schedule to storage class zones if volume does not exist (ephemeral volume)", func() {
 			pod := test.UnschedulablePod(test.PodOptions{
 				EphemeralVolumeTemplates: []test.EphemeralVolumeTemplateOptions{
@@ -1610,6 +2220,77 @@ var _ = Describe("Provisioning", func() {
 			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
 		})
 	})
+	Context("Resource Claim Requirements", func() {
+		var resourceClass *resourcev1alpha2.ResourceClass
+		var claimTemplate *resourcev1alpha2.ResourceClaimTemplate
+		var pod *v1.Pod
+		BeforeEach(func() {
+			resourceClass = &resourcev1alpha2.ResourceClass{
+				ObjectMeta: test.ObjectMeta(),
+				DriverName: string(fake.ResourceGPUVendorA),
+			}
+			claimTemplate = &resourcev1alpha2.ResourceClaimTemplate{
+				ObjectMeta: test.NamespacedObjectMeta(),
+				Spec: resourcev1alpha2.ResourceClaimTemplateSpec{
+					Spec: resourcev1alpha2.ResourceClaimSpec{ResourceClassName: resourceClass.Name},
+				},
+			}
+			pod = test.UnschedulablePod()
+			pod.Spec.ResourceClaims = []v1.PodResourceClaim{{
+				Name:   "gpu",
+				Source: v1.ClaimSource{ResourceClaimTemplateName: lo.ToPtr(claimTemplate.Name)},
+			}}
+		})
+		It("should schedule to an instance type that advertises the driver's devices", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(), resourceClass, claimTemplate)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Status.Capacity).To(HaveKey(fake.ResourceGPUVendorA))
+		})
+		It("should schedule to an instance type that advertises the resource of the device resource annotation", func() {
+			resourceClass.DriverName = "gpu.example.com"
+			resourceClass.Annotations = map[string]string{v1beta1.DeviceResourceAnnotationKey: string(fake.ResourceGPUVendorB)}
+			ExpectApplied(ctx, env.Client, test.NodePool(), resourceClass, claimTemplate)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Status.Capacity).To(HaveKey(fake.ResourceGPUVendorB))
+		})
+		It("should schedule to the resource class's suitable nodes", func() {
+			resourceClass.SuitableNodes = &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+			}}}
+			ExpectApplied(ctx, env.Client, test.NodePool(), resourceClass, claimTemplate)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
+			Expect(node.Status.Capacity).To(HaveKey(fake.ResourceGPUVendorA))
+		})
+		It("should schedule pods that reference a resource claim", func() {
+			claim := &resourcev1alpha2.ResourceClaim{
+				ObjectMeta: test.NamespacedObjectMeta(),
+				Spec:       resourcev1alpha2.ResourceClaimSpec{ResourceClassName: resourceClass.Name},
+			}
+			pod.Spec.ResourceClaims = []v1.PodResourceClaim{{
+				Name:   "gpu",
+				Source: v1.ClaimSource{ResourceClaimName: lo.ToPtr(claim.Name)},
+			}}
+			ExpectApplied(ctx, env.Client, test.NodePool(), resourceClass, claim)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Status.Capacity).To(HaveKey(fake.ResourceGPUVendorA))
+		})
+		It("should not schedule if no instance type advertises the driver's devices", func() {
+			resourceClass.DriverName = "gpu.example.com"
+			ExpectApplied(ctx, env.Client, test.NodePool(), resourceClass, claimTemplate)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should not schedule if the resource class doesn't exist", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(), claimTemplate)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+	})
 	Context("Preferential Fallback", func() {
 		Context("Required", func() {
 			It("should not relax the final term", func() {
@@ -1741,6 +2422,204 @@ var _ = Describe("Provisioning", func() {
 			})
 		})
 	})
+	Context("Headroom", func() {
+		var nodePool *v1beta1.NodePool
+		BeforeEach(func() {
+			nodePool = test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Headroom: &v1beta1.Headroom{Count: 2, Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			}})
+		})
+		It("should launch nodes for headroom without any pending pods", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+			Expect(nodeClaims[0].Spec.Resources.Requests.Cpu().Cmp(resource.MustParse("2"))).To(BeNumerically(">=", 0))
+		})
+		It("should not launch nodes once the headroom fits on existing nodes", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		})
+		It("should launch nodes for headroom that pods have used up", func() {
+			nodePool.Spec.Headroom.Count = 1
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector:         map[string]string{v1.LabelInstanceTypeStable: "small-instance-type"},
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+		})
+		It("should not launch nodes for headroom of nodepools that are deleting", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectDeletionTimestampSet(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		})
+	})
+	Context("Instance Type Cache", func() {
+		var notifyingCloudProvider *fake.NotifyingCloudProvider
+		var cachingProv *provisioning.Provisioner
+		var nodePool *v1beta1.NodePool
+		var small, large *cloudprovider.InstanceType
+
+		scheduledInstanceTypes := func(p *provisioning.Provisioner) []string {
+			results, err := p.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			return lo.Map(results.NewNodeClaims[0].InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) string { return it.Name })
+		}
+		BeforeEach(func() {
+			small = fake.NewInstanceType(fake.InstanceTypeOptions{Name: "small"})
+			large = fake.NewInstanceType(fake.InstanceTypeOptions{Name: "large"})
+			notifyingCloudProvider = fake.NewNotifyingCloudProvider()
+			notifyingCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small}
+			cachingProv = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), notifyingCloudProvider, cluster, fakeClock)
+			nodePool = test.NodePool()
+			ExpectApplied(ctx, env.Client, nodePool, test.UnschedulablePod())
+		})
+		It("should use cached instance types until their offerings change", func() {
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+			notifyingCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small, large}
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+
+			notifyingCloudProvider.ChangeOfferings("small")
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small", "large"))
+		})
+		It("should keep cached instance types when offerings of other instance types change", func() {
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+			notifyingCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small, large}
+
+			notifyingCloudProvider.ChangeOfferings("large")
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+		})
+		It("should rebuild instance types when any offering may have changed", func() {
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+			notifyingCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small, large}
+
+			notifyingCloudProvider.ChangeOfferings()
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small", "large"))
+		})
+		It("should rebuild instance types when the nodepool changes", func() {
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small"))
+			notifyingCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small, large}
+
+			nodePool.Spec.Template.Labels = lo.Assign(nodePool.Spec.Template.Labels, map[string]string{"test-key": "test-value"})
+			ExpectApplied(ctx, env.Client, nodePool)
+			Expect(scheduledInstanceTypes(cachingProv)).To(ConsistOf("small", "large"))
+		})
+		It("should not cache instance types for cloudproviders that don't notify of offering changes", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small}
+			Expect(scheduledInstanceTypes(prov)).To(ConsistOf("small"))
+
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{small, large}
+			Expect(scheduledInstanceTypes(prov)).To(ConsistOf("small", "large"))
+		})
+	})
+	Context("Warm Pools", func() {
+		var nodePool *v1beta1.NodePool
+		var warmNodeClaim *v1beta1.NodeClaim
+		BeforeEach(func() {
+			nodePool = test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{WarmPool: &v1beta1.WarmPool{Size: 1}}})
+			warmNodeClaim = test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:   nodePool.Name,
+						v1.LabelInstanceTypeStable: "default-instance-type",
+					},
+					Annotations: map[string]string{v1beta1.WarmPoolAnnotationKey: "true"},
+				},
+				Spec: v1beta1.NodeClaimSpec{
+					Taints: []v1.Taint{v1beta1.WarmPoolNoScheduleTaint},
+				},
+				Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			warmNodeClaim.StatusConditions().MarkTrue(v1beta1.Stopped)
+		})
+		It("should claim a stopped nodeclaim from the warm pool instead of launching", func() {
+			ExpectApplied(ctx, env.Client, nodePool, warmNodeClaim)
+			cluster.UpdateNodeClaim(warmNodeClaim)
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+
+			names, err := prov.CreateNodeClaims(ctx, results.NewNodeClaims)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(ConsistOf(warmNodeClaim.Name))
+			Expect(cloudProvider.CreateCalls).To(BeEmpty())
+
+			warmNodeClaim = ExpectExists(ctx, env.Client, warmNodeClaim)
+			Expect(warmNodeClaim.Annotations).ToNot(HaveKey(v1beta1.WarmPoolAnnotationKey))
+			Expect(warmNodeClaim.Spec.Taints).ToNot(ContainElement(v1beta1.WarmPoolNoScheduleTaint))
+		})
+		It("should launch a nodeclaim if the warm nodeclaims aren't compatible with the pod", func() {
+			ExpectApplied(ctx, env.Client, nodePool, warmNodeClaim)
+			cluster.UpdateNodeClaim(warmNodeClaim)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.LabelInstanceTypeStable: "small-instance-type"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			warmNodeClaim = ExpectExists(ctx, env.Client, warmNodeClaim)
+			Expect(warmNodeClaim.Annotations).To(HaveKeyWithValue(v1beta1.WarmPoolAnnotationKey, "true"))
+		})
+		It("should not schedule pods to warm nodeclaims", func() {
+			warmNodeClaim.StatusConditions().MarkTrue(v1beta1.Initialized)
+			ExpectApplied(ctx, env.Client, nodePool, warmNodeClaim)
+			cluster.UpdateNodeClaim(warmNodeClaim)
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.ExistingNodes).To(BeEmpty())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+		})
+	})
+	Context("Batching", func() {
+		var batcher *provisioning.Batcher
+		BeforeEach(func() {
+			batcher = provisioning.NewBatcher()
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchIdleDuration: lo.ToPtr(100 * time.Millisecond),
+				BatchMaxDuration:  lo.ToPtr(time.Second),
+			}))
+		})
+		It("should wait for a trigger before starting a batch", func() {
+			waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+			defer cancel()
+			Expect(batcher.Wait(waitCtx)).To(BeFalse())
+		})
+		It("should end the batch once no triggers arrive within the idle duration", func() {
+			batcher.Trigger()
+			start := time.Now()
+			Expect(batcher.Wait(ctx)).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+		It("should extend the batch while triggers arrive, up to the max duration", func() {
+			batcher.Trigger()
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				defer GinkgoRecover()
+				for {
+					select {
+					case <-done:
+						return
+					case <-time.After(10 * time.Millisecond):
+						batcher.Trigger()
+					}
+				}
+			}()
+			start := time.Now()
+			Expect(batcher.Wait(ctx)).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+	})
 	Context("Multiple NodePools", func() {
 		It("should schedule to an explicitly selected NodePool", func() {
 			nodePool := test.NodePool()
@@ -1812,6 +2691,69 @@ var _ = Describe("Provisioning", func() {
 				node := ExpectScheduled(ctx, env.Client, pod)
 				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(targetedNodePool.Name))
 			})
+			It("should schedule to a lower priority nodepool when the higher priority nodepool is unhealthy", func() {
+				nodePools := []client.Object{
+					test.NodePool(),
+					test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(100)}}),
+				}
+				ExpectApplied(ctx, env.Client, nodePools...)
+				for i := 0; i < state.UnhealthyLaunchFailureThreshold; i++ {
+					cluster.RecordLaunchFailure(nodePools[1].GetName())
+				}
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(nodePools[0].GetName()))
+			})
+			It("should schedule to a lower priority nodepool when the higher priority nodepool is out of capacity", func() {
+				nodePools := []client.Object{
+					test.NodePool(),
+					test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(100)}}),
+				}
+				ExpectApplied(ctx, env.Client, nodePools...)
+				cluster.RecordInsufficientCapacity(nodePools[1].GetName())
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(nodePools[0].GetName()))
+			})
+			It("should schedule to the higher priority nodepool again once its capacity error expires", func() {
+				nodePools := []client.Object{
+					test.NodePool(),
+					test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(100)}}),
+				}
+				ExpectApplied(ctx, env.Client, nodePools...)
+				cluster.RecordInsufficientCapacity(nodePools[1].GetName())
+				fakeClock.Step(5 * time.Minute)
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(nodePools[1].GetName()))
+			})
+			It("should schedule to the higher priority nodepool again once it launches successfully", func() {
+				nodePools := []client.Object{
+					test.NodePool(),
+					test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(100)}}),
+				}
+				ExpectApplied(ctx, env.Client, nodePools...)
+				cluster.RecordInsufficientCapacity(nodePools[1].GetName())
+				cluster.RecordLaunchSuccess(nodePools[1].GetName())
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(nodePools[1].GetName()))
+			})
+			It("should schedule to an unhealthy nodepool when it's the only one that's compatible", func() {
+				unhealthyNodePool := test.NodePool()
+				ExpectApplied(ctx, env.Client, unhealthyNodePool, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{Weight: ptr.Int32(100)}}))
+				for i := 0; i < state.UnhealthyLaunchFailureThreshold; i++ {
+					cluster.RecordLaunchFailure(unhealthyNodePool.Name)
+				}
+				pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1beta1.NodePoolLabelKey: unhealthyNodePool.Name}})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[v1beta1.NodePoolLabelKey]).To(Equal(unhealthyNodePool.Name))
+			})
 		})
 	})
 })
@@ -0,0 +1,73 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/functional"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// claimWarmNodeClaim takes a stopped NodeClaim out of its nodepool's warm pool if it's compatible with the NodeClaim
+// that would otherwise be launched. The claimed NodeClaim is started by the lifecycle controller and its node
+// registers with the cluster again. It returns the name of the claimed NodeClaim, or false if no warm NodeClaim was
+// compatible.
+func (p *Provisioner) claimWarmNodeClaim(ctx context.Context, n *scheduler.NodeClaim, opts ...functional.Option[LaunchOptions]) (string, bool, error) {
+	for _, node := range p.cluster.Nodes() {
+		if !node.Warm() || node.MarkedForDeletion() || node.Labels()[v1beta1.NodePoolLabelKey] != n.NodePoolName ||
+			!node.NodeClaim.StatusConditions().GetCondition(v1beta1.Stopped).IsTrue() {
+			continue
+		}
+		labels := lo.OmitByKeys(node.Labels(), []string{v1.LabelHostname})
+		if n.Requirements.Intersects(scheduling.NewLabelRequirements(labels)) != nil ||
+			!lo.ContainsBy(n.InstanceTypeOptions, func(it *cloudprovider.InstanceType) bool { return it.Name == labels[v1.LabelInstanceTypeStable] }) {
+			continue
+		}
+		nodeClaim := node.NodeClaim.DeepCopy()
+		stored := nodeClaim.DeepCopy()
+		delete(nodeClaim.Annotations, v1beta1.WarmPoolAnnotationKey)
+		nodeClaim.Spec.Taints = lo.Reject(nodeClaim.Spec.Taints, func(t v1.Taint, _ int) bool { return t.MatchTaint(&v1beta1.WarmPoolNoScheduleTaint) })
+		// The optimistic lock ensures that a warm NodeClaim can only be claimed once
+		if err := p.kubeClient.Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+			if errors.IsConflict(err) || errors.IsNotFound(err) {
+				continue
+			}
+			return "", false, fmt.Errorf("claiming warm nodeclaim, %w", err)
+		}
+		logging.FromContext(ctx).With("nodepool", n.NodePoolName, "nodeclaim", nodeClaim.Name).Infof("claimed nodeclaim from warm pool")
+		p.cluster.UpdateNodeClaim(nodeClaim)
+		if functional.ResolveOptions(opts...).RecordPodNomination {
+			for _, pod := range lo.Reject(n.Pods, func(pod *v1.Pod, _ int) bool { return podutil.IsOwnedByNodePool(pod) }) {
+				p.recorder.Publish(scheduler.NominatePodEvent(pod, nil, nodeClaim))
+			}
+		}
+		return nodeClaim.Name, true, nil
+	}
+	return "", false, nil
+}
@@ -42,6 +42,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 // Cluster maintains cluster state that is often needed but expensive to compute.
@@ -51,11 +52,20 @@ type Cluster struct {
 	clock         clock.Clock
 
 	mu                        sync.RWMutex
-	nodes                     map[string]*StateNode           // provider id -> cached node
-	bindings                  map[types.NamespacedName]string // pod namespaced named -> node name
-	nodeNameToProviderID      map[string]string               // node name -> provider id
-	nodeClaimNameToProviderID map[string]string               // node claim name -> provider id
-	daemonSetPods             sync.Map                        // daemonSet -> existing pod
+	nodes                     map[string]*StateNode              // provider id -> cached node
+	bindings                  map[types.NamespacedName]string    // pod namespaced named -> node name
+	nodeNameToProviderID      map[string]string                  // node name -> provider id
+	nodeClaimNameToProviderID map[string]string                  // node claim name -> provider id
+	unlaunchedNodeClaims      map[string]*v1beta1.NodeClaim      // node claim name -> NodeClaim that hasn't launched yet
+	daemonSetPods             sync.Map                           // daemonSet -> existing pod
+	restoredNominations       map[string]time.Time               // provider id -> nomination expiry for nodes that aren't tracked yet
+	podNominations            map[types.NamespacedName]time.Time // pod namespaced name -> nomination expiry of pods with nomination annotations
+	launchFailures            map[string]*launchFailures         // nodepool name -> consecutive launch failures
+	pendingLimitReservations  map[*LimitReservation]struct{}     // reservations of nodepool limits for NodeClaims that are being created
+	limitReservations         map[string]*LimitReservation       // node claim name -> reservation of nodepool limits for the NodeClaim until it launches
+	scaleUpStalls             map[string]*scaleUpStall           // nodepool name -> scale-up that can't launch capacity for pending pods
+	nodePoolUsage             map[string]*NodePoolUsage          // nodepool name -> aggregated usage of its nodes
+	nodeUsage                 map[string]nodeUsage               // provider id -> the node's contribution to its nodepool's usage
 
 	clusterStateMu sync.RWMutex // Separate mutex as this is called in some places that mu is held
 	// A monotonically increasing timestamp representing the time state of the
@@ -63,7 +73,10 @@ type Cluster struct {
 	// changed about the cluster that might make consolidation possible. By recording
 	// the state, interested disruption methods can check to see if this has changed to
 	// optimize and not try to disrupt if nothing about the cluster has changed.
-	clusterState     time.Time
+	clusterState time.Time
+	// A monotonically increasing revision of the cluster state with respect to consolidation. This increases every
+	// time clusterState changes, so that disruption methods can key the results of their simulations on it.
+	revision         uint64
 	antiAffinityPods sync.Map // pod namespaced name -> *v1.Pod of pods that have required anti affinities
 }
 
@@ -77,6 +90,15 @@ func NewCluster(clk clock.Clock, client client.Client, cp cloudprovider.CloudPro
 		daemonSetPods:             sync.Map{},
 		nodeNameToProviderID:      map[string]string{},
 		nodeClaimNameToProviderID: map[string]string{},
+		unlaunchedNodeClaims:      map[string]*v1beta1.NodeClaim{},
+		restoredNominations:       map[string]time.Time{},
+		podNominations:            map[types.NamespacedName]time.Time{},
+		launchFailures:            map[string]*launchFailures{},
+		pendingLimitReservations:  map[*LimitReservation]struct{}{},
+		limitReservations:         map[string]*LimitReservation{},
+		scaleUpStalls:             map[string]*scaleUpStall{},
+		nodePoolUsage:             map[string]*NodePoolUsage{},
+		nodeUsage:                 map[string]nodeUsage{},
 	}
 }
 
@@ -175,6 +197,65 @@ func (c *Cluster) Nodes() StateNodes {
 	})
 }
 
+// NodePoolUsage is the aggregated resource usage of all the nodes in cluster state that are owned by a NodePool
+type NodePoolUsage struct {
+	// Nodes is the number of nodes owned by the NodePool
+	Nodes int
+	// NodeClaims is the number of the NodePool's nodes that have a NodeClaim
+	NodeClaims int
+	// KubeNodes is the number of the NodePool's nodes that have a Node
+	KubeNodes int
+	// Capacity is the sum of the capacity of all nodes owned by the NodePool
+	Capacity v1.ResourceList
+	// Allocatable is the sum of the allocatable resources of all nodes owned by the NodePool
+	Allocatable v1.ResourceList
+	// Requests is the sum of the resource requests of all pods bound to nodes owned by the NodePool
+	Requests v1.ResourceList
+	// CapacityTypes is the number of nodes owned by the NodePool with each capacity type, along with the NodeClaims that
+	// are launching for it
+	CapacityTypes map[string]int
+}
+
+// NodePoolUsage returns the aggregated resource usage of the nodes tracked in cluster state, keyed by the name of the
+// NodePool that owns them. Nodes that are marked for deletion aren't counted to stay consistent with how NodePool limits
+// are evaluated throughout the provisioning and disruption loops. The usage is maintained as nodes change, so this
+// doesn't aggregate every node on each call.
+func (c *Cluster) NodePoolUsage() map[string]NodePoolUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	usage := lo.MapValues(c.nodePoolUsage, func(u *NodePoolUsage, _ string) NodePoolUsage {
+		return NodePoolUsage{
+			Nodes:         u.Nodes,
+			NodeClaims:    u.NodeClaims,
+			KubeNodes:     u.KubeNodes,
+			Capacity:      u.Capacity.DeepCopy(),
+			Allocatable:   u.Allocatable.DeepCopy(),
+			Requests:      u.Requests.DeepCopy(),
+			CapacityTypes: lo.Ternary(len(u.CapacityTypes) == 0, nil, lo.Assign(u.CapacityTypes)),
+		}
+	})
+	// NodeClaims that haven't launched yet count towards the capacity types of their NodePool, so that the launches
+	// that are in flight aren't repeated by the next scheduling loop
+	for _, nodeClaim := range c.unlaunchedNodeClaims {
+		nodePoolName, ok := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+		if !ok || !nodeClaim.DeletionTimestamp.IsZero() {
+			continue
+		}
+		capacityType, ok := nodeClaimCapacityType(nodeClaim)
+		if !ok {
+			continue
+		}
+		u := usage[nodePoolName]
+		if u.CapacityTypes == nil {
+			u.CapacityTypes = map[string]int{}
+		}
+		u.CapacityTypes[capacityType]++
+		usage[nodePoolName] = u
+	}
+	return usage
+}
+
 // IsNodeNominated returns true if the given node was expected to have a pod bound to it during a recent scheduling
 // batch
 func (c *Cluster) IsNodeNominated(providerID string) bool {
@@ -197,6 +278,50 @@ func (c *Cluster) NominateNodeForPod(ctx context.Context, providerID string) {
 	}
 }
 
+// Nominations returns when the nomination of each nominated node expires, keyed by provider id. This includes
+// restored nominations for nodes that aren't tracked yet.
+func (c *Cluster) Nominations() map[string]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	nominations := map[string]time.Time{}
+	for providerID, until := range c.restoredNominations {
+		if until.After(c.clock.Now()) {
+			nominations[providerID] = until
+		}
+	}
+	for providerID, n := range c.nodes {
+		if n.Nominated() {
+			nominations[providerID] = n.nominatedUntil.Time
+		}
+	}
+	return nominations
+}
+
+// RestoreNominations nominates nodes that were nominated before a restart until their nominations expire. Nodes that
+// aren't tracked yet are nominated once they're added to cluster state.
+func (c *Cluster) RestoreNominations(nominations map[string]time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for providerID, until := range nominations {
+		if n, ok := c.nodes[providerID]; ok {
+			n.restoreNomination(until)
+			continue
+		}
+		c.restoredNominations[providerID] = until
+	}
+}
+
+// PodNominations returns when the nomination of each pod that's annotated as nominated to a node expires, keyed by the
+// pod's namespaced name. Pods whose nomination annotations can't be parsed have already expired.
+func (c *Cluster) PodNominations() map[types.NamespacedName]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return lo.Assign(c.podNominations)
+}
+
 // TODO remove this when v1alpha5 APIs are deprecated. With v1beta1 APIs Karpenter relies on the existence
 // of the karpenter.sh/disruption taint to know when a node is marked for deletion.
 // UnmarkForDeletion removes the marking on the node as a node the controller intends to delete
@@ -207,6 +332,7 @@ func (c *Cluster) UnmarkForDeletion(providerIDs ...string) {
 	for _, id := range providerIDs {
 		if n, ok := c.nodes[id]; ok {
 			n.markedForDeletion = false
+			c.updateNodePoolUsage(id)
 		}
 	}
 }
@@ -221,6 +347,7 @@ func (c *Cluster) MarkForDeletion(providerIDs ...string) {
 	for _, id := range providerIDs {
 		if n, ok := c.nodes[id]; ok {
 			n.markedForDeletion = true
+			c.updateNodePoolUsage(id)
 		}
 	}
 }
@@ -235,6 +362,12 @@ func (c *Cluster) UpdateNodeClaim(nodeClaim *v1beta1.NodeClaim) {
 	if nodeClaim.Status.ProviderID != "" {
 		n := c.newStateFromNodeClaim(nodeClaim, c.nodes[nodeClaim.Status.ProviderID])
 		c.nodes[nodeClaim.Status.ProviderID] = n
+		c.updateNodePoolUsage(nodeClaim.Status.ProviderID)
+		// The capacity of the launched nodeclaim is counted against its nodepool's limits from now on
+		c.releaseLimitReservation(nodeClaim.Name)
+		delete(c.unlaunchedNodeClaims, nodeClaim.Name)
+	} else {
+		c.unlaunchedNodeClaims[nodeClaim.Name] = nodeClaim
 	}
 	// If the nodeclaim hasn't launched yet, we want to add it into cluster state to ensure
 	// that we're not racing with the internal cache for the cluster, assuming the node doesn't exist.
@@ -274,6 +407,7 @@ func (c *Cluster) UpdateNode(ctx context.Context, node *v1.Node) error {
 	}
 	c.nodes[node.Spec.ProviderID] = n
 	c.nodeNameToProviderID[node.Name] = node.Spec.ProviderID
+	c.updateNodePoolUsage(node.Spec.ProviderID)
 	clusterStateNodesCount.Set(float64(len(c.nodes)))
 	return nil
 }
@@ -296,6 +430,10 @@ func (c *Cluster) UpdatePod(ctx context.Context, pod *v1.Pod) error {
 		err = c.updateNodeUsageFromPod(ctx, pod)
 	}
 	c.updatePodAntiAffinities(pod)
+	c.updatePodNomination(pod)
+	if !podutils.IsProvisionable(pod) {
+		c.resolveScaleUpStalls(client.ObjectKeyFromObject(pod))
+	}
 	return err
 }
 
@@ -304,6 +442,8 @@ func (c *Cluster) DeletePod(podKey types.NamespacedName) {
 	defer c.mu.Unlock()
 
 	c.antiAffinityPods.Delete(podKey)
+	delete(c.podNominations, podKey)
+	c.resolveScaleUpStalls(podKey)
 	c.updateNodeUsageFromPodCompletion(podKey)
 	c.MarkUnconsolidated()
 }
@@ -315,6 +455,7 @@ func (c *Cluster) MarkUnconsolidated() time.Time {
 	newState := c.clock.Now()
 	c.clusterStateMu.Lock()
 	c.clusterState = newState
+	c.revision++
 	c.clusterStateMu.Unlock()
 	return newState
 }
@@ -339,14 +480,32 @@ func (c *Cluster) ConsolidationState() time.Time {
 	return c.MarkUnconsolidated()
 }
 
-// Reset the cluster state for unit testing
+// Revision returns a monotonically increasing revision of the cluster state with respect to consolidation. The revision
+// increases whenever ConsolidationState changes, including when ConsolidationState resets after five minutes.
+func (c *Cluster) Revision() uint64 {
+	c.ConsolidationState()
+
+	c.clusterStateMu.RLock()
+	defer c.clusterStateMu.RUnlock()
+	return c.revision
+}
+
+// Reset the cluster state for unit testing. Limit reservations are kept, since the NodeClaims that they're held for
+// may still be being created, and are released once the NodeClaims launch or are deleted.
 func (c *Cluster) Reset() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.nodes = map[string]*StateNode{}
+	c.nodePoolUsage = map[string]*NodePoolUsage{}
+	c.nodeUsage = map[string]nodeUsage{}
 	c.nodeNameToProviderID = map[string]string{}
 	c.nodeClaimNameToProviderID = map[string]string{}
+	c.unlaunchedNodeClaims = map[string]*v1beta1.NodeClaim{}
 	c.bindings = map[types.NamespacedName]string{}
+	c.restoredNominations = map[string]time.Time{}
+	c.podNominations = map[types.NamespacedName]time.Time{}
+	c.launchFailures = map[string]*launchFailures{}
+	c.scaleUpStalls = map[string]*scaleUpStall{}
 	c.antiAffinityPods = sync.Map{}
 	c.daemonSetPods = sync.Map{}
 }
@@ -405,6 +564,7 @@ func (c *Cluster) newStateFromNodeClaim(nodeClaim *v1beta1.NodeClaim, oldNode *S
 		markedForDeletion: oldNode.markedForDeletion,
 		nominatedUntil:    oldNode.nominatedUntil,
 	}
+	c.applyRestoredNomination(nodeClaim.Status.ProviderID, n)
 	// Cleanup the old nodeClaim with its old providerID if its providerID changes
 	// This can happen since nodes don't get created with providerIDs. Rather, CCM picks up the
 	// created node and injects the providerID into the spec.providerID
@@ -415,6 +575,13 @@ func (c *Cluster) newStateFromNodeClaim(nodeClaim *v1beta1.NodeClaim, oldNode *S
 	return n
 }
 
+func (c *Cluster) applyRestoredNomination(providerID string, n *StateNode) {
+	if until, ok := c.restoredNominations[providerID]; ok && providerID != "" {
+		n.restoreNomination(until)
+		delete(c.restoredNominations, providerID)
+	}
+}
+
 func (c *Cluster) cleanupNodeClaim(name string) {
 	if id := c.nodeClaimNameToProviderID[name]; id != "" {
 		if c.nodes[id].Node == nil {
@@ -422,12 +589,15 @@ func (c *Cluster) cleanupNodeClaim(name string) {
 		} else {
 			c.nodes[id].NodeClaim = nil
 		}
+		c.updateNodePoolUsage(id)
 		c.MarkUnconsolidated()
 	}
 	// Delete the node claim from the nodeClaimNameToProviderID in the case that the provider ID hasn't resolved
 	// yet. This ensures that if a nodeClaim is created and then deleted before it was able to launch that
 	// this is cleaned up.
 	delete(c.nodeClaimNameToProviderID, name)
+	delete(c.unlaunchedNodeClaims, name)
+	c.releaseLimitReservation(name)
 }
 
 func (c *Cluster) newStateFromNode(ctx context.Context, node *v1.Node, oldNode *StateNode) (*StateNode, error) {
@@ -446,6 +616,7 @@ func (c *Cluster) newStateFromNode(ctx context.Context, node *v1.Node, oldNode *
 		markedForDeletion: oldNode.markedForDeletion,
 		nominatedUntil:    oldNode.nominatedUntil,
 	}
+	c.applyRestoredNomination(node.Spec.ProviderID, n)
 	if err := multierr.Combine(
 		c.populateResourceRequests(ctx, n),
 		c.populateVolumeLimits(ctx, n),
@@ -469,6 +640,7 @@ func (c *Cluster) cleanupNode(name string) {
 		} else {
 			c.nodes[id].Node = nil
 		}
+		c.updateNodePoolUsage(id)
 		delete(c.nodeNameToProviderID, name)
 		c.MarkUnconsolidated()
 	}
@@ -515,7 +687,8 @@ func (c *Cluster) updateNodeUsageFromPod(ctx context.Context, pod *v1.Pod) error
 		return nil
 	}
 
-	n, ok := c.nodes[c.nodeNameToProviderID[pod.Spec.NodeName]]
+	providerID := c.nodeNameToProviderID[pod.Spec.NodeName]
+	n, ok := c.nodes[providerID]
 	if !ok {
 		// the node must exist for us to update the resource requests on the node
 		return errors.NewNotFound(schema.GroupResource{Resource: "Node"}, pod.Spec.NodeName)
@@ -523,6 +696,7 @@ func (c *Cluster) updateNodeUsageFromPod(ctx context.Context, pod *v1.Pod) error
 	if err := n.updateForPod(ctx, c.kubeClient, pod); err != nil {
 		return err
 	}
+	c.updateNodePoolUsage(providerID)
 	c.cleanupOldBindings(pod)
 	c.bindings[client.ObjectKeyFromObject(pod)] = pod.Spec.NodeName
 	return nil
@@ -536,12 +710,14 @@ func (c *Cluster) updateNodeUsageFromPodCompletion(podKey types.NamespacedName)
 	}
 
 	delete(c.bindings, podKey)
-	n, ok := c.nodes[c.nodeNameToProviderID[nodeName]]
+	providerID := c.nodeNameToProviderID[nodeName]
+	n, ok := c.nodes[providerID]
 	if !ok {
 		// we weren't tracking the node yet, so nothing to do
 		return
 	}
 	n.cleanupForPod(podKey)
+	c.updateNodePoolUsage(providerID)
 }
 
 func (c *Cluster) cleanupOldBindings(pod *v1.Pod) {
@@ -555,6 +731,7 @@ func (c *Cluster) cleanupOldBindings(pod *v1.Pod) {
 		if oldNode, ok := c.nodes[c.nodeNameToProviderID[oldNodeName]]; ok {
 			// we were tracking the old node, so we need to reduce its capacity by the amount of the pod that left
 			oldNode.cleanupForPod(client.ObjectKeyFromObject(pod))
+			c.updateNodePoolUsage(c.nodeNameToProviderID[oldNodeName])
 			delete(c.bindings, client.ObjectKeyFromObject(pod))
 		}
 	}
@@ -562,6 +739,91 @@ func (c *Cluster) cleanupOldBindings(pod *v1.Pod) {
 	c.MarkUnconsolidated()
 }
 
+// nodeUsage is a node's contribution to the usage of the NodePool that owns it
+type nodeUsage struct {
+	nodePoolName string
+	capacity     v1.ResourceList
+	allocatable  v1.ResourceList
+	requests     v1.ResourceList
+	capacityType string
+	nodeClaim    bool
+	node         bool
+}
+
+// updateNodePoolUsage replaces the contribution of the node with the provider id to its NodePool's usage with its
+// current one. It must be called whenever a node that's tracked in cluster state changes.
+func (c *Cluster) updateNodePoolUsage(providerID string) {
+	if old, ok := c.nodeUsage[providerID]; ok {
+		u := c.nodePoolUsage[old.nodePoolName]
+		u.Nodes--
+		u.NodeClaims -= lo.Ternary(old.nodeClaim, 1, 0)
+		u.KubeNodes -= lo.Ternary(old.node, 1, 0)
+		u.Capacity = subtractUsage(u.Capacity, old.capacity)
+		u.Allocatable = subtractUsage(u.Allocatable, old.allocatable)
+		u.Requests = subtractUsage(u.Requests, old.requests)
+		if old.capacityType != "" {
+			if u.CapacityTypes[old.capacityType]--; u.CapacityTypes[old.capacityType] == 0 {
+				delete(u.CapacityTypes, old.capacityType)
+			}
+		}
+		if u.Nodes == 0 {
+			delete(c.nodePoolUsage, old.nodePoolName)
+		}
+		delete(c.nodeUsage, providerID)
+	}
+	n, ok := c.nodes[providerID]
+	if !ok {
+		return
+	}
+	nodePoolName, ok := n.Labels()[v1beta1.NodePoolLabelKey]
+	if !ok || n.MarkedForDeletion() {
+		return
+	}
+	capacityType, _ := n.CapacityType()
+	current := nodeUsage{
+		nodePoolName: nodePoolName,
+		capacity:     n.Capacity().DeepCopy(),
+		allocatable:  n.Allocatable().DeepCopy(),
+		requests:     n.PodRequests(),
+		capacityType: capacityType,
+		nodeClaim:    n.NodeClaim != nil,
+		node:         n.Node != nil,
+	}
+	c.nodeUsage[providerID] = current
+	u, ok := c.nodePoolUsage[nodePoolName]
+	if !ok {
+		u = &NodePoolUsage{Requests: v1.ResourceList{}}
+		c.nodePoolUsage[nodePoolName] = u
+	}
+	u.Nodes++
+	u.NodeClaims += lo.Ternary(current.nodeClaim, 1, 0)
+	u.KubeNodes += lo.Ternary(current.node, 1, 0)
+	u.Capacity = resources.MergeInto(u.Capacity, current.capacity)
+	u.Allocatable = resources.MergeInto(u.Allocatable, current.allocatable)
+	u.Requests = resources.MergeInto(u.Requests, current.requests)
+	if capacityType != "" {
+		if u.CapacityTypes == nil {
+			u.CapacityTypes = map[string]int{}
+		}
+		u.CapacityTypes[capacityType]++
+	}
+}
+
+// subtractUsage subtracts a node's contribution from a NodePool's usage in place, dropping the resources that no longer
+// have any usage
+func subtractUsage(usage, contribution v1.ResourceList) v1.ResourceList {
+	for resourceName, quantity := range contribution {
+		current := usage[resourceName]
+		current.Sub(quantity)
+		if current.IsZero() {
+			delete(usage, resourceName)
+		} else {
+			usage[resourceName] = current
+		}
+	}
+	return usage
+}
+
 func (c *Cluster) updatePodAntiAffinities(pod *v1.Pod) {
 	// We intentionally don't track inverse anti-affinity preferences. We're not
 	// required to enforce them so it just adds complexity for very little
@@ -574,6 +836,15 @@ func (c *Cluster) updatePodAntiAffinities(pod *v1.Pod) {
 	}
 }
 
+func (c *Cluster) updatePodNomination(pod *v1.Pod) {
+	if _, ok := pod.Annotations[v1beta1.NominatedNodeAnnotationKey]; !ok {
+		delete(c.podNominations, client.ObjectKeyFromObject(pod))
+		return
+	}
+	_, until, _ := podutils.Nomination(pod)
+	c.podNominations[client.ObjectKeyFromObject(pod)] = until
+}
+
 func (c *Cluster) triggerConsolidationOnChange(old, new *StateNode) {
 	if old == nil || new == nil {
 		c.MarkUnconsolidated()
@@ -0,0 +1,242 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// InconsistencyType is the part of cluster state that diverged from the apiserver
+type InconsistencyType string
+
+const (
+	InconsistencyTypePodBinding    InconsistencyType = "PodBinding"
+	InconsistencyTypeNode          InconsistencyType = "Node"
+	InconsistencyTypeNodeClaimNode InconsistencyType = "NodeClaimNode"
+)
+
+// Inconsistency describes a single difference between cluster state and the apiserver
+type Inconsistency struct {
+	Type InconsistencyType
+	// Object is the object that the inconsistency was found for, it's nil if the object isn't known
+	Object  client.Object
+	Message string
+}
+
+// apiServerState is a snapshot of the objects in the apiserver that cluster state is built from
+type apiServerState struct {
+	nodes      []v1.Node
+	nodeClaims []v1beta1.NodeClaim
+	pods       []v1.Pod
+}
+
+func listAPIServerState(ctx context.Context, apiReader client.Reader) (apiServerState, error) {
+	nodeList := &v1.NodeList{}
+	if err := apiReader.List(ctx, nodeList); err != nil {
+		return apiServerState{}, fmt.Errorf("listing nodes, %w", err)
+	}
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := apiReader.List(ctx, nodeClaimList); err != nil {
+		return apiServerState{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	podList := &v1.PodList{}
+	if err := apiReader.List(ctx, podList); err != nil {
+		return apiServerState{}, fmt.Errorf("listing pods, %w", err)
+	}
+	return apiServerState{nodes: nodeList.Items, nodeClaims: nodeClaimList.Items, pods: podList.Items}, nil
+}
+
+// Inconsistencies cross-validates cluster state against the nodes, nodeclaims, and pod bindings stored in the
+// apiserver. Cluster state is only updated through watch events, so a missed or mishandled event can leave it
+// stale until the object changes again. The apiReader must read from the apiserver rather than from the informer
+// cache, since the cache is fed by the same watches as cluster state.
+func (c *Cluster) Inconsistencies(ctx context.Context, apiReader client.Reader) ([]Inconsistency, error) {
+	s, err := listAPIServerState(ctx, apiReader)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append(append(c.nodeInconsistencies(s), c.nodeClaimNodeInconsistencies(s)...), c.podBindingInconsistencies(s)...), nil
+}
+
+func (c *Cluster) nodeInconsistencies(s apiServerState) []Inconsistency {
+	var inconsistencies []Inconsistency
+	names := sets.New[string]()
+	for i := range s.nodes {
+		node := &s.nodes[i]
+		names.Insert(node.Name)
+		if _, ok := c.nodeNameToProviderID[node.Name]; !ok && tracksNode(node) {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:    InconsistencyTypeNode,
+				Object:  node,
+				Message: fmt.Sprintf("node %q exists but isn't tracked in cluster state", node.Name),
+			})
+		}
+	}
+	for name, providerID := range c.nodeNameToProviderID {
+		if !names.Has(name) {
+			inconsistency := Inconsistency{
+				Type:    InconsistencyTypeNode,
+				Message: fmt.Sprintf("node %q is tracked in cluster state but no longer exists", name),
+			}
+			if n, ok := c.nodes[providerID]; ok && n.Node != nil {
+				inconsistency.Object = n.Node
+			}
+			inconsistencies = append(inconsistencies, inconsistency)
+		}
+	}
+	return inconsistencies
+}
+
+func (c *Cluster) nodeClaimNodeInconsistencies(s apiServerState) []Inconsistency {
+	var inconsistencies []Inconsistency
+	nodes := map[string]*v1.Node{}
+	for i := range s.nodes {
+		if s.nodes[i].Spec.ProviderID != "" {
+			nodes[s.nodes[i].Spec.ProviderID] = &s.nodes[i]
+		}
+	}
+	for i := range s.nodeClaims {
+		nodeClaim := &s.nodeClaims[i]
+		node, ok := nodes[nodeClaim.Status.ProviderID]
+		if !ok || !tracksNode(node) {
+			continue
+		}
+		n, ok := c.nodes[nodeClaim.Status.ProviderID]
+		if !ok || n.NodeClaim == nil || n.Node == nil || n.NodeClaim.Name != nodeClaim.Name || n.Node.Name != node.Name {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:    InconsistencyTypeNodeClaimNode,
+				Object:  nodeClaim,
+				Message: fmt.Sprintf("nodeclaim %q and node %q share provider id %q but aren't linked in cluster state", nodeClaim.Name, node.Name, nodeClaim.Status.ProviderID),
+			})
+		}
+	}
+	return inconsistencies
+}
+
+func (c *Cluster) podBindingInconsistencies(s apiServerState) []Inconsistency {
+	var inconsistencies []Inconsistency
+	bound := sets.New[types.NamespacedName]()
+	for i := range s.pods {
+		pod := &s.pods[i]
+		// Cluster state can only track bindings to nodes that it's tracking, untracked nodes are reported separately
+		if _, ok := c.nodeNameToProviderID[pod.Spec.NodeName]; !ok || podutils.IsTerminal(pod) {
+			continue
+		}
+		key := client.ObjectKeyFromObject(pod)
+		bound.Insert(key)
+		if nodeName := c.bindings[key]; nodeName != pod.Spec.NodeName {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:    InconsistencyTypePodBinding,
+				Object:  pod,
+				Message: fmt.Sprintf("pod %q is bound to node %q but cluster state has it bound to %q", key, pod.Spec.NodeName, nodeName),
+			})
+		}
+	}
+	for key, nodeName := range c.bindings {
+		if !bound.Has(key) {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:    InconsistencyTypePodBinding,
+				Message: fmt.Sprintf("pod %q is bound to node %q in cluster state but isn't bound to it", key, nodeName),
+			})
+		}
+	}
+	return inconsistencies
+}
+
+// Resync rebuilds cluster state from the nodes, nodeclaims, and pods that the apiReader reads from the apiserver,
+// dropping anything that no longer exists
+func (c *Cluster) Resync(ctx context.Context, apiReader client.Reader) error {
+	s, err := listAPIServerState(ctx, apiReader)
+	if err != nil {
+		return err
+	}
+	var errs error
+	nodeNames := sets.New[string]()
+	for i := range s.nodes {
+		nodeNames.Insert(s.nodes[i].Name)
+		errs = multierr.Append(errs, c.UpdateNode(ctx, &s.nodes[i]))
+	}
+	nodeClaimNames := sets.New[string]()
+	for i := range s.nodeClaims {
+		nodeClaimNames.Insert(s.nodeClaims[i].Name)
+		c.UpdateNodeClaim(&s.nodeClaims[i])
+	}
+	bound := sets.New[types.NamespacedName]()
+	for i := range s.pods {
+		if s.pods[i].Spec.NodeName != "" && !podutils.IsTerminal(&s.pods[i]) {
+			bound.Insert(client.ObjectKeyFromObject(&s.pods[i]))
+		}
+		// Pods bound to nodes that still aren't tracked are picked up once the node is
+		if err = c.UpdatePod(ctx, &s.pods[i]); !errors.IsNotFound(err) {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	c.mu.RLock()
+	staleNodes := sets.New[string]()
+	for name := range c.nodeNameToProviderID {
+		if !nodeNames.Has(name) {
+			staleNodes.Insert(name)
+		}
+	}
+	staleNodeClaims := sets.New[string]()
+	for name := range c.nodeClaimNameToProviderID {
+		if !nodeClaimNames.Has(name) {
+			staleNodeClaims.Insert(name)
+		}
+	}
+	staleBindings := sets.New[types.NamespacedName]()
+	for key := range c.bindings {
+		if !bound.Has(key) {
+			staleBindings.Insert(key)
+		}
+	}
+	c.mu.RUnlock()
+
+	for key := range staleBindings {
+		c.DeletePod(key)
+	}
+	for name := range staleNodes {
+		c.DeleteNode(name)
+	}
+	for name := range staleNodeClaims {
+		c.DeleteNodeClaim(name)
+	}
+	return errs
+}
+
+// tracksNode mirrors the nodes that UpdateNode adds to cluster state. Managed nodes aren't tracked until they have
+// a provider id and, before they're initialized, an instance type label.
+func tracksNode(node *v1.Node) bool {
+	if node.Labels[v1beta1.NodePoolLabelKey] == "" {
+		return true
+	}
+	return node.Spec.ProviderID != "" && (node.Labels[v1.LabelInstanceTypeStable] != "" || node.Labels[v1beta1.NodeInitializedLabelKey] != "")
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// checkPeriod is how often cluster state is compared against the apiserver
+const checkPeriod = 5 * time.Minute
+
+// Controller periodically cross-validates cluster state against the apiserver. Cluster state is driven by watch
+// events, so if it ends up stale in a long-running controller, disruption decisions are silently made against
+// nodes and pods that no longer look that way.
+type Controller struct {
+	apiReader client.Reader
+	cluster   *state.Cluster
+	recorder  events.Recorder
+	// suspected are the inconsistencies found in the previous check. Cluster state can briefly lag behind the
+	// apiserver while events are processed, so an inconsistency is only reported once it's seen in two checks in a row.
+	suspected sets.Set[string]
+}
+
+// NewController constructs a controller that compares cluster state against the objects that the apiReader reads,
+// which must come from the apiserver rather than from the informer cache
+func NewController(apiReader client.Reader, cluster *state.Cluster, recorder events.Recorder) operatorcontroller.Controller {
+	return &Controller{
+		apiReader: apiReader,
+		cluster:   cluster,
+		recorder:  recorder,
+		suspected: sets.New[string](),
+	}
+}
+
+func (c *Controller) Name() string {
+	return "state.consistency"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	// Until cluster state has synced, everything that hasn't been processed yet would look inconsistent
+	if !c.cluster.Synced(ctx) {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+	inconsistencies, err := c.cluster.Inconsistencies(ctx, c.apiReader)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("checking cluster state consistency, %w", err)
+	}
+	found := sets.New[string]()
+	var confirmed int
+	for _, inconsistency := range inconsistencies {
+		found.Insert(inconsistency.Message)
+		if !c.suspected.Has(inconsistency.Message) {
+			continue
+		}
+		confirmed++
+		logging.FromContext(ctx).Errorf("cluster state is inconsistent, %s", inconsistency.Message)
+		inconsistenciesCounter.With(prometheus.Labels{typeLabel: string(inconsistency.Type)}).Inc()
+		if inconsistency.Object != nil {
+			c.recorder.Publish(InconsistentClusterStateEvent(inconsistency.Object, inconsistency.Message))
+		}
+	}
+	c.suspected = found
+	if confirmed > 0 && options.FromContext(ctx).ResyncStateOnInconsistency {
+		if err = c.cluster.Resync(ctx, c.apiReader); err != nil {
+			return reconcile.Result{}, fmt.Errorf("resyncing cluster state, %w", err)
+		}
+		logging.FromContext(ctx).With("inconsistencies", confirmed).Infof("resynced cluster state")
+		// Anything we just resynced shouldn't carry over to the next check
+		c.suspected = sets.New[string]()
+	}
+	return reconcile.Result{RequeueAfter: checkPeriod}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func InconsistentClusterStateEvent(obj client.Object, message string) events.Event {
+	return events.Event{
+		InvolvedObject: obj,
+		Type:           v1.EventTypeWarning,
+		Reason:         "InconsistentClusterState",
+		Message:        message,
+		DedupeValues:   []string{string(obj.GetUID()), message},
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/consistency"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var consistencyController controller.Controller
+var nodeController controller.Controller
+var nodeClaimController controller.Controller
+var podController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cluster *state.Cluster
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "State/Consistency")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, fake.NewCloudProvider())
+	recorder = test.NewEventRecorder()
+	nodeController = informer.NewNodeController(env.Client, cluster)
+	nodeClaimController = informer.NewNodeClaimController(env.Client, cluster)
+	podController = informer.NewPodController(env.Client, cluster)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Consistency", func() {
+	var nodeClaim *v1beta1.NodeClaim
+	var node *v1.Node
+
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
+		consistencyController = consistency.NewController(env.Client, cluster, recorder)
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   "default",
+				v1.LabelInstanceTypeStable: "default-instance-type",
+			}},
+			Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		cluster.Reset()
+		recorder.Reset()
+	})
+
+	It("should not report anything when cluster state matches the apiserver", func() {
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		Expect(recorder.Calls("InconsistentClusterState")).To(Equal(0))
+	})
+	It("should report pod bindings that cluster state hasn't seen", func() {
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		inconsistencies, err := cluster.Inconsistencies(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inconsistencies).To(HaveLen(1))
+		Expect(inconsistencies[0].Type).To(Equal(state.InconsistencyTypePodBinding))
+
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		Expect(recorder.Calls("InconsistentClusterState")).To(Equal(1))
+		_, found := FindMetricWithLabelValues("karpenter_cluster_state_inconsistencies", map[string]string{"type": string(state.InconsistencyTypePodBinding)})
+		Expect(found).To(BeTrue())
+	})
+	It("should report nodes that no longer exist", func() {
+		ExpectDeleted(ctx, env.Client, node)
+
+		inconsistencies, err := cluster.Inconsistencies(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inconsistencies).To(HaveLen(1))
+		Expect(inconsistencies[0].Type).To(Equal(state.InconsistencyTypeNode))
+	})
+	It("should report nodeclaims and nodes that aren't linked", func() {
+		cluster.DeleteNodeClaim(nodeClaim.Name)
+
+		inconsistencies, err := cluster.Inconsistencies(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inconsistencies).To(HaveLen(1))
+		Expect(inconsistencies[0].Type).To(Equal(state.InconsistencyTypeNodeClaimNode))
+	})
+	It("should not report inconsistencies that are only seen once", func() {
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		Expect(recorder.Calls("InconsistentClusterState")).To(Equal(0))
+	})
+	It("should resync cluster state when enabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ResyncStateOnInconsistency: lo.ToPtr(true)}))
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		Expect(recorder.Calls("InconsistentClusterState")).To(Equal(1))
+
+		inconsistencies, err := cluster.Inconsistencies(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inconsistencies).To(BeEmpty())
+	})
+	It("should not resync cluster state when disabled", func() {
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+		ExpectReconcileSucceeded(ctx, consistencyController, client.ObjectKey{})
+
+		inconsistencies, err := cluster.Inconsistencies(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inconsistencies).To(HaveLen(1))
+	})
+})
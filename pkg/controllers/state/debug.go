@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// DebugStateNode is the view of a StateNode that's served for troubleshooting. It includes what the scheduler needs to
+// know about the node, so that scheduling can be replayed against the nodes offline.
+type DebugStateNode struct {
+	Name              string            `json:"name"`
+	ProviderID        string            `json:"providerID,omitempty"`
+	NodePool          string            `json:"nodePool,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Taints            []v1.Taint        `json:"taints,omitempty"`
+	Capacity          v1.ResourceList   `json:"capacity,omitempty"`
+	Allocatable       v1.ResourceList   `json:"allocatable,omitempty"`
+	Requests          v1.ResourceList   `json:"requests,omitempty"`
+	MarkedForDeletion bool              `json:"markedForDeletion"`
+	Nominated         bool              `json:"nominated"`
+}
+
+// DebugNodes returns a view of every node tracked in cluster state, ordered by name
+func (c *Cluster) DebugNodes() []DebugStateNode {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	nodes := make([]DebugStateNode, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		nodes = append(nodes, DebugStateNode{
+			Name:              n.Name(),
+			ProviderID:        n.ProviderID(),
+			NodePool:          n.Labels()[v1beta1.NodePoolLabelKey],
+			Labels:            n.Labels(),
+			Taints:            n.Taints(),
+			Capacity:          n.Capacity(),
+			Allocatable:       n.Allocatable(),
+			Requests:          n.PodRequests(),
+			MarkedForDeletion: n.MarkedForDeletion(),
+			Nominated:         n.Nominated(),
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// DebugHandler serves the nodes tracked in cluster state as JSON
+func DebugHandler(c *Cluster) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.DebugNodes()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// maxInternedResourceLists bounds the number of distinct resource lists that we hold on to. Pods in a cluster
+// typically share a small number of request shapes, so it's rare to hit this. When we do, we drop the table and
+// start over; lists that were handed out before the reset are still valid, they just stop being shared.
+const maxInternedResourceLists = 10_000
+
+// resourceListInterner canonicalizes resource lists so that identical requests and limits across pods share
+// the same backing map rather than each pod holding its own copy. Lists returned from Intern are shared and
+// must be treated as read-only.
+type resourceListInterner struct {
+	mu      sync.Mutex
+	entries map[string]v1.ResourceList
+}
+
+func newResourceListInterner() *resourceListInterner {
+	return &resourceListInterner{entries: map[string]v1.ResourceList{}}
+}
+
+// podResources is the interner used for all pod requests and limits tracked in cluster state
+var podResources = newResourceListInterner()
+
+// Intern returns a canonical resource list that is equal to the passed list
+func (i *resourceListInterner) Intern(resourceList v1.ResourceList) v1.ResourceList {
+	key := resourceListKey(resourceList)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if interned, ok := i.entries[key]; ok {
+		return interned
+	}
+	if len(i.entries) >= maxInternedResourceLists {
+		i.entries = map[string]v1.ResourceList{}
+	}
+	i.entries[key] = resourceList
+	return resourceList
+}
+
+// resourceListKey builds a key that is stable regardless of map iteration order. Quantities are keyed by their
+// canonical string form so that semantically equal quantities (e.g. "1000m" and "1") map to the same entry.
+func resourceListKey(resourceList v1.ResourceList) string {
+	names := make([]string, 0, len(resourceList))
+	for name := range resourceList {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		quantity := resourceList[v1.ResourceName(name)]
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.WriteString(quantity.String())
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
@@ -0,0 +1,200 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// launchBackoffBase is how long launches for a nodepool back off after its first failure. The backoff doubles with
+	// each consecutive failure, up to launchBackoffMax.
+	launchBackoffBase = 10 * time.Second
+	launchBackoffMax  = 5 * time.Minute
+	// UnhealthyLaunchFailureThreshold is the number of consecutive launch failures after which a nodepool is
+	// considered unhealthy
+	UnhealthyLaunchFailureThreshold = 3
+	// insufficientCapacityTTL is how long a nodepool is considered to be out of capacity after a launch from it fails
+	// with an insufficient capacity error
+	insufficientCapacityTTL = 3 * time.Minute
+)
+
+type launchFailures struct {
+	count                  int
+	backoffUntil           time.Time
+	insufficientCapacityAt time.Time
+}
+
+type scaleUpStall struct {
+	since time.Time
+	// pods are the pending pods that the nodepool couldn't launch capacity for. The scale-up is no longer stalled once
+	// none of them are pending, even if no scheduling run confirms it.
+	pods sets.Set[types.NamespacedName]
+}
+
+// RecordLaunchFailure records that a NodeClaim from the nodepool failed to launch, and backs off further launches for
+// the nodepool exponentially in the number of consecutive failures
+func (c *Cluster) RecordLaunchFailure(nodePoolName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok {
+		failures = &launchFailures{}
+		c.launchFailures[nodePoolName] = failures
+	}
+	failures.count++
+	backoff := launchBackoffMax
+	// Guard the shift so that it can't overflow for nodepools that have been failing for a long time
+	if failures.count < 16 {
+		backoff = min(launchBackoffBase<<(failures.count-1), launchBackoffMax)
+	}
+	failures.backoffUntil = c.clock.Now().Add(backoff)
+}
+
+// RecordRetryableLaunchFailure records that a NodeClaim from the nodepool failed to launch like RecordLaunchFailure,
+// but backs off further launches for the nodepool for the delay that the cloudprovider asked for rather than
+// exponentially
+func (c *Cluster) RecordRetryableLaunchFailure(nodePoolName string, retryAfter time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok {
+		failures = &launchFailures{}
+		c.launchFailures[nodePoolName] = failures
+	}
+	failures.count++
+	failures.backoffUntil = c.clock.Now().Add(retryAfter)
+}
+
+// DelayLaunches holds back launches for the nodepool for at least the delay, e.g. when the cloudprovider throttled its
+// requests. Unlike RecordLaunchFailure, this doesn't count as a failed launch.
+func (c *Cluster) DelayLaunches(nodePoolName string, delay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok {
+		failures = &launchFailures{}
+		c.launchFailures[nodePoolName] = failures
+	}
+	if until := c.clock.Now().Add(delay); until.After(failures.backoffUntil) {
+		failures.backoffUntil = until
+	}
+}
+
+// RecordInsufficientCapacity records that a NodeClaim from the nodepool failed to launch because the cloudprovider
+// didn't have capacity for it. The failure itself is recorded separately with RecordLaunchFailure.
+func (c *Cluster) RecordInsufficientCapacity(nodePoolName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok {
+		failures = &launchFailures{}
+		c.launchFailures[nodePoolName] = failures
+	}
+	failures.insufficientCapacityAt = c.clock.Now()
+}
+
+// RecordLaunchSuccess records that a NodeClaim from the nodepool launched, which resets its launch failures and shows
+// that the nodepool has capacity again
+func (c *Cluster) RecordLaunchSuccess(nodePoolName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.launchFailures, nodePoolName)
+}
+
+// LaunchBackoff returns how much longer launches for the nodepool should wait after its most recent launch failure
+func (c *Cluster) LaunchBackoff(nodePoolName string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok {
+		return 0
+	}
+	return max(failures.backoffUntil.Sub(c.clock.Now()), 0)
+}
+
+// ConsecutiveLaunchFailures returns the number of launches for the nodepool that have failed since its last successful
+// launch
+func (c *Cluster) ConsecutiveLaunchFailures(nodePoolName string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if failures, ok := c.launchFailures[nodePoolName]; ok {
+		return failures.count
+	}
+	return 0
+}
+
+// IsNodePoolUnhealthy returns whether enough of the nodepool's launches have failed in a row that the nodepool is
+// considered unhealthy. Launches are still attempted once the backoff expires, and the first that succeeds makes the
+// nodepool healthy again.
+func (c *Cluster) IsNodePoolUnhealthy(nodePoolName string) bool {
+	return c.ConsecutiveLaunchFailures(nodePoolName) >= UnhealthyLaunchFailureThreshold
+}
+
+// HasInsufficientCapacity returns whether a launch from the nodepool recently failed with an insufficient capacity
+// error, and no launch from the nodepool has succeeded since
+func (c *Cluster) HasInsufficientCapacity(nodePoolName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	failures, ok := c.launchFailures[nodePoolName]
+	if !ok || failures.insufficientCapacityAt.IsZero() {
+		return false
+	}
+	return c.clock.Since(failures.insufficientCapacityAt) < insufficientCapacityTTL
+}
+
+// RecordStalledScaleUps records the nodepools that a scheduling run couldn't launch capacity for pending pods from,
+// because their limits are exhausted or none of their instance types have capacity, keyed by nodepool name along with
+// the pods. Nodepools that aren't passed are no longer stalled.
+func (c *Cluster) RecordStalledScaleUps(stalled map[string]sets.Set[types.NamespacedName]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.scaleUpStalls {
+		if _, ok := stalled[name]; !ok {
+			delete(c.scaleUpStalls, name)
+		}
+	}
+	for name, pods := range stalled {
+		if stall, ok := c.scaleUpStalls[name]; ok && stall.pods.Len() > 0 {
+			stall.pods = pods.Clone()
+			continue
+		}
+		c.scaleUpStalls[name] = &scaleUpStall{since: c.clock.Now(), pods: pods.Clone()}
+	}
+}
+
+// ScaleUpStalledFor returns how long the nodepool has been unable to launch capacity for pending pods, and whether
+// its scale-up is stalled at all, which it is while any of the pods that it couldn't launch capacity for are pending
+func (c *Cluster) ScaleUpStalledFor(nodePoolName string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stall, ok := c.scaleUpStalls[nodePoolName]
+	if !ok || stall.pods.Len() == 0 {
+		return 0, false
+	}
+	return c.clock.Since(stall.since), true
+}
+
+// resolveScaleUpStalls drops the pod from the stalled scale-ups once it's no longer pending
+func (c *Cluster) resolveScaleUpStalls(podKey types.NamespacedName) {
+	for _, stall := range c.scaleUpStalls {
+		stall.pods.Delete(podKey)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// LimitReservation holds a share of a nodepool's limits for a NodeClaim from when it's created until it's launched.
+// Until then, cluster state doesn't know the capacity of the NodeClaim, so without the reservation concurrent
+// scheduling rounds and launches would each see the same headroom under the nodepool's limits.
+type LimitReservation struct {
+	nodePoolName  string
+	nodeClaimName string
+	resources     v1.ResourceList
+}
+
+// ReserveLimits reserves the resources for a NodeClaim from the nodepool. It returns an error without reserving
+// anything if the resources don't fit in the headroom under the nodepool's limits that isn't used by its nodes or held
+// by other reservations. A NodeClaim that replaces nodes is checked as if the capacity of the nodes it replaces had
+// already been removed. Nodepools without limits aren't tracked, so a nil reservation is returned for them.
+func (c *Cluster) ReserveLimits(nodePool *v1beta1.NodePool, requested v1.ResourceList, replacedProviderIDs ...string) (*LimitReservation, error) {
+	if len(nodePool.Spec.Limits) == 0 {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usage := resources.Merge(c.reservedLimits(nodePool.Name), requested)
+	if u, ok := c.nodePoolUsage[nodePool.Name]; ok {
+		usage = resources.MergeInto(usage, u.Capacity)
+	}
+	for _, id := range replacedProviderIDs {
+		if replaced, ok := c.nodeUsage[id]; ok && replaced.nodePoolName == nodePool.Name {
+			usage = resources.Subtract(usage, replaced.capacity)
+		}
+	}
+	if err := nodePool.Spec.Limits.ExceededBy(usage); err != nil {
+		return nil, fmt.Errorf("reserving limits, %w", err)
+	}
+	reservation := &LimitReservation{nodePoolName: nodePool.Name, resources: requested}
+	c.pendingLimitReservations[reservation] = struct{}{}
+	return reservation, nil
+}
+
+// BindLimitReservation ties the reservation to the NodeClaim that was created for it, and must be called once cluster
+// state has been updated with the NodeClaim. The reservation is released once cluster state sees the NodeClaim launch,
+// since its capacity is counted from then on, or once the NodeClaim is deleted.
+func (c *Cluster) BindLimitReservation(reservation *LimitReservation, nodeClaimName string) {
+	if reservation == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pendingLimitReservations, reservation)
+	// The NodeClaim may have already launched or been deleted, e.g. when its launch or deletion raced with the creation
+	// call returning, in which case nothing would release the reservation
+	if _, ok := c.unlaunchedNodeClaims[nodeClaimName]; !ok {
+		return
+	}
+	reservation.nodeClaimName = nodeClaimName
+	c.limitReservations[nodeClaimName] = reservation
+}
+
+// ReleaseLimitReservation releases the reservation, e.g. when the NodeClaim it's held for couldn't be created
+func (c *Cluster) ReleaseLimitReservation(reservation *LimitReservation) {
+	if reservation == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pendingLimitReservations, reservation)
+	c.releaseLimitReservation(reservation.nodeClaimName)
+}
+
+// ReservedLimits returns the sum of the resources that are reserved from the nodepool for NodeClaims that haven't
+// launched yet
+func (c *Cluster) ReservedLimits(nodePoolName string) v1.ResourceList {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reservedLimits(nodePoolName)
+}
+
+func (c *Cluster) reservedLimits(nodePoolName string) v1.ResourceList {
+	reserved := v1.ResourceList{}
+	for r := range c.pendingLimitReservations {
+		if r.nodePoolName == nodePoolName {
+			reserved = resources.MergeInto(reserved, r.resources)
+		}
+	}
+	for _, r := range c.limitReservations {
+		if r.nodePoolName == nodePoolName {
+			reserved = resources.MergeInto(reserved, r.resources)
+		}
+	}
+	return reserved
+}
+
+// releaseLimitReservation releases the reservation that's bound to the NodeClaim, if any. Callers must hold the lock.
+func (c *Cluster) releaseLimitReservation(nodeClaimName string) {
+	delete(c.limitReservations, nodeClaimName)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+)
+
+const (
+	stateSubsystem = "cluster_state"
+)
+
+var (
+	nominatedNodesGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: stateSubsystem,
+			Name:      "nominated_nodes",
+			Help:      "Current count of nodes in cluster state that are nominated for pending pods, labeled by nodepool.",
+		},
+		[]string{metrics.NodePoolLabel},
+	)
+	inflightNodeClaimsGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: stateSubsystem,
+			Name:      "inflight_nodeclaims",
+			Help:      "Current count of nodeclaims in cluster state that have launched but haven't initialized, labeled by nodepool.",
+		},
+		[]string{metrics.NodePoolLabel},
+	)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(nominatedNodesGaugeVec, inflightNodeClaimsGaugeVec)
+}
+
+// Controller periodically publishes metrics about the nominated and inflight capacity in cluster state
+type Controller struct {
+	cluster     *state.Cluster
+	metricStore *metrics.Store
+}
+
+func NewController(cluster *state.Cluster) operatorcontroller.Controller {
+	return &Controller{
+		cluster:     cluster,
+		metricStore: metrics.NewStore(),
+	}
+}
+
+func (c *Controller) Name() string {
+	return "state.metrics"
+}
+
+func (c *Controller) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	nodePools := sets.New[string]()
+	nominated := map[string]int{}
+	inflight := map[string]int{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		nodePoolName, ok := n.Labels()[v1beta1.NodePoolLabelKey]
+		if !ok {
+			return true
+		}
+		nodePools.Insert(nodePoolName)
+		if n.Nominated() {
+			nominated[nodePoolName]++
+		}
+		if n.NodeClaim != nil && n.NodeClaim.StatusConditions().GetCondition(v1beta1.Launched).IsTrue() && !n.Initialized() {
+			inflight[nodePoolName]++
+		}
+		return true
+	})
+	// Every nodepool that has nodes gets both metrics, so that they drop to zero rather than disappearing
+	store := map[string][]*metrics.StoreMetric{}
+	for nodePoolName := range nodePools {
+		store[nodePoolName] = []*metrics.StoreMetric{
+			{
+				GaugeVec: nominatedNodesGaugeVec,
+				Value:    float64(nominated[nodePoolName]),
+				Labels:   prometheus.Labels{metrics.NodePoolLabel: nodePoolName},
+			},
+			{
+				GaugeVec: inflightNodeClaimsGaugeVec,
+				Value:    float64(inflight[nodePoolName]),
+				Labels:   prometheus.Labels{metrics.NodePoolLabel: nodePoolName},
+			},
+		}
+	}
+	c.metricStore.ReplaceAll(store)
+	return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
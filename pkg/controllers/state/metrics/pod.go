@@ -0,0 +1,112 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+)
+
+var (
+	PodUnschedulableDurationHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "pods",
+			Name:      "unschedulable_duration_seconds",
+			Help:      "The time from a pod being marked unschedulable until it's bound to a node.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+	)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(PodUnschedulableDurationHistogram)
+}
+
+// PodController observes how long pods stay unschedulable before they're bound to a node
+type PodController struct {
+	clock      clock.Clock
+	kubeClient client.Client
+
+	mu            sync.Mutex
+	unschedulable map[string]time.Time // pod key -> when the pod was marked unschedulable
+}
+
+func NewPodController(clk clock.Clock, kubeClient client.Client) operatorcontroller.Controller {
+	return &PodController{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		unschedulable: map[string]time.Time{},
+	}
+}
+
+func (c *PodController) Name() string {
+	return "state.metrics.pod"
+}
+
+func (c *PodController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pod := &v1.Pod{}
+	if err := c.kubeClient.Get(ctx, req.NamespacedName, pod); err != nil {
+		if errors.IsNotFound(err) {
+			c.mu.Lock()
+			delete(c.unschedulable, req.NamespacedName.String())
+			c.mu.Unlock()
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	key := req.NamespacedName.String()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pod.Spec.NodeName != "" {
+		if since, ok := c.unschedulable[key]; ok {
+			PodUnschedulableDurationHistogram.Observe(c.clock.Since(since).Seconds())
+			delete(c.unschedulable, key)
+		}
+		return reconcile.Result{}, nil
+	}
+	cond, ok := lo.Find(pod.Status.Conditions, func(c v1.PodCondition) bool {
+		return c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable
+	})
+	if _, tracked := c.unschedulable[key]; ok && !tracked {
+		c.unschedulable[key] = lo.Ternary(cond.LastTransitionTime.IsZero(), c.clock.Now(), cond.LastTransitionTime.Time)
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *PodController) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		For(&v1.Pod{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
+	)
+}
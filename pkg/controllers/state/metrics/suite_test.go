@@ -0,0 +1,189 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	statemetrics "sigs.k8s.io/karpenter/pkg/controllers/state/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var metricsController controller.Controller
+var podController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cluster *state.Cluster
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "State/Metrics")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cluster = state.NewCluster(fakeClock, env.Client, fake.NewCloudProvider())
+	metricsController = statemetrics.NewController(cluster)
+	podController = statemetrics.NewPodController(fakeClock, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cluster.Reset()
+	fakeClock.SetTime(time.Now())
+})
+
+var _ = Describe("Metrics", func() {
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+	})
+	Context("Nominated Nodes", func() {
+		It("should count the nominated nodes for each nodepool", func() {
+			nodeClaims := []*v1beta1.NodeClaim{
+				test.NodeClaim(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+					Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+				}),
+				test.NodeClaim(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+					Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+				}),
+			}
+			for _, nodeClaim := range nodeClaims {
+				cluster.UpdateNodeClaim(nodeClaim)
+			}
+			cluster.NominateNodeForPod(ctx, nodeClaims[0].Status.ProviderID)
+
+			ExpectReconcileSucceeded(ctx, metricsController, client.ObjectKey{})
+			ExpectMetricGaugeValue("karpenter_cluster_state_nominated_nodes", 1, map[string]string{"nodepool": nodePool.Name})
+		})
+		It("should report zero nominated nodes for a nodepool without nominations", func() {
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			cluster.UpdateNodeClaim(nodeClaim)
+			ExpectReconcileSucceeded(ctx, metricsController, client.ObjectKey{})
+			ExpectMetricGaugeValue("karpenter_cluster_state_nominated_nodes", 0, map[string]string{"nodepool": nodePool.Name})
+		})
+	})
+	Context("Inflight NodeClaims", func() {
+		It("should count nodeclaims that have launched but not initialized", func() {
+			launched := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			launched.StatusConditions().MarkTrue(v1beta1.Launched)
+			initialized := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			initialized.StatusConditions().MarkTrue(v1beta1.Launched)
+			initialized.StatusConditions().MarkTrue(v1beta1.Registered)
+			initialized.StatusConditions().MarkTrue(v1beta1.Initialized)
+			pending := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			for _, nodeClaim := range []*v1beta1.NodeClaim{launched, initialized, pending} {
+				cluster.UpdateNodeClaim(nodeClaim)
+			}
+
+			ExpectReconcileSucceeded(ctx, metricsController, client.ObjectKey{})
+			ExpectMetricGaugeValue("karpenter_cluster_state_inflight_nodeclaims", 1, map[string]string{"nodepool": nodePool.Name})
+		})
+		It("should remove the metric once the nodepool has no nodes", func() {
+			nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+				Status:     v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			nodeClaim.StatusConditions().MarkTrue(v1beta1.Launched)
+			cluster.UpdateNodeClaim(nodeClaim)
+			ExpectReconcileSucceeded(ctx, metricsController, client.ObjectKey{})
+			ExpectMetricGaugeValue("karpenter_cluster_state_inflight_nodeclaims", 1, map[string]string{"nodepool": nodePool.Name})
+
+			cluster.DeleteNodeClaim(nodeClaim.Name)
+			ExpectReconcileSucceeded(ctx, metricsController, client.ObjectKey{})
+			_, found := FindMetricWithLabelValues("karpenter_cluster_state_inflight_nodeclaims", map[string]string{"nodepool": nodePool.Name})
+			Expect(found).To(BeFalse())
+		})
+	})
+	Context("Unschedulable Duration", func() {
+		It("should observe how long a pod was unschedulable once it's bound", func() {
+			before := unschedulableDurationSampleCount()
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+			node := test.Node()
+			ExpectApplied(ctx, env.Client, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+			Expect(unschedulableDurationSampleCount()).To(Equal(before + 1))
+
+			// A pod is only observed once
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+			Expect(unschedulableDurationSampleCount()).To(Equal(before + 1))
+		})
+		It("should not observe pods that were never unschedulable", func() {
+			before := unschedulableDurationSampleCount()
+			node := test.Node()
+			pod := test.Pod(test.PodOptions{NodeName: node.Name})
+			ExpectApplied(ctx, env.Client, node, pod)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+			Expect(unschedulableDurationSampleCount()).To(Equal(before))
+		})
+	})
+})
+
+func unschedulableDurationSampleCount() uint64 {
+	GinkgoHelper()
+	m, ok := FindMetricWithLabelValues("karpenter_pods_unschedulable_duration_seconds", map[string]string{})
+	if !ok {
+		return 0
+	}
+	return m.GetHistogram().GetSampleCount()
+}
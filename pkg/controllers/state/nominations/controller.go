@@ -0,0 +1,212 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nominations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/multierr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	operatorcontroller "sigs.k8s.io/karpenter/pkg/operator/controller"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+const (
+	nominationsKey = "nominations"
+	// persistPeriod is how often nominations are persisted. It's shorter than the minimum nomination window so that
+	// every nomination is persisted before it can expire.
+	persistPeriod = 5 * time.Second
+)
+
+// ConfigMapName returns the name of the ConfigMap in Karpenter's namespace that nominations are persisted to. It's scoped
+// to the shard of nodepools that this instance of Karpenter manages, since each shard nominates its own nodes.
+func ConfigMapName(ctx context.Context) string {
+	return nodepoolutil.ShardScopedName(ctx, "karpenter-nominations")
+}
+
+// Controller persists the nodes that were nominated for pending pods so that they survive restarts. Without this,
+// a restarted controller can consider a node that's about to receive pods as empty and disrupt it, only to provision
+// a replacement for the same pods. Nominations are also rebuilt from the nomination annotations of pods, which the
+// controller removes once they've expired.
+type Controller struct {
+	clock      clock.Clock
+	kubeClient client.Client
+	cluster    *state.Cluster
+	restored   bool
+	persisted  map[string]time.Time
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster) operatorcontroller.Controller {
+	return &Controller{
+		clock:      clk,
+		kubeClient: kubeClient,
+		cluster:    cluster,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "state.nominations"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	if !c.restored {
+		if err := c.restore(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		c.restored = true
+	}
+	if err := c.persist(ctx); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := c.clearExpiredPodNominations(ctx); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: persistPeriod}, nil
+}
+
+// restore nominates the nodes that were persisted or annotated on pods before we restarted
+func (c *Controller) restore(ctx context.Context) error {
+	nominations, err := c.podNominations(ctx)
+	if err != nil {
+		return err
+	}
+	cm := &v1.ConfigMap{}
+	if err = c.kubeClient.Get(ctx, client.ObjectKey{Namespace: system.Namespace(), Name: ConfigMapName(ctx)}, cm); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	persisted := map[string]time.Time{}
+	if err = json.Unmarshal([]byte(cm.Data[nominationsKey]), &persisted); err != nil && cm.Data[nominationsKey] != "" {
+		// The nominations are short-lived, so if they can't be read we're better off dropping them than blocking
+		logging.FromContext(ctx).Errorf("unmarshaling persisted nominations, %s", err)
+	}
+	for providerID, until := range persisted {
+		if until.After(nominations[providerID]) {
+			nominations[providerID] = until
+		}
+	}
+	if len(nominations) == 0 {
+		return nil
+	}
+	c.cluster.RestoreNominations(nominations)
+	c.persisted = persisted
+	logging.FromContext(ctx).With("nodes", len(nominations)).Debugf("restored nominations")
+	return nil
+}
+
+// podNominations returns the latest nomination expiry of each node that pods are annotated as nominated to. Only the
+// pods that aren't bound yet are listed, since the nominations of the pods that were bound have served their purpose.
+func (c *Controller) podNominations(ctx context.Context) (map[string]time.Time, error) {
+	podList := &v1.PodList{}
+	if err := c.kubeClient.List(ctx, podList, client.MatchingFields{"spec.nodeName": ""}); err != nil {
+		return nil, fmt.Errorf("listing pods, %w", err)
+	}
+	nominations := map[string]time.Time{}
+	for i := range podList.Items {
+		providerID, until, ok := podutil.Nomination(&podList.Items[i])
+		if ok && until.After(nominations[providerID]) {
+			nominations[providerID] = until
+		}
+	}
+	return nominations, nil
+}
+
+// clearExpiredPodNominations removes the nomination annotations of pods whose nominations have expired. The pods are
+// found through cluster state, so that we don't list every pod in the cluster each time nominations are persisted.
+func (c *Controller) clearExpiredPodNominations(ctx context.Context) error {
+	var errs error
+	for podKey, until := range c.cluster.PodNominations() {
+		if until.After(c.clock.Now()) {
+			continue
+		}
+		pod := &v1.Pod{}
+		if err := c.kubeClient.Get(ctx, podKey, pod); err != nil {
+			errs = multierr.Append(errs, client.IgnoreNotFound(err))
+			continue
+		}
+		// The pod may have been nominated again since cluster state last saw it
+		if _, ok := pod.Annotations[v1beta1.NominatedNodeAnnotationKey]; !ok {
+			continue
+		}
+		if _, until, ok := podutil.Nomination(pod); ok && until.After(c.clock.Now()) {
+			continue
+		}
+		stored := pod.DeepCopy()
+		delete(pod.Annotations, v1beta1.NominatedNodeAnnotationKey)
+		delete(pod.Annotations, v1beta1.NominatedUntilAnnotationKey)
+		if err := c.kubeClient.Patch(ctx, pod, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			errs = multierr.Append(errs, fmt.Errorf("clearing nomination of pod %s, %w", client.ObjectKeyFromObject(pod), err))
+		}
+	}
+	return errs
+}
+
+// persist writes the current nominations to the ConfigMap if they've changed since they were last written
+func (c *Controller) persist(ctx context.Context) error {
+	nominations := c.cluster.Nominations()
+	if equal(nominations, c.persisted) {
+		return nil
+	}
+	data, err := json.Marshal(nominations)
+	if err != nil {
+		return fmt.Errorf("marshaling nominations, %w", err)
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: ConfigMapName(ctx)},
+		Data:       map[string]string{nominationsKey: string(data)},
+	}
+	if err = c.kubeClient.Update(ctx, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("updating nominations configmap, %w", err)
+		}
+		if err = c.kubeClient.Create(ctx, cm); err != nil {
+			return fmt.Errorf("creating nominations configmap, %w", err)
+		}
+	}
+	c.persisted = nominations
+	return nil
+}
+
+func equal(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for providerID, until := range a {
+		if other, ok := b[providerID]; !ok || !until.Equal(other) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) operatorcontroller.Builder {
+	return operatorcontroller.NewSingletonManagedBy(m)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nominations_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/nominations"
+	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var nominationsController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cluster *state.Cluster
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Nominations")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cluster = state.NewCluster(fakeClock, env.Client, fake.NewCloudProvider())
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Nominations", func() {
+	var nodeClaim *v1beta1.NodeClaim
+
+	BeforeEach(func() {
+		// Nominations are only restored once for each controller, so each test gets a fresh one
+		nominationsController = nominations.NewController(fakeClock, env.Client, cluster)
+		nodeClaim = test.NodeClaim(v1beta1.NodeClaim{Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()}})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		Expect(client.IgnoreNotFound(env.Client.Delete(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(ctx)}}))).To(Succeed())
+		cluster.Reset()
+	})
+
+	It("should persist nominated nodes", func() {
+		cluster.UpdateNodeClaim(nodeClaim)
+		cluster.NominateNodeForPod(ctx, nodeClaim.Status.ProviderID)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+
+		persisted := ExpectPersistedNominations()
+		Expect(persisted).To(HaveKey(nodeClaim.Status.ProviderID))
+		Expect(persisted[nodeClaim.Status.ProviderID]).To(BeTemporally("~", cluster.Nominations()[nodeClaim.Status.ProviderID], time.Second))
+	})
+	It("should not persist anything if no nodes are nominated", func() {
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(ctx)}})
+	})
+	It("should restore persisted nominations for nodes that are added after a restart", func() {
+		ExpectPersistNominations(map[string]time.Time{nodeClaim.Status.ProviderID: time.Now().Add(time.Minute)})
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeFalse())
+
+		cluster.UpdateNodeClaim(nodeClaim)
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeTrue())
+	})
+	It("should not restore nominations that have expired", func() {
+		ExpectPersistNominations(map[string]time.Time{nodeClaim.Status.ProviderID: time.Now().Add(-time.Minute)})
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should restore nominations from the nomination annotations of pods", func() {
+		until := time.Now().Add(time.Minute).Truncate(time.Second)
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			v1beta1.NominatedNodeAnnotationKey:  nodeClaim.Status.ProviderID,
+			v1beta1.NominatedUntilAnnotationKey: until.Format(time.RFC3339),
+		}}})
+		ExpectApplied(ctx, env.Client, pod)
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeTrue())
+		Expect(cluster.Nominations()[nodeClaim.Status.ProviderID]).To(BeTemporally("==", until))
+		Expect(ExpectExists(ctx, env.Client, pod).Annotations).To(HaveKey(v1beta1.NominatedNodeAnnotationKey))
+	})
+	It("should not restore nominations from the nomination annotations of pods that are bound", func() {
+		node := test.Node()
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1beta1.NominatedNodeAnnotationKey:  nodeClaim.Status.ProviderID,
+				v1beta1.NominatedUntilAnnotationKey: time.Now().Add(time.Minute).Format(time.RFC3339),
+			}},
+			NodeName: node.Name,
+		})
+		ExpectApplied(ctx, env.Client, node, pod)
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should remove the nomination annotations of pods once their nominations have expired", func() {
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			v1beta1.NominatedNodeAnnotationKey:  nodeClaim.Status.ProviderID,
+			v1beta1.NominatedUntilAnnotationKey: fakeClock.Now().Add(-time.Minute).Format(time.RFC3339),
+		}}})
+		ExpectApplied(ctx, env.Client, pod)
+		Expect(cluster.UpdatePod(ctx, pod)).To(Succeed())
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(cluster.IsNodeNominated(nodeClaim.Status.ProviderID)).To(BeFalse())
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Annotations).ToNot(HaveKey(v1beta1.NominatedNodeAnnotationKey))
+		Expect(pod.Annotations).ToNot(HaveKey(v1beta1.NominatedUntilAnnotationKey))
+	})
+	It("should keep the nomination annotations of pods until their nominations expire", func() {
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			v1beta1.NominatedNodeAnnotationKey:  nodeClaim.Status.ProviderID,
+			v1beta1.NominatedUntilAnnotationKey: fakeClock.Now().Add(time.Minute).Format(time.RFC3339),
+		}}})
+		ExpectApplied(ctx, env.Client, pod)
+		Expect(cluster.UpdatePod(ctx, pod)).To(Succeed())
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(ExpectExists(ctx, env.Client, pod).Annotations).To(HaveKey(v1beta1.NominatedNodeAnnotationKey))
+
+		fakeClock.Step(2 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(ExpectExists(ctx, env.Client, pod).Annotations).ToNot(HaveKey(v1beta1.NominatedNodeAnnotationKey))
+	})
+	It("should only clear the nominations of pods that cluster state tracks", func() {
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			v1beta1.NominatedNodeAnnotationKey:  nodeClaim.Status.ProviderID,
+			v1beta1.NominatedUntilAnnotationKey: fakeClock.Now().Add(-time.Minute).Format(time.RFC3339),
+		}}})
+		ExpectApplied(ctx, env.Client, pod)
+
+		ExpectReconcileSucceeded(ctx, nominationsController, client.ObjectKey{})
+		Expect(ExpectExists(ctx, env.Client, pod).Annotations).To(HaveKey(v1beta1.NominatedNodeAnnotationKey))
+	})
+	It("should persist nominations to a ConfigMap for the shard of nodepools", func() {
+		shardCtx := options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr("team=a")}))
+		Expect(nominations.ConfigMapName(shardCtx)).ToNot(Equal(nominations.ConfigMapName(ctx)))
+		cluster.UpdateNodeClaim(nodeClaim)
+		cluster.NominateNodeForPod(ctx, nodeClaim.Status.ProviderID)
+
+		ExpectReconcileSucceeded(shardCtx, nominationsController, client.ObjectKey{})
+		ExpectExists(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(shardCtx)}})
+		ExpectNotFound(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(ctx)}})
+		Expect(env.Client.Delete(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(shardCtx)}})).To(Succeed())
+	})
+})
+
+func ExpectPersistNominations(n map[string]time.Time) {
+	GinkgoHelper()
+	data, err := json.Marshal(n)
+	Expect(err).ToNot(HaveOccurred())
+	ExpectApplied(ctx, env.Client, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(ctx)},
+		Data:       map[string]string{"nominations": string(data)},
+	})
+}
+
+func ExpectPersistedNominations() map[string]time.Time {
+	GinkgoHelper()
+	cm := ExpectExists(ctx, env.Client, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: nominations.ConfigMapName(ctx)}})
+	n := map[string]time.Time{}
+	Expect(json.Unmarshal([]byte(cm.Data["nominations"]), &n)).To(Succeed())
+	return n
+}
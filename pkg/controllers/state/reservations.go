@@ -0,0 +1,92 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// Reservation identifies the capacity reservations of an instance type in a zone
+type Reservation struct {
+	InstanceType string
+	Zone         string
+}
+
+// RemainingReservations returns the number of nodes that can still be launched into the capacity reservations of the
+// instance types, keyed by the instance type and zone of the reservations. The cloudprovider supplies the capacity of
+// the reservations through the instance types' offerings, and every node in cluster state that was launched into them
+// takes up a slot until it's removed from cluster state, including nodes that are marked for deletion. NodeClaims that
+// haven't launched yet take up a slot in the reservation that they're expected to launch into, so that back-to-back
+// scheduling runs don't oversubscribe the reservations.
+func (c *Cluster) RemainingReservations(instanceTypes []*cloudprovider.InstanceType) map[Reservation]int {
+	remaining := map[Reservation]int{}
+	for _, instanceType := range instanceTypes {
+		for _, offering := range instanceType.Offerings.Available() {
+			if offering.CapacityType == v1beta1.CapacityTypeReserved {
+				remaining[Reservation{InstanceType: instanceType.Name, Zone: offering.Zone}] = offering.ReservationCapacity
+			}
+		}
+	}
+	if len(remaining) == 0 {
+		return remaining
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var reservations []Reservation
+	for _, n := range c.nodes {
+		if capacityType, ok := n.CapacityType(); !ok || capacityType != v1beta1.CapacityTypeReserved {
+			continue
+		}
+		reservations = append(reservations, Reservation{InstanceType: n.Labels()[v1.LabelInstanceTypeStable], Zone: n.Labels()[v1.LabelTopologyZone]})
+	}
+	for _, nodeClaim := range c.unlaunchedNodeClaims {
+		if reservation, ok := ReservationFor(instanceTypes, scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)); ok {
+			reservations = append(reservations, reservation)
+		}
+	}
+	for _, reservation := range reservations {
+		if slots, ok := remaining[reservation]; ok {
+			remaining[reservation] = max(slots-1, 0)
+		}
+	}
+	return remaining
+}
+
+// ReservationFor returns the capacity reservation that a NodeClaim with the requirements is expected to launch into,
+// if it's allowed to launch into one. The cloudprovider launches into the cheapest of the compatible offerings of the
+// instance types, so that's the reservation that's returned.
+func ReservationFor(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements) (Reservation, bool) {
+	var reservation Reservation
+	var price float64
+	found := false
+	for _, instanceType := range instanceTypes {
+		if !requirements.Get(v1.LabelInstanceTypeStable).Has(instanceType.Name) {
+			continue
+		}
+		for _, offering := range instanceType.Offerings.Available().Compatible(requirements) {
+			if offering.CapacityType != v1beta1.CapacityTypeReserved || (found && offering.Price >= price) {
+				continue
+			}
+			reservation, price, found = Reservation{InstanceType: instanceType.Name, Zone: offering.Zone}, offering.Price, true
+		}
+	}
+	return reservation, found
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Snapshot is a point-in-time view of the cluster state. Everything in a Snapshot is deep-copied from the cluster
+// state, so it can be held and inspected for as long as needed without blocking updates to the cluster state.
+// Changes made to a Snapshot are not reflected back into the cluster state.
+type Snapshot struct {
+	// Nodes are deep copies of all tracked state nodes
+	Nodes StateNodes
+	// Bindings maps a bound pod's namespaced name to the name of the node it is bound to
+	Bindings map[types.NamespacedName]string
+	// DaemonSetPods maps a daemonset's namespaced name to the most recently created pod for that daemonset,
+	// which is used to compute daemonset overhead on new nodes
+	DaemonSetPods map[types.NamespacedName]*v1.Pod
+}
+
+// Snapshot returns a deep-copied, point-in-time view of the cluster state. The cluster state lock is only held while
+// the copy is taken, so callers that need to iterate over the state for a long time should prefer this over ForEachNode.
+// NOTE: This is very inefficient so this should only be used when DeepCopying is absolutely necessary
+func (c *Cluster) Snapshot() *Snapshot {
+	c.mu.RLock()
+	nodes := lo.Map(lo.Values(c.nodes), func(n *StateNode, _ int) *StateNode {
+		return n.DeepCopy()
+	})
+	bindings := lo.Assign(c.bindings)
+	c.mu.RUnlock()
+
+	daemonSetPods := map[types.NamespacedName]*v1.Pod{}
+	c.daemonSetPods.Range(func(k, v any) bool {
+		daemonSetPods[k.(types.NamespacedName)] = v.(*v1.Pod).DeepCopy()
+		return true
+	})
+	return &Snapshot{
+		Nodes:         nodes,
+		Bindings:      bindings,
+		DaemonSetPods: daemonSetPods,
+	}
+}
+
+// NodeForPod returns the state node that the pod with the given namespaced name is bound to in the snapshot
+func (s *Snapshot) NodeForPod(podKey types.NamespacedName) (*StateNode, bool) {
+	nodeName, ok := s.Bindings[podKey]
+	if !ok {
+		return nil, false
+	}
+	return lo.Find(s.Nodes, func(n *StateNode) bool {
+		return n.Node != nil && n.Node.Name == nodeName
+	})
+}
@@ -19,12 +19,14 @@ package state
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,10 +45,10 @@ import (
 // nolint: revive
 type StateNodes []*StateNode
 
-// Active filters StateNodes that are not in a MarkedForDeletion state
+// Active filters StateNodes that are not in a MarkedForDeletion state and aren't held in a warm pool
 func (n StateNodes) Active() StateNodes {
 	return lo.Filter(n, func(node *StateNode, _ int) bool {
-		return !node.MarkedForDeletion()
+		return !node.MarkedForDeletion() && !node.Warm()
 	})
 }
 
@@ -96,6 +98,8 @@ type StateNode struct {
 	daemonSetRequests map[types.NamespacedName]v1.ResourceList
 	daemonSetLimits   map[types.NamespacedName]v1.ResourceList
 
+	// podRequests and podLimits hold interned resource lists that may be shared with other pods and nodes. These
+	// lists must not be modified in place.
 	podRequests map[types.NamespacedName]v1.ResourceList
 	podLimits   map[types.NamespacedName]v1.ResourceList
 
@@ -195,6 +199,26 @@ func (in *StateNode) Labels() map[string]string {
 	return in.Node.Labels
 }
 
+// CapacityType returns the capacity type of the node. NodeClaims that don't have the capacity type label yet take it
+// from their requirements if they only allow one.
+func (in *StateNode) CapacityType() (string, bool) {
+	if capacityType, ok := in.Labels()[v1beta1.CapacityTypeLabelKey]; ok {
+		return capacityType, true
+	}
+	if in.NodeClaim == nil {
+		return "", false
+	}
+	return nodeClaimCapacityType(in.NodeClaim)
+}
+
+// nodeClaimCapacityType returns the capacity type of the NodeClaim if its requirements only allow one
+func nodeClaimCapacityType(nodeClaim *v1beta1.NodeClaim) (string, bool) {
+	if requirement := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...).Get(v1beta1.CapacityTypeLabelKey); requirement.Len() == 1 {
+		return requirement.Any(), true
+	}
+	return "", false
+}
+
 func (in *StateNode) Taints() []v1.Taint {
 	// If we have a managed node that isn't registered, we should use its NodeClaim
 	// representation of taints. Likewise, if we don't have a Node representation for this
@@ -281,7 +305,34 @@ func (in *StateNode) Allocatable() v1.ResourceList {
 
 // Available is allocatable minus anything allocated to pods.
 func (in *StateNode) Available() v1.ResourceList {
-	return resources.Subtract(in.Allocatable(), in.PodRequests())
+	return resources.Subtract(in.Allocatable(), resources.Merge(in.PodRequests(), in.Reserved()))
+}
+
+// Reserved returns the resources of the node's allocatable that are held back from scheduling. These are the resources
+// that the NodeClaim reserves, and the ones reserved through the reserved resources annotation, e.g. for pods that an
+// external scheduler is binding to the node. Annotation entries that can't be parsed are ignored.
+func (in *StateNode) Reserved() v1.ResourceList {
+	var reserved v1.ResourceList
+	if in.NodeClaim != nil {
+		reserved = in.NodeClaim.Spec.Resources.Reserved
+	}
+	value, ok := in.Annotations()[v1beta1.ReservedResourcesAnnotationKey]
+	if !ok {
+		return reserved
+	}
+	annotated := v1.ResourceList{}
+	for _, entry := range strings.Split(value, ",") {
+		name, quantity, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		q, err := resource.ParseQuantity(strings.TrimSpace(quantity))
+		if err != nil || q.Sign() < 0 {
+			continue
+		}
+		annotated[v1.ResourceName(strings.TrimSpace(name))] = q
+	}
+	return resources.Merge(reserved, annotated)
 }
 
 func (in *StateNode) DaemonSetRequests() v1.ResourceList {
@@ -326,6 +377,13 @@ func (in *StateNode) Nominate(ctx context.Context) {
 	in.nominatedUntil = metav1.Time{Time: time.Now().Add(nominationWindow(ctx))}
 }
 
+// restoreNomination extends the node's nomination to a nomination that was made before a restart
+func (in *StateNode) restoreNomination(until time.Time) {
+	if until.After(in.nominatedUntil.Time) {
+		in.nominatedUntil = metav1.Time{Time: until}
+	}
+}
+
 func (in *StateNode) Nominated() bool {
 	return in.nominatedUntil.After(time.Now())
 }
@@ -334,6 +392,40 @@ func (in *StateNode) Managed() bool {
 	return in.NodeClaim != nil
 }
 
+// Warm returns whether the node is held in its nodepool's warm pool. Warm nodes aren't available to pods until
+// they're claimed from the warm pool.
+func (in *StateNode) Warm() bool {
+	return in.NodeClaim != nil && in.NodeClaim.Annotations[v1beta1.WarmPoolAnnotationKey] == "true"
+}
+
+// IsCompatible returns why the pod can't schedule to the node, or nil if it can, without modifying the node. It checks
+// the node's taints, labels, host ports, volume limits and available resources, but not the pod's (anti-)affinity to
+// other pods or its topology spread, which depend on the rest of the cluster. Pods that are already bound to the node
+// count against its available resources, so they're only compatible if there's room for them twice. PreferNoSchedule
+// taints are ignored, since they don't keep kube-scheduler from binding the pod to the node. The volumes are the pod's
+// volumes, as returned by scheduling.GetVolumes, so that callers that check a pod against many nodes resolve them once.
+func (in *StateNode) IsCompatible(pod *v1.Pod, volumes scheduling.Volumes) error {
+	if err := scheduling.HardTaints(in.Taints()).Tolerates(pod); err != nil {
+		return err
+	}
+	requirements := scheduling.NewLabelRequirements(in.Labels())
+	requirements.Add(scheduling.NewRequirement(v1.LabelHostname, v1.NodeSelectorOpIn, in.HostName()))
+	// Preferred node affinities don't prevent the pod from scheduling to the node
+	if err := requirements.Compatible(scheduling.NewStrictPodRequirements(pod)); err != nil {
+		return err
+	}
+	if err := in.HostPortUsage().Conflicts(pod); err != nil {
+		return fmt.Errorf("checking host port usage, %w", err)
+	}
+	if err := in.VolumeUsage().ExceedsLimits(volumes); err != nil {
+		return fmt.Errorf("checking volume usage, %w", err)
+	}
+	if !resources.Fits(resources.RequestsForPods(pod), in.Available()) {
+		return fmt.Errorf("exceeds node resources")
+	}
+	return nil
+}
+
 func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client, pod *v1.Pod) error {
 	podKey := client.ObjectKeyFromObject(pod)
 	hostPorts := scheduling.GetHostPorts(pod)
@@ -341,12 +433,15 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 	if err != nil {
 		return fmt.Errorf("tracking volume usage, %w", err)
 	}
-	in.podRequests[podKey] = resources.RequestsForPods(pod)
-	in.podLimits[podKey] = resources.LimitsForPods(pod)
+	// Pods commonly share the same requests and limits, so we intern them to avoid holding a copy per pod
+	requests := podResources.Intern(resources.RequestsForPods(pod))
+	limits := podResources.Intern(resources.LimitsForPods(pod))
+	in.podRequests[podKey] = requests
+	in.podLimits[podKey] = limits
 	// if it's a daemonset, we track what it has requested separately
 	if podutils.IsOwnedByDaemonSet(pod) {
-		in.daemonSetRequests[podKey] = resources.RequestsForPods(pod)
-		in.daemonSetLimits[podKey] = resources.LimitsForPods(pod)
+		in.daemonSetRequests[podKey] = requests
+		in.daemonSetLimits[podKey] = limits
 	}
 	in.hostPortUsage.Add(pod, hostPorts)
 	in.volumeUsage.Add(pod, volumes)
@@ -398,9 +493,10 @@ func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTa
 		}
 		stored := node.DeepCopy()
 		// If the taint is present and we want to remove the taint, remove it.
+		// The PreferNoSchedule taint that's added ahead of the disruption is managed by RequirePreferNoScheduleTaint
 		if !addTaint {
 			node.Spec.Taints = lo.Reject(node.Spec.Taints, func(taint v1.Taint, _ int) bool {
-				return taint.Key == v1beta1.DisruptionTaintKey
+				return taint.Key == v1beta1.DisruptionTaintKey && taint.Effect != v1.TaintEffectPreferNoSchedule
 			})
 			// otherwise, add it.
 		} else if addTaint && !hasTaint {
@@ -418,3 +514,41 @@ func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTa
 	}
 	return multiErr
 }
+
+// RequirePreferNoScheduleTaint will add/remove the karpenter.sh/disruption:PreferNoSchedule taint from the candidates.
+// It's added for a grace window before the candidates are tainted with RequireNoScheduleTaint, which replaces it.
+func RequirePreferNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTaint bool, nodes ...*StateNode) error {
+	var multiErr error
+	for _, n := range nodes {
+		if n.Node == nil || n.NodeClaim == nil {
+			continue
+		}
+		node := &v1.Node{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: n.Node.Name}, node); err != nil {
+			multiErr = multierr.Append(multiErr, client.IgnoreNotFound(fmt.Errorf("getting node, %w", err)))
+			continue
+		}
+		// Nodes that are being deleted or already disrupting are left to the termination and disruption controllers
+		if !node.DeletionTimestamp.IsZero() || lo.ContainsBy(node.Spec.Taints, v1beta1.IsDisruptingTaint) {
+			continue
+		}
+		hasTaint := lo.ContainsBy(node.Spec.Taints, func(taint v1.Taint) bool {
+			return taint.MatchTaint(&v1beta1.DisruptionPreferNoScheduleTaint)
+		})
+		if hasTaint == addTaint {
+			continue
+		}
+		stored := node.DeepCopy()
+		if addTaint {
+			node.Spec.Taints = append(node.Spec.Taints, v1beta1.DisruptionPreferNoScheduleTaint)
+		} else {
+			node.Spec.Taints = lo.Reject(node.Spec.Taints, func(taint v1.Taint, _ int) bool {
+				return taint.MatchTaint(&v1beta1.DisruptionPreferNoScheduleTaint)
+			})
+		}
+		if err := kubeClient.Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+			multiErr = multierr.Append(multiErr, fmt.Errorf("patching node %s, %w", node.Name, err))
+		}
+	}
+	return multiErr
+}
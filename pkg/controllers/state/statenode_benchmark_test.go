@@ -0,0 +1,114 @@
+//go:build test_performance
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+// To run the benchmarks use:
+// `go test -tags=test_performance -run=XXX -bench=StateNode -benchmem`
+//
+// The "heap-bytes/pod" metric reports the retained heap per pod tracked in cluster state, which is the number
+// to compare when changing how pod requests and limits are stored.
+func BenchmarkStateNodePodTracking1000(b *testing.B) {
+	benchmarkStateNodePodTracking(b, 10, 1000)
+}
+func BenchmarkStateNodePodTracking10000(b *testing.B) {
+	benchmarkStateNodePodTracking(b, 100, 10000)
+}
+
+func benchmarkStateNodePodTracking(b *testing.B, nodeCount, podCount int) {
+	ctx := context.Background()
+	kubeClient := fakecr.NewClientBuilder().WithIndex(&v1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+		return []string{o.(*v1.Pod).Spec.NodeName}
+	}).Build()
+	cloudProvider := fake.NewCloudProvider()
+
+	var nodes []*v1.Node
+	for i := 0; i < nodeCount; i++ {
+		nodes = append(nodes, test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+			ProviderID: fmt.Sprintf("fake:///node-%d", i),
+		}))
+	}
+	// A handful of distinct request shapes, which mirrors most workloads where pods come from a small number of
+	// deployments
+	shapes := []v1.ResourceList{
+		{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("128Mi")},
+		{v1.ResourceCPU: resource.MustParse("250m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+		{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("512Mi")},
+		{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	var pods []*v1.Pod
+	for i := 0; i < podCount; i++ {
+		pods = append(pods, test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"},
+			NodeName:   nodes[i%nodeCount].Name,
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: shapes[i%len(shapes)],
+				Limits:   shapes[i%len(shapes)],
+			},
+		}))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	var heapBytes uint64
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		cluster := state.NewCluster(&clock.RealClock{}, kubeClient, cloudProvider)
+		for _, node := range nodes {
+			if err := cluster.UpdateNode(ctx, node); err != nil {
+				b.Fatalf("updating node, %s", err)
+			}
+		}
+		for _, pod := range pods {
+			if err := cluster.UpdatePod(ctx, pod); err != nil {
+				b.Fatalf("updating pod, %s", err)
+			}
+		}
+
+		runtime.GC()
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		if after.HeapAlloc > before.HeapAlloc {
+			heapBytes += after.HeapAlloc - before.HeapAlloc
+		}
+		runtime.KeepAlive(cluster)
+	}
+	b.ReportMetric(float64(heapBytes)/float64(b.N)/float64(podCount), "heap-bytes/pod")
+}
@@ -18,14 +18,17 @@ package state_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
-	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
 	storagev1 "k8s.io/api/storage/v1"
-	"k8s.io/apimachinery/pkg/util/sets"
 	cloudproviderapi "k8s.io/cloud-provider/api"
 	clock "k8s.io/utils/clock/testing"
 	"knative.dev/pkg/ptr"
@@ -158,9 +161,7 @@ var _ = Describe("Volume Usage/Limits", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding more volumes should cause an error since we are at the volume limits
-		Expect(stateNode.VolumeUsage().ExceedsLimits(scheduling.Volumes{
-			csiProvider: sets.New("test"),
-		})).ToNot(BeNil())
+		Expect(stateNode.VolumeUsage().ExceedsLimits(podVolumes(volumePod(sc)))).ToNot(BeNil())
 	})
 	It("should maintain the volume usage state when receiving NodeClaim updates", func() {
 		ExpectApplied(ctx, env.Client, sc, nodeClaim, node, csiNode)
@@ -180,21 +181,17 @@ var _ = Describe("Volume Usage/Limits", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding more volumes should cause an error since we are at the volume limits
-		Expect(stateNode.VolumeUsage().ExceedsLimits(scheduling.Volumes{
-			csiProvider: sets.New("test"),
-		})).ToNot(BeNil())
+		Expect(stateNode.VolumeUsage().ExceedsLimits(podVolumes(volumePod(sc)))).ToNot(BeNil())
 
 		// Reconcile the nodeclaim one more time to ensure that we maintain our volume usage state
 		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
 
 		// Ensure that we still consider adding another volume to the node breaching our volume limits
-		Expect(stateNode.VolumeUsage().ExceedsLimits(scheduling.Volumes{
-			csiProvider: sets.New("test"),
-		})).ToNot(BeNil())
+		Expect(stateNode.VolumeUsage().ExceedsLimits(podVolumes(volumePod(sc)))).ToNot(BeNil())
 	})
 	It("should ignore the volume usage limits breach if the pod update is for an already tracked pod", func() {
 		ExpectApplied(ctx, env.Client, sc, nodeClaim, node, csiNode)
-		var pvcs []*v1.PersistentVolumeClaim
+		var pods []*v1.Pod
 		for i := 0; i < 10; i++ {
 			pvc := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
 				StorageClassName: ptr.String(sc.Name),
@@ -202,7 +199,7 @@ var _ = Describe("Volume Usage/Limits", func() {
 			pod := test.Pod(test.PodOptions{
 				PersistentVolumeClaims: []string{pvc.Name},
 			})
-			pvcs = append(pvcs, pvc)
+			pods = append(pods, pod)
 			ExpectApplied(ctx, env.Client, pvc, pod)
 			ExpectManualBinding(ctx, env.Client, pod, node)
 		}
@@ -212,12 +209,39 @@ var _ = Describe("Volume Usage/Limits", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding more volumes should not cause an error since this PVC volume is already tracked
-		Expect(stateNode.VolumeUsage().ExceedsLimits(scheduling.Volumes{
-			csiProvider: sets.New(client.ObjectKeyFromObject(pvcs[5]).String()),
-		})).To(BeNil())
+		Expect(stateNode.VolumeUsage().ExceedsLimits(podVolumes(pods[5]))).To(BeNil())
+	})
+	It("should not exceed the volume limits for a pod without volumes", func() {
+		ExpectApplied(ctx, env.Client, sc, node, csiNode)
+		for i := 0; i < 10; i++ {
+			pod := volumePod(sc)
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+		}
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		stateNode := ExpectStateNodeExists(cluster, node)
+
+		Expect(stateNode.VolumeUsage().ExceedsLimits(podVolumes(test.Pod()))).To(BeNil())
 	})
 })
 
+// volumePod returns a pod that mounts a new volume from the storage class
+func podVolumes(pod *v1.Pod) scheduling.Volumes {
+	GinkgoHelper()
+	return lo.Must(scheduling.GetVolumes(ctx, env.Client, pod))
+}
+
+func volumePod(sc *storagev1.StorageClass) *v1.Pod {
+	GinkgoHelper()
+	pvc := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{
+		StorageClassName: ptr.String(sc.Name),
+	})
+	ExpectApplied(ctx, env.Client, pvc)
+	return test.Pod(test.PodOptions{
+		PersistentVolumeClaims: []string{pvc.Name},
+	})
+}
+
 var _ = Describe("HostPort Usage", func() {
 	var nodeClaim *v1beta1.NodeClaim
 	var node *v1.Node
@@ -248,13 +272,7 @@ var _ = Describe("HostPort Usage", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding a conflicting host port should cause an error
-		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(), []scheduling.HostPort{
-			{
-				IP:       net.IP("0.0.0.0"),
-				Port:     int32(5),
-				Protocol: v1.ProtocolTCP,
-			},
-		})).ToNot(BeNil())
+		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(test.PodOptions{HostPorts: []int32{5}}))).ToNot(BeNil())
 	})
 	It("should maintain the host port usage state when receiving NodeClaim updates", func() {
 		ExpectApplied(ctx, env.Client, node)
@@ -272,25 +290,13 @@ var _ = Describe("HostPort Usage", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding a conflicting host port should cause an error
-		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(), []scheduling.HostPort{
-			{
-				IP:       net.IP("0.0.0.0"),
-				Port:     int32(5),
-				Protocol: v1.ProtocolTCP,
-			},
-		})).ToNot(BeNil())
+		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(test.PodOptions{HostPorts: []int32{5}}))).ToNot(BeNil())
 
 		// Reconcile the nodeclaim one more time to ensure that we maintain our volume usage state
 		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
 
 		// Ensure that we still consider the host port usage addition an error
-		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(), []scheduling.HostPort{
-			{
-				IP:       net.IP("0.0.0.0"),
-				Port:     int32(5),
-				Protocol: v1.ProtocolTCP,
-			},
-		})).ToNot(BeNil())
+		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(test.PodOptions{HostPorts: []int32{5}}))).ToNot(BeNil())
 	})
 	It("should ignore the host port usage conflict if the pod update is for an already tracked pod", func() {
 		ExpectApplied(ctx, env.Client, node)
@@ -310,13 +316,101 @@ var _ = Describe("HostPort Usage", func() {
 		stateNode := ExpectStateNodeExists(cluster, node)
 
 		// Adding a conflicting host port should not cause an error since this port is already tracked for the pod
-		Expect(stateNode.HostPortUsage().Conflicts(pods[5], []scheduling.HostPort{
-			{
-				IP:       net.IP("0.0.0.0"),
-				Port:     int32(5),
-				Protocol: v1.ProtocolTCP,
+		Expect(stateNode.HostPortUsage().Conflicts(pods[5])).To(BeNil())
+	})
+	It("should describe every host port that conflicts", func() {
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		var pods []*v1.Pod
+		for i := 0; i < 10; i++ {
+			pod := test.Pod(test.PodOptions{
+				HostPorts: []int32{int32(i)},
+			})
+			pods = append(pods, pod)
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+		}
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		stateNode := ExpectStateNodeExists(cluster, node)
+
+		err := stateNode.HostPortUsage().Conflicts(test.Pod(test.PodOptions{HostPorts: []int32{3, 5, 20}}))
+		Expect(multierr.Errors(err)).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring(client.ObjectKeyFromObject(pods[3]).String()))
+		Expect(err.Error()).To(ContainSubstring(client.ObjectKeyFromObject(pods[5]).String()))
+		Expect(stateNode.HostPortUsage().Conflicts(test.Pod(test.PodOptions{HostPorts: []int32{20}}))).To(BeNil())
+	})
+})
+
+var _ = Describe("Pod Compatibility", func() {
+	var node *v1.Node
+	var stateNode *state.StateNode
+	BeforeEach(func() {
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelTopologyZone: "test-zone-1"}},
+			Taints:     []v1.Taint{{Key: "test-taint", Value: "true", Effect: v1.TaintEffectNoSchedule}},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("4"),
+				v1.ResourcePods: resource.MustParse("10"),
 			},
-		})).To(BeNil())
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		bound := test.Pod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			HostPorts:            []int32{80},
+			Tolerations:          []v1.Toleration{{Key: "test-taint", Operator: v1.TolerationOpExists}},
+		})
+		ExpectApplied(ctx, env.Client, bound)
+		ExpectManualBinding(ctx, env.Client, bound, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(bound))
+		stateNode = ExpectStateNodeExists(cluster, node)
+	})
+	compatiblePod := func(opts ...test.PodOptions) *v1.Pod {
+		return test.Pod(append([]test.PodOptions{{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			Tolerations:          []v1.Toleration{{Key: "test-taint", Operator: v1.TolerationOpExists}},
+			NodeSelector:         map[string]string{v1.LabelTopologyZone: "test-zone-1"},
+		}}, opts...)...)
+	}
+	It("should be compatible with a pod that fits the node", func() {
+		Expect(stateNode.IsCompatible(compatiblePod(), nil)).To(Succeed())
+	})
+	It("should not be compatible with a pod that doesn't tolerate the node's taints", func() {
+		pod := compatiblePod()
+		pod.Spec.Tolerations = nil
+		Expect(stateNode.IsCompatible(pod, nil)).ToNot(Succeed())
+	})
+	It("should be compatible with a pod that doesn't tolerate the node's PreferNoSchedule taints", func() {
+		node.Spec.Taints = append(node.Spec.Taints, v1beta1.DisruptionPreferNoScheduleTaint)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		Expect(ExpectStateNodeExists(cluster, node).IsCompatible(compatiblePod(), nil)).To(Succeed())
+	})
+	It("should not be compatible with a pod that doesn't select the node's labels", func() {
+		pod := compatiblePod()
+		pod.Spec.NodeSelector = map[string]string{v1.LabelTopologyZone: "test-zone-2"}
+		Expect(stateNode.IsCompatible(pod, nil)).ToNot(Succeed())
+	})
+	It("should not be compatible with a pod whose host ports conflict", func() {
+		Expect(stateNode.IsCompatible(compatiblePod(test.PodOptions{HostPorts: []int32{80}}), nil)).ToNot(Succeed())
+	})
+	It("should not be compatible with a pod that exceeds the node's available resources", func() {
+		pod := compatiblePod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+		})
+		Expect(stateNode.IsCompatible(pod, nil)).ToNot(Succeed())
+	})
+	It("should be compatible with a pod whose preferred node affinity doesn't match", func() {
+		pod := compatiblePod(test.PodOptions{
+			NodePreferences: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+		})
+		Expect(stateNode.IsCompatible(pod, nil)).To(Succeed())
+	})
+	It("should not modify the node", func() {
+		available := stateNode.Available()
+		Expect(stateNode.IsCompatible(compatiblePod(test.PodOptions{HostPorts: []int32{81}}), nil)).To(Succeed())
+		Expect(stateNode.Available()).To(Equal(available))
+		Expect(stateNode.HostPortUsage().Conflicts(compatiblePod(test.PodOptions{HostPorts: []int32{81}}))).To(Succeed())
 	})
 })
 
@@ -581,6 +675,83 @@ var _ = Describe("Node Resource Level", func() {
 			return true
 		})
 	})
+	It("should subtract the reserved resources of nodes from their available resources", func() {
+		pod := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("1.5"),
+				}},
+		})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				},
+				Annotations: map[string]string{
+					// Entries that can't be parsed are ignored
+					v1beta1.ReservedResourcesAnnotationKey: "cpu=1, memory=1Gi,pods,nvidia.com/gpu=invalid",
+				},
+			},
+			Allocatable: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+		cluster.ForEachNode(func(n *state.StateNode) bool {
+			ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}, n.Reserved())
+			ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1.5"), v1.ResourceMemory: resource.MustParse("3Gi")}, n.Available())
+			return true
+		})
+
+		// Removing the annotation releases the reserved resources
+		delete(node.Annotations, v1beta1.ReservedResourcesAnnotationKey)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		cluster.ForEachNode(func(n *state.StateNode) bool {
+			Expect(n.Reserved()).To(BeEmpty())
+			ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("2.5"), v1.ResourceMemory: resource.MustParse("4Gi")}, n.Available())
+			return true
+		})
+	})
+	It("should subtract the resources that the NodeClaim reserves from the available resources", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				},
+				Annotations: map[string]string{v1beta1.ReservedResourcesAnnotationKey: "cpu=1"},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				Resources: v1beta1.ResourceRequirements{
+					Reserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		cluster.ForEachNode(func(n *state.StateNode) bool {
+			ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1.5"), v1.ResourceMemory: resource.MustParse("1Gi")}, n.Reserved())
+			ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("2.5"), v1.ResourceMemory: resource.MustParse("3Gi")}, n.Available())
+			return true
+		})
+	})
 	It("should track pods correctly if we miss events or they are consolidated", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
 			ObjectMeta: metav1.ObjectMeta{Name: "stateful-set-pod"},
@@ -932,6 +1103,66 @@ var _ = Describe("Node Resource Level", func() {
 	})
 })
 
+var _ = Describe("Debug State", func() {
+	var node *v1.Node
+	var pod *v1.Pod
+
+	BeforeEach(func() {
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Capacity: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU: resource.MustParse("4"),
+			},
+			Allocatable: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU: resource.MustParse("3.5"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		pod = test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("1.5"),
+				}},
+		})
+		ExpectApplied(ctx, env.Client, node, pod)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+	})
+	It("should describe the nodes in cluster state", func() {
+		cluster.NominateNodeForPod(ctx, node.Spec.ProviderID)
+		cluster.MarkForDeletion(node.Spec.ProviderID)
+
+		nodes := cluster.DebugNodes()
+		Expect(nodes).To(HaveLen(1))
+		Expect(nodes[0].Name).To(Equal(node.Name))
+		Expect(nodes[0].ProviderID).To(Equal(node.Spec.ProviderID))
+		Expect(nodes[0].NodePool).To(Equal(nodePool.Name))
+		Expect(nodes[0].Labels).To(HaveKeyWithValue(v1beta1.NodePoolLabelKey, nodePool.Name))
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}, nodes[0].Capacity)
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("3.5")}, nodes[0].Allocatable)
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1.5")}, nodes[0].Requests)
+		Expect(nodes[0].MarkedForDeletion).To(BeTrue())
+		Expect(nodes[0].Nominated).To(BeTrue())
+	})
+	It("should serve the nodes in cluster state as JSON", func() {
+		recorder := httptest.NewRecorder()
+		state.DebugHandler(cluster).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/state", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var nodes []state.DebugStateNode
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &nodes)).To(Succeed())
+		Expect(nodes).To(HaveLen(1))
+		Expect(nodes[0].Name).To(Equal(node.Name))
+		Expect(nodes[0].NodePool).To(Equal(nodePool.Name))
+		Expect(nodes[0].MarkedForDeletion).To(BeFalse())
+	})
+})
+
 var _ = Describe("Pod Anti-Affinity", func() {
 	It("should track pods with required anti-affinity", func() {
 		pod := test.UnschedulablePod(test.PodOptions{
@@ -1307,6 +1538,55 @@ var _ = Describe("Cluster State Sync", func() {
 	})
 })
 
+var _ = Describe("Sharding", func() {
+	var shardCtx context.Context
+
+	BeforeEach(func() {
+		shardCtx = options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr("team=a")}))
+	})
+	It("should track nodes from nodepools in another shard without managing them", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name, v1.LabelInstanceTypeStable: "default-instance-type", "team": "b"},
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(shardCtx, nodeController, client.ObjectKeyFromObject(node))
+		Expect(cluster.Nodes()).To(HaveLen(1))
+		Expect(ExpectStateNodeExists(cluster, node).Managed()).To(BeFalse())
+		Expect(cluster.Synced(shardCtx)).To(BeTrue())
+	})
+	It("should track nodes from nodepools in its shard and nodes that aren't from a nodepool", func() {
+		managed := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name, v1.LabelInstanceTypeStable: "default-instance-type", "team": "a"},
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		unmanaged := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+		ExpectApplied(ctx, env.Client, managed, unmanaged)
+		ExpectReconcileSucceeded(shardCtx, nodeController, client.ObjectKeyFromObject(managed))
+		ExpectReconcileSucceeded(shardCtx, nodeController, client.ObjectKeyFromObject(unmanaged))
+		Expect(cluster.Nodes()).To(HaveLen(2))
+		Expect(cluster.Synced(shardCtx)).To(BeTrue())
+	})
+	It("should account for the pods bound to nodes in another shard", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name, v1.LabelInstanceTypeStable: "default-instance-type", "team": "b"},
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		pod := test.Pod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}})
+		ExpectApplied(ctx, env.Client, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(shardCtx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(shardCtx, podController, client.ObjectKeyFromObject(pod))
+		Expect(ExpectStateNodeExists(cluster, node).PodRequests()).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("1")))
+	})
+})
+
 var _ = Describe("DaemonSet Controller", func() {
 	It("should not update daemonsetCache when daemonset pod is not present", func() {
 		daemonset := test.DaemonSet(
@@ -1462,6 +1742,432 @@ var _ = Describe("Consolidated State", func() {
 		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
 		Expect(cluster.ConsolidationState()).ToNot(Equal(state))
 	})
+	It("should increase the revision when setting consolidation", func() {
+		revision := cluster.Revision()
+		Expect(cluster.Revision()).To(Equal(revision))
+
+		cluster.MarkUnconsolidated()
+		Expect(cluster.Revision()).To(BeNumerically(">", revision))
+	})
+	It("should increase the revision when consolidation timeout (5m) has passed and state hasn't changed", func() {
+		revision := cluster.Revision()
+
+		fakeClock.Step(time.Minute * 4)
+		Expect(cluster.Revision()).To(Equal(revision))
+
+		fakeClock.Step(time.Minute * 2)
+		Expect(cluster.Revision()).To(BeNumerically(">", revision))
+	})
+	It("should increase the revision when a pod is bound to a node", func() {
+		node := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, node, pod)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		revision := cluster.Revision()
+
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		Expect(cluster.Revision()).To(BeNumerically(">", revision))
+	})
+})
+
+var _ = Describe("NodePool Usage", func() {
+	It("should aggregate capacity, allocatable and requests by nodepool", func() {
+		pod := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("1"),
+				}},
+		})
+		nodes := lo.Times(2, func(_ int) *v1.Node {
+			return test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				}},
+				Capacity: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("4"),
+				},
+				Allocatable: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("3"),
+				},
+				ProviderID: test.RandomProviderID(),
+			})
+		})
+		unmanaged := test.Node(test.NodeOptions{
+			Capacity: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU: resource.MustParse("8"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, nodes[0], nodes[1], unmanaged)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[0]))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[1]))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(unmanaged))
+		ExpectManualBinding(ctx, env.Client, pod, nodes[0])
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+		usage := cluster.NodePoolUsage()
+		Expect(usage).To(HaveLen(1))
+		Expect(usage[nodePool.Name].Nodes).To(Equal(2))
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}, usage[nodePool.Name].Capacity)
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")}, usage[nodePool.Name].Allocatable)
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, usage[nodePool.Name].Requests)
+	})
+	It("should not count nodes that are marked for deletion", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Capacity: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU: resource.MustParse("4"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		Expect(cluster.NodePoolUsage()[nodePool.Name].Nodes).To(Equal(1))
+
+		cluster.MarkForDeletion(node.Spec.ProviderID)
+		Expect(cluster.NodePoolUsage()).ToNot(HaveKey(nodePool.Name))
+	})
+	It("should count the capacity types of nodes and of nodeclaims without the capacity type label", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:     nodePool.Name,
+				v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot,
+			}},
+			ProviderID: test.RandomProviderID(),
+		})
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Spec: v1beta1.NodeClaimSpec{
+				Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1beta1.CapacityTypeLabelKey,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{v1beta1.CapacityTypeOnDemand},
+					},
+				}},
+			},
+			Status: v1beta1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		ExpectApplied(ctx, env.Client, node, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		Expect(cluster.NodePoolUsage()[nodePool.Name].CapacityTypes).To(Equal(map[string]int{
+			v1beta1.CapacityTypeSpot:     1,
+			v1beta1.CapacityTypeOnDemand: 1,
+		}))
+	})
+	It("should count the capacity types of nodeclaims that haven't launched yet", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+			Spec: v1beta1.NodeClaimSpec{
+				Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1beta1.CapacityTypeLabelKey,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{v1beta1.CapacityTypeSpot},
+					},
+				}},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(cluster.NodePoolUsage()[nodePool.Name].CapacityTypes).To(Equal(map[string]int{v1beta1.CapacityTypeSpot: 1}))
+
+		// Once it launches, it's counted as a node rather than as an in-flight nodeclaim
+		nodeClaim.Status.ProviderID = test.RandomProviderID()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(cluster.NodePoolUsage()[nodePool.Name].CapacityTypes).To(Equal(map[string]int{v1beta1.CapacityTypeSpot: 1}))
+
+		ExpectDeleted(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(cluster.NodePoolUsage()[nodePool.Name].CapacityTypes).To(BeNil())
+	})
+	It("should update the usage as pods and nodes change", func() {
+		pod := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("1"),
+				},
+			},
+		})
+		nodes := lo.Times(2, func(_ int) *v1.Node {
+			return test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				}},
+				Capacity: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("4"),
+				},
+				ProviderID: test.RandomProviderID(),
+			})
+		})
+		ExpectApplied(ctx, env.Client, pod, nodes[0], nodes[1])
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[0]))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[1]))
+		ExpectManualBinding(ctx, env.Client, pod, nodes[0])
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, cluster.NodePoolUsage()[nodePool.Name].Requests)
+
+		ExpectDeleted(ctx, env.Client, pod)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		Expect(cluster.NodePoolUsage()[nodePool.Name].Requests).To(BeEmpty())
+
+		cluster.MarkForDeletion(nodes[0].Spec.ProviderID)
+		Expect(cluster.NodePoolUsage()[nodePool.Name].Nodes).To(Equal(1))
+		cluster.UnmarkForDeletion(nodes[0].Spec.ProviderID)
+		Expect(cluster.NodePoolUsage()[nodePool.Name].Nodes).To(Equal(2))
+
+		ExpectDeleted(ctx, env.Client, nodes[0])
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[0]))
+		usage := cluster.NodePoolUsage()
+		Expect(usage[nodePool.Name].Nodes).To(Equal(1))
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}, usage[nodePool.Name].Capacity)
+
+		ExpectDeleted(ctx, env.Client, nodes[1])
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(nodes[1]))
+		Expect(cluster.NodePoolUsage()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Limit Reservations", func() {
+	cpu := func(quantity string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(quantity)}
+	}
+	BeforeEach(func() {
+		// Reset keeps the reservations, so each test reserves from its own nodepool
+		nodePool.Name = test.RandomName()
+		nodePool.Spec.Limits = v1beta1.Limits(cpu("10"))
+	})
+	It("should not track reservations for nodepools without limits", func() {
+		nodePool.Spec.Limits = nil
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("100"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservation).To(BeNil())
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should reject reservations that exceed the remaining headroom", func() {
+		_, err := cluster.ReserveLimits(nodePool, cpu("6"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = cluster.ReserveLimits(nodePool, cpu("6"))
+		Expect(err).To(HaveOccurred())
+		ExpectResources(cpu("6"), cluster.ReservedLimits(nodePool.Name))
+	})
+	It("should count the capacity of the nodepool's nodes against its limits", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Capacity:   cpu("8"),
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		_, err := cluster.ReserveLimits(nodePool, cpu("4"))
+		Expect(err).To(HaveOccurred())
+		_, err = cluster.ReserveLimits(nodePool, cpu("2"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should check replacements against the headroom without the capacity of the nodes they replace", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Capacity:   cpu("8"),
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		_, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).To(HaveOccurred())
+		_, err = cluster.ReserveLimits(nodePool, cpu("8"), node.Spec.ProviderID)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectResources(cpu("8"), cluster.ReservedLimits(nodePool.Name))
+		// The replacement's reservation is counted against later replacements of the same node
+		_, err = cluster.ReserveLimits(nodePool, cpu("4"), node.Spec.ProviderID)
+		Expect(err).To(HaveOccurred())
+	})
+	It("should not credit replaced nodes from other nodepools", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   "other",
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Capacity:   cpu("8"),
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		_, err := cluster.ReserveLimits(nodePool, cpu("12"), node.Spec.ProviderID)
+		Expect(err).To(HaveOccurred())
+	})
+	It("should release reservations that are released explicitly", func() {
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.ReleaseLimitReservation(reservation)
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should release reservations once their nodeclaim launches", func() {
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}},
+		})
+		nodeClaim.Status.ProviderID = ""
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.UpdateNodeClaim(nodeClaim)
+		cluster.BindLimitReservation(reservation, nodeClaim.Name)
+		ExpectResources(cpu("8"), cluster.ReservedLimits(nodePool.Name))
+
+		nodeClaim.Status.ProviderID = test.RandomProviderID()
+		nodeClaim.Status.Capacity = cpu("8")
+		cluster.UpdateNodeClaim(nodeClaim)
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should release reservations once their nodeclaim is deleted", func() {
+		nodeClaim := test.NodeClaim()
+		nodeClaim.Status.ProviderID = ""
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.UpdateNodeClaim(nodeClaim)
+		cluster.BindLimitReservation(reservation, nodeClaim.Name)
+		ExpectResources(cpu("8"), cluster.ReservedLimits(nodePool.Name))
+
+		cluster.DeleteNodeClaim(nodeClaim.Name)
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should only release the reservation of the nodeclaim that launches", func() {
+		nodeClaims := []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()}
+		for _, nodeClaim := range nodeClaims {
+			nodeClaim.Status.ProviderID = ""
+			reservation, err := cluster.ReserveLimits(nodePool, cpu("4"))
+			Expect(err).ToNot(HaveOccurred())
+			cluster.UpdateNodeClaim(nodeClaim)
+			cluster.BindLimitReservation(reservation, nodeClaim.Name)
+		}
+		ExpectResources(cpu("8"), cluster.ReservedLimits(nodePool.Name))
+
+		nodeClaims[0].Status.ProviderID = test.RandomProviderID()
+		cluster.UpdateNodeClaim(nodeClaims[0])
+		ExpectResources(cpu("4"), cluster.ReservedLimits(nodePool.Name))
+	})
+	It("should not hold reservations for nodeclaims that launched before they were bound", func() {
+		nodeClaim := test.NodeClaim()
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.UpdateNodeClaim(nodeClaim)
+
+		cluster.BindLimitReservation(reservation, nodeClaim.Name)
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should not hold reservations for nodeclaims that were deleted before they were bound", func() {
+		nodeClaim := test.NodeClaim()
+		nodeClaim.Status.ProviderID = ""
+		reservation, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.UpdateNodeClaim(nodeClaim)
+		cluster.DeleteNodeClaim(nodeClaim.Name)
+
+		cluster.BindLimitReservation(reservation, nodeClaim.Name)
+		Expect(cluster.ReservedLimits(nodePool.Name)).To(BeEmpty())
+	})
+	It("should keep reservations across a reset", func() {
+		_, err := cluster.ReserveLimits(nodePool, cpu("8"))
+		Expect(err).ToNot(HaveOccurred())
+		cluster.Reset()
+		ExpectResources(cpu("8"), cluster.ReservedLimits(nodePool.Name))
+	})
+})
+
+var _ = Describe("Snapshot", func() {
+	It("should include nodes and pod bindings", func() {
+		pod := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU: resource.MustParse("1"),
+				}},
+		})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Allocatable: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU: resource.MustParse("4"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+		snapshot := cluster.Snapshot()
+		Expect(snapshot.Nodes).To(HaveLen(1))
+		Expect(snapshot.Bindings).To(HaveKeyWithValue(client.ObjectKeyFromObject(pod), node.Name))
+		n, ok := snapshot.NodeForPod(client.ObjectKeyFromObject(pod))
+		Expect(ok).To(BeTrue())
+		Expect(n.Name()).To(Equal(node.Name))
+		ExpectResources(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, n.PodRequests())
+	})
+	It("should not be affected by later updates to cluster state", func() {
+		pod := test.UnschedulablePod()
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1beta1.NodePoolLabelKey:   nodePool.Name,
+				v1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		snapshot := cluster.Snapshot()
+		Expect(snapshot.Bindings).To(BeEmpty())
+
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		cluster.MarkForDeletion(node.Spec.ProviderID)
+
+		Expect(snapshot.Bindings).To(BeEmpty())
+		Expect(snapshot.Nodes[0].MarkedForDeletion()).To(BeFalse())
+		Expect(cluster.Snapshot().Bindings).To(HaveLen(1))
+	})
+	It("should include daemonset pods", func() {
+		daemonset := test.DaemonSet()
+		ExpectApplied(ctx, env.Client, daemonset)
+		daemonsetPod := test.UnschedulablePod(
+			test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "DaemonSet",
+							Name:               daemonset.Name,
+							UID:                daemonset.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					},
+				},
+			})
+		daemonsetPod.Spec = daemonset.Spec.Template.Spec
+		ExpectApplied(ctx, env.Client, daemonsetPod)
+		ExpectReconcileSucceeded(ctx, daemonsetController, client.ObjectKeyFromObject(daemonset))
+
+		Expect(cluster.Snapshot().DaemonSetPods).To(HaveKeyWithValue(client.ObjectKeyFromObject(daemonset), daemonsetPod))
+	})
 })
 
 var _ = Describe("Data Races", func() {
@@ -37,6 +37,10 @@ const (
 	ExpirationReason    = "expiration"
 	EmptinessReason     = "emptiness"
 	DriftReason         = "drift"
+	ResizeReason        = "resize"
+	RequestedReason     = "requested"
+	MinNodesReason      = "minnodes"
+	WarmPoolReason      = "warmpool"
 )
 
 // DurationBuckets returns a []float64 of default threshold values for duration histograms.
@@ -64,6 +64,17 @@ var (
 			NodePoolLabel,
 		},
 	)
+	NodeClaimsRegistrationTimeoutCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: nodeClaimSubsystem,
+			Name:      "registration_timeout",
+			Help:      "Number of nodeclaims deleted in total by Karpenter because their nodes didn't register within the registration timeout. Labeled by the owning nodepool.",
+		},
+		[]string{
+			NodePoolLabel,
+		},
+	)
 	NodeClaimsRegisteredCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: Namespace,
@@ -110,6 +121,18 @@ var (
 			NodePoolLabel,
 		},
 	)
+	NodeClaimsDriftedReasonsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: nodeClaimSubsystem,
+			Name:      "drifted_reasons",
+			Help:      "Number of specific reasons that nodeclaims drifted in total by Karpenter. Labeled by the drifted field or cloudprovider reason and the owning nodepool.",
+		},
+		[]string{
+			ReasonLabel,
+			NodePoolLabel,
+		},
+	)
 	NodesCreatedCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: Namespace,
@@ -136,6 +159,6 @@ var (
 
 func init() {
 	crmetrics.Registry.MustRegister(NodeClaimsCreatedCounter, NodeClaimsTerminatedCounter, NodeClaimsLaunchedCounter,
-		NodeClaimsRegisteredCounter, NodeClaimsInitializedCounter, NodeClaimsDisruptedCounter, NodeClaimsDriftedCounter,
+		NodeClaimsRegisteredCounter, NodeClaimsRegistrationTimeoutCounter, NodeClaimsInitializedCounter, NodeClaimsDisruptedCounter, NodeClaimsDriftedCounter, NodeClaimsDriftedReasonsCounter,
 		NodesCreatedCounter, NodesTerminatedCounter)
 }
@@ -0,0 +1,82 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+type kubeClient struct {
+	client.Client
+	injector *Injector
+}
+
+// DecorateClient returns a new `client.Client` instance that will delegate all method calls to the argument,
+// `kubeClient`, and inject the configured faults into its patches, including the patches of status subresources
+func DecorateClient(c client.Client, injector *Injector) client.Client {
+	return &kubeClient{Client: c, injector: injector}
+}
+
+func (c *kubeClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.injector.Inject(ctx, TargetPatch, c.kind(obj)); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *kubeClient) Status() client.SubResourceWriter {
+	return &subResourceWriter{SubResourceWriter: c.Client.Status(), client: c}
+}
+
+func (c *kubeClient) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{SubResourceClient: c.Client.SubResource(subResource), client: c}
+}
+
+func (c *kubeClient) kind(obj client.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return ""
+	}
+	return gvk.Kind
+}
+
+type subResourceWriter struct {
+	client.SubResourceWriter
+	client *kubeClient
+}
+
+func (w *subResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if err := w.client.injector.Inject(ctx, TargetPatch, w.client.kind(obj)); err != nil {
+		return err
+	}
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+type subResourceClient struct {
+	client.SubResourceClient
+	client *kubeClient
+}
+
+func (c *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if err := c.client.injector.Inject(ctx, TargetPatch, c.client.kind(obj)); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// decorator implements CloudProvider
+var _ cloudprovider.Decorator = (*decorator)(nil)
+var _ cloudprovider.BatchCreator = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+	injector *Injector
+}
+
+// DecorateCloudProvider returns a new `CloudProvider` instance that will delegate all method calls to the argument,
+// `cloudProvider`, and inject the configured faults into its Create, Delete and Get calls. It returns `cloudProvider`
+// unchanged unless fault injection is enabled.
+//
+// The returned instance supports the optional interfaces that `cloudProvider` supports, which are looked up with
+// `cloudprovider.As`.
+func DecorateCloudProvider(ctx context.Context, cloudProvider cloudprovider.CloudProvider, kubeClient client.Client) cloudprovider.CloudProvider {
+	if !options.FromContext(ctx).EnableFaultInjection {
+		return cloudProvider
+	}
+	return &decorator{CloudProvider: cloudProvider, injector: NewInjector(kubeClient)}
+}
+
+// Unwrap returns the decorated CloudProvider
+func (d *decorator) Unwrap() cloudprovider.CloudProvider {
+	return d.CloudProvider
+}
+
+func (d *decorator) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	if err := d.injector.Inject(ctx, TargetCloudProviderCreate, ""); err != nil {
+		return nil, err
+	}
+	return d.CloudProvider.Create(ctx, nodeClaim)
+}
+
+// CreateBatch injects faults into each NodeClaim's launch, and only launches the NodeClaims that didn't fail
+func (d *decorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	created, errs := make([]*v1beta1.NodeClaim, len(nodeClaims)), make([]error, len(nodeClaims))
+	var launched []int
+	for i := range nodeClaims {
+		if errs[i] = d.injector.Inject(ctx, TargetCloudProviderCreate, ""); errs[i] == nil {
+			launched = append(launched, i)
+		}
+	}
+	if len(launched) == 0 {
+		return created, errs
+	}
+	batchCreated, batchErrs := cloudprovider.CreateBatch(ctx, d.CloudProvider, lo.Map(launched, func(i int, _ int) *v1beta1.NodeClaim { return nodeClaims[i] }))
+	for j, i := range launched {
+		if j >= len(batchCreated) || j >= len(batchErrs) {
+			errs[i] = fmt.Errorf("creating batch, cloudprovider returned %d nodeclaims and %d errors for %d nodeclaims", len(batchCreated), len(batchErrs), len(launched))
+			continue
+		}
+		created[i], errs[i] = batchCreated[j], batchErrs[j]
+	}
+	return created, errs
+}
+
+func (d *decorator) Delete(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	if err := d.injector.Inject(ctx, TargetCloudProviderDelete, ""); err != nil {
+		return err
+	}
+	return d.CloudProvider.Delete(ctx, nodeClaim)
+}
+
+func (d *decorator) Get(ctx context.Context, providerID string) (*v1beta1.NodeClaim, error) {
+	if err := d.injector.Inject(ctx, TargetCloudProviderGet, ""); err != nil {
+		return nil, err
+	}
+	return d.CloudProvider.Get(ctx, providerID)
+}
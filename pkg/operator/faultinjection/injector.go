@@ -0,0 +1,153 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap in Karpenter's namespace that configures the injected faults
+	ConfigMapName = "karpenter-fault-injection"
+	// ConfigKey is the key of the ConfigMap's data that holds the fault injection configuration
+	ConfigKey = "config"
+)
+
+// Targets are the calls that faults can be injected into
+const (
+	TargetCloudProviderCreate = "CloudProvider.Create"
+	TargetCloudProviderDelete = "CloudProvider.Delete"
+	TargetCloudProviderGet    = "CloudProvider.Get"
+	TargetPatch               = "Patch"
+)
+
+// Errors are the errors, other than a generic error, that failed calls can return
+const (
+	ErrorInsufficientCapacity = "InsufficientCapacity"
+)
+
+// Config is the fault injection configuration, serialized as YAML or JSON in the ConfigMap
+type Config struct {
+	Faults []Fault `json:"faults,omitempty"`
+}
+
+func (c Config) validate() error {
+	for _, fault := range c.Faults {
+		if fault.Error != "" && fault.Error != ErrorInsufficientCapacity {
+			return fmt.Errorf("unknown error %q for target %s", fault.Error, fault.Target)
+		}
+	}
+	return nil
+}
+
+// Fault delays or fails a fraction of the calls to its target
+type Fault struct {
+	// Target is the call that the fault is injected into
+	Target string `json:"target"`
+	// Kind restricts Patch faults to the patches of objects of this kind, e.g. NodeClaim. Leave empty to match every kind.
+	Kind string `json:"kind,omitempty"`
+	// FailureRate is the fraction of calls, between 0 and 1, that fail
+	FailureRate float64 `json:"failureRate,omitempty"`
+	// Error is the error that the failed calls return, e.g. InsufficientCapacity for CloudProvider.Create. Leave empty
+	// to return a generic error.
+	Error string `json:"error,omitempty"`
+	// MaxDelay delays every call by a random duration up to MaxDelay
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+// Injector injects the faults configured in the fault injection ConfigMap. The ConfigMap is read on every call, so
+// that the faults can be changed while soak tests run without restarting Karpenter.
+type Injector struct {
+	kubeClient client.Client
+
+	mu              sync.Mutex
+	resourceVersion string
+	config          Config
+}
+
+func NewInjector(kubeClient client.Client) *Injector {
+	return &Injector{kubeClient: kubeClient}
+}
+
+// Inject delays the call to the target and returns an error if it should fail
+func (i *Injector) Inject(ctx context.Context, target, kind string) error {
+	for _, fault := range i.faults(ctx) {
+		if fault.Target != target || (fault.Kind != "" && fault.Kind != kind) {
+			continue
+		}
+		if fault.MaxDelay != nil && fault.MaxDelay.Duration > 0 {
+			//nolint:gosec
+			delay := time.Duration(rand.Int63n(int64(fault.MaxDelay.Duration)))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		//nolint:gosec
+		if rand.Float64() < fault.FailureRate {
+			return fault.err(target)
+		}
+	}
+	return nil
+}
+
+func (f Fault) err(target string) error {
+	err := fmt.Errorf("injected fault in %s", target)
+	if f.Error == ErrorInsufficientCapacity {
+		return cloudprovider.NewInsufficientCapacityError(err)
+	}
+	return err
+}
+
+// faults returns the faults of the current configuration, only parsing the ConfigMap when it changes
+func (i *Injector) faults(ctx context.Context) []Fault {
+	cm := &v1.ConfigMap{}
+	if err := i.kubeClient.Get(ctx, client.ObjectKey{Namespace: system.Namespace(), Name: ConfigMapName}, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logging.FromContext(ctx).Errorf("getting fault injection configmap, %s", err)
+		}
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if cm.ResourceVersion != i.resourceVersion {
+		config := Config{}
+		if err := yaml.UnmarshalStrict([]byte(cm.Data[ConfigKey]), &config); err != nil {
+			// An invalid configuration injects no faults, rather than the faults of a configuration that was replaced
+			logging.FromContext(ctx).Errorf("parsing fault injection configuration, %s", err)
+		} else if err = config.validate(); err != nil {
+			logging.FromContext(ctx).Errorf("validating fault injection configuration, %s", err)
+			config = Config{}
+		}
+		i.resourceVersion, i.config = cm.ResourceVersion, config
+	}
+	return i.config.Faults
+}
@@ -0,0 +1,210 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/operator/faultinjection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/test"
+
+	. "knative.dev/pkg/logging/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeCloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FaultInjection")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	fakeCloudProvider = fake.NewCloudProvider()
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EnableFaultInjection: lo.ToPtr(true)}))
+	fakeCloudProvider.Reset()
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	Expect(client.IgnoreNotFound(env.Client.Delete(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: faultinjection.ConfigMapName}}))).To(Succeed())
+})
+
+func applyConfig(config string) {
+	ExpectApplied(ctx, env.Client, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: faultinjection.ConfigMapName},
+		Data:       map[string]string{faultinjection.ConfigKey: config},
+	})
+}
+
+var _ = Describe("FaultInjection", func() {
+	var nodeClaim *v1beta1.NodeClaim
+
+	BeforeEach(func() {
+		nodeClaim = test.NodeClaim()
+	})
+	Context("CloudProvider", func() {
+		It("should not decorate the cloudprovider when fault injection is disabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EnableFaultInjection: lo.ToPtr(false)}))
+			Expect(faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)).To(BeIdenticalTo(fakeCloudProvider))
+		})
+		It("should not inject faults without a configmap", func() {
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeCloudProvider.CreateCalls).To(HaveLen(1))
+		})
+		It("should fail the calls to the configured target", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Create
+  failureRate: 1
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).To(MatchError(ContainSubstring("injected fault in CloudProvider.Create")))
+			Expect(fakeCloudProvider.CreateCalls).To(BeEmpty())
+
+			// Faults aren't injected into the other targets
+			_, err = cloudProvider.Get(ctx, test.RandomProviderID())
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+		})
+		It("should fail the calls with the configured error", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Create
+  failureRate: 1
+  error: InsufficientCapacity
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeTrue())
+			Expect(fakeCloudProvider.CreateCalls).To(BeEmpty())
+		})
+		It("should not inject faults when the configured error is unknown", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Create
+  failureRate: 1
+  error: Unknown
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should only launch the nodeclaims of a batch that didn't fail", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Create
+  failureRate: 1
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			batchCreator, ok := cloudprovider.As[cloudprovider.BatchCreator](cloudProvider)
+			Expect(ok).To(BeTrue())
+			created, errs := batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{nodeClaim, test.NodeClaim()})
+			Expect(created).To(HaveLen(2))
+			Expect(errs).To(HaveLen(2))
+			Expect(errs).To(HaveEach(MatchError(ContainSubstring("injected fault"))))
+			Expect(fakeCloudProvider.CreateCalls).To(BeEmpty())
+		})
+		It("should pick up changes to the configmap", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Delete
+  failureRate: 1
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			Expect(cloudProvider.Delete(ctx, nodeClaim)).To(MatchError(ContainSubstring("injected fault")))
+			Expect(fakeCloudProvider.DeleteCalls).To(BeEmpty())
+
+			applyConfig(`faults: []`)
+			Expect(cloudprovider.IsNodeClaimNotFoundError(cloudProvider.Delete(ctx, nodeClaim))).To(BeTrue())
+			Expect(fakeCloudProvider.DeleteCalls).To(HaveLen(1))
+		})
+		It("should not inject faults when the configuration is invalid", func() {
+			applyConfig(`
+faults:
+- target: CloudProvider.Create
+  failureRate: 1
+  unknownField: true
+`)
+			cloudProvider := faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should preserve the interruption provider of the cloudprovider", func() {
+			_, ok := cloudprovider.As[cloudprovider.InterruptionProvider](faultinjection.DecorateCloudProvider(ctx, fakeCloudProvider, env.Client))
+			Expect(ok).To(BeTrue())
+		})
+		It("should not implement BatchCreator when the cloudprovider doesn't", func() {
+			_, ok := cloudprovider.As[cloudprovider.BatchCreator](faultinjection.DecorateCloudProvider(ctx, struct{ cloudprovider.CloudProvider }{fakeCloudProvider}, env.Client))
+			Expect(ok).To(BeFalse())
+		})
+	})
+	Context("Client", func() {
+		It("should fail the patches of the configured kind", func() {
+			applyConfig(`
+faults:
+- target: Patch
+  kind: NodeClaim
+  failureRate: 1
+`)
+			kubeClient := faultinjection.DecorateClient(env.Client, faultinjection.NewInjector(env.Client))
+			node := test.Node()
+			ExpectApplied(ctx, env.Client, nodeClaim, node)
+
+			stored := nodeClaim.DeepCopy()
+			nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{"test": "value"})
+			Expect(kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored))).To(MatchError(ContainSubstring("injected fault in Patch")))
+			stored = nodeClaim.DeepCopy()
+			nodeClaim.StatusConditions().MarkTrue(v1beta1.Launched)
+			Expect(kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored))).To(MatchError(ContainSubstring("injected fault in Patch")))
+
+			// Faults aren't injected into the patches of other kinds
+			storedNode := node.DeepCopy()
+			node.Labels = lo.Assign(node.Labels, map[string]string{"test": "value"})
+			Expect(kubeClient.Patch(ctx, node, client.MergeFrom(storedNode))).To(Succeed())
+		})
+	})
+})
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -48,6 +49,11 @@ const (
 // certain portions of the code since logging would be too noisy
 var NopLogger = zap.NewNop().Sugar()
 
+var (
+	levelsMu sync.Mutex
+	levels   []zap.AtomicLevel // levels of the loggers that the log level option applies to
+)
+
 func DefaultZapConfig(ctx context.Context, component string) zap.Config {
 	logLevel := lo.Ternary(component != "webhook", zap.NewAtomicLevelAt(zap.InfoLevel), zap.NewAtomicLevelAt(zap.ErrorLevel))
 	if l := options.FromContext(ctx).LogLevel; l != "" && component != "webhook" {
@@ -103,8 +109,34 @@ func WithCommit(logger *zap.SugaredLogger) *zap.SugaredLogger {
 	return logger.With(zap.String(logkey.Commit, revision))
 }
 
+// SetLevel changes the level of the loggers that the log level option applies to, which is every logger except the
+// webhook's. An empty level resets them to info.
+func SetLevel(level string) error {
+	l := zap.InfoLevel
+	if level != "" {
+		if err := l.Set(level); err != nil {
+			return fmt.Errorf("parsing log level, %w", err)
+		}
+	}
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	for _, atomicLevel := range levels {
+		atomicLevel.SetLevel(l)
+	}
+	return nil
+}
+
+func build(cfg zap.Config, component string) *zap.SugaredLogger {
+	if component != "webhook" {
+		levelsMu.Lock()
+		levels = append(levels, cfg.Level)
+		levelsMu.Unlock()
+	}
+	return WithCommit(lo.Must(cfg.Build()).Sugar()).Named(component)
+}
+
 func defaultLogger(ctx context.Context, component string) *zap.SugaredLogger {
-	return WithCommit(lo.Must(DefaultZapConfig(ctx, component).Build()).Sugar()).Named(component)
+	return build(DefaultZapConfig(ctx, component), component)
 }
 
 func loggerFromFile(ctx context.Context, component string) *zap.SugaredLogger {
@@ -125,7 +157,7 @@ func loggerFromFile(ctx context.Context, component string) *zap.SugaredLogger {
 	if raw != nil {
 		cfg.Level = lo.Must(zap.ParseAtomicLevel(string(raw)))
 	}
-	return WithCommit(lo.Must(cfg.Build()).Sugar()).Named(component)
+	return build(cfg, component)
 }
 
 // ConfigureGlobalLoggers sets up any package-wide loggers like "log" or "klog" that are utilized by other packages
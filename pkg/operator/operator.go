@@ -24,6 +24,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/go-logr/zapr"
 	"github.com/samber/lo"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
@@ -54,12 +56,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/controller"
+	"sigs.k8s.io/karpenter/pkg/operator/faultinjection"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	"sigs.k8s.io/karpenter/pkg/operator/logging"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
 	"sigs.k8s.io/karpenter/pkg/webhooks"
 )
 
@@ -93,7 +101,32 @@ type Operator struct {
 	EventRecorder       events.Recorder
 	Clock               clock.Clock
 
-	webhooks []knativeinjection.ControllerConstructor
+	kubeClient client.Client
+	webhooks   []knativeinjection.ControllerConstructor
+	debugState *deferredHandler
+	simulation *deferredHandler
+	// tracerProvider exports the spans that controllers record, if tracing is enabled
+	tracerProvider *sdktrace.TracerProvider
+}
+
+// deferredHandler serves a handler on the metrics endpoint. The endpoints are registered when the manager is created,
+// which is before what they serve exists, so each is unavailable until the operator is given its handler.
+type deferredHandler struct {
+	handler     atomic.Pointer[http.Handler]
+	unavailable string
+}
+
+func (h *deferredHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := h.handler.Load()
+	if handler == nil {
+		http.Error(w, h.unavailable, http.StatusServiceUnavailable)
+		return
+	}
+	(*handler).ServeHTTP(w, r)
+}
+
+func (h *deferredHandler) set(handler http.Handler) {
+	h.handler.Store(&handler)
 }
 
 // NewOperator instantiates a controller manager or panics
@@ -135,11 +168,17 @@ func NewOperator() (context.Context, *Operator) {
 
 	knativelogging.FromContext(ctx).With("version", Version).Debugf("discovered karpenter version")
 
+	// Tracing
+	var tracerProvider *sdktrace.TracerProvider
+	if options.FromContext(ctx).EnableTracing {
+		tracerProvider = lo.Must(tracing.NewTracerProvider(ctx, appName, Version))
+	}
+
 	// Manager
 	mgrOpts := controllerruntime.Options{
 		Logger:                        logging.IgnoreDebugEvents(zapr.NewLogger(logger.Desugar())),
 		LeaderElection:                options.FromContext(ctx).EnableLeaderElection,
-		LeaderElectionID:              "karpenter-leader-election",
+		LeaderElectionID:              leaderElectionID(ctx),
 		LeaderElectionResourceLock:    resourcelock.LeasesResourceLock,
 		LeaderElectionNamespace:       system.Namespace(),
 		LeaderElectionReleaseOnCancel: true,
@@ -148,20 +187,33 @@ func NewOperator() (context.Context, *Operator) {
 			BindAddress: fmt.Sprintf(":%d", options.FromContext(ctx).MetricsPort),
 		},
 		HealthProbeBindAddress: fmt.Sprintf(":%d", options.FromContext(ctx).HealthProbePort),
+		// Reconciles share the options of the root context, so that options reloaded from the operator config are
+		// seen by every controller. They aren't cancelled along with it though.
 		BaseContext: func() context.Context {
-			ctx := context.Background()
-			ctx = knativelogging.WithLogger(ctx, logger)
-			ctx = injection.WithOptionsOrDie(ctx, options.Injectables...)
-			return ctx
+			return context.WithoutCancel(ctx)
 		},
 		Cache: cache.Options{
 			ByObject: map[client.Object]cache.ByObject{
 				&coordinationv1.Lease{}: {
 					Field: fields.SelectorFromSet(fields.Set{"metadata.namespace": "kube-node-lease"}),
 				},
+				&v1.ConfigMap{}: {
+					Field: fields.SelectorFromSet(fields.Set{"metadata.namespace": system.Namespace()}),
+				},
+				// Each instance of Karpenter only watches the NodePools in its shard, and the NodeClaims that were
+				// launched for them. NodeClaims without shard labels match the selector of the default shard.
+				&v1beta1.NodePool{}: {
+					Label: nodepoolutil.Selector(ctx),
+				},
+				&v1beta1.NodeClaim{}: {
+					Label: nodepoolutil.Selector(ctx),
+				},
 			},
 		},
 	}
+	if options.FromContext(ctx).MinimizePodCache {
+		mgrOpts.Cache.ByObject[&v1.Pod{}] = cache.ByObject{Transform: podutil.StripUnusedFields}
+	}
 	if options.FromContext(ctx).EnableProfiling {
 		// TODO @joinnis: Investigate the mgrOpts.PprofBindAddress that would allow native support for pprof
 		// On initial look, it seems like this native pprof doesn't support some of the routes that we have here
@@ -179,6 +231,18 @@ func NewOperator() (context.Context, *Operator) {
 			"/debug/pprof/threadcreate": pprof.Handler("threadcreate"),
 		})
 	}
+	debugState := &deferredHandler{unavailable: "cluster state isn't available"}
+	if options.FromContext(ctx).EnableDebugState {
+		mgrOpts.Metrics.ExtraHandlers = lo.Assign(mgrOpts.Metrics.ExtraHandlers, map[string]http.Handler{
+			"/debug/state": debugState,
+		})
+	}
+	simulation := &deferredHandler{unavailable: "the provisioner isn't available"}
+	if options.FromContext(ctx).EnableSimulation {
+		mgrOpts.Metrics.ExtraHandlers = lo.Assign(mgrOpts.Metrics.ExtraHandlers, map[string]http.Handler{
+			"/debug/simulate": simulation,
+		})
+	}
 	mgr, err := controllerruntime.NewManager(config, mgrOpts)
 	mgr = lo.Must(mgr, err, "failed to setup manager")
 	lo.Must0(mgr.GetFieldIndexer().IndexField(ctx, &v1.Pod{}, "spec.nodeName", func(o client.Object) []string {
@@ -197,16 +261,43 @@ func NewOperator() (context.Context, *Operator) {
 	lo.Must0(mgr.AddHealthzCheck("healthz", healthz.Ping))
 	lo.Must0(mgr.AddReadyzCheck("readyz", healthz.Ping))
 
+	kubeClient := mgr.GetClient()
+	if options.FromContext(ctx).EnableFaultInjection {
+		knativelogging.FromContext(ctx).Warnf("fault injection enabled, faults configured in the %q configmap will be injected into API patches", faultinjection.ConfigMapName)
+		kubeClient = faultinjection.DecorateClient(kubeClient, faultinjection.NewInjector(mgr.GetClient()))
+	}
+
 	return ctx, &Operator{
 		Manager:             mgr,
 		KubernetesInterface: kubernetesInterface,
 		EventRecorder:       events.NewRecorder(mgr.GetEventRecorderFor(appName)),
 		Clock:               clock.RealClock{},
+		kubeClient:          kubeClient,
+		debugState:          debugState,
+		simulation:          simulation,
+		tracerProvider:      tracerProvider,
 	}
 }
 
+// GetClient returns the manager's client. When fault injection is enabled, it injects the configured faults into
+// API patches.
+func (o *Operator) GetClient() client.Client {
+	return o.kubeClient
+}
+
+// WithClusterState serves the cluster state on the /debug/state metrics endpoint, if it's enabled
+func (o *Operator) WithClusterState(cluster *state.Cluster) *Operator {
+	o.debugState.set(state.DebugHandler(cluster))
+	return o
+}
+
+// WithControllers registers the controllers with the manager. Scheduling simulations are served on the
+// /debug/simulate metrics endpoint by the provisioner among them, if it's enabled.
 func (o *Operator) WithControllers(ctx context.Context, controllers ...controller.Controller) *Operator {
 	for _, c := range controllers {
+		if p, ok := c.(*provisioning.Provisioner); ok {
+			o.simulation.set(provisioning.NewSimulationHandler(p))
+		}
 		lo.Must0(c.Builder(ctx, o.Manager).Complete(c))
 	}
 	return o
@@ -238,4 +329,18 @@ func (o *Operator) Start(ctx context.Context) {
 		}()
 	}
 	wg.Wait()
+	if o.tracerProvider != nil {
+		// The root context is done, so the spans that are still batched are flushed within a grace period of their own
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := o.tracerProvider.Shutdown(shutdownCtx); err != nil {
+			knativelogging.FromContext(ctx).Errorf("shutting down tracer provider, %s", err)
+		}
+	}
+}
+
+// leaderElectionID scopes leader election to the shard of nodepools that this instance of Karpenter manages, so that
+// deployments with different nodepool selectors each elect their own leader
+func leaderElectionID(ctx context.Context) string {
+	return nodepoolutil.ShardScopedName(ctx, "karpenter-leader-election")
 }
@@ -21,17 +21,35 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/labels"
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"sigs.k8s.io/karpenter/pkg/utils/env"
 )
 
+const (
+	// BinPackingStrategyCPU packs the pods with the largest CPU requests first
+	BinPackingStrategyCPU = "CPU"
+	// BinPackingStrategyMemory packs the pods with the largest memory requests first
+	BinPackingStrategyMemory = "Memory"
+	// BinPackingStrategyDominantResource packs the pods with the largest share of the CPU or memory of the largest
+	// instance type first
+	BinPackingStrategyDominantResource = "DominantResource"
+)
+
 var (
-	validLogLevels = []string{"", "debug", "info", "error"}
+	validLogLevels            = []string{"", "debug", "info", "error"}
+	validBinPackingStrategies = []string{BinPackingStrategyCPU, BinPackingStrategyMemory, BinPackingStrategyDominantResource}
 
 	Injectables = []Injectable{&Options{}}
 )
@@ -43,25 +61,49 @@ type FeatureGates struct {
 
 	Drift                   bool
 	SpotToSpotConsolidation bool
+	NodeRepair              bool
+	NodeResize              bool
+	NodeDrain               bool
 }
 
 // Options contains all CLI flags / env vars for karpenter-core. It adheres to the options.Injectable interface.
 type Options struct {
-	ServiceName          string
-	DisableWebhook       bool
-	WebhookPort          int
-	MetricsPort          int
-	WebhookMetricsPort   int
-	HealthProbePort      int
-	KubeClientQPS        int
-	KubeClientBurst      int
-	EnableProfiling      bool
-	EnableLeaderElection bool
-	MemoryLimit          int64
-	LogLevel             string
-	BatchMaxDuration     time.Duration
-	BatchIdleDuration    time.Duration
-	FeatureGates         FeatureGates
+	ServiceName                       string
+	DisableWebhook                    bool
+	WebhookPort                       int
+	MetricsPort                       int
+	WebhookMetricsPort                int
+	HealthProbePort                   int
+	KubeClientQPS                     int
+	KubeClientBurst                   int
+	EnableProfiling                   bool
+	EnableTracing                     bool
+	EnableDebugState                  bool
+	EnableSimulation                  bool
+	EnableLeaderElection              bool
+	EnableAdmissionPolicies           bool
+	EnableFaultInjection              bool
+	MinimizePodCache                  bool
+	MemoryLimit                       int64
+	LogLevel                          string
+	BatchMaxDuration                  time.Duration
+	BatchIdleDuration                 time.Duration
+	NodeRepairTolerationDuration      time.Duration
+	PreTerminationHookTimeout         time.Duration
+	BlockedTerminationThreshold       time.Duration
+	DisruptionPreferNoScheduleWindow  time.Duration
+	DisruptionActionRetention         time.Duration
+	ReservedLimitsPercentage          int
+	ResyncStateOnInconsistency        bool
+	MultiNodeConsolidationTimeout     time.Duration
+	MultiNodeConsolidationParallelism int
+	NodePoolSelector                  string
+	ProtectedPodNamespaces            string
+	ProtectedPodSelector              string
+	EvictionBypassNamespaceSelector   string
+	CloudProviderRateLimits           string
+	BinPackingStrategy                string
+	FeatureGates                      FeatureGates
 }
 
 type FlagSet struct {
@@ -91,12 +133,33 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.IntVar(&o.KubeClientQPS, "kube-client-qps", env.WithDefaultInt("KUBE_CLIENT_QPS", 200), "The smoothed rate of qps to kube-apiserver")
 	fs.IntVar(&o.KubeClientBurst, "kube-client-burst", env.WithDefaultInt("KUBE_CLIENT_BURST", 300), "The maximum allowed burst of queries to the kube-apiserver")
 	fs.BoolVarWithEnv(&o.EnableProfiling, "enable-profiling", "ENABLE_PROFILING", false, "Enable the profiling on the metric endpoint")
+	fs.BoolVarWithEnv(&o.EnableTracing, "enable-tracing", "ENABLE_TRACING", false, "Export the spans of disruption decisions and of NodeClaim launches and terminations with OTLP over HTTP. The exporter is configured by the standard OTEL_EXPORTER_OTLP_* environment variables.")
+	fs.BoolVarWithEnv(&o.EnableDebugState, "enable-debug-state", "ENABLE_DEBUG_STATE", false, "Enable dumping Karpenter's cluster state as JSON on the /debug/state metric endpoint")
+	fs.BoolVarWithEnv(&o.EnableSimulation, "enable-simulation", "ENABLE_SIMULATION", false, "Enable serving scheduling simulations for posted pods on the /debug/simulate metric endpoint")
 	fs.BoolVarWithEnv(&o.EnableLeaderElection, "leader-elect", "LEADER_ELECT", true, "Start leader election client and gain leadership before executing the main loop. Enable this when running replicated components for high availability.")
+	fs.BoolVarWithEnv(&o.EnableAdmissionPolicies, "enable-admission-policies", "ENABLE_ADMISSION_POLICIES", false, "Install a ValidatingAdmissionPolicy that validates NodePools in the apiserver, for clusters that don't run the webhook. Requires the admissionregistration.k8s.io/v1beta1 API.")
+	fs.BoolVarWithEnv(&o.EnableFaultInjection, "enable-fault-injection", "ENABLE_FAULT_INJECTION", false, "Inject the delays and failures configured in the karpenter-fault-injection ConfigMap into cloud provider calls and API patches. Only meant for soak testing.")
+	fs.BoolVarWithEnv(&o.MinimizePodCache, "minimize-pod-cache", "MINIMIZE_POD_CACHE", false, "Drop the fields of pods that Karpenter doesn't use, e.g. managed fields and the environment and probes of their containers, from the informer cache. Reduces memory usage on clusters with many pods.")
 	fs.Int64Var(&o.MemoryLimit, "memory-limit", env.WithDefaultInt64("MEMORY_LIMIT", -1), "Memory limit on the container running the controller. The GC soft memory limit is set to 90% of this value.")
 	fs.StringVar(&o.LogLevel, "log-level", env.WithDefaultString("LOG_LEVEL", "info"), "Log verbosity level. Can be one of 'debug', 'info', or 'error'")
 	fs.DurationVar(&o.BatchMaxDuration, "batch-max-duration", env.WithDefaultDuration("BATCH_MAX_DURATION", 10*time.Second), "The maximum length of a batch window. The longer this is, the more pods we can consider for provisioning at one time which usually results in fewer but larger nodes.")
 	fs.DurationVar(&o.BatchIdleDuration, "batch-idle-duration", env.WithDefaultDuration("BATCH_IDLE_DURATION", time.Second), "The maximum amount of time with no new pending pods that if exceeded ends the current batching window. If pods arrive faster than this time, the batching window will be extended up to the maxDuration. If they arrive slower, the pods will be batched separately.")
-	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "Drift=true,SpotToSpotConsolidation=false"), "Optional features can be enabled / disabled using feature gates. Current options are: Drift,SpotToSpotConsolidation")
+	fs.DurationVar(&o.NodeRepairTolerationDuration, "node-repair-toleration-duration", env.WithDefaultDuration("NODE_REPAIR_TOLERATION_DURATION", 30*time.Minute), "The amount of time a node can be NotReady or under DiskPressure before it is forcefully replaced. Only used when the NodeRepair feature gate is enabled.")
+	fs.DurationVar(&o.PreTerminationHookTimeout, "pre-termination-hook-timeout", env.WithDefaultDuration("PRE_TERMINATION_HOOK_TIMEOUT", 10*time.Minute), "The maximum amount of time that the instance of a drained node waits for the NodeClaim's pre-termination hooks to complete before it's deleted.")
+	fs.DurationVar(&o.BlockedTerminationThreshold, "blocked-termination-threshold", env.WithDefaultDuration("BLOCKED_TERMINATION_THRESHOLD", 5*time.Minute), "The amount of time that a node's drain is blocked by pods that can't be evicted before those pods are listed on the TerminationBlocked condition of its NodeClaims.")
+	fs.DurationVar(&o.DisruptionPreferNoScheduleWindow, "disruption-prefer-no-schedule-window", env.WithDefaultDuration("DISRUPTION_PREFER_NO_SCHEDULE_WINDOW", 0), "The amount of time that nodes are tainted with karpenter.sh/disruption:PreferNoSchedule before they're disrupted, so that new pods prefer other nodes rather than landing on nodes that are about to be drained. Set to 0 to disable.")
+	fs.DurationVar(&o.DisruptionActionRetention, "disruption-action-retention", env.WithDefaultDuration("DISRUPTION_ACTION_RETENTION", 7*24*time.Hour), "The amount of time that DisruptionActions, the records of the disruption commands that Karpenter decided on, are kept for once the commands complete. Set to 0 to stop recording them.")
+	fs.IntVar(&o.ReservedLimitsPercentage, "reserved-limits-percentage", env.WithDefaultInt("RESERVED_LIMITS_PERCENTAGE", 0), "The percentage of each NodePool's limits that is reserved for pods with a positive priority. Once a NodePool is within this percentage of its limits, pods without a positive priority won't be provisioned capacity from it. Set to 0 to disable.")
+	fs.BoolVarWithEnv(&o.ResyncStateOnInconsistency, "resync-state-on-inconsistency", "RESYNC_STATE_ON_INCONSISTENCY", false, "Rebuild Karpenter's cluster state from the apiserver when the periodic consistency check finds that it has diverged.")
+	fs.DurationVar(&o.MultiNodeConsolidationTimeout, "multi-node-consolidation-timeout", env.WithDefaultDuration("MULTI_NODE_CONSOLIDATION_TIMEOUT", time.Minute), "The time budget for finding a multi-node consolidation. Once it's exceeded, the largest consolidation found so far is used.")
+	fs.IntVar(&o.MultiNodeConsolidationParallelism, "multi-node-consolidation-parallelism", env.WithDefaultInt("MULTI_NODE_CONSOLIDATION_PARALLELISM", 4), "The number of batches of nodes that are evaluated in parallel when finding a multi-node consolidation.")
+	fs.StringVar(&o.NodePoolSelector, "nodepool-selector", env.WithDefaultString("NODEPOOL_SELECTOR", ""), "A label selector for the NodePools that this instance of Karpenter manages, along with their NodeClaims and Nodes. Use disjoint selectors to shard NodePools across multiple Karpenter deployments in the same cluster. NodeClaims and Nodes without the labels of the selector belong to the deployment whose selector matches them without those labels, e.g. \"team!=a\". Leave empty to manage every NodePool.")
+	fs.StringVar(&o.ProtectedPodNamespaces, "protected-pod-namespaces", env.WithDefaultString("PROTECTED_POD_NAMESPACES", ""), "A comma-separated list of namespaces whose pods block the voluntary disruption of their nodes, as if they had the karpenter.sh/do-not-disrupt annotation. DaemonSet and mirror pods don't block disruption. If a protected pod selector is also set, only the pods in these namespaces that match it are protected.")
+	fs.StringVar(&o.ProtectedPodSelector, "protected-pod-selector", env.WithDefaultString("PROTECTED_POD_SELECTOR", ""), "A label selector for pods that block the voluntary disruption of their nodes, as if they had the karpenter.sh/do-not-disrupt annotation. DaemonSet and mirror pods don't block disruption. Leave this and the protected pod namespaces empty to not protect any pods.")
+	fs.StringVar(&o.EvictionBypassNamespaceSelector, "eviction-bypass-namespace-selector", env.WithDefaultString("EVICTION_BYPASS_NAMESPACE_SELECTOR", ""), "A label selector for namespaces whose pods are deleted rather than evicted when draining nodes, bypassing their PDBs. Meant for workloads with PDBs that never allow an eviction. Leave empty to evict the pods of every namespace.")
+	fs.StringVar(&o.CloudProviderRateLimits, "cloudprovider-rate-limits", env.WithDefaultString("CLOUDPROVIDER_RATE_LIMITS", ""), "A comma-separated list of the client-side budgets of cloud provider methods, shared by every controller, in the form Method=QPS[:Burst], e.g. Create=5:10,Delete=5. The budget of a method is lowered while the cloud provider throttles it and recovers once it stops. Methods without a budget aren't limited.")
+	fs.StringVar(&o.BinPackingStrategy, "binpacking-strategy", env.WithDefaultString("BINPACKING_STRATEGY", BinPackingStrategyCPU), "The order in which pods are packed onto new nodes, largest first. CPU and Memory order pods by their CPU or memory requests, and DominantResource by the largest share of the CPU or memory of the largest instance type that they request. Valid values are CPU, Memory and DominantResource.")
+	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "Drift=true,SpotToSpotConsolidation=false,NodeRepair=false,NodeResize=false,NodeDrain=false"), "Optional features can be enabled / disabled using feature gates. Current options are: Drift,SpotToSpotConsolidation,NodeRepair,NodeResize,NodeDrain")
 }
 
 func (o *Options) Parse(fs *FlagSet, args ...string) error {
@@ -110,6 +173,42 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	if !lo.Contains(validLogLevels, o.LogLevel) {
 		return fmt.Errorf("validating cli flags / env vars, invalid log level %q", o.LogLevel)
 	}
+	if o.ReservedLimitsPercentage < 0 || o.ReservedLimitsPercentage > 100 {
+		return fmt.Errorf("validating cli flags / env vars, reserved limits percentage must be between 0 and 100, got %d", o.ReservedLimitsPercentage)
+	}
+	if o.MultiNodeConsolidationTimeout <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, multi-node consolidation timeout must be positive, got %s", o.MultiNodeConsolidationTimeout)
+	}
+	if o.PreTerminationHookTimeout <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, pre-termination hook timeout must be positive, got %s", o.PreTerminationHookTimeout)
+	}
+	if o.BlockedTerminationThreshold < 0 {
+		return fmt.Errorf("validating cli flags / env vars, blocked termination threshold must be non-negative, got %s", o.BlockedTerminationThreshold)
+	}
+	if o.DisruptionPreferNoScheduleWindow < 0 {
+		return fmt.Errorf("validating cli flags / env vars, disruption prefer no schedule window can't be negative, got %s", o.DisruptionPreferNoScheduleWindow)
+	}
+	if o.DisruptionActionRetention < 0 {
+		return fmt.Errorf("validating cli flags / env vars, disruption action retention can't be negative, got %s", o.DisruptionActionRetention)
+	}
+	if o.MultiNodeConsolidationParallelism < 1 {
+		return fmt.Errorf("validating cli flags / env vars, multi-node consolidation parallelism must be at least 1, got %d", o.MultiNodeConsolidationParallelism)
+	}
+	if _, err := labels.Parse(o.NodePoolSelector); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid nodepool selector %q, %w", o.NodePoolSelector, err)
+	}
+	if _, err := labels.Parse(o.ProtectedPodSelector); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid protected pod selector %q, %w", o.ProtectedPodSelector, err)
+	}
+	if _, err := labels.Parse(o.EvictionBypassNamespaceSelector); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid eviction bypass namespace selector %q, %w", o.EvictionBypassNamespaceSelector, err)
+	}
+	if _, err := ParseRateLimits(o.CloudProviderRateLimits); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid cloudprovider rate limits %q, %w", o.CloudProviderRateLimits, err)
+	}
+	if !lo.Contains(validBinPackingStrategies, o.BinPackingStrategy) {
+		return fmt.Errorf("validating cli flags / env vars, invalid binpacking strategy %q", o.BinPackingStrategy)
+	}
 	gates, err := ParseFeatureGates(o.FeatureGates.inputStr)
 	if err != nil {
 		return fmt.Errorf("parsing feature gates, %w", err)
@@ -122,9 +221,43 @@ func (o *Options) ToContext(ctx context.Context) context.Context {
 	return ToContext(ctx, o)
 }
 
+// Reload returns a copy of the options with the options in overrides, keyed by their flag name, replaced. Only the
+// batch durations, feature gates and log level can be reloaded. Feature gates that overrides don't mention keep their
+// current value.
+func (o *Options) Reload(overrides map[string]string) (*Options, error) {
+	reloaded := *o
+	fs := flag.NewFlagSet("karpenter-reload", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.DurationVar(&reloaded.BatchMaxDuration, "batch-max-duration", o.BatchMaxDuration, "")
+	fs.DurationVar(&reloaded.BatchIdleDuration, "batch-idle-duration", o.BatchIdleDuration, "")
+	fs.StringVar(&reloaded.LogLevel, "log-level", o.LogLevel, "")
+	fs.Func("feature-gates", "", func(val string) (err error) {
+		reloaded.FeatureGates, err = parseFeatureGates(reloaded.FeatureGates, val)
+		return err
+	})
+	names := lo.Keys(overrides)
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("validating reloaded options, %q can't be reloaded", name)
+		}
+		if err := fs.Set(name, overrides[name]); err != nil {
+			return nil, fmt.Errorf("validating reloaded options, invalid %s %q, %w", name, overrides[name], err)
+		}
+	}
+	if !lo.Contains(validLogLevels, reloaded.LogLevel) {
+		return nil, fmt.Errorf("validating reloaded options, invalid log level %q", reloaded.LogLevel)
+	}
+	return &reloaded, nil
+}
+
 func ParseFeatureGates(gateStr string) (FeatureGates, error) {
+	return parseFeatureGates(FeatureGates{}, gateStr)
+}
+
+// parseFeatureGates overrides the gates with the ones that are set in the string
+func parseFeatureGates(gates FeatureGates, gateStr string) (FeatureGates, error) {
 	gateMap := map[string]bool{}
-	gates := FeatureGates{}
 
 	// Parses feature gates with the upstream mechanism. This is meant to be used with flag directly but this enables
 	// simple merging with environment vars.
@@ -137,19 +270,93 @@ func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	if val, ok := gateMap["SpotToSpotConsolidation"]; ok {
 		gates.SpotToSpotConsolidation = val
 	}
+	if val, ok := gateMap["NodeRepair"]; ok {
+		gates.NodeRepair = val
+	}
+	if val, ok := gateMap["NodeResize"]; ok {
+		gates.NodeResize = val
+	}
+	if val, ok := gateMap["NodeDrain"]; ok {
+		gates.NodeDrain = val
+	}
 
 	return gates, nil
 }
 
+// RateLimitedMethods are the cloud provider methods that can be given a budget
+var RateLimitedMethods = []string{"Create", "Delete", "Get", "List", "GetInstanceTypes", "IsDrifted", "Price"}
+
+// RateLimit is the client-side budget of a cloud provider method
+type RateLimit struct {
+	QPS   float64
+	Burst int
+}
+
+// ParseRateLimits parses the budgets of cloud provider methods, keyed by the method name, from a comma-separated list
+// of Method=QPS[:Burst]. The burst defaults to the QPS, rounded up.
+func ParseRateLimits(str string) (map[string]RateLimit, error) {
+	rateLimits := map[string]RateLimit{}
+	for _, entry := range strings.Split(str, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		method, budget, ok := strings.Cut(entry, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("%q isn't in the form Method=QPS[:Burst]", entry)
+		}
+		if !lo.Contains(RateLimitedMethods, method) {
+			return nil, fmt.Errorf("unknown method %q, must be one of %s", method, strings.Join(RateLimitedMethods, ", "))
+		}
+		qpsStr, burstStr, hasBurst := strings.Cut(budget, ":")
+		qps, err := strconv.ParseFloat(qpsStr, 64)
+		if err != nil || qps <= 0 {
+			return nil, fmt.Errorf("qps of %s must be a positive number, got %q", method, qpsStr)
+		}
+		burst := int(math.Ceil(qps))
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+				return nil, fmt.Errorf("burst of %s must be a positive integer, got %q", method, burstStr)
+			}
+		}
+		rateLimits[method] = RateLimit{QPS: qps, Burst: burst}
+	}
+	return rateLimits, nil
+}
+
+// injectedOptions holds the options that were injected into a context, and the options that they were last reloaded
+// to. Every context that's derived from it shares the reloaded options.
+type injectedOptions struct {
+	injected *Options
+	current  atomic.Pointer[Options]
+}
+
 func ToContext(ctx context.Context, opts *Options) context.Context {
-	return context.WithValue(ctx, optionsKey{}, opts)
+	injected := &injectedOptions{injected: opts}
+	injected.current.Store(opts)
+	return context.WithValue(ctx, optionsKey{}, injected)
 }
 
 func FromContext(ctx context.Context) *Options {
+	return fromContext(ctx).current.Load()
+}
+
+// ReloadContext replaces the options of the context, and of every context derived from it, with the options that were
+// injected into it with the overrides applied. Passing no overrides restores the injected options.
+func ReloadContext(ctx context.Context, overrides map[string]string) (*Options, error) {
+	injected := fromContext(ctx)
+	reloaded, err := injected.injected.Reload(overrides)
+	if err != nil {
+		return nil, err
+	}
+	injected.current.Store(reloaded)
+	return reloaded, nil
+}
+
+func fromContext(ctx context.Context) *injectedOptions {
 	retval := ctx.Value(optionsKey{})
 	if retval == nil {
 		// This is a developer error if this happens, so we should panic
 		panic("options doesn't exist in context")
 	}
-	return retval.(*Options)
+	return retval.(*injectedOptions)
 }
@@ -53,11 +53,32 @@ var _ = Describe("Options", func() {
 		"KUBE_CLIENT_QPS",
 		"KUBE_CLIENT_BURST",
 		"ENABLE_PROFILING",
+		"ENABLE_TRACING",
+		"ENABLE_DEBUG_STATE",
+		"ENABLE_SIMULATION",
 		"LEADER_ELECT",
+		"ENABLE_ADMISSION_POLICIES",
+		"ENABLE_FAULT_INJECTION",
+		"MINIMIZE_POD_CACHE",
 		"MEMORY_LIMIT",
 		"LOG_LEVEL",
 		"BATCH_MAX_DURATION",
 		"BATCH_IDLE_DURATION",
+		"NODE_REPAIR_TOLERATION_DURATION",
+		"PRE_TERMINATION_HOOK_TIMEOUT",
+		"BLOCKED_TERMINATION_THRESHOLD",
+		"DISRUPTION_PREFER_NO_SCHEDULE_WINDOW",
+		"DISRUPTION_ACTION_RETENTION",
+		"RESERVED_LIMITS_PERCENTAGE",
+		"RESYNC_STATE_ON_INCONSISTENCY",
+		"MULTI_NODE_CONSOLIDATION_TIMEOUT",
+		"MULTI_NODE_CONSOLIDATION_PARALLELISM",
+		"NODEPOOL_SELECTOR",
+		"PROTECTED_POD_NAMESPACES",
+		"PROTECTED_POD_SELECTOR",
+		"EVICTION_BYPASS_NAMESPACE_SELECTOR",
+		"CLOUDPROVIDER_RATE_LIMITS",
+		"BINPACKING_STRATEGY",
 		"FEATURE_GATES",
 	}
 
@@ -95,20 +116,41 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr(""),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(8443),
-				MetricsPort:          lo.ToPtr(8000),
-				WebhookMetricsPort:   lo.ToPtr(8001),
-				HealthProbePort:      lo.ToPtr(8081),
-				KubeClientQPS:        lo.ToPtr(200),
-				KubeClientBurst:      lo.ToPtr(300),
-				EnableProfiling:      lo.ToPtr(false),
-				EnableLeaderElection: lo.ToPtr(true),
-				MemoryLimit:          lo.ToPtr[int64](-1),
-				LogLevel:             lo.ToPtr("info"),
-				BatchMaxDuration:     lo.ToPtr(10 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(time.Second),
+				ServiceName:                       lo.ToPtr(""),
+				DisableWebhook:                    lo.ToPtr(true),
+				WebhookPort:                       lo.ToPtr(8443),
+				MetricsPort:                       lo.ToPtr(8000),
+				WebhookMetricsPort:                lo.ToPtr(8001),
+				HealthProbePort:                   lo.ToPtr(8081),
+				KubeClientQPS:                     lo.ToPtr(200),
+				KubeClientBurst:                   lo.ToPtr(300),
+				EnableProfiling:                   lo.ToPtr(false),
+				EnableTracing:                     lo.ToPtr(false),
+				EnableDebugState:                  lo.ToPtr(false),
+				EnableSimulation:                  lo.ToPtr(false),
+				EnableLeaderElection:              lo.ToPtr(true),
+				EnableAdmissionPolicies:           lo.ToPtr(false),
+				EnableFaultInjection:              lo.ToPtr(false),
+				MinimizePodCache:                  lo.ToPtr(false),
+				MemoryLimit:                       lo.ToPtr[int64](-1),
+				LogLevel:                          lo.ToPtr("info"),
+				BatchMaxDuration:                  lo.ToPtr(10 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(time.Second),
+				NodeRepairTolerationDuration:      lo.ToPtr(30 * time.Minute),
+				PreTerminationHookTimeout:         lo.ToPtr(10 * time.Minute),
+				BlockedTerminationThreshold:       lo.ToPtr(5 * time.Minute),
+				DisruptionPreferNoScheduleWindow:  lo.ToPtr(time.Duration(0)),
+				DisruptionActionRetention:         lo.ToPtr(7 * 24 * time.Hour),
+				ReservedLimitsPercentage:          lo.ToPtr(0),
+				ResyncStateOnInconsistency:        lo.ToPtr(false),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(time.Minute),
+				MultiNodeConsolidationParallelism: lo.ToPtr(4),
+				NodePoolSelector:                  lo.ToPtr(""),
+				ProtectedPodNamespaces:            lo.ToPtr(""),
+				ProtectedPodSelector:              lo.ToPtr(""),
+				EvictionBypassNamespaceSelector:   lo.ToPtr(""),
+				CloudProviderRateLimits:           lo.ToPtr(""),
+				BinPackingStrategy:                lo.ToPtr("CPU"),
 				FeatureGates: test.FeatureGates{
 					Drift: lo.ToPtr(true),
 				},
@@ -127,29 +169,71 @@ var _ = Describe("Options", func() {
 				"--kube-client-qps", "0",
 				"--kube-client-burst", "0",
 				"--enable-profiling",
+				"--enable-tracing",
+				"--enable-debug-state",
+				"--enable-simulation",
 				"--leader-elect=false",
+				"--enable-admission-policies",
+				"--enable-fault-injection",
+				"--minimize-pod-cache",
 				"--memory-limit", "0",
 				"--log-level", "debug",
 				"--batch-max-duration", "5s",
 				"--batch-idle-duration", "5s",
+				"--node-repair-toleration-duration", "5m",
+				"--pre-termination-hook-timeout", "5m",
+				"--blocked-termination-threshold", "10m",
+				"--disruption-prefer-no-schedule-window", "1m",
+				"--disruption-action-retention", "24h",
+				"--reserved-limits-percentage", "10",
+				"--resync-state-on-inconsistency",
+				"--multi-node-consolidation-timeout", "5m",
+				"--multi-node-consolidation-parallelism", "8",
+				"--nodepool-selector", "team=cli",
+				"--protected-pod-namespaces", "kube-system",
+				"--protected-pod-selector", "app=cli",
+				"--eviction-bypass-namespace-selector", "eviction=cli",
+				"--cloudprovider-rate-limits", "Create=5:10",
+				"--binpacking-strategy", "Memory",
 				"--feature-gates", "Drift=true",
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("cli"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("cli"),
+				DisableWebhook:                    lo.ToPtr(true),
+				WebhookPort:                       lo.ToPtr(0),
+				MetricsPort:                       lo.ToPtr(0),
+				WebhookMetricsPort:                lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				EnableTracing:                     lo.ToPtr(true),
+				EnableDebugState:                  lo.ToPtr(true),
+				EnableSimulation:                  lo.ToPtr(true),
+				EnableLeaderElection:              lo.ToPtr(false),
+				EnableAdmissionPolicies:           lo.ToPtr(true),
+				EnableFaultInjection:              lo.ToPtr(true),
+				MinimizePodCache:                  lo.ToPtr(true),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				NodeRepairTolerationDuration:      lo.ToPtr(5 * time.Minute),
+				PreTerminationHookTimeout:         lo.ToPtr(5 * time.Minute),
+				BlockedTerminationThreshold:       lo.ToPtr(10 * time.Minute),
+				DisruptionPreferNoScheduleWindow:  lo.ToPtr(time.Minute),
+				DisruptionActionRetention:         lo.ToPtr(24 * time.Hour),
+				ReservedLimitsPercentage:          lo.ToPtr(10),
+				ResyncStateOnInconsistency:        lo.ToPtr(true),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(5 * time.Minute),
+				MultiNodeConsolidationParallelism: lo.ToPtr(8),
+				NodePoolSelector:                  lo.ToPtr("team=cli"),
+				ProtectedPodNamespaces:            lo.ToPtr("kube-system"),
+				ProtectedPodSelector:              lo.ToPtr("app=cli"),
+				EvictionBypassNamespaceSelector:   lo.ToPtr("eviction=cli"),
+				CloudProviderRateLimits:           lo.ToPtr("Create=5:10"),
+				BinPackingStrategy:                lo.ToPtr("Memory"),
 				FeatureGates: test.FeatureGates{
 					Drift: lo.ToPtr(true),
 				},
@@ -166,11 +250,32 @@ var _ = Describe("Options", func() {
 			os.Setenv("KUBE_CLIENT_QPS", "0")
 			os.Setenv("KUBE_CLIENT_BURST", "0")
 			os.Setenv("ENABLE_PROFILING", "true")
+			os.Setenv("ENABLE_TRACING", "true")
+			os.Setenv("ENABLE_DEBUG_STATE", "true")
+			os.Setenv("ENABLE_SIMULATION", "true")
 			os.Setenv("LEADER_ELECT", "false")
+			os.Setenv("ENABLE_ADMISSION_POLICIES", "true")
+			os.Setenv("ENABLE_FAULT_INJECTION", "true")
+			os.Setenv("MINIMIZE_POD_CACHE", "true")
 			os.Setenv("MEMORY_LIMIT", "0")
 			os.Setenv("LOG_LEVEL", "debug")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
+			os.Setenv("NODE_REPAIR_TOLERATION_DURATION", "5m")
+			os.Setenv("PRE_TERMINATION_HOOK_TIMEOUT", "5m")
+			os.Setenv("BLOCKED_TERMINATION_THRESHOLD", "10m")
+			os.Setenv("DISRUPTION_PREFER_NO_SCHEDULE_WINDOW", "1m")
+			os.Setenv("DISRUPTION_ACTION_RETENTION", "24h")
+			os.Setenv("RESERVED_LIMITS_PERCENTAGE", "10")
+			os.Setenv("RESYNC_STATE_ON_INCONSISTENCY", "true")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_TIMEOUT", "5m")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_PARALLELISM", "8")
+			os.Setenv("NODEPOOL_SELECTOR", "team=env")
+			os.Setenv("PROTECTED_POD_NAMESPACES", "kube-system,monitoring")
+			os.Setenv("PROTECTED_POD_SELECTOR", "app=env")
+			os.Setenv("EVICTION_BYPASS_NAMESPACE_SELECTOR", "eviction=env")
+			os.Setenv("CLOUDPROVIDER_RATE_LIMITS", "Create=5,Delete=2")
+			os.Setenv("BINPACKING_STRATEGY", "DominantResource")
 			os.Setenv("FEATURE_GATES", "Drift=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
@@ -179,20 +284,41 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("env"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("env"),
+				DisableWebhook:                    lo.ToPtr(true),
+				WebhookPort:                       lo.ToPtr(0),
+				MetricsPort:                       lo.ToPtr(0),
+				WebhookMetricsPort:                lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				EnableTracing:                     lo.ToPtr(true),
+				EnableDebugState:                  lo.ToPtr(true),
+				EnableSimulation:                  lo.ToPtr(true),
+				EnableLeaderElection:              lo.ToPtr(false),
+				EnableAdmissionPolicies:           lo.ToPtr(true),
+				EnableFaultInjection:              lo.ToPtr(true),
+				MinimizePodCache:                  lo.ToPtr(true),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				NodeRepairTolerationDuration:      lo.ToPtr(5 * time.Minute),
+				PreTerminationHookTimeout:         lo.ToPtr(5 * time.Minute),
+				BlockedTerminationThreshold:       lo.ToPtr(10 * time.Minute),
+				DisruptionPreferNoScheduleWindow:  lo.ToPtr(time.Minute),
+				DisruptionActionRetention:         lo.ToPtr(24 * time.Hour),
+				ReservedLimitsPercentage:          lo.ToPtr(10),
+				ResyncStateOnInconsistency:        lo.ToPtr(true),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(5 * time.Minute),
+				MultiNodeConsolidationParallelism: lo.ToPtr(8),
+				NodePoolSelector:                  lo.ToPtr("team=env"),
+				ProtectedPodNamespaces:            lo.ToPtr("kube-system,monitoring"),
+				ProtectedPodSelector:              lo.ToPtr("app=env"),
+				EvictionBypassNamespaceSelector:   lo.ToPtr("eviction=env"),
+				CloudProviderRateLimits:           lo.ToPtr("Create=5,Delete=2"),
+				BinPackingStrategy:                lo.ToPtr("DominantResource"),
 				FeatureGates: test.FeatureGates{
 					Drift: lo.ToPtr(true),
 				},
@@ -207,11 +333,32 @@ var _ = Describe("Options", func() {
 			os.Setenv("KUBE_CLIENT_QPS", "0")
 			os.Setenv("KUBE_CLIENT_BURST", "0")
 			os.Setenv("ENABLE_PROFILING", "true")
+			os.Setenv("ENABLE_TRACING", "true")
+			os.Setenv("ENABLE_DEBUG_STATE", "true")
+			os.Setenv("ENABLE_SIMULATION", "true")
 			os.Setenv("LEADER_ELECT", "false")
+			os.Setenv("ENABLE_ADMISSION_POLICIES", "true")
+			os.Setenv("ENABLE_FAULT_INJECTION", "true")
+			os.Setenv("MINIMIZE_POD_CACHE", "true")
 			os.Setenv("MEMORY_LIMIT", "0")
 			os.Setenv("LOG_LEVEL", "debug")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
+			os.Setenv("NODE_REPAIR_TOLERATION_DURATION", "5m")
+			os.Setenv("PRE_TERMINATION_HOOK_TIMEOUT", "5m")
+			os.Setenv("BLOCKED_TERMINATION_THRESHOLD", "10m")
+			os.Setenv("DISRUPTION_PREFER_NO_SCHEDULE_WINDOW", "1m")
+			os.Setenv("DISRUPTION_ACTION_RETENTION", "24h")
+			os.Setenv("RESERVED_LIMITS_PERCENTAGE", "10")
+			os.Setenv("RESYNC_STATE_ON_INCONSISTENCY", "true")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_TIMEOUT", "5m")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_PARALLELISM", "8")
+			os.Setenv("NODEPOOL_SELECTOR", "team=env")
+			os.Setenv("PROTECTED_POD_NAMESPACES", "kube-system,monitoring")
+			os.Setenv("PROTECTED_POD_SELECTOR", "app=env")
+			os.Setenv("EVICTION_BYPASS_NAMESPACE_SELECTOR", "eviction=env")
+			os.Setenv("CLOUDPROVIDER_RATE_LIMITS", "Create=5,Delete=2")
+			os.Setenv("BINPACKING_STRATEGY", "DominantResource")
 			os.Setenv("FEATURE_GATES", "Drift=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
@@ -224,20 +371,41 @@ var _ = Describe("Options", func() {
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("cli"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("cli"),
+				DisableWebhook:                    lo.ToPtr(true),
+				WebhookPort:                       lo.ToPtr(0),
+				MetricsPort:                       lo.ToPtr(0),
+				WebhookMetricsPort:                lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				EnableTracing:                     lo.ToPtr(true),
+				EnableDebugState:                  lo.ToPtr(true),
+				EnableSimulation:                  lo.ToPtr(true),
+				EnableLeaderElection:              lo.ToPtr(false),
+				EnableAdmissionPolicies:           lo.ToPtr(true),
+				EnableFaultInjection:              lo.ToPtr(true),
+				MinimizePodCache:                  lo.ToPtr(true),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				NodeRepairTolerationDuration:      lo.ToPtr(5 * time.Minute),
+				PreTerminationHookTimeout:         lo.ToPtr(5 * time.Minute),
+				BlockedTerminationThreshold:       lo.ToPtr(10 * time.Minute),
+				DisruptionPreferNoScheduleWindow:  lo.ToPtr(time.Minute),
+				DisruptionActionRetention:         lo.ToPtr(24 * time.Hour),
+				ReservedLimitsPercentage:          lo.ToPtr(10),
+				ResyncStateOnInconsistency:        lo.ToPtr(true),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(5 * time.Minute),
+				MultiNodeConsolidationParallelism: lo.ToPtr(8),
+				NodePoolSelector:                  lo.ToPtr("team=env"),
+				ProtectedPodNamespaces:            lo.ToPtr("kube-system,monitoring"),
+				ProtectedPodSelector:              lo.ToPtr("app=env"),
+				EvictionBypassNamespaceSelector:   lo.ToPtr("eviction=env"),
+				CloudProviderRateLimits:           lo.ToPtr("Create=5,Delete=2"),
+				BinPackingStrategy:                lo.ToPtr("DominantResource"),
 				FeatureGates: test.FeatureGates{
 					Drift: lo.ToPtr(true),
 				},
@@ -274,6 +442,99 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs, "--log-level", "hello")
 			Expect(err).ToNot(BeNil())
 		})
+		It("should error with a reserved limits percentage outside of 0-100", func() {
+			err := opts.Parse(fs, "--reserved-limits-percentage", "101")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a non-positive multi-node consolidation timeout", func() {
+			err := opts.Parse(fs, "--multi-node-consolidation-timeout", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a non-positive pre-termination hook timeout", func() {
+			err := opts.Parse(fs, "--pre-termination-hook-timeout", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative blocked termination threshold", func() {
+			err := opts.Parse(fs, "--blocked-termination-threshold", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption prefer no schedule window", func() {
+			err := opts.Parse(fs, "--disruption-prefer-no-schedule-window", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption action retention", func() {
+			err := opts.Parse(fs, "--disruption-action-retention", "-1h")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a multi-node consolidation parallelism less than 1", func() {
+			err := opts.Parse(fs, "--multi-node-consolidation-parallelism", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid nodepool selector", func() {
+			err := opts.Parse(fs, "--nodepool-selector", "team in (a")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid protected pod selector", func() {
+			err := opts.Parse(fs, "--protected-pod-selector", "app in (a")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid eviction bypass namespace selector", func() {
+			err := opts.Parse(fs, "--eviction-bypass-namespace-selector", "eviction in (a")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with invalid cloudprovider rate limits", func() {
+			for _, rateLimits := range []string{"Create", "Create=0", "Create=a", "Create=5:0", "=5", "Creat=5"} {
+				err := opts.Parse(fs, "--cloudprovider-rate-limits", rateLimits)
+				Expect(err).ToNot(BeNil())
+			}
+		})
+		It("should error with an invalid binpacking strategy", func() {
+			err := opts.Parse(fs, "--binpacking-strategy", "GPU")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Reload", func() {
+		It("should override the reloadable options", func() {
+			Expect(opts.Parse(fs, "--batch-idle-duration", "1s", "--feature-gates", "Drift=true")).To(Succeed())
+			reloaded, err := opts.Reload(map[string]string{
+				"batch-idle-duration": "3s",
+				"batch-max-duration":  "30s",
+				"log-level":           "debug",
+				"feature-gates":       "SpotToSpotConsolidation=true",
+			})
+			Expect(err).To(BeNil())
+			Expect(reloaded.BatchIdleDuration).To(Equal(3 * time.Second))
+			Expect(reloaded.BatchMaxDuration).To(Equal(30 * time.Second))
+			Expect(reloaded.LogLevel).To(Equal("debug"))
+			Expect(reloaded.FeatureGates.Drift).To(BeTrue())
+			Expect(reloaded.FeatureGates.SpotToSpotConsolidation).To(BeTrue())
+			// The reloaded options are a copy
+			Expect(opts.BatchIdleDuration).To(Equal(time.Second))
+		})
+		It("should share the reloaded options with derived contexts", func() {
+			ctx := options.ToContext(ctx, test.Options())
+			derived, cancel := context.WithCancel(ctx)
+			defer cancel()
+			_, err := options.ReloadContext(ctx, map[string]string{"batch-idle-duration": "3s"})
+			Expect(err).To(BeNil())
+			Expect(options.FromContext(derived).BatchIdleDuration).To(Equal(3 * time.Second))
+
+			_, err = options.ReloadContext(ctx, nil)
+			Expect(err).To(BeNil())
+			Expect(options.FromContext(derived).BatchIdleDuration).To(Equal(test.Options().BatchIdleDuration))
+		})
+		DescribeTable(
+			"should error with invalid overrides",
+			func(name, value string) {
+				_, err := opts.Reload(map[string]string{name: value})
+				Expect(err).ToNot(BeNil())
+			},
+			Entry("option that can't be reloaded", "metrics-port", "9000"),
+			Entry("invalid duration", "batch-max-duration", "soon"),
+			Entry("invalid log level", "log-level", "hello"),
+			Entry("invalid feature gates", "feature-gates", "Drift"),
+		)
 	})
 })
 
@@ -293,10 +554,31 @@ func expectOptionsMatch(optsA, optsB *options.Options) {
 	Expect(optsA.KubeClientQPS).To(Equal(optsB.KubeClientQPS))
 	Expect(optsA.KubeClientBurst).To(Equal(optsB.KubeClientBurst))
 	Expect(optsA.EnableProfiling).To(Equal(optsB.EnableProfiling))
+	Expect(optsA.EnableTracing).To(Equal(optsB.EnableTracing))
+	Expect(optsA.EnableDebugState).To(Equal(optsB.EnableDebugState))
+	Expect(optsA.EnableSimulation).To(Equal(optsB.EnableSimulation))
 	Expect(optsA.EnableLeaderElection).To(Equal(optsB.EnableLeaderElection))
+	Expect(optsA.EnableAdmissionPolicies).To(Equal(optsB.EnableAdmissionPolicies))
+	Expect(optsA.EnableFaultInjection).To(Equal(optsB.EnableFaultInjection))
+	Expect(optsA.MinimizePodCache).To(Equal(optsB.MinimizePodCache))
 	Expect(optsA.MemoryLimit).To(Equal(optsB.MemoryLimit))
 	Expect(optsA.LogLevel).To(Equal(optsB.LogLevel))
 	Expect(optsA.BatchMaxDuration).To(Equal(optsB.BatchMaxDuration))
 	Expect(optsA.BatchIdleDuration).To(Equal(optsB.BatchIdleDuration))
+	Expect(optsA.NodeRepairTolerationDuration).To(Equal(optsB.NodeRepairTolerationDuration))
+	Expect(optsA.PreTerminationHookTimeout).To(Equal(optsB.PreTerminationHookTimeout))
+	Expect(optsA.BlockedTerminationThreshold).To(Equal(optsB.BlockedTerminationThreshold))
+	Expect(optsA.DisruptionPreferNoScheduleWindow).To(Equal(optsB.DisruptionPreferNoScheduleWindow))
+	Expect(optsA.DisruptionActionRetention).To(Equal(optsB.DisruptionActionRetention))
+	Expect(optsA.ReservedLimitsPercentage).To(Equal(optsB.ReservedLimitsPercentage))
+	Expect(optsA.ResyncStateOnInconsistency).To(Equal(optsB.ResyncStateOnInconsistency))
+	Expect(optsA.MultiNodeConsolidationTimeout).To(Equal(optsB.MultiNodeConsolidationTimeout))
+	Expect(optsA.MultiNodeConsolidationParallelism).To(Equal(optsB.MultiNodeConsolidationParallelism))
+	Expect(optsA.NodePoolSelector).To(Equal(optsB.NodePoolSelector))
+	Expect(optsA.ProtectedPodNamespaces).To(Equal(optsB.ProtectedPodNamespaces))
+	Expect(optsA.ProtectedPodSelector).To(Equal(optsB.ProtectedPodSelector))
+	Expect(optsA.EvictionBypassNamespaceSelector).To(Equal(optsB.EvictionBypassNamespaceSelector))
+	Expect(optsA.CloudProviderRateLimits).To(Equal(optsB.CloudProviderRateLimits))
+	Expect(optsA.BinPackingStrategy).To(Equal(optsB.BinPackingStrategy))
 	Expect(optsA.FeatureGates.Drift).To(Equal(optsB.FeatureGates.Drift))
 }
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -71,17 +73,23 @@ func (u *HostPortUsage) Add(usedBy *v1.Pod, ports []HostPort) {
 	u.reserved[client.ObjectKeyFromObject(usedBy)] = ports
 }
 
-func (u *HostPortUsage) Conflicts(usedBy *v1.Pod, ports []HostPort) error {
-	for _, newEntry := range ports {
-		for podKey, entries := range u.reserved {
-			for _, existing := range entries {
-				if newEntry.Matches(existing) && podKey != client.ObjectKeyFromObject(usedBy) {
-					return fmt.Errorf("%s conflicts with existing HostPort configuration %s", newEntry, existing)
+// Conflicts returns an error describing every host port of the pod that's already in use by another pod, or nil if
+// the pod's host ports are all available
+func (u *HostPortUsage) Conflicts(pod *v1.Pod) error {
+	var errs error
+	for _, newEntry := range GetHostPorts(pod) {
+		for _, podKey := range lo.Keys(u.reserved) {
+			if podKey == client.ObjectKeyFromObject(pod) {
+				continue
+			}
+			for _, existing := range u.reserved[podKey] {
+				if newEntry.Matches(existing) {
+					errs = multierr.Append(errs, fmt.Errorf("%s conflicts with existing HostPort configuration %s of pod %s", newEntry, existing, podKey))
 				}
 			}
 		}
 	}
-	return nil
+	return errs
 }
 
 // DeletePod deletes all host port usage from the HostPortUsage that were created by the pod with the given name.
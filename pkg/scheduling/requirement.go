@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"regexp"
+	"sort"
 	"strconv"
 
 	"github.com/samber/lo"
@@ -36,7 +38,9 @@ type Requirement struct {
 	values      sets.Set[string]
 	greaterThan *int
 	lessThan    *int
-	MinValues   *int
+	// notMatches are the patterns that values must not match, keyed by the pattern that they were compiled from
+	notMatches map[string]*regexp.Regexp
+	MinValues  *int
 }
 
 // NewRequirementWithFlexibility constructs new requirement from the combination of key, values, minValues and the operator that
@@ -80,6 +84,14 @@ func NewRequirementWithFlexibility(key string, operator v1.NodeSelectorOperator,
 		value, _ := strconv.Atoi(values[0]) // prevalidated
 		r.lessThan = &value
 	}
+	if operator == v1beta1.NodeSelectorOpNotMatches {
+		r.notMatches = make(map[string]*regexp.Regexp, len(values))
+		for _, value := range values {
+			if pattern, err := v1beta1.CompileNotMatchesPattern(value); err == nil { // prevalidated
+				r.notMatches[value] = pattern
+			}
+		}
+	}
 	return r
 }
 
@@ -109,6 +121,21 @@ func (r *Requirement) NodeSelectorRequirement() v1beta1.NodeSelectorRequirementW
 		}
 	case r.complement:
 		switch {
+		case len(r.notMatches) > 0:
+			// Excluded values are folded into the patterns, since a requirement only has a single operator
+			patterns := lo.Keys(r.notMatches)
+			sort.Strings(patterns)
+			for _, value := range sets.List(r.values) {
+				patterns = append(patterns, regexp.QuoteMeta(value))
+			}
+			return v1beta1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: v1.NodeSelectorRequirement{
+					Key:      r.Key,
+					Operator: v1beta1.NodeSelectorOpNotMatches,
+					Values:   patterns,
+				},
+				MinValues: r.MinValues,
+			}
 		case len(r.values) > 0:
 			return v1beta1.NodeSelectorRequirementWithMinValues{
 				NodeSelectorRequirement: v1.NodeSelectorRequirement{
@@ -164,6 +191,9 @@ func (r *Requirement) Intersection(requirement *Requirement) *Requirement {
 		return NewRequirementWithFlexibility(r.Key, v1.NodeSelectorOpDoesNotExist, minValues)
 	}
 
+	// Patterns
+	notMatches := unionPatterns(r.notMatches, requirement.notMatches)
+
 	// Values
 	var values sets.Set[string]
 	if r.complement && requirement.complement {
@@ -176,15 +206,15 @@ func (r *Requirement) Intersection(requirement *Requirement) *Requirement {
 		values = r.values.Intersection(requirement.values)
 	}
 	for value := range values {
-		if !withinIntPtrs(value, greaterThan, lessThan) {
+		if !withinIntPtrs(value, greaterThan, lessThan) || matchesAny(value, notMatches) {
 			values.Delete(value)
 		}
 	}
-	// Remove boundaries for concrete sets
+	// Remove boundaries and patterns for concrete sets
 	if !complement {
-		greaterThan, lessThan = nil, nil
+		greaterThan, lessThan, notMatches = nil, nil, nil
 	}
-	return &Requirement{Key: r.Key, values: values, complement: complement, greaterThan: greaterThan, lessThan: lessThan, MinValues: minValues}
+	return &Requirement{Key: r.Key, values: values, complement: complement, greaterThan: greaterThan, lessThan: lessThan, notMatches: notMatches, MinValues: minValues}
 }
 
 func (r *Requirement) Any() string {
@@ -208,7 +238,7 @@ func (r *Requirement) Any() string {
 // Has returns true if the requirement allows the value
 func (r *Requirement) Has(value string) bool {
 	if r.complement {
-		return !r.values.Has(value) && withinIntPtrs(value, r.greaterThan, r.lessThan)
+		return !r.values.Has(value) && withinIntPtrs(value, r.greaterThan, r.lessThan) && !matchesAny(value, r.notMatches)
 	}
 	return r.values.Has(value) && withinIntPtrs(value, r.greaterThan, r.lessThan)
 }
@@ -259,6 +289,11 @@ func (r *Requirement) String() string {
 	if r.lessThan != nil {
 		s += fmt.Sprintf(" <%d", *r.lessThan)
 	}
+	if len(r.notMatches) > 0 {
+		patterns := lo.Keys(r.notMatches)
+		sort.Strings(patterns)
+		s += fmt.Sprintf(" !~%s", patterns)
+	}
 	if r.MinValues != nil {
 		s += fmt.Sprintf(" minValues %d", *r.MinValues)
 	}
@@ -283,6 +318,26 @@ func withinIntPtrs(valueAsString string, greaterThan, lessThan *int) bool {
 	return true
 }
 
+// matchesAny returns true if the value matches any of the patterns
+func matchesAny(value string, patterns map[string]*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func unionPatterns(a, b map[string]*regexp.Regexp) map[string]*regexp.Regexp {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	return lo.Assign(a, b)
+}
+
 func minIntPtr(a, b *int) *int {
 	if a == nil {
 		return b
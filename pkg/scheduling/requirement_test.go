@@ -962,4 +962,35 @@ var _ = Describe("Requirement", func() {
 		})
 
 	})
+	Context("NotMatches", func() {
+		notMatchesMetal := NewRequirement("key", v1beta1.NodeSelectorOpNotMatches, ".*metal.*")
+		notMatchesLarge := NewRequirement("key", v1beta1.NodeSelectorOpNotMatches, ".*large")
+
+		It("should exclude the values that match the patterns", func() {
+			Expect(notMatchesMetal.Has("m5.large")).To(BeTrue())
+			Expect(notMatchesMetal.Has("m5.metal")).To(BeFalse())
+			Expect(notMatchesMetal.Has("m5.metal-24xl")).To(BeFalse())
+			// Patterns must match the whole value
+			Expect(notMatchesLarge.Has("m5.large")).To(BeFalse())
+			Expect(notMatchesLarge.Has("m5.large-metal")).To(BeTrue())
+			Expect(notMatchesMetal.Operator()).To(Equal(v1.NodeSelectorOpExists))
+		})
+		It("should intersect with other requirements", func() {
+			instanceTypes := NewRequirement("key", v1.NodeSelectorOpIn, "m5.large", "m5.metal", "c5.metal")
+			Expect(notMatchesMetal.Intersection(instanceTypes)).To(Equal(NewRequirement("key", v1.NodeSelectorOpIn, "m5.large")))
+			Expect(instanceTypes.Intersection(notMatchesMetal)).To(Equal(NewRequirement("key", v1.NodeSelectorOpIn, "m5.large")))
+			Expect(notMatchesMetal.Intersection(notMatchesLarge).Intersection(instanceTypes).Len()).To(Equal(0))
+
+			excluded := notMatchesMetal.Intersection(notInA)
+			Expect(excluded.Has("A")).To(BeFalse())
+			Expect(excluded.Has("c5.metal")).To(BeFalse())
+			Expect(excluded.Has("B")).To(BeTrue())
+		})
+		It("should print and convert the patterns", func() {
+			Expect(notMatchesMetal.String()).To(Equal("key Exists !~[.*metal.*]"))
+			Expect(notMatchesMetal.Intersection(notInA).String()).To(Equal("key NotIn [A] !~[.*metal.*]"))
+			Expect(notMatchesMetal.NodeSelectorRequirement()).To(Equal(v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "key", Operator: v1beta1.NodeSelectorOpNotMatches, Values: []string{".*metal.*"}}}))
+			Expect(notMatchesMetal.Intersection(NewRequirement("key", v1.NodeSelectorOpNotIn, "m5.large")).NodeSelectorRequirement()).To(Equal(v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "key", Operator: v1beta1.NodeSelectorOpNotMatches, Values: []string{".*metal.*", `m5\.large`}}}))
+		})
+	})
 })
@@ -52,6 +52,14 @@ func (ts Taints) Tolerates(pod *v1.Pod) (errs error) {
 	return errs
 }
 
+// HardTaints returns the taints that keep kube-scheduler from binding pods to a node. PreferNoSchedule taints don't,
+// since pods that don't tolerate them are still bound when there's nowhere better to go: each daemonset pod can only
+// go to its own node, and nodes keep taking pods while they have the disruption PreferNoSchedule taint. Treating them
+// as hard taints would launch capacity that goes unused.
+func HardTaints(taints []v1.Taint) Taints {
+	return lo.Reject(taints, func(taint v1.Taint, _ int) bool { return taint.Effect == v1.TaintEffectPreferNoSchedule })
+}
+
 // Merge merges in taints with the passed in taints.
 func (ts Taints) Merge(with Taints) Taints {
 	res := lo.Map(ts, func(t v1.Taint, _ int) v1.Taint {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/samber/lo"
+	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -78,6 +79,18 @@ func (u Volumes) Insert(volumes Volumes) {
 	}
 }
 
+// ExceedsLimits returns an error describing every storage driver whose volume limit is exceeded by the volumes, or nil
+// if the volumes fit within the limits
+func (u Volumes) ExceedsLimits(limits map[string]int) error {
+	var errs error
+	for _, k := range sets.List(sets.KeySet(u)) {
+		if limit, hasLimit := limits[k]; hasLimit && len(u[k]) > limit {
+			errs = multierr.Append(errs, fmt.Errorf("would exceed volume limit for %s, %d > %d", k, len(u[k]), limit))
+		}
+	}
+	return errs
+}
+
 //nolint:gocyclo
 func GetVolumes(ctx context.Context, kubeClient client.Client, pod *v1.Pod) (Volumes, error) {
 	podPVCs := Volumes{}
@@ -195,19 +208,22 @@ func NewVolumeUsage() *VolumeUsage {
 	}
 }
 
-func (v *VolumeUsage) ExceedsLimits(vols Volumes) error {
-	for k, volumes := range v.volumes.Union(vols) {
-		if limit, hasLimit := v.limits[k]; hasLimit && len(volumes) > limit {
-			return fmt.Errorf("would exceed volume limit for %s, %d > %d", k, len(volumes), limit)
-		}
-	}
-	return nil
+// ExceedsLimits returns an error describing every storage driver whose volume limit would be exceeded by mounting the
+// volumes of a pod, as returned by GetVolumes, or nil if they fit within the limits
+func (v *VolumeUsage) ExceedsLimits(volumes Volumes) error {
+	return v.volumes.Union(volumes).ExceedsLimits(v.limits)
 }
 
 func (v *VolumeUsage) AddLimit(storageDriver string, value int) {
 	v.limits[storageDriver] = value
 }
 
+// HasLimits returns whether any storage driver's volume limit is known, which is only the case once the node's CSINode
+// has reported them
+func (v *VolumeUsage) HasLimits() bool {
+	return len(v.limits) > 0
+}
+
 func (v *VolumeUsage) Add(pod *v1.Pod, volumes Volumes) {
 	v.podVolumes[client.ObjectKeyFromObject(pod)] = volumes
 	v.volumes = v.volumes.Union(volumes)
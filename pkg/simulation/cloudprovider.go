@@ -0,0 +1,83 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+)
+
+var _ cloudprovider.CloudProvider = (*staticCloudProvider)(nil)
+
+var errNotSupported = fmt.Errorf("not supported in scheduling simulations")
+
+// staticCloudProvider serves the instance types that were passed to the simulation. Simulations never launch or
+// look up instances, so the rest of the cloudprovider interface isn't supported.
+type staticCloudProvider struct {
+	instanceTypes map[string][]*cloudprovider.InstanceType
+}
+
+func (c *staticCloudProvider) Create(context.Context, *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	return nil, errNotSupported
+}
+
+func (c *staticCloudProvider) Delete(context.Context, *v1beta1.NodeClaim) error {
+	return errNotSupported
+}
+
+func (c *staticCloudProvider) Get(context.Context, string) (*v1beta1.NodeClaim, error) {
+	return nil, errNotSupported
+}
+
+func (c *staticCloudProvider) List(context.Context) ([]*v1beta1.NodeClaim, error) {
+	return nil, errNotSupported
+}
+
+func (c *staticCloudProvider) GetInstanceTypes(_ context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	return c.instanceTypes[nodePool.Name], nil
+}
+
+func (c *staticCloudProvider) IsDrifted(context.Context, *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+	return "", nil
+}
+
+func (c *staticCloudProvider) Price(_ context.Context, instanceType, capacityType, zone string) (float64, error) {
+	for _, its := range c.instanceTypes {
+		for _, it := range its {
+			if it.Name != instanceType {
+				continue
+			}
+			for _, o := range it.Offerings {
+				if o.CapacityType == capacityType && o.Zone == zone {
+					return o.Price, nil
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("no offering found for instance type %q with capacity type %q in zone %q", instanceType, capacityType, zone)
+}
+
+func (c *staticCloudProvider) RepairPolicies() []cloudprovider.RepairPolicy {
+	return nil
+}
+
+func (c *staticCloudProvider) Name() string {
+	return "simulation"
+}
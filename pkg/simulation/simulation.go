@@ -0,0 +1,138 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+)
+
+var ErrNodePoolsNotFound = errors.New("no nodepools found")
+
+// Simulate runs Karpenter's scheduler for the pods that aren't bound to a node and returns the pods that would
+// schedule to the state nodes and the NodeClaims that the NodePools would launch for the rest, so capacity planners
+// and CLIs can forecast Karpenter's binpacking without running it. The pods that are bound to a state node are counted
+// by topology spread, pod affinity and preemption. Simulations don't talk to a cloudprovider, so the instance types that
+// each nodepool can launch are passed in, keyed by nodepool name, along with the daemonset pods that new NodeClaims
+// reserve room for. Nothing is read from or written to a cluster, so the pods' volumes aren't looked up. The context
+// must carry Karpenter's operator options (see options.ToContext).
+func Simulate(ctx context.Context, pods []*v1.Pod, stateNodes []*state.StateNode, nodePools []*v1beta1.NodePool,
+	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*v1.Pod) (scheduling.Results, error) {
+	nodePoolList := &v1beta1.NodePoolList{}
+	for _, np := range nodePools {
+		if np.DeletionTimestamp.IsZero() && np.RuntimeValidate() == nil {
+			nodePoolList.Items = append(nodePoolList.Items, *np.DeepCopy())
+		}
+	}
+	if len(nodePoolList.Items) == 0 {
+		return scheduling.Results{}, ErrNodePoolsNotFound
+	}
+	nodePoolList.OrderByWeight()
+	nodePools = lo.ToSlicePtr(nodePoolList.Items)
+
+	cloudProvider := &staticCloudProvider{instanceTypes: instanceTypes}
+	instanceTypes = map[string][]*cloudprovider.InstanceType{}
+	domains := map[string]sets.Set[string]{}
+	for _, nodePool := range nodePools {
+		instanceTypes[nodePool.Name] = lo.Map(cloudProvider.instanceTypes[nodePool.Name], func(it *cloudprovider.InstanceType, _ int) *cloudprovider.InstanceType {
+			return it.WithPodsCapacity(cloudprovider.MaxPods(ctx, cloudProvider, it, nodePool.Spec.Template.Spec.Kubelet))
+		})
+		for key, values := range scheduling.TopologyDomains(nodePool, instanceTypes[nodePool.Name]) {
+			if domains[key] == nil {
+				domains[key] = sets.New[string]()
+			}
+			domains[key].Insert(values.UnsortedList()...)
+		}
+	}
+
+	// The scheduler looks up the pods that topology spread and pod affinity count, and the nodes that they run on,
+	// through a client, so the inputs are served to it from memory
+	pods = lo.Map(pods, func(p *v1.Pod, _ int) *v1.Pod { return p.DeepCopy() })
+	var objects []client.Object
+	for _, n := range stateNodes {
+		if n.NodeClaim != nil {
+			objects = append(objects, n.NodeClaim.DeepCopy())
+		}
+		if n.Node != nil {
+			objects = append(objects, n.Node.DeepCopy())
+		}
+	}
+	for _, p := range pods {
+		objects = append(objects, p)
+	}
+	for _, obj := range objects {
+		// The client manages resource versions itself, and scheduling tells pods apart by their uid
+		obj.SetResourceVersion("")
+		if obj.GetUID() == "" {
+			obj.SetUID(types.UID(client.ObjectKeyFromObject(obj).String()))
+		}
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objects...).
+		WithIndex(&v1.Pod{}, "spec.nodeName", func(o client.Object) []string { return []string{o.(*v1.Pod).Spec.NodeName} }).
+		Build()
+
+	// Cluster state tracks the nodepools' usage against their limits and the pods with anti-affinity
+	cluster := state.NewCluster(clock.RealClock{}, kubeClient, cloudProvider)
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *v1beta1.NodeClaim:
+			cluster.UpdateNodeClaim(o)
+		case *v1.Node:
+			if err := cluster.UpdateNode(ctx, o); err != nil {
+				return scheduling.Results{}, fmt.Errorf("tracking node %s, %w", o.Name, err)
+			}
+		}
+	}
+	pendingPods := lo.Filter(pods, func(p *v1.Pod, _ int) bool { return p.Spec.NodeName == "" })
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		if err := cluster.UpdatePod(ctx, p); err != nil {
+			return scheduling.Results{}, fmt.Errorf("tracking pod %s, %w", client.ObjectKeyFromObject(p), err)
+		}
+	}
+
+	topology, err := scheduling.NewTopology(ctx, kubeClient, cluster, domains, pendingPods)
+	if err != nil {
+		return scheduling.Results{}, fmt.Errorf("tracking topology counts, %w", err)
+	}
+	// The scheduler modifies the state nodes that it's given
+	stateNodes = lo.Map(stateNodes, func(n *state.StateNode, _ int) *state.StateNode { return n.DeepCopy() })
+	s := scheduling.NewScheduler(ctx, kubeClient, nodePools, cluster, stateNodes, topology, instanceTypes, daemonSetPods,
+		scheduling.NewDaemonOverheadCache(), events.NewRecorder(&record.FakeRecorder{}))
+	return s.Solve(ctx, pendingPods).TruncateInstanceTypes(scheduling.MaxInstanceTypes), nil
+}
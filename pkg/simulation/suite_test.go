@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	. "knative.dev/pkg/logging/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/scheme"
+	"sigs.k8s.io/karpenter/pkg/simulation"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var ctx context.Context
+
+func TestSimulation(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulation")
+}
+
+var _ = BeforeSuite(func() {
+	ctx = options.ToContext(ctx, test.Options())
+})
+
+var _ = Describe("Simulate", func() {
+	var nodePool *v1beta1.NodePool
+	var instanceTypes []*cloudprovider.InstanceType
+	var nodePoolInstanceTypes map[string][]*cloudprovider.InstanceType
+	var objects []client.Object
+	var daemonSetPods []*v1.Pod
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		instanceTypes = fake.InstanceTypes(5)
+		nodePoolInstanceTypes = map[string][]*cloudprovider.InstanceType{nodePool.Name: instanceTypes}
+		objects = []client.Object{nodePool}
+		daemonSetPods = nil
+	})
+	simulate := func(pods ...*v1.Pod) (scheduling.Results, error) {
+		kubeClient := clientfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(objects...).
+			WithIndex(&v1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+				return []string{o.(*v1.Pod).Spec.NodeName}
+			}).
+			Build()
+		cluster := state.NewCluster(clock.RealClock{}, kubeClient, fake.NewCloudProvider())
+		var nodePools []*v1beta1.NodePool
+		for _, obj := range objects {
+			switch o := obj.(type) {
+			case *v1beta1.NodePool:
+				nodePools = append(nodePools, o)
+			case *v1beta1.NodeClaim:
+				cluster.UpdateNodeClaim(o)
+			case *v1.Node:
+				Expect(cluster.UpdateNode(ctx, o)).To(Succeed())
+			case *v1.Pod:
+				Expect(cluster.UpdatePod(ctx, o)).To(Succeed())
+				pods = append(pods, o)
+			}
+		}
+		return simulation.Simulate(ctx, pods, cluster.Nodes().Active(), nodePools, nodePoolInstanceTypes, daemonSetPods)
+	}
+	It("should launch NodeClaims for pods that don't fit on existing nodes", func() {
+		pods := test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		}, 3)
+		results, err := simulate(pods...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.PodErrors).To(BeEmpty())
+		Expect(results.NewNodeClaims).To(HaveLen(1))
+		Expect(results.NewNodeClaims[0].Pods).To(HaveLen(3))
+		Expect(results.NewNodeClaims[0].NodePoolName).To(Equal(nodePool.Name))
+	})
+	It("should schedule pods to existing nodes that have room for them", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:   nodePool.Name,
+					v1.LabelInstanceTypeStable: instanceTypes[4].Name,
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Capacity:   instanceTypes[4].Capacity,
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:  resource.MustParse("5"),
+					v1.ResourcePods: resource.MustParse("50"),
+				},
+			},
+		})
+		objects = append(objects, nodeClaim, node, test.Pod(test.PodOptions{
+			NodeName:             node.Name,
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+		}))
+
+		pods := test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		}, 3)
+		results, err := simulate(pods...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.PodErrors).To(BeEmpty())
+		// Two of the pods fit in the node's remaining 2 CPUs, so only one NodeClaim is needed for the third
+		Expect(results.ExistingNodes).To(HaveLen(1))
+		Expect(results.ExistingNodes[0].Pods).To(HaveLen(2))
+		Expect(results.NewNodeClaims).To(HaveLen(1))
+		Expect(results.NewNodeClaims[0].Pods).To(HaveLen(1))
+	})
+	It("should only pick instance types that the nodepool can launch", func() {
+		nodePoolInstanceTypes[nodePool.Name] = instanceTypes[:1]
+		pods := test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+		}, 1)
+		results, err := simulate(pods...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.NewNodeClaims).To(BeEmpty())
+		Expect(results.PodErrors).To(HaveLen(1))
+	})
+	It("should reserve room for daemonset pods on new NodeClaims", func() {
+		// Every instance type has room for the pod, but only the larger ones have room for the daemonset pod as well
+		daemonSetPods = []*v1.Pod{test.Pod(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		})}
+		pods := test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		}, 1)
+		results, err := simulate(pods...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.NewNodeClaims).To(HaveLen(1))
+		for _, it := range results.NewNodeClaims[0].InstanceTypeOptions {
+			allocatable := it.Allocatable()
+			Expect(allocatable.Cpu().Value()).To(BeNumerically(">=", 2))
+		}
+	})
+	It("should not modify the pods that it's passed", func() {
+		pod := test.UnschedulablePod()
+		expected := pod.DeepCopy()
+		_, err := simulate(pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod).To(Equal(expected))
+	})
+	It("should fail without nodepools", func() {
+		objects = nil
+		_, err := simulate(test.UnschedulablePod())
+		Expect(err).To(MatchError(simulation.ErrNodePoolsNotFound))
+	})
+})
@@ -97,6 +97,19 @@ func NewEnvironment(scheme *runtime.Scheme, options ...functional.Option[Environ
 		// Ref: https://github.com/aws/karpenter-core/pull/330
 		environment.ControlPlane.GetAPIServer().Configure().Set("feature-gates", "MinDomainsInPodTopologySpread=true")
 	}
+	if version.Minor() >= 28 && version.Minor() < 30 {
+		// ValidatingAdmissionPolicies are beta and disabled by default until v1.30, so the API has to be turned on
+		// for the NodePool admission policy to be installed
+		environment.ControlPlane.GetAPIServer().Configure().Append("feature-gates", "ValidatingAdmissionPolicy=true")
+		environment.ControlPlane.GetAPIServer().Configure().Set("runtime-config", "admissionregistration.k8s.io/v1beta1=true")
+	}
+
+	if version.Minor() >= 26 && version.Minor() < 31 {
+		// Dynamic Resource Allocation is alpha, so its resource.k8s.io/v1alpha2 API has to be turned on for pods with
+		// resource claims to be provisioned
+		environment.ControlPlane.GetAPIServer().Configure().Append("feature-gates", "DynamicResourceAllocation=true")
+		environment.ControlPlane.GetAPIServer().Configure().Append("runtime-config", "resource.k8s.io/v1alpha2=true")
+	}
 
 	_ = lo.Must(environment.Start())
 
@@ -33,6 +33,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -48,6 +49,7 @@ import (
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
@@ -200,6 +202,8 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		&storagev1.StorageClass{},
 		&v1beta1.NodePool{},
 		&v1beta1.NodeClaim{},
+		&v1alpha1.DisruptionAction{},
+		&v1alpha1.NodeDrain{},
 	} {
 		for _, namespace := range namespaces.Items {
 			wg.Add(1)
@@ -213,6 +217,8 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		}
 	}
 	wg.Wait()
+	// The api-server's system PriorityClasses can't be deleted, so we only clean up the ones created by tests
+	Expect(c.DeleteAllOf(ctx, &schedulingv1.PriorityClass{}, client.MatchingLabels{test.DiscoveryLabel: "unspecified"})).To(Succeed())
 }
 
 func ExpectFinalizersRemovedFromList(ctx context.Context, c client.Client, objectLists ...client.ObjectList) {
@@ -28,26 +28,50 @@ import (
 
 type OptionsFields struct {
 	// Vendor Neutral
-	ServiceName          *string
-	DisableWebhook       *bool
-	WebhookPort          *int
-	MetricsPort          *int
-	WebhookMetricsPort   *int
-	HealthProbePort      *int
-	KubeClientQPS        *int
-	KubeClientBurst      *int
-	EnableProfiling      *bool
-	EnableLeaderElection *bool
-	MemoryLimit          *int64
-	LogLevel             *string
-	BatchMaxDuration     *time.Duration
-	BatchIdleDuration    *time.Duration
-	FeatureGates         FeatureGates
+	ServiceName                       *string
+	DisableWebhook                    *bool
+	WebhookPort                       *int
+	MetricsPort                       *int
+	WebhookMetricsPort                *int
+	HealthProbePort                   *int
+	KubeClientQPS                     *int
+	KubeClientBurst                   *int
+	EnableProfiling                   *bool
+	EnableTracing                     *bool
+	EnableDebugState                  *bool
+	EnableSimulation                  *bool
+	EnableLeaderElection              *bool
+	EnableAdmissionPolicies           *bool
+	EnableFaultInjection              *bool
+	MinimizePodCache                  *bool
+	MemoryLimit                       *int64
+	LogLevel                          *string
+	BatchMaxDuration                  *time.Duration
+	BatchIdleDuration                 *time.Duration
+	NodeRepairTolerationDuration      *time.Duration
+	PreTerminationHookTimeout         *time.Duration
+	BlockedTerminationThreshold       *time.Duration
+	DisruptionPreferNoScheduleWindow  *time.Duration
+	DisruptionActionRetention         *time.Duration
+	ReservedLimitsPercentage          *int
+	ResyncStateOnInconsistency        *bool
+	MultiNodeConsolidationTimeout     *time.Duration
+	MultiNodeConsolidationParallelism *int
+	NodePoolSelector                  *string
+	ProtectedPodNamespaces            *string
+	ProtectedPodSelector              *string
+	EvictionBypassNamespaceSelector   *string
+	CloudProviderRateLimits           *string
+	BinPackingStrategy                *string
+	FeatureGates                      FeatureGates
 }
 
 type FeatureGates struct {
 	Drift                   *bool
 	SpotToSpotConsolidation *bool
+	NodeRepair              *bool
+	NodeResize              *bool
+	NodeDrain               *bool
 }
 
 func Options(overrides ...OptionsFields) *options.Options {
@@ -59,23 +83,47 @@ func Options(overrides ...OptionsFields) *options.Options {
 	}
 
 	return &options.Options{
-		ServiceName:          lo.FromPtrOr(opts.ServiceName, ""),
-		DisableWebhook:       lo.FromPtrOr(opts.DisableWebhook, false),
-		WebhookPort:          lo.FromPtrOr(opts.WebhookPort, 8443),
-		MetricsPort:          lo.FromPtrOr(opts.MetricsPort, 8000),
-		WebhookMetricsPort:   lo.FromPtrOr(opts.WebhookMetricsPort, 8001),
-		HealthProbePort:      lo.FromPtrOr(opts.HealthProbePort, 8081),
-		KubeClientQPS:        lo.FromPtrOr(opts.KubeClientQPS, 200),
-		KubeClientBurst:      lo.FromPtrOr(opts.KubeClientBurst, 300),
-		EnableProfiling:      lo.FromPtrOr(opts.EnableProfiling, false),
-		EnableLeaderElection: lo.FromPtrOr(opts.EnableLeaderElection, true),
-		MemoryLimit:          lo.FromPtrOr(opts.MemoryLimit, -1),
-		LogLevel:             lo.FromPtrOr(opts.LogLevel, ""),
-		BatchMaxDuration:     lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
-		BatchIdleDuration:    lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		ServiceName:                       lo.FromPtrOr(opts.ServiceName, ""),
+		DisableWebhook:                    lo.FromPtrOr(opts.DisableWebhook, false),
+		WebhookPort:                       lo.FromPtrOr(opts.WebhookPort, 8443),
+		MetricsPort:                       lo.FromPtrOr(opts.MetricsPort, 8000),
+		WebhookMetricsPort:                lo.FromPtrOr(opts.WebhookMetricsPort, 8001),
+		HealthProbePort:                   lo.FromPtrOr(opts.HealthProbePort, 8081),
+		KubeClientQPS:                     lo.FromPtrOr(opts.KubeClientQPS, 200),
+		KubeClientBurst:                   lo.FromPtrOr(opts.KubeClientBurst, 300),
+		EnableProfiling:                   lo.FromPtrOr(opts.EnableProfiling, false),
+		EnableTracing:                     lo.FromPtrOr(opts.EnableTracing, false),
+		EnableDebugState:                  lo.FromPtrOr(opts.EnableDebugState, false),
+		EnableSimulation:                  lo.FromPtrOr(opts.EnableSimulation, false),
+		EnableLeaderElection:              lo.FromPtrOr(opts.EnableLeaderElection, true),
+		EnableAdmissionPolicies:           lo.FromPtrOr(opts.EnableAdmissionPolicies, false),
+		EnableFaultInjection:              lo.FromPtrOr(opts.EnableFaultInjection, false),
+		MinimizePodCache:                  lo.FromPtrOr(opts.MinimizePodCache, false),
+		MemoryLimit:                       lo.FromPtrOr(opts.MemoryLimit, -1),
+		LogLevel:                          lo.FromPtrOr(opts.LogLevel, ""),
+		BatchMaxDuration:                  lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
+		BatchIdleDuration:                 lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		NodeRepairTolerationDuration:      lo.FromPtrOr(opts.NodeRepairTolerationDuration, 30*time.Minute),
+		PreTerminationHookTimeout:         lo.FromPtrOr(opts.PreTerminationHookTimeout, 10*time.Minute),
+		BlockedTerminationThreshold:       lo.FromPtrOr(opts.BlockedTerminationThreshold, 5*time.Minute),
+		DisruptionPreferNoScheduleWindow:  lo.FromPtrOr(opts.DisruptionPreferNoScheduleWindow, 0),
+		DisruptionActionRetention:         lo.FromPtrOr(opts.DisruptionActionRetention, 7*24*time.Hour),
+		ReservedLimitsPercentage:          lo.FromPtrOr(opts.ReservedLimitsPercentage, 0),
+		ResyncStateOnInconsistency:        lo.FromPtrOr(opts.ResyncStateOnInconsistency, false),
+		MultiNodeConsolidationTimeout:     lo.FromPtrOr(opts.MultiNodeConsolidationTimeout, time.Minute),
+		MultiNodeConsolidationParallelism: lo.FromPtrOr(opts.MultiNodeConsolidationParallelism, 4),
+		NodePoolSelector:                  lo.FromPtrOr(opts.NodePoolSelector, ""),
+		ProtectedPodNamespaces:            lo.FromPtrOr(opts.ProtectedPodNamespaces, ""),
+		ProtectedPodSelector:              lo.FromPtrOr(opts.ProtectedPodSelector, ""),
+		EvictionBypassNamespaceSelector:   lo.FromPtrOr(opts.EvictionBypassNamespaceSelector, ""),
+		CloudProviderRateLimits:           lo.FromPtrOr(opts.CloudProviderRateLimits, ""),
+		BinPackingStrategy:                lo.FromPtrOr(opts.BinPackingStrategy, options.BinPackingStrategyCPU),
 		FeatureGates: options.FeatureGates{
 			Drift:                   lo.FromPtrOr(opts.FeatureGates.Drift, false),
 			SpotToSpotConsolidation: lo.FromPtrOr(opts.FeatureGates.SpotToSpotConsolidation, false),
+			NodeRepair:              lo.FromPtrOr(opts.FeatureGates.NodeRepair, false),
+			NodeResize:              lo.FromPtrOr(opts.FeatureGates.NodeResize, false),
+			NodeDrain:               lo.FromPtrOr(opts.FeatureGates.NodeDrain, false),
 		},
 	}
 }
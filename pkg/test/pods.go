@@ -35,6 +35,7 @@ type PodOptions struct {
 	NodeName                      string
 	Overhead                      v1.ResourceList
 	PriorityClassName             string
+	Priority                      *int32
 	InitContainers                []v1.Container
 	ResourceRequirements          v1.ResourceRequirements
 	NodeSelector                  map[string]string
@@ -142,6 +143,7 @@ func Pod(overrides ...PodOptions) *v1.Pod {
 			NodeName:                      options.NodeName,
 			Volumes:                       volumes,
 			PriorityClassName:             options.PriorityClassName,
+			Priority:                      options.Priority,
 			RestartPolicy:                 options.RestartPolicy,
 			TerminationGracePeriodSeconds: options.TerminationGracePeriodSeconds,
 		},
@@ -0,0 +1,30 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	schedulingv1 "k8s.io/api/scheduling/v1"
+)
+
+// PriorityClass creates a test PriorityClass with the given value. Pods that set a priority need to reference a
+// PriorityClass with the same value, otherwise the api-server's Priority admission plugin rejects them.
+func PriorityClass(value int32) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		ObjectMeta: ObjectMeta(),
+		Value:      value,
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// Selector returns the selector of the nodepools that this instance of Karpenter manages. It selects every nodepool
+// unless the nodepool selector option is set.
+func Selector(ctx context.Context) labels.Selector {
+	// The selector is validated when the options are parsed, so it only fails to parse here if it was never validated
+	selector, err := labels.Parse(options.FromContext(ctx).NodePoolSelector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return selector
+}
+
+// IsSharded returns whether this instance of Karpenter only manages a subset of the nodepools
+func IsSharded(ctx context.Context) bool {
+	return !Selector(ctx).Empty()
+}
+
+// ShardID returns an identifier for the shard of nodepools that this instance of Karpenter manages, which is the same
+// for every instance that uses an equivalent nodepool selector
+func ShardID(ctx context.Context) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(Selector(ctx).String()))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// ShardScopedName scopes the name of an object that's shared by the instances of Karpenter that manage the same shard of
+// nodepools, such as their leader election lease, so that deployments with different nodepool selectors don't share it
+func ShardScopedName(ctx context.Context, name string) string {
+	if !IsSharded(ctx) {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", name, ShardID(ctx))
+}
+
+// ShardLabels returns the nodepool's labels that the nodepool selector matches on. They're propagated to the
+// nodepool's NodeClaims and Nodes, so that each instance of Karpenter can tell which of them belong to its shard.
+func ShardLabels(ctx context.Context, nodePool *v1beta1.NodePool) map[string]string {
+	requirements, _ := Selector(ctx).Requirements()
+	shardLabels := map[string]string{}
+	for _, requirement := range requirements {
+		if value, ok := nodePool.Labels[requirement.Key()]; ok {
+			shardLabels[requirement.Key()] = value
+		}
+	}
+	return shardLabels
+}
+
+// IsDefaultShard returns whether this instance of Karpenter manages the default shard, which owns the NodeClaims and
+// Nodes that don't carry any shard labels, such as those launched before the nodepool selector was set. It's the shard
+// whose nodepool selector matches objects without the labels, e.g. "team!=a", in the same way that the cache selects
+// the NodeClaims to watch.
+func IsDefaultShard(ctx context.Context) bool {
+	return Selector(ctx).Matches(labels.Set{})
+}
+
+// InShard returns whether a NodeClaim or Node belongs to the shard of nodepools that this instance of Karpenter
+// manages. Ones that were launched for a nodepool belong to the shard that their shard labels match, or to the default
+// shard if they don't carry any. Nodes that weren't launched for a nodepool aren't owned by Karpenter, so they're in
+// every shard.
+func InShard(ctx context.Context, obj client.Object) bool {
+	if _, ok := obj.GetLabels()[v1beta1.NodePoolLabelKey]; !ok {
+		return true
+	}
+	requirements, _ := Selector(ctx).Requirements()
+	if !lo.ContainsBy(requirements, func(r labels.Requirement) bool { return labels.Set(obj.GetLabels()).Has(r.Key()) }) {
+		return IsDefaultShard(ctx)
+	}
+	return Selector(ctx).Matches(labels.Set(obj.GetLabels()))
+}
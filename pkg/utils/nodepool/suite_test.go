@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	nodepoolutil "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+func TestNodePool(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NodePoolUtils")
+}
+
+func withSelector(selector string) context.Context {
+	return options.ToContext(context.Background(), test.Options(test.OptionsFields{NodePoolSelector: lo.ToPtr(selector)}))
+}
+
+var _ = Describe("NodePoolUtils", func() {
+	It("should select every nodepool without a nodepool selector", func() {
+		ctx := withSelector("")
+		Expect(nodepoolutil.IsSharded(ctx)).To(BeFalse())
+		Expect(nodepoolutil.InShard(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default"}}})).To(BeTrue())
+		Expect(nodepoolutil.ShardLabels(ctx, &v1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "a"}}})).To(BeEmpty())
+	})
+	It("should only include nodes from nodepools that match the nodepool selector", func() {
+		ctx := withSelector("team=a")
+		Expect(nodepoolutil.IsSharded(ctx)).To(BeTrue())
+		Expect(nodepoolutil.InShard(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default", "team": "a"}}})).To(BeTrue())
+		Expect(nodepoolutil.InShard(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default", "team": "b"}}})).To(BeFalse())
+		Expect(nodepoolutil.InShard(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default"}}})).To(BeFalse())
+	})
+	It("should only include nodes from nodepools without shard labels in the default shard", func() {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default"}}}
+		Expect(nodepoolutil.IsDefaultShard(withSelector("team!=a"))).To(BeTrue())
+		Expect(nodepoolutil.InShard(withSelector("team!=a"), node)).To(BeTrue())
+		Expect(nodepoolutil.IsDefaultShard(withSelector("team=a"))).To(BeFalse())
+		Expect(nodepoolutil.InShard(withSelector("team=a"), node)).To(BeFalse())
+		Expect(nodepoolutil.InShard(withSelector(""), node)).To(BeTrue())
+	})
+	It("should only include nodeclaims from nodepools without shard labels in the default shard", func() {
+		nodeClaim := &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.NodePoolLabelKey: "default"}}}
+		Expect(nodepoolutil.InShard(withSelector("!team"), nodeClaim)).To(BeTrue())
+		Expect(nodepoolutil.InShard(withSelector("team in (a,b)"), nodeClaim)).To(BeFalse())
+	})
+	It("should include nodes that weren't launched for a nodepool in every shard", func() {
+		Expect(nodepoolutil.InShard(withSelector("team=a"), &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "b"}}})).To(BeTrue())
+	})
+	It("should only return the nodepool labels that the nodepool selector matches on", func() {
+		nodePool := &v1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "a", "env": "prod", "unrelated": "value"}}}
+		Expect(nodepoolutil.ShardLabels(withSelector("team=a,env in (prod,dev),tier!=batch"), nodePool)).To(Equal(map[string]string{"team": "a", "env": "prod"}))
+	})
+	It("should return the same shard id for equivalent nodepool selectors", func() {
+		Expect(nodepoolutil.ShardID(withSelector("team=a,env=prod"))).To(Equal(nodepoolutil.ShardID(withSelector("env=prod, team=a"))))
+		Expect(nodepoolutil.ShardID(withSelector("team=a"))).ToNot(Equal(nodepoolutil.ShardID(withSelector("team=b"))))
+	})
+	It("should only scope names to the shard when the nodepool selector is set", func() {
+		Expect(nodepoolutil.ShardScopedName(withSelector(""), "karpenter")).To(Equal("karpenter"))
+		Expect(nodepoolutil.ShardScopedName(withSelector("team=a"), "karpenter")).To(Equal("karpenter-" + nodepoolutil.ShardID(withSelector("team=a"))))
+	})
+})
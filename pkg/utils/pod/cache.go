@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+const lastAppliedConfigAnnotationKey = "kubectl.kubernetes.io/last-applied-configuration"
+
+// StripUnusedFields is a cache transform that drops the fields of pods that Karpenter doesn't read, e.g. managed fields
+// and the environment of their containers, so that the pods in the informer cache take up less memory. Karpenter
+// patches pods with merge patches computed from the cached pod, so the dropped fields are never written back.
+func StripUnusedFields(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	pod.ManagedFields = nil
+	delete(pod.Annotations, lastAppliedConfigAnnotationKey)
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			stripContainer(&containers[i])
+		}
+	}
+	for i := range pod.Spec.EphemeralContainers {
+		stripContainer((*v1.Container)(&pod.Spec.EphemeralContainers[i].EphemeralContainerCommon))
+	}
+	pod.Status.InitContainerStatuses = nil
+	pod.Status.ContainerStatuses = nil
+	pod.Status.EphemeralContainerStatuses = nil
+	return pod, nil
+}
+
+// stripContainer drops the fields of a container that don't affect where it can be scheduled. Its resources, ports
+// and restart policy are kept since they're scheduling inputs.
+func stripContainer(container *v1.Container) {
+	container.Command = nil
+	container.Args = nil
+	container.Env = nil
+	container.EnvFrom = nil
+	container.VolumeMounts = nil
+	container.VolumeDevices = nil
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	container.StartupProbe = nil
+	container.Lifecycle = nil
+}
@@ -96,6 +96,23 @@ func IsDisruptable(pod *v1.Pod) bool {
 	return !(IsActive(pod) && HasDoNotDisrupt(pod))
 }
 
+// IsSafeToEvict checks if the cluster-autoscaler.kubernetes.io/safe-to-evict annotation allows a pod to be disrupted.
+// Like the karpenter.sh/do-not-disrupt annotation, it only blocks disruption while the pod is actively running.
+func IsSafeToEvict(pod *v1.Pod) bool {
+	return !(IsActive(pod) && pod.Annotations[v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey] == "false")
+}
+
+// IsHealthy checks if the pod counts as healthy towards its PodDisruptionBudgets, which the eviction API considers to be
+// the case only once the pod is Ready. Crashlooping pods and pods that haven't reported their readiness aren't healthy.
+func IsHealthy(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
 // FailedToSchedule ensures that the kube-scheduler has seen this pod and has intentionally
 // marked this pod with a condition, noting that it thinks that the pod can't schedule anywhere
 // It does this by marking the pod status condition "PodScheduled" as "Unschedulable"
@@ -154,6 +171,14 @@ func IsOwnedByNode(pod *v1.Pod) bool {
 	})
 }
 
+// IsOwnedByNodePool returns true if the pod is a placeholder for a nodepool's headroom. These pods are only scheduled
+// in simulations and are never created in the cluster.
+func IsOwnedByNodePool(pod *v1.Pod) bool {
+	return IsOwnedBy(pod, []schema.GroupVersionKind{
+		v1beta1.SchemeGroupVersion.WithKind("NodePool"),
+	})
+}
+
 func IsOwnedBy(pod *v1.Pod, gvks []schema.GroupVersionKind) bool {
 	for _, ignoredOwner := range gvks {
 		for _, owner := range pod.ObjectMeta.OwnerReferences {
@@ -192,3 +217,17 @@ func HasPodAntiAffinity(pod *v1.Pod) bool {
 		(len(pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0 ||
 			len(pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 0)
 }
+
+// Nomination returns the provider id of the node that the pod was nominated to and when the nomination expires, if the
+// pod has a valid nomination annotation
+func Nomination(pod *v1.Pod) (string, time.Time, bool) {
+	providerID, ok := pod.Annotations[v1beta1.NominatedNodeAnnotationKey]
+	if !ok || providerID == "" {
+		return "", time.Time{}, false
+	}
+	until, err := time.Parse(time.RFC3339, pod.Annotations[v1beta1.NominatedUntilAnnotationKey])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return providerID, until, true
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/test"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+func TestPod(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PodUtils")
+}
+
+var _ = Describe("StripUnusedFields", func() {
+	var pod *v1.Pod
+
+	BeforeEach(func() {
+		pod = test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1beta1.DoNotDisruptAnnotationKey:                  "true",
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				},
+			},
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			HostPorts:            []int32{80},
+			NodeSelector:         map[string]string{v1.LabelTopologyZone: "test-zone-1"},
+		})
+		pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+		pod.Spec.Containers[0].Command = []string{"sleep"}
+		pod.Spec.Containers[0].Env = []v1.EnvVar{{Name: "FOO", Value: "bar"}}
+		pod.Spec.Containers[0].ReadinessProbe = &v1.Probe{}
+		pod.Spec.InitContainers = []v1.Container{{Name: "init", Env: []v1.EnvVar{{Name: "FOO", Value: "bar"}}}}
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "container"}}
+	})
+	It("should drop the fields that Karpenter doesn't use", func() {
+		obj, err := podutil.StripUnusedFields(pod)
+		Expect(err).ToNot(HaveOccurred())
+		stripped := obj.(*v1.Pod)
+		Expect(stripped.ManagedFields).To(BeNil())
+		Expect(stripped.Annotations).ToNot(HaveKey("kubectl.kubernetes.io/last-applied-configuration"))
+		Expect(stripped.Spec.Containers[0].Command).To(BeNil())
+		Expect(stripped.Spec.Containers[0].Env).To(BeNil())
+		Expect(stripped.Spec.Containers[0].ReadinessProbe).To(BeNil())
+		Expect(stripped.Spec.InitContainers[0].Env).To(BeNil())
+		Expect(stripped.Status.ContainerStatuses).To(BeNil())
+	})
+	It("should keep the fields that scheduling and disruption depend on", func() {
+		obj, err := podutil.StripUnusedFields(pod)
+		Expect(err).ToNot(HaveOccurred())
+		stripped := obj.(*v1.Pod)
+		Expect(stripped.Annotations).To(HaveKeyWithValue(v1beta1.DoNotDisruptAnnotationKey, "true"))
+		Expect(stripped.Spec.NodeSelector).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-1"))
+		Expect(stripped.Spec.Containers[0].Resources.Requests).To(HaveKey(v1.ResourceCPU))
+		Expect(stripped.Spec.Containers[0].Ports).To(HaveLen(1))
+		Expect(stripped.Status.Conditions).To(Equal(pod.Status.Conditions))
+	})
+	It("should ignore objects that aren't pods", func() {
+		node := test.Node()
+		obj, err := podutil.StripUnusedFields(node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(node))
+	})
+})
+
+var _ = Describe("IsSafeToEvict", func() {
+	It("should not be safe to evict a running pod with safe-to-evict set to false", func() {
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey: "false"}}})
+		Expect(podutil.IsSafeToEvict(pod)).To(BeFalse())
+	})
+	It("should be safe to evict a pod with safe-to-evict set to true", func() {
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey: "true"}}})
+		Expect(podutil.IsSafeToEvict(pod)).To(BeTrue())
+	})
+	It("should be safe to evict a terminal pod with safe-to-evict set to false", func() {
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.ClusterAutoscalerSafeToEvictAnnotationKey: "false"}},
+			Phase:      v1.PodSucceeded,
+		})
+		Expect(podutil.IsSafeToEvict(pod)).To(BeTrue())
+	})
+})
+
+var _ = Describe("IsHealthy", func() {
+	It("should be healthy when the pod is ready", func() {
+		pod := test.Pod(test.PodOptions{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}})
+		Expect(podutil.IsHealthy(pod)).To(BeTrue())
+	})
+	It("should not be healthy when the pod isn't ready", func() {
+		pod := test.Pod(test.PodOptions{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}}})
+		Expect(podutil.IsHealthy(pod)).To(BeFalse())
+	})
+	It("should not be healthy when the pod hasn't reported its readiness", func() {
+		pod := test.Pod(test.PodOptions{Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue}}})
+		Expect(podutil.IsHealthy(pod)).To(BeFalse())
+	})
+})
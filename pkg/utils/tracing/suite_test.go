@@ -0,0 +1,72 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+	"sigs.k8s.io/karpenter/pkg/utils/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracing Suite")
+}
+
+var _ = Describe("Tracing", func() {
+	var spanContext trace.SpanContext
+
+	BeforeEach(func() {
+		spanContext = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+			SpanID:     trace.SpanID{0x04, 0x05, 0x06},
+			TraceFlags: trace.FlagsSampled,
+		})
+	})
+	It("should continue a trace that was injected on an object", func() {
+		obj := &metav1.ObjectMeta{}
+		Expect(tracing.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), obj)).To(BeTrue())
+		Expect(obj.Annotations).To(HaveKey(v1beta1.TraceParentAnnotationKey))
+
+		extracted := trace.SpanContextFromContext(tracing.Extract(context.Background(), obj))
+		Expect(extracted.TraceID()).To(Equal(spanContext.TraceID()))
+		Expect(extracted.SpanID()).To(Equal(spanContext.SpanID()))
+		Expect(extracted.IsRemote()).To(BeTrue())
+	})
+	It("should not change an object that already has the trace context", func() {
+		obj := &metav1.ObjectMeta{}
+		ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+		Expect(tracing.Inject(ctx, obj)).To(BeTrue())
+		Expect(tracing.Inject(ctx, obj)).To(BeFalse())
+	})
+	It("should not inject without a span in the context", func() {
+		obj := &metav1.ObjectMeta{}
+		Expect(tracing.Inject(context.Background(), obj)).To(BeFalse())
+		Expect(obj.Annotations).To(BeEmpty())
+	})
+	It("should return the context unchanged for an object without a trace context", func() {
+		ctx := context.Background()
+		Expect(tracing.Extract(ctx, &metav1.ObjectMeta{})).To(Equal(ctx))
+	})
+})
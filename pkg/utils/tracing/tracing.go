@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1beta1"
+)
+
+// TracerName is the name of the tracer that Karpenter's spans are recorded with. Spans are only exported if a global
+// TracerProvider is registered, see NewTracerProvider.
+const TracerName = "sigs.k8s.io/karpenter"
+
+// NewTracerProvider registers a global TracerProvider that exports spans with OTLP over HTTP. The exporter is
+// configured by the standard OTEL_EXPORTER_OTLP_* environment variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT. The
+// TracerProvider must be shut down to flush the spans that haven't been exported yet.
+func NewTracerProvider(ctx context.Context, serviceName, version string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp exporter, %w", err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+		)),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	return tracerProvider, nil
+}
+
+// Start starts a span that's a child of the span in the context, if there is one
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error on the span, if there is one, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject records the trace context of the span in the context on the object, so that controllers that later
+// reconcile the object can continue the trace. It returns whether the object was changed.
+func Inject(ctx context.Context, obj metav1.Object) bool {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	traceParent, ok := carrier["traceparent"]
+	if !ok || obj.GetAnnotations()[v1beta1.TraceParentAnnotationKey] == traceParent {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1beta1.TraceParentAnnotationKey] = traceParent
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// Extract returns a context that continues the trace recorded on the object, if there is one
+func Extract(ctx context.Context, obj metav1.Object) context.Context {
+	traceParent, ok := obj.GetAnnotations()[v1beta1.TraceParentAnnotationKey]
+	if !ok {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}